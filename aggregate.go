@@ -0,0 +1,152 @@
+package glide
+
+import (
+	"errors"
+
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/dominikbraun/graph"
+)
+
+// stateRank orders states for aggregation.
+// A node can only ever move to a higher ranked state.
+func stateRank(s State) int {
+	switch s {
+	case Active:
+		return 1
+	case Complete, Failed:
+		return 2
+	}
+	return 0
+}
+
+// Aggregate merges multiple execution results into a single effective result.
+//
+// Results should be provided in the order they were produced, for example
+// one result per approval event as it arrives. Aggregation uses monotonic
+// completion semantics: once a node is Active or Complete in any result,
+// it remains so in the aggregated result.
+//
+// The aggregated outcome is the highest priority outcome reached by any result.
+// If no result reached an outcome, the aggregated outcome is the default outcome.
+// If the workflow failed in any result, the aggregated result is failed
+// and has no outcome.
+// The returned Result has FirstCompleted set, recording the index of the
+// result in which each node first became complete.
+func Aggregate(results []*Result) (*Result, error) {
+	if len(results) == 0 {
+		return nil, errors.New("at least one result must be provided to aggregate")
+	}
+
+	agg := Result{
+		CG:             graph.New(step.Hash, graph.Directed(), graph.PreventCycles()),
+		State:          map[string]State{},
+		FirstCompleted: map[string]int{},
+	}
+
+	var defaultOutcome *node.Node
+
+	for i, r := range results {
+		if r == nil {
+			continue
+		}
+
+		for k, s := range r.State {
+			existing, ok := agg.State[k]
+			if !ok || stateRank(s) > stateRank(existing) {
+				agg.State[k] = s
+			}
+			if _, ok := agg.FirstCompleted[k]; !ok && s == Complete {
+				agg.FirstCompleted[k] = i
+			}
+		}
+
+		// a default outcome is only the result if no
+		// outcome was reached by any of the executions.
+		if r.DefaultOutcome {
+			if defaultOutcome == nil {
+				defaultOutcome = r.OutcomeNode
+			}
+		} else if r.OutcomeNode != nil && (agg.OutcomeNode == nil || r.OutcomeNode.Priority > agg.OutcomeNode.Priority) {
+			n := *r.OutcomeNode
+			agg.OutcomeNode = &n
+			agg.Outcome = n.ID
+		}
+
+		for _, n := range r.ReachedOutcomes {
+			agg.ReachedOutcomes = appendOutcome(agg.ReachedOutcomes, n)
+		}
+
+		if r.Failed {
+			agg.Failed = true
+		}
+
+		// later results have the most recent state of stateful actions.
+		for k, data := range r.ActionState {
+			if agg.ActionState == nil {
+				agg.ActionState = map[string][]byte{}
+			}
+			agg.ActionState[k] = data
+		}
+
+		if r.CG == nil {
+			continue
+		}
+		err := mergeGraph(agg.CG, r.CG)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if agg.Failed {
+		agg.Outcome = ""
+		agg.OutcomeNode = nil
+	}
+
+	reasons, err := outcomeReasons(agg.CG, agg.State, agg.Outcome)
+	if err != nil {
+		return nil, err
+	}
+	agg.Reasons = reasons
+
+	if agg.Outcome == "" && !agg.Failed && defaultOutcome != nil {
+		n := *defaultOutcome
+		agg.Outcome = n.ID
+		agg.OutcomeNode = &n
+		agg.DefaultOutcome = true
+	}
+	if agg.Outcome != "" {
+		agg.Outcomes = []string{agg.Outcome}
+	}
+
+	return &agg, nil
+}
+
+// mergeGraph adds all of the vertices and edges of src into dst.
+func mergeGraph(dst, src graph.Graph[string, step.Step]) error {
+	adj, err := src.AdjacencyMap()
+	if err != nil {
+		return err
+	}
+
+	for k := range adj {
+		v, err := src.Vertex(k)
+		if err != nil {
+			return err
+		}
+		err = dst.AddVertex(v)
+		if err != nil && err != graph.ErrVertexAlreadyExists {
+			return err
+		}
+	}
+
+	for _, edges := range adj {
+		for _, e := range edges {
+			err = dst.AddEdge(e.Source, e.Target)
+			if err != nil && err != graph.ErrEdgeAlreadyExists {
+				return err
+			}
+		}
+	}
+	return nil
+}
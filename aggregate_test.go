@@ -0,0 +1,114 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check("input.approved"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+		InputSchema: &jsoncel.Schema{
+			Properties: map[string]*jsoncel.Schema{
+				"approved": {
+					Type: jsoncel.Boolean,
+				},
+			},
+		},
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []*Result
+	for _, approved := range []bool{false, true, false} {
+		res, err := g.Execute("request", map[string]any{"approved": approved})
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, res)
+	}
+
+	got, err := Aggregate(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]State{
+		"request":   Complete,
+		"default.1": Complete,
+		"approved":  Complete,
+	}, got.State)
+	assert.Equal(t, map[string]int{
+		"request":   0,
+		"default.1": 1,
+		"approved":  1,
+	}, got.FirstCompleted)
+	assert.Equal(t, "approved", got.Outcome)
+	assert.Equal(t, node.Outcome, got.OutcomeNode.Type)
+	assert.Equal(t, []node.Node{*got.OutcomeNode}, got.ReachedOutcomes)
+
+	_, err = got.CG.Edge("default.1", "approved")
+	assert.NoError(t, err)
+}
+
+func TestAggregate_NoResults(t *testing.T) {
+	_, err := Aggregate(nil)
+	assert.Error(t, err)
+}
+
+func TestAggregate_DefaultOutcome(t *testing.T) {
+	pending := &node.Node{Type: node.Outcome, ID: "pending_review", Priority: 2}
+	approved := &node.Node{Type: node.Outcome, ID: "approved", Priority: 1}
+
+	tests := []struct {
+		name        string
+		results     []*Result
+		wantOutcome string
+		wantDefault bool
+	}{
+		{
+			name: "no outcome reached",
+			results: []*Result{
+				{Outcome: "pending_review", OutcomeNode: pending, DefaultOutcome: true},
+				{Outcome: "pending_review", OutcomeNode: pending, DefaultOutcome: true},
+			},
+			wantOutcome: "pending_review",
+			wantDefault: true,
+		},
+		{
+			name: "reached outcome has a lower priority than the default",
+			results: []*Result{
+				{Outcome: "pending_review", OutcomeNode: pending, DefaultOutcome: true},
+				{Outcome: "approved", OutcomeNode: approved},
+			},
+			wantOutcome: "approved",
+		},
+		{
+			name: "failed",
+			results: []*Result{
+				{Outcome: "pending_review", OutcomeNode: pending, DefaultOutcome: true},
+				{Failed: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Aggregate(tt.results)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			assert.Equal(t, tt.wantDefault, got.DefaultOutcome)
+		})
+	}
+}
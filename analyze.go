@@ -0,0 +1,265 @@
+package glide
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// ErrUnreachable is returned by FindInput if no input can reach the outcome,
+// for example because every path to it contains a check which is always false.
+var ErrUnreachable = errors.New("outcome is unreachable")
+
+// ErrNoInputFound is returned by FindInput if the search
+// could not construct an input which reaches the outcome.
+// The outcome may still be reachable.
+var ErrNoInputFound = errors.New("could not find an input which reaches the outcome")
+
+// maxCandidates limits the number of inputs tried by FindInput.
+const maxCandidates = 4096
+
+// FindInput attempts to construct an input which reaches an outcome
+// when the graph is executed from the start node.
+//
+// This is useful for debugging workflows which never reach an outcome.
+// Actions are assumed to be completed when searching for an input.
+//
+// The search first evaluates the graph with every input field unknown.
+// If the outcome is inactive, it can't be reached by any input, and ErrUnreachable
+// is returned. Otherwise, candidate inputs are built from the constants in the
+// check expressions and the input schema, and executed until one reaches the outcome.
+func (g *Graph) FindInput(start, outcome string) (map[string]any, error) {
+	if _, err := g.store.step(outcome); err != nil {
+		return nil, fmt.Errorf("outcome %s was not found in the graph: %w", outcome, err)
+	}
+
+	opts := []ExecuteOption{WithPartialInput(), assumeActionsComplete()}
+
+	res, err := g.execute(start, map[string]any{}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	switch res.State[outcome] {
+	case Complete:
+		return map[string]any{}, nil
+	case Inactive:
+		return nil, ErrUnreachable
+	}
+
+	fields, candidates, err := g.candidateValues()
+	if err != nil {
+		return nil, err
+	}
+
+	// enumerate the combinations of candidate values.
+	counters := make([]int, len(fields))
+	for i := 0; i < maxCandidates; i++ {
+		input := map[string]any{}
+		for f, field := range fields {
+			setField(input, field, candidates[f][counters[f]])
+		}
+
+		res, err := g.execute(start, input, opts...)
+		if err == nil && res.State[outcome] == Complete {
+			return input, nil
+		}
+
+		// increment the counters, like an odometer.
+		f := 0
+		for ; f < len(fields); f++ {
+			counters[f]++
+			if counters[f] < len(candidates[f]) {
+				break
+			}
+			counters[f] = 0
+		}
+		if f == len(fields) {
+			// all combinations have been tried.
+			break
+		}
+	}
+
+	return nil, ErrNoInputFound
+}
+
+func assumeActionsComplete() ExecuteOption {
+	return func(o *executeOptions) {
+		o.assumeActionsComplete = true
+	}
+}
+
+func assumeActionsUnknown() ExecuteOption {
+	return func(o *executeOptions) {
+		o.assumeActionsUnknown = true
+	}
+}
+
+// candidateValues returns the input fields referenced by the graph's checks,
+// along with a list of candidate values for each field.
+func (g *Graph) candidateValues() ([]string, [][]any, error) {
+	cv := constantsVisitor{asts: g.asts}
+	err := g.Walk(&cv)
+	if err != nil {
+		return nil, nil, err
+	}
+	referenced, constants := cv.referenced, cv.constants
+
+	var strs []string
+	var nums []float64
+	for _, c := range constants {
+		switch v := c.GetConstantKind().(type) {
+		case *exprpb.Constant_StringValue:
+			strs = append(strs, v.StringValue)
+		case *exprpb.Constant_Int64Value:
+			n := float64(v.Int64Value)
+			nums = append(nums, n, n+1, n-1)
+		case *exprpb.Constant_Uint64Value:
+			n := float64(v.Uint64Value)
+			nums = append(nums, n, n+1, n-1)
+		case *exprpb.Constant_DoubleValue:
+			nums = append(nums, v.DoubleValue, v.DoubleValue+1, v.DoubleValue-1)
+		}
+	}
+	strs = mergeFields(strs)
+	nums = uniqueNumbers(nums)
+
+	var fields []string
+	var candidates [][]any
+	for _, field := range referenced {
+		var schema *jsoncel.Schema
+		if g.provider != nil {
+			schema = g.provider.FieldSchema(field)
+		}
+		if schema == nil {
+			continue
+		}
+
+		var values []any
+		for _, e := range schema.Enum {
+			values = append(values, e)
+		}
+		switch schema.Type {
+		case jsoncel.Boolean:
+			values = append(values, true, false)
+		case jsoncel.String:
+			for _, s := range strs {
+				values = append(values, s)
+			}
+			values = append(values, "")
+		case jsoncel.Integer, jsoncel.Number:
+			for _, n := range nums {
+				values = append(values, n)
+			}
+			values = append(values, float64(0))
+		case jsoncel.Array:
+			for _, s := range strs {
+				values = append(values, []any{s})
+			}
+			values = append(values, []any{})
+		case jsoncel.Object:
+			values = append(values, map[string]any{})
+		}
+
+		if len(values) > 0 {
+			fields = append(fields, field)
+			candidates = append(candidates, values)
+		}
+	}
+
+	return fields, candidates, nil
+}
+
+// constantsVisitor collects the input fields and constants referenced by checks.
+type constantsVisitor struct {
+	NopVisitor
+	asts       map[string]*cel.Ast
+	referenced []string
+	constants  []*exprpb.Constant
+}
+
+// VisitAction collects the input fields and constants referenced by the 'if' guard of an action.
+func (v *constantsVisitor) VisitAction(s step.Step, a step.Action) error {
+	if s.If == "" {
+		return nil
+	}
+	return v.VisitCheck(s, step.Check{Expression: s.If})
+}
+
+func (v *constantsVisitor) VisitCheck(s step.Step, c step.Check) error {
+	ast, ok := v.asts[s.Hash()]
+	if !ok {
+		return fmt.Errorf("could not find CEL AST for %s", s.Hash())
+	}
+	v.referenced = mergeFields(v.referenced, inputFields(ast.Expr()))
+	v.constants = append(v.constants, exprConstants(ast.Expr())...)
+	return nil
+}
+
+// uniqueNumbers returns the sorted, de-duplicated list of numbers.
+func uniqueNumbers(nums []float64) []float64 {
+	sort.Float64s(nums)
+	var unique []float64
+	for i, n := range nums {
+		if i == 0 || n != nums[i-1] {
+			unique = append(unique, n)
+		}
+	}
+	return unique
+}
+
+// setField sets a dot-separated field in the input, creating any parent objects.
+func setField(input map[string]any, field string, value any) {
+	parts := strings.Split(field, ".")
+	m := input
+	for _, p := range parts[:len(parts)-1] {
+		child, ok := m[p].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			m[p] = child
+		}
+		m = child
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+// exprConstants returns all of the constants in a CEL expression.
+func exprConstants(e *exprpb.Expr) []*exprpb.Constant {
+	if e == nil {
+		return nil
+	}
+
+	var constants []*exprpb.Constant
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		constants = append(constants, k.ConstExpr)
+	case *exprpb.Expr_SelectExpr:
+		constants = append(constants, exprConstants(k.SelectExpr.GetOperand())...)
+	case *exprpb.Expr_CallExpr:
+		constants = append(constants, exprConstants(k.CallExpr.GetTarget())...)
+		for _, a := range k.CallExpr.GetArgs() {
+			constants = append(constants, exprConstants(a)...)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, el := range k.ListExpr.GetElements() {
+			constants = append(constants, exprConstants(el)...)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			constants = append(constants, exprConstants(entry.GetMapKey())...)
+			constants = append(constants, exprConstants(entry.GetValue())...)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		c := k.ComprehensionExpr
+		constants = append(constants, exprConstants(c.GetIterRange())...)
+		constants = append(constants, exprConstants(c.GetLoopCondition())...)
+		constants = append(constants, exprConstants(c.GetLoopStep())...)
+		constants = append(constants, exprConstants(c.GetResult())...)
+	}
+	return constants
+}
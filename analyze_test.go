@@ -0,0 +1,128 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindInput(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"approved": {
+				Type: jsoncel.Boolean,
+			},
+			"group": {
+				Type: jsoncel.Object,
+				Properties: map[string]*jsoncel.Schema{
+					"id": {
+						Type: jsoncel.String,
+					},
+					"size": {
+						Type: jsoncel.Integer,
+					},
+				},
+			},
+		},
+	}
+
+	type testcase struct {
+		name    string
+		program *Program
+		outcome string
+		want    map[string]any
+		wantErr error
+	}
+
+	testcases := []testcase{
+		{
+			name: "no checks",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Outcome("approved"),
+			),
+			outcome: "approved",
+			want:    map[string]any{},
+		},
+		{
+			name: "boolean field",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Check("input.approved"),
+				s.Outcome("approved"),
+			),
+			outcome: "approved",
+			want:    map[string]any{"approved": true},
+		},
+		{
+			name: "nested fields",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Check(`input.group.id == "admins"`),
+				s.Check("input.group.size > 10"),
+				s.Outcome("approved"),
+			),
+			outcome: "approved",
+			want: map[string]any{
+				"group": map[string]any{
+					"id":   "admins",
+					"size": float64(11),
+				},
+			},
+		},
+		{
+			name: "actions are assumed complete",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Action("slack", nil),
+				s.Check("!input.approved"),
+				s.Outcome("approved"),
+			),
+			outcome: "approved",
+			want:    map[string]any{"approved": false},
+		},
+		{
+			name: "always false check",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Check("1 > 2"),
+				s.Outcome("approved"),
+			),
+			outcome: "approved",
+			wantErr: ErrUnreachable,
+		},
+		{
+			name: "contradiction",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Check("input.approved"),
+				s.Check("!input.approved"),
+				s.Outcome("approved"),
+			),
+			outcome: "approved",
+			wantErr: ErrNoInputFound,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.program, InputSchema: schema}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.FindInput("request", tt.outcome)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
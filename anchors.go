@@ -0,0 +1,141 @@
+package glide
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+)
+
+// expandAnchors rewrites a YAML file so that aliases are replaced by
+// a copy of their anchored value, and merge keys ('<<') are replaced by
+// the keys of the merged mappings.
+//
+// Steps are decoded from the YAML nodes of a workflow, so without this the
+// steps reused through an alias would keep the paths of their anchor.
+// After expanding, each use of an anchor has its own nodes, and errors
+// point to where the anchor was used.
+func expandAnchors(f *ast.File) ([]byte, error) {
+	e := anchorExpander{anchors: map[string]any{}}
+
+	var out []byte
+	for i, doc := range f.Docs {
+		v, err := e.value(doc.Body)
+		if err != nil {
+			return nil, err
+		}
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out = append(out, "---\n"...)
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
+
+type anchorExpander struct {
+	// anchors maps anchor names to their expanded values.
+	anchors map[string]any
+}
+
+// value converts a node to a value which can be marshalled.
+// Scalar nodes are kept as they are, so that they are marshalled
+// exactly as they were written.
+func (e *anchorExpander) value(n ast.Node) (any, error) {
+	switch n := n.(type) {
+	case nil:
+		return nil, nil
+	case *ast.AnchorNode:
+		v, err := e.value(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		e.anchors[n.Name.String()] = v
+		return v, nil
+	case *ast.AliasNode:
+		v, ok := e.anchors[n.Value.String()]
+		if !ok {
+			return nil, fmt.Errorf("%s: alias %s refers to an undefined anchor", n.GetPath(), n.Value.String())
+		}
+		return v, nil
+	case *ast.SequenceNode:
+		s := make([]any, len(n.Values))
+		for i, item := range n.Values {
+			v, err := e.value(item)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = v
+		}
+		return s, nil
+	case *ast.MappingNode:
+		return e.mapping(n.Values)
+	case *ast.MappingValueNode:
+		return e.mapping([]*ast.MappingValueNode{n})
+	case *ast.LiteralNode:
+		// the indentation of block scalars depends on where
+		// they were written, so they are converted to strings.
+		return n.Value.Value, nil
+	}
+	return n, nil
+}
+
+// mapping converts the values of a mapping to a yaml.MapSlice,
+// so that the order of the keys is preserved.
+func (e *anchorExpander) mapping(values []*ast.MappingValueNode) (yaml.MapSlice, error) {
+	var m yaml.MapSlice
+	var merged []yaml.MapSlice
+	keys := map[string]bool{}
+
+	for _, mv := range values {
+		v, err := e.value(mv.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := mv.Key.(*ast.MergeKeyNode); ok {
+			// the value of a merge key is a mapping, or a sequence of mappings.
+			switch v := v.(type) {
+			case yaml.MapSlice:
+				merged = append(merged, v)
+			case []any:
+				for _, item := range v {
+					ms, ok := item.(yaml.MapSlice)
+					if !ok {
+						return nil, fmt.Errorf("%s: merge key must refer to a mapping", mv.GetPath())
+					}
+					merged = append(merged, ms)
+				}
+			default:
+				return nil, fmt.Errorf("%s: merge key must refer to a mapping", mv.GetPath())
+			}
+			continue
+		}
+
+		// the encoder only supports string keys.
+		key := mv.Key.String()
+		if sn, ok := mv.Key.(ast.ScalarNode); ok {
+			key = fmt.Sprint(sn.GetValue())
+		}
+		keys[key] = true
+		m = append(m, yaml.MapItem{Key: key, Value: v})
+	}
+
+	// keys written in the mapping take precedence over merged keys, and
+	// earlier merged mappings take precedence over later ones.
+	for _, ms := range merged {
+		for _, item := range ms {
+			k := item.Key.(string)
+			if keys[k] {
+				continue
+			}
+			keys[k] = true
+			m = append(m, item)
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,511 @@
+package glide
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/goccy/go-yaml"
+)
+
+// StateMachineOptions configures how a state machine is converted into a workflow.
+type StateMachineOptions struct {
+	// Start is the ID of the start node which each pass begins at, e.g. 'request'.
+	Start string
+
+	// Succeed is the ID of the outcome node which Succeed states lead to, e.g. 'approved'.
+	// States with an 'End' field are treated as Succeed states.
+	Succeed string
+
+	// Fail is the ID of the outcome node which Fail states lead to. If it's empty,
+	// paths which end in a Fail state aren't converted, as a workflow which doesn't
+	// reach an outcome isn't approved.
+	Fail string
+}
+
+// aslStateMachine is a state machine in the Amazon States Language.
+type aslStateMachine struct {
+	Comment string              `json:"Comment"`
+	StartAt string              `json:"StartAt"`
+	States  map[string]aslState `json:"States"`
+}
+
+type aslState struct {
+	Type     string            `json:"Type"`
+	Comment  string            `json:"Comment"`
+	Next     string            `json:"Next"`
+	End      bool              `json:"End"`
+	Choices  []json.RawMessage `json:"Choices"`
+	Default  string            `json:"Default"`
+	Branches []aslStateMachine `json:"Branches"`
+}
+
+// aslPath is a sequence of workflow steps leading to a terminal state.
+type aslPath struct {
+	steps []any
+	// terminal is the name of the terminal state.
+	terminal string
+	// failed is true if the terminal state is a Fail state.
+	failed bool
+}
+
+// ConvertStateMachine converts a state machine written in the Amazon States Language,
+// as used by AWS Step Functions, into a workflow YAML definition. This allows approval
+// state machines to be migrated to Glide without rewriting them by hand.
+//
+// A subset of the language is supported: Choice, Parallel, Pass, Succeed and Fail states.
+// Each path through the state machine from 'StartAt' to a Succeed or Fail state becomes
+// a pass, named after the state it ends at. The rules of Choice states become checks,
+// and Parallel states become parallel steps which are complete once every branch has
+// succeeded. Other states, like Task and Wait states, and loops, can't be converted.
+//
+// Choice rules refer to the input with JSONPath, e.g. '$.group', which is converted to
+// 'input.group'. Timestamp comparisons can't be converted.
+func ConvertStateMachine(data []byte, opts StateMachineOptions) ([]byte, error) {
+	if opts.Start == "" || opts.Succeed == "" {
+		return nil, fmt.Errorf("a start node and an outcome for Succeed states must be provided")
+	}
+
+	var sm aslStateMachine
+	err := json.Unmarshal(data, &sm)
+	if err != nil {
+		return nil, fmt.Errorf("parsing state machine: %w", err)
+	}
+
+	c := aslConverter{opts: opts}
+	paths, err := c.paths(sm, sm.StartAt, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	passes := yaml.MapSlice{}
+	used := map[string]bool{}
+	for _, p := range paths {
+		outcome := opts.Succeed
+		if p.failed {
+			if opts.Fail == "" {
+				continue
+			}
+			outcome = opts.Fail
+		}
+
+		name := p.terminal
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s_%d", p.terminal, i)
+		}
+		used[name] = true
+
+		steps := []any{yaml.MapSlice{{Key: "start", Value: opts.Start}}}
+		steps = append(steps, p.steps...)
+		steps = append(steps, yaml.MapSlice{{Key: "outcome", Value: outcome}})
+		passes = append(passes, yaml.MapItem{Key: name, Value: yaml.MapSlice{{Key: "steps", Value: steps}}})
+	}
+	if len(passes) == 0 {
+		return nil, fmt.Errorf("the state machine has no paths to a Succeed state")
+	}
+
+	return yaml.MarshalWithOptions(yaml.MapSlice{{Key: "workflow", Value: passes}}, yaml.IndentSequence(true))
+}
+
+// ImportStateMachine converts a state machine written in the Amazon States Language
+// into a program which can be compiled. See ConvertStateMachine.
+func ImportStateMachine(data []byte, d dialect.Dialect, opts StateMachineOptions) (*Program, error) {
+	workflow, err := ConvertStateMachine(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(workflow, d)
+}
+
+type aslConverter struct {
+	opts StateMachineOptions
+}
+
+// paths returns the paths from a state to the terminal states of the state machine.
+// visiting are the states on the current path, which are used to detect loops.
+func (c *aslConverter) paths(sm aslStateMachine, name string, visiting map[string]bool) ([]aslPath, error) {
+	if visiting[name] {
+		return nil, fmt.Errorf("state %s is part of a loop: loops can't be converted", name)
+	}
+	s, ok := sm.States[name]
+	if !ok {
+		return nil, fmt.Errorf("state %q does not exist", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	// next returns the paths which follow the state, prefixed by the steps.
+	next := func(steps ...any) ([]aslPath, error) {
+		if s.End {
+			return []aslPath{{steps: steps, terminal: name}}, nil
+		}
+		if s.Next == "" {
+			return nil, fmt.Errorf("state %s must have 'Next' or 'End'", name)
+		}
+		rest, err := c.paths(sm, s.Next, visiting)
+		if err != nil {
+			return nil, err
+		}
+		return prefixPaths(steps, rest), nil
+	}
+
+	switch s.Type {
+	case "Succeed":
+		return []aslPath{{terminal: name}}, nil
+	case "Fail":
+		return []aslPath{{terminal: name, failed: true}}, nil
+	case "Pass":
+		return next()
+	case "Choice":
+		return c.choicePaths(sm, name, s, visiting)
+	case "Parallel":
+		var branches []any
+		for i, b := range s.Branches {
+			branch, ok, err := c.branch(b)
+			if err != nil {
+				return nil, fmt.Errorf("branch %d of state %s: %w", i, name, err)
+			}
+			if !ok {
+				// a branch which never succeeds fails the parallel state.
+				return []aslPath{{terminal: name, failed: true}}, nil
+			}
+			if branch != nil {
+				branches = append(branches, branch)
+			}
+		}
+		switch len(branches) {
+		case 0:
+			return next()
+		case 1:
+			return next(branches[0])
+		}
+		return next(yaml.MapSlice{{Key: "parallel", Value: branches}})
+	}
+	return nil, fmt.Errorf("state %s has type %s: only Choice, Parallel, Pass, Succeed and Fail states can be converted", name, s.Type)
+}
+
+// choicePaths returns the paths through a Choice state. Rules are evaluated in
+// order, so each rule's check includes the negation of the rules before it.
+// If no rule matches and there is no default, the state machine fails.
+func (c *aslConverter) choicePaths(sm aslStateMachine, name string, s aslState, visiting map[string]bool) ([]aslPath, error) {
+	var paths []aslPath
+	var earlier []string
+	for i, raw := range s.Choices {
+		var rule map[string]json.RawMessage
+		err := json.Unmarshal(raw, &rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d of state %s: %w", i, name, err)
+		}
+		var nextState string
+		err = json.Unmarshal(rule["Next"], &nextState)
+		if err != nil || nextState == "" {
+			return nil, fmt.Errorf("rule %d of state %s must have a 'Next' state", i, name)
+		}
+		delete(rule, "Next")
+
+		expr, err := aslCondition(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d of state %s: %w", i, name, err)
+		}
+
+		rest, err := c.paths(sm, nextState, visiting)
+		if err != nil {
+			return nil, err
+		}
+		check := choiceCheck(name, s.Comment, append(append([]string{}, earlier...), expr))
+		paths = append(paths, prefixPaths([]any{check}, rest)...)
+		earlier = append(earlier, "!("+expr+")")
+	}
+
+	if s.Default == "" {
+		return append(paths, aslPath{terminal: name, failed: true}), nil
+	}
+	rest, err := c.paths(sm, s.Default, visiting)
+	if err != nil {
+		return nil, err
+	}
+	if len(earlier) == 0 {
+		return append(paths, rest...), nil
+	}
+	check := choiceCheck(name+" (default)", s.Comment, earlier)
+	return append(paths, prefixPaths([]any{check}, rest)...), nil
+}
+
+// choiceCheck returns a check step for a rule of a Choice state, which is
+// described by the state's comment.
+func choiceCheck(name, comment string, exprs []string) yaml.MapSlice {
+	check := yaml.MapSlice{{Key: "name", Value: name}}
+	if comment != "" {
+		check = append(check, yaml.MapItem{Key: "description", Value: comment})
+	}
+	// the expression is formatted to remove parentheses which aren't needed.
+	expr := strings.Join(exprs, " && ")
+	if formatted, err := FormatExpression(expr); err == nil {
+		expr = formatted
+	}
+	return append(check, yaml.MapItem{Key: "check", Value: expr})
+}
+
+// branch converts a branch of a Parallel state into a step. ok is false if the branch
+// never succeeds, and the step is nil if the branch always succeeds.
+func (c *aslConverter) branch(sm aslStateMachine) (step any, ok bool, err error) {
+	paths, err := c.paths(sm, sm.StartAt, map[string]bool{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	var branches []any
+	for _, p := range paths {
+		if p.failed {
+			continue
+		}
+		if len(p.steps) == 0 {
+			// the branch can succeed without any conditions.
+			return nil, true, nil
+		}
+		if len(p.steps) == 1 {
+			branches = append(branches, p.steps[0])
+			continue
+		}
+		branches = append(branches, yaml.MapSlice{{Key: "steps", Value: p.steps}})
+	}
+
+	switch len(branches) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		if seq, ok := branches[0].(yaml.MapSlice); ok && seq[0].Key == "steps" {
+			return yaml.MapSlice{{Key: "parallel", Value: branches}}, true, nil
+		}
+		return branches[0], true, nil
+	}
+	// the branch succeeds if any of its paths succeeds.
+	return yaml.MapSlice{{Key: "parallel", Value: branches}, {Key: "join", Value: "any"}}, true, nil
+}
+
+func prefixPaths(steps []any, paths []aslPath) []aslPath {
+	out := make([]aslPath, len(paths))
+	for i, p := range paths {
+		p.steps = append(append([]any{}, steps...), p.steps...)
+		out[i] = p
+	}
+	return out
+}
+
+// aslComparisons maps the comparison operators of choice rules to CEL.
+var aslComparisons = map[string]string{
+	"StringEquals":             "==",
+	"StringLessThan":           "<",
+	"StringGreaterThan":        ">",
+	"StringLessThanEquals":     "<=",
+	"StringGreaterThanEquals":  ">=",
+	"NumericEquals":            "==",
+	"NumericLessThan":          "<",
+	"NumericGreaterThan":       ">",
+	"NumericLessThanEquals":    "<=",
+	"NumericGreaterThanEquals": ">=",
+	"BooleanEquals":            "==",
+}
+
+// aslCondition converts a choice rule, without its 'Next' field, into a CEL expression.
+func aslCondition(rule map[string]json.RawMessage) (string, error) {
+	if raw, ok := rule["And"]; ok {
+		return aslConditions(raw, " && ")
+	}
+	if raw, ok := rule["Or"]; ok {
+		return aslConditions(raw, " || ")
+	}
+	if raw, ok := rule["Not"]; ok {
+		var inner map[string]json.RawMessage
+		err := json.Unmarshal(raw, &inner)
+		if err != nil {
+			return "", err
+		}
+		expr, err := aslCondition(inner)
+		if err != nil {
+			return "", err
+		}
+		return "!(" + expr + ")", nil
+	}
+
+	var variable string
+	err := json.Unmarshal(rule["Variable"], &variable)
+	if err != nil || variable == "" {
+		return "", fmt.Errorf("rule must have a 'Variable'")
+	}
+	v, err := aslPathExpr(variable)
+	if err != nil {
+		return "", err
+	}
+
+	// the rule has a single operator, as well as the variable.
+	var ops []string
+	for k := range rule {
+		if k != "Variable" {
+			ops = append(ops, k)
+		}
+	}
+	sort.Strings(ops)
+	if len(ops) != 1 {
+		return "", fmt.Errorf("rule must have exactly one comparison operator, found %d", len(ops))
+	}
+	op, raw := ops[0], rule[ops[0]]
+
+	// operators ending in 'Path' compare the variable to another path in the input.
+	if base := strings.TrimSuffix(op, "Path"); base != op {
+		if cmp, ok := aslComparisons[base]; ok {
+			var other string
+			err := json.Unmarshal(raw, &other)
+			if err != nil {
+				return "", fmt.Errorf("%s must be a path: %w", op, err)
+			}
+			o, err := aslPathExpr(other)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s %s %s", v, cmp, o), nil
+		}
+	}
+
+	if cmp, ok := aslComparisons[op]; ok {
+		var value any
+		err := json.Unmarshal(raw, &value)
+		if err != nil {
+			return "", err
+		}
+		literal, err := celLiteral(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		return fmt.Sprintf("%s %s %s", v, cmp, literal), nil
+	}
+
+	var flag, negate bool
+	switch op {
+	case "IsPresent", "IsNull", "IsString", "IsNumeric", "IsBoolean":
+		err := json.Unmarshal(raw, &flag)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a boolean: %w", op, err)
+		}
+		negate = !flag
+	}
+
+	var expr string
+	switch op {
+	case "IsPresent":
+		if !strings.Contains(v, ".") {
+			return "", fmt.Errorf("IsPresent can only be used on a field of the input")
+		}
+		expr = "has(" + v + ")"
+	case "IsNull":
+		expr = v + " == null"
+	case "IsString":
+		expr = "type(" + v + ") == string"
+	case "IsNumeric":
+		expr = "(type(" + v + ") == int || type(" + v + ") == double)"
+	case "IsBoolean":
+		expr = "type(" + v + ") == bool"
+	case "StringMatches":
+		var pattern string
+		err := json.Unmarshal(raw, &pattern)
+		if err != nil {
+			return "", fmt.Errorf("StringMatches must be a string: %w", err)
+		}
+		return fmt.Sprintf("%s.matches(%s)", v, strconv.Quote(globRegex(pattern))), nil
+	default:
+		return "", fmt.Errorf("operator %s can't be converted", op)
+	}
+	if negate {
+		return "!(" + expr + ")", nil
+	}
+	return expr, nil
+}
+
+// aslConditions converts the rules of an 'And' or 'Or' rule, joined by the operator.
+func aslConditions(raw json.RawMessage, op string) (string, error) {
+	var rules []map[string]json.RawMessage
+	err := json.Unmarshal(raw, &rules)
+	if err != nil {
+		return "", err
+	}
+	var exprs []string
+	for _, r := range rules {
+		expr, err := aslCondition(r)
+		if err != nil {
+			return "", err
+		}
+		exprs = append(exprs, "("+expr+")")
+	}
+	if len(exprs) == 0 {
+		return "", fmt.Errorf("'And' and 'Or' rules must contain at least one rule")
+	}
+	return strings.Join(exprs, op), nil
+}
+
+// aslSegmentRegex matches a segment of a JSONPath: a field, a quoted field or an index.
+var aslSegmentRegex = regexp.MustCompile(`^(?:\.([a-zA-Z_][a-zA-Z0-9_]*)|\['([^']*)'\]|\[([0-9]+)\])`)
+
+// aslPathExpr converts a JSONPath to the input, e.g. '$.group.id', into a CEL expression.
+func aslPathExpr(path string) (string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return "", fmt.Errorf("path %q must start with '$'", path)
+	}
+	expr := "input"
+	rest := path[1:]
+	for rest != "" {
+		m := aslSegmentRegex.FindStringSubmatch(rest)
+		if m == nil {
+			return "", fmt.Errorf("path %q can't be converted: only fields and indexes are supported", path)
+		}
+		switch {
+		case m[1] != "":
+			expr += "." + m[1]
+		case m[3] != "":
+			expr += "[" + m[3] + "]"
+		default:
+			expr += "[" + strconv.Quote(m[2]) + "]"
+		}
+		rest = rest[len(m[0]):]
+	}
+	return expr, nil
+}
+
+// celLiteral converts a JSON value into a CEL literal.
+func celLiteral(v any) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return strconv.Quote(t), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case float64:
+		if t == float64(int64(t)) {
+			return strconv.FormatInt(int64(t), 10), nil
+		}
+		return strconv.FormatFloat(t, 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("value %v must be a string, number or boolean", v)
+}
+
+// globRegex converts a StringMatches pattern, where '*' matches any characters
+// and '\*' matches a literal '*', into a regular expression.
+func globRegex(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			b.WriteString(regexp.QuoteMeta(pattern[i+1 : i+2]))
+			i++
+		case pattern[i] == '*':
+			b.WriteString(".*")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
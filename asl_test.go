@@ -0,0 +1,215 @@
+package glide
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/stretchr/testify/assert"
+)
+
+const testStateMachine = `{
+  "StartAt": "CheckGroup",
+  "States": {
+    "CheckGroup": {
+      "Type": "Choice",
+      "Comment": "Admins are approved automatically",
+      "Choices": [
+        {"Variable": "$.group", "StringEquals": "admins", "Next": "Approved"},
+        {"Variable": "$.duration", "NumericLessThanEquals": 3600, "Next": "Checks"}
+      ],
+      "Default": "Denied"
+    },
+    "Checks": {
+      "Type": "Parallel",
+      "Branches": [
+        {"StartAt": "OnCall", "States": {
+          "OnCall": {"Type": "Choice", "Choices": [{"Variable": "$.on_call", "BooleanEquals": true, "Next": "Done"}]},
+          "Done": {"Type": "Succeed"}
+        }},
+        {"StartAt": "Env", "States": {
+          "Env": {"Type": "Choice", "Choices": [{"Not": {"Variable": "$.env", "StringMatches": "prod-*"}, "Next": "Ok"}]},
+          "Ok": {"Type": "Pass", "End": true}
+        }}
+      ],
+      "Next": "Approved"
+    },
+    "Approved": {"Type": "Succeed"},
+    "Denied": {"Type": "Fail"}
+  }
+}`
+
+func TestConvertStateMachine(t *testing.T) {
+	got, err := ConvertStateMachine([]byte(testStateMachine), StateMachineOptions{Start: "request", Succeed: "approved", Fail: "denied"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `workflow:
+  Approved:
+    steps:
+      - start: request
+      - name: CheckGroup
+        description: Admins are approved automatically
+        check: input.group == "admins"
+      - outcome: approved
+  Approved_2:
+    steps:
+      - start: request
+      - name: CheckGroup
+        description: Admins are approved automatically
+        check: "!(input.group == \"admins\") && input.duration <= 3600"
+      - parallel:
+          - name: OnCall
+            check: input.on_call == true
+          - name: Env
+            check: "!input.env.matches(\"^prod-.*$\")"
+      - outcome: approved
+  Denied:
+    steps:
+      - start: request
+      - name: CheckGroup (default)
+        description: Admins are approved automatically
+        check: "!(input.group == \"admins\") && !(input.duration <= 3600)"
+      - outcome: denied
+`
+	assert.Equal(t, want, string(got))
+}
+
+func TestImportStateMachine(t *testing.T) {
+	d := dialect.Dialect{
+		Nodes: map[string]node.Node{
+			"request":  {Type: node.Start},
+			"approved": {Type: node.Outcome, Priority: 1},
+			"denied":   {Type: node.Outcome, Priority: 2},
+		},
+	}
+	p, err := ImportStateMachine([]byte(testStateMachine), d, StateMachineOptions{Start: "request", Succeed: "approved", Fail: "denied"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Compiler{Program: p, InputSchema: &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"group":    {Type: jsoncel.String},
+			"duration": {Type: jsoncel.Integer},
+			"on_call":  {Type: jsoncel.Boolean},
+			"env":      {Type: jsoncel.String},
+		},
+	}}
+	g, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input map[string]any
+		want  string
+	}{
+		{
+			name:  "admins",
+			input: map[string]any{"group": "admins", "duration": 7200, "on_call": false, "env": "prod-1"},
+			want:  "approved",
+		},
+		{
+			name:  "short and on call",
+			input: map[string]any{"group": "devs", "duration": 600, "on_call": true, "env": "dev-1"},
+			want:  "approved",
+		},
+		{
+			name:  "short in prod",
+			input: map[string]any{"group": "devs", "duration": 600, "on_call": true, "env": "prod-1"},
+			want:  "",
+		},
+		{
+			name:  "long",
+			input: map[string]any{"group": "devs", "duration": 7200, "on_call": true, "env": "dev-1"},
+			want:  "denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, res.Outcome)
+		})
+	}
+}
+
+func TestConvertStateMachine_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{
+			name: "task",
+			give: `{"StartAt": "A", "States": {"A": {"Type": "Task", "Resource": "arn:aws:lambda:approve", "End": true}}}`,
+			want: "state A has type Task: only Choice, Parallel, Pass, Succeed and Fail states can be converted",
+		},
+		{
+			name: "loop",
+			give: `{"StartAt": "A", "States": {"A": {"Type": "Pass", "Next": "B"}, "B": {"Type": "Choice", "Choices": [{"Variable": "$.a", "BooleanEquals": true, "Next": "A"}], "Default": "C"}, "C": {"Type": "Succeed"}}}`,
+			want: "state A is part of a loop: loops can't be converted",
+		},
+		{
+			name: "timestamp",
+			give: `{"StartAt": "A", "States": {"A": {"Type": "Choice", "Choices": [{"Variable": "$.at", "TimestampLessThan": "2023-01-01T00:00:00Z", "Next": "B"}]}, "B": {"Type": "Succeed"}}}`,
+			want: "rule 0 of state A: operator TimestampLessThan can't be converted",
+		},
+		{
+			name: "missing state",
+			give: `{"StartAt": "A", "States": {"A": {"Type": "Pass", "Next": "B"}}}`,
+			want: `state "B" does not exist`,
+		},
+		{
+			name: "never succeeds",
+			give: `{"StartAt": "A", "States": {"A": {"Type": "Fail"}}}`,
+			want: "the state machine has no paths to a Succeed state",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConvertStateMachine([]byte(tt.give), StateMachineOptions{Start: "request", Succeed: "approved"})
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestASLCondition(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{name: "string", give: `{"Variable": "$.group.id", "StringEquals": "admins"}`, want: `input.group.id == "admins"`},
+		{name: "number", give: `{"Variable": "$.level", "NumericGreaterThan": 2.5}`, want: `input.level > 2.5`},
+		{name: "path", give: `{"Variable": "$.approver", "StringEqualsPath": "$.requestor"}`, want: `input.approver == input.requestor`},
+		{name: "index", give: `{"Variable": "$.groups[0]", "StringEquals": "a"}`, want: `input.groups[0] == "a"`},
+		{name: "quoted field", give: `{"Variable": "$['on call']", "BooleanEquals": true}`, want: `input["on call"] == true`},
+		{name: "not present", give: `{"Variable": "$.ticket", "IsPresent": false}`, want: `!(has(input.ticket))`},
+		{name: "null", give: `{"Variable": "$.ticket", "IsNull": true}`, want: `input.ticket == null`},
+		{name: "matches", give: `{"Variable": "$.env", "StringMatches": "prod-\\*-*"}`, want: `input.env.matches("^prod-\\*-.*$")`},
+		{name: "or", give: `{"Or": [{"Variable": "$.a", "BooleanEquals": true}, {"Variable": "$.b", "BooleanEquals": true}]}`, want: `(input.a == true) || (input.b == true)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rule map[string]json.RawMessage
+			err := json.Unmarshal([]byte(tt.give), &rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := aslCondition(rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
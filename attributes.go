@@ -0,0 +1,73 @@
+package glide
+
+import (
+	"fmt"
+)
+
+// SetNodeAttribute sets a rendering attribute of the step with the hash, such as
+// its 'color' or 'shape'. Attributes are written as DOT attributes by Render,
+// taking precedence over the attributes Glide sets, and are included in the
+// 'attributes' field of the node by ExportJSON.
+//
+// Setting an attribute modifies the graph, so a graph which is shared, such as
+// a cached compiled workflow, should be copied with CloneForRender first.
+func (g *Graph) SetNodeAttribute(hash, key, value string) error {
+	if _, err := g.store.step(hash); err != nil {
+		return fmt.Errorf("step %s not found", hash)
+	}
+	if g.nodeAttributes == nil {
+		g.nodeAttributes = map[string]map[string]string{}
+	}
+	if g.nodeAttributes[hash] == nil {
+		g.nodeAttributes[hash] = map[string]string{}
+	}
+	g.nodeAttributes[hash][key] = value
+	return nil
+}
+
+// NodeAttributes returns a copy of the rendering attributes
+// set on the step with the hash by SetNodeAttribute.
+func (g *Graph) NodeAttributes(hash string) map[string]string {
+	return copyAttributes(g.nodeAttributes[hash])
+}
+
+// CloneForRender returns a copy of the graph for rendering, whose attributes
+// can be set without affecting the graph or other copies of it. The copy can
+// be rendered by freezing it, e.g. g.CloneForRender().Freeze().Render(w, res).
+//
+// The copy shares the steps and compiled programs of the graph,
+// which must not be modified, so cloning is cheap.
+func (g *Graph) CloneForRender() *Graph {
+	c := *g
+	c.nodeAttributes = map[string]map[string]string{}
+	for k, attrs := range g.nodeAttributes {
+		c.nodeAttributes[k] = copyAttributes(attrs)
+	}
+	return &c
+}
+
+// expandedFor returns the graph which was executed for a result whose
+// execution was expanded for the input, with the rendering attributes of g,
+// and the result to render on it.
+func (g *Graph) expandedFor(res *Result) (*Graph, *Result) {
+	if len(g.nodeAttributes) == 0 {
+		return res.graph, res
+	}
+	eg := *res.graph
+	eg.nodeAttributes = g.nodeAttributes
+
+	r := *res
+	r.graph = &eg
+	return &eg, &r
+}
+
+func copyAttributes(attrs map[string]string) map[string]string {
+	if attrs == nil {
+		return nil
+	}
+	out := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		out[k] = v
+	}
+	return out
+}
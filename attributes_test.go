@@ -0,0 +1,82 @@
+package glide
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_SetNodeAttribute(t *testing.T) {
+	g, err := (&Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check("true"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone := g.CloneForRender()
+	err = clone.SetNodeAttribute("default.1", "color", "red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = clone.SetNodeAttribute("approved", "fillcolor", `"gold"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = clone.Freeze().Render(&buf, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, buf.String(), `color="red"`)
+	// attributes set by the caller take precedence, and are escaped.
+	assert.Contains(t, buf.String(), `fillcolor="\"gold\""`)
+
+	// the original graph isn't modified.
+	assert.Nil(t, g.NodeAttributes("default.1"))
+	buf.Reset()
+	err = g.Freeze().Render(&buf, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, buf.String(), `color="red"`)
+
+	// clones of the clone have their own attributes.
+	other := clone.CloneForRender()
+	err = other.SetNodeAttribute("default.1", "color", "blue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"color": "red"}, clone.NodeAttributes("default.1"))
+
+	out, err := clone.ExportJSON(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var export exportGraph
+	err = json.Unmarshal(out, &export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range export.Elements.Nodes {
+		if n.Data.ID == "default.1" {
+			assert.Equal(t, map[string]string{"color": "red"}, n.Data.Attributes)
+		}
+	}
+
+	err = clone.SetNodeAttribute("default.9", "color", "red")
+	assert.EqualError(t, err, "step default.9 not found")
+}
@@ -0,0 +1,123 @@
+package glide
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// BacktestInput is a recorded input to backtest workflows with.
+type BacktestInput struct {
+	// Name identifies the input in the report, such as the file it was read from.
+	Name  string
+	Input map[string]any
+}
+
+// BacktestOutcome is the outcome of executing a workflow with an input.
+type BacktestOutcome struct {
+	// Outcome is the ID of the outcome, or empty if no outcome was reached.
+	Outcome string
+	// Failed is true if the workflow failed.
+	Failed bool
+}
+
+// String returns the outcome ID, or '<failed>' or '<running>'
+// if the workflow failed or has no outcome.
+func (o BacktestOutcome) String() string {
+	if o.Failed {
+		return "<failed>"
+	}
+	if o.Outcome == "" {
+		return "<running>"
+	}
+	return o.Outcome
+}
+
+// BacktestTransition is a change from the outcome of the old
+// workflow to the outcome of the new workflow.
+type BacktestTransition struct {
+	Old BacktestOutcome
+	New BacktestOutcome
+}
+
+func (t BacktestTransition) String() string {
+	return fmt.Sprintf("%s -> %s", t.Old, t.New)
+}
+
+// OutcomeChange is an input whose outcome is different
+// when it's executed with the new workflow.
+type OutcomeChange struct {
+	// Input is the name of the input.
+	Input string
+	BacktestTransition
+}
+
+// BacktestReport is the result of backtesting a change to a workflow.
+type BacktestReport struct {
+	// Total is the number of inputs which were executed.
+	Total int
+
+	// Changed are the inputs whose outcome changed, in the order of the inputs.
+	Changed []OutcomeChange
+
+	// Counts are the number of inputs with each change of outcome.
+	Counts map[BacktestTransition]int
+}
+
+// Transitions returns the changes of outcome, ordered from the
+// most common to the least, so that reports are stable.
+func (r *BacktestReport) Transitions() []BacktestTransition {
+	var out []BacktestTransition
+	for t := range r.Counts {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if r.Counts[out[i]] != r.Counts[out[j]] {
+			return r.Counts[out[i]] > r.Counts[out[j]]
+		}
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
+// Backtest executes an old and a new version of a workflow with each of
+// the inputs, and reports the inputs whose outcome is different with the
+// new workflow. It can be used to estimate the impact of a policy change
+// on past requests before rolling it out.
+//
+// Steps which can't be evaluated are treated as they are by Execute,
+// so an input with a step error still has an outcome.
+func Backtest(oldWorkflow, newWorkflow *Compiled, start string, inputs []BacktestInput, opts ...ExecuteOption) (*BacktestReport, error) {
+	report := BacktestReport{Counts: map[BacktestTransition]int{}}
+
+	for _, in := range inputs {
+		before, err := backtestOutcome(oldWorkflow, start, in.Input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("executing the old workflow with %s: %w", in.Name, err)
+		}
+		after, err := backtestOutcome(newWorkflow, start, in.Input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("executing the new workflow with %s: %w", in.Name, err)
+		}
+
+		report.Total++
+		if before == after {
+			continue
+		}
+
+		t := BacktestTransition{Old: before, New: after}
+		report.Changed = append(report.Changed, OutcomeChange{Input: in.Name, BacktestTransition: t})
+		report.Counts[t]++
+	}
+
+	return &report, nil
+}
+
+func backtestOutcome(c *Compiled, start string, input map[string]any, opts []ExecuteOption) (BacktestOutcome, error) {
+	res, err := c.Execute(start, input, opts...)
+	var ee *ExecutionError
+	if err != nil && !errors.As(err, &ee) {
+		return BacktestOutcome{}, err
+	}
+	return BacktestOutcome{Outcome: res.Outcome, Failed: res.Failed}, nil
+}
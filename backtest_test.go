@@ -0,0 +1,76 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBacktest(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"group":  {Type: jsoncel.String},
+			"oncall": {Type: jsoncel.Boolean},
+		},
+	}
+
+	oldWorkflow, err := (&Compiler{Program: SimpleProgram(
+		s.Start("request"),
+		s.Check(`input.group == "admins"`),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	), InputSchema: schema}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the new workflow also requires admins to be on call.
+	newWorkflow, err := (&Compiler{Program: SimpleProgram(
+		s.Start("request"),
+		s.Check(`input.group == "admins" && input.oncall`),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	), InputSchema: schema}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []BacktestInput{
+		{Name: "a.json", Input: map[string]any{"group": "admins", "oncall": true}},
+		{Name: "b.json", Input: map[string]any{"group": "admins", "oncall": false}},
+		{Name: "c.json", Input: map[string]any{"group": "developers", "oncall": true}},
+		{Name: "d.json", Input: map[string]any{"group": "admins", "oncall": false}},
+	}
+
+	got, err := Backtest(oldWorkflow, newWorkflow, "request", inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revoked := BacktestTransition{
+		Old: BacktestOutcome{Outcome: "approved"},
+		New: BacktestOutcome{},
+	}
+	assert.Equal(t, 4, got.Total)
+	assert.Equal(t, []OutcomeChange{
+		{Input: "b.json", BacktestTransition: revoked},
+		{Input: "d.json", BacktestTransition: revoked},
+	}, got.Changed)
+	assert.Equal(t, map[BacktestTransition]int{revoked: 2}, got.Counts)
+	assert.Equal(t, []BacktestTransition{revoked}, got.Transitions())
+	assert.Equal(t, "approved -> <running>", revoked.String())
+}
+
+func TestBacktest_Error(t *testing.T) {
+	g, err := (&Compiler{Program: SimpleProgram(
+		s.Start("request"),
+		s.Outcome("approved"),
+	)}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Backtest(g, g, "approved", []BacktestInput{{Name: "a.json"}})
+	assert.EqualError(t, err, "executing the old workflow with a.json: provided start approved was not a start node (got outcome)")
+}
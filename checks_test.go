@@ -0,0 +1,128 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute_Checks(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: admin
+        check: input.admin == true
+      - id: short
+        check: input.duration < 4
+      - outcome: approved
+  notify:
+    steps:
+      - start: request
+      - id: notify
+        action: my_action
+        if: input.duration > 8
+      - outcome: notified
+  named:
+    steps:
+      - start: request
+      - id: name
+        check: input.name == "bob"
+      - outcome: denied
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"admin":    {Type: jsoncel.Boolean},
+			"duration": {Type: jsoncel.Integer},
+			"name":     {Type: jsoncel.String},
+		},
+	}
+	g, err := (&Compiler{Program: p, InputSchema: schema}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input map[string]any
+		opts  []ExecuteOption
+		want  map[string]CheckValue
+	}{
+		{
+			name:  "true",
+			input: map[string]any{"admin": true, "duration": 12, "name": "bob"},
+			want: map[string]CheckValue{
+				"default.admin": CheckTrue,
+				"default.short": CheckFalse,
+				"notify.notify": CheckTrue,
+				"named.name":    CheckTrue,
+			},
+		},
+		{
+			// checks after a check which is false are never evaluated.
+			name:  "never evaluated",
+			input: map[string]any{"admin": false, "duration": 2, "name": "alice"},
+			want: map[string]CheckValue{
+				"default.admin": CheckFalse,
+				"notify.notify": CheckFalse,
+				"named.name":    CheckFalse,
+			},
+		},
+		{
+			name:  "unknown",
+			input: map[string]any{"admin": true, "name": "bob"},
+			opts:  []ExecuteOption{WithPartialInput()},
+			want: map[string]CheckValue{
+				"default.admin": CheckTrue,
+				"default.short": CheckUnknown,
+				"notify.notify": CheckUnknown,
+				"named.name":    CheckTrue,
+			},
+		},
+		{
+			name:  "error",
+			input: map[string]any{"admin": false},
+			want: map[string]CheckValue{
+				"default.admin": CheckFalse,
+				"notify.notify": CheckError,
+				"named.name":    CheckError,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := g.Execute("request", tt.input, tt.opts...)
+			if !assert.NotNil(t, res) {
+				return
+			}
+
+			got := map[string]CheckValue{}
+			for k, c := range res.Checks {
+				got[k] = c.Value
+				assert.Equal(t, c.Value == CheckError, c.Err != nil, k)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	res, err := g.Execute("request", map[string]any{"admin": true, "duration": 12, "name": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "input.admin == true", res.Checks["default.admin"].Expression)
+	assert.Equal(t, "input.duration > 8", res.Checks["notify.notify"].Expression)
+}
+
+func TestCheckValue_Zero(t *testing.T) {
+	var c EvaluatedCheck
+	for _, v := range []CheckValue{CheckTrue, CheckFalse, CheckUnknown, CheckError} {
+		assert.NotEqual(t, v, c.Value)
+	}
+	assert.Equal(t, "", c.Value.String())
+}
@@ -0,0 +1,144 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/urfave/cli/v2"
+)
+
+var Analyze = cli.Command{
+	Name:  "analyze",
+	Usage: "find an input which reaches an outcome, or list the input fields referenced by checks",
+	Description: `With --fields, the input fields referenced by the workflow's checks are listed
+with their inferred types, and fields which are referenced but not declared in the
+schema, or declared but not referenced by any check, are reported.`,
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow YAML file to compile, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format, as a path or URL", Required: true},
+		&cli.StringFlag{Name: "outcome", Usage: "the outcome to find an input for"},
+		&cli.BoolFlag{Name: "fields", Usage: "list the input fields referenced by checks, rather than finding an input"},
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		f := c.Path("file")
+		schemaFile := c.Path("schema")
+		outcome := c.String("outcome")
+		if outcome == "" && !c.Bool("fields") {
+			return errors.New("either --outcome or --fields must be provided")
+		}
+
+		data, err := readSource(c.Context, f)
+		if err != nil {
+			return err
+		}
+
+		unmarshalOpts, err := unmarshalOptions(c)
+		if err != nil {
+			return err
+		}
+
+		p, err := glide.Unmarshal(data, cf.Dialect, unmarshalOpts...)
+
+		var ne noderr.NodeError
+		if errors.As(err, &ne) {
+			clio.Infof("node error at: %s", ne.Node.GetPath())
+			source, printErr := ne.PrettyPrint(data)
+			if printErr != nil {
+				clio.Errorf("error pretty printing YAML path: %s", printErr)
+			}
+			fmt.Fprintf(os.Stderr, "%s\n", source)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		schemaBytes, err := readSource(c.Context, schemaFile)
+		if err != nil {
+			return err
+		}
+
+		var schema jsoncel.Schema
+		err = json.Unmarshal(schemaBytes, &schema)
+		if err != nil {
+			return err
+		}
+
+		compiler := glide.Compiler{
+			Program:     p,
+			InputSchema: &schema,
+		}
+
+		g, err := compiler.Compile()
+		if errors.As(err, &ne) {
+			clio.Infof("node error at: %s", ne.Node.GetPath())
+			source, printErr := ne.PrettyPrint(data)
+			if printErr != nil {
+				clio.Errorf("error pretty printing YAML path: %s", printErr)
+			}
+			fmt.Fprintf(os.Stderr, "%s\n", source)
+		}
+
+		if err != nil {
+			clio.Error("compile err")
+			return err
+		}
+
+		if c.Bool("fields") {
+			return printFieldReport(g)
+		}
+
+		input, err := g.FindInput("request", outcome)
+		if errors.Is(err, glide.ErrUnreachable) {
+			clio.Errorf("outcome %s can't be reached by any input", outcome)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		clio.Infof("outcome %s is reached by the input:", outcome)
+
+		out, err := json.MarshalIndent(input, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+
+		return nil
+	},
+}
+
+func printFieldReport(g *glide.Graph) error {
+	report, err := g.AnalyzeFields()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tTYPE\tCHECKS")
+	for _, f := range report.Fields {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", f.Path, f.Type, strings.Join(f.Steps, ", "))
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, field := range report.Undeclared {
+		clio.Warnf("%s is referenced by a check but isn't declared in the schema", field)
+	}
+	for _, field := range report.Unused {
+		clio.Warnf("%s is declared in the schema but isn't referenced by any check", field)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/urfave/cli/v2"
+)
+
+var Backtest = cli.Command{
+	Name:  "backtest",
+	Usage: "execute a new and an old workflow over recorded inputs, and report the inputs whose outcome changed",
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the new workflow YAML file, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "old", Usage: "the old workflow YAML file to compare against, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "inputs", Usage: "a directory of recorded inputs, one JSON file per input", Required: true},
+		&cli.IntFlag{Name: "samples", Value: 5, Usage: "the number of example inputs to print for each change of outcome"},
+		overlayFlag,
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		schemaBytes, err := readSource(c.Context, c.Path("schema"))
+		if err != nil {
+			return err
+		}
+
+		var schema jsoncel.Schema
+		err = json.Unmarshal(schemaBytes, &schema)
+		if err != nil {
+			return err
+		}
+
+		newWorkflow, err := compileBacktestWorkflow(c, c.Path("file"), &schema)
+		if err != nil {
+			return err
+		}
+		oldWorkflow, err := compileBacktestWorkflow(c, c.Path("old"), &schema)
+		if err != nil {
+			return err
+		}
+
+		inputs, err := readBacktestInputs(c.Path("inputs"))
+		if err != nil {
+			return err
+		}
+
+		report, err := glide.Backtest(oldWorkflow, newWorkflow, "request", inputs)
+		if err != nil {
+			return err
+		}
+
+		clio.Infof("executed %d inputs: %d outcomes changed", report.Total, len(report.Changed))
+
+		samples := c.Int("samples")
+		for _, t := range report.Transitions() {
+			fmt.Printf("%s: %d\n", t, report.Counts[t])
+
+			var printed int
+			for _, change := range report.Changed {
+				if printed == samples {
+					break
+				}
+				if change.BacktestTransition == t {
+					fmt.Printf("  %s\n", change.Input)
+					printed++
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// compileBacktestWorkflow reads and compiles one of the workflows to backtest.
+func compileBacktestWorkflow(c *cli.Context, file string, schema *jsoncel.Schema) (*glide.Compiled, error) {
+	data, err := readSource(c.Context, file)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := unmarshalWorkflow(c, data, cf.New(integrations()...))
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := glide.Compiler{
+		Program:     p,
+		InputSchema: schema,
+	}
+
+	g, err := compiler.Build()
+
+	var ne noderr.NodeError
+	if errors.As(err, &ne) {
+		clio.Infof("node error in %s at: %s", file, ne.Node.GetPath())
+		source, printErr := ne.PrettyPrint(data)
+		if printErr != nil {
+			clio.Errorf("error pretty printing YAML path: %s", printErr)
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", source)
+	}
+
+	return g, err
+}
+
+// readBacktestInputs reads the JSON files in a directory, in order of their names.
+func readBacktestInputs(dir string) ([]glide.BacktestInput, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var inputs []glide.BacktestInput
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		var input map[string]any
+		err = json.Unmarshal(data, &input)
+		if err != nil {
+			return nil, fmt.Errorf("reading input %s: %w", name, err)
+		}
+		inputs = append(inputs, glide.BacktestInput{Name: name, Input: input})
+	}
+
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no JSON inputs were found in %s", dir)
+	}
+	return inputs, nil
+}
@@ -0,0 +1,215 @@
+package command
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide/pkg/bundle"
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/urfave/cli/v2"
+)
+
+// dialects are the dialects which bundles can be written in, by name.
+var dialects = map[string]dialect.Dialect{
+	"cf": cf.Dialect,
+}
+
+var Bundle = cli.Command{
+	Name:  "bundle",
+	Usage: "build and verify policy bundles",
+	Subcommands: []*cli.Command{
+		&bundleBuild,
+		&bundleVerify,
+		&bundleKeygen,
+		&bundleSign,
+	},
+}
+
+var bundleBuild = cli.Command{
+	Name:  "build",
+	Usage: "package a directory containing bundle.yml, workflow.yml, schema.json and tests.yml into a bundle",
+	Flags: []cli.Flag{
+		&cli.PathFlag{Name: "dir", Aliases: []string{"d"}, Usage: "the directory containing the bundle files", Value: "."},
+		&cli.PathFlag{Name: "output", Aliases: []string{"o"}, Usage: "the bundle file to write", Value: "bundle.tar.gz"},
+	},
+	Action: func(c *cli.Context) error {
+		b, err := bundle.Load(c.Path("dir"))
+		if err != nil {
+			return err
+		}
+
+		err = verifyBundle(b)
+		if err != nil {
+			return err
+		}
+
+		data, err := b.Bytes()
+		if err != nil {
+			return err
+		}
+
+		out := c.Path("output")
+		err = os.WriteFile(out, data, 0644)
+		if err != nil {
+			return err
+		}
+
+		clio.Infof("wrote bundle %s %s to %s", b.Manifest.Name, b.Manifest.Version, out)
+		return nil
+	},
+}
+
+var bundleVerify = cli.Command{
+	Name:  "verify",
+	Usage: "compile the workflow in a bundle and run the bundle tests",
+	Flags: []cli.Flag{
+		&cli.PathFlag{Name: "bundle", Aliases: []string{"b"}, Usage: "the bundle file to verify", Required: true},
+		&cli.StringSliceFlag{Name: "key", Aliases: []string{"k"}, Usage: "a trusted public key file; if provided, the bundle signature is checked"},
+	},
+	Action: func(c *cli.Context) error {
+		path := c.Path("bundle")
+
+		var trusted []ed25519.PublicKey
+		for _, f := range c.StringSlice("key") {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return err
+			}
+			key, err := bundle.ParsePublicKey(data)
+			if err != nil {
+				return fmt.Errorf("parsing %s: %w", f, err)
+			}
+			trusted = append(trusted, key)
+		}
+
+		var b *bundle.Bundle
+		var err error
+		if len(trusted) > 0 {
+			b, err = bundle.OpenSigned(path, trusted...)
+		} else {
+			b, err = bundle.Open(path)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(trusted) > 0 {
+			clio.Infof("bundle signature is valid")
+		}
+
+		err = verifyBundle(b)
+		if err != nil {
+			return err
+		}
+
+		clio.Infof("bundle %s %s is valid", b.Manifest.Name, b.Manifest.Version)
+		return nil
+	},
+}
+
+var bundleKeygen = cli.Command{
+	Name:  "keygen",
+	Usage: "generate an ed25519 key pair for signing bundles",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "the name of the key files to write; the public key is written with a '.pub' suffix", Value: "glide.key"},
+	},
+	Action: func(c *cli.Context) error {
+		out := c.String("output")
+
+		pub, priv, err := bundle.GenerateKey()
+		if err != nil {
+			return err
+		}
+
+		privPEM, err := bundle.MarshalPrivateKey(priv)
+		if err != nil {
+			return err
+		}
+		pubPEM, err := bundle.MarshalPublicKey(pub)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(out, privPEM, 0600)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(out+".pub", pubPEM, 0644)
+		if err != nil {
+			return err
+		}
+
+		clio.Infof("wrote private key to %s and public key to %s", out, out+".pub")
+		return nil
+	},
+}
+
+var bundleSign = cli.Command{
+	Name:  "sign",
+	Usage: "sign a bundle, writing a detached signature file next to it",
+	Flags: []cli.Flag{
+		&cli.PathFlag{Name: "bundle", Aliases: []string{"b"}, Usage: "the bundle file to sign", Required: true},
+		&cli.PathFlag{Name: "key", Aliases: []string{"k"}, Usage: "the private key file", Required: true},
+	},
+	Action: func(c *cli.Context) error {
+		path := c.Path("bundle")
+
+		keyData, err := os.ReadFile(c.Path("key"))
+		if err != nil {
+			return err
+		}
+		key, err := bundle.ParsePrivateKey(keyData)
+		if err != nil {
+			return err
+		}
+
+		archive, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		// ensure that we're signing a valid bundle.
+		_, err = bundle.Read(bytes.NewReader(archive))
+		if err != nil {
+			return err
+		}
+
+		sig := bundle.Sign(archive, key)
+		err = os.WriteFile(path+bundle.SignatureExt, sig, 0644)
+		if err != nil {
+			return err
+		}
+
+		clio.Infof("wrote signature to %s", path+bundle.SignatureExt)
+		return nil
+	},
+}
+
+// verifyBundle compiles the bundle and runs its tests,
+// printing any test failures.
+func verifyBundle(b *bundle.Bundle) error {
+	d, ok := dialects[b.Manifest.Dialect]
+	if !ok {
+		return fmt.Errorf("unknown dialect %s", b.Manifest.Dialect)
+	}
+
+	err := b.Verify(d)
+
+	var ve *bundle.VerifyError
+	if errors.As(err, &ve) {
+		for _, f := range ve.Failures {
+			clio.Errorf("test failed: %s", f)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	clio.Infof("%d tests passed", len(b.Tests))
+	return nil
+}
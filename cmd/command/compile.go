@@ -1,60 +1,149 @@
 package command
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 
+	"github.com/common-fate/clio"
 	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/bundle"
 	"github.com/common-fate/glide/pkg/dialect/cf"
 	"github.com/common-fate/glide/pkg/jsoncel"
-	"github.com/dominikbraun/graph/draw"
 	"github.com/urfave/cli/v2"
 )
 
 var Compile = cli.Command{
 	Name: "compile",
-	Flags: []cli.Flag{
-		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow file to compile", Required: true},
-		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format", Required: true},
-	},
+	Description: `With --all, every workflow in the project manifest is compiled and
+their warnings are printed, but the graphs aren't.`,
+	Flags: append(append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow file to compile, as a path or URL"},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format, as a path or URL"},
+		&cli.BoolFlag{Name: "watch", Aliases: []string{"w"}, Usage: "watch the workflow and schema files, and recompile when they change"},
+		&cli.StringFlag{Name: "format", Usage: "the output format: dot, mermaid, json, svg or png", Value: "dot"},
+		&cli.StringSliceFlag{Name: "pass", Usage: "compile only the pass with this name, which can be repeated to compile several passes"},
+		&cli.IntFlag{Name: "max-expression-nodes", Usage: "warn about checks with more nodes than this, or -1 to disable"},
+		&cli.IntFlag{Name: "max-expression-nesting", Usage: "warn about checks with operators nested deeper than this, or -1 to disable"},
+		&cli.IntFlag{Name: "max-expression-fields", Usage: "warn about checks which reference more fields than this, or -1 to disable"},
+		overlayFlag,
+	}, varFlags...), projectFlags...),
 	Action: func(c *cli.Context) error {
-		f := c.Path("file")
-		schemaFile := c.Path("schema")
-
-		data, err := os.ReadFile(f)
-		if err != nil {
-			return err
+		if c.Bool("all") {
+			bundles, err := workflowBundles(c)
+			if err != nil {
+				return err
+			}
+			return forEachWorkflow(bundles, func(b *bundle.Bundle) error { return compileWorkflow(c, b) })
 		}
-		prog, err := glide.Unmarshal(data, cf.Dialect)
-		if err != nil {
-			return err
+		if c.Path("file") == "" || c.Path("schema") == "" {
+			return errors.New("--file and --schema must be provided, unless --all is set")
 		}
-
-		schemaBytes, err := os.ReadFile(schemaFile)
-		if err != nil {
-			return err
+		if c.Bool("watch") {
+			files := []string{c.Path("file"), c.Path("schema")}
+			return watch(c.Context, files, func() error { return compile(c) })
 		}
+		return compile(c)
+	},
+}
 
-		var schema jsoncel.Schema
-		err = json.Unmarshal(schemaBytes, &schema)
-		if err != nil {
-			return err
-		}
+// compile the workflow and print the graph in the format set with the 'format' flag.
+func compile(c *cli.Context) error {
+	f := c.Path("file")
+	schemaFile := c.Path("schema")
 
-		compiler := glide.Compiler{
-			Program:     prog,
-			InputSchema: &schema,
-		}
+	data, err := readSource(c.Context, f)
+	if err != nil {
+		return err
+	}
+	prog, err := unmarshalWorkflow(c, data, cf.Dialect)
+	if err != nil {
+		return err
+	}
 
-		g, err := compiler.Compile()
-		if err != nil {
-			return err
-		}
-		err = draw.DOT(g.G, os.Stdout)
-		if err != nil {
-			return err
-		}
+	schemaBytes, err := readSource(c.Context, schemaFile)
+	if err != nil {
+		return err
+	}
 
-		return nil
-	},
+	var schema jsoncel.Schema
+	err = json.Unmarshal(schemaBytes, &schema)
+	if err != nil {
+		return err
+	}
+
+	g, err := glide.Build(prog, &schema, compileOptions(c)...)
+	if err != nil {
+		return err
+	}
+
+	// warnings don't fail compilation, but are printed so that
+	// they are visible in CI logs.
+	for _, w := range g.Warnings() {
+		clio.Warnf("%s", w)
+	}
+
+	var buf bytes.Buffer
+	switch format := c.String("format"); format {
+	case "dot":
+		err = g.Render(&buf, nil)
+	case "mermaid":
+		err = g.RenderMermaid(&buf, nil)
+	case "json":
+		var out []byte
+		out, err = g.ExportJSON(nil)
+		buf.Write(out)
+	case "svg", "png":
+		err = renderImage(&buf, g, nil, format)
+	default:
+		return fmt.Errorf("unsupported format %q: must be dot, mermaid, json, svg or png", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(buf.Bytes())
+	return err
+}
+
+// compileOptions returns the options set with the command's flags.
+func compileOptions(c *cli.Context) []glide.CompileOption {
+	return []glide.CompileOption{
+		glide.WithComplexity(glide.ComplexityLimits{
+			MaxNodes:   c.Int("max-expression-nodes"),
+			MaxNesting: c.Int("max-expression-nesting"),
+			MaxFields:  c.Int("max-expression-fields"),
+		}),
+		glide.WithPasses(c.StringSlice("pass")...),
+	}
+}
+
+// compileWorkflow compiles a workflow in a project, printing its warnings.
+func compileWorkflow(c *cli.Context, b *bundle.Bundle) error {
+	d, ok := dialects[b.Manifest.Dialect]
+	if !ok {
+		return fmt.Errorf("unknown dialect %s", b.Manifest.Dialect)
+	}
+
+	prog, err := glide.Unmarshal(b.Workflow, d)
+	if err != nil {
+		return err
+	}
+
+	var schema jsoncel.Schema
+	err = json.Unmarshal(b.Schema, &schema)
+	if err != nil {
+		return err
+	}
+
+	g, err := glide.Build(prog, &schema, compileOptions(c)...)
+	if err != nil {
+		return err
+	}
+	for _, w := range g.Warnings() {
+		clio.Warnf("%s: %s", b.Manifest.Name, w)
+	}
+	return nil
 }
@@ -0,0 +1,32 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/urfave/cli/v2"
+)
+
+var Dialect = cli.Command{
+	Name:  "dialect",
+	Usage: "inspect the workflow dialect",
+	Subcommands: []*cli.Command{
+		&dialectDescribe,
+	},
+}
+
+var dialectDescribe = cli.Command{
+	Name:  "describe",
+	Usage: "print the nodes, actions and macros of the dialect as JSON",
+	Description: `The description includes the schema of each action's 'with' properties
+and outputs, so that it can be used to generate forms in workflow editors.`,
+	Action: func(c *cli.Context) error {
+		out, err := json.MarshalIndent(cf.Dialect.Describe(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	},
+}
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/urfave/cli/v2"
+)
+
+var Diff = cli.Command{
+	Name:  "diff",
+	Usage: "render the structural difference between an old and a new workflow as a graph",
+	Description: `Steps and edges which were added are drawn in green, those which were removed
+in red and dashed, and steps which changed in orange.`,
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the new workflow YAML file, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "old", Usage: "the old workflow YAML file to compare against, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format, as a path or URL", Required: true},
+		&cli.StringFlag{Name: "format", Usage: "the output format: dot or mermaid", Value: "dot"},
+		overlayFlag,
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		schemaBytes, err := readSource(c.Context, c.Path("schema"))
+		if err != nil {
+			return err
+		}
+
+		var schema jsoncel.Schema
+		err = json.Unmarshal(schemaBytes, &schema)
+		if err != nil {
+			return err
+		}
+
+		newWorkflow, err := compileBacktestWorkflow(c, c.Path("file"), &schema)
+		if err != nil {
+			return err
+		}
+		oldWorkflow, err := compileBacktestWorkflow(c, c.Path("old"), &schema)
+		if err != nil {
+			return err
+		}
+
+		diff, err := glide.DiffGraphs(oldWorkflow, newWorkflow)
+		if err != nil {
+			return err
+		}
+		if !diff.Changed() {
+			clio.Info("the workflows have the same graph")
+		}
+
+		var buf bytes.Buffer
+		switch format := c.String("format"); format {
+		case "dot":
+			err = diff.RenderDiff(&buf)
+		case "mermaid":
+			err = diff.RenderDiffMermaid(&buf)
+		default:
+			return fmt.Errorf("unsupported format %q: must be dot or mermaid", format)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	},
+}
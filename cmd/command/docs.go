@@ -0,0 +1,154 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/urfave/cli/v2"
+)
+
+var Docs = cli.Command{
+	Name:  "docs",
+	Usage: "render each example workflow in a directory, for documentation",
+	Description: `Each example is a folder containing a workflow.yml and schema.json file.
+If the folder contains an input.json file, the workflow is executed with it
+and the steps are shaded by their state.
+
+Images are written to the output directory, named after the example folder.`,
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "dir", Aliases: []string{"d"}, Usage: "the directory containing the examples", Value: filepath.Join("docs", "examples")},
+		&cli.PathFlag{Name: "output", Aliases: []string{"o"}, Usage: "the directory to write the rendered examples to", Value: filepath.Join("docs", "img")},
+		&cli.StringSliceFlag{Name: "format", Usage: "the formats to render: svg, png or mermaid", Value: cli.NewStringSlice("svg")},
+		&cli.StringFlag{Name: "start", Usage: "the start node to execute examples with an input from", Value: "request"},
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		for _, f := range c.StringSlice("format") {
+			if _, ok := docFormats[f]; !ok {
+				return fmt.Errorf("unsupported format %q: must be svg, png or mermaid", f)
+			}
+		}
+
+		dir := c.Path("dir")
+		folders, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		err = os.MkdirAll(c.Path("output"), 0o755)
+		if err != nil {
+			return err
+		}
+
+		for _, folder := range folders {
+			if !folder.IsDir() {
+				clio.Infof("skipping %s: not a folder", folder.Name())
+				continue
+			}
+			err = renderExample(c, filepath.Join(dir, folder.Name()))
+			if err != nil {
+				return fmt.Errorf("rendering example %s: %w", folder.Name(), err)
+			}
+		}
+		return nil
+	},
+}
+
+// docFormats maps the supported formats to their file extensions.
+var docFormats = map[string]string{
+	"svg":     ".svg",
+	"png":     ".png",
+	"mermaid": ".mmd",
+}
+
+// renderExample renders the example workflow in the folder,
+// in each of the formats provided with the 'format' flag.
+func renderExample(c *cli.Context, folder string) error {
+	workflow, err := os.ReadFile(filepath.Join(folder, "workflow.yml"))
+	if err != nil {
+		return err
+	}
+
+	unmarshalOpts, err := unmarshalOptions(c)
+	if err != nil {
+		return err
+	}
+
+	prog, err := glide.Unmarshal(workflow, cf.Dialect, unmarshalOpts...)
+	if err != nil {
+		return err
+	}
+
+	schemaBytes, err := os.ReadFile(filepath.Join(folder, "schema.json"))
+	if err != nil {
+		return err
+	}
+
+	var schema jsoncel.Schema
+	err = json.Unmarshal(schemaBytes, &schema)
+	if err != nil {
+		return err
+	}
+
+	compiler := glide.Compiler{
+		Program:     prog,
+		InputSchema: &schema,
+	}
+
+	g, err := compiler.Build()
+	if err != nil {
+		return err
+	}
+
+	// if the example has an input, run the workflow too,
+	// and shade the steps by their state.
+	var res *glide.Result
+
+	inputBytes, err := os.ReadFile(filepath.Join(folder, "input.json"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		var input map[string]any
+		err = json.Unmarshal(inputBytes, &input)
+		if err != nil {
+			return err
+		}
+
+		res, err = g.Execute(c.String("start"), input)
+		if err != nil {
+			return err
+		}
+	}
+
+	name := filepath.Base(folder)
+
+	for _, format := range c.StringSlice("format") {
+		var buf bytes.Buffer
+
+		if format == "mermaid" {
+			err = g.RenderMermaid(&buf, res)
+		} else {
+			err = renderImage(&buf, g, res, format)
+		}
+		if err != nil {
+			return err
+		}
+
+		outfile := filepath.Join(c.Path("output"), name+docFormats[format])
+		err = os.WriteFile(outfile, buf.Bytes(), 0o644)
+		if err != nil {
+			return err
+		}
+		clio.Successf("rendered %s", outfile)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/urfave/cli/v2"
+)
+
+var Estimate = cli.Command{
+	Name:  "estimate",
+	Usage: "estimate the time to reach each outcome, using the 'estimate' of each step",
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow YAML file to compile, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "input", Aliases: []string{"i"}, Usage: "an input to estimate the time for, in JSON format, as a path or URL. Missing fields are treated as unknown"},
+		overlayFlag,
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		data, err := readSource(c.Context, c.Path("file"))
+		if err != nil {
+			return err
+		}
+
+		p, err := unmarshalWorkflow(c, data, cf.Dialect)
+		if err != nil {
+			return err
+		}
+
+		schemaBytes, err := readSource(c.Context, c.Path("schema"))
+		if err != nil {
+			return err
+		}
+
+		var schema jsoncel.Schema
+		err = json.Unmarshal(schemaBytes, &schema)
+		if err != nil {
+			return err
+		}
+
+		var input map[string]any
+		if f := c.Path("input"); f != "" {
+			inputBytes, err := readSource(c.Context, f)
+			if err != nil {
+				return err
+			}
+			err = json.Unmarshal(inputBytes, &input)
+			if err != nil {
+				return err
+			}
+		}
+
+		compiler := glide.Compiler{
+			Program:     p,
+			InputSchema: &schema,
+		}
+
+		g, err := compiler.Build()
+		if err != nil {
+			return err
+		}
+
+		paths, err := g.CriticalPaths("request", input)
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			clio.Warn("no outcomes can be reached")
+		}
+
+		for _, path := range paths {
+			fmt.Printf("%s: %s (%s)\n", path.Outcome, step.FormatEstimate(path.Duration), strings.Join(path.Steps, " -> "))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,151 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/urfave/cli/v2"
+)
+
+var Export = cli.Command{
+	Name:  "export",
+	Usage: "translate a workflow into another policy language or format",
+	Subcommands: []*cli.Command{
+		&exportRego,
+		&exportCSV,
+	},
+}
+
+var exportRego = cli.Command{
+	Name:  "rego",
+	Usage: "translate a workflow into a Rego module, for enforcement with Open Policy Agent",
+	Description: `Checks, 'and' and 'or' steps and outcomes are translated into rules, and the
+'outcome' rule is the outcome of the workflow. Actions, and checks which use
+features with no Rego equivalent, can't be translated: they are never complete
+in the module, and are listed in the mapping report.`,
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow YAML file to translate, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format, as a path or URL", Required: true},
+		&cli.StringFlag{Name: "start", Usage: "the start node to translate the workflow from", Value: "request"},
+		&cli.StringFlag{Name: "package", Usage: "the package of the Rego module", Value: "glide.workflow"},
+		&cli.PathFlag{Name: "output", Aliases: []string{"o"}, Usage: "the file to write the Rego module to. If not provided, it is printed"},
+		&cli.PathFlag{Name: "report", Usage: "a file to write the mapping report of the steps which couldn't be translated to, as JSON"},
+		overlayFlag,
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		data, err := readSource(c.Context, c.Path("file"))
+		if err != nil {
+			return err
+		}
+
+		p, err := unmarshalWorkflow(c, data, cf.Dialect)
+		if err != nil {
+			return err
+		}
+
+		schemaBytes, err := readSource(c.Context, c.Path("schema"))
+		if err != nil {
+			return err
+		}
+
+		var schema jsoncel.Schema
+		err = json.Unmarshal(schemaBytes, &schema)
+		if err != nil {
+			return err
+		}
+
+		compiler := glide.Compiler{
+			Program:     p,
+			InputSchema: &schema,
+		}
+
+		g, err := compiler.Build()
+		if err != nil {
+			return err
+		}
+
+		out, err := g.ExportRego(c.String("start"), c.String("package"))
+		if err != nil {
+			return err
+		}
+
+		for _, u := range out.Untranslated {
+			clio.Warnf("%s (%s) was not translated: %s", u.Label, u.Step, u.Reason)
+		}
+
+		if f := c.Path("report"); f != "" {
+			untranslated := out.Untranslated
+			if untranslated == nil {
+				untranslated = []glide.UntranslatedStep{}
+			}
+			report, err := json.MarshalIndent(untranslated, "", "  ")
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(f, report, 0o644)
+			if err != nil {
+				return err
+			}
+		}
+
+		if f := c.Path("output"); f != "" {
+			err = os.WriteFile(f, []byte(out.Module), 0o644)
+			if err != nil {
+				return err
+			}
+			clio.Successf("wrote %s", f)
+			return nil
+		}
+
+		fmt.Print(out.Module)
+		return nil
+	},
+}
+
+var exportCSV = cli.Command{
+	Name:  "csv",
+	Usage: "write the steps of a workflow as a CSV table, for reviewing policies in a spreadsheet",
+	Description: `Each step is a row, with its pass, position, ID, name and type, the expression
+of a check, the type and 'with' properties of an action, and the outcomes its
+pass leads to.`,
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow YAML file to export, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "output", Aliases: []string{"o"}, Usage: "the file to write the CSV table to. If not provided, it is printed"},
+		overlayFlag,
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		data, err := readSource(c.Context, c.Path("file"))
+		if err != nil {
+			return err
+		}
+
+		p, err := unmarshalWorkflow(c, data, cf.Dialect)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		err = p.ExportCSV(&buf)
+		if err != nil {
+			return err
+		}
+
+		if f := c.Path("output"); f != "" {
+			err = os.WriteFile(f, buf.Bytes(), 0o644)
+			if err != nil {
+				return err
+			}
+			clio.Successf("wrote %s", f)
+			return nil
+		}
+
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	},
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/urfave/cli/v2"
+)
+
+var Fmt = cli.Command{
+	Name:  "fmt",
+	Usage: "check that CEL expressions in a workflow are canonically formatted",
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow YAML file to check, as a path or URL", Required: true},
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		data, err := readSource(c.Context, c.Path("file"))
+		if err != nil {
+			return err
+		}
+
+		unmarshalOpts, err := unmarshalOptions(c)
+		if err != nil {
+			return err
+		}
+
+		p, err := glide.Unmarshal(data, cf.Dialect, unmarshalOpts...)
+		if err != nil {
+			return err
+		}
+
+		var issues int
+		for _, check := range glide.FormatChecks(p) {
+			pos := fmt.Sprintf("%s %v", check.Pass, check.Position)
+
+			if check.Err != nil {
+				issues++
+				clio.Errorf("%s: %s", pos, check.Err)
+				source, printErr := noderr.NodeError{Node: check.Node, Err: check.Err}.PrettyPrint(data)
+				if printErr != nil {
+					clio.Errorf("error pretty printing YAML path: %s", printErr)
+				}
+				fmt.Fprintf(os.Stderr, "%s\n", source)
+				continue
+			}
+
+			if check.Changed() {
+				issues++
+				clio.Warnf("%s: %s -> %s", pos, check.Expression, check.Formatted)
+			}
+		}
+
+		if issues > 0 {
+			return fmt.Errorf("found %d expressions which are invalid or not canonically formatted", issues)
+		}
+		clio.Successf("all expressions are canonically formatted")
+		return nil
+	},
+}
@@ -0,0 +1,38 @@
+//go:build !nographviz
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/common-fate/glide"
+	"github.com/goccy/go-graphviz"
+)
+
+// renderImage renders the workflow as an image using Graphviz.
+//
+// Graphviz requires CGO. Build with the 'nographviz' tag to
+// render SVG images in Go instead.
+func renderImage(buf *bytes.Buffer, g *glide.Compiled, res *glide.Result, format string) error {
+	if format != "svg" && format != "png" {
+		return fmt.Errorf("unsupported image format %q: must be svg or png", format)
+	}
+
+	var dot bytes.Buffer
+	err := g.Render(&dot, res)
+	if err != nil {
+		return err
+	}
+
+	graph, err := graphviz.ParseBytes(dot.Bytes())
+	if err != nil {
+		return err
+	}
+	defer graph.Close()
+
+	gv := graphviz.New()
+	defer gv.Close()
+
+	return gv.Render(graph, graphviz.Format(format), buf)
+}
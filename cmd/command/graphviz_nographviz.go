@@ -0,0 +1,19 @@
+//go:build nographviz
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/common-fate/glide"
+)
+
+// renderImage renders the workflow as an SVG image, laid out in Go.
+// PNG images require Graphviz, which isn't available with the 'nographviz' tag.
+func renderImage(buf *bytes.Buffer, g *glide.Compiled, res *glide.Result, format string) error {
+	if format != "svg" {
+		return fmt.Errorf("unsupported image format %q: glide was built without Graphviz, so only svg is supported", format)
+	}
+	return g.RenderSVG(buf, res)
+}
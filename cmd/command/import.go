@@ -0,0 +1,67 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/urfave/cli/v2"
+)
+
+var Import = cli.Command{
+	Name:  "import",
+	Usage: "convert a workflow from another format into a Glide workflow",
+	Subcommands: []*cli.Command{
+		&importASL,
+	},
+}
+
+var importASL = cli.Command{
+	Name:  "asl",
+	Usage: "convert an AWS Step Functions state machine, written in the Amazon States Language, into a workflow",
+	Description: `Choice, Parallel, Pass, Succeed and Fail states are supported. Each path
+from 'StartAt' to a Succeed state becomes a pass leading to the --succeed outcome.
+Paths to a Fail state lead to the --fail outcome, or are dropped if it isn't provided.`,
+	Flags: []cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the state machine JSON file to convert, as a path or URL", Required: true},
+		&cli.StringFlag{Name: "start", Usage: "the start node which each pass begins at", Value: "request"},
+		&cli.StringFlag{Name: "succeed", Usage: "the outcome which Succeed states lead to", Value: "approved"},
+		&cli.StringFlag{Name: "fail", Usage: "the outcome which Fail states lead to"},
+		&cli.PathFlag{Name: "output", Aliases: []string{"o"}, Usage: "the file to write the workflow to. If not provided, it is printed"},
+	},
+	Action: func(c *cli.Context) error {
+		data, err := readSource(c.Context, c.Path("file"))
+		if err != nil {
+			return err
+		}
+
+		workflow, err := glide.ConvertStateMachine(data, glide.StateMachineOptions{
+			Start:   c.String("start"),
+			Succeed: c.String("succeed"),
+			Fail:    c.String("fail"),
+		})
+		if err != nil {
+			return err
+		}
+
+		// make sure the converted workflow is valid in the dialect.
+		_, err = glide.Unmarshal(workflow, cf.Dialect)
+		if err != nil {
+			return fmt.Errorf("the converted workflow is invalid: %w", err)
+		}
+
+		if f := c.Path("output"); f != "" {
+			err = os.WriteFile(f, workflow, 0o644)
+			if err != nil {
+				return err
+			}
+			clio.Successf("wrote %s", f)
+			return nil
+		}
+
+		fmt.Print(string(workflow))
+		return nil
+	},
+}
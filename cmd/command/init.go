@@ -0,0 +1,217 @@
+package command
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/urfave/cli/v2"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// templateFiles are the files scaffolded by each template.
+var templateFiles = []string{"workflow.yml", "schema.json", "input.json"}
+
+var Init = cli.Command{
+	Name:      "init",
+	Usage:     "scaffold a workflow, input schema and example input from a template",
+	ArgsUsage: "[template]",
+	Flags: []cli.Flag{
+		&cli.PathFlag{Name: "dir", Aliases: []string{"d"}, Usage: "the directory to write the files to", Value: "."},
+		&cli.BoolFlag{Name: "force", Usage: "overwrite existing files"},
+		&cli.BoolFlag{Name: "list", Usage: "list the available templates"},
+	},
+	Action: func(c *cli.Context) error {
+		names, err := templateNames()
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("list") {
+			for _, n := range names {
+				fmt.Println(n)
+			}
+			return nil
+		}
+
+		name := c.Args().First()
+		if name == "" {
+			name = "basic"
+		}
+
+		if !contains(names, name) {
+			return fmt.Errorf("unknown template %s (available templates: %s)", name, strings.Join(names, ", "))
+		}
+
+		d := cf.Dialect
+
+		files, err := renderTemplate(name, d)
+		if err != nil {
+			return err
+		}
+
+		dir := c.Path("dir")
+		if !c.Bool("force") {
+			for _, f := range templateFiles {
+				_, err := os.Stat(filepath.Join(dir, f))
+				if err == nil {
+					return fmt.Errorf("%s already exists (use --force to overwrite it)", filepath.Join(dir, f))
+				}
+			}
+		}
+
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range templateFiles {
+			err = os.WriteFile(filepath.Join(dir, f), files[f], 0644)
+			if err != nil {
+				return err
+			}
+			clio.Infof("created %s", filepath.Join(dir, f))
+		}
+
+		clio.Infof("run the example with: glide run -f %s -s %s -i %s",
+			filepath.Join(dir, "workflow.yml"),
+			filepath.Join(dir, "schema.json"),
+			filepath.Join(dir, "input.json"),
+		)
+
+		return nil
+	},
+}
+
+// templateNames returns the sorted names of the built-in templates.
+func templateNames() ([]string, error) {
+	entries, err := fs.ReadDir(templates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// templateData is the data available to templates.
+type templateData struct {
+	// Start is the ID of the start node in the dialect.
+	Start string
+	// Outcome is the ID of the highest priority outcome node in the dialect.
+	Outcome string
+}
+
+// renderTemplate renders the files for a template, using the nodes from the dialect.
+// The rendered workflow is compiled and executed against the example input,
+// to ensure that the template is valid for the dialect.
+func renderTemplate(name string, d dialect.Dialect) (map[string][]byte, error) {
+	var data templateData
+	var outcome *node.Node
+
+	// sort the node IDs so that the chosen nodes are deterministic.
+	var ids []string
+	for id := range d.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		n := d.Nodes[id]
+		if n.Type == node.Start && data.Start == "" {
+			data.Start = id
+		}
+		if n.Type == node.Outcome && (outcome == nil || n.Priority > outcome.Priority) {
+			outcome = &n
+			data.Outcome = id
+		}
+	}
+
+	if data.Start == "" || data.Outcome == "" {
+		return nil, errors.New("the dialect must contain a start and an outcome node")
+	}
+
+	files := map[string][]byte{}
+	for _, f := range templateFiles {
+		src, err := templates.ReadFile(filepath.ToSlash(filepath.Join("templates", name, f)))
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl, err := template.New(f).Parse(string(src))
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, data)
+		if err != nil {
+			return nil, err
+		}
+		files[f] = buf.Bytes()
+	}
+
+	p, err := glide.Unmarshal(files["workflow.yml"], d)
+	if err != nil {
+		return nil, fmt.Errorf("template %s is not valid for the dialect: %w", name, err)
+	}
+
+	var schema jsoncel.Schema
+	err = json.Unmarshal(files["schema.json"], &schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var input map[string]any
+	err = json.Unmarshal(files["input.json"], &input)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := glide.Compiler{
+		Program:     p,
+		InputSchema: &schema,
+	}
+
+	g, err := compiler.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("template %s is not valid for the dialect: %w", name, err)
+	}
+
+	_, err = g.Execute(data.Start, input)
+	if err != nil {
+		return nil, fmt.Errorf("template %s is not valid for the dialect: %w", name, err)
+	}
+
+	return files, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
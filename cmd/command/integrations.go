@@ -0,0 +1,26 @@
+package command
+
+import (
+	"os"
+
+	"github.com/common-fate/glide/pkg/dialect/cf"
+)
+
+// integrations returns options configuring the integrations used by
+// cf dialect actions, based on environment variables.
+func integrations() []cf.Option {
+	s := cf.Schedules{}
+	if token := os.Getenv("PAGERDUTY_TOKEN"); token != "" {
+		s["pagerduty"] = &cf.PagerDuty{Token: token}
+	}
+	if key := os.Getenv("OPSGENIE_API_KEY"); key != "" {
+		s["opsgenie"] = &cf.Opsgenie{APIKey: key}
+	}
+	opts := []cf.Option{cf.WithSchedules(s)}
+
+	if u := os.Getenv("SCIM_URL"); u != "" {
+		opts = append(opts, cf.WithDirectory(&cf.SCIM{BaseURL: u, Token: os.Getenv("SCIM_TOKEN")}))
+	}
+
+	return opts
+}
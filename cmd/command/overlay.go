@@ -0,0 +1,54 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/urfave/cli/v2"
+)
+
+// overlayFlag is the flag for merging environment-specific overlays over a workflow.
+var overlayFlag = &cli.StringSliceFlag{Name: "overlay", Usage: "an environment-specific overlay to merge over the workflow, e.g. 'workflow.prod.yml', as a path or URL. Overlays are merged in the order they are provided"}
+
+// unmarshalWorkflow unmarshals the workflow, merging the overlays provided with the 'overlay' flag.
+// Errors in a YAML node are pretty-printed along with the source of the file they are in.
+func unmarshalWorkflow(c *cli.Context, data []byte, d dialect.Dialect) (*glide.Program, error) {
+	unmarshalOpts, err := unmarshalOptions(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var overlays [][]byte
+	for _, f := range c.StringSlice("overlay") {
+		o, err := readSource(c.Context, f)
+		if err != nil {
+			return nil, err
+		}
+		overlays = append(overlays, o)
+	}
+
+	p, err := glide.UnmarshalWithOverlays(data, overlays, d, unmarshalOpts...)
+
+	var ne noderr.NodeError
+	if errors.As(err, &ne) {
+		source := data
+		var oe glide.OverlayError
+		if errors.As(err, &oe) {
+			source = overlays[oe.Index]
+		}
+
+		clio.Infof("node error at: %s", ne.Node.GetPath())
+		pretty, printErr := ne.PrettyPrint(source)
+		if printErr != nil {
+			clio.Errorf("error pretty printing YAML path: %s", printErr)
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", pretty)
+	}
+
+	return p, err
+}
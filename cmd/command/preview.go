@@ -0,0 +1,174 @@
+package command
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/urfave/cli/v2"
+)
+
+//go:embed preview.html
+var previewPage []byte
+
+var Preview = cli.Command{
+	Name:  "preview",
+	Usage: "start a local web server to preview a workflow with inputs entered in a form",
+	Description: `The page shows the workflow as a diagram, with a form generated from the input
+schema. The workflow is executed as the form changes, and the diagram is shaded
+by the state of each step. The workflow and schema are read again for each
+execution, so changes to them are shown without restarting the server.`,
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow YAML file to preview, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format, as a path or URL", Required: true},
+		&cli.StringFlag{Name: "start", Usage: "the start node to execute the workflow from", Value: "request"},
+		&cli.StringFlag{Name: "addr", Usage: "the address to listen on", Value: "localhost:8080"},
+		overlayFlag,
+	}, varFlags...),
+	Action: func(c *cli.Context) error {
+		addr := c.String("addr")
+		srv := &http.Server{Addr: addr, Handler: previewHandler(c)}
+
+		go func() {
+			<-c.Context.Done()
+			_ = srv.Close()
+		}()
+
+		clio.Infof("previewing %s at http://%s", c.Path("file"), addr)
+		err := srv.ListenAndServe()
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	},
+}
+
+// previewResponse is the result of executing the workflow
+// with the input entered in the preview form.
+type previewResponse struct {
+	Outcome string        `json:"outcome"`
+	Summary string        `json:"summary,omitempty"`
+	SVG     string        `json:"svg,omitempty"`
+	Result  *glide.Result `json:"result,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// previewHandler serves the preview page, the input schema, and
+// executions of the workflow with the input entered in the form.
+func previewHandler(c *cli.Context) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(previewPage)
+	})
+
+	mux.HandleFunc("/schema", func(w http.ResponseWriter, r *http.Request) {
+		schema, err := readSource(r.Context(), c.Path("schema"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(schema)
+	})
+
+	mux.HandleFunc("/execute", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var input map[string]any
+		err := json.NewDecoder(r.Body).Decode(&input)
+		if err != nil {
+			writePreview(w, http.StatusBadRequest, previewResponse{Error: fmt.Sprintf("invalid input: %s", err)})
+			return
+		}
+
+		res, err := previewExecute(c, input)
+		if err != nil {
+			writePreview(w, http.StatusUnprocessableEntity, previewResponse{Error: err.Error()})
+			return
+		}
+		writePreview(w, http.StatusOK, *res)
+	})
+
+	return mux
+}
+
+// previewExecute compiles the workflow, executes it with the input,
+// and renders the result. Fields missing from the input are unknown,
+// so that the diagram is shaded while the form is being filled in.
+func previewExecute(c *cli.Context, input map[string]any) (*previewResponse, error) {
+	data, err := readSource(c.Context, c.Path("file"))
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := unmarshalWorkflow(c, data, cf.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaBytes, err := readSource(c.Context, c.Path("schema"))
+	if err != nil {
+		return nil, err
+	}
+
+	var schema jsoncel.Schema
+	err = json.Unmarshal(schemaBytes, &schema)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := glide.Build(p, &schema)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := g.Execute(c.String("start"), input, glide.WithPartialInput())
+	var ee *glide.ExecutionError
+	if err != nil && !errors.As(err, &ee) {
+		return nil, err
+	}
+
+	out := previewResponse{Outcome: res.Outcome, Result: res}
+	if res.Failed {
+		out.Outcome = "<failed>"
+	}
+
+	out.Summary, err = g.Summarize(res)
+	if err != nil {
+		return nil, err
+	}
+
+	var svg bytes.Buffer
+	err = g.RenderSVG(&svg, res)
+	if err != nil {
+		return nil, err
+	}
+	out.SVG = svg.String()
+
+	return &out, nil
+}
+
+func writePreview(w http.ResponseWriter, status int, res previewResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(res)
+	if err != nil {
+		clio.Errorf("writing preview response: %s", err)
+	}
+}
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide/pkg/bundle"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/project"
+	"github.com/urfave/cli/v2"
+)
+
+// projectFlags select every workflow in a project manifest,
+// rather than the single workflow set with --file and --schema.
+var projectFlags = []cli.Flag{
+	&cli.BoolFlag{Name: "all", Usage: "use every workflow in the project manifest, rather than --file and --schema"},
+	&cli.PathFlag{Name: "project", Aliases: []string{"p"}, Usage: "the project manifest, or the directory containing it, used with --all", Value: project.File},
+}
+
+var Validate = cli.Command{
+	Name:  "validate",
+	Usage: "check that workflows compile, and that the inputs of their tests are valid for the schema",
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow file to validate"},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format"},
+		&cli.StringSliceFlag{Name: "tests", Aliases: []string{"t"}, Usage: "a test suite file, which can be repeated"},
+	}, projectFlags...),
+	Action: func(c *cli.Context) error {
+		bundles, err := workflowBundles(c)
+		if err != nil {
+			return err
+		}
+		return forEachWorkflow(bundles, validateWorkflow)
+	},
+}
+
+var Test = cli.Command{
+	Name:  "test",
+	Usage: "run the tests of workflows",
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow file to test"},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format"},
+		&cli.StringSliceFlag{Name: "tests", Aliases: []string{"t"}, Usage: "a test suite file, which can be repeated"},
+	}, projectFlags...),
+	Action: func(c *cli.Context) error {
+		bundles, err := workflowBundles(c)
+		if err != nil {
+			return err
+		}
+		return forEachWorkflow(bundles, testWorkflow)
+	},
+}
+
+// workflowBundles returns every workflow in the project manifest if --all is set,
+// and otherwise the workflow set with --file, --schema and --tests.
+// The workflows are returned as bundles, which can be compiled and tested.
+func workflowBundles(c *cli.Context) ([]*bundle.Bundle, error) {
+	if c.Bool("all") {
+		p, err := project.Load(c.Path("project"))
+		if err != nil {
+			return nil, err
+		}
+		var bundles []*bundle.Bundle
+		for _, w := range p.Workflows {
+			b, err := p.Bundle(w)
+			if err != nil {
+				return nil, fmt.Errorf("workflow %s: %w", w.Name, err)
+			}
+			bundles = append(bundles, b)
+		}
+		return bundles, nil
+	}
+
+	f := c.Path("file")
+	schemaFile := c.Path("schema")
+	if f == "" || schemaFile == "" {
+		return nil, errors.New("--file and --schema must be provided, unless --all is set")
+	}
+
+	b := bundle.Bundle{Manifest: bundle.Manifest{Name: f, Dialect: "cf"}}
+	var err error
+	b.Workflow, err = readSource(c.Context, f)
+	if err != nil {
+		return nil, err
+	}
+	b.Schema, err = readSource(c.Context, schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	if c.IsSet("tests") {
+		for _, suite := range c.StringSlice("tests") {
+			data, err := readSource(c.Context, suite)
+			if err != nil {
+				return nil, err
+			}
+			tests, err := bundle.ParseTests(data)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", suite, err)
+			}
+			b.Tests = append(b.Tests, tests...)
+		}
+	}
+	return []*bundle.Bundle{&b}, nil
+}
+
+// forEachWorkflow calls fn for each workflow, printing the workflows which
+// failed. It returns an error if any of the workflows failed.
+func forEachWorkflow(bundles []*bundle.Bundle, fn func(b *bundle.Bundle) error) error {
+	var failed int
+	for _, b := range bundles {
+		err := fn(b)
+		if err != nil {
+			clio.Errorf("%s: %s", b.Manifest.Name, err)
+			failed++
+			continue
+		}
+		clio.Successf("%s: ok", b.Manifest.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d workflows failed", failed, len(bundles))
+	}
+	return nil
+}
+
+// validateWorkflow compiles a workflow, printing its warnings, and checks
+// that the inputs of its tests are valid for the schema.
+func validateWorkflow(b *bundle.Bundle) error {
+	d, ok := dialects[b.Manifest.Dialect]
+	if !ok {
+		return fmt.Errorf("unknown dialect %s", b.Manifest.Dialect)
+	}
+
+	g, err := b.Compile(d)
+	if err != nil {
+		return err
+	}
+	for _, w := range g.Warnings {
+		clio.Warnf("%s: %s", b.Manifest.Name, w)
+	}
+
+	var schema jsoncel.Schema
+	err = json.Unmarshal(b.Schema, &schema)
+	if err != nil {
+		return err
+	}
+	for _, t := range b.Tests {
+		err = jsoncel.Validate(&schema, t.Input)
+		if err != nil {
+			return fmt.Errorf("the input of test %s is not valid: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// testWorkflow runs the tests of a workflow, printing the tests which failed.
+func testWorkflow(b *bundle.Bundle) error {
+	d, ok := dialects[b.Manifest.Dialect]
+	if !ok {
+		return fmt.Errorf("unknown dialect %s", b.Manifest.Dialect)
+	}
+
+	err := b.Verify(d)
+
+	var ve *bundle.VerifyError
+	if errors.As(err, &ve) {
+		for _, f := range ve.Failures {
+			clio.Errorf("%s: test failed: %s", b.Manifest.Name, f)
+		}
+	}
+	return err
+}
@@ -5,126 +5,171 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/common-fate/clio"
 	"github.com/common-fate/glide"
 	"github.com/common-fate/glide/pkg/dialect/cf"
 	"github.com/common-fate/glide/pkg/jsoncel"
 	"github.com/common-fate/glide/pkg/noderr"
-	"github.com/dominikbraun/graph/draw"
 	"github.com/urfave/cli/v2"
 )
 
 var Run = cli.Command{
 	Name: "run",
-	Flags: []cli.Flag{
-		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow YAML file to compile", Required: true},
-		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format", Required: true},
-		&cli.PathFlag{Name: "input", Aliases: []string{"i"}, Usage: "the input data for the workflow, in JSON format", Required: true},
-	},
+	Flags: append([]cli.Flag{
+		&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "the workflow YAML file to compile, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the input schema, in JSON schema format, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "input", Aliases: []string{"i"}, Usage: "the input data for the workflow, in JSON format, as a path or URL", Required: true},
+		&cli.BoolFlag{Name: "partial", Usage: "allow the input to be missing fields, evaluating checks which depend on them as unknown"},
+		&cli.BoolFlag{Name: "short-circuit", Usage: "skip the remaining steps once the highest priority outcome is complete"},
+		&cli.BoolFlag{Name: "watch", Aliases: []string{"w"}, Usage: "watch the workflow, schema and input files, and re-run when they change"},
+		&cli.BoolFlag{Name: "json", Usage: "print the execution result as JSON, rather than the graph in DOT format"},
+		overlayFlag,
+	}, varFlags...),
 	Action: func(c *cli.Context) error {
-		f := c.Path("file")
-		schemaFile := c.Path("schema")
-		inputFile := c.Path("input")
-
-		data, err := os.ReadFile(f)
-		if err != nil {
-			return err
-		}
-
-		p, err := glide.Unmarshal(data, cf.Dialect)
-
-		var ne noderr.NodeError
-		if errors.As(err, &ne) {
-			clio.Infof("node error at: %s", ne.Node.GetPath())
-			source, printErr := ne.PrettyPrint(data)
-			if printErr != nil {
-				clio.Errorf("error pretty printing YAML path: %s", printErr)
-			}
-			fmt.Fprintf(os.Stderr, "%s\n", source)
-		}
-
-		if err != nil {
-			return err
-		}
-
-		schemaBytes, err := os.ReadFile(schemaFile)
-		if err != nil {
-			return err
-		}
-
-		var schema jsoncel.Schema
-		err = json.Unmarshal(schemaBytes, &schema)
-		if err != nil {
-			return err
-		}
-
-		inputBytes, err := os.ReadFile(inputFile)
-		if err != nil {
-			return err
-		}
-
-		var input map[string]any
-		err = json.Unmarshal(inputBytes, &input)
-		if err != nil {
-			return err
+		if c.Bool("watch") {
+			files := []string{c.Path("file"), c.Path("schema"), c.Path("input")}
+			return watch(c.Context, files, func() error { return run(c) })
 		}
+		return run(c)
+	},
+}
 
-		compiler := glide.Compiler{
-			Program:     p,
-			InputSchema: &schema,
+// run executes the workflow against the input and
+// prints the graph in DOT format, with nodes shaded by their state.
+func run(c *cli.Context) error {
+	f := c.Path("file")
+	schemaFile := c.Path("schema")
+	inputFile := c.Path("input")
+
+	data, err := readSource(c.Context, f)
+	if err != nil {
+		return err
+	}
+
+	p, err := unmarshalWorkflow(c, data, cf.New(integrations()...))
+	if err != nil {
+		return err
+	}
+
+	schemaBytes, err := readSource(c.Context, schemaFile)
+	if err != nil {
+		return err
+	}
+
+	var schema jsoncel.Schema
+	err = json.Unmarshal(schemaBytes, &schema)
+	if err != nil {
+		return err
+	}
+
+	inputBytes, err := readSource(c.Context, inputFile)
+	if err != nil {
+		return err
+	}
+
+	var input map[string]any
+	err = json.Unmarshal(inputBytes, &input)
+	if err != nil {
+		return err
+	}
+
+	compiler := glide.Compiler{
+		Program:     p,
+		InputSchema: &schema,
+	}
+
+	// compile the graph
+	g, err := compiler.Build()
+
+	var ne noderr.NodeError
+	if errors.As(err, &ne) {
+		clio.Infof("node error at: %s", ne.Node.GetPath())
+		source, printErr := ne.PrettyPrint(data)
+		if printErr != nil {
+			clio.Errorf("error pretty printing YAML path: %s", printErr)
 		}
-
-		// compile the graph
-		g, err := compiler.Compile()
-		if errors.As(err, &ne) {
-			clio.Infof("node error at: %s", ne.Node.GetPath())
+		fmt.Fprintf(os.Stderr, "%s\n", source)
+	}
+
+	if err != nil {
+		clio.Error("compile err")
+		return err
+	}
+
+	// execute the graph
+	var opts []glide.ExecuteOption
+	if c.Bool("partial") {
+		opts = append(opts, glide.WithPartialInput())
+	}
+	if c.Bool("short-circuit") {
+		opts = append(opts, glide.WithShortCircuit())
+	}
+
+	// if steps fail to evaluate, the result contains the states computed
+	// for the other steps, so the graph is still drawn.
+	res, execErr := g.Execute("request", input, opts...)
+	var ee *glide.ExecutionError
+	if execErr != nil && !errors.As(execErr, &ee) {
+		return execErr
+	}
+
+	outcome := res.Outcome
+	if outcome == "" {
+		outcome = "<running>"
+	}
+
+	if res.Failed {
+		outcome = "<failed>"
+	}
+
+	clio.Infof("workflow outcome: %s", outcome)
+
+	summary, err := g.Summarize(res)
+	if err != nil {
+		return err
+	}
+	clio.Infof("summary: %s", summary)
+
+	for id, err := range res.Errors {
+		clio.Errorf("action %s failed: %s", id, err)
+	}
+
+	for id, effect := range res.Effects {
+		clio.Infof("action %s has a side effect to carry out: %+v", id, effect)
+	}
+
+	for _, se := range res.StepErrors {
+		clio.Errorf("step %s could not be evaluated: %s", se.Step, se.Err)
+		if errors.As(se.Err, &ne) {
 			source, printErr := ne.PrettyPrint(data)
 			if printErr != nil {
 				clio.Errorf("error pretty printing YAML path: %s", printErr)
 			}
 			fmt.Fprintf(os.Stderr, "%s\n", source)
 		}
+	}
 
-		if err != nil {
-			clio.Error("compile err")
-			return err
-		}
+	if len(res.UnknownFields) > 0 {
+		clio.Infof("unknown fields which could change the outcome: %s", strings.Join(res.UnknownFields, ", "))
+	}
 
-		// execute the graph
-		res, err := g.Execute("request", input)
+	if c.Bool("json") {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(res)
 		if err != nil {
 			return err
 		}
+		return execErr
+	}
 
-		outcome := res.Outcome
-		if outcome == "" {
-			outcome = "<running>"
-		}
-
-		clio.Infof("workflow outcome: %s", outcome)
-
-		// shade completed nodes
-		for id, state := range res.State {
-			_, props, err := g.G.VertexWithProperties(id)
-			if err != nil {
-				return err
-			}
-			props.Attributes["style"] = "filled"
-
-			switch state {
-			case glide.Complete:
-				props.Attributes["fillcolor"] = "#00FF00"
-			case glide.Active:
-				props.Attributes["fillcolor"] = "#89CFF0"
-			}
-		}
-
-		err = draw.DOT(g.G, os.Stdout)
-		if err != nil {
-			return err
-		}
+	// render the graph with nodes shaded by their state
+	err = g.Render(os.Stdout, res)
+	if err != nil {
+		return err
+	}
 
-		return nil
-	},
+	return execErr
 }
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/common-fate/clio"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/urfave/cli/v2"
+)
+
+var Schema = cli.Command{
+	Name:  "schema",
+	Usage: "inspect input schemas",
+	Subcommands: []*cli.Command{
+		&schemaDiff,
+	},
+}
+
+var schemaDiff = cli.Command{
+	Name:  "diff",
+	Usage: "report the changes to the fields of an input schema, failing if any are breaking",
+	Description: `Changes are breaking if they remove a field or an enum value, change the type
+of a field, or make a required field optional, as workflows which reference the field
+may no longer compile or behave the same way.`,
+	Flags: []cli.Flag{
+		&cli.PathFlag{Name: "schema", Aliases: []string{"s"}, Usage: "the new input schema, in JSON schema format, as a path or URL", Required: true},
+		&cli.PathFlag{Name: "old", Usage: "the old input schema to compare against, as a path or URL", Required: true},
+	},
+	Action: func(c *cli.Context) error {
+		before, err := readSchema(c, c.Path("old"))
+		if err != nil {
+			return err
+		}
+		after, err := readSchema(c, c.Path("schema"))
+		if err != nil {
+			return err
+		}
+
+		changes := jsoncel.CheckCompatibility(before, after)
+		for _, change := range changes {
+			if change.Breaking {
+				clio.Errorf("%s", change)
+			} else {
+				clio.Infof("%s", change)
+			}
+		}
+
+		if breaking := jsoncel.BreakingChanges(changes); len(breaking) > 0 {
+			return fmt.Errorf("found %d breaking changes to the schema", len(breaking))
+		}
+		clio.Successf("the schema is compatible")
+		return nil
+	},
+}
+
+func readSchema(c *cli.Context, path string) (*jsoncel.Schema, error) {
+	data, err := readSource(c.Context, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema jsoncel.Schema
+	err = json.Unmarshal(data, &schema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema %s: %w", path, err)
+	}
+	return &schema, nil
+}
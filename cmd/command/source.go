@@ -0,0 +1,19 @@
+package command
+
+import (
+	"context"
+
+	"github.com/common-fate/glide/pkg/loader"
+)
+
+// readSource reads a file from a local path or a remote source,
+// such as 'https://example.com/workflow.yml#sha256=<hex>'.
+// See the loader package for the supported sources.
+func readSource(ctx context.Context, source string) ([]byte, error) {
+	l, err := loader.New()
+	if err != nil {
+		// caching is unavailable if there is no cache directory.
+		l = &loader.Loader{}
+	}
+	return l.Load(ctx, source)
+}
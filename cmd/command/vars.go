@@ -0,0 +1,41 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/common-fate/glide"
+	"github.com/urfave/cli/v2"
+)
+
+// varFlags are the flags for providing interpolation variables to a workflow.
+var varFlags = []cli.Flag{
+	&cli.StringSliceFlag{Name: "var", Usage: "a variable for ${var.<name>} references in the workflow, in the format 'name=value'"},
+	&cli.BoolFlag{Name: "env", Usage: "allow ${env.<name>} references in the workflow to read environment variables"},
+}
+
+// unmarshalOptions returns the unmarshal options for the variable flags.
+func unmarshalOptions(c *cli.Context) ([]glide.UnmarshalOption, error) {
+	vars := map[string]string{}
+	for _, v := range c.StringSlice("var") {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q: variables must be in the format 'name=value'", v)
+		}
+		vars[name] = value
+	}
+
+	opts := []glide.UnmarshalOption{glide.WithVariables(vars)}
+
+	if c.Bool("env") {
+		env := map[string]string{}
+		for _, e := range os.Environ() {
+			name, value, _ := strings.Cut(e, "=")
+			env[name] = value
+		}
+		opts = append(opts, glide.WithEnv(env))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/common-fate/clio"
+)
+
+// watchInterval is how often watched files are polled for changes.
+const watchInterval = 500 * time.Millisecond
+
+// watch calls fn, and then calls it again each time one of the files changes.
+// Errors returned by fn are printed rather than stopping the watcher,
+// so that diagnostics are shown incrementally while iterating on a workflow.
+//
+// Files are polled for changes rather than using filesystem notifications,
+// which keeps the watcher portable and free of extra dependencies.
+func watch(ctx context.Context, files []string, fn func() error) error {
+	last := modTimes(files)
+
+	run := func() {
+		err := fn()
+		if err != nil {
+			clio.Error(err.Error())
+		}
+		clio.Infof("watching for changes...")
+	}
+
+	run()
+
+	t := time.NewTicker(watchInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+			current := modTimes(files)
+			if changed(last, current) {
+				last = current
+				clio.Infof("change detected, re-running")
+				run()
+			}
+		}
+	}
+}
+
+// modTimes returns the modification times of the files.
+// Files which can't be read have a zero modification time.
+func modTimes(files []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(files))
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err == nil {
+			times[f] = info.ModTime()
+		} else {
+			times[f] = time.Time{}
+		}
+	}
+	return times
+}
+
+func changed(last, current map[string]time.Time) bool {
+	for f, t := range current {
+		if !last[f].Equal(t) {
+			return true
+		}
+	}
+	return false
+}
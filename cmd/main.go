@@ -14,7 +14,22 @@ func main() {
 		Usage: "https://commonfate.io",
 		Commands: []*cli.Command{
 			&command.Compile,
+			&command.Validate,
+			&command.Test,
 			&command.Run,
+			&command.Analyze,
+			&command.Init,
+			&command.Bundle,
+			&command.Docs,
+			&command.Fmt,
+			&command.Estimate,
+			&command.Dialect,
+			&command.Export,
+			&command.Import,
+			&command.Backtest,
+			&command.Diff,
+			&command.Schema,
+			&command.Preview,
 		},
 	}
 	err := app.Run(os.Args)
@@ -8,7 +8,9 @@ import (
 	"github.com/common-fate/glide/pkg/noderr"
 	"github.com/common-fate/glide/pkg/step"
 	"github.com/dominikbraun/graph"
+	"github.com/goccy/go-yaml/ast"
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
 	"github.com/pkg/errors"
 )
 
@@ -23,6 +25,45 @@ type Compiler struct {
 	InputSchema *jsoncel.Schema
 	// MaxDepth is set to 10 by default if not provided.
 	MaxDepth int
+	// Complexity are the limits above which checks are reported
+	// as too complex in the compiler warnings.
+	Complexity ComplexityLimits
+	// RejectImpure rejects workflows with checks which call impure
+	// dialect functions, so that every decision is a pure function
+	// of the input. Otherwise, the calls are reported in the warnings.
+	RejectImpure bool
+	// AllowImpure are the names of impure dialect functions which
+	// checks are allowed to call without being reported.
+	AllowImpure []string
+	// FoldConstants removes checks which are always true from the graph,
+	// such as 'check: true' placeholders, linking the steps before them
+	// directly to the steps after them.
+	FoldConstants bool
+	// DedupeSteps merges steps which are the same in several passes,
+	// and follow the same steps, into a single vertex in the shared pass.
+	// Programs with many similar passes compile into much smaller graphs.
+	DedupeSteps bool
+	// LazyPrograms defers constructing the CEL program for each check
+	// until the check is first evaluated, which cuts the compile time of
+	// very large workflows where most checks aren't evaluated for a request.
+	// Programs are constructed eagerly by default, so that errors
+	// constructing them are returned by Compile.
+	LazyPrograms bool
+	// OutcomeStrategy selects the outcomes of an execution when more than
+	// one outcome is reached. If nil, the outcome with the highest priority
+	// is selected. See OutcomeStrategy.
+	OutcomeStrategy OutcomeStrategy
+	// Libraries are CEL libraries of functions and macros which checks can
+	// use, in addition to those of the dialect, such as accesscel.Library().
+	Libraries []cel.EnvOption
+	// Passes are the names of the passes to compile. If empty, every
+	// pass is compiled. Compiling a subset of the passes is useful for
+	// previewing or executing a single path in isolation.
+	Passes []string
+
+	// matrixInput is the input which matrix passes over input fields
+	// are expanded with, when the workflow is executed.
+	matrixInput map[string]any
 }
 
 // Compile statements into an execution graph.
@@ -32,34 +73,178 @@ func (c *Compiler) Compile() (*Graph, error) {
 		c.MaxDepth = DefaultMaxDepth
 	}
 
+	program, err := selectPasses(c.Program, c.Passes)
+	if err != nil {
+		return nil, err
+	}
+
+	// references in the input schema are resolved, so
+	// that the fields they refer to are typed.
+	inputSchema, err := jsoncel.Resolve(c.InputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("input schema: %w", err)
+	}
+
+	// the fields which the dialect's actions expect are added to the input schema,
+	// so that checks which reference them are type-checked too.
+	inputSchema, err = dialectInputSchema(inputSchema, program.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	// expand the matrix passes into a pass for each of their values.
+	program, runtimeMatrix, err := expandMatrices(program, inputSchema, c.matrixInput)
+	if err != nil {
+		return nil, err
+	}
+
 	// set up the type for the 'input' object,
 	// based on the provided JSON schema.
-	p := jsoncel.NewProvider("input", c.InputSchema)
+	p := jsoncel.NewProvider("input", inputSchema)
 
-	env, err := cel.NewEnv(
+	// set up the type for the 'steps' object, which contains
+	// the outputs of actions, based on their output schemas.
+	steps, outputSteps, err := stepsSchema(program)
+	if err != nil {
+		return nil, err
+	}
+
+	// steps can also be referenced by checks by their ID or name,
+	// to read whether they are complete.
+	keys := stepKeys(program)
+	addStateSchema(steps, keys)
+	p.Register(stepsVar, steps)
+	p.Register(workflowVar, workflowSchema)
+
+	envOpts := []cel.EnvOption{
 		cel.CustomTypeProvider(p),
+		cel.CustomTypeAdapter(p),
 		cel.Variable("input", cel.ObjectType("input")),
-	)
+		cel.Variable(stepsVar, cel.ObjectType(stepsVar)),
+		cel.Variable(workflowVar, cel.ObjectType(workflowVar)),
+	}
+
+	// register any expression macros provided by the dialect.
+	if program.Dialect != nil {
+		macros, err := dialectMacros(program.Dialect)
+		if err != nil {
+			return nil, err
+		}
+		envOpts = append(envOpts, cel.Macros(macros...))
+		envOpts = append(envOpts, dialectFunctions(program.Dialect)...)
+	}
+	envOpts = append(envOpts, c.Libraries...)
+
+	env, err := cel.NewEnv(envOpts...)
 	if err != nil {
 		return nil, err
 	}
 
+	// actions in graphs expanded for an input have already been validated,
+	// when the workflow was compiled with placeholder values. Validators may
+	// call external systems, which shouldn't fail an execution.
+	if c.matrixInput == nil {
+		err = validateActions(program, env, inputSchema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	g := NewGraph()
+	g.provider = p
+	g.dialect = program.Dialect
+	g.version = program.Version
+	g.outputSteps = outputSteps
+	g.stepKeys = keys
+	g.lazyPrograms = c.LazyPrograms
+	g.outcomeStrategy = c.OutcomeStrategy
+
+	g.defaultOutcome, err = defaultOutcome(program)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatePreconditions(program.Preconditions)
+	if err != nil {
+		return nil, err
+	}
 
-	for passID, pd := range c.Program.Workflow {
+	for passID, pd := range program.Workflow {
 		p := pd
 		err = compilePass(compilePassOpts{
-			G:          g,
-			PassID:     passID,
-			Env:        env,
-			Statements: p.Steps,
-			MaxDepth:   c.MaxDepth,
+			G:             g,
+			PassID:        passID,
+			Env:           env,
+			Statements:    p.Steps,
+			Preconditions: program.Preconditions,
+			MaxDepth:      c.MaxDepth,
 		})
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	err = g.addNeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.resolveStepRefs(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// steps are visited after the steps they need.
+	for _, n := range g.needs {
+		for _, id := range n.ids {
+			g.deps[n.target] = append(g.deps[n.target], n.pass+"."+id)
+		}
+	}
+
+	err = g.addJoinDeps()
+	if err != nil {
+		return nil, err
+	}
+
+	// graphs expanded for an input have already been diagnosed,
+	// when the workflow was compiled with placeholder values.
+	if c.matrixInput == nil {
+		if runtimeMatrix {
+			mc := *c
+			g.matrixCompiler = &mc
+		}
+
+		impure, err := g.impureChecks(c.AllowImpure)
+		if err != nil {
+			return nil, err
+		}
+		if c.RejectImpure && len(impure) > 0 {
+			return nil, noderr.Wrap(errors.New(impure[0].String()), impure[0].Node)
+		}
+
+		g.Warnings, err = g.diagnose(c.Complexity.withDefaults())
+		if err != nil {
+			return nil, err
+		}
+		g.Warnings = append(g.Warnings, impure...)
+	}
+
+	// constants are folded after the graph has been diagnosed,
+	// so that the warnings refer to the checks in the workflow.
+	if c.FoldConstants {
+		err = g.foldConstants()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.DedupeSteps {
+		err = g.dedupeSteps()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return g, nil
 }
 
@@ -75,7 +260,10 @@ type compilePassOpts struct {
 	PassID     string
 	Env        *cel.Env
 	Statements []step.Step
-	MaxDepth   int
+	// Preconditions are the program's preconditions,
+	// which are compiled after the start node.
+	Preconditions []step.Step
+	MaxDepth      int
 }
 
 // compilePass compiles a particular pass over the workflow graph into.
@@ -100,6 +288,13 @@ func compilePass(opts compilePassOpts) error {
 		return err
 	}
 
+	// step IDs must be unique within a pass, otherwise
+	// the steps would be merged into a single vertex.
+	err = checkUniqueIDs(opts.Statements, map[string]bool{})
+	if err != nil {
+		return err
+	}
+
 	var prev *step.Step
 	for i, sd := range opts.Statements {
 		s := sd
@@ -119,11 +314,40 @@ func compilePass(opts compilePassOpts) error {
 		}
 
 		prev = &s
+
+		if i == 0 && len(opts.Preconditions) > 0 {
+			prev, err = compilePreconditions(opts, prev)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// checkUniqueIDs returns an error if any of the statements,
+// their children, or their on_fail steps share the same ID.
+func checkUniqueIDs(statements []step.Step, seen map[string]bool) error {
+	for _, s := range statements {
+		if s.ID != "" {
+			if seen[s.ID] {
+				return noderr.Wrap(fmt.Errorf("duplicate step id %q: step ids must be unique within a pass", s.ID), s.Node)
+			}
+			seen[s.ID] = true
+		}
+		err := checkUniqueIDs(s.Children, seen)
+		if err != nil {
+			return err
+		}
+		err = checkUniqueIDs(s.OnFail.Steps, seen)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // assertNode asserts that a particular statement
 // contains a reference to a node, and that the
 // node is a particular type.
@@ -165,6 +389,58 @@ type VisitOpts struct {
 
 	Parent   *step.Step
 	Previous *step.Step
+
+	// OnFail is true if the statement is the first statement in an
+	// on_fail branch, so that the edge from the Previous statement
+	// is only followed if the Previous statement fails.
+	OnFail bool
+}
+
+// onFailAttribute is the edge attribute set on edges into on_fail branches.
+const onFailAttribute = "on_fail"
+
+// needsAttribute is the edge attribute set on edges from the steps
+// listed in a step's 'needs' field.
+const needsAttribute = "needs"
+
+// stepNeeds are the steps which a step needs, recorded while visiting
+// statements so that the edges are added once every step has been added.
+type stepNeeds struct {
+	target string
+	pass   string
+	ids    []string
+	node   ast.Node
+}
+
+// addNeeds adds an edge from each step listed in a 'needs' field
+// to the step which needs it.
+func (g *Graph) addNeeds() error {
+	for _, n := range g.needs {
+		for _, id := range n.ids {
+			source := n.pass + "." + id
+			if _, err := g.store.step(source); err != nil {
+				return noderr.Wrap(fmt.Errorf("step needs %q, but pass %s has no step with this id", id, n.pass), n.node)
+			}
+
+			err := g.store.addEdge(source, n.target, map[string]string{
+				needsAttribute: "true",
+				"label":        "needs",
+				"style":        "dotted",
+			})
+			if errors.Is(err, graph.ErrEdgeAlreadyExists) {
+				// the needed step is already a predecessor,
+				// such as the step before it.
+				continue
+			}
+			if errors.Is(err, graph.ErrEdgeCreatesCycle) || source == n.target {
+				return noderr.Wrap(fmt.Errorf("step needs %q, which creates a cycle", id), n.node)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func visitStatement(opts *VisitOpts) error {
@@ -176,26 +452,34 @@ func visitStatement(opts *VisitOpts) error {
 	e := opts.Statement
 	g := opts.G
 
+	if _, ok := e.Body.(step.Sequence); ok {
+		return compileSequence(opts)
+	}
+
 	if opts.Parent != nil {
 		e.Position = opts.Parent.Position
 	}
 
 	e.Position = append(e.Position, opts.Index)
-	err := g.G.AddVertex(*e, graph.VertexAttribute("label", e.Debug()))
+	err := g.store.addStep(*e)
 
 	// it's okay if we've already inserted the vertex on an earlier pass.
 	// this logic might need to be changed if the hashing function changes for nodes,
 	// because at the moment the pass ID is included as part of the hash.
 	// This ensures that we only have collisions for ref nodes.
-	if err != nil && err != graph.ErrVertexAlreadyExists {
+	if err != nil && err != errStepExists {
 		return err
 	}
 
 	key := opts.Statement.Hash()
 
+	if len(e.Needs) > 0 {
+		g.needs = append(g.needs, stepNeeds{target: key, pass: e.Pass, ids: e.Needs, node: e.Node})
+	}
+
 	// if there is a parent, link the current node to it
 	if opts.Parent != nil {
-		err = g.G.AddEdge(key, opts.Parent.Hash())
+		err = g.store.addEdge(key, opts.Parent.Hash(), nil)
 		if err != nil {
 			return err
 		}
@@ -204,7 +488,15 @@ func visitStatement(opts *VisitOpts) error {
 	// if there are no children and we have a node from the previous statement,
 	// link the previous statement node to the entry
 	if len(e.Children) == 0 && opts.Previous != nil {
-		err = g.G.AddEdge(opts.Previous.Hash(), key)
+		var attributes map[string]string
+		if opts.OnFail {
+			attributes = map[string]string{
+				onFailAttribute: "true",
+				"label":         "on fail",
+				"style":         "dashed",
+			}
+		}
+		err = g.store.addEdge(opts.Previous.Hash(), key, attributes)
 		if err != nil {
 			return errors.Wrapf(err, "adding edge to previous node %s", key)
 		}
@@ -213,20 +505,22 @@ func visitStatement(opts *VisitOpts) error {
 	// node-specific compilation steps
 	switch t := e.Body.(type) {
 	case step.Check:
-		ast, issues := opts.Env.Compile(t.Expression)
-		if issues != nil && issues.Err() != nil {
-			return fmt.Errorf("CEL type-check error: %s", issues.Err())
-		}
-		if ast.OutputType() != cel.BoolType {
-			return fmt.Errorf("CEL expression must return a boolean (returned %s instead)", ast.OutputType())
-		}
-
-		prg, err := opts.Env.Program(ast)
+		err = g.compileExpression(opts.Env, key, t.Expression)
 		if err != nil {
-			return fmt.Errorf("CEL program construction error: %s", err)
+			return err
+		}
+	case step.Action:
+		// the 'if' guard of an action is compiled like a check, and
+		// stored under the hash of the action, as it isn't a separate step.
+		if e.If != "" {
+			err = g.compileExpression(opts.Env, key, e.If)
+			if err != nil {
+				return noderr.Wrap(fmt.Errorf("invalid if: %w", err), e.Node)
+			}
 		}
-		g.programs[key] = prg
 	case step.Ref:
+		g.refs = append(g.refs, newNodeRef(g.dialect, e.Pass, append([]int{}, e.Position...), t.Node))
+
 		// unknown refs cannot be compiled - a node reference must be to a start or an end node.
 		if t.Node.Type == node.Unknown {
 			return fmt.Errorf("invalid node %s: did not match any known start or end nodes", e.Body)
@@ -266,11 +560,195 @@ func visitStatement(opts *VisitOpts) error {
 			Depth:         opts.Depth + 1,
 			MaxDepth:      opts.MaxDepth,
 			NumStatements: opts.NumStatements,
+			OnFail:        opts.OnFail,
 		})
 		if err != nil {
 			return noderr.Wrap(err, child.Node)
 		}
 	}
 
+	if e.OnFail.Behavior == step.Route {
+		err = compileFailBranch(opts, e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compileExpression compiles the boolean CEL expression of a check
+// or an action's guard, storing its program under the step's hash.
+func (g *Graph) compileExpression(env *cel.Env, key, expression string) error {
+	ast, err := compileCheck(env, expression, g.stepKeys)
+	if err != nil {
+		return err
+	}
+	if ast.OutputType() != cel.BoolType {
+		return fmt.Errorf("CEL expression must return a boolean (returned %s instead)", ast.OutputType())
+	}
+
+	g.asts[key] = ast
+	if mk, ok := g.checkMemoKey(ast); ok {
+		g.memoKeys[key] = mk
+	}
+	if g.lazyPrograms {
+		g.programs[key] = newLazyProgram(env, ast)
+		return nil
+	}
+	prg, err := newCheckProgram(env, ast)
+	if err != nil {
+		return err
+	}
+	g.programs[key] = prg
+	return nil
+}
+
+// addJoinDeps makes boolean steps be visited after each of the steps they join,
+// so that the branches of a parallel step which contain more than one step
+// are evaluated before they are joined.
+func (g *Graph) addJoinDeps() error {
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return err
+	}
+	for _, k := range hashes {
+		s, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.Body.(step.Boolean); !ok {
+			continue
+		}
+		preds, err := g.store.predecessors(k)
+		if err != nil {
+			return err
+		}
+		for _, p := range preds {
+			g.deps[k] = append(g.deps[k], p.Source)
+		}
+	}
+	return nil
+}
+
+// compileSequence compiles a branch of a parallel step.
+//
+// The sequence itself isn't added to the graph. Its steps are chained
+// together, starting from the step before the parallel step,
+// and the last step is linked to the parallel step.
+func compileSequence(opts *VisitOpts) error {
+	e := opts.Statement
+	if opts.Parent == nil {
+		return fmt.Errorf("a list of steps can only be used as a branch of a parallel step")
+	}
+	if len(e.Children) == 0 {
+		return fmt.Errorf("parallel branches must contain at least one step")
+	}
+
+	// steps are positioned under the sequence, e.g. '1.0.0', '1.0.1'
+	e.Position = append(append([]int{}, opts.Parent.Position...), opts.Index)
+
+	prev := opts.Previous
+	for i, sd := range e.Children {
+		s := sd
+		s.Position = append([]int{}, e.Position...)
+
+		err := visitStatement(&VisitOpts{
+			Statement:     &s,
+			G:             opts.G,
+			Previous:      prev,
+			Index:         i,
+			Env:           opts.Env,
+			Depth:         opts.Depth,
+			MaxDepth:      opts.MaxDepth,
+			NumStatements: opts.NumStatements,
+			OnFail:        i == 0 && opts.OnFail,
+		})
+		if err != nil {
+			return noderr.Wrap(err, s.Node)
+		}
+
+		prev = &s
+	}
+
+	return opts.G.store.addEdge(prev.Hash(), opts.Parent.Hash(), nil)
+}
+
+// compileFailBranch compiles the alternative branch of steps
+// which is followed if an action step fails.
+//
+// The branch is linked to the action with an edge which has the
+// 'on_fail' attribute set, so that it is only followed if the action fails.
+// Like a pass, the branch must end with an outcome.
+func compileFailBranch(opts *VisitOpts, e *step.Step) error {
+	if _, ok := e.Body.(step.Action); !ok {
+		return fmt.Errorf("on_fail can only be used on action steps")
+	}
+
+	branch := e.OnFail.Steps
+	if len(branch) == 0 {
+		return fmt.Errorf("on_fail must contain at least one step")
+	}
+
+	if r, ok := branch[0].Body.(step.Ref); ok && r.Node.Type == node.Start {
+		return noderr.Wrap(fmt.Errorf("on_fail steps cannot contain a start node"), branch[0].Node)
+	}
+
+	err := assertNode(branch[len(branch)-1], node.Outcome)
+	if err != nil {
+		return noderr.Wrap(err, branch[len(branch)-1].Node)
+	}
+
+	prev := e
+	for i, sd := range branch {
+		s := sd
+
+		// branch steps are positioned under the action, e.g. '1.0', '1.1'
+		s.Position = append([]int{}, e.Position...)
+
+		err := visitStatement(&VisitOpts{
+			Statement:     &s,
+			G:             opts.G,
+			Previous:      prev,
+			Index:         i,
+			Env:           opts.Env,
+			MaxDepth:      opts.MaxDepth,
+			NumStatements: len(branch),
+			OnFail:        i == 0,
+		})
+		if err != nil {
+			return noderr.Wrap(err, s.Node)
+		}
+
+		prev = &s
+	}
+
 	return nil
 }
+
+// compileCheck parses and type-checks a check expression.
+// References to steps are validated before the expression is type-checked,
+// so that the error says which step is missing.
+func compileCheck(env *cel.Env, expression string, keys map[string]bool) (*cel.Ast, error) {
+	parsed, issues := env.Parse(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("CEL type-check error: %s", issues.Err())
+	}
+
+	pe, err := cel.AstToParsedExpr(parsed)
+	if err != nil {
+		return nil, err
+	}
+	rewriteStepIndexes(pe.GetExpr())
+
+	err = checkStepRefs(pe.GetExpr(), keys)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Check(cel.ParsedExprToAstWithSource(pe, common.NewTextSource(expression)))
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("CEL type-check error: %s", issues.Err())
+	}
+	return ast, nil
+}
@@ -215,9 +215,262 @@ func Test_Compile(t *testing.T) {
 				"[second.1] if: false -> [B] outcome: B",
 			},
 		},
+		{
+			name: "with step ids",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.WithID("always").Check("true"),
+					s.WithID("either").Boolean(step.Or,
+						s.Check("true"),
+						s.WithID("never").Check("false"),
+					),
+					s.Outcome("D"),
+				),
+			},
+			want: []string{
+				"[A] start: A -> [default.always] if: true",
+				"[default.2.0] if: true -> [default.either] OR",
+				"[default.always] if: true -> [default.2.0] if: true",
+				"[default.always] if: true -> [default.never] if: false",
+				"[default.either] OR -> [D] outcome: D",
+				"[default.never] if: false -> [default.either] OR",
+			},
+		},
+		{
+			name: "invalid duplicate step ids",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.WithID("check").Check("true"),
+					s.WithID("check").Check("false"),
+					s.Outcome("D"),
+				),
+			},
+			wantErr: true,
+		},
+		{
+			name: "with dialect macros",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.Boolean(step.And,
+						s.Check(`oncall()`),
+						s.Check(`in_group("admins")`),
+					),
+					s.Outcome("B"),
+				),
+				InputSchema: &jsoncel.Schema{
+					Properties: map[string]*jsoncel.Schema{
+						"oncall": {
+							Type: jsoncel.Boolean,
+						},
+						"groups": {
+							Type:  jsoncel.Array,
+							Items: &jsoncel.Schema{Type: jsoncel.String},
+						},
+					},
+				},
+			},
+			dialect: &dialect.Dialect{
+				Macros: map[string]dialect.Macro{
+					"oncall":   {Expression: "input.oncall == true"},
+					"in_group": {Params: []string{"group"}, Expression: "group in input.groups"},
+				},
+			},
+			want: []string{
+				`[A] start: A -> [default.1.0] if: oncall()`,
+				`[A] start: A -> [default.1.1] if: in_group(\"admins\")`,
+				`[default.1.0] if: oncall() -> [default.1] AND`,
+				`[default.1.1] if: in_group(\"admins\") -> [default.1] AND`,
+				`[default.1] AND -> [B] outcome: B`,
+			},
+		},
+		{
+			name: "invalid dialect macro argument count",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.Check(`in_group()`),
+					s.Outcome("B"),
+				),
+			},
+			dialect: &dialect.Dialect{
+				Macros: map[string]dialect.Macro{
+					"in_group": {Params: []string{"group"}, Expression: "group in input.groups"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid dialect macro referencing unknown field",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.Check(`oncall()`),
+					s.Outcome("B"),
+				),
+			},
+			dialect: &dialect.Dialect{
+				Macros: map[string]dialect.Macro{
+					"oncall": {Expression: "input.oncall == true"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "with action outputs",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.WithID("approval").Action("approval", &testOutputAction{}),
+					s.Check(`steps.approval.outputs.approver == "bob"`),
+					s.Outcome("B"),
+				),
+			},
+			want: []string{
+				`[A] start: A -> [default.approval] action: approval`,
+				`[default.2] if: steps.approval.outputs.approver == \"bob\" -> [B] outcome: B`,
+				`[default.approval] action: approval -> [default.2] if: steps.approval.outputs.approver == \"bob\"`,
+			},
+		},
+		{
+			name: "invalid unknown action output",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.WithID("approval").Action("approval", &testOutputAction{}),
+					s.Check(`steps.approval.outputs.other == "bob"`),
+					s.Outcome("B"),
+				),
+			},
+			wantErr: true,
+		},
+		{
+			name: "with needs",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.WithID("b").Check("true"),
+					s.Check("false"),
+					s.WithID("d").Needs("b").Check("true"),
+					s.Outcome("E"),
+				),
+			},
+			want: []string{
+				`[A] start: A -> [default.b] if: true`,
+				`[default.2] if: false -> [default.d] if: true`,
+				`[default.b] if: true -> [default.2] if: false`,
+				`[default.b] if: true -> [default.d] if: true`,
+				`[default.d] if: true -> [E] outcome: E`,
+			},
+		},
+		{
+			name: "invalid needs unknown step",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.WithID("b").Needs("c").Check("true"),
+					s.Outcome("E"),
+				),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid needs cycle",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.WithID("b").Needs("c").Check("true"),
+					s.WithID("c").Check("true"),
+					s.Outcome("E"),
+				),
+			},
+			wantErr: true,
+		},
+		{
+			name: "with parallel sequences",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.Boolean(step.And,
+						s.Check("true"),
+						s.Sequence(
+							s.Check("false"),
+							s.Check("1 == 1"),
+						),
+					),
+					s.Outcome("E"),
+				),
+			},
+			want: []string{
+				`[A] start: A -> [default.1.0] if: true`,
+				`[A] start: A -> [default.1.1.0] if: false`,
+				`[default.1.0] if: true -> [default.1] AND`,
+				`[default.1.1.0] if: false -> [default.1.1.1] if: 1 == 1`,
+				`[default.1.1.1] if: 1 == 1 -> [default.1] AND`,
+				`[default.1] AND -> [E] outcome: E`,
+			},
+		},
+		{
+			name: "invalid empty sequence",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.Boolean(step.And, s.Check("true"), s.Sequence()),
+					s.Outcome("E"),
+				),
+			},
+			wantErr: true,
+		},
+		{
+			name: "with on fail route",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.OnFail(step.Route,
+						s.Check("true"),
+						s.Outcome("C"),
+					).Action("approval", nil),
+					s.Outcome("B"),
+				),
+			},
+			want: []string{
+				"[A] start: A -> [default.1] action: approval",
+				"[default.1.0] if: true -> [C] outcome: C",
+				"[default.1] action: approval -> [B] outcome: B",
+				"[default.1] action: approval -> [default.1.0] if: true",
+			},
+		},
+		{
+			name: "invalid on fail route without outcome",
+			give: Compiler{
+				Program: SimpleProgram(
+					s.Start("A"),
+					s.OnFail(step.Route,
+						s.Check("true"),
+					).Action("approval", nil),
+					s.Outcome("B"),
+				),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid duplicate action output ids across passes",
+			give: Compiler{
+				Program: NewProgram().
+					Pass("a", s.Start("A"), s.WithID("approval").Action("approval", &testOutputAction{}), s.Outcome("B")).
+					Pass("b", s.Start("A"), s.WithID("approval").Action("approval", &testOutputAction{}), s.Outcome("B")),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			if tt.dialect != nil {
+				tt.give.Program.Dialect = tt.dialect
+			}
+
 			got, err := tt.give.Compile()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("compile() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,109 @@
+package glide
+
+import (
+	"io"
+)
+
+// Compiled is a compiled workflow, with methods for executing and rendering it.
+//
+// Unlike Graph, Compiled doesn't expose the underlying graph data structure,
+// so the graph can't be modified once it's built. It's safe to cache and to
+// execute concurrently, as long as the workflow's actions are safe for
+// concurrent use: an action is shared by every execution, so it must not
+// record the state of an execution on itself. See Completer and Stateful.
+type Compiled struct {
+	g *Graph
+}
+
+// Build compiles the program into a Compiled workflow.
+func (c *Compiler) Build() (*Compiled, error) {
+	g, err := c.Compile()
+	if err != nil {
+		return nil, err
+	}
+	return g.Freeze(), nil
+}
+
+// Freeze converts a Graph into a Compiled workflow,
+// for users of Compile who are migrating to Build.
+// The graph must not be modified after it is frozen.
+func (g *Graph) Freeze() *Compiled {
+	return &Compiled{g: g}
+}
+
+// Redact returns a copy of the input with its sensitive fields masked. See Graph.Redact.
+func (c *Compiled) Redact(input map[string]any, r Redactor) map[string]any {
+	return c.g.Redact(input, r)
+}
+
+// Execute the workflow. See Graph.Execute.
+func (c *Compiled) Execute(start string, input map[string]any, opts ...ExecuteOption) (*Result, error) {
+	return c.g.Execute(start, input, opts...)
+}
+
+// Render writes the workflow graph in DOT format.
+// If a result is provided, steps are shaded by their state.
+//
+// Rendering doesn't modify the workflow, so a Compiled workflow can be
+// rendered with different results concurrently.
+func (c *Compiled) Render(w io.Writer, res *Result) error {
+	return c.g.render(w, res)
+}
+
+// RenderMermaid writes the workflow graph as a Mermaid flowchart,
+// which can be embedded in Markdown documentation.
+// If a result is provided, steps are shaded by their state.
+func (c *Compiled) RenderMermaid(w io.Writer, res *Result) error {
+	return c.g.renderMermaid(w, res)
+}
+
+// ExportJSON returns the workflow graph as JSON in the Cytoscape.js
+// elements format. See Graph.ExportJSON.
+func (c *Compiled) ExportJSON(res *Result) ([]byte, error) {
+	return c.g.ExportJSON(res)
+}
+
+// ExportLocalizedJSON returns the workflow graph as JSON with the steps
+// labelled in the locale. See Graph.ExportLocalizedJSON.
+func (c *Compiled) ExportLocalizedJSON(res *Result, locale string) ([]byte, error) {
+	return c.g.ExportLocalizedJSON(res, locale)
+}
+
+// ExportRego translates the workflow into a Rego module. See Graph.ExportRego.
+func (c *Compiled) ExportRego(start, pkg string) (*RegoExport, error) {
+	return c.g.ExportRego(start, pkg)
+}
+
+// Warnings returns the non-fatal issues found when compiling the workflow.
+func (c *Compiled) Warnings() []Diagnostic {
+	return append([]Diagnostic{}, c.g.Warnings...)
+}
+
+// RenderSVG writes the workflow graph as an SVG image.
+// If a result is provided, steps are shaded by their state.
+//
+// The image is laid out in Go, so unlike rendering the DOT
+// output with Graphviz, it doesn't need CGO.
+func (c *Compiled) RenderSVG(w io.Writer, res *Result) error {
+	return c.g.renderSVG(w, res)
+}
+
+// Walk calls the visitor for each step in the workflow. See Graph.Walk.
+func (c *Compiled) Walk(v Visitor) error {
+	return c.g.Walk(v)
+}
+
+// Refs lists the start and outcome references in the workflow. See Graph.Refs.
+func (c *Compiled) Refs() []NodeRef {
+	return c.g.Refs()
+}
+
+// FindInput searches for an input which reaches the outcome. See Graph.FindInput.
+func (c *Compiled) FindInput(start, outcome string) (map[string]any, error) {
+	return c.g.FindInput(start, outcome)
+}
+
+// AnalyzeFields lists the input fields referenced by the workflow's checks. See Graph.AnalyzeFields.
+func (c *Compiled) AnalyzeFields() (*FieldReport, error) {
+	return c.g.AnalyzeFields()
+}
@@ -0,0 +1,361 @@
+package glide
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiled_Render(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check("true"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}
+	compiled, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := compiled.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+
+	// render concurrently, to check that rendering with a result
+	// doesn't modify the shared graph.
+	var wg sync.WaitGroup
+	outputs := make([]bytes.Buffer, 2)
+	for i, r := range []*Result{res, nil} {
+		wg.Add(1)
+		go func(i int, r *Result) {
+			defer wg.Done()
+			err := compiled.Render(&outputs[i], r)
+			assert.NoError(t, err)
+		}(i, r)
+	}
+	wg.Wait()
+
+	assert.Contains(t, outputs[0].String(), stateColors[Complete])
+	assert.Contains(t, outputs[0].String(), `label="[default.1] if: true"`)
+	assert.NotContains(t, outputs[1].String(), "fillcolor")
+	assert.Contains(t, outputs[1].String(), `label="[default.1] if: true"`)
+}
+
+func TestCompiled_RenderCompletionEdges(t *testing.T) {
+	c := Compiler{
+		Program: NewProgram().
+			Pass("auto",
+				s.Start("request"),
+				s.Check("true"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			).
+			Pass("manual",
+				s.Start("request"),
+				s.Check("false"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+	}
+	compiled, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := compiled.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = compiled.Render(&buf, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the edges which carried completion are bold, and the others aren't.
+	out := buf.String()
+	assert.Contains(t, out, `"auto.1" -> "approved" [ color="#008000", style="bold", weight=0 ];`)
+	assert.Contains(t, out, `"request" -> "manual.1" [ color="#008000", style="bold", weight=0 ];`)
+	assert.Contains(t, out, `"manual.1" -> "approved" [ weight=0 ];`)
+
+	buf.Reset()
+	err = compiled.Render(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, buf.String(), "bold")
+}
+
+func TestGraph_Freeze(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check("true"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compiled := g.Freeze()
+	res, err := compiled.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+	assert.Equal(t, g.Refs(), compiled.Refs())
+}
+
+func TestCompiled_RenderMermaid(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check(`"a" == "a"`),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}
+	compiled, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := compiled.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = compiled.RenderMermaid(&buf, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `flowchart TD
+    s0["[approved] outcome: approved"]
+    style s0 fill:#00FF00
+    s1["[default.1] if: #quot;a#quot; == #quot;a#quot;"]
+    style s1 fill:#00FF00
+    s2["[request] start: request"]
+    style s2 fill:#00FF00
+    s1 --> s0
+    s2 --> s1
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestCompiled_RenderSVG(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Boolean(step.Or,
+				s.Check(`"a" == "a"`),
+				s.Check("false"),
+			),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}
+	compiled, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := compiled.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = compiled.RenderSVG(&buf, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the output must be well-formed XML.
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := buf.String()
+	assert.Equal(t, 5, strings.Count(out, "<ellipse"))
+	assert.Equal(t, 5, strings.Count(out, `marker-end="url(#arrow)"`))
+	assert.Contains(t, out, "[default.1.0] if: &#34;a&#34; == &#34;a&#34;")
+	assert.Contains(t, out, stateColors[Complete])
+}
+
+func TestCompiled_RenderDescription(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Described(`Checks "a" is "a"`).Check("true"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}
+	compiled, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dot, mermaid, svg bytes.Buffer
+	err = compiled.Render(&dot, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = compiled.RenderMermaid(&mermaid, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = compiled.RenderSVG(&svg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, dot.String(), `tooltip="Checks \"a\" is \"a\""`)
+	assert.Contains(t, mermaid.String(), "    s1[\"[default.1] if: true\"]\n    %% Checks \"a\" is \"a\"\n")
+	assert.Contains(t, svg.String(), "<title>Checks &#34;a&#34; is &#34;a&#34;</title>")
+}
+
+func TestCompiled_RenderDeterministic(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Boolean(step.Or, s.Check("true"), s.Check("false"), s.Check("1 == 1")),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}
+	compiled, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want bytes.Buffer
+	err = compiled.Render(&want, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `strict digraph {
+	"approved" [ label="[approved] outcome: approved", weight=0 ];
+	"default.1" [ label="[default.1] OR", weight=0 ];
+	"default.1" -> "approved" [ weight=0 ];
+	"default.1.0" [ label="[default.1.0] if: true", weight=0 ];
+	"default.1.0" -> "default.1" [ weight=0 ];
+	"default.1.1" [ label="[default.1.1] if: false", weight=0 ];
+	"default.1.1" -> "default.1" [ weight=0 ];
+	"default.1.2" [ label="[default.1.2] if: 1 == 1", weight=0 ];
+	"default.1.2" -> "default.1" [ weight=0 ];
+	"request" [ label="[request] start: request", weight=0 ];
+	"request" -> "default.1.0" [ weight=0 ];
+	"request" -> "default.1.1" [ weight=0 ];
+	"request" -> "default.1.2" [ weight=0 ];
+}
+`, want.String())
+
+	// rendering the same graph again produces identical output,
+	// regardless of map iteration order.
+	for i := 0; i < 10; i++ {
+		var got bytes.Buffer
+		err = compiled.Render(&got, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, want.String(), got.String())
+	}
+}
+
+func TestCompiled_ExecuteConcurrently(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: approval
+        action: approval
+        with:
+          groups: [admins]
+      - check: steps.approval.outputs.approver == input.want
+      - outcome: approved
+`), cf.Dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"want": {Type: jsoncel.String},
+		},
+	}
+	compiled, err := (&Compiler{Program: p, InputSchema: schema}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each execution must see its own approver in the outputs.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := fmt.Sprintf("user%d@example.com", i)
+			res, err := compiled.Execute("request", map[string]any{
+				"want": user,
+				"approvals": []any{
+					map[string]any{"user": user, "groups": []any{"admins"}},
+				},
+			})
+			if assert.NoError(t, err) {
+				assert.Equal(t, "approved", res.Outcome, user)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCompiled_ExecuteStatefulConcurrently(t *testing.T) {
+	a := &testReminderAction{required: 100}
+	compiled, err := (&Compiler{Program: SimpleProgram(
+		s.Start("request"),
+		s.WithID("remind").Action("remind", a),
+		s.Outcome("approved"),
+	)}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// executions of a stateful action are serialised,
+	// so each one sees the state it loaded.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			state := map[string][]byte{"default.remind": []byte(strconv.Itoa(i))}
+			res, err := compiled.Execute("request", nil, WithActionState(state))
+			if assert.NoError(t, err) {
+				assert.Equal(t, strconv.Itoa(i+1), string(res.ActionState["default.remind"]))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
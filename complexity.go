@@ -0,0 +1,161 @@
+package glide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// The default complexity limits for check expressions.
+const (
+	DefaultMaxExpressionNodes   = 40
+	DefaultMaxExpressionNesting = 3
+	DefaultMaxExpressionFields  = 5
+)
+
+// ComplexityLimits are the thresholds above which a check expression is
+// reported as too complex. Complex checks are harder to read and produce
+// less useful diagrams than checks split into nested 'and' and 'or' steps.
+//
+// Limits which aren't provided are set to their defaults.
+// A limit can be disabled by setting it to -1.
+type ComplexityLimits struct {
+	// MaxNodes is the maximum number of nodes in the expression's syntax tree.
+	// 'input.a == 1' has 4 nodes: the '==' call, 'input.a', 'input' and '1'.
+	MaxNodes int
+
+	// MaxNesting is the maximum depth that '&&', '||', '!' and '?:'
+	// operators can be nested inside different operators. 'a && b && c'
+	// has a nesting of 1, and 'a && (b || !c)' has a nesting of 3.
+	MaxNesting int
+
+	// MaxFields is the maximum number of distinct fields, such as
+	// 'input.group', that the expression references.
+	MaxFields int
+}
+
+// withDefaults returns the limits with the defaults set for any limits which aren't provided.
+func (l ComplexityLimits) withDefaults() ComplexityLimits {
+	if l.MaxNodes == 0 {
+		l.MaxNodes = DefaultMaxExpressionNodes
+	}
+	if l.MaxNesting == 0 {
+		l.MaxNesting = DefaultMaxExpressionNesting
+	}
+	if l.MaxFields == 0 {
+		l.MaxFields = DefaultMaxExpressionFields
+	}
+	return l
+}
+
+// exprComplexity measures the complexity of a check expression.
+type exprComplexity struct {
+	nodes   int
+	nesting int
+	fields  map[string]bool
+}
+
+// measureComplexity parses an expression and measures its complexity.
+//
+// Macros aren't expanded when the expression is parsed, so that a call
+// like 'input.groups.exists(g, g == "admins")' is measured as it's written
+// rather than as the comprehension it expands to.
+func measureComplexity(expression string) (exprComplexity, error) {
+	env, err := cel.NewEnv(cel.ClearMacros())
+	if err != nil {
+		return exprComplexity{}, err
+	}
+	parsed, issues := env.Parse(expression)
+	if issues != nil && issues.Err() != nil {
+		return exprComplexity{}, issues.Err()
+	}
+
+	c := exprComplexity{fields: map[string]bool{}}
+	c.walk(parsed.Expr(), "", 0)
+	return c, nil
+}
+
+// logicalOperators are the operators counted towards the nesting of an expression.
+var logicalOperators = map[string]bool{
+	"_&&_":  true,
+	"_||_":  true,
+	"!_":    true,
+	"_?_:_": true,
+}
+
+func (c *exprComplexity) walk(e *exprpb.Expr, parentOp string, nesting int) {
+	if e == nil {
+		return
+	}
+	c.nodes++
+
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		c.fields[k.IdentExpr.GetName()] = true
+	case *exprpb.Expr_SelectExpr:
+		if path, ok := selectPath(e); ok {
+			c.fields[path] = true
+			// the operands of the field are counted as nodes,
+			// but not as fields of their own.
+			c.nodes += strings.Count(path, ".")
+			return
+		}
+		c.walk(k.SelectExpr.GetOperand(), parentOp, nesting)
+	case *exprpb.Expr_CallExpr:
+		fn := k.CallExpr.GetFunction()
+		if logicalOperators[fn] && fn != parentOp {
+			nesting++
+			if nesting > c.nesting {
+				c.nesting = nesting
+			}
+			parentOp = fn
+		}
+		c.walk(k.CallExpr.GetTarget(), parentOp, nesting)
+		for _, a := range k.CallExpr.GetArgs() {
+			c.walk(a, parentOp, nesting)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, el := range k.ListExpr.GetElements() {
+			c.walk(el, parentOp, nesting)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			c.walk(entry.GetMapKey(), parentOp, nesting)
+			c.walk(entry.GetValue(), parentOp, nesting)
+		}
+	}
+}
+
+// selectPath returns the dot-separated path of a field selection
+// like 'input.group.id', including selections tested with 'has()'. The second return value is false if the
+// selection isn't made on an identifier, e.g. 'f(x).id'.
+func selectPath(e *exprpb.Expr) (string, bool) {
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		return k.IdentExpr.GetName(), true
+	case *exprpb.Expr_SelectExpr:
+		operand, ok := selectPath(k.SelectExpr.GetOperand())
+		if !ok {
+			return "", false
+		}
+		return operand + "." + k.SelectExpr.GetField(), true
+	}
+	return "", false
+}
+
+// messages returns a message for each limit the expression is over.
+func (c exprComplexity) messages(expression string, limits ComplexityLimits) []string {
+	var msgs []string
+	if limits.MaxNodes > 0 && c.nodes > limits.MaxNodes {
+		msgs = append(msgs, fmt.Sprintf("check %q is too complex (%d nodes, the limit is %d): consider splitting it into nested 'and' and 'or' steps", expression, c.nodes, limits.MaxNodes))
+	}
+	if limits.MaxNesting > 0 && c.nesting > limits.MaxNesting {
+		msgs = append(msgs, fmt.Sprintf("check %q is too deeply nested (%d levels, the limit is %d): consider splitting it into nested 'and' and 'or' steps", expression, c.nesting, limits.MaxNesting))
+	}
+	if limits.MaxFields > 0 && len(c.fields) > limits.MaxFields {
+		msgs = append(msgs, fmt.Sprintf("check %q references too many fields (%d, the limit is %d): consider splitting it into nested 'and' and 'or' steps", expression, len(c.fields), limits.MaxFields))
+	}
+	return msgs
+}
@@ -0,0 +1,101 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasureComplexity(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		wantNodes   int
+		wantNesting int
+		wantFields  []string
+	}{
+		{name: "comparison", expression: `input.a == 1`, wantNodes: 4, wantFields: []string{"input.a"}},
+		{name: "nested field", expression: `input.group.id == "admins"`, wantNodes: 5, wantFields: []string{"input.group.id"}},
+		{name: "chained and", expression: `input.a && input.b && input.c`, wantNodes: 8, wantNesting: 1, wantFields: []string{"input.a", "input.b", "input.c"}},
+		{name: "mixed operators", expression: `input.a && (input.b || !input.c)`, wantNodes: 9, wantNesting: 3, wantFields: []string{"input.a", "input.b", "input.c"}},
+		{name: "repeated field", expression: `input.a > 1 || input.a < -1`, wantNodes: 9, wantNesting: 1, wantFields: []string{"input.a"}},
+		{name: "has", expression: `has(input.a)`, wantNodes: 3, wantFields: []string{"input.a"}},
+		{name: "macro isn't expanded", expression: `input.groups.exists(g, g == "admins")`, wantNodes: 7, wantFields: []string{"g", "input.groups"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := measureComplexity(tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields []string
+			for f := range got.fields {
+				fields = append(fields, f)
+			}
+			assert.Equal(t, tt.wantNodes, got.nodes)
+			assert.Equal(t, tt.wantNesting, got.nesting)
+			assert.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestCompile_ComplexityWarnings(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"a": {Type: jsoncel.Boolean},
+			"b": {Type: jsoncel.Boolean},
+			"c": {Type: jsoncel.Boolean},
+		},
+	}
+	prog := SimpleProgram(
+		s.Start("request"),
+		s.Check("input.a && (input.b || !input.c)"),
+		s.Outcome("approved"),
+	)
+
+	tests := []struct {
+		name   string
+		limits ComplexityLimits
+		want   []string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:   "too many nodes",
+			limits: ComplexityLimits{MaxNodes: 8},
+			want:   []string{`default.1: check "input.a && (input.b || !input.c)" is too complex (9 nodes, the limit is 8): consider splitting it into nested 'and' and 'or' steps`},
+		},
+		{
+			name:   "too deeply nested",
+			limits: ComplexityLimits{MaxNesting: 2},
+			want:   []string{`default.1: check "input.a && (input.b || !input.c)" is too deeply nested (3 levels, the limit is 2): consider splitting it into nested 'and' and 'or' steps`},
+		},
+		{
+			name:   "too many fields",
+			limits: ComplexityLimits{MaxFields: 2},
+			want:   []string{`default.1: check "input.a && (input.b || !input.c)" references too many fields (3, the limit is 2): consider splitting it into nested 'and' and 'or' steps`},
+		},
+		{
+			name:   "disabled",
+			limits: ComplexityLimits{MaxNodes: -1, MaxNesting: -1, MaxFields: -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: prog, InputSchema: schema, Complexity: tt.limits}
+			g, err := c.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, w := range g.Warnings() {
+				got = append(got, w.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
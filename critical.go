@@ -0,0 +1,161 @@
+package glide
+
+import (
+	"sort"
+	"time"
+
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// CriticalPath is the sequence of steps which determines
+// the expected time for a workflow to reach an outcome.
+type CriticalPath struct {
+	// Outcome is the ID of the outcome.
+	Outcome string
+
+	// Duration is the expected time to reach the outcome,
+	// the sum of the estimates of the steps in the path.
+	Duration time.Duration
+
+	// Steps are the hashes of the steps in the path,
+	// from the start node to the outcome.
+	Steps []string
+}
+
+// CriticalPaths estimates the time to reach each outcome from a start node,
+// using the 'estimate' of each step. Steps without an estimate take no time.
+//
+// Steps which wait for all of their predecessors, such as 'and' steps and steps
+// with 'needs', take as long as their slowest predecessor. 'or' steps and outcomes
+// reached by more than one pass take as long as their fastest predecessor.
+// The path assumes that actions succeed, so on_fail branches aren't included.
+//
+// If an input is provided, steps which can't be reached with the input
+// are excluded, so that the expected time can be compared for different
+// kinds of requests. Fields missing from the input are treated as unknown,
+// and actions aren't called.
+//
+// Outcomes which can't be reached from the start node aren't included.
+// The paths are sorted by the priority of their outcomes, highest first.
+func (g *Graph) CriticalPaths(start string, input map[string]any) ([]CriticalPath, error) {
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	reachable, err := g.reachable(start)
+	if err != nil {
+		return nil, err
+	}
+
+	var state map[string]State
+	if input != nil {
+		res, err := g.execute(start, input, WithPartialInput(), assumeActionsUnknown())
+		if err != nil {
+			return nil, err
+		}
+		state = res.State
+	}
+
+	// elapsed is the expected time for each step to be complete,
+	// and via is the predecessor on the critical path to it.
+	elapsed := map[string]time.Duration{}
+	via := map[string]string{}
+
+	var outcomes []node.Node
+
+	for _, k := range order {
+		if !reachable[k] {
+			continue
+		}
+		s, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+
+		// checks and actions which can't be complete with the input are excluded.
+		// Whether other steps can be reached follows from their predecessors.
+		switch s.Body.(type) {
+		case step.Check, step.Action:
+			if st, ok := state[k]; ok && (st == Inactive || st == Unreachable) {
+				continue
+			}
+		}
+		if k == start {
+			elapsed[k] = s.Estimate
+			continue
+		}
+
+		preds, err := g.store.predecessors(k)
+		if err != nil {
+			return nil, err
+		}
+
+		fastest := waitsForAny(s)
+		found := false
+		for _, e := range preds {
+			if e.Attributes[onFailAttribute] == "true" {
+				continue
+			}
+			t, ok := elapsed[e.Source]
+			if !ok {
+				if fastest {
+					continue
+				}
+				// the step waits for a predecessor which can't be complete.
+				found = false
+				break
+			}
+			if !found || (fastest && t < elapsed[k]) || (!fastest && t > elapsed[k]) {
+				elapsed[k] = t
+				via[k] = e.Source
+				found = true
+			}
+		}
+		if !found {
+			delete(elapsed, k)
+			delete(via, k)
+			continue
+		}
+		elapsed[k] += s.Estimate
+
+		if r, ok := s.Body.(step.Ref); ok && r.Node.Type == node.Outcome {
+			outcomes = append(outcomes, r.Node)
+		}
+	}
+
+	sort.SliceStable(outcomes, func(i, j int) bool {
+		if outcomes[i].Priority != outcomes[j].Priority {
+			return outcomes[i].Priority > outcomes[j].Priority
+		}
+		return outcomes[i].ID < outcomes[j].ID
+	})
+
+	var paths []CriticalPath
+	for _, o := range outcomes {
+		p := CriticalPath{Outcome: o.ID, Duration: elapsed[o.ID]}
+		for k := o.ID; k != ""; k = via[k] {
+			p.Steps = append([]string{k}, p.Steps...)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// waitsForAny returns true if a step is complete once any of its
+// predecessors are, rather than waiting for all of them.
+func waitsForAny(s step.Step) bool {
+	switch t := s.Body.(type) {
+	case step.Boolean:
+		return t.Op == step.Or
+	case step.Ref:
+		return t.Node.Type == node.Outcome
+	}
+	return false
+}
+
+// CriticalPaths estimates the time to reach each outcome from a start node. See Graph.CriticalPaths.
+func (c *Compiled) CriticalPaths(start string, input map[string]any) ([]CriticalPath, error) {
+	return c.g.CriticalPaths(start, input)
+}
@@ -0,0 +1,91 @@
+package glide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCriticalPaths(t *testing.T) {
+	day := 24 * time.Hour
+
+	prog := NewProgram().
+		Pass("oncall",
+			s.Start("request"),
+			s.Check("input.oncall"),
+			s.Named("Approved").Priority(2).Outcome("approved"),
+		).
+		Pass("review",
+			s.Start("request"),
+			s.Boolean(step.And,
+				s.WithID("manager").Estimate(day).Action("approval", &testAction{}),
+				s.WithID("security").Estimate(3*day).Action("approval", &testAction{}),
+			),
+			s.Named("Approved").Priority(2).Outcome("approved"),
+		).
+		Pass("escalate",
+			s.Start("request"),
+			s.Boolean(step.Or,
+				s.WithID("admin").Estimate(4*time.Hour).Action("approval", &testAction{}),
+				s.WithID("owner").Estimate(day).Action("approval", &testAction{}),
+			),
+			s.WithID("ticket").Estimate(time.Hour).Action("approval", &testAction{}),
+			s.Named("Escalated").Priority(1).Outcome("escalated"),
+		)
+
+	c := Compiler{
+		Program: prog,
+		InputSchema: &jsoncel.Schema{
+			Properties: map[string]*jsoncel.Schema{
+				"oncall": {Type: jsoncel.Boolean},
+			},
+		},
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input map[string]any
+		want  []CriticalPath
+	}{
+		{
+			name: "any input",
+			want: []CriticalPath{
+				{Outcome: "approved", Duration: 0, Steps: []string{"request", "oncall.1", "approved"}},
+				{Outcome: "escalated", Duration: 5 * time.Hour, Steps: []string{"request", "escalate.admin", "escalate.1", "escalate.ticket", "escalated"}},
+			},
+		},
+		{
+			name:  "not on call",
+			input: map[string]any{"oncall": false},
+			want: []CriticalPath{
+				{Outcome: "approved", Duration: 3 * day, Steps: []string{"request", "review.security", "review.1", "approved"}},
+				{Outcome: "escalated", Duration: 5 * time.Hour, Steps: []string{"request", "escalate.admin", "escalate.1", "escalate.ticket", "escalated"}},
+			},
+		},
+		{
+			name:  "unknown field",
+			input: map[string]any{},
+			want: []CriticalPath{
+				{Outcome: "approved", Duration: 0, Steps: []string{"request", "oncall.1", "approved"}},
+				{Outcome: "escalated", Duration: 5 * time.Hour, Steps: []string{"request", "escalate.admin", "escalate.1", "escalate.ticket", "escalated"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.CriticalPaths("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package glide
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// csvHeader is the header row of the CSV export.
+var csvHeader = []string{"pass", "position", "id", "name", "type", "expression", "action", "with", "outcome"}
+
+// ExportCSV writes the steps of the program as a CSV table with a row for each step,
+// for reviewing workflows in a spreadsheet rather than as a diagram.
+//
+// The columns are the pass, the position of the step in the pass (e.g. '1.0' for
+// the first child of the second step), its ID, name and type, the expression of a
+// check or the field of a for_each step, the type of an action and its 'with'
+// properties as JSON, and the outcomes which the pass leads to. Preconditions are
+// listed first, followed by the passes in order of their name.
+func (p *Program) ExportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	if len(p.Preconditions) > 0 {
+		rows = appendCSVRows(rows, PreconditionsPass, passOutcomes(p.Preconditions), p.Preconditions, "")
+	}
+
+	var passes []string
+	for id := range p.Workflow {
+		passes = append(passes, id)
+	}
+	sort.Strings(passes)
+
+	for _, id := range passes {
+		steps := p.Workflow[id].Steps
+		rows = appendCSVRows(rows, id, passOutcomes(steps), steps, "")
+	}
+
+	err = cw.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// appendCSVRows appends a row for each step, and their nested steps.
+func appendCSVRows(rows [][]string, pass, outcomes string, steps []step.Step, parent string) [][]string {
+	for i, s := range steps {
+		pos := parent + strconv.Itoa(i)
+
+		row := map[string]string{
+			"pass":     pass,
+			"position": pos,
+			"id":       s.ID,
+			"name":     s.Name,
+			"type":     csvType(s),
+			"outcome":  outcomes,
+		}
+
+		switch t := s.Body.(type) {
+		case step.Ref:
+			// start and outcome steps are named by their node.
+			if row["name"] == "" {
+				row["name"] = t.Node.ID
+			}
+		case step.Check:
+			row["expression"] = t.Expression
+		case step.ForEach:
+			row["expression"] = t.Field
+		case step.Action:
+			row["action"] = t.Name
+			props, err := json.Marshal(t.Action)
+			if err == nil && string(props) != "{}" && string(props) != "null" {
+				row["with"] = string(props)
+			}
+		}
+
+		var values []string
+		for _, col := range csvHeader {
+			values = append(values, row[col])
+		}
+		rows = append(rows, values)
+
+		rows = appendCSVRows(rows, pass, outcomes, s.Children, pos+".")
+		rows = appendCSVRows(rows, pass, outcomes, s.OnFail.Steps, pos+".on_fail.")
+	}
+	return rows
+}
+
+// csvType returns the type of a step, as used in the CSV export.
+func csvType(s step.Step) string {
+	switch s.Body.(type) {
+	case step.Sequence:
+		return "sequence"
+	case step.ForEach:
+		return "for_each"
+	}
+	return exportType(s)
+}
+
+// passOutcomes returns the IDs of the outcomes referenced by the steps,
+// including outcomes which are routed to when an action fails,
+// separated by semicolons.
+func passOutcomes(steps []step.Step) string {
+	var ids []string
+	seen := map[string]bool{}
+
+	var visit func(steps []step.Step)
+	visit = func(steps []step.Step) {
+		for _, s := range steps {
+			if r, ok := s.Body.(step.Ref); ok && r.Node.Type == node.Outcome && !seen[r.Node.ID] {
+				seen[r.Node.ID] = true
+				ids = append(ids, r.Node.ID)
+			}
+			visit(s.Children)
+			visit(s.OnFail.Steps)
+		}
+	}
+	visit(steps)
+
+	return strings.Join(ids, ";")
+}
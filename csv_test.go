@@ -0,0 +1,46 @@
+package glide
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgram_ExportCSV(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+preconditions:
+  - check: input.user != ""
+workflow:
+  default:
+    steps:
+      - start: request
+      - or:
+          - name: On call
+            check: input.oncall
+          - id: manager
+            action: my_action
+            with:
+              property: "managers, admins"
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = p.ExportCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `pass,position,id,name,type,expression,action,with,outcome
+preconditions,0,,,check,"input.user != """"",,,
+default,0,,request,start,,,,approved
+default,1,,,or,,,,approved
+default,1.0,,On call,check,input.oncall,,,approved
+default,1.1,manager,,action,,my_action,"{""Property"":""managers, admins""}",approved
+default,2,,approved,outcome,,,,approved
+`
+	assert.Equal(t, want, buf.String())
+}
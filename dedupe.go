@@ -0,0 +1,274 @@
+package glide
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// SharedPass is the pass of the steps which are shared between passes
+// when the workflow is compiled with DedupeSteps.
+//
+// Shared steps are hashed by their content, e.g. 'shared.5f1d2a0c9b3e7d41',
+// so the hash doesn't change when the passes containing them are renamed.
+const SharedPass = "shared"
+
+// dedupeSteps merges steps with the same content and the same predecessors
+// into a single shared vertex. Steps are hashed with the hashes of their
+// predecessors, so a chain of identical steps at the start of several passes
+// is merged as a whole.
+//
+// Steps are only merged if their predecessors are the same, as a step is
+// complete once any of its predecessors are complete: merging steps which
+// follow different steps would let the workflow skip the steps before them.
+func (g *Graph) dedupeSteps() error {
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return err
+	}
+
+	// contents maps the hashes of steps to their content hashes.
+	// Steps which can't be shared are identified by their hash.
+	contents := map[string]string{}
+	// shared maps the content hashes of steps to the hash of the vertex kept for them.
+	shared := map[string]string{}
+	for _, k := range order {
+		s, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+		content, ok := g.stepContent(s)
+		if !ok {
+			contents[k] = k
+			continue
+		}
+		pres, err := g.store.predecessors(k)
+		if err != nil {
+			return err
+		}
+		h, err := contentHash(content, pres, contents)
+		if err != nil {
+			return err
+		}
+		contents[k] = h
+
+		kept, ok := shared[h]
+		if !ok {
+			shared[h] = k
+			continue
+		}
+
+		// the first duplicate of a step moves it into the shared pass.
+		if kept != SharedPass+"."+h {
+			ks, err := g.store.step(kept)
+			if err != nil {
+				return err
+			}
+			ks.Pass = SharedPass
+			ks.ID = h
+			err = g.moveStep(kept, ks, true)
+			if err != nil {
+				return err
+			}
+			kept = ks.Hash()
+			shared[h] = kept
+			contents[kept] = h
+		}
+
+		err = g.moveStep(k, step.Step{Pass: SharedPass, ID: h}, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveStep replaces the vertex k with the vertex of the step to, moving the
+// edges out of k onto it. If add is true the step is added to the graph and
+// the edges into k are moved too; otherwise the step must already be in the
+// graph with the same edges into it.
+func (g *Graph) moveStep(k string, to step.Step, add bool) error {
+	target := to.Hash()
+
+	pres, err := g.store.predecessors(k)
+	if err != nil {
+		return err
+	}
+	succs, err := g.store.successors(k)
+	if err != nil {
+		return err
+	}
+	var outs []edge
+	for _, succ := range succs {
+		succPres, err := g.store.predecessors(succ)
+		if err != nil {
+			return err
+		}
+		for _, e := range succPres {
+			if e.Source == k {
+				outs = append(outs, e)
+			}
+		}
+	}
+
+	err = g.store.removeStep(k)
+	if err != nil {
+		return err
+	}
+
+	if add {
+		err = g.store.addStep(to)
+		if err != nil {
+			return err
+		}
+		for _, p := range pres {
+			err = g.store.addEdge(p.Source, target, p.Attributes)
+			if err != nil {
+				return err
+			}
+		}
+		if prg, ok := g.programs[k]; ok {
+			g.programs[target] = prg
+			g.asts[target] = g.asts[k]
+		}
+		if mk, ok := g.memoKeys[k]; ok {
+			g.memoKeys[target] = mk
+		}
+		if deps, ok := g.deps[k]; ok {
+			g.deps[target] = deps
+		}
+	}
+
+	for _, e := range outs {
+		if g.hasEdge(target, e.Target) {
+			continue
+		}
+		err = g.store.addEdge(target, e.Target, e.Attributes)
+		if err != nil {
+			return err
+		}
+	}
+
+	delete(g.programs, k)
+	delete(g.asts, k)
+	delete(g.memoKeys, k)
+	delete(g.deps, k)
+	for check, deps := range g.deps {
+		var replaced []string
+		for _, dep := range deps {
+			if dep == k {
+				dep = target
+			}
+			if !containsString(replaced, dep) {
+				replaced = append(replaced, dep)
+			}
+		}
+		g.deps[check] = replaced
+	}
+	return nil
+}
+
+// stepContent returns a representation of everything about a step which
+// affects how it's executed and displayed, other than its position.
+// The second return value is false if the step can't be shared between passes:
+// start and outcome nodes are already shared, steps with an ID or needs are
+// referenced by their pass, and checks which read the 'workflow' variable
+// can behave differently in each pass.
+func (g *Graph) stepContent(s step.Step) (string, bool) {
+	if s.ID != "" || len(s.Needs) > 0 {
+		return "", false
+	}
+
+	var body string
+	switch t := s.Body.(type) {
+	case step.Check:
+		c, err := measureComplexity(t.Expression)
+		if err != nil {
+			return "", false
+		}
+		for field := range c.fields {
+			if field == workflowVar || strings.HasPrefix(field, workflowVar+".") {
+				return "", false
+			}
+		}
+		body = "check: " + t.Expression
+	case step.Boolean:
+		body = t.String()
+	case step.Wait:
+		body = "wait: " + t.After.String()
+	case step.Action:
+		props, err := json.Marshal(t.Action)
+		if err != nil {
+			return "", false
+		}
+		body = "action: " + t.Name + " " + string(props)
+	default:
+		return "", false
+	}
+
+	return body + "\n" + stepAttributes(s), true
+}
+
+// stepAttributes returns a representation of everything about a step
+// other than its body and position which affects how it's executed and
+// displayed. It's shared by stepContent and the diff of two graphs.
+func stepAttributes(s step.Step) string {
+	content := fmt.Sprintf("name: %s\ndescription: %s\nestimate: %s\nremind_every: %s\nescalate_after: %s\non_fail: %d",
+		s.Name, s.Description, s.Estimate, s.RemindEvery, s.EscalateAfter, s.OnFail.Behavior)
+
+	// the schedule is only included if it's set, so that
+	// the hashes of steps without one don't change.
+	if s.When != nil {
+		content += "\nwhen: " + s.When.Spec
+	}
+	if s.If != "" {
+		content += "\nif: " + s.If
+	}
+	if len(s.Names) > 0 {
+		locales := make([]string, 0, len(s.Names))
+		for l := range s.Names {
+			locales = append(locales, l)
+		}
+		sort.Strings(locales)
+		for _, l := range locales {
+			content += fmt.Sprintf("\nnames.%s: %s", l, s.Names[l])
+		}
+	}
+	return content
+}
+
+// contentHash hashes the content of a step together with the content hashes
+// of its predecessors and the attributes of the edges from them.
+func contentHash(content string, pres []edge, contents map[string]string) (string, error) {
+	var lines []string
+	for _, p := range pres {
+		// attributes are marshalled with sorted keys.
+		attrs, err := json.Marshal(p.Attributes)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, contents[p.Source]+" "+string(attrs))
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	h.Write([]byte(content))
+	for _, l := range lines {
+		fmt.Fprintf(h, "\n%s", l)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, el := range list {
+		if el == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package glide
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_DedupeSteps(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  dev:
+    steps:
+      - start: request
+      - check: input.approved
+      - name: Deploy
+        check: input.ticket != ""
+      - check: input.env == "dev"
+      - outcome: approved
+  prod:
+    steps:
+      - start: request
+      - check: input.approved
+      - name: Deploy
+        check: input.ticket != ""
+      - check: input.env == "prod"
+      - outcome: approved
+  test:
+    steps:
+      - start: request
+      - check: input.env == "test"
+      - check: input.approved
+      - check: workflow.pass == "test"
+      - outcome: approved
+  staging:
+    steps:
+      - start: request
+      - check: input.env == "staging"
+      - check: input.approved
+      - check: workflow.pass == "test"
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"approved": {Type: jsoncel.Boolean},
+			"ticket":   {Type: jsoncel.String},
+			"env":      {Type: jsoncel.String},
+		},
+	}
+
+	c := Compiler{Program: p, InputSchema: schema, DedupeSteps: true}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes, err := g.store.hashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var shared, unshared []string
+	for _, k := range hashes {
+		if strings.HasPrefix(k, SharedPass+".") {
+			shared = append(shared, k)
+		} else {
+			unshared = append(unshared, k)
+		}
+	}
+	// the first two steps of the dev and prod passes are shared. The checks in
+	// the test and staging passes follow different steps, or read the pass.
+	assert.Len(t, shared, 2)
+	assert.Equal(t, []string{
+		"approved", "dev.3", "prod.3", "request",
+		"staging.1", "staging.2", "staging.3",
+		"test.1", "test.2", "test.3",
+	}, unshared)
+
+	s, err := g.store.step(shared[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, SharedPass, s.Pass)
+
+	// the shared hashes only depend on the content of the steps.
+	g2, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes2, err := g2.store.hashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, hashes, hashes2)
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		wantOutcome string
+	}{
+		{name: "prod approved", input: map[string]any{"approved": true, "ticket": "T-1", "env": "prod"}, wantOutcome: "approved"},
+		{name: "prod without ticket", input: map[string]any{"approved": true, "ticket": "", "env": "prod"}, wantOutcome: ""},
+		{name: "staging approved", input: map[string]any{"approved": true, "ticket": "", "env": "staging"}, wantOutcome: ""},
+		{name: "test approved", input: map[string]any{"approved": true, "ticket": "", "env": "test"}, wantOutcome: "approved"},
+		{name: "unknown env", input: map[string]any{"approved": true, "ticket": "T-1", "env": "qa"}, wantOutcome: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, res.Outcome)
+		})
+	}
+}
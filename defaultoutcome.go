@@ -0,0 +1,46 @@
+package glide
+
+import (
+	"fmt"
+
+	"github.com/common-fate/glide/pkg/node"
+)
+
+// defaultOutcome returns the outcome which is the result of the program
+// when no other outcome is reached: the program's default outcome if it's
+// set, otherwise the dialect's. It returns nil if neither is set.
+//
+// If the program has a dialect, the default outcome must be one of its outcome nodes.
+func defaultOutcome(p *Program) (*node.Node, error) {
+	id := p.DefaultOutcome
+	if id == "" && p.Dialect != nil {
+		id = p.Dialect.DefaultOutcome
+	}
+	if id == "" {
+		return nil, nil
+	}
+
+	if p.Dialect == nil {
+		return &node.Node{Type: node.Outcome, ID: id}, nil
+	}
+
+	n, ok := p.Dialect.Nodes[id]
+	if !ok || n.Type != node.Outcome {
+		return nil, fmt.Errorf("default outcome %s must be an outcome node in the dialect", id)
+	}
+	n.ID = id
+	return &n, nil
+}
+
+// setDefaultOutcome sets the outcome of a result which didn't reach
+// an outcome to the default outcome, if the graph has one.
+func (g *Graph) setDefaultOutcome(res *Result) {
+	if g.defaultOutcome == nil {
+		return
+	}
+	n := *g.defaultOutcome
+	res.Outcome = n.ID
+	res.OutcomeNode = &n
+	res.Outcomes = []string{n.ID}
+	res.DefaultOutcome = true
+}
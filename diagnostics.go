@@ -0,0 +1,252 @@
+package glide
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/goccy/go-yaml/ast"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Diagnostic is a non-fatal issue found when compiling a workflow,
+// such as a check which is always true.
+//
+// Unlike compile errors, diagnostics don't prevent the workflow from being
+// executed, but they usually mean that the workflow doesn't behave as
+// its author intended.
+type Diagnostic struct {
+	// Step is the hash of the step the diagnostic is about.
+	Step string
+
+	// Message describes the issue.
+	Message string
+
+	// Node is the YAML node of the step, if the workflow was
+	// unmarshalled from YAML. Used to pretty-print the diagnostic.
+	Node ast.Node
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Step, d.Message)
+}
+
+// PrettyPrint the diagnostic along with the YAML node.
+func (d Diagnostic) PrettyPrint(yml []byte) (string, error) {
+	return noderr.NodeError{Node: d.Node, Err: errors.New(d.Message)}.PrettyPrint(yml)
+}
+
+// diagnose finds non-fatal issues in the compiled graph, including checks
+// which are over the complexity limits.
+// The diagnostics are ordered by the position of their steps in the graph.
+func (g *Graph) diagnose(limits ComplexityLimits) ([]Diagnostic, error) {
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+
+	// names counts the steps with each name,
+	// so that duplicates are reported once for every extra step.
+	names := map[string]int{}
+
+	for _, k := range order {
+		s, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+
+		if c, ok := s.Body.(step.Check); ok {
+			if val, constant := g.constantValue(k); constant && val {
+				diags = append(diags, Diagnostic{
+					Step:    k,
+					Message: fmt.Sprintf("check %q is always true, so it has no effect", c.Expression),
+					Node:    s.Node,
+				})
+			} else if constant {
+				diags = append(diags, Diagnostic{
+					Step:    k,
+					Message: fmt.Sprintf("check %q is always false, so the steps after it are never reached through it", c.Expression),
+					Node:    s.Node,
+				})
+			}
+		}
+
+		if c, ok := s.Body.(step.Check); ok {
+			// the expression has already been compiled, so it can be parsed.
+			complexity, err := measureComplexity(c.Expression)
+			if err != nil {
+				return nil, err
+			}
+			for _, msg := range complexity.messages(c.Expression, limits) {
+				diags = append(diags, Diagnostic{Step: k, Message: msg, Node: s.Node})
+			}
+		}
+
+		// start and outcome nodes are shared between passes,
+		// so they are expected to have the same name,
+		// as are preconditions, which are compiled for every start node.
+		if _, ok := s.Body.(step.Ref); ok || s.Name == "" || isPrecondition(s) {
+			continue
+		}
+		names[s.Name]++
+		if names[s.Name] == 2 {
+			diags = append(diags, Diagnostic{
+				Step:    k,
+				Message: fmt.Sprintf("more than one step is named %q: add a unique id to the steps to reference them in checks", s.Name),
+				Node:    s.Node,
+			})
+		}
+	}
+
+	// finding shadowed outcomes evaluates every check,
+	// which would construct every lazily constructed program.
+	if g.lazyPrograms {
+		return diags, nil
+	}
+
+	shadowed, err := g.shadowedOutcomes()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(diags, shadowed...), nil
+}
+
+// constantTrue returns true if the check doesn't reference any
+// variables or call impure functions, and always evaluates to true.
+func (g *Graph) constantTrue(k string) bool {
+	val, constant := g.constantValue(k)
+	return constant && val
+}
+
+// constantValue returns the value of a check and true if the check doesn't
+// reference any variables or call impure functions, so it always has the value.
+func (g *Graph) constantValue(k string) (val bool, constant bool) {
+	a, ok := g.asts[k]
+	if !ok || hasIdent(a.Expr()) || len(impureCalls(a.Expr(), g.impureFunctions())) > 0 {
+		return false, false
+	}
+	v, _, err := g.programs[k].Eval(map[string]any{})
+	if err != nil {
+		return false, false
+	}
+	b, ok := v.Value().(bool)
+	return b, ok
+}
+
+// shadowedOutcomes finds outcomes which can never be the result of the workflow,
+// because an outcome with a higher priority is reached for any input from the
+// same start node.
+func (g *Graph) shadowedOutcomes() ([]Diagnostic, error) {
+	starts := map[string]bool{}
+	outcomes := map[string]node.Node{}
+	for _, r := range g.refs {
+		switch r.Node.Type {
+		case node.Start:
+			starts[r.Node.ID] = true
+		case node.Outcome:
+			outcomes[r.Node.ID] = r.Node
+		}
+	}
+
+	var sorted []string
+	for start := range starts {
+		sorted = append(sorted, start)
+	}
+	sort.Strings(sorted)
+
+	var diags []Diagnostic
+	reported := map[string]bool{}
+
+	for _, start := range sorted {
+		// with every input field unknown, the outcomes which are
+		// complete are reached by any input. Actions aren't evaluated
+		// while compiling, so they could be in any state.
+		res, err := g.execute(start, map[string]any{}, WithPartialInput(), assumeActionsUnknown())
+		if err != nil {
+			return nil, err
+		}
+		reachable, err := g.reachable(start)
+		if err != nil {
+			return nil, err
+		}
+
+		var always *node.Node
+		for id, o := range outcomes {
+			o := o
+			if res.State[id] == Complete && (always == nil || o.Priority > always.Priority) {
+				always = &o
+			}
+		}
+		if always == nil {
+			continue
+		}
+
+		var ids []string
+		for id, o := range outcomes {
+			if reachable[id] && o.Priority < always.Priority && !reported[id] {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			reported[id] = true
+			s, err := g.store.step(id)
+			if err != nil {
+				return nil, err
+			}
+			diags = append(diags, Diagnostic{
+				Step:    id,
+				Message: fmt.Sprintf("outcome %s is never the result from %s, because %s has a higher priority and is always reached", id, start, always.ID),
+				Node:    s.Node,
+			})
+		}
+	}
+
+	return diags, nil
+}
+
+// hasIdent returns true if the expression references a variable.
+func hasIdent(e *exprpb.Expr) bool {
+	if e == nil {
+		return false
+	}
+
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		return true
+	case *exprpb.Expr_SelectExpr:
+		return hasIdent(k.SelectExpr.GetOperand())
+	case *exprpb.Expr_CallExpr:
+		if hasIdent(k.CallExpr.GetTarget()) {
+			return true
+		}
+		for _, a := range k.CallExpr.GetArgs() {
+			if hasIdent(a) {
+				return true
+			}
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, el := range k.ListExpr.GetElements() {
+			if hasIdent(el) {
+				return true
+			}
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			if hasIdent(entry.GetMapKey()) || hasIdent(entry.GetValue()) {
+				return true
+			}
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		// comprehensions declare their own variables.
+		return true
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_Warnings(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"approved": {Type: jsoncel.Boolean},
+		},
+	}
+
+	tests := []struct {
+		name string
+		give *Program
+		want []string
+	}{
+		{
+			name: "no warnings",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("input.approved"),
+				s.Outcome("approved"),
+			),
+		},
+		{
+			name: "constant true check",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("1 < 2"),
+				s.Check("input.approved"),
+				s.Outcome("approved"),
+			),
+			want: []string{`default.1: check "1 < 2" is always true, so it has no effect`},
+		},
+		{
+			name: "constant false check",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("false"),
+				s.Outcome("approved"),
+			),
+			want: []string{`default.1: check "false" is always false, so the steps after it are never reached through it`},
+		},
+		{
+			name: "duplicate step names",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Named("review").Check("input.approved"),
+				s.Named("review").Check("input.approved"),
+				s.Outcome("approved"),
+			),
+			want: []string{`default.2: more than one step is named "review": add a unique id to the steps to reference them in checks`},
+		},
+		{
+			name: "shadowed outcome",
+			give: NewProgram().
+				Pass("allow",
+					s.Start("request"),
+					step.Step{Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: "approved", Priority: 2}}},
+				).
+				Pass("deny",
+					s.Start("request"),
+					s.Check("input.approved"),
+					step.Step{Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: "denied", Priority: 1}}},
+				),
+			want: []string{"denied: outcome denied is never the result from request, because approved has a higher priority and is always reached"},
+		},
+		{
+			// actions aren't evaluated while compiling, so an outcome
+			// after an action isn't always reached, even if the action
+			// would be complete.
+			name: "outcome after action not shadowing",
+			give: NewProgram().
+				Pass("allow",
+					s.Start("request"),
+					s.Action("approve", &testAction{complete: true}),
+					step.Step{Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: "approved", Priority: 2}}},
+				).
+				Pass("deny",
+					s.Start("request"),
+					s.Check("input.approved"),
+					step.Step{Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: "denied", Priority: 1}}},
+				),
+		},
+		{
+			name: "higher priority outcome not shadowed",
+			give: NewProgram().
+				Pass("allow",
+					s.Start("request"),
+					step.Step{Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: "approved", Priority: 1}}},
+				).
+				Pass("deny",
+					s.Start("request"),
+					s.Check("input.approved"),
+					step.Step{Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: "denied", Priority: 2}}},
+				),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give, InputSchema: schema}
+			g, err := c.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, w := range g.Warnings() {
+				got = append(got, w.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
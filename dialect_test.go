@@ -1,8 +1,14 @@
 package glide
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+
 	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
 	"github.com/common-fate/glide/pkg/node"
+	"github.com/google/cel-go/cel"
 )
 
 // testDialect is a Glide dialect used
@@ -22,6 +28,17 @@ var testDialect = dialect.Dialect{
 	},
 }
 
+// yamlTestDialect is testDialect with a priority on the 'approved' outcome,
+// for tests which unmarshal workflows from YAML, as outcomes in YAML
+// workflows must have a priority.
+var yamlTestDialect = dialect.Dialect{
+	Actions: testDialect.Actions,
+	Nodes: map[string]node.Node{
+		"request":  {Type: node.Start},
+		"approved": {Type: node.Outcome, Priority: 1},
+	},
+}
+
 type testAction struct {
 	Property string `yaml:"property"`
 	// complete will mark the action as complete
@@ -29,6 +46,116 @@ type testAction struct {
 	complete bool
 }
 
-func (t *testAction) Complete(input any) (bool, error) {
+func (t *testAction) Complete(ctx context.Context, input any) (bool, error) {
 	return t.complete, nil
 }
+
+// testOutputAction is an action which records
+// the user who approved it as an output.
+type testOutputAction struct {
+	// approver is set as the output when the action is complete.
+	// If empty, the action is not complete.
+	approver string
+}
+
+func (t *testOutputAction) Complete(ctx context.Context, input any) (bool, error) {
+	return t.approver != "", nil
+}
+
+func (t *testOutputAction) OutputSchema() *jsoncel.Schema {
+	return &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"approver": {Type: jsoncel.String},
+		},
+	}
+}
+
+func (t *testOutputAction) Outputs(ctx context.Context, input any) (map[string]any, error) {
+	return map[string]any{"approver": t.approver}, nil
+}
+
+// testFailAction is an action which can fail.
+type testFailAction struct {
+	// failed will mark the action as failed.
+	failed bool
+	// err is returned from Complete.
+	err error
+}
+
+func (t *testFailAction) Failed(ctx context.Context, input any) (bool, error) {
+	return t.failed, nil
+}
+
+func (t *testFailAction) Complete(ctx context.Context, input any) (bool, error) {
+	return t.err == nil, t.err
+}
+
+// testEffectAction is an action with a side effect.
+type testEffectAction struct {
+	complete bool
+}
+
+func (t *testEffectAction) Complete(ctx context.Context, input any) (bool, error) {
+	return t.complete, nil
+}
+
+func (t *testEffectAction) Effect(ctx context.Context, input any) (any, error) {
+	return "notify", nil
+}
+
+// testReminderAction is an action which counts the reminders it has sent.
+// It is complete once it has sent the required number of reminders.
+type testReminderAction struct {
+	required int
+	sent     int
+}
+
+func (t *testReminderAction) Complete(ctx context.Context, input any) (bool, error) {
+	t.sent++
+	return t.sent >= t.required, nil
+}
+
+func (t *testReminderAction) SaveState() ([]byte, error) {
+	return []byte(strconv.Itoa(t.sent)), nil
+}
+
+func (t *testReminderAction) LoadState(data []byte) error {
+	if data == nil {
+		t.sent = 0
+		return nil
+	}
+	sent, err := strconv.Atoi(string(data))
+	if err != nil {
+		return err
+	}
+	t.sent = sent
+	return nil
+}
+
+// testMutatingAction is an action which modifies its input.
+type testMutatingAction struct{}
+
+func (t *testMutatingAction) Complete(ctx context.Context, input any) (bool, error) {
+	m := input.(map[string]any)
+	m["approved"] = true
+	m["groups"].([]string)[0] = "modified"
+	return true, nil
+}
+
+// testValidatedAction is an action which checks
+// that its property is a field of the input schema.
+type testValidatedAction struct {
+	Field string `yaml:"field"`
+}
+
+func (t *testValidatedAction) Complete(ctx context.Context, input any) (bool, error) {
+	return false, nil
+}
+
+func (t *testValidatedAction) ValidateCompile(env *cel.Env, schema *jsoncel.Schema) error {
+	if _, ok := schema.Properties[t.Field]; !ok {
+		return fmt.Errorf("field %s is not in the input schema", t.Field)
+	}
+	return nil
+}
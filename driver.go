@@ -0,0 +1,54 @@
+package glide
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/common-fate/glide/pkg/dialect"
+)
+
+// Drive executes the workflow, and if it reaches an outcome, calls the
+// dialect's handler for the outcome so that its side effects are carried out.
+//
+// Execute never calls outcome handlers, so that executing a workflow is
+// free of side effects. Drive is for host applications which let the dialect
+// act on outcomes, such as granting access once a request is approved.
+//
+// The context is passed to the methods of actions and to the handler.
+// If the handler returns an error, the result is returned along with the error.
+// If a step can't be evaluated, the handler isn't called, and like Execute, the
+// result is returned along with the *ExecutionError.
+func (c *Compiled) Drive(ctx context.Context, start string, input map[string]any, opts ...ExecuteOption) (*Result, error) {
+	res, err := c.Execute(start, input, append([]ExecuteOption{WithContext(ctx)}, opts...)...)
+	if err != nil {
+		return res, err
+	}
+	return res, c.g.handleOutcome(ctx, start, input, res)
+}
+
+// handleOutcome calls the dialect's handler for the outcome of the result.
+func (g *Graph) handleOutcome(ctx context.Context, start string, input map[string]any, res *Result) error {
+	if res.Outcome == "" || g.dialect == nil {
+		return nil
+	}
+	h, ok := g.dialect.Outcomes[res.Outcome]
+	if !ok {
+		return nil
+	}
+
+	o := dialect.Outcome{
+		ID:      res.Outcome,
+		Start:   start,
+		Input:   input,
+		Effects: res.Effects,
+	}
+	if res.OutcomeNode != nil {
+		o.Node = *res.OutcomeNode
+	}
+
+	err := h.OnOutcome(ctx, o)
+	if err != nil {
+		return fmt.Errorf("handling outcome %s: %w", res.Outcome, err)
+	}
+	return nil
+}
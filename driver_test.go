@@ -0,0 +1,151 @@
+package glide
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiled_Drive(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      map[string]any
+		handlerErr error
+		wantCalls  []dialect.Outcome
+		wantErr    string
+	}{
+		{
+			name:  "outcome reached",
+			input: map[string]any{"ok": true},
+			wantCalls: []dialect.Outcome{
+				{
+					ID:    "approved",
+					Node:  node.Node{Type: node.Outcome, ID: "approved", Priority: 1},
+					Start: "request",
+					Input: map[string]any{"ok": true},
+				},
+			},
+		},
+		{
+			name:  "outcome not reached",
+			input: map[string]any{"ok": false},
+		},
+		{
+			name:       "handler error",
+			input:      map[string]any{"ok": true},
+			handlerErr: errors.New("grant failed"),
+			wantCalls: []dialect.Outcome{
+				{
+					ID:    "approved",
+					Node:  node.Node{Type: node.Outcome, ID: "approved", Priority: 1},
+					Start: "request",
+					Input: map[string]any{"ok": true},
+				},
+			},
+			wantErr: "handling outcome approved: grant failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []dialect.Outcome
+
+			d := dialect.New().Start("request")
+			d.Nodes["approved"] = node.Node{Type: node.Outcome, Priority: 1}
+			d.OnOutcome("approved", dialect.OutcomeHandlerFunc(func(ctx context.Context, o dialect.Outcome) error {
+				calls = append(calls, o)
+				return tt.handlerErr
+			}))
+
+			p, err := Unmarshal([]byte(serviceWorkflow), *d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := Compiler{
+				Program: p,
+				InputSchema: &jsoncel.Schema{
+					Properties: map[string]*jsoncel.Schema{
+						"ok": {Type: jsoncel.Boolean},
+					},
+				},
+			}
+			g, err := c.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// executing the workflow never calls the handler.
+			_, err = g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Empty(t, calls)
+
+			res, err := g.Drive(context.Background(), "request", tt.input)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if assert.NotNil(t, res) {
+				assert.Equal(t, tt.input["ok"] == true, res.Outcome == "approved")
+			}
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestCompiled_Drive_StepErrors(t *testing.T) {
+	var calls []dialect.Outcome
+	d := dialect.New().Start("request")
+	d.Nodes["approved"] = node.Node{Type: node.Outcome, Priority: 1}
+	d.OnOutcome("approved", dialect.OutcomeHandlerFunc(func(ctx context.Context, o dialect.Outcome) error {
+		calls = append(calls, o)
+		return nil
+	}))
+
+	p, err := Unmarshal([]byte(serviceWorkflow), *d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Compiler{
+		Program: p,
+		InputSchema: &jsoncel.Schema{
+			Properties: map[string]*jsoncel.Schema{
+				"ok": {Type: jsoncel.Boolean},
+			},
+		},
+	}
+	g, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'ok' is missing from the input, so the check can't be evaluated.
+	res, err := g.Drive(context.Background(), "request", map[string]any{})
+
+	var ee *ExecutionError
+	if !errors.As(err, &ee) {
+		t.Fatalf("expected ExecutionError, got %v", err)
+	}
+	if assert.NotNil(t, res) {
+		assert.Equal(t, ee.Errors, res.StepErrors)
+		assert.Equal(t, Complete, res.State["request"])
+	}
+	assert.Empty(t, calls)
+}
+
+func TestDialect_OutcomeHandlerValidation(t *testing.T) {
+	d := dialect.New().Start("request")
+	d.Nodes["approved"] = node.Node{Type: node.Outcome, Priority: 1}
+	d.OnOutcome("request", dialect.OutcomeHandlerFunc(func(ctx context.Context, o dialect.Outcome) error {
+		return nil
+	}))
+
+	_, err := Unmarshal([]byte(serviceWorkflow), *d)
+	assert.ErrorContains(t, err, "outcome handler request must be for an outcome node")
+}
@@ -0,0 +1,312 @@
+package glide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/common-fate/glide/pkg/dialect"
+)
+
+// ErrStateNotFound is returned by a StateStore for requests which have no state.
+var ErrStateNotFound = errors.New("state not found")
+
+// ErrVersionConflict is returned by a StateStore if the state of a request
+// was saved by another writer since it was read.
+var ErrVersionConflict = errors.New("state was modified concurrently")
+
+// DefaultConflictRetries is the number of times an Engine handles
+// an event again if the state of the request is modified concurrently.
+const DefaultConflictRetries = 3
+
+// RequestState is the state of a request's workflow, which is saved
+// between events so that the next event continues where it left off.
+type RequestState struct {
+	// Version is incremented each time the state is saved,
+	// and is zero for requests which have no saved state.
+	Version int
+
+	// Input is the input of the last event.
+	Input map[string]any
+
+	// UpdatedAt is the time of the last event.
+	UpdatedAt time.Time
+
+	// Outcome is the outcome of the workflow, if it has reached one.
+	Outcome string
+
+	// Failed is true if the workflow failed.
+	Failed bool
+
+	// State maps vertex hashes to the state of the step.
+	State map[string]State
+
+	// ActionState is the internal state of Stateful actions.
+	ActionState map[string][]byte
+
+	// Timers are the times which waits became active.
+	Timers map[string]time.Time
+}
+
+// StateStore saves the state of requests between events, such as in a database.
+//
+// Stores use optimistic concurrency: Put only saves the state if the stored
+// version is still the version which was read, so that events for the same
+// request which are handled at the same time don't overwrite each other.
+type StateStore interface {
+	// Get returns the state of the request, or ErrStateNotFound
+	// if the request has no saved state.
+	Get(ctx context.Context, requestID string) (*RequestState, error)
+
+	// Put saves the state of the request with its version incremented, if the
+	// saved version is state.Version. If the version has changed since the
+	// state was read, it returns ErrVersionConflict. New requests are saved
+	// with a Version of zero.
+	Put(ctx context.Context, requestID string, state RequestState) error
+}
+
+// Transition is a change to the state of a request, emitted by
+// an Engine to its sink after each event it handles.
+type Transition struct {
+	// RequestID is the ID of the request.
+	RequestID string
+
+	// Time is the time of the event.
+	Time time.Time
+
+	// Result of executing the workflow with the event's input.
+	Result *Result
+
+	// Changes maps the hashes of the steps whose state changed to
+	// their new state. For the first event of a request, it contains
+	// every step which isn't Inactive.
+	Changes map[string]State
+
+	// Version is the version of the request's state which was saved.
+	Version int
+}
+
+// Sink receives the transitions of the requests handled by an Engine,
+// so that the host application can notify approvers, carry out effects
+// or record an audit log.
+type Sink interface {
+	Emit(ctx context.Context, t Transition) error
+}
+
+// SinkFunc is a Sink implemented by a function.
+type SinkFunc func(ctx context.Context, t Transition) error
+
+func (f SinkFunc) Emit(ctx context.Context, t Transition) error {
+	return f(ctx, t)
+}
+
+// EngineOption configures an Engine.
+type EngineOption func(*Engine)
+
+// WithSink emits the transition of each event the engine handles to the sink.
+func WithSink(s Sink) EngineOption {
+	return func(e *Engine) {
+		e.sink = s
+	}
+}
+
+// WithConflictRetries sets how many times the engine handles an event again
+// if the state of the request is modified concurrently.
+func WithConflictRetries(n int) EngineOption {
+	return func(e *Engine) {
+		e.retries = n
+	}
+}
+
+// WithEngineExecuteOptions sets the options which each event is executed with.
+func WithEngineExecuteOptions(opts ...ExecuteOption) EngineOption {
+	return func(e *Engine) {
+		e.opts = append(e.opts, opts...)
+	}
+}
+
+// Engine runs the workflows of requests as events occur, saving
+// the state of each request in a StateStore between events.
+//
+// Where Execute evaluates a workflow for a single input, an Engine
+// carries the internal state of actions and the timers of waits from
+// each event to the next, as Replay does for a list of events.
+// An Engine is safe for use by multiple goroutines.
+type Engine struct {
+	workflow *Compiled
+	start    string
+	store    StateStore
+	sink     Sink
+	retries  int
+	opts     []ExecuteOption
+}
+
+// NewEngine returns an Engine which runs the workflow
+// from the start node, saving state in the store.
+func NewEngine(workflow *Compiled, start string, store StateStore, opts ...EngineOption) *Engine {
+	e := &Engine{
+		workflow: workflow,
+		start:    start,
+		store:    store,
+		retries:  DefaultConflictRetries,
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// HandleEvent executes the workflow of the request with the input of the event,
+// continuing from the saved state of the request, and saves the new state.
+// If the state is modified concurrently, the event is handled again with
+// the latest state, up to the number of conflict retries.
+//
+// Once the state is saved, the transition is emitted to the sink. Steps which
+// could not be evaluated are recorded in the result's StepErrors.
+func (e *Engine) HandleEvent(ctx context.Context, requestID string, event Event) (*Result, error) {
+	return e.handle(ctx, requestID, event.Time, func(*RequestState) (map[string]any, error) {
+		return event.Input, nil
+	})
+}
+
+// HandleInputEvent folds the event into the input of the request's last event
+// with the reducers of the workflow's dialect, and executes the workflow with
+// the new input. See ReduceInput and HandleEvent.
+func (e *Engine) HandleInputEvent(ctx context.Context, requestID string, event dialect.InputEvent) (*Result, error) {
+	d := e.workflow.g.dialect
+	if d == nil {
+		return nil, errors.New("the workflow has no dialect to reduce events with")
+	}
+	return e.handle(ctx, requestID, event.Time, func(prev *RequestState) (map[string]any, error) {
+		return ReduceInput(*d, prev.Input, event)
+	})
+}
+
+// handle executes the workflow of the request, with the input returned for
+// the saved state of the request, retrying if the state is modified concurrently.
+func (e *Engine) handle(ctx context.Context, requestID string, at time.Time, input func(prev *RequestState) (map[string]any, error)) (*Result, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := e.handleEvent(ctx, requestID, at, input)
+		if errors.Is(err, ErrVersionConflict) && attempt < e.retries {
+			continue
+		}
+		return res, err
+	}
+}
+
+func (e *Engine) handleEvent(ctx context.Context, requestID string, at time.Time, input func(prev *RequestState) (map[string]any, error)) (*Result, error) {
+	prev, err := e.store.Get(ctx, requestID)
+	if errors.Is(err, ErrStateNotFound) {
+		prev, err = &RequestState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading state of request %s: %w", requestID, err)
+	}
+
+	event := Event{Time: at}
+	event.Input, err = input(prev)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := append(append([]ExecuteOption{}, e.opts...),
+		WithActionState(prev.ActionState),
+		WithTimers(prev.Timers),
+		WithContext(ctx),
+	)
+	if !event.Time.IsZero() {
+		opts = append(opts, WithTime(event.Time))
+	}
+
+	res, err := e.workflow.Execute(e.start, event.Input, opts...)
+	var ee *ExecutionError
+	if err != nil && !errors.As(err, &ee) {
+		return nil, err
+	}
+
+	changes := map[string]State{}
+	for k, s := range res.State {
+		if prev.State == nil {
+			if s != Inactive {
+				changes[k] = s
+			}
+			continue
+		}
+		if prev.State[k] != s {
+			changes[k] = s
+		}
+	}
+
+	next := RequestState{
+		Version:     prev.Version,
+		Input:       event.Input,
+		UpdatedAt:   event.Time,
+		Outcome:     res.Outcome,
+		Failed:      res.Failed,
+		State:       res.State,
+		ActionState: res.ActionState,
+		Timers:      res.Timers,
+	}
+	err = e.store.Put(ctx, requestID, next)
+	if err != nil {
+		return nil, fmt.Errorf("saving state of request %s: %w", requestID, err)
+	}
+
+	if e.sink != nil {
+		err = e.sink.Emit(ctx, Transition{
+			RequestID: requestID,
+			Time:      event.Time,
+			Result:    res,
+			Changes:   changes,
+			Version:   prev.Version + 1,
+		})
+		if err != nil {
+			return res, fmt.Errorf("emitting transition of request %s: %w", requestID, err)
+		}
+	}
+
+	return res, nil
+}
+
+// State returns the saved state of the request, or ErrStateNotFound.
+func (e *Engine) State(ctx context.Context, requestID string) (*RequestState, error) {
+	return e.store.Get(ctx, requestID)
+}
+
+// MemoryStateStore is a StateStore which keeps state in memory,
+// for tests and for applications which run in a single process.
+// It is safe for use by multiple goroutines.
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[string]RequestState
+}
+
+// NewMemoryStateStore returns an empty MemoryStateStore.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: map[string]RequestState{}}
+}
+
+func (m *MemoryStateStore) Get(ctx context.Context, requestID string) (*RequestState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.states[requestID]
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+	return &s, nil
+}
+
+func (m *MemoryStateStore) Put(ctx context.Context, requestID string, state RequestState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.states[requestID].Version != state.Version {
+		return ErrVersionConflict
+	}
+	state.Version++
+	m.states[requestID] = state
+	return nil
+}
@@ -0,0 +1,135 @@
+package glide
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_HandleEvent(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Check("input.approved"),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)
+
+	c, err := Build(p, &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{"approved": {Type: jsoncel.Boolean}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var transitions []Transition
+	sink := SinkFunc(func(ctx context.Context, tr Transition) error {
+		transitions = append(transitions, tr)
+		return nil
+	})
+
+	store := NewMemoryStateStore()
+	e := NewEngine(c, "request", store, WithSink(sink))
+	ctx := context.Background()
+
+	requested := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	res, err := e.HandleEvent(ctx, "req_1", Event{Time: requested, Input: map[string]any{"approved": false}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", res.Outcome)
+
+	res, err = e.HandleEvent(ctx, "req_1", Event{Time: requested.Add(time.Hour), Input: map[string]any{"approved": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+
+	state, err := e.State(ctx, "req_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, state.Version)
+	assert.Equal(t, "approved", state.Outcome)
+	assert.Equal(t, requested.Add(time.Hour), state.UpdatedAt)
+
+	assert.Len(t, transitions, 2)
+	assert.Equal(t, map[string]State{"request": Complete}, transitions[0].Changes)
+	assert.Equal(t, map[string]State{"default.1": Complete, "approved": Complete}, transitions[1].Changes)
+	assert.Equal(t, 2, transitions[1].Version)
+
+	_, err = e.State(ctx, "req_2")
+	assert.ErrorIs(t, err, ErrStateNotFound)
+}
+
+// conflictStore is a StateStore which modifies the state of
+// a request before the first conflicts writes of it.
+type conflictStore struct {
+	*MemoryStateStore
+	conflicts int
+}
+
+func (c *conflictStore) Put(ctx context.Context, requestID string, state RequestState) error {
+	if c.conflicts > 0 {
+		c.conflicts--
+		// another writer saves the state first.
+		err := c.MemoryStateStore.Put(ctx, requestID, state)
+		if err != nil {
+			return err
+		}
+	}
+	return c.MemoryStateStore.Put(ctx, requestID, state)
+}
+
+func TestEngine_VersionConflict(t *testing.T) {
+	c, err := Build(SimpleProgram(s.Start("request"), s.Named("Approved").Priority(1).Outcome("approved")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &conflictStore{MemoryStateStore: NewMemoryStateStore(), conflicts: 2}
+	e := NewEngine(c, "request", store)
+
+	res, err := e.HandleEvent(context.Background(), "req_1", Event{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+
+	// the two concurrent writes and the retried event.
+	state, err := store.Get(context.Background(), "req_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, state.Version)
+
+	store = &conflictStore{MemoryStateStore: NewMemoryStateStore(), conflicts: 5}
+	e = NewEngine(c, "request", store, WithConflictRetries(1))
+
+	_, err = e.HandleEvent(context.Background(), "req_1", Event{})
+	assert.ErrorIs(t, err, ErrVersionConflict)
+}
+
+func TestEngine_Context(t *testing.T) {
+	c, err := Build(SimpleProgram(
+		s.Start("request"),
+		s.WithID("ctx").Action("ctx", testContextAction{}),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngine(c, "request", NewMemoryStateStore())
+
+	// actions are given the context of the event.
+	ctx := context.WithValue(context.Background(), testContextKey{}, true)
+	res, err := e.HandleEvent(ctx, "req_1", Event{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+}
@@ -1,12 +1,11 @@
 package glide
 
 import (
-	"context"
 	"testing"
 
 	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
 	"github.com/common-fate/glide/pkg/noderr"
-	"github.com/goccy/go-yaml"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
@@ -58,19 +57,218 @@ workflow:
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			d := dialect.New()
-			var got Program
+			_, err := NewDecoder(*dialect.New()).Decode([]byte(tt.give))
+			var ne noderr.NodeError
+			if errors.As(err, &ne) {
+				assert.Equal(t, tt.wantErrPath, ne.Node.GetPath())
+			} else {
+				t.Fatal("error was not noderr.NodeError")
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
 
-			ctx := Use(context.Background(), *d)
+func TestUnmarshal_UndefinedVariable(t *testing.T) {
+	tests := []struct {
+		name        string
+		give        string
+		wantErrPath string
+		wantErr     string
+	}{
+		{
+			name: "in name",
+			give: `
+workflow:
+  default:
+    steps:
+      - name: ${var.missing}
+        check: "true"
+`,
+			wantErrPath: "$.workflow.default.steps[0].name",
+			wantErr:     "undefined variable ${var.missing}",
+		},
+		{
+			name: "in action properties",
+			give: `
+workflow:
+  default:
+    steps:
+      - action: my_action
+        with:
+          property: ${var.missing}
+`,
+			wantErrPath: "$.workflow.default.steps[0].with.property",
+			wantErr:     "undefined variable ${var.missing}",
+		},
+	}
 
-			err := yaml.UnmarshalContext(ctx, []byte(tt.give), &got)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(tt.give), yamlTestDialect)
 			var ne noderr.NodeError
 			if errors.As(err, &ne) {
 				assert.Equal(t, tt.wantErrPath, ne.Node.GetPath())
 			} else {
-				t.Fatal("error was not noderr.NodeError")
+				t.Fatalf("error was not noderr.NodeError: %v", err)
 			}
 			assert.EqualError(t, err, tt.wantErr)
 		})
 	}
 }
+
+func TestUnmarshal_AnchorErrorPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		give        string
+		wantErrPath string
+		wantErr     string
+		// wantLine is the line highlighted in the pretty printed error,
+		// which is where the anchor was used.
+		wantLine string
+	}{
+		{
+			name: "merge key",
+			give: `
+defaults: &defaults
+  action: missing
+workflow:
+  default:
+    steps:
+      - start: request
+      - <<: *defaults
+        name: Missing
+      - outcome: approved
+`,
+			wantErrPath: "$.workflow.default.steps[1].action",
+			wantErr:     "unknown action type missing",
+			wantLine:    ">  8 |",
+		},
+		{
+			name: "alias",
+			give: `
+common:
+  with: &with
+    property: ${var.missing}
+workflow:
+  default:
+    steps:
+      - start: request
+      - action: my_action
+        with: *with
+      - outcome: approved
+`,
+			wantErrPath: "$.workflow.default.steps[1].with.property",
+			wantErr:     "undefined variable ${var.missing}",
+			wantLine:    "> 10 |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(tt.give), yamlTestDialect)
+			var ne noderr.NodeError
+			if !errors.As(err, &ne) {
+				t.Fatalf("error was not noderr.NodeError: %v", err)
+			}
+			assert.Equal(t, tt.wantErrPath, ne.Node.GetPath())
+			assert.EqualError(t, err, tt.wantErr)
+
+			source, err := ne.PrettyPrint([]byte(tt.give))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Contains(t, source, tt.wantLine)
+		})
+	}
+}
+
+func TestExecute_CheckErrorPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		give        string
+		wantErrPath string
+		wantErr     string
+	}{
+		{
+			name: "check",
+			give: `
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: "true"
+      - check: input.oncall == true
+      - outcome: approved
+`,
+			wantErrPath: "$.workflow.default.steps[2].check",
+			wantErr:     "error in $.workflow.default.steps[2].check (input.oncall == true): no such key: oncall",
+		},
+		{
+			name: "nested in boolean",
+			give: `
+workflow:
+  default:
+    steps:
+      - start: request
+      - and:
+          - check: "true"
+          - check: input.oncall == true
+      - outcome: approved
+`,
+			wantErrPath: "$.workflow.default.steps[1].and[1].check",
+			wantErr:     "error in $.workflow.default.steps[1].and[1].check (input.oncall == true): no such key: oncall",
+		},
+		{
+			name: "with id",
+			give: `
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: oncall
+        check: input.oncall == true
+      - outcome: approved
+`,
+			wantErrPath: "$.workflow.default.steps[1].id",
+			wantErr:     "error in $.workflow.default.steps[1].check (input.oncall == true): no such key: oncall",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Unmarshal([]byte(tt.give), yamlTestDialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := Compiler{
+				Program: p,
+				InputSchema: &jsoncel.Schema{
+					Properties: map[string]*jsoncel.Schema{
+						"oncall": {Type: jsoncel.Boolean},
+					},
+				},
+			}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := g.Execute("request", map[string]any{})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !assert.Len(t, res.StepErrors, 1) {
+				return
+			}
+
+			var ne noderr.NodeError
+			if errors.As(res.StepErrors[0], &ne) {
+				assert.Equal(t, tt.wantErrPath, ne.Node.GetPath())
+			} else {
+				t.Fatalf("error was not noderr.NodeError: %v", res.StepErrors[0])
+			}
+			assert.EqualError(t, res.StepErrors[0].Err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package glide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscalate_Execute(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: manager
+        action: my_action
+        escalate:
+          after: 3d
+          steps:
+            - check: input.director_approved
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	escalation := p.Workflow["default"].Steps[1]
+	assert.Equal(t, step.Boolean{Op: step.Or}, escalation.Body)
+	assert.Equal(t, "manager", escalation.Children[0].ID)
+	assert.Equal(t, step.Wait{After: 72 * time.Hour}, escalation.Children[1].Children[0].Body)
+
+	c := Compiler{Program: p, InputSchema: &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{"director_approved": {Type: jsoncel.Boolean}},
+	}}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requested := time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC)
+	approved := map[string]any{"director_approved": true}
+
+	tests := []struct {
+		name        string
+		time        time.Time
+		timers      map[string]time.Time
+		wantWait    State
+		wantOutcome string
+	}{
+		{
+			name:     "wait starts",
+			time:     requested,
+			wantWait: Active,
+		},
+		{
+			name:     "wait not elapsed",
+			time:     requested.Add(48 * time.Hour),
+			timers:   map[string]time.Time{"default.1.1.0": requested},
+			wantWait: Active,
+		},
+		{
+			name:        "escalated",
+			time:        requested.Add(72 * time.Hour),
+			timers:      map[string]time.Time{"default.1.1.0": requested},
+			wantWait:    Complete,
+			wantOutcome: "approved",
+		},
+		{
+			name:     "timers not provided",
+			time:     requested.Add(72 * time.Hour),
+			wantWait: Active,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Execute("request", approved, WithTime(tt.time), WithTimers(tt.timers))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantWait, got.State["default.1.1.0"])
+			assert.Equal(t, Active, got.State["default.manager"])
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+
+			// the wait keeps the time it became active.
+			want := tt.time
+			if since, ok := tt.timers["default.1.1.0"]; ok {
+				want = since
+			}
+			assert.Equal(t, map[string]time.Time{"default.1.1.0": want}, got.Timers)
+		})
+	}
+}
+
+func TestEscalate_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr string
+	}{
+		{
+			name:    "not an action",
+			give:    "check: \"true\"\n        escalate:\n          after: 1d\n          steps:\n            - action: my_action",
+			wantErr: "escalate can only be used on action steps",
+		},
+		{
+			name:    "no after",
+			give:    "action: my_action\n        escalate:\n          steps:\n            - action: my_action",
+			wantErr: "escalate must contain an 'after' field",
+		},
+		{
+			name:    "invalid after",
+			give:    "action: my_action\n        escalate:\n          after: later\n          steps:\n            - action: my_action",
+			wantErr: `invalid escalate after: "later" must be a duration`,
+		},
+		{
+			name:    "no steps",
+			give:    "action: my_action\n        escalate:\n          after: 1d",
+			wantErr: "escalate must contain a 'steps' field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := "workflow:\n  default:\n    steps:\n      - start: request\n      - " + tt.give + "\n      - outcome: approved\n"
+			_, err := Unmarshal([]byte(workflow), yamlTestDialect)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -1,11 +1,22 @@
 package glide
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/common-fate/glide/pkg/jsoncel"
 	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/noderr"
 	"github.com/common-fate/glide/pkg/step"
 	"github.com/dominikbraun/graph"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
 	"github.com/pkg/errors"
 )
 
@@ -15,6 +26,22 @@ const (
 	Inactive State = iota
 	Complete
 	Active
+	// Unknown is only used when executing with WithPartialInput.
+	// It indicates that the node could be complete or inactive,
+	// depending on the value of input fields which were not provided.
+	Unknown
+	// Failed is used for actions which have failed, as distinct
+	// from actions which are not complete yet.
+	Failed
+	// Unreachable is used for nodes which can never be complete for this
+	// execution, regardless of further input. For example, the steps after
+	// a failed action, or an on_fail branch of an action which completed.
+	// Unlike Inactive nodes, which may still complete as input is provided.
+	Unreachable
+	// Skipped is only used when executing with WithShortCircuit.
+	// It indicates that the node was not evaluated, because the
+	// workflow had already reached its highest priority outcome.
+	Skipped
 )
 
 func (s State) String() string {
@@ -25,6 +52,14 @@ func (s State) String() string {
 		return "active"
 	case Inactive:
 		return "inactive"
+	case Unknown:
+		return "unknown"
+	case Failed:
+		return "failed"
+	case Unreachable:
+		return "unreachable"
+	case Skipped:
+		return "skipped"
 	}
 	return "unknown"
 }
@@ -39,22 +74,535 @@ type Result struct {
 	// A map of vertex hashes to their corresponding state.
 	State map[string]State
 
-	// Outcome is the end state of the workflow.
+	// Outcome is the end state of the workflow. If more than one outcome
+	// is reached, it's selected by the OutcomeStrategy, which is the outcome
+	// with the highest priority by default.
 	// If empty, the workflow is considered in an indeterminate, ongoing state.
 	Outcome string
+
+	// ReachedOutcomes are the outcome nodes which were complete, in the order
+	// they were reached, whether or not they were selected as an outcome.
+	// Callers can use it to detect ambiguous policies, where more than
+	// one outcome is reached for the same input.
+	ReachedOutcomes []node.Node
+
+	// Outcomes are the IDs of the outcomes selected by the OutcomeStrategy,
+	// starting with Outcome. The default strategy selects a single outcome,
+	// while AllReached selects every outcome which was reached.
+	Outcomes []string
+
+	// OutcomeNode is the dialect node for the Outcome,
+	// including any metadata configured in the dialect.
+	// It is nil if the workflow has no outcome.
+	OutcomeNode *node.Node
+
+	// DefaultOutcome is true if no outcome was reached, and Outcome
+	// is the default outcome of the workflow, e.g. 'pending_review'.
+	// Failed workflows don't have a default outcome.
+	DefaultOutcome bool
+
+	// FirstCompleted maps vertex hashes to the index of the result
+	// in which the vertex first became complete.
+	// It is only set on results returned by Aggregate.
+	FirstCompleted map[string]int
+
+	// UnknownFields are the missing input fields whose value could change the outcome.
+	// Fields are dot-separated paths relative to the input, e.g. 'group.id'.
+	// It is only set when executing with WithPartialInput.
+	UnknownFields []string
+
+	// Reasons are the completed checks and actions on the path to the outcome,
+	// sorted by their position in the workflow. It is empty if the workflow
+	// has no outcome.
+	Reasons []Reason
+
+	// Failed is true if an action failed and its on_fail behaviour
+	// is to fail the workflow. A failed workflow has no outcome.
+	Failed bool
+
+	// Errors maps vertex hashes to the errors returned by actions, as an
+	// *ActionError which classifies whether the action returned the error,
+	// panicked or timed out. An action which returns an error is Failed.
+	Errors map[string]error
+
+	// Effects maps vertex hashes of active actions to the side effects
+	// which the host application should carry out, such as sending a webhook.
+	// See Effector.
+	Effects map[string]any
+
+	// ActionState maps vertex hashes to the internal state of Stateful actions.
+	// Provide it to the next execution with WithActionState.
+	ActionState map[string][]byte
+
+	// Timers maps the hashes of waits which are active or complete to the time
+	// they became active. Provide it to the next execution with WithTimers,
+	// so that escalations are activated once their wait has elapsed.
+	Timers map[string]time.Time
+
+	// StepErrors are the errors which occurred when evaluating steps,
+	// such as a CEL expression which could not be evaluated, sorted by step.
+	// Steps which could not be evaluated are Inactive.
+	StepErrors []StepError
+
+	// Checks maps vertex hashes of checks, and of actions with an 'if' guard,
+	// to the value their expression evaluated to. An Inactive check which
+	// isn't in Checks was never evaluated, e.g. because no step before
+	// it was complete, rather than evaluating to false.
+	Checks map[string]EvaluatedCheck
+
+	// Pending are the actions which are active but not complete, sorted by step,
+	// with their reminder and escalation schedules. It is empty if the workflow
+	// has reached an outcome other than its default outcome, or has failed.
+	Pending []PendingAction
+
+	// Gated are the steps which would have been activated, but weren't
+	// because it's outside of the schedule in their 'when' field, sorted by step.
+	Gated []GatedStep
+
+	// Locale is the locale which the display strings of the result
+	// were localized in with WithLocale, if any.
+	Locale string
+
+	// graph is the graph which was executed, if the workflow was expanded
+	// for the input. It's used to render the result.
+	graph *Graph
+}
+
+// StepError is an error which occurred when evaluating a step.
+type StepError struct {
+	// Step is the hash of the vertex which could not be evaluated.
+	Step string
+	Err  error
+}
+
+func (e StepError) Error() string {
+	return fmt.Sprintf("evaluating %s: %s", e.Step, e.Err)
+}
+
+func (e StepError) Unwrap() error {
+	return e.Err
+}
+
+// GatedStep is a step which wasn't activated because
+// it's outside of the schedule in its 'when' field.
+type GatedStep struct {
+	// Step is the hash of the vertex.
+	Step string
+	// When is the schedule of the step, as it was written in the workflow.
+	When string
+	// Opens is when the schedule next opens, or the zero
+	// time if it doesn't open in the next year.
+	Opens time.Time
+}
+
+// CheckValue is the value which the expression of a check evaluated to.
+// The zero value isn't a valid CheckValue, so that an EvaluatedCheck
+// which wasn't set can't be mistaken for a check which passed.
+type CheckValue int
+
+const (
+	// CheckTrue is a check which evaluated to true.
+	CheckTrue CheckValue = iota + 1
+	// CheckFalse is a check which evaluated to false.
+	CheckFalse
+	// CheckUnknown is a check which depends on missing input
+	// fields, or on the outputs of an action which isn't complete.
+	CheckUnknown
+	// CheckError is a check which could not be evaluated,
+	// or which didn't evaluate to a boolean.
+	CheckError
+)
+
+func (v CheckValue) String() string {
+	switch v {
+	case CheckTrue:
+		return "true"
+	case CheckFalse:
+		return "false"
+	case CheckUnknown:
+		return "unknown"
+	case CheckError:
+		return "error"
+	}
+	return ""
+}
+
+// EvaluatedCheck is a check, or the 'if' guard of an action,
+// which was evaluated during an execution.
+type EvaluatedCheck struct {
+	// Expression is the CEL expression of the check, as it was
+	// rendered in the graph, e.g. after expanding matrix variables.
+	Expression string
+	// Value is the value the expression evaluated to.
+	Value CheckValue
+	// Err is the error evaluating the expression, if Value is CheckError.
+	Err error
+}
+
+// evaluatedCheck returns the EvaluatedCheck for the value of an expression.
+func evaluatedCheck(expression string, val ref.Val, err error) EvaluatedCheck {
+	c := EvaluatedCheck{Expression: expression, Value: CheckError, Err: err}
+	if err != nil {
+		return c
+	}
+	if types.IsUnknown(val) {
+		c.Value = CheckUnknown
+		return c
+	}
+	b, ok := val.Value().(bool)
+	if !ok {
+		c.Err = fmt.Errorf("could not convert CEL to bool: %s", val)
+		return c
+	}
+	c.Value = CheckFalse
+	if b {
+		c.Value = CheckTrue
+	}
+	return c
+}
+
+// ExecutionError is returned by Execute if any steps could not be evaluated.
+// The Result returned with it contains the states computed for the other steps.
+type ExecutionError struct {
+	Errors []StepError
+}
+
+func (e *ExecutionError) Error() string {
+	var msgs []string
+	for _, se := range e.Errors {
+		msgs = append(msgs, se.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ExecuteOption configures the execution of a graph.
+type ExecuteOption func(*executeOptions)
+
+type executeOptions struct {
+	partial bool
+
+	// assumeActionsComplete treats all activated actions as complete.
+	// It is used when analysing the graph.
+	assumeActionsComplete bool
+
+	// assumeActionsUnknown treats all activated actions as Unknown,
+	// without evaluating them. It is used when compiling the graph.
+	assumeActionsUnknown bool
+
+	shortCircuit bool
+
+	// strategy selects the outcomes of the execution.
+	// If nil, the graph's strategy is used.
+	strategy OutcomeStrategy
+
+	// actionState is the internal state of stateful actions,
+	// saved by a previous execution.
+	actionState map[string][]byte
+
+	// now is the time of the execution, used to evaluate waits.
+	// If zero, the current time is used.
+	now time.Time
+
+	// timers are the times which waits became active,
+	// saved by a previous execution.
+	timers map[string]time.Time
+
+	// redactor masks sensitive input fields in the result and errors.
+	redactor *Redactor
+
+	// tracer is notified of each step evaluated.
+	tracer Tracer
+
+	// locale is the locale to localize display strings in.
+	locale string
+
+	// validateInput validates the input against the input schema.
+	validateInput bool
+
+	// actionTimeout is the timeout for calling the methods of actions.
+	// If zero, the methods aren't timed out.
+	actionTimeout time.Duration
+
+	// ctx is passed to the methods of actions.
+	// If nil, context.Background is used.
+	ctx context.Context
+}
+
+// WithPartialInput executes the graph with an input which may be
+// missing some of the fields declared in the input schema.
+//
+// Rather than returning an error, checks which depend on a missing field
+// are marked Unknown. Unknown states propagate through the graph using
+// three-valued logic: an AND with an inactive predecessor is inactive, and an
+// OR with a complete predecessor is complete, regardless of any unknowns.
+//
+// The Result lists the missing fields whose value could change the outcome
+// in UnknownFields, so that users can be asked only the questions that matter.
+func WithPartialInput() ExecuteOption {
+	return func(o *executeOptions) {
+		o.partial = true
+	}
+}
+
+// WithInputValidation validates the input against the input schema
+// before executing the graph, and returns the jsoncel.ValidationErrors if
+// it isn't valid. With WithPartialInput, required fields may be missing.
+func WithInputValidation() ExecuteOption {
+	return func(o *executeOptions) {
+		o.validateInput = true
+	}
+}
+
+// WithShortCircuit stops evaluating the graph once the highest priority
+// outcome in the workflow is complete, as no other outcome can take precedence.
+// It only applies when outcomes are selected with HighestPriority.
+//
+// The steps which have not been evaluated yet are marked Skipped, so that
+// large graphs are cheaper to execute and rendered results only shade the
+// steps which led to the outcome. Skipped actions are not completed and
+// have no effects, and a skipped action can't fail the workflow.
+func WithShortCircuit() ExecuteOption {
+	return func(o *executeOptions) {
+		o.shortCircuit = true
+	}
+}
+
+// WithOutcomeStrategy selects the outcomes of the execution with the strategy,
+// rather than the strategy the graph was compiled with.
+func WithOutcomeStrategy(s OutcomeStrategy) ExecuteOption {
+	return func(o *executeOptions) {
+		o.strategy = s
+	}
+}
+
+// WithContext passes ctx to the methods of actions, so that actions
+// which call external systems can be cancelled. Compiled.Drive, Engine
+// and the methods of Service pass their context with WithContext.
+func WithContext(ctx context.Context) ExecuteOption {
+	return func(o *executeOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithActionState restores the internal state of Stateful actions,
+// from the ActionState of the result of a previous execution.
+func WithActionState(state map[string][]byte) ExecuteOption {
+	return func(o *executeOptions) {
+		o.actionState = state
+	}
+}
+
+// WithTime executes the graph as if it were the time t, rather than the current time.
+// The time is only used to evaluate the waits before escalations, so that
+// executions of workflows with escalations can be reproduced.
+func WithTime(t time.Time) ExecuteOption {
+	return func(o *executeOptions) {
+		o.now = t
+	}
+}
+
+// WithTimers restores when the waits before escalations became active,
+// from the Timers of the result of a previous execution.
+// Without them, waits start again from the time of the execution.
+func WithTimers(timers map[string]time.Time) ExecuteOption {
+	return func(o *executeOptions) {
+		o.timers = timers
+	}
 }
 
+// Completer is implemented by actions which can be complete, such as an
+// approval which is complete once the request has been approved.
+//
+// The methods of actions are passed the context of the execution, set with
+// WithContext, which is cancelled if the method times out. An action is
+// shared by every execution of a compiled workflow, so its methods may be
+// called concurrently, and must not record the state of an execution on the action.
 type Completer interface {
-	Complete(input any) (bool, error)
+	Complete(ctx context.Context, input any) (bool, error)
+}
+
+// Failer is implemented by actions which can fail, as distinct
+// from not being complete yet. For example, an approval action
+// may fail if the request is rejected.
+//
+// What happens when an action fails is configured on the
+// step using 'on_fail'. By default, the workflow fails.
+type Failer interface {
+	Failed(ctx context.Context, input any) (bool, error)
+}
+
+// Effector is implemented by actions which have side effects that the host
+// application carries out when the action is activated, such as sending a webhook.
+//
+// Effect is called when the action is active but not complete, and the effect
+// is returned in Result.Effects. Effects are returned on each execution
+// while the action is active, so delivering them should be idempotent.
+type Effector interface {
+	Effect(ctx context.Context, input any) (any, error)
+}
+
+// Stateful is implemented by actions which keep internal state between
+// executions, such as the number of reminders they have sent.
+//
+// Before an active action is evaluated, LoadState is called with the state
+// provided with WithActionState, or with nil if there is no saved state, in which
+// case the action should reset its state. After the action is evaluated,
+// SaveState is called and the state is returned in Result.ActionState,
+// to be provided to the next execution.
+//
+// Actions are shared between executions of a compiled workflow, so a
+// Stateful action is only evaluated by one execution at a time, from
+// LoadState until SaveState. Other executions wait for it to be saved.
+type Stateful interface {
+	SaveState() ([]byte, error)
+	LoadState(data []byte) error
 }
 
 // Execute a policy graph.
 // The 'start' argument is the ID of a node to start execution from.
-func (g *Graph) Execute(start string, input map[string]any) (*Result, error) {
-	// build the input map for evaluating CEL expressions
-	// this map contains dot separated keys,
-	// such as 'input.group.id' -> 'test'
-	inputMap := NewInputMap("input", input)
+//
+// If a step can't be evaluated, execution continues and an *ExecutionError
+// is returned along with the Result, so that callers can render the states
+// computed so far and surface the failing steps. For other errors the
+// Result may be nil. If the Result can't be localized for WithLocale, it's
+// returned along with the error, which wraps any *ExecutionError.
+//
+// If the workflow has for_each steps, or matrix passes over input fields whose
+// values aren't enumerated by the input schema, they are expanded over the
+// values in the input and a graph is compiled for the execution.
+func (g *Graph) Execute(start string, input map[string]any, opts ...ExecuteOption) (*Result, error) {
+	var o executeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	res, err := g.expandAndExecute(start, input, opts...)
+
+	// if the result can't be localized, it's still returned, along
+	// with any error from the execution, so that it can be rendered.
+	var lerr error
+	if res != nil && o.locale != "" {
+		res.Locale = o.locale
+		lerr = g.localize(res)
+	}
+
+	if o.redactor != nil {
+		m := g.newMasker(input, *o.redactor)
+		m.result(res)
+
+		var ee *ExecutionError
+		if errors.As(err, &ee) && res != nil {
+			err = &ExecutionError{Errors: res.StepErrors}
+		} else {
+			err = m.error(err)
+		}
+	}
+
+	if lerr != nil {
+		if err == nil {
+			return res, fmt.Errorf("localizing the result: %w", lerr)
+		}
+		return res, fmt.Errorf("%w (localizing the result: %v)", err, lerr)
+	}
+	return res, err
+}
+
+// expandAndExecute executes the graph, or if the workflow has steps which are
+// expanded over the values in the input, a graph compiled for the input.
+func (g *Graph) expandAndExecute(start string, input map[string]any, opts ...ExecuteOption) (*Result, error) {
+	if g.matrixCompiler == nil {
+		return g.execute(start, input, opts...)
+	}
+
+	c := *g.matrixCompiler
+	c.matrixInput = input
+	if c.matrixInput == nil {
+		c.matrixInput = map[string]any{}
+	}
+	eg, err := c.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("expanding the workflow for the input: %w", err)
+	}
+
+	res, err := eg.execute(start, input, opts...)
+	if res != nil {
+		res.graph = eg
+	}
+	return res, err
+}
+
+// execute the graph without expanding it for the input. It's used to analyse
+// the graph, in which matrix passes and for_each steps have placeholder values.
+func (g *Graph) execute(start string, input map[string]any, opts ...ExecuteOption) (*Result, error) {
+	var o executeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// the input is copied, so that the caller's data isn't shared
+	// with actions, which may be running in other executions.
+	input = cloneInput(input)
+
+	if o.validateInput && g.provider != nil {
+		validate := jsoncel.Validate
+		if o.partial {
+			validate = jsoncel.ValidatePartial
+		}
+		if err := validate(g.provider.Schema(), input); err != nil {
+			return nil, fmt.Errorf("input is not valid: %w", err)
+		}
+	}
+
+	// build the variables for evaluating CEL expressions.
+	// the input is passed to CEL as an object value, so that
+	// nested fields can be accessed without flattening the input.
+	vars := map[string]any{"input": input}
+
+	// actions are given a copy of the input, so that
+	// actions which modify it can't affect the checks.
+	actionInput := cloneInput(input)
+
+	// steps contains the outputs of completed actions.
+	// It is populated as actions are completed during the traversal.
+	steps := map[string]any{}
+
+	// steps which can be referenced by checks are inactive until they are visited.
+	for key := range g.stepHashes {
+		steps[key] = stepState(Inactive)
+	}
+
+	if g.provider != nil {
+		vars["input"] = g.provider.NewObjectValue(input)
+		vars[stepsVar] = g.provider.NewRootValue(stepsVar, steps)
+	}
+
+	// ctx is passed to the methods of actions.
+	ctx := o.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// missing is the list of missing input fields, when executing with partial input.
+	var missing []string
+
+	// patterns match the missing input fields, so that they are treated as unknown.
+	var patterns []*interpreter.AttributePattern
+
+	if o.partial && g.provider != nil {
+		missing = jsoncel.MissingFields(g.provider.Schema(), input)
+
+		for _, field := range missing {
+			pattern := cel.AttributePattern("input")
+			for _, part := range strings.Split(field, ".") {
+				pattern = pattern.QualString(part)
+			}
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	// unknownFields maps nodes in the Unknown state to the
+	// missing input fields which they depend on.
+	unknownFields := map[string][]string{}
 
 	// initialise the completion graph
 	// this is a graph which contains the same vertices as our input graph,
@@ -72,13 +620,8 @@ func (g *Graph) Execute(start string, input map[string]any) (*Result, error) {
 
 	cg := graph.New(step.Hash, graph.Directed(), graph.PreventCycles())
 
-	pres, err := g.G.PredecessorMap()
-	if err != nil {
-		return nil, err
-	}
-
 	// the provided 'start' argument must always be a Start node
-	startVertex, err := g.G.Vertex(start)
+	startVertex, err := g.store.step(start)
 	if err != nil {
 		return nil, err
 	}
@@ -93,11 +636,66 @@ func (g *Graph) Execute(start string, input map[string]any) (*Result, error) {
 	// a map to track the state nodes
 	state := map[string]State{}
 
-	// outcome is set if there is a completed End node.
-	var outcome node.Node
+	// errors returned by actions, which cause them to fail.
+	actionErrs := map[string]error{}
+
+	// errors which occurred evaluating steps.
+	var stepErrs []StepError
+
+	// results of checks which only depend on the input, keyed by
+	// their memo key, so that identical checks are evaluated once.
+	memo := map[string]ref.Val{}
+
+	// values of the checks and 'if' guards which were evaluated.
+	checks := map[string]EvaluatedCheck{}
+
+	// side effects of active actions.
+	effects := map[string]any{}
+
+	// internal state of stateful actions. The state of actions which
+	// aren't evaluated is carried over from the previous execution.
+	actionState := map[string][]byte{}
+	for k, data := range o.actionState {
+		actionState[k] = data
+	}
+
+	// times which waits became active. Waits which aren't
+	// active any more start again when they are next activated.
+	timers := map[string]time.Time{}
+	now := o.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// reached are the completed End nodes, in the order they were reached.
+	var reached []node.Node
+
+	// gated are the steps which weren't activated because of their schedule.
+	var gated []GatedStep
+
+	strategy := o.strategy
+	if strategy == nil {
+		strategy = g.outcomeStrategy
+	}
+	if strategy == nil {
+		strategy = HighestPriority
+	}
+
+	// when short circuiting, the remaining steps are skipped
+	// once an outcome with the highest priority is complete.
+	// Other strategies may select outcomes reached after it.
+	shortCircuit := o.shortCircuit && strategy == HighestPriority
+	var highest int
+	var skip bool
+	if shortCircuit {
+		highest, err = g.highestPriority()
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	var verr error // used to track errors occurred during visiting
-	graph.BFS(g.G, start, func(k string) bool {
+	visit := func(k string) bool {
 		// node is inactive by default
 		state[k] = Inactive
 
@@ -106,7 +704,7 @@ func (g *Graph) Execute(start string, input map[string]any) (*Result, error) {
 			state[k] = Complete
 		}
 
-		v, err := g.G.Vertex(k)
+		v, err := g.store.step(k)
 		if err != nil {
 			verr = err
 			return true // stop traversal
@@ -118,64 +716,161 @@ func (g *Graph) Execute(start string, input map[string]any) (*Result, error) {
 			return true // stop traversal
 		}
 
+		if skip {
+			state[k] = Skipped
+			return false // continue traversal
+		}
+
 		// create edges between the current node and all completed predecessors
 		//
 		// e.g.
 		// request [complete] >> if(on_call) . if(in_admin_group) . approved
 		//					  ↑		↑
 		//	   create this edge	    current node
-		predecessors := pres[k]
+		predecessors, err := g.store.predecessors(k)
+		if err != nil {
+			verr = err
+			return true // stop traversal
+		}
 
 		// count the number of completed predecessors
 		// so that if the node is a Boolean, we can determine
 		// whether it should be complete.
 		var completedCount int
-		for _, edge := range predecessors {
-			vstate, ok := state[edge.Source]
-			if ok && vstate == Complete {
+
+		// count the number of unknown predecessors, when executing with partial input.
+		// predUnknownFields are the missing fields that the unknown predecessors depend on.
+		var unknownCount int
+		var predUnknownFields []string
+
+		// the steps a step needs must all be complete, but they
+		// don't activate the step by themselves.
+		var needsCount int
+		var needsUnmet, needsUnknown bool
+
+		for _, pred := range predecessors {
+			vstate, ok := state[pred.Source]
+			followed, err := g.edgeFollowed(pred, vstate)
+			if err != nil {
+				verr = err
+				return true // stop traversal
+			}
+			if pred.Attributes[needsAttribute] == "true" {
+				needsCount++
+				switch {
+				case ok && followed:
+					err = cg.AddEdge(pred.Source, k)
+					if err != nil {
+						verr = errors.Wrap(err, "adding edge to complete graph")
+						return true // stop traversal
+					}
+				case ok && vstate == Unknown:
+					needsUnknown = true
+					predUnknownFields = mergeFields(predUnknownFields, unknownFields[pred.Source])
+				default:
+					needsUnmet = true
+				}
+				continue
+			}
+			if ok && followed {
 				completedCount++
-				err = cg.AddEdge(edge.Source, k)
+				err = cg.AddEdge(pred.Source, k)
 				if err != nil {
 					verr = errors.Wrap(err, "adding edge to complete graph")
 					return true // stop traversal
 				}
 			}
+			if ok && vstate == Unknown {
+				unknownCount++
+				predUnknownFields = mergeFields(predUnknownFields, unknownFields[pred.Source])
+			}
+		}
+
+		if needsUnmet {
+			completedCount, unknownCount = 0, 0
+		} else if needsUnknown {
+			unknownCount += completedCount
+			completedCount = 0
+		}
+
+		// needs edges aren't counted when checking whether
+		// all of the predecessors of an AND are complete.
+		predecessorCount := len(predecessors) - needsCount
+
+		// setUnknown marks the node as Unknown.
+		setUnknown := func(fields []string) {
+			state[k] = Unknown
+			unknownFields[k] = fields
+		}
+
+		// steps with a schedule aren't activated outside of it. Schedules
+		// are ignored when analysing the graph, so that the analysis
+		// doesn't depend on the time it's carried out.
+		if v.When != nil && completedCount > 0 && !o.assumeActionsComplete && !o.assumeActionsUnknown && !v.When.Open(now) {
+			gated = append(gated, GatedStep{Step: k, When: v.When.Spec, Opens: v.When.Next(now)})
+			return false // continue traversal
 		}
 
 		switch t := v.Body.(type) {
 		case step.Check:
-			if completedCount == 0 {
+			if completedCount == 0 && unknownCount == 0 {
 				// if no vertexes are completed before this one,
 				// this vertex cannot be complete.
 				return false // continue traversal
 			}
 
-			// get the CEL program
-			prg, ok := g.programs[k]
-			if !ok {
-				verr = fmt.Errorf("could not find CEL program for %s", k)
-				return true // stop traversal
+			// checks with the same expression as a check which has
+			// already been evaluated have the same result.
+			mk, memoized := g.memoKeys[k]
+			val, ok := memo[mk]
+			if !memoized || !ok {
+				val, err = g.evalCheck(k, v, vars, patterns, steps)
+				if err != nil {
+					checks[k] = evaluatedCheck(t.Expression, nil, err)
+					stepErrs = append(stepErrs, StepError{Step: k, Err: err})
+					return false // continue traversal
+				}
+				if memoized {
+					memo[mk] = val
+				}
 			}
+			checks[k] = evaluatedCheck(t.Expression, val, nil)
 
-			val, _, err := prg.Eval(inputMap.Data)
-			if err != nil {
-				verr = err
-				return true // stop traversal
+			if types.IsUnknown(val) && !o.partial {
+				// the check depends on the outputs of an action
+				// which isn't complete, so it can't be complete either.
+				return false // continue traversal
+			}
+
+			if types.IsUnknown(val) {
+				fields := dependentFields(inputFields(g.asts[k].Expr()), missing)
+				if completedCount == 0 {
+					fields = mergeFields(fields, predUnknownFields)
+				}
+				setUnknown(fields)
+				return false // continue traversal
 			}
 
 			valbool, ok := val.Value().(bool)
 			if !ok {
-				verr = fmt.Errorf("could not convert CEL to bool: %s", val)
-				return true // stop traversal
+				err = fmt.Errorf("could not convert CEL to bool: %s", val)
+				stepErrs = append(stepErrs, StepError{Step: k, Err: checkError(v, t, err)})
+				return false // continue traversal
 			}
 
-			if valbool {
+			if valbool && completedCount > 0 {
 				state[k] = Complete
 			}
 
+			if valbool && completedCount == 0 {
+				// the check passed, but it's unknown whether
+				// a predecessor will be complete.
+				setUnknown(predUnknownFields)
+			}
+
 		case step.Boolean:
 			// for the AND node to be complete, all previous nodes must be complete.
-			if t.Op == step.And && completedCount == len(predecessors) {
+			if t.Op == step.And && completedCount == predecessorCount {
 				state[k] = Complete
 			}
 
@@ -184,7 +879,55 @@ func (g *Graph) Execute(start string, input map[string]any) (*Result, error) {
 				state[k] = Complete
 			}
 
+			// the AND node is unknown if none of the previous nodes are inactive,
+			// and the OR node is unknown if none of the previous nodes are complete.
+			if t.Op == step.And && unknownCount > 0 && completedCount+unknownCount == predecessorCount {
+				setUnknown(predUnknownFields)
+			}
+			if t.Op == step.Or && unknownCount > 0 && completedCount == 0 {
+				setUnknown(predUnknownFields)
+			}
+
 		case step.Action:
+			// an action with an 'if' guard is only activated if the guard is true.
+			if v.If != "" && (completedCount > 0 || unknownCount > 0) {
+				mk, memoized := g.memoKeys[k]
+				val, ok := memo[mk]
+				if !memoized || !ok {
+					val, err = g.evalCheck(k, v, vars, patterns, steps)
+					if err != nil {
+						checks[k] = evaluatedCheck(v.If, nil, err)
+						stepErrs = append(stepErrs, StepError{Step: k, Err: err})
+						return false // continue traversal
+					}
+					if memoized {
+						memo[mk] = val
+					}
+				}
+				checks[k] = evaluatedCheck(v.If, val, nil)
+
+				if types.IsUnknown(val) {
+					if o.partial {
+						fields := dependentFields(inputFields(g.asts[k].Expr()), missing)
+						if completedCount == 0 {
+							fields = mergeFields(fields, predUnknownFields)
+						}
+						setUnknown(fields)
+					}
+					return false // continue traversal
+				}
+
+				guard, ok := val.Value().(bool)
+				if !ok {
+					err = fmt.Errorf("could not convert CEL to bool: %s", val)
+					stepErrs = append(stepErrs, StepError{Step: k, Err: evalError(v, err)})
+					return false // continue traversal
+				}
+				if !guard {
+					return false // continue traversal
+				}
+			}
+
 			// if any predecessor is complete, the action is activated.
 			// note that in regular graph constructions, actions should only have
 			// a single predecessor anyway.
@@ -192,19 +935,164 @@ func (g *Graph) Execute(start string, input map[string]any) (*Result, error) {
 				state[k] = Active
 			}
 
+			if completedCount == 0 && unknownCount > 0 {
+				setUnknown(predUnknownFields)
+			}
+
+			// when analysing a graph, actions are assumed to be completed.
+			if o.assumeActionsComplete && completedCount > 0 {
+				state[k] = Complete
+				return false // continue traversal
+			}
+			if o.assumeActionsUnknown && completedCount > 0 {
+				setUnknown(predUnknownFields)
+				return false // continue traversal
+			}
+
+			// the methods of the action are called with callAction, so
+			// that panics and timeouts fail the action rather than the process.
+			timeout := o.timeoutFor(t.Action)
+
+			// running is set if a method of the action timed out and may still
+			// be running, in which case no other methods of the action are called.
+			// calls is done once every method which was called has returned.
+			var running bool
+			var calls sync.WaitGroup
+
+			// restore the internal state of the action, and save it
+			// once the action has been evaluated.
+			if st, ok := t.Action.(Stateful); ok && completedCount > 0 {
+				// the state is held on the action, so it's evaluated by one
+				// execution at a time, until any method which timed out returns.
+				unlock := lockStateful(t.Action)
+				defer func() {
+					if running {
+						go func() {
+							calls.Wait()
+							unlock()
+						}()
+						return
+					}
+					unlock()
+				}()
+
+				_, err := callAction(ctx, &calls, "LoadState", timeout, func(context.Context) (any, error) {
+					return nil, st.LoadState(o.actionState[k])
+				})
+				if err != nil {
+					actionErrs[k] = fmt.Errorf("loading action state: %w", err)
+					state[k] = Failed
+					return false // continue traversal
+				}
+				defer func() {
+					if running {
+						// the state of the action is carried over from the previous execution.
+						return
+					}
+					data, err := callAction(ctx, &calls, "SaveState", timeout, func(context.Context) ([]byte, error) {
+						return st.SaveState()
+					})
+					if err != nil {
+						actionErrs[k] = fmt.Errorf("saving action state: %w", err)
+						state[k] = Failed
+						return
+					}
+					actionState[k] = data
+				}()
+			}
+
+			// if the action supports it, check whether it has failed.
+			if f, ok := t.Action.(Failer); ok && completedCount > 0 {
+				failed, err := callAction(ctx, &calls, "Failed", timeout, func(ctx context.Context) (bool, error) {
+					return f.Failed(ctx, actionInput)
+				})
+				if err != nil {
+					actionErrs[k] = err
+					running = timedOut(err)
+					failed = true
+				}
+				if failed {
+					state[k] = Failed
+					return false // continue traversal
+				}
+			}
+
 			// if the action supports it, evaluate it to determine
 			// whether the workflow step is complete.
 			// a step can only be complete if one of it's predecessors is complete,
 			// so check that too with completedCount > 0
 			if c, ok := t.Action.(Completer); ok && completedCount > 0 {
-				complete, err := c.Complete(input)
+				complete, err := callAction(ctx, &calls, "Complete", timeout, func(ctx context.Context) (bool, error) {
+					return c.Complete(ctx, actionInput)
+				})
 				if err != nil {
-					verr = err
-					return true // stop traversal
+					// an erroring action fails, rather than stopping
+					// the execution, so that the partial state is returned.
+					actionErrs[k] = err
+					running = timedOut(err)
+					state[k] = Failed
+					return false // continue traversal
 				}
 				if complete {
 					state[k] = Complete
+
+					// make the action outputs available to later checks.
+					if out, ok := t.Action.(Outputter); ok && v.ID != "" {
+						outputs, err := callAction(ctx, &calls, "Outputs", timeout, func(ctx context.Context) (map[string]any, error) {
+							return out.Outputs(ctx, actionInput)
+						})
+						if err != nil {
+							actionErrs[k] = err
+							running = timedOut(err)
+							state[k] = Failed
+							return false // continue traversal
+						}
+						setStepValue(steps, v.ID, "outputs", outputs)
+					}
+				}
+			}
+
+			// actions which are active but not complete may have side effects
+			// for the host application to carry out, like sending a webhook.
+			if e, ok := t.Action.(Effector); ok && state[k] == Active {
+				effect, err := callAction(ctx, &calls, "Effect", timeout, func(ctx context.Context) (any, error) {
+					return e.Effect(ctx, actionInput)
+				})
+				if err != nil {
+					actionErrs[k] = err
+					running = timedOut(err)
+					state[k] = Failed
+					return false // continue traversal
 				}
+				effects[k] = effect
+			}
+		case step.Wait:
+			if completedCount == 0 && unknownCount > 0 {
+				setUnknown(predUnknownFields)
+			}
+			if completedCount == 0 {
+				return false // continue traversal
+			}
+
+			// when analysing a graph, waits are treated like actions.
+			if o.assumeActionsComplete {
+				state[k] = Complete
+				return false // continue traversal
+			}
+			if o.assumeActionsUnknown {
+				setUnknown(predUnknownFields)
+				return false // continue traversal
+			}
+
+			since, ok := o.timers[k]
+			if !ok || since.After(now) {
+				since = now
+			}
+			timers[k] = since
+
+			state[k] = Active
+			if now.Sub(since) >= t.After {
+				state[k] = Complete
 			}
 		case step.Ref:
 			var isComplete bool
@@ -216,61 +1104,423 @@ func (g *Graph) Execute(start string, input map[string]any) (*Result, error) {
 				isComplete = true
 			}
 
-			// if it's an End node, set it as the outcome if it's higher priority
-			if isComplete && isEndNode && outcome.Priority < t.Node.Priority {
-				outcome = t.Node
+			if completedCount == 0 && unknownCount > 0 {
+				setUnknown(predUnknownFields)
+			}
+
+			// if it's an End node, record that it was reached.
+			if isComplete && isEndNode {
+				reached = appendOutcome(reached, t.Node)
+				skip = shortCircuit && t.Node.Priority >= highest
 			}
 		}
 
 		return false
+	}
+
+	// stepKeys maps the hashes of steps to the keys which checks reference them by.
+	stepKeys := map[string][]string{}
+	for key, hash := range g.stepHashes {
+		stepKeys[hash] = append(stepKeys[hash], key)
+	}
+
+	err = g.bfs(start, func(k string) bool {
+		began := time.Now()
+		stop := visit(k)
+		if o.tracer != nil && !stop {
+			o.tracer.StepEvaluated(k, state[k], time.Since(began))
+		}
+
+		// make the state of the step available to checks which reference it.
+		for _, key := range stepKeys[k] {
+			setStepValue(steps, key, "complete", state[k] == Complete)
+			setStepValue(steps, key, "state", state[k].String())
+		}
+		return stop
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	if verr != nil {
 		return nil, verr
 	}
 
+	err = g.markUnreachable(state)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(stepErrs, func(i, j int) bool {
+		return stepErrs[i].Step < stepErrs[j].Step
+	})
+
+	// the first selected outcome is the primary outcome.
+	var outcome node.Node
+	selected := strategy.SelectOutcomes(reached)
+	if len(selected) > 0 {
+		outcome = selected[0]
+	}
+
 	res := Result{
 		CG:      cg,
 		State:   state,
 		Outcome: outcome.ID,
 	}
 
+	if outcome.ID != "" {
+		res.OutcomeNode = &outcome
+	}
+	for _, n := range selected {
+		res.Outcomes = append(res.Outcomes, n.ID)
+	}
+	res.ReachedOutcomes = reached
+
+	if len(actionErrs) > 0 {
+		res.Errors = actionErrs
+	}
+
+	res.StepErrors = stepErrs
+
+	if len(checks) > 0 {
+		res.Checks = checks
+	}
+
+	sort.Slice(gated, func(i, j int) bool {
+		return gated[i].Step < gated[j].Step
+	})
+	res.Gated = gated
+
+	if len(effects) > 0 {
+		res.Effects = effects
+	}
+
+	if len(actionState) > 0 {
+		res.ActionState = actionState
+	}
+
+	if len(timers) > 0 {
+		res.Timers = timers
+	}
+
+	// if any failed action fails the workflow, the workflow has no outcome.
+	for k, s := range state {
+		if s != Failed {
+			continue
+		}
+		v, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+		if v.OnFail.Behavior == step.FailWorkflow {
+			res.Failed = true
+			res.Outcome = ""
+			res.OutcomeNode = nil
+			res.Outcomes = nil
+		}
+	}
+
+	res.Reasons, err = outcomeReasons(cg, state, res.Outcome)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Outcome == "" && !res.Failed {
+		g.setDefaultOutcome(&res)
+	}
+
+	if !res.Failed && (res.Outcome == "" || res.DefaultOutcome) {
+		res.Pending, err = g.pendingActions(state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.partial {
+		// the fields which could change the outcome are the fields
+		// that unknown outcomes with a higher priority depend on.
+		for k, s := range state {
+			if s != Unknown {
+				continue
+			}
+			v, err := g.store.step(k)
+			if err != nil {
+				return nil, err
+			}
+			r, ok := v.Body.(step.Ref)
+			if ok && r.Node.Type == node.Outcome && r.Node.Priority > outcome.Priority {
+				res.UnknownFields = mergeFields(res.UnknownFields, unknownFields[k])
+			}
+		}
+	}
+
+	if len(stepErrs) > 0 {
+		return &res, &ExecutionError{Errors: stepErrs}
+	}
+
 	return &res, nil
 }
 
-// InputMap is a map of flattened input keys to their corresponding values,
-// e.g.
+// appendOutcome appends an outcome node to the reached outcomes,
+// unless an outcome with the same ID has already been reached.
+func appendOutcome(reached []node.Node, n node.Node) []node.Node {
+	for _, r := range reached {
+		if r.ID == n.ID {
+			return reached
+		}
+	}
+	return append(reached, n)
+}
+
+// edgeFollowed returns true if an edge is followed
+// from a predecessor in a particular state.
 //
-//	'group.id' -> 'test'
+// Edges are followed from complete predecessors, and from failed actions
+// which continue on failure. Edges into an on_fail branch are
+// only followed if the action failed.
+func (g *Graph) edgeFollowed(e edge, s State) (bool, error) {
+	if e.Attributes[onFailAttribute] == "true" {
+		return s == Failed, nil
+	}
+
+	if s == Failed {
+		v, err := g.store.step(e.Source)
+		if err != nil {
+			return false, err
+		}
+		return v.OnFail.Behavior == step.Continue, nil
+	}
+
+	return s == Complete, nil
+}
+
+// markUnreachable marks the inactive nodes which can never be complete
+// as Unreachable, in topological order so that unreachable nodes propagate.
 //
-// Used as the input for CEL evaluation, because CEL requires keys
-// to be dot separated.
-type InputMap struct {
-	// The flattened mapping - e.g. 'group.id' -> 'test'
-	Data map[string]any
+// Completed and failed actions don't change state as more input is provided,
+// so edges which aren't followed from them never will be. A node is
+// unreachable if none of its incoming edges can be followed, or for AND nodes,
+// if any of them can't be followed.
+func (g *Graph) markUnreachable(state map[string]State) error {
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, k := range order {
+		if s, ok := state[k]; !ok || s != Inactive {
+			continue
+		}
+
+		predecessors, err := g.store.predecessors(k)
+		if err != nil {
+			return err
+		}
+		if len(predecessors) == 0 {
+			continue
+		}
+
+		// a step is also unreachable if any of the steps it needs are.
+		var dead, regular int
+		var deadNeeds bool
+		for _, pred := range predecessors {
+			d, err := g.edgeDead(pred, state[pred.Source])
+			if err != nil {
+				return err
+			}
+			if pred.Attributes[needsAttribute] == "true" {
+				deadNeeds = deadNeeds || d
+				continue
+			}
+			regular++
+			if d {
+				dead++
+			}
+		}
+
+		v, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+		and := false
+		if b, ok := v.Body.(step.Boolean); ok && b.Op == step.And {
+			and = true
+		}
+
+		if deadNeeds || (regular > 0 && dead == regular) || (and && dead > 0) {
+			state[k] = Unreachable
+		}
+	}
+	return nil
 }
 
-// NewInputMap creates a new input map. The 'key' field is
-// the root name of the input - such as 'input'.
-func NewInputMap(key string, data map[string]any) *InputMap {
-	im := InputMap{}
-	im.build(key, data)
-	return &im
+// edgeDead returns true if an edge can never be followed
+// from a predecessor in a particular state.
+func (g *Graph) edgeDead(e edge, s State) (bool, error) {
+	switch s {
+	case Unreachable:
+		return true, nil
+	case Complete, Failed:
+		v, err := g.store.step(e.Source)
+		if err != nil {
+			return false, err
+		}
+		// only actions are known not to change state.
+		if _, ok := v.Body.(step.Action); !ok {
+			return false, nil
+		}
+		followed, err := g.edgeFollowed(e, s)
+		return !followed, err
+	}
+	return false, nil
+}
+
+// checkError localises an error evaluating a check to the position
+// of the check in the workflow YAML, including the expression text.
+func checkError(s step.Step, c step.Check, err error) error {
+	return exprError(s, "check", c.Expression, err)
+}
+
+// evalError wraps an error evaluating the expression of a step,
+// which is either a check or the 'if' guard of an action.
+func evalError(s step.Step, err error) error {
+	if c, ok := s.Body.(step.Check); ok {
+		return checkError(s, c, err)
+	}
+	return exprError(s, "if", s.If, err)
+}
+
+// exprError wraps an error evaluating the expression in a field of a step,
+// e.g. 'check', with the path of the field if the step has a YAML node.
+func exprError(s step.Step, field, expression string, err error) error {
+	if s.Node == nil {
+		return fmt.Errorf("error in %s %q: %w", field, expression, err)
+	}
+
+	// the path of a step node is the path of its first field,
+	// which may not be the 'check' field if the step has an id or name.
+	path := s.Node.GetPath()
+	if i := strings.LastIndex(path, "."); i != -1 {
+		path = path[:i]
+	}
+	path += "." + field
+
+	return noderr.NodeError{
+		Err:  fmt.Errorf("error in %s (%s): %w", path, expression, err),
+		Node: s.Node,
+	}
 }
 
-func (im *InputMap) build(key string, input map[string]any) {
-	if im.Data == nil {
-		im.Data = map[string]any{}
+// setStepValue sets a field of a step in the 'steps' variable.
+func setStepValue(steps map[string]any, key, field string, value any) {
+	s, ok := steps[key].(map[string]any)
+	if !ok {
+		s = map[string]any{}
+		steps[key] = s
 	}
+	s[field] = value
+}
 
-	for k, v := range input {
-		childKey := key + "." + k // 'group.id'
-		im.Data[childKey] = v
+// evalCheck evaluates the CEL program of a check, or of the 'if' guard of an action.
+func (g *Graph) evalCheck(k string, v step.Step, vars map[string]any, patterns []*interpreter.AttributePattern, steps map[string]any) (ref.Val, error) {
+	prg, ok := g.programs[k]
+	if !ok {
+		return nil, errors.New("could not find CEL program")
+	}
+
+	// the 'workflow' variable describes the check being evaluated.
+	var metadata any = workflowMetadata(g.version, v)
+	if g.provider != nil {
+		metadata = g.provider.NewRootValue(workflowVar, workflowMetadata(g.version, v))
+	}
+	vars[workflowVar] = metadata
 
-		// if the value is also a map, call the Build method
-		// again to register all child fields.
-		if child, ok := v.(map[string]any); ok {
-			im.build(childKey, child)
+	activation, err := g.activation(vars, patterns, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	val, _, err := prg.Eval(activation)
+	if err != nil {
+		return nil, evalError(v, err)
+	}
+	return val, nil
+}
+
+// activation returns the CEL activation for evaluating a check.
+// The outputs of actions which aren't complete yet are treated as unknown,
+// along with the state of unknown steps, and any missing input fields
+// matched by the patterns.
+func (g *Graph) activation(vars map[string]any, patterns []*interpreter.AttributePattern, steps map[string]any) (any, error) {
+	patterns = append([]*interpreter.AttributePattern{}, patterns...)
+	for _, id := range g.outputSteps {
+		s, _ := steps[id].(map[string]any)
+		if _, ok := s["outputs"]; !ok {
+			patterns = append(patterns, cel.AttributePattern(stepsVar).QualString(id).QualString("outputs"))
+		}
+	}
+	for key := range g.stepHashes {
+		s, _ := steps[key].(map[string]any)
+		if s["state"] == Unknown.String() {
+			patterns = append(patterns, cel.AttributePattern(stepsVar).QualString(key))
+		}
+	}
+
+	if len(patterns) == 0 {
+		return vars, nil
+	}
+
+	return cel.PartialVars(vars, patterns...)
+}
+
+// dependentFields returns the missing fields which the referenced fields depend on.
+// A referenced field depends on a missing field if it is the missing field,
+// or is nested inside it.
+func dependentFields(referenced []string, missing []string) []string {
+	var fields []string
+	for _, m := range missing {
+		for _, r := range referenced {
+			if r == m || strings.HasPrefix(r, m+".") {
+				fields = append(fields, m)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// mergeFields returns the sorted union of field lists.
+func mergeFields(lists ...[]string) []string {
+	seen := map[string]bool{}
+	var merged []string
+	for _, l := range lists {
+		for _, f := range l {
+			if !seen[f] {
+				seen[f] = true
+				merged = append(merged, f)
+			}
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// highestPriority returns the highest priority of the outcome nodes in the graph.
+func (g *Graph) highestPriority() (int, error) {
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return 0, err
+	}
+	var highest int
+	for _, k := range hashes {
+		v, err := g.store.step(k)
+		if err != nil {
+			return 0, err
+		}
+		r, ok := v.Body.(step.Ref)
+		if ok && r.Node.Type == node.Outcome && r.Node.Priority > highest {
+			highest = r.Node.Priority
 		}
 	}
+	return highest, nil
 }
@@ -1,10 +1,12 @@
 package glide
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/common-fate/glide/pkg/dialect"
 	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
 	"github.com/common-fate/glide/pkg/step"
 	"github.com/common-fate/glide/pkg/step/s"
 	"github.com/stretchr/testify/assert"
@@ -170,6 +172,78 @@ func TestExecute(t *testing.T) {
 				"approved":  Complete,
 			},
 		},
+		{
+			name:  "with CEL presence test on an object",
+			start: "request",
+			compiler: Compiler{
+				Program: SimpleProgram(
+					s.Start("request"),
+					s.Check(`has(input.group.id) && input.group.members > 1`),
+					s.Outcome("approved"),
+				),
+				InputSchema: &jsoncel.Schema{
+					Type: jsoncel.Object,
+					Properties: map[string]*jsoncel.Schema{
+						"group": {
+							Type: jsoncel.Object,
+							Properties: map[string]*jsoncel.Schema{
+								"id": {
+									Type: jsoncel.String,
+								},
+								"members": {
+									Type: jsoncel.Integer,
+								},
+							},
+						},
+					},
+				},
+			},
+			dialect: testDialect,
+			input: map[string]any{
+				"group": map[string]any{
+					"id":      "test",
+					"members": float64(2),
+				},
+			},
+			wantState: map[string]State{
+				"request":   Complete,
+				"default.1": Complete,
+				"approved":  Complete,
+			},
+		},
+		{
+			name:  "with dialect macro",
+			start: "request",
+			compiler: Compiler{
+				Program: SimpleProgram(
+					s.Start("request"),
+					s.Check(`in_group("admins")`),
+					s.Outcome("approved"),
+				),
+				InputSchema: &jsoncel.Schema{
+					Properties: map[string]*jsoncel.Schema{
+						"groups": {
+							Type:  jsoncel.Array,
+							Items: &jsoncel.Schema{Type: jsoncel.String},
+						},
+					},
+				},
+			},
+			dialect: dialect.Dialect{
+				Nodes: testDialect.Nodes,
+				Macros: map[string]dialect.Macro{
+					"in_group": {Params: []string{"group"}, Expression: "group in input.groups"},
+				},
+			},
+			input: map[string]any{
+				"groups": []any{"admins"},
+			},
+			wantState: map[string]State{
+				"request":   Complete,
+				"default.1": Complete,
+				"approved":  Complete,
+			},
+		},
 		{
 			name:  "with action completion",
 			start: "request",
@@ -229,9 +303,28 @@ func TestExecute(t *testing.T) {
 				"approved":  Inactive,
 			},
 		},
+		{
+			name:  "outcome reached by passes of different lengths",
+			start: "request",
+			compiler: Compiler{
+				Program: NewProgram().
+					Pass("a", s.Start("request"), s.Check("false"), s.Outcome("approved")).
+					Pass("b", s.Start("request"), s.Check("true"), s.Check("true"), s.Outcome("approved")),
+			},
+			dialect: testDialect,
+			wantState: map[string]State{
+				"request":  Complete,
+				"a.1":      Inactive,
+				"b.1":      Complete,
+				"b.2":      Complete,
+				"approved": Complete,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			tt.compiler.Program.Dialect = &tt.dialect
+
 			g, err := tt.compiler.Compile()
 			if err != nil {
 				t.Fatal(err)
@@ -250,3 +343,1102 @@ func TestExecute(t *testing.T) {
 		})
 	}
 }
+
+func TestExecute_OutcomeMetadata(t *testing.T) {
+	d := dialect.Dialect{
+		Nodes: map[string]node.Node{
+			"request": {Type: node.Start},
+			"approved": {
+				Type:     node.Outcome,
+				Priority: 1,
+				Metadata: map[string]any{"severity": "low"},
+			},
+		},
+	}
+
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: "true"
+      - outcome: approved
+`), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Compiler{Program: p}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "approved", got.Outcome)
+	if assert.NotNil(t, got.OutcomeNode) {
+		assert.Equal(t, "low", got.OutcomeNode.Metadata["severity"])
+	}
+}
+
+func TestExecute_PartialInput(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"oncall": {
+				Type: jsoncel.Boolean,
+			},
+			"group": {
+				Type: jsoncel.String,
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		program           *Program
+		input             map[string]any
+		wantState         map[string]State
+		wantOutcome       string
+		wantUnknownFields []string
+	}{
+		{
+			name: "OR with a complete branch",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.Or,
+					s.Check("input.oncall"),
+					s.Check(`input.group == "admins"`),
+				),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"group": "admins"},
+			wantState: map[string]State{
+				"request":     Complete,
+				"default.1":   Complete,
+				"default.1.0": Unknown,
+				"default.1.1": Complete,
+				"approved":    Complete,
+			},
+			wantOutcome: "approved",
+		},
+		{
+			name: "OR with no input",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.Or,
+					s.Check("input.oncall"),
+					s.Check(`input.group == "admins"`),
+				),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{},
+			wantState: map[string]State{
+				"request":     Complete,
+				"default.1":   Unknown,
+				"default.1.0": Unknown,
+				"default.1.1": Unknown,
+				"approved":    Unknown,
+			},
+			wantUnknownFields: []string{"group", "oncall"},
+		},
+		{
+			name: "AND with an inactive branch",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.And,
+					s.Check("input.oncall"),
+					s.Check(`input.group == "admins"`),
+				),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"group": "developers"},
+			wantState: map[string]State{
+				"request":     Complete,
+				"default.1":   Inactive,
+				"default.1.0": Unknown,
+				"default.1.1": Inactive,
+				"approved":    Inactive,
+			},
+		},
+		{
+			name: "sequential checks",
+			program: SimpleProgram(
+				s.Start("request"),
+				s.Check("input.oncall"),
+				s.Check(`input.group == "admins"`),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"group": "admins"},
+			wantState: map[string]State{
+				"request":   Complete,
+				"default.1": Unknown,
+				"default.2": Unknown,
+				"approved":  Unknown,
+			},
+			wantUnknownFields: []string{"oncall"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.program, InputSchema: schema}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", tt.input, WithPartialInput())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.wantState, got.State)
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			assert.Equal(t, tt.wantUnknownFields, got.UnknownFields)
+		})
+	}
+}
+
+func TestExecute_WithInputValidation(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check(`input.duration < 8`),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+		InputSchema: &jsoncel.Schema{
+			Required: []string{"group", "duration"},
+			Properties: map[string]*jsoncel.Schema{
+				"group":    {Type: jsoncel.String},
+				"duration": {Type: jsoncel.Integer, Minimum: jsoncel.Float(1)},
+			},
+		},
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = g.Execute("request", map[string]any{"group": "admins", "duration": 0}, WithInputValidation())
+	assert.EqualError(t, err, "input is not valid: duration: must be at least 1")
+
+	_, err = g.Execute("request", map[string]any{"duration": 2}, WithInputValidation())
+	assert.EqualError(t, err, "input is not valid: group: is required")
+
+	got, err := g.Execute("request", map[string]any{"duration": 2}, WithInputValidation(), WithPartialInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", got.Outcome)
+}
+
+func TestExecute_ActionOutputs(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"requestor": {Type: jsoncel.String},
+		},
+	}
+
+	program := func(approver string) *Program {
+		return SimpleProgram(
+			s.Start("request"),
+			s.WithID("approval").Action("approval", &testOutputAction{approver: approver}),
+			s.Check("steps.approval.outputs.approver != input.requestor"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		)
+	}
+
+	type testcase struct {
+		name        string
+		approver    string
+		partial     bool
+		wantOutcome string
+		wantCheck   State
+	}
+
+	testcases := []testcase{
+		{name: "approved by someone else", approver: "bob", wantOutcome: "approved", wantCheck: Complete},
+		{name: "approved by the requestor", approver: "alice", wantCheck: Inactive},
+		{name: "not approved", wantCheck: Inactive},
+		{name: "not approved with partial input", partial: true, wantCheck: Inactive},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: program(tt.approver), InputSchema: schema}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var opts []ExecuteOption
+			if tt.partial {
+				opts = append(opts, WithPartialInput())
+			}
+
+			got, err := g.Execute("request", map[string]any{"requestor": "alice"}, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			assert.Equal(t, tt.wantCheck, got.State["default.2"])
+		})
+	}
+}
+
+func TestExecute_ActionFailure(t *testing.T) {
+	type testcase struct {
+		name        string
+		action      *testFailAction
+		onFail      *s.StepBuilder
+		wantOutcome string
+		wantFailed  bool
+		wantState   State
+		wantErrors  []string
+	}
+
+	escalation := []step.Step{
+		s.Check("true"),
+		s.Named("Escalated").Priority(1).Outcome("escalated"),
+	}
+
+	testcases := []testcase{
+		{
+			name:        "complete",
+			action:      &testFailAction{},
+			onFail:      s.OnFail(step.FailWorkflow),
+			wantOutcome: "approved",
+			wantState:   Complete,
+		},
+		{
+			name:       "fail workflow",
+			action:     &testFailAction{failed: true},
+			onFail:     s.OnFail(step.FailWorkflow),
+			wantFailed: true,
+			wantState:  Failed,
+		},
+		{
+			name:        "continue",
+			action:      &testFailAction{failed: true},
+			onFail:      s.OnFail(step.Continue),
+			wantOutcome: "approved",
+			wantState:   Failed,
+		},
+		{
+			name:        "route",
+			action:      &testFailAction{failed: true},
+			onFail:      s.OnFail(step.Route, escalation...),
+			wantOutcome: "escalated",
+			wantState:   Failed,
+		},
+		{
+			name:        "route not followed if complete",
+			action:      &testFailAction{},
+			onFail:      s.OnFail(step.Route, escalation...),
+			wantOutcome: "approved",
+			wantState:   Complete,
+		},
+		{
+			name:       "error fails the action",
+			action:     &testFailAction{err: errors.New("something went wrong")},
+			onFail:     s.OnFail(step.FailWorkflow),
+			wantFailed: true,
+			wantState:  Failed,
+			wantErrors: []string{"something went wrong"},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{
+				Program: SimpleProgram(
+					s.Start("request"),
+					tt.onFail.ID("notify").Action("notify", tt.action),
+					s.Named("Approved").Priority(2).Outcome("approved"),
+				),
+			}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			assert.Equal(t, tt.wantFailed, got.Failed)
+			assert.Equal(t, tt.wantState, got.State["default.notify"])
+
+			var gotErrors []string
+			for _, err := range got.Errors {
+				gotErrors = append(gotErrors, err.Error())
+			}
+			assert.Equal(t, tt.wantErrors, gotErrors)
+		})
+	}
+}
+
+func TestExecute_StepErrors(t *testing.T) {
+	c := Compiler{
+		Program: NewProgram().
+			Pass("first",
+				s.Start("request"),
+				s.WithID("name").Check(`input.name == "bob"`),
+				s.Named("Denied").Priority(1).Outcome("denied"),
+			).
+			Pass("second",
+				s.Start("request"),
+				s.WithID("admin").Check(`input.admin == true`),
+				s.Named("Approved").Priority(2).Outcome("approved"),
+			),
+		InputSchema: &jsoncel.Schema{
+			Properties: map[string]*jsoncel.Schema{
+				"name":  {Type: jsoncel.String},
+				"admin": {Type: jsoncel.Boolean},
+			},
+		},
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'name' is missing from the input, so the first check can't be evaluated.
+	got, err := g.Execute("request", map[string]any{"admin": true})
+
+	var ee *ExecutionError
+	if !errors.As(err, &ee) {
+		t.Fatalf("expected ExecutionError, got %v", err)
+	}
+	if assert.NotNil(t, got) {
+		assert.Equal(t, "approved", got.Outcome)
+		assert.Equal(t, Inactive, got.State["first.name"])
+		assert.Equal(t, Complete, got.State["second.admin"])
+		assert.Equal(t, ee.Errors, got.StepErrors)
+	}
+	if assert.Len(t, ee.Errors, 1) {
+		assert.Equal(t, "first.name", ee.Errors[0].Step)
+	}
+}
+
+func TestExecute_Effects(t *testing.T) {
+	tests := []struct {
+		name   string
+		action *testEffectAction
+		want   map[string]any
+	}{
+		{
+			name:   "active",
+			action: &testEffectAction{},
+			want:   map[string]any{"default.notify": "notify"},
+		},
+		{
+			name:   "complete",
+			action: &testEffectAction{complete: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{
+				Program: SimpleProgram(
+					s.Start("request"),
+					s.WithID("notify").Action("notify", tt.action),
+					s.Named("Approved").Priority(1).Outcome("approved"),
+				),
+			}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got.Effects)
+		})
+	}
+}
+
+func TestExecute_ActionState(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.WithID("remind").Action("remind", &testReminderAction{required: 3}),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each execution sends a reminder, and the count is carried
+	// to the next execution through the result.
+	var state map[string][]byte
+	for i, want := range []string{"1", "2", "3"} {
+		got, err := g.Execute("request", nil, WithActionState(state))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, want, string(got.ActionState["default.remind"]))
+		assert.Equal(t, i == 2, got.Outcome == "approved")
+		state = got.ActionState
+	}
+
+	// without a saved state, the action starts again.
+	got, err := g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1", string(got.ActionState["default.remind"]))
+
+	// an invalid state fails the action.
+	got, err = g.Execute("request", nil, WithActionState(map[string][]byte{"default.remind": []byte("invalid")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Failed, got.State["default.remind"])
+	assert.ErrorContains(t, got.Errors["default.remind"], "loading action state")
+}
+
+func TestExecute_WorkflowMetadata(t *testing.T) {
+	type testcase struct {
+		name         string
+		check        string
+		wantComplete map[string]bool
+	}
+
+	testcases := []testcase{
+		{
+			name:         "pass",
+			check:        `workflow.pass == "breakglass"`,
+			wantComplete: map[string]bool{"breakglass": true, "default": false},
+		},
+		{
+			name:         "step",
+			check:        `workflow.step == "Shared check"`,
+			wantComplete: map[string]bool{"breakglass": true, "default": true},
+		},
+		{
+			name:         "version",
+			check:        `workflow.version == "v2"`,
+			wantComplete: map[string]bool{"breakglass": true, "default": true},
+		},
+		{
+			name:         "version mismatch",
+			check:        `workflow.version == "v1"`,
+			wantComplete: map[string]bool{"breakglass": false, "default": false},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Unmarshal([]byte(`
+version: v2
+workflow:
+  default:
+    steps:
+      - start: request
+      - name: Shared check
+        check: '`+tt.check+`'
+      - outcome: approved
+  breakglass:
+    steps:
+      - start: request
+      - name: Shared check
+        check: '`+tt.check+`'
+      - outcome: approved
+`), yamlTestDialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "v2", p.Version)
+
+			c := Compiler{Program: p, InputSchema: &jsoncel.Schema{}}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", map[string]any{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the workflow has two passes with the same check,
+			// so the check is evaluated in each pass.
+			for pass, want := range tt.wantComplete {
+				assert.Equal(t, want, got.State[pass+".1"] == Complete, pass)
+			}
+		})
+	}
+}
+
+func TestCompile_WorkflowMetadataUnknownField(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check(`workflow.environment == "prod"`),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+		InputSchema: &jsoncel.Schema{},
+	}
+	_, err := c.Compile()
+	assert.ErrorContains(t, err, "environment")
+}
+
+func TestExecute_ShortCircuit(t *testing.T) {
+	program := NewProgram().
+		Pass("default",
+			s.Start("request"),
+			s.Check("input.approve"),
+			s.Named("Approved").Priority(2).Outcome("approved"),
+		).
+		Pass("slow",
+			s.Start("request"),
+			s.Check("true"),
+			s.Check("true"),
+			s.Action("my_action", &testAction{}),
+			s.Named("Denied").Priority(1).Outcome("denied"),
+		)
+
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"approve": {Type: jsoncel.Boolean},
+		},
+	}
+
+	type testcase struct {
+		name         string
+		approve      bool
+		shortCircuit bool
+		wantOutcome  string
+		want         map[string]State
+	}
+
+	testcases := []testcase{
+		{
+			name:         "highest priority outcome skips remaining steps",
+			approve:      true,
+			shortCircuit: true,
+			wantOutcome:  "approved",
+			want: map[string]State{
+				"default.1": Complete,
+				"approved":  Complete,
+				"slow.1":    Complete,
+				"slow.2":    Skipped,
+				"slow.3":    Skipped,
+				"denied":    Skipped,
+			},
+		},
+		{
+			name:        "without short circuit",
+			approve:     true,
+			wantOutcome: "approved",
+			want: map[string]State{
+				"default.1": Complete,
+				"approved":  Complete,
+				"slow.1":    Complete,
+				"slow.2":    Complete,
+				"slow.3":    Active,
+				"denied":    Inactive,
+			},
+		},
+		{
+			name:         "highest priority outcome not reached",
+			shortCircuit: true,
+			want: map[string]State{
+				"default.1": Inactive,
+				"approved":  Inactive,
+				"slow.1":    Complete,
+				"slow.2":    Complete,
+				"slow.3":    Active,
+				"denied":    Inactive,
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: program, InputSchema: schema}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var opts []ExecuteOption
+			if tt.shortCircuit {
+				opts = append(opts, WithShortCircuit())
+			}
+
+			got, err := g.Execute("request", map[string]any{"approve": tt.approve}, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			for k, want := range tt.want {
+				assert.Equal(t, want.String(), got.State[k].String(), k)
+			}
+		})
+	}
+}
+
+func TestExecute_Unreachable(t *testing.T) {
+	escalation := []step.Step{
+		s.Check("true"),
+		s.Named("Escalated").Priority(1).Outcome("escalated"),
+	}
+
+	type testcase struct {
+		name string
+		give *Program
+		want map[string]State
+	}
+
+	testcases := []testcase{
+		{
+			name: "on_fail branch of a complete action",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.OnFail(step.Route, escalation...).ID("notify").Action("notify", &testFailAction{}),
+				s.Check("false"),
+				s.Named("Approved").Priority(2).Outcome("approved"),
+			),
+			want: map[string]State{
+				"default.notify": Complete,
+				"default.1.0":    Unreachable,
+				"escalated":      Unreachable,
+				// the check could still be complete with a different input.
+				"default.2": Inactive,
+				"approved":  Inactive,
+			},
+		},
+		{
+			name: "steps after a failed action",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.OnFail(step.Route, escalation...).ID("notify").Action("notify", &testFailAction{failed: true}),
+				s.Check("false"),
+				s.Named("Approved").Priority(2).Outcome("approved"),
+			),
+			want: map[string]State{
+				"default.notify": Failed,
+				"default.1.0":    Complete,
+				"escalated":      Complete,
+				"default.2":      Unreachable,
+				"approved":       Unreachable,
+			},
+		},
+		{
+			name: "and with a failed action",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.And,
+					s.Check("false"),
+					s.OnFail(step.FailWorkflow).ID("notify").Action("notify", &testFailAction{failed: true}),
+				),
+				s.Named("Approved").Priority(2).Outcome("approved"),
+			),
+			want: map[string]State{
+				"default.1.0":    Inactive,
+				"default.notify": Failed,
+				"default.1":      Unreachable,
+				"approved":       Unreachable,
+			},
+		},
+		{
+			name: "or with a failed action",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.Or,
+					s.Check("false"),
+					s.OnFail(step.FailWorkflow).ID("notify").Action("notify", &testFailAction{failed: true}),
+				),
+				s.Named("Approved").Priority(2).Outcome("approved"),
+			),
+			want: map[string]State{
+				"default.1.0":    Inactive,
+				"default.notify": Failed,
+				"default.1":      Inactive,
+				"approved":       Inactive,
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, want := range tt.want {
+				assert.Equal(t, want.String(), got.State[k].String(), k)
+			}
+		})
+	}
+}
+
+func TestExecute_Needs(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - or:
+          - id: security
+            check: input.security
+          - check: input.manager
+      - id: final
+        needs: [security]
+        check: input.final
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"security"}, p.Workflow["default"].Steps[2].Needs)
+
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"security": {Type: jsoncel.Boolean},
+			"manager":  {Type: jsoncel.Boolean},
+			"final":    {Type: jsoncel.Boolean},
+		},
+	}
+
+	type testcase struct {
+		name        string
+		input       map[string]any
+		partial     bool
+		wantOutcome string
+		wantFinal   State
+	}
+
+	testcases := []testcase{
+		{
+			name:        "needed step complete",
+			input:       map[string]any{"security": true, "manager": false, "final": true},
+			wantOutcome: "approved",
+			wantFinal:   Complete,
+		},
+		{
+			name:      "needed step not complete",
+			input:     map[string]any{"security": false, "manager": true, "final": true},
+			wantFinal: Inactive,
+		},
+		{
+			name:      "needed step unknown",
+			input:     map[string]any{"manager": true, "final": true},
+			partial:   true,
+			wantFinal: Unknown,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: p, InputSchema: schema}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var opts []ExecuteOption
+			if tt.partial {
+				opts = append(opts, WithPartialInput())
+			}
+
+			got, err := g.Execute("request", tt.input, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			assert.Equal(t, tt.wantFinal.String(), got.State["default.final"].String())
+		})
+	}
+}
+
+func TestExecute_Parallel(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"security": {Type: jsoncel.Boolean},
+			"manager":  {Type: jsoncel.Boolean},
+			"final":    {Type: jsoncel.Boolean},
+		},
+	}
+
+	type testcase struct {
+		name        string
+		join        string
+		input       map[string]any
+		wantOutcome string
+	}
+
+	testcases := []testcase{
+		{
+			name:        "all branches complete",
+			input:       map[string]any{"security": true, "manager": true, "final": true},
+			wantOutcome: "approved",
+		},
+		{
+			name:  "sequence not complete",
+			input: map[string]any{"security": true, "manager": true, "final": false},
+		},
+		{
+			name:  "single branch not complete",
+			input: map[string]any{"security": false, "manager": true, "final": true},
+		},
+		{
+			name:        "join any",
+			join:        "any",
+			input:       map[string]any{"security": false, "manager": true, "final": true},
+			wantOutcome: "approved",
+		},
+		{
+			name:  "join any no branches complete",
+			join:  "any",
+			input: map[string]any{"security": false, "manager": true, "final": false},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			join := ""
+			if tt.join != "" {
+				join = "join: " + tt.join
+			}
+			p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - parallel:
+          - check: input.security
+          - steps:
+              - check: input.manager
+              - check: input.final
+        `+join+`
+      - outcome: approved
+`), yamlTestDialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := Compiler{Program: p, InputSchema: schema}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+		})
+	}
+}
+
+func TestExecute_Preconditions(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"suspended": {Type: jsoncel.Boolean},
+			"mfa":       {Type: jsoncel.Boolean},
+			"admin":     {Type: jsoncel.Boolean},
+		},
+	}
+
+	p, err := Unmarshal([]byte(`
+preconditions:
+  - check: input.suspended == false
+  - check: input.mfa
+workflow:
+  admins:
+    steps:
+      - start: request
+      - check: input.admin
+      - outcome: approved
+  others:
+    steps:
+      - start: request
+      - check: input.admin == false
+      - outcome: denied
+  break_glass:
+    steps:
+      - start: escalate
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Compiler{Program: p, InputSchema: schema}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		name        string
+		start       string
+		input       map[string]any
+		wantOutcome string
+		// wantDenied is true if the 'denied' outcome is complete. It isn't
+		// in the dialect, so it doesn't have a priority to be the result outcome.
+		wantDenied bool
+	}
+
+	testcases := []testcase{
+		{
+			name:        "preconditions pass",
+			start:       "request",
+			input:       map[string]any{"suspended": false, "mfa": true, "admin": true},
+			wantOutcome: "approved",
+		},
+		{
+			name:       "preconditions are shared by passes",
+			start:      "request",
+			input:      map[string]any{"suspended": false, "mfa": true, "admin": false},
+			wantDenied: true,
+		},
+		{
+			name:  "precondition fails",
+			start: "request",
+			input: map[string]any{"suspended": false, "mfa": false, "admin": true},
+		},
+		{
+			name:        "other start node",
+			start:       "escalate",
+			input:       map[string]any{"suspended": false, "mfa": true},
+			wantOutcome: "approved",
+		},
+		{
+			name:  "other start node precondition fails",
+			start: "escalate",
+			input: map[string]any{"suspended": true, "mfa": true},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Execute(tt.start, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			assert.Equal(t, tt.wantDenied, got.State["denied"] == Complete)
+		})
+	}
+}
+
+func TestCompile_InvalidPreconditions(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Outcome("approved"),
+	)
+	p.Preconditions = []step.Step{s.Action("approval", testAction{})}
+
+	c := Compiler{Program: p}
+	_, err := c.Compile()
+	assert.ErrorContains(t, err, "preconditions must be checks")
+}
+
+func TestExecute_DefaultOutcome(t *testing.T) {
+	d := dialect.Dialect{
+		Nodes: map[string]node.Node{
+			"request":        {Type: node.Start},
+			"approved":       {Type: node.Outcome, Priority: 2},
+			"pending_review": {Type: node.Outcome, Priority: 1, Name: "Pending review"},
+		},
+		DefaultOutcome: "pending_review",
+	}
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"approved": {Type: jsoncel.Boolean},
+		},
+	}
+
+	type testcase struct {
+		name           string
+		defaultOutcome string
+		input          map[string]any
+		wantOutcome    string
+		wantDefault    bool
+		wantCompileErr string
+	}
+
+	testcases := []testcase{
+		{
+			name:        "outcome reached",
+			input:       map[string]any{"approved": true},
+			wantOutcome: "approved",
+		},
+		{
+			name:        "dialect default outcome",
+			input:       map[string]any{"approved": false},
+			wantOutcome: "pending_review",
+			wantDefault: true,
+		},
+		{
+			name:           "program default outcome",
+			defaultOutcome: "default_outcome: approved",
+			input:          map[string]any{"approved": false},
+			wantOutcome:    "approved",
+			wantDefault:    true,
+		},
+		{
+			name:           "default outcome not in dialect",
+			defaultOutcome: "default_outcome: unknown",
+			wantCompileErr: "default outcome unknown must be an outcome node in the dialect",
+		},
+		{
+			name:           "default outcome is a start node",
+			defaultOutcome: "default_outcome: request",
+			wantCompileErr: "default outcome request must be an outcome node in the dialect",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Unmarshal([]byte(tt.defaultOutcome+`
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: input.approved
+      - outcome: approved
+`), d)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := Compiler{Program: p, InputSchema: schema}
+			g, err := c.Compile()
+			if tt.wantCompileErr != "" {
+				assert.EqualError(t, err, tt.wantCompileErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			assert.Equal(t, tt.wantOutcome, got.OutcomeNode.ID)
+			assert.Equal(t, tt.wantDefault, got.DefaultOutcome)
+		})
+	}
+}
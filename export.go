@@ -0,0 +1,160 @@
+package glide
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// exportGraph is the JSON representation of a graph, in the
+// Cytoscape.js elements format.
+//
+//	{"elements": {"nodes": [{"data": {...}}], "edges": [{"data": {...}}]}}
+type exportGraph struct {
+	Elements exportElements `json:"elements"`
+}
+
+type exportElements struct {
+	Nodes []exportElement[exportNode] `json:"nodes"`
+	Edges []exportElement[exportEdge] `json:"edges"`
+}
+
+type exportElement[T any] struct {
+	Data T `json:"data"`
+}
+
+type exportNode struct {
+	ID          string `json:"id"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	// Type is 'start', 'outcome', 'check', 'action', 'and' or 'or'.
+	Type string `json:"type"`
+	// Pass is empty for start and outcome nodes,
+	// which are shared between passes.
+	Pass  string `json:"pass,omitempty"`
+	State *State `json:"state,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Attributes are the rendering attributes set with SetNodeAttribute.
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type exportEdge struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Label  string `json:"label,omitempty"`
+	// Style is 'dashed' for on_fail edges and 'dotted' for needs edges.
+	Style string `json:"style,omitempty"`
+}
+
+// ExportJSON returns the graph as JSON in the Cytoscape.js elements format,
+// so that it can be rendered as an interactive diagram in a web UI.
+// If a result is provided, the state of each step is included.
+//
+// Nodes have the fields 'id', 'label', 'description', 'type', 'pass', 'state' and 'attributes',
+// and edges have the fields 'id', 'source', 'target', 'label' and 'style'.
+// If the result was localized with WithLocale, steps are labelled in its locale.
+func (g *Graph) ExportJSON(res *Result) ([]byte, error) {
+	var locale string
+	if res != nil {
+		locale = res.Locale
+	}
+	return g.ExportLocalizedJSON(res, locale)
+}
+
+// ExportLocalizedJSON is ExportJSON with the steps labelled in the locale,
+// using the 'names' of the nodes and steps. See node.Localize.
+func (g *Graph) ExportLocalizedJSON(res *Result, locale string) ([]byte, error) {
+	// results of executions which were expanded for the input are
+	// rendered on the graph which was executed.
+	if res != nil && res.graph != nil && res.graph != g {
+		eg, r := g.expandedFor(res)
+		return eg.ExportLocalizedJSON(r, locale)
+	}
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return nil, err
+	}
+
+	stepErrs := map[string]string{}
+	if res != nil {
+		for _, se := range res.StepErrors {
+			stepErrs[se.Step] = se.Err.Error()
+		}
+	}
+
+	out := exportGraph{
+		Elements: exportElements{
+			Nodes: []exportElement[exportNode]{},
+			Edges: []exportElement[exportEdge]{},
+		},
+	}
+
+	for _, k := range hashes {
+		s, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+
+		n := exportNode{
+			ID:          k,
+			Label:       node.Localize(s.Names, locale, exportLabel(s)),
+			Description: s.Description,
+			Type:        exportType(s),
+			Pass:        s.Pass,
+			Error:       stepErrs[k],
+			Attributes:  g.NodeAttributes(k),
+		}
+		if _, ok := s.Body.(step.Ref); ok {
+			n.Pass = ""
+		}
+		if state, ok := res.state(k); ok {
+			n.State = &state
+		}
+		out.Elements.Nodes = append(out.Elements.Nodes, exportElement[exportNode]{Data: n})
+	}
+
+	edges, err := g.store.edges()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range edges {
+		out.Elements.Edges = append(out.Elements.Edges, exportElement[exportEdge]{Data: exportEdge{
+			ID:     e.Source + "->" + e.Target,
+			Source: e.Source,
+			Target: e.Target,
+			Label:  e.Attributes["label"],
+			Style:  e.Attributes["style"],
+		}})
+	}
+
+	return json.Marshal(out)
+}
+
+// exportLabel returns the label of a step. Unlike Label,
+// quotes in check expressions aren't escaped for DOT.
+func exportLabel(s step.Step) string {
+	if c, ok := s.Body.(step.Check); ok && s.Name == "" {
+		return "if: " + c.Expression
+	}
+	return s.Label()
+}
+
+// exportType returns the type of a step, as used in the exported JSON.
+func exportType(s step.Step) string {
+	switch t := s.Body.(type) {
+	case step.Ref:
+		return t.Node.Type.String()
+	case step.Check:
+		return "check"
+	case step.Action:
+		return "action"
+	case step.Boolean:
+		return strings.ToLower(t.String())
+	case step.Wait:
+		return "wait"
+	}
+	return ""
+}
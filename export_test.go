@@ -0,0 +1,90 @@
+package glide
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_ExportJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    *Program
+		execute bool
+		want    string
+	}{
+		{
+			name: "ok",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.Or,
+					s.Check(`"admins" == "ops"`),
+					s.Named("Approval").Action("my_action", &testAction{}),
+				),
+				s.Outcome("approved"),
+			),
+			want: `{"elements": {
+				"nodes": [
+					{"data": {"id": "approved", "label": "outcome: approved", "type": "outcome"}},
+					{"data": {"id": "default.1", "label": "OR", "type": "or", "pass": "default"}},
+					{"data": {"id": "default.1.0", "label": "if: \"admins\" == \"ops\"", "type": "check", "pass": "default"}},
+					{"data": {"id": "default.1.1", "label": "Approval", "type": "action", "pass": "default"}},
+					{"data": {"id": "request", "label": "start: request", "type": "start"}}
+				],
+				"edges": [
+					{"data": {"id": "default.1->approved", "source": "default.1", "target": "approved"}},
+					{"data": {"id": "default.1.0->default.1", "source": "default.1.0", "target": "default.1"}},
+					{"data": {"id": "default.1.1->default.1", "source": "default.1.1", "target": "default.1"}},
+					{"data": {"id": "request->default.1.0", "source": "request", "target": "default.1.0"}},
+					{"data": {"id": "request->default.1.1", "source": "request", "target": "default.1.1"}}
+				]
+			}}`,
+		},
+		{
+			name: "with result",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.OnFail(step.Continue).Action("notify", &testFailAction{err: errors.New("unavailable")}),
+				s.Outcome("approved"),
+			),
+			execute: true,
+			want: `{"elements": {
+				"nodes": [
+					{"data": {"id": "approved", "label": "outcome: approved", "type": "outcome", "state": "complete"}},
+					{"data": {"id": "default.1", "label": "action: notify", "type": "action", "pass": "default", "state": "failed"}},
+					{"data": {"id": "request", "label": "start: request", "type": "start", "state": "complete"}}
+				],
+				"edges": [
+					{"data": {"id": "default.1->approved", "source": "default.1", "target": "approved"}},
+					{"data": {"id": "request->default.1", "source": "request", "target": "default.1"}}
+				]
+			}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var res *Result
+			if tt.execute {
+				res, err = g.Execute("request", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := g.ExportJSON(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
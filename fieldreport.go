@@ -0,0 +1,166 @@
+package glide
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// FieldUsage is an input field which is referenced by the checks in a workflow.
+type FieldUsage struct {
+	// Path is the dot-separated path of the field, e.g. 'group.id'.
+	Path string `json:"path"`
+
+	// Type is the type of the field inferred by the CEL type checker,
+	// e.g. 'string' or 'list(string)'. Nested objects have the type 'object',
+	// and fields which the type checker can't infer a type for have the type 'dyn'.
+	Type string `json:"type"`
+
+	// Declared is false if the field isn't declared in the input schema, which is
+	// only possible if its parent is an object which doesn't declare its properties.
+	Declared bool `json:"declared"`
+
+	// Steps are the hashes of the checks which reference the field, sorted.
+	Steps []string `json:"steps"`
+}
+
+// FieldReport lists the input fields referenced by a workflow's checks,
+// cross-checked against the input schema so that the schema and the
+// workflow can be kept in sync.
+type FieldReport struct {
+	// Fields are the input fields referenced by checks, sorted by path.
+	Fields []FieldUsage `json:"fields"`
+
+	// Undeclared are the paths of the referenced fields which
+	// aren't declared in the input schema, sorted.
+	Undeclared []string `json:"undeclared"`
+
+	// Unused are the paths of the fields in the input schema which no check
+	// references, sorted. If none of an object's properties are referenced,
+	// only the object itself is listed rather than each of its properties.
+	Unused []string `json:"unused"`
+}
+
+// AnalyzeFields lists the input fields referenced by the checks in the graph.
+// A check which references an object, e.g. 'input.group', uses each of its properties.
+func (g *Graph) AnalyzeFields() (*FieldReport, error) {
+	v := fieldUsageVisitor{asts: g.asts, usage: map[string]*FieldUsage{}}
+	err := g.Walk(&v)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *jsoncel.Schema
+	if g.provider != nil {
+		schema = g.provider.Schema()
+	}
+
+	report := FieldReport{}
+	for _, u := range v.usage {
+		sort.Strings(u.Steps)
+		u.Declared = schema != nil && jsoncel.Lookup(schema, u.Path) != nil
+		if !u.Declared {
+			report.Undeclared = append(report.Undeclared, u.Path)
+		}
+		report.Fields = append(report.Fields, *u)
+	}
+	sort.Slice(report.Fields, func(i, j int) bool { return report.Fields[i].Path < report.Fields[j].Path })
+	sort.Strings(report.Undeclared)
+
+	if schema != nil {
+		appendUnusedFields(&report.Unused, "", schema, v.usage)
+		sort.Strings(report.Unused)
+	}
+	return &report, nil
+}
+
+// appendUnusedFields appends the properties of the schema which
+// aren't referenced, and which don't have a referenced descendant.
+func appendUnusedFields(unused *[]string, prefix string, s *jsoncel.Schema, usage map[string]*FieldUsage) {
+	for k, child := range s.Properties {
+		path := prefix + k
+		if _, ok := usage[path]; ok {
+			continue
+		}
+
+		descendant := false
+		for used := range usage {
+			if strings.HasPrefix(used, path+".") {
+				descendant = true
+				break
+			}
+		}
+		if !descendant {
+			*unused = append(*unused, path)
+			continue
+		}
+		if child != nil {
+			appendUnusedFields(unused, path+".", child, usage)
+		}
+	}
+}
+
+// fieldUsageVisitor collects the input fields referenced
+// by checks, along with their types and the checks.
+type fieldUsageVisitor struct {
+	NopVisitor
+	asts  map[string]*cel.Ast
+	usage map[string]*FieldUsage
+}
+
+// VisitAction collects the input fields referenced by the 'if' guard of an action.
+func (v *fieldUsageVisitor) VisitAction(s step.Step, a step.Action) error {
+	if s.If == "" {
+		return nil
+	}
+	return v.VisitCheck(s, step.Check{Expression: s.If})
+}
+
+func (v *fieldUsageVisitor) VisitCheck(s step.Step, c step.Check) error {
+	ast, ok := v.asts[s.Hash()]
+	if !ok {
+		return fmt.Errorf("could not find CEL AST for %s", s.Hash())
+	}
+
+	var types map[int64]*exprpb.Type
+	if ast.IsChecked() {
+		checked, err := cel.AstToCheckedExpr(ast)
+		if err != nil {
+			return err
+		}
+		types = checked.GetTypeMap()
+	}
+
+	collectInputFields(ast.Expr(), func(path string, e *exprpb.Expr) {
+		u, ok := v.usage[path]
+		if !ok {
+			u = &FieldUsage{Path: path, Type: "dyn"}
+			v.usage[path] = u
+		}
+		if t := fieldTypeName(types[e.GetId()]); u.Type == "dyn" {
+			u.Type = t
+		}
+		if len(u.Steps) == 0 || u.Steps[len(u.Steps)-1] != s.Hash() {
+			u.Steps = append(u.Steps, s.Hash())
+		}
+	})
+	return nil
+}
+
+// fieldTypeName formats a checked type, naming the
+// object types generated from the input schema 'object'.
+func fieldTypeName(t *exprpb.Type) string {
+	if t == nil {
+		return "dyn"
+	}
+	if t.GetMessageType() != "" {
+		return "object"
+	}
+	return checker.FormatCheckedType(t)
+}
@@ -0,0 +1,59 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeFields(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check(`input.group.id == "admins" && input.duration < 8`),
+			s.Check(`"oncall" in input.user.labels && input.context.ticket != ""`),
+			s.Check(`input.duration > 1`),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+		InputSchema: &jsoncel.Schema{
+			Properties: map[string]*jsoncel.Schema{
+				"group": {
+					Type: jsoncel.Object,
+					Properties: map[string]*jsoncel.Schema{
+						"id":   {Type: jsoncel.String},
+						"name": {Type: jsoncel.String},
+					},
+				},
+				"duration": {Type: jsoncel.Integer},
+				"user": {
+					Type: jsoncel.Object,
+					Properties: map[string]*jsoncel.Schema{
+						"labels": {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+					},
+				},
+				"context":       {Type: jsoncel.Object},
+				"justification": {Type: jsoncel.String},
+			},
+		},
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.AnalyzeFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []FieldUsage{
+		{Path: "context.ticket", Type: "dyn", Declared: false, Steps: []string{"default.2"}},
+		{Path: "duration", Type: "int", Declared: true, Steps: []string{"default.1", "default.3"}},
+		{Path: "group.id", Type: "string", Declared: true, Steps: []string{"default.1"}},
+		{Path: "user.labels", Type: "list(string)", Declared: true, Steps: []string{"default.2"}},
+	}, got.Fields)
+	assert.Equal(t, []string{"context.ticket"}, got.Undeclared)
+	assert.Equal(t, []string{"group.name", "justification"}, got.Unused)
+}
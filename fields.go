@@ -0,0 +1,110 @@
+package glide
+
+import (
+	"sort"
+
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// inputFields returns the input fields referenced by a CEL expression,
+// as dot-separated paths relative to the input (e.g. 'group.id').
+// The returned paths are sorted and unique.
+func inputFields(e *exprpb.Expr) []string {
+	found := map[string]bool{}
+	collectInputFields(e, func(path string, _ *exprpb.Expr) { found[path] = true })
+
+	var fields []string
+	for f := range found {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// collectInputFields calls visit with each input field referenced by
+// the expression, along with the expression which selects the field.
+func collectInputFields(e *exprpb.Expr, visit func(path string, e *exprpb.Expr)) {
+	if e == nil {
+		return
+	}
+
+	// record the longest field path, rather than each of its parents.
+	if path, ok := inputPath(e); ok {
+		if path != "" {
+			visit(path, e)
+		}
+		return
+	}
+
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		collectInputFields(k.SelectExpr.GetOperand(), visit)
+	case *exprpb.Expr_CallExpr:
+		collectInputFields(k.CallExpr.GetTarget(), visit)
+		for _, a := range k.CallExpr.GetArgs() {
+			collectInputFields(a, visit)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, el := range k.ListExpr.GetElements() {
+			collectInputFields(el, visit)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			collectInputFields(entry.GetMapKey(), visit)
+			collectInputFields(entry.GetValue(), visit)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		c := k.ComprehensionExpr
+		collectInputFields(c.GetIterRange(), visit)
+		collectInputFields(c.GetAccuInit(), visit)
+		collectInputFields(c.GetLoopCondition(), visit)
+		collectInputFields(c.GetLoopStep(), visit)
+		collectInputFields(c.GetResult(), visit)
+	}
+}
+
+// inputPath returns the field path if the expression is a chain of
+// field selections (or constant string indexes) on the 'input' variable.
+//
+//	input.group.id     -> 'group.id'
+//	input["group"].id  -> 'group.id'
+//	input              -> ''
+func inputPath(e *exprpb.Expr) (string, bool) {
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		return "", k.IdentExpr.GetName() == "input"
+
+	case *exprpb.Expr_SelectExpr:
+		if k.SelectExpr.GetTestOnly() {
+			return "", false
+		}
+		return appendPath(k.SelectExpr.GetOperand(), k.SelectExpr.GetField())
+
+	case *exprpb.Expr_CallExpr:
+		call := k.CallExpr
+		if call.GetFunction() != "_[_]" || len(call.GetArgs()) != 2 {
+			return "", false
+		}
+		key, ok := call.GetArgs()[1].GetExprKind().(*exprpb.Expr_ConstExpr)
+		if !ok {
+			return "", false
+		}
+		field, ok := key.ConstExpr.GetConstantKind().(*exprpb.Constant_StringValue)
+		if !ok {
+			return "", false
+		}
+		return appendPath(call.GetArgs()[0], field.StringValue)
+	}
+	return "", false
+}
+
+func appendPath(operand *exprpb.Expr, field string) (string, bool) {
+	parent, ok := inputPath(operand)
+	if !ok {
+		return "", false
+	}
+	if parent == "" {
+		return field, true
+	}
+	return parent + "." + field, true
+}
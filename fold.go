@@ -0,0 +1,109 @@
+package glide
+
+import (
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// foldConstants removes the checks which are always true from the graph.
+// The steps before a removed check are linked directly to the steps after it,
+// so the graph is executed in the same way, with fewer steps.
+//
+// Checks with an ID or a name aren't removed, as other checks can reference
+// them, and neither are checks which need other steps or are needed by them,
+// or checks with a schedule, which are only complete during it.
+// Checks which are always false are kept: removing them would change when
+// 'and' steps after them are complete. They are reported as warnings instead.
+func (g *Graph) foldConstants() error {
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return err
+	}
+
+	for _, k := range hashes {
+		s, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.Body.(step.Check); !ok || s.ID != "" || s.Name != "" || len(s.Names) > 0 || s.When != nil || !g.constantTrue(k) {
+			continue
+		}
+
+		pres, err := g.store.predecessors(k)
+		if err != nil {
+			return err
+		}
+		succs, err := g.store.successors(k)
+		if err != nil {
+			return err
+		}
+		if !g.foldable(k, pres, succs) {
+			continue
+		}
+
+		err = g.store.removeStep(k)
+		if err != nil {
+			return err
+		}
+
+		// edges into the check, such as the first step of an on_fail branch,
+		// keep their attributes.
+		for _, p := range pres {
+			for _, target := range succs {
+				if g.hasEdge(p.Source, target) {
+					continue
+				}
+				err = g.store.addEdge(p.Source, target, p.Attributes)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		delete(g.programs, k)
+		delete(g.asts, k)
+		delete(g.deps, k)
+		for target, deps := range g.deps {
+			g.deps[target] = removeString(deps, k)
+		}
+	}
+	return nil
+}
+
+// foldable returns true if the edges into and out of a check don't have attributes
+// which would be lost by removing it, other than the attributes of on_fail edges into it.
+func (g *Graph) foldable(k string, pres []edge, succs []string) bool {
+	if len(pres) == 0 || len(succs) == 0 {
+		return false
+	}
+	for _, p := range pres {
+		if p.Attributes[needsAttribute] == "true" {
+			return false
+		}
+	}
+	for _, target := range succs {
+		targetPres, err := g.store.predecessors(target)
+		if err != nil {
+			return false
+		}
+		for _, e := range targetPres {
+			if e.Source == k && len(e.Attributes) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasEdge returns true if there is an edge from the source to the target.
+func (g *Graph) hasEdge(source, target string) bool {
+	succs, err := g.store.successors(source)
+	if err != nil {
+		return false
+	}
+	for _, s := range succs {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
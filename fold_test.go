@@ -0,0 +1,151 @@
+package glide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_FoldConstants(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"approved": {Type: jsoncel.Boolean},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		give       *Program
+		wantHashes []string
+		wantEdges  []string
+		// wantOutcome is the outcome for each value of input.approved.
+		wantOutcome map[bool]string
+	}{
+		{
+			name: "check in sequence",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("true"),
+				s.Check("input.approved"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			wantHashes:  []string{"approved", "default.2", "request"},
+			wantEdges:   []string{"default.2->approved", "request->default.2"},
+			wantOutcome: map[bool]string{true: "approved", false: ""},
+		},
+		{
+			name: "branch of and",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.And, s.Check("1 == 1"), s.Check("input.approved")),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			wantHashes:  []string{"approved", "default.1", "default.1.1", "request"},
+			wantEdges:   []string{"default.1->approved", "default.1.1->default.1", "request->default.1", "request->default.1.1"},
+			wantOutcome: map[bool]string{true: "approved", false: ""},
+		},
+		{
+			name: "branch of or",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.Or, s.Check("true"), s.Check("input.approved")),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			wantHashes:  []string{"approved", "default.1", "default.1.1", "request"},
+			wantEdges:   []string{"default.1->approved", "default.1.1->default.1", "request->default.1", "request->default.1.1"},
+			wantOutcome: map[bool]string{true: "approved", false: "approved"},
+		},
+		{
+			name: "named check is kept",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Named("placeholder").Check("true"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			wantHashes:  []string{"approved", "default.1", "request"},
+			wantEdges:   []string{"default.1->approved", "request->default.1"},
+			wantOutcome: map[bool]string{true: "approved", false: "approved"},
+		},
+		{
+			name: "false check is kept",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("false"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			wantHashes:  []string{"approved", "default.1", "request"},
+			wantEdges:   []string{"default.1->approved", "request->default.1"},
+			wantOutcome: map[bool]string{true: "", false: ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give, InputSchema: schema, FoldConstants: true}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hashes, err := g.store.hashes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantHashes, hashes)
+
+			edges, err := g.store.edges()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotEdges []string
+			for _, e := range edges {
+				gotEdges = append(gotEdges, e.Source+"->"+e.Target)
+			}
+			assert.Equal(t, tt.wantEdges, gotEdges)
+
+			for approved, want := range tt.wantOutcome {
+				res, err := g.Execute("request", map[string]any{"approved": approved})
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, want, res.Outcome, "approved=%v", approved)
+			}
+		})
+	}
+}
+
+func TestCompile_FoldConstantsKeepsSchedules(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		(&s.StepBuilder{}).When("mon-fri 09:00-17:00").Check("true"),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)
+	c := Compiler{Program: p, FoldConstants: true}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashes, err := g.store.hashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"approved", "default.1", "request"}, hashes)
+
+	saturday := time.Date(2023, 1, 7, 12, 0, 0, 0, time.UTC)
+	res, err := g.Execute("request", map[string]any{}, WithTime(saturday))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", res.Outcome)
+
+	monday := time.Date(2023, 1, 9, 12, 0, 0, 0, time.UTC)
+	res, err = g.Execute("request", map[string]any{}, WithTime(monday))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+}
@@ -0,0 +1,119 @@
+package glide
+
+import (
+	"fmt"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// eachVar is the variable which the steps of a for_each step read the element from,
+// e.g. 'each.entitlement'.
+const eachVar = "each"
+
+// expandForEach returns a copy of the steps in a pass, with each for_each step
+// replaced by a parallel step which has a branch for each element of its input list.
+// The branches are joined with the for_each step's join, so that the parallel step
+// is complete once every element, or any element, has been approved.
+//
+// If the input is nil, for_each steps are expanded with one branch with a placeholder
+// value, so that the graph can be checked and rendered before it's executed.
+// found is true if the steps contain any for_each steps.
+//
+// It's an error for the for_each steps to expand into more than maxForEachBranches
+// branches, counting the branches of nested for_each steps.
+func expandForEach(steps []step.Step, pass string, schema *jsoncel.Schema, input map[string]any) (out []step.Step, found bool, err error) {
+	var branches int
+	return expandForEachSteps(steps, pass, schema, input, &branches)
+}
+
+// expandForEachSteps expands the for_each steps in steps, adding
+// the number of branches they expand into to branches.
+func expandForEachSteps(steps []step.Step, pass string, schema *jsoncel.Schema, input map[string]any, branches *int) (out []step.Step, found bool, err error) {
+	if steps == nil {
+		return nil, false, nil
+	}
+
+	out = make([]step.Step, len(steps))
+	for i, s := range steps {
+		f, ok := s.Body.(step.ForEach)
+		if !ok {
+			var foundChildren, foundOnFail bool
+			s.Children, foundChildren, err = expandForEachSteps(s.Children, pass, schema, input, branches)
+			if err != nil {
+				return nil, false, err
+			}
+			s.OnFail.Steps, foundOnFail, err = expandForEachSteps(s.OnFail.Steps, pass, schema, input, branches)
+			if err != nil {
+				return nil, false, err
+			}
+			found = found || foundChildren || foundOnFail
+			out[i] = s
+			continue
+		}
+		found = true
+
+		_, item, err := inputField(schema, f.Field)
+		if err != nil {
+			return nil, false, noderr.Wrap(err, s.Node)
+		}
+		var t jsoncel.FieldType
+		if item != nil {
+			t = item.Type
+		}
+
+		values := []matrixValue{placeholderValue(t)}
+		if input != nil {
+			values, err = inputValues(input, f.Field, t)
+			if err != nil {
+				return nil, false, noderr.Wrap(err, s.Node)
+			}
+		}
+
+		if s.Name == "" {
+			s.Name = f.String()
+		}
+
+		// with no elements, every element has been approved
+		// but no element can be approved.
+		if len(values) == 0 {
+			s.Body = step.Check{Expression: "true"}
+			if f.Op == step.Or {
+				s.Body = step.Check{Expression: "false"}
+			}
+			s.Children = nil
+			out[i] = s
+			continue
+		}
+
+		*branches += len(values)
+		if *branches > maxForEachBranches {
+			return nil, false, noderr.Wrap(fmt.Errorf("for_each steps in pass %s expand into more than %d branches", pass, maxForEachBranches), s.Node)
+		}
+
+		var expanded []step.Step
+		for _, v := range values {
+			children, err := expandSteps(s.Children, refs{
+				namespace: eachVar,
+				pass:      pass,
+				values:    map[string]matrixValue{f.As: v},
+				// nested for_each steps reference their own elements.
+				partial: true,
+			})
+			if err != nil {
+				return nil, false, err
+			}
+			children, _, err = expandForEachSteps(children, pass, schema, input, branches)
+			if err != nil {
+				return nil, false, err
+			}
+			expanded = append(expanded, step.Step{Body: step.Sequence{}, Children: children, Pass: pass, Node: s.Node})
+		}
+
+		s.Body = step.Boolean{Op: f.Op}
+		s.Children = expanded
+		out[i] = s
+	}
+	return out, found, nil
+}
@@ -0,0 +1,205 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEach_Execute(t *testing.T) {
+	workflow := func(join string) string {
+		return `
+workflow:
+  default:
+    steps:
+      - start: request
+      - for_each: input.entitlements
+        as: entitlement
+        join: ` + join + `
+        steps:
+          - name: Approve ${each.entitlement}
+            check: each.entitlement in input.approved
+      - outcome: approved
+`
+	}
+	list := &jsoncel.Schema{Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}}
+	schema := &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{"entitlements": list, "approved": list},
+	}
+
+	tests := []struct {
+		name      string
+		join      string
+		input     map[string]any
+		wantState map[string]State
+	}{
+		{
+			name:  "all elements approved",
+			join:  "all",
+			input: map[string]any{"entitlements": []any{"admin", "read"}, "approved": []any{"admin", "read"}},
+			wantState: map[string]State{
+				"request":       Complete,
+				"default.1.0.0": Complete,
+				"default.1.1.0": Complete,
+				"default.1":     Complete,
+				"approved":      Complete,
+			},
+		},
+		{
+			name:  "one element approved",
+			join:  "all",
+			input: map[string]any{"entitlements": []any{"admin", "read"}, "approved": []any{"read"}},
+			wantState: map[string]State{
+				"request":       Complete,
+				"default.1.0.0": Inactive,
+				"default.1.1.0": Complete,
+				"default.1":     Inactive,
+				"approved":      Inactive,
+			},
+		},
+		{
+			name:  "any element approved",
+			join:  "any",
+			input: map[string]any{"entitlements": []any{"admin", "read"}, "approved": []any{"read"}},
+			wantState: map[string]State{
+				"request":       Complete,
+				"default.1.0.0": Inactive,
+				"default.1.1.0": Complete,
+				"default.1":     Complete,
+				"approved":      Complete,
+			},
+		},
+		{
+			name:  "no elements with all",
+			join:  "all",
+			input: map[string]any{"entitlements": []any{}, "approved": []any{}},
+			wantState: map[string]State{
+				"request":   Complete,
+				"default.1": Complete,
+				"approved":  Complete,
+			},
+		},
+		{
+			name:  "no elements with any",
+			join:  "any",
+			input: map[string]any{"entitlements": []any{}, "approved": []any{}},
+			wantState: map[string]State{
+				"request":   Complete,
+				"default.1": Inactive,
+				"approved":  Inactive,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Unmarshal([]byte(workflow(tt.join)), yamlTestDialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := Compiler{Program: p, InputSchema: schema}
+			g, err := c.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantState, got.State)
+		})
+	}
+}
+
+func TestExpandForEach(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - parallel:
+          - check: "true"
+          - for_each: input.groups
+            steps:
+              - action: my_action
+                with:
+                  property: ${each.value}
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parallel := p.Workflow["default"].Steps[1]
+	assert.Equal(t, step.ForEach{Field: "groups", As: "value"}, parallel.Children[1].Body)
+
+	got, found, err := expandForEach(p.Workflow["default"].Steps, "default", nil, map[string]any{"groups": []any{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, found)
+
+	forEach := got[1].Children[1]
+	assert.Equal(t, "for each value in input.groups", forEach.Name)
+	assert.Equal(t, step.Boolean{Op: step.And}, forEach.Body)
+	if assert.Len(t, forEach.Children, 2) {
+		assert.Equal(t, "b", forEach.Children[1].Children[0].Body.(step.Action).Action.(*testAction).Property)
+	}
+
+	// placeholder values are used when there's no input.
+	got, _, err = expandForEach(p.Workflow["default"].Steps, "default", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forEach = got[1].Children[1]
+	if assert.Len(t, forEach.Children, 1) {
+		assert.Equal(t, "*", forEach.Children[0].Children[0].Body.(step.Action).Action.(*testAction).Property)
+	}
+}
+
+func TestForEach_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr string
+	}{
+		{
+			name:    "not an input field",
+			give:    "for_each: [a, b]\n        steps:\n          - check: \"true\"",
+			wantErr: "for_each must be an input field, like 'input.entitlements'",
+		},
+		{
+			name:    "no steps",
+			give:    "for_each: input.groups",
+			wantErr: "for_each must contain a 'steps' field",
+		},
+		{
+			name:    "step ids",
+			give:    "for_each: input.groups\n        steps:\n          - id: approval\n            check: \"true\"",
+			wantErr: "steps in a for_each can't have an id, as they are repeated for each element",
+		},
+		{
+			name:    "invalid join",
+			give:    "for_each: input.groups\n        join: some\n        steps:\n          - check: \"true\"",
+			wantErr: `invalid join "some": must be 'all' or 'any'`,
+		},
+		{
+			name:    "as without for_each",
+			give:    "as: group\n        and:\n          - check: \"true\"",
+			wantErr: "as can only be used on for_each steps",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := "workflow:\n  default:\n    steps:\n      - start: request\n      - " + tt.give + "\n      - outcome: approved\n"
+			_, err := Unmarshal([]byte(workflow), yamlTestDialect)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
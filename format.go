@@ -0,0 +1,104 @@
+package glide
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/google/cel-go/cel"
+)
+
+// FormatExpression parses a CEL expression and returns it in a canonical format,
+// with consistent spacing, double-quoted strings, and only the parentheses
+// which are needed. For example:
+//
+//	input.a==1&&(input.b)   ->   input.a == 1 && input.b
+//
+// The expression is parsed but not type-checked, so that syntax errors
+// can be reported without an input schema.
+func FormatExpression(expression string) (string, error) {
+	// macro calls are tracked so that macros like 'all' and 'exists'
+	// are printed as they were written, rather than expanded.
+	env, err := cel.NewEnv(cel.EnableMacroCallTracking())
+	if err != nil {
+		return "", err
+	}
+
+	parsed, issues := env.Parse(expression)
+	if issues != nil && issues.Err() != nil {
+		return "", fmt.Errorf("CEL parse error: %s", issues.Err())
+	}
+
+	return cel.AstToString(parsed)
+}
+
+// FormattedCheck is a check in a program, along with its canonical expression.
+type FormattedCheck struct {
+	// Pass is the name of the pass containing the check.
+	Pass string
+
+	// Position of the check in the pass.
+	Position []int
+
+	// Expression is the expression as it was written.
+	Expression string
+
+	// Formatted is the canonical expression. It is empty if Err is set.
+	Formatted string
+
+	// Err is set if the expression could not be parsed.
+	Err error
+
+	// Node is the YAML node of the check, if the program was
+	// unmarshalled from YAML. Used to pretty-print errors.
+	Node ast.Node
+}
+
+// Changed returns true if the canonical expression
+// is different to the expression as it was written.
+func (f FormattedCheck) Changed() bool {
+	return f.Err == nil && f.Formatted != f.Expression
+}
+
+// FormatChecks formats the expression of every check and action guard in the program,
+// starting with the preconditions and then ordered by pass name and position.
+func FormatChecks(p *Program) []FormattedCheck {
+	var passes []string
+	for id := range p.Workflow {
+		passes = append(passes, id)
+	}
+	sort.Strings(passes)
+
+	checks := appendFormattedChecks(nil, PreconditionsPass, p.Preconditions, nil)
+	for _, pass := range passes {
+		checks = appendFormattedChecks(checks, pass, p.Workflow[pass].Steps, nil)
+	}
+	return checks
+}
+
+func appendFormattedChecks(checks []FormattedCheck, pass string, steps []step.Step, parent []int) []FormattedCheck {
+	for i, s := range steps {
+		pos := append(append([]int{}, parent...), i)
+
+		// the 'if' guards of actions are formatted like checks.
+		expression := s.If
+		if c, ok := s.Body.(step.Check); ok {
+			expression = c.Expression
+		}
+		if expression != "" {
+			formatted, err := FormatExpression(expression)
+			checks = append(checks, FormattedCheck{
+				Pass:       pass,
+				Position:   pos,
+				Expression: expression,
+				Formatted:  formatted,
+				Err:        err,
+				Node:       s.Node,
+			})
+		}
+		checks = appendFormattedChecks(checks, pass, s.Children, pos)
+		checks = appendFormattedChecks(checks, pass, s.OnFail.Steps, pos)
+	}
+	return checks
+}
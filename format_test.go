@@ -0,0 +1,68 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       string
+		wantErr    bool
+	}{
+		{name: "canonical", expression: `input.a == 1`, want: `input.a == 1`},
+		{name: "spacing", expression: `input.a==1&&input.b`, want: `input.a == 1 && input.b`},
+		{name: "redundant parentheses", expression: `(input.a) && !(input.b)`, want: `input.a && !input.b`},
+		{name: "needed parentheses", expression: `input.a && (input.b || input.c)`, want: `input.a && (input.b || input.c)`},
+		{name: "quotes", expression: `input.group == 'admins'`, want: `input.group == "admins"`},
+		{name: "macro", expression: `input.groups.exists(g,g=="admins")`, want: `input.groups.exists(g, g == "admins")`},
+		{name: "parse error", expression: `input.a ==`, wantErr: true},
+		{name: "unknown identifiers are not an error", expression: `missing.field`, want: `missing.field`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatExpression(tt.expression)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatChecks(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Check("input.a==1"),
+		s.Sequence(
+			s.Check(`input.b`),
+			s.OnFail(step.Route, s.Check("input.c =="), s.Outcome("denied")).Action("approval", testAction{}),
+		),
+		s.Outcome("approved"),
+	)
+
+	got := FormatChecks(p)
+	for i := range got {
+		// errors are checked separately, as they contain positions from the parser.
+		if got[i].Err != nil {
+			assert.Equal(t, []int{2, 1, 0}, got[i].Position)
+			assert.False(t, got[i].Changed())
+			got[i].Err = nil
+		}
+	}
+
+	assert.Equal(t, []FormattedCheck{
+		{Pass: "default", Position: []int{1}, Expression: "input.a==1", Formatted: "input.a == 1"},
+		{Pass: "default", Position: []int{2, 0}, Expression: "input.b", Formatted: "input.b"},
+		{Pass: "default", Position: []int{2, 1, 0}, Expression: "input.c =="},
+	}, got)
+	assert.True(t, got[0].Changed())
+	assert.False(t, got[1].Changed())
+}
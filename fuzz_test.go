@@ -0,0 +1,84 @@
+package glide
+
+import (
+	"context"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/goccy/go-yaml"
+)
+
+var fuzzSeeds = []string{
+	`
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: input.approved
+      - outcome: approved
+`,
+	`
+workflow:
+  default:
+    steps:
+      - start: request
+      - or:
+          - and:
+              - check: "true"
+              - action: my_action
+                with:
+                  property: test
+          - check: "false"
+      - outcome: approved
+`,
+	`
+workflow:
+  default:
+    steps:
+      - start: request
+      - &check
+        check: input.approved
+      - *check
+      - outcome: approved
+`,
+	`workflow: {default: {steps: [{start: request}, {outcome: approved}]}}`,
+	`workflow:`,
+	`workflow: [`,
+	``,
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// errors are expected for invalid workflows, but
+		// Unmarshal must never panic.
+		_, _ = Unmarshal(data, yamlTestDialect)
+	})
+}
+
+func FuzzStep_UnmarshalYAML(f *testing.F) {
+	for _, s := range []string{
+		`- start: request`,
+		`- check: input.approved`,
+		`- action: my_action`,
+		`- {or: [{check: "true"}, {and: [{check: "false"}]}]}`,
+		`- {action: my_action, on_fail: {continue: [{outcome: approved}]}}`,
+		`- id: first
+  name: First
+  check: "true"`,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// input which fails the checks in Unmarshal
+		// never reaches Step.UnmarshalYAML.
+		if _, _, err := parseYAML(data); err != nil {
+			return
+		}
+		var steps []step.Step
+		ctx := Use(context.Background(), yamlTestDialect)
+		_ = yaml.UnmarshalContext(ctx, data, &steps)
+	})
+}
@@ -1,22 +1,104 @@
 package glide
 
 import (
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
 	"github.com/common-fate/glide/pkg/step"
 	"github.com/dominikbraun/graph"
 	"github.com/google/cel-go/cel"
 )
 
 type Graph struct {
-	// G is the underlying graph data structure.
+	// G is the underlying graph data structure, which can be used
+	// to render the graph. Glide accesses the graph through the store,
+	// so vertices and edges should not be added to G directly.
 	G graph.Graph[string, step.Step]
 
+	// Warnings are the non-fatal issues found when compiling the graph,
+	// such as checks which are always true.
+	Warnings []Diagnostic
+
+	// store holds the steps and edges of the graph.
+	store store
+
 	// programs is a map of graph vertex hashes to compiled CEL programs.
 	programs map[string]cel.Program
+
+	// lazyPrograms is true if the programs are constructed
+	// the first time they're evaluated.
+	lazyPrograms bool
+
+	// asts is a map of graph vertex hashes to type-checked CEL expressions.
+	asts map[string]*cel.Ast
+
+	// memoKeys maps the hashes of checks whose result only depends on the
+	// input to the key their results are memoized by during an execution.
+	memoKeys map[string]string
+
+	// provider is the type provider for the 'input' and 'steps' objects.
+	// It is used to convert the input into CEL values during execution.
+	provider *jsoncel.Provider
+
+	// dialect is the dialect the program was written in, if known.
+	dialect *dialect.Dialect
+
+	// version is the version of the program, available to checks
+	// as 'workflow.version'.
+	version string
+
+	// outputSteps are the IDs of the action steps which have outputs.
+	outputSteps []string
+
+	// stepKeys are the IDs and names which checks can reference steps by.
+	// The value is false if more than one step has the key.
+	stepKeys map[string]bool
+
+	// stepHashes maps the keys which steps can be referenced by to their hashes.
+	stepHashes map[string]string
+
+	// deps maps the hashes of steps to the hashes of the steps which must
+	// be visited before them during an execution: the steps whose state a check
+	// references, and the steps listed in a step's 'needs' field.
+	deps map[string][]string
+
+	// needs are the steps listed in the 'needs' field of steps.
+	needs []stepNeeds
+
+	// refs are the start and outcome references compiled into the graph.
+	refs []NodeRef
+
+	// preconditions maps the hashes of start nodes to the last
+	// of the preconditions compiled after them.
+	preconditions map[string]*step.Step
+
+	// outcomeStrategy selects the outcomes of an execution.
+	// If nil, HighestPriority is used.
+	outcomeStrategy OutcomeStrategy
+
+	// defaultOutcome is the outcome which is the result of an
+	// execution when no other outcome is reached, if any.
+	defaultOutcome *node.Node
+
+	// matrixCompiler is set if the program has matrix passes or for_each steps which
+	// are expanded over the values in the input. The graph contains them once, with
+	// placeholder values, and Execute compiles a graph for each input.
+	matrixCompiler *Compiler
+
+	// nodeAttributes are the rendering attributes of steps,
+	// keyed by their hash, set with SetNodeAttribute.
+	nodeAttributes map[string]map[string]string
 }
 
 func NewGraph() *Graph {
+	s := newLibraryStore()
 	return &Graph{
-		G:        graph.New(step.Hash, graph.Directed(), graph.PreventCycles()),
+		G:        s.g,
+		store:    s,
 		programs: map[string]cel.Program{},
+		asts:     map[string]*cel.Ast{},
+		memoKeys: map[string]string{},
+
+		preconditions: map[string]*step.Step{},
 	}
 }
@@ -0,0 +1,343 @@
+package glide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// diffColors are the colours used to shade steps and edges by how they changed.
+var diffColors = map[DiffKind]string{
+	DiffAdded:   "#00AA00",
+	DiffRemoved: "#DD0000",
+	DiffChanged: "#FF8C00",
+}
+
+// DiffKind is how a step or edge changed between two graphs.
+type DiffKind int
+
+const (
+	DiffUnchanged DiffKind = iota
+	DiffAdded
+	DiffRemoved
+	DiffChanged
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	}
+	return "unchanged"
+}
+
+// GraphDiff is the structural difference between two versions of a workflow,
+// matching steps by their hash and edges by their source and target.
+type GraphDiff struct {
+	// Steps maps the hash of each step in either graph to how it changed.
+	// Steps are changed if their body, name, description or
+	// other properties are different.
+	Steps map[string]DiffKind
+
+	// Edges maps each edge in either graph, as a [source, target] pair,
+	// to how it changed. Edges are changed if their label or style is different.
+	Edges map[[2]string]DiffKind
+
+	old, new *Graph
+}
+
+// Changed returns true if any step or edge is different.
+func (d *GraphDiff) Changed() bool {
+	for _, k := range d.Steps {
+		if k != DiffUnchanged {
+			return true
+		}
+	}
+	for _, k := range d.Edges {
+		if k != DiffUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffGraphs compares two versions of a workflow, such as the workflow
+// before and after a policy change, so that the structural effect of the
+// change can be rendered with RenderDiff or RenderDiffMermaid.
+func DiffGraphs(before, after *Compiled) (*GraphDiff, error) {
+	d := GraphDiff{
+		Steps: map[string]DiffKind{},
+		Edges: map[[2]string]DiffKind{},
+		old:   before.g,
+		new:   after.g,
+	}
+
+	oldSteps, err := diffSteps(before.g)
+	if err != nil {
+		return nil, err
+	}
+	newSteps, err := diffSteps(after.g)
+	if err != nil {
+		return nil, err
+	}
+	for k, content := range newSteps {
+		prev, ok := oldSteps[k]
+		switch {
+		case !ok:
+			d.Steps[k] = DiffAdded
+		case prev != content:
+			d.Steps[k] = DiffChanged
+		default:
+			d.Steps[k] = DiffUnchanged
+		}
+	}
+	for k := range oldSteps {
+		if _, ok := newSteps[k]; !ok {
+			d.Steps[k] = DiffRemoved
+		}
+	}
+
+	oldEdges, err := diffEdges(before.g)
+	if err != nil {
+		return nil, err
+	}
+	newEdges, err := diffEdges(after.g)
+	if err != nil {
+		return nil, err
+	}
+	for k, e := range newEdges {
+		prev, ok := oldEdges[k]
+		switch {
+		case !ok:
+			d.Edges[k] = DiffAdded
+		case prev.Attributes["label"] != e.Attributes["label"] || prev.Attributes["style"] != e.Attributes["style"]:
+			d.Edges[k] = DiffChanged
+		default:
+			d.Edges[k] = DiffUnchanged
+		}
+	}
+	for k := range oldEdges {
+		if _, ok := newEdges[k]; !ok {
+			d.Edges[k] = DiffRemoved
+		}
+	}
+
+	return &d, nil
+}
+
+// diffSteps returns a representation of each step in the graph,
+// keyed by its hash, which is different if the step has changed.
+func diffSteps(g *Graph) (map[string]string, error) {
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	for _, k := range hashes {
+		s, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = diffContent(s)
+	}
+	return out, nil
+}
+
+// diffContent returns everything about a step which affects
+// how it's executed and displayed.
+func diffContent(s step.Step) string {
+	content := fmt.Sprint(s.Body)
+	switch t := s.Body.(type) {
+	case step.Action:
+		props, _ := json.Marshal(t.Action)
+		content += "\nwith: " + string(props)
+	case step.Ref:
+		content += fmt.Sprintf("\npriority: %d", t.Node.Priority)
+	}
+	return content + "\n" + stepAttributes(s)
+}
+
+func diffEdges(g *Graph) (map[[2]string]edge, error) {
+	edges, err := g.store.edges()
+	if err != nil {
+		return nil, err
+	}
+	out := map[[2]string]edge{}
+	for _, e := range edges {
+		out[[2]string{e.Source, e.Target}] = e
+	}
+	return out, nil
+}
+
+// step returns a step from the new graph, or from the old graph if it was removed.
+func (d *GraphDiff) step(k string) (step.Step, error) {
+	if d.Steps[k] == DiffRemoved {
+		return d.old.store.step(k)
+	}
+	return d.new.store.step(k)
+}
+
+// edgeAttributes returns the attributes of an edge in the new graph,
+// or in the old graph if it was removed.
+func (d *GraphDiff) edgeAttributes(k [2]string) (map[string]string, error) {
+	g := d.new
+	if d.Edges[k] == DiffRemoved {
+		g = d.old
+	}
+	edges, err := g.store.predecessors(k[1])
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range edges {
+		if e.Source == k[0] {
+			return e.Attributes, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *GraphDiff) hashes() []string {
+	hashes := make([]string, 0, len(d.Steps))
+	for k := range d.Steps {
+		hashes = append(hashes, k)
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+func (d *GraphDiff) edges() [][2]string {
+	edges := make([][2]string, 0, len(d.Edges))
+	for k := range d.Edges {
+		edges = append(edges, k)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	return edges
+}
+
+// RenderDiff writes the steps and edges of both versions of the workflow
+// in DOT format, with added steps and edges in green, removed steps and
+// edges in red and dashed, and changed steps in orange. Changed steps
+// have the old version of the step as their tooltip.
+func (d *GraphDiff) RenderDiff(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("strict digraph {\n")
+
+	for _, k := range d.hashes() {
+		s, err := d.step(k)
+		if err != nil {
+			return err
+		}
+
+		attrs := map[string]string{"label": s.Debug()}
+		kind := d.Steps[k]
+		if color, ok := diffColors[kind]; ok {
+			attrs["color"] = color
+			attrs["penwidth"] = "2"
+		}
+		switch kind {
+		case DiffRemoved:
+			attrs["style"] = "dashed"
+			attrs["fontcolor"] = diffColors[DiffRemoved]
+		case DiffChanged:
+			prev, err := d.old.store.step(k)
+			if err != nil {
+				return err
+			}
+			attrs["tooltip"] = dotEscape("was: " + prev.Debug())
+		}
+		fmt.Fprintf(&b, "\t\"%s\" [ %s ];\n", dotEscape(k), dotAttributes(attrs))
+	}
+
+	for _, k := range d.edges() {
+		e, err := d.edgeAttributes(k)
+		if err != nil {
+			return err
+		}
+		attrs := copyAttributes(e)
+		if attrs == nil {
+			attrs = map[string]string{}
+		}
+		kind := d.Edges[k]
+		if color, ok := diffColors[kind]; ok {
+			attrs["color"] = color
+			attrs["penwidth"] = "2"
+		}
+		if kind == DiffRemoved {
+			attrs["style"] = "dashed"
+		}
+		fmt.Fprintf(&b, "\t\"%s\" -> \"%s\" [ %s ];\n", dotEscape(k[0]), dotEscape(k[1]), dotAttributes(attrs))
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderDiffMermaid writes the steps and edges of both versions of the
+// workflow as a Mermaid flowchart, coloured like RenderDiff.
+func (d *GraphDiff) RenderDiffMermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	// hashes can contain characters which aren't valid in
+	// Mermaid node IDs, so steps are numbered instead.
+	ids := map[string]string{}
+
+	for i, k := range d.hashes() {
+		s, err := d.step(k)
+		if err != nil {
+			return err
+		}
+		id := fmt.Sprintf("s%d", i)
+		ids[k] = id
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, mermaidEscape(s.Debug()))
+
+		kind := d.Steps[k]
+		if color, ok := diffColors[kind]; ok {
+			style := fmt.Sprintf("stroke:%s,stroke-width:2px", color)
+			if kind == DiffRemoved {
+				style += ",stroke-dasharray:5 5"
+			}
+			fmt.Fprintf(&b, "    style %s %s\n", id, style)
+		}
+	}
+
+	// links are styled by their index, in the order they're written.
+	for i, k := range d.edges() {
+		e, err := d.edgeAttributes(k)
+		if err != nil {
+			return err
+		}
+		kind := d.Edges[k]
+
+		arrow := "-->"
+		if e["style"] == "dashed" || kind == DiffRemoved {
+			arrow = "-.->"
+		}
+		if label := e["label"]; label != "" {
+			arrow += "|" + mermaidEscape(label) + "|"
+		}
+		fmt.Fprintf(&b, "    %s %s %s\n", ids[k[0]], arrow, ids[k[1]])
+
+		if color, ok := diffColors[kind]; ok {
+			fmt.Fprintf(&b, "    linkStyle %d stroke:%s,stroke-width:2px\n", i, color)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
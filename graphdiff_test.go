@@ -0,0 +1,103 @@
+package glide
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffGraphs(t *testing.T) {
+	before, err := Build(SimpleProgram(
+		s.Start("request"),
+		s.Check("true"),
+		s.Check("1 == 1"),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := Build(SimpleProgram(
+		s.Start("request"),
+		s.Check("false"),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := DiffGraphs(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, d.Changed())
+	assert.Equal(t, map[string]DiffKind{
+		"request":   DiffUnchanged,
+		"default.1": DiffChanged,
+		"default.2": DiffRemoved,
+		"approved":  DiffUnchanged,
+	}, d.Steps)
+	assert.Equal(t, map[[2]string]DiffKind{
+		{"request", "default.1"}:   DiffUnchanged,
+		{"default.1", "default.2"}: DiffRemoved,
+		{"default.2", "approved"}:  DiffRemoved,
+		{"default.1", "approved"}:  DiffAdded,
+	}, d.Edges)
+
+	var dot bytes.Buffer
+	err = d.RenderDiff(&dot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, dot.String(), `"default.1" [ color="#FF8C00", label="[default.1] if: false", penwidth="2", tooltip="was: [default.1] if: true", weight=0 ];`)
+	assert.Contains(t, dot.String(), `"default.2" [ color="#DD0000", fontcolor="#DD0000", label="[default.2] if: 1 == 1", penwidth="2", style="dashed", weight=0 ];`)
+	assert.Contains(t, dot.String(), `"default.1" -> "approved" [ color="#00AA00", penwidth="2", weight=0 ];`)
+	assert.Contains(t, dot.String(), `"request" -> "default.1" [ weight=0 ];`)
+
+	var mermaid bytes.Buffer
+	err = d.RenderDiffMermaid(&mermaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, mermaid.String(), "style s1 stroke:#FF8C00,stroke-width:2px\n")
+	assert.Contains(t, mermaid.String(), "style s2 stroke:#DD0000,stroke-width:2px,stroke-dasharray:5 5\n")
+	assert.Contains(t, mermaid.String(), "s1 -.-> s2\n    linkStyle 1 stroke:#DD0000,stroke-width:2px\n")
+
+	same, err := DiffGraphs(after, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, same.Changed())
+}
+
+func TestDiffGraphs_Names(t *testing.T) {
+	build := func(name string) *Compiled {
+		p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - name: On call
+        names:
+          fr: `+name+`
+        check: "true"
+      - outcome: approved
+`), yamlTestDialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := (&Compiler{Program: p}).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+
+	d, err := DiffGraphs(build("De garde"), build("En astreinte"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, DiffChanged, d.Steps["default.1"])
+}
@@ -0,0 +1,133 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuard_Execute(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: approval
+        action: my_action
+        if: input.duration > 8
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"duration": {Type: jsoncel.Integer},
+		},
+	}
+	g, err := (&Compiler{Program: p, InputSchema: schema}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name              string
+		input             map[string]any
+		opts              []ExecuteOption
+		wantState         State
+		wantUnknownFields []string
+	}{
+		{
+			name:      "guard is true",
+			input:     map[string]any{"duration": 12},
+			wantState: Active,
+		},
+		{
+			name:      "guard is false",
+			input:     map[string]any{"duration": 1},
+			wantState: Inactive,
+		},
+		{
+			name:              "guard is unknown",
+			input:             map[string]any{},
+			opts:              []ExecuteOption{WithPartialInput()},
+			wantState:         Unknown,
+			wantUnknownFields: []string{"duration"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Execute("request", tt.input, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantState, got.State["default.approval"])
+			assert.Equal(t, tt.wantUnknownFields, got.UnknownFields)
+		})
+	}
+}
+
+func TestGuard_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr string
+	}{
+		{
+			name: "on a check",
+			give: `
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: "true"
+        if: "true"
+      - outcome: approved
+`,
+			wantErr: "if can only be used on action steps",
+		},
+		{
+			name: "with escalate",
+			give: `
+workflow:
+  default:
+    steps:
+      - start: request
+      - action: my_action
+        if: "true"
+        escalate:
+          after: 1d
+          steps:
+            - action: my_action
+      - outcome: approved
+`,
+			wantErr: "if can't be used on action steps which escalate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(tt.give), yamlTestDialect)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestGuard_NotBoolean(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - action: my_action
+        if: "1 + 1"
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = (&Compiler{Program: p}).Compile()
+	assert.ErrorContains(t, err, "invalid if: CEL expression must return a boolean")
+}
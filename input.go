@@ -0,0 +1,95 @@
+package glide
+
+import (
+	"reflect"
+	"strings"
+)
+
+// cloneInput returns a deep copy of the maps and slices in an input,
+// so that actions can't modify data owned by the caller of Execute.
+// Other values, like pointers and structs, are copied by value.
+func cloneInput(input map[string]any) map[string]any {
+	if input == nil {
+		return nil
+	}
+	out := make(map[string]any, len(input))
+	for k, v := range input {
+		out[k] = cloneValue(v)
+	}
+	return out
+}
+
+func cloneValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		return cloneInput(t)
+	case []any:
+		if t == nil {
+			return t
+		}
+		out := make([]any, len(t))
+		for i, el := range t {
+			out[i] = cloneValue(el)
+		}
+		return out
+	}
+
+	// other map and slice types, such as []string, are copied with reflection.
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), cloneReflect(iter.Value(), rv.Type().Elem()))
+		}
+		return out.Interface()
+	case reflect.Slice:
+		if rv.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			out.Index(i).Set(cloneReflect(rv.Index(i), rv.Type().Elem()))
+		}
+		return out.Interface()
+	}
+	return v
+}
+
+// cloneReflect clones a map or slice element, converting
+// the clone back to the element type of the container.
+func cloneReflect(v reflect.Value, elem reflect.Type) reflect.Value {
+	if !v.CanInterface() {
+		return v
+	}
+	c := cloneValue(v.Interface())
+	if c == nil {
+		return reflect.Zero(elem)
+	}
+	return reflect.ValueOf(c).Convert(elem)
+}
+
+// Lookup returns the value at a dot-separated path in an input, e.g. 'group.id'.
+// The second return value is false if the path doesn't exist.
+//
+// Actions should read their input with Lookup rather than by modifying it:
+// each execution gives its actions a copy of the input, so reading it is
+// safe even when a workflow is executed concurrently.
+func Lookup(input any, path string) (any, bool) {
+	cur := input
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
@@ -0,0 +1,87 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneInput(t *testing.T) {
+	input := map[string]any{
+		"name":   "bob",
+		"groups": []string{"admins"},
+		"nested": map[string]any{"list": []any{map[string]any{"id": "a"}}},
+		"typed":  map[string]int{"a": 1},
+	}
+
+	got := cloneInput(input)
+	assert.Equal(t, input, got)
+
+	got["name"] = "alice"
+	got["groups"].([]string)[0] = "ops"
+	got["nested"].(map[string]any)["list"].([]any)[0].(map[string]any)["id"] = "b"
+	got["typed"].(map[string]int)["a"] = 2
+
+	assert.Equal(t, map[string]any{
+		"name":   "bob",
+		"groups": []string{"admins"},
+		"nested": map[string]any{"list": []any{map[string]any{"id": "a"}}},
+		"typed":  map[string]int{"a": 1},
+	}, input)
+}
+
+func TestLookup(t *testing.T) {
+	input := map[string]any{"group": map[string]any{"id": "admins"}}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   any
+		wantOK bool
+	}{
+		{name: "nested", path: "group.id", want: "admins", wantOK: true},
+		{name: "map", path: "group", want: map[string]any{"id": "admins"}, wantOK: true},
+		{name: "missing", path: "group.name"},
+		{name: "not a map", path: "group.id.value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Lookup(input, tt.path)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExecute_InputNotModified(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Action("mutate", &testMutatingAction{}),
+			s.Check("!input.approved && input.groups[0] == 'admins'"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+		InputSchema: &jsoncel.Schema{
+			Properties: map[string]*jsoncel.Schema{
+				"approved": {Type: jsoncel.Boolean},
+				"groups":   {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+			},
+		},
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := map[string]any{"approved": false, "groups": []string{"admins"}}
+	got, err := g.Execute("request", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the check sees the original input, as does the caller.
+	assert.Equal(t, "approved", got.Outcome)
+	assert.Equal(t, map[string]any{"approved": false, "groups": []string{"admins"}}, input)
+}
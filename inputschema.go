@@ -0,0 +1,42 @@
+package glide
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+)
+
+// dialectInputSchema merges the input schema with the fields that the
+// dialect declares in its InputSchema. It returns an error if the input
+// schema declares one of the fields with a different type.
+func dialectInputSchema(s *jsoncel.Schema, d *dialect.Dialect) (*jsoncel.Schema, error) {
+	if d == nil || d.InputSchema == nil {
+		return s, nil
+	}
+	if s == nil {
+		s = &jsoncel.Schema{Type: jsoncel.Object}
+	}
+
+	merged, err := jsoncel.Merge(s, d.InputSchema)
+	var conflict *jsoncel.MergeConflict
+	if errors.As(err, &conflict) {
+		field := "input"
+		if conflict.Path != "" {
+			field = "input." + conflict.Path
+		}
+		return nil, fmt.Errorf("%s is declared as %s in the input schema, but the dialect expects %s", field, typeName(conflict.Base), typeName(conflict.Overlay))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func typeName(t jsoncel.FieldType) string {
+	if t == "" {
+		return "any"
+	}
+	return string(t)
+}
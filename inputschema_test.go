@@ -0,0 +1,146 @@
+package glide
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_DialectInputSchema(t *testing.T) {
+	d := yamlTestDialect
+	d.InputSchema = &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"approvals": {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+		},
+	}
+
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: size(input.approvals) > 0 && input.duration < 8
+      - outcome: approved
+`), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		schema  *jsoncel.Schema
+		wantErr string
+	}{
+		{
+			name: "merged with the input schema",
+			schema: &jsoncel.Schema{
+				Type: jsoncel.Object,
+				Properties: map[string]*jsoncel.Schema{
+					"duration": {Type: jsoncel.Integer},
+				},
+			},
+		},
+		{
+			name: "declared with the same type",
+			schema: &jsoncel.Schema{
+				Type: jsoncel.Object,
+				Properties: map[string]*jsoncel.Schema{
+					"duration":  {Type: jsoncel.Integer},
+					"approvals": {Type: jsoncel.Array},
+				},
+			},
+		},
+		{
+			name: "conflict",
+			schema: &jsoncel.Schema{
+				Type: jsoncel.Object,
+				Properties: map[string]*jsoncel.Schema{
+					"duration":  {Type: jsoncel.Integer},
+					"approvals": {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.Integer}},
+				},
+			},
+			wantErr: "input.approvals[] is declared as integer in the input schema, but the dialect expects string",
+		},
+		{
+			name:    "no input schema",
+			wantErr: "undefined field 'duration'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := (&Compiler{Program: p, InputSchema: tt.schema}).Compile()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := g.Execute("request", map[string]any{
+				"approvals": []any{"alice"},
+				"duration":  2,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []string{"approved"}, res.Outcomes)
+		})
+	}
+}
+
+func TestCompile_InputSchemaRefsAndNulls(t *testing.T) {
+	var schema jsoncel.Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"$defs": {
+			"group": {"type": "object", "properties": {"id": {"type": "string"}}}
+		},
+		"properties": {
+			"group": {"$ref": "#/$defs/group"},
+			"ticket": {"type": ["string", "null"]}
+		}
+	}`), &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Check(`input.group.id == "admins" && input.ticket != null`),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)
+	g, err := (&Compiler{Program: p, InputSchema: &schema}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := g.Execute("request", map[string]any{"group": map[string]any{"id": "admins"}, "ticket": "T-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+
+	res, err = g.Execute("request", map[string]any{"group": map[string]any{"id": "admins"}, "ticket": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", res.Outcome)
+
+	// checks are type-checked against the schema the reference refers to.
+	p = SimpleProgram(
+		s.Start("request"),
+		s.Check(`input.group.name == "admins"`),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)
+	_, err = (&Compiler{Program: p, InputSchema: &schema}).Compile()
+	assert.Error(t, err)
+
+	schema.Properties["group"].Ref = "#/$defs/team"
+	_, err = (&Compiler{Program: p, InputSchema: &schema}).Compile()
+	assert.EqualError(t, err, `input schema: group: $ref "#/$defs/team" refers to team, which isn't in $defs`)
+}
@@ -0,0 +1,63 @@
+package glide
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// lazyProgram is a CEL program which is constructed the first time it's evaluated,
+// for graphs compiled with LazyPrograms. It's safe to evaluate from multiple
+// goroutines, so that lazily compiled workflows can still be shared.
+type lazyProgram struct {
+	env *cel.Env
+	ast *cel.Ast
+
+	once sync.Once
+	prg  cel.Program
+	err  error
+}
+
+func newLazyProgram(env *cel.Env, ast *cel.Ast) *lazyProgram {
+	return &lazyProgram{env: env, ast: ast}
+}
+
+// program constructs the program if it hasn't been already.
+func (p *lazyProgram) program() (cel.Program, error) {
+	p.once.Do(func() {
+		p.prg, p.err = newCheckProgram(p.env, p.ast)
+	})
+	return p.prg, p.err
+}
+
+func (p *lazyProgram) Eval(vars any) (ref.Val, *cel.EvalDetails, error) {
+	prg, err := p.program()
+	if err != nil {
+		return nil, nil, err
+	}
+	return prg.Eval(vars)
+}
+
+func (p *lazyProgram) ContextEval(ctx context.Context, vars any) (ref.Val, *cel.EvalDetails, error) {
+	prg, err := p.program()
+	if err != nil {
+		return nil, nil, err
+	}
+	return prg.ContextEval(ctx, vars)
+}
+
+// newCheckProgram constructs the CEL program for a type-checked check expression.
+//
+// Partial evaluation is enabled so that checks can be evaluated
+// against inputs with unknown fields. It doesn't change
+// evaluation behaviour for complete inputs.
+func newCheckProgram(env *cel.Env, ast *cel.Ast) (cel.Program, error) {
+	prg, err := env.Program(ast, cel.EvalOptions(cel.OptPartialEval))
+	if err != nil {
+		return nil, fmt.Errorf("CEL program construction error: %s", err)
+	}
+	return prg, nil
+}
@@ -0,0 +1,96 @@
+package glide
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_LazyPrograms(t *testing.T) {
+	p := NewProgram().
+		Pass("admins",
+			s.Start("request"),
+			s.Check(`input.group == "admins"`),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		).
+		Pass("unreached",
+			s.Start("other"),
+			s.Check(`input.group == "developers"`),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		)
+	schema := &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{"group": {Type: jsoncel.String}},
+	}
+
+	c := Compiler{Program: p, InputSchema: schema, LazyPrograms: true}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admins := g.programs["admins.1"].(*lazyProgram)
+	unreached := g.programs["unreached.1"].(*lazyProgram)
+	assert.Nil(t, admins.prg)
+
+	// executions share the lazily constructed programs.
+	var wg sync.WaitGroup
+	results := make([]*Result, 8)
+	errs := make([]error, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = g.Freeze().Execute("request", map[string]any{"group": "admins"})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		assert.Equal(t, "approved", results[i].Outcome)
+		assert.Equal(t, Complete, results[i].State["admins.1"])
+	}
+	assert.NotNil(t, admins.prg)
+	assert.Nil(t, unreached.prg)
+}
+
+func TestCompile_LazyProgramsMatchEager(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Boolean(step.Or, s.Check(`input.group == "admins"`), s.Check(`input.group.startsWith("dev")`)),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)
+	schema := &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{"group": {Type: jsoncel.String}},
+	}
+
+	eager, err := (&Compiler{Program: p, InputSchema: schema}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lazy, err := (&Compiler{Program: p, InputSchema: schema, LazyPrograms: true}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, group := range []string{"admins", "developers", "finance"} {
+		want, err := eager.Execute("request", map[string]any{"group": group})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := lazy.Execute("request", map[string]any{"group": group})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, want.State, got.State, group)
+		assert.Equal(t, want.Outcome, got.Outcome, group)
+	}
+}
@@ -0,0 +1,223 @@
+package glide
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// Limits on the structure of workflow definitions, which protect
+// against untrusted YAML that is expensive to decode.
+const (
+	// maxYAMLDepth is the maximum nesting depth of YAML nodes.
+	maxYAMLDepth = 100
+	// maxYAMLNodes is the maximum number of YAML nodes in a workflow,
+	// counting the nodes of an anchor each time it is referenced by an alias.
+	maxYAMLNodes = 100000
+)
+
+// Limits on the expansion of matrix passes and for_each steps, which protect
+// against input lists that are expensive to compile, as each combination of
+// values is compiled into its own steps when the workflow is executed.
+const (
+	// maxMatrixPasses is the maximum number of passes a matrix pass expands into.
+	maxMatrixPasses = 100
+	// maxForEachBranches is the maximum number of branches the for_each steps of
+	// a pass expand into, counting the branches of nested for_each steps.
+	maxForEachBranches = 100
+)
+
+// parseYAML parses a workflow definition and checks it against the limits.
+// anchors is true if the definition contains aliases or merge keys.
+//
+// The YAML parser can panic on some malformed input,
+// so panics while parsing are returned as errors.
+func parseYAML(data []byte) (f *ast.File, anchors bool, err error) {
+	// the time taken to parse YAML grows much faster than the nesting
+	// depth, so reject deeply nested input before parsing it.
+	if yamlNesting(data) > 2*maxYAMLDepth {
+		return nil, false, fmt.Errorf("YAML is nested more than %d levels deep", maxYAMLDepth)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid YAML: %v", r)
+		}
+	}()
+
+	f, err = parser.ParseBytes(data, 0)
+	if err != nil {
+		return nil, false, err
+	}
+
+	l := yamlLimits{anchors: map[string]int{}}
+	for _, doc := range f.Docs {
+		err = l.count(doc, 0)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return f, l.aliases, nil
+}
+
+// yamlLimits counts the nodes in a YAML document, expanding aliases.
+type yamlLimits struct {
+	// anchors maps anchor names to the number of nodes in the anchored value.
+	anchors map[string]int
+	total   int
+	// aliases is true if the document contains aliases or merge keys.
+	aliases bool
+}
+
+func (l *yamlLimits) count(n ast.Node, depth int) error {
+	if n == nil {
+		return nil
+	}
+	if depth > maxYAMLDepth {
+		return fmt.Errorf("%s: YAML is nested more than %d levels deep", n.GetPath(), maxYAMLDepth)
+	}
+
+	before := l.total
+	l.total++
+	if l.total > maxYAMLNodes {
+		return fmt.Errorf("%s: YAML contains more than %d nodes after expanding aliases", n.GetPath(), maxYAMLNodes)
+	}
+
+	var children []ast.Node
+
+	switch n := n.(type) {
+	case *ast.DocumentNode:
+		children = []ast.Node{n.Body}
+	case *ast.MappingNode:
+		for _, v := range n.Values {
+			children = append(children, v)
+		}
+	case *ast.MappingValueNode:
+		if n.Key == nil || n.Value == nil {
+			return fmt.Errorf("%s: invalid mapping", n.GetPath())
+		}
+		if _, ok := n.Key.(*ast.MergeKeyNode); ok {
+			l.aliases = true
+		}
+		children = []ast.Node{n.Key, n.Value}
+	case *ast.MappingKeyNode:
+		if n.Value == nil {
+			return fmt.Errorf("%s: invalid mapping key", n.GetPath())
+		}
+		children = []ast.Node{n.Value}
+	case *ast.SequenceNode:
+		children = n.Values
+		// the parser can produce sequences with missing values
+		// for malformed input, which cause the decoder to panic.
+		for _, v := range n.Values {
+			if v == nil {
+				return fmt.Errorf("%s: invalid sequence", n.GetPath())
+			}
+		}
+	case *ast.TagNode:
+		if n.Value == nil {
+			return fmt.Errorf("%s: invalid tag", n.GetPath())
+		}
+		children = []ast.Node{n.Value}
+	case *ast.AnchorNode:
+		if n.Name == nil || n.Value == nil {
+			return fmt.Errorf("%s: invalid anchor", n.GetPath())
+		}
+		err := l.count(n.Value, depth+1)
+		if err != nil {
+			return err
+		}
+		l.anchors[n.Name.String()] = l.total - before
+		return nil
+	case *ast.AliasNode:
+		l.aliases = true
+
+		// the decoder panics on aliases to undefined anchors.
+		if n.Value == nil {
+			return fmt.Errorf("%s: alias must have a name", n.GetPath())
+		}
+		size, ok := l.anchors[n.Value.String()]
+		if !ok {
+			return fmt.Errorf("%s: alias %s refers to an undefined anchor", n.GetPath(), n.Value.String())
+		}
+
+		// aliases are decoded by decoding the anchored value again.
+		l.total += size
+		if l.total > maxYAMLNodes {
+			return fmt.Errorf("%s: YAML contains more than %d nodes after expanding aliases", n.GetPath(), maxYAMLNodes)
+		}
+		return nil
+	}
+
+	for _, c := range children {
+		err := l.count(c, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yamlNesting estimates the maximum nesting depth of YAML without parsing it.
+// It is an upper bound for well-formed YAML: each line is nested
+// within the less indented lines before it, and within any open
+// flow collections, and may open further collections itself with
+// sequence entries, mapping values and flow collections.
+func yamlNesting(data []byte) int {
+	var (
+		indents []int // indentation of the enclosing lines
+		flow    int   // depth of open flow collections
+		max     int
+	)
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		indent := len(line) - len(bytes.TrimLeft(line, " "))
+		content := bytes.TrimSpace(line)
+		if len(content) == 0 || content[0] == '#' {
+			continue
+		}
+
+		for len(indents) > 0 && indents[len(indents)-1] >= indent {
+			indents = indents[:len(indents)-1]
+		}
+		indents = append(indents, indent)
+
+		var inline int
+		var quote byte
+		for i, c := range content {
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			switch c {
+			case '\'', '"':
+				quote = c
+			case '[', '{':
+				flow++
+				inline++
+			case ']', '}':
+				if flow > 0 {
+					flow--
+				}
+			case '-', ':':
+				if i+1 == len(content) || content[i+1] == ' ' {
+					inline++
+				}
+			}
+			if c == '#' && i > 0 && content[i-1] == ' ' {
+				break
+			}
+		}
+
+		depth := len(indents) + flow + inline
+		if depth > max {
+			max = depth
+		}
+	}
+
+	return max
+}
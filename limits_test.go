@@ -0,0 +1,173 @@
+package glide
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseYAML(t *testing.T) {
+	// laughs is a 'billion laughs' document, where
+	// each anchor is referenced ten times by the next.
+	laughs := "a0: &a0 [lol]\n"
+	for i := 1; i < 10; i++ {
+		laughs += "a" + string(rune('0'+i)) + ": &a" + string(rune('0'+i)) + " ["
+		laughs += strings.TrimSuffix(strings.Repeat("*a"+string(rune('0'+i-1))+",", 10), ",") + "]\n"
+	}
+
+	tests := []struct {
+		name    string
+		give    string
+		wantErr string
+	}{
+		{
+			name: "ok",
+			give: `
+workflow:
+  default:
+    steps:
+      - start: request
+      - &check
+        check: input.approved
+      - *check
+      - outcome: approved
+`,
+		},
+		{
+			name:    "parser panic",
+			give:    "000000000: :",
+			wantErr: "invalid YAML",
+		},
+		{
+			name:    "deeply nested flow",
+			give:    "workflow: " + strings.Repeat("[", 100000),
+			wantErr: "YAML is nested more than 100 levels deep",
+		},
+		{
+			name:    "deeply nested block",
+			give:    "workflow: " + strings.Repeat("a: ", 100000),
+			wantErr: "YAML is nested more than 100 levels deep",
+		},
+		{
+			name:    "nested",
+			give:    "workflow: " + strings.Repeat("[", 150) + strings.Repeat("]", 150),
+			wantErr: "YAML is nested more than 100 levels deep",
+		},
+		{
+			name:    "billion laughs",
+			give:    laughs,
+			wantErr: "YAML contains more than 100000 nodes after expanding aliases",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			_, _, err := parseYAML([]byte(tt.give))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseYAML() error = %v, want %q", err, tt.wantErr)
+			}
+			if d := time.Since(start); d > 5*time.Second {
+				t.Fatalf("parseYAML() took %s", d)
+			}
+		})
+	}
+}
+
+func TestExpansionLimits(t *testing.T) {
+	matrix := `
+workflow:
+  default:
+    matrix:
+      group: input.groups
+      user: input.users
+    steps:
+      - start: request
+      - check: matrix.group != matrix.user
+      - outcome: approved
+`
+	forEach := `
+workflow:
+  default:
+    steps:
+      - start: request
+      - for_each: input.groups
+        as: group
+        steps:
+          - for_each: input.users
+            as: user
+            steps:
+              - check: each.group != each.user
+      - outcome: approved
+`
+	list := func(prefix string, n int) []any {
+		var out []any
+		for i := 0; i < n; i++ {
+			out = append(out, fmt.Sprintf("%s-%d", prefix, i))
+		}
+		return out
+	}
+	strings := &jsoncel.Schema{Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}}
+	schema := &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{"groups": strings, "users": strings},
+	}
+
+	tests := []struct {
+		name     string
+		workflow string
+		size     int
+		wantErr  string
+	}{
+		{
+			name:     "matrix within the limit",
+			workflow: matrix,
+			size:     10,
+		},
+		{
+			name:     "matrix over the limit",
+			workflow: matrix,
+			size:     11,
+			wantErr:  "pass default: matrix expands into more than 100 passes",
+		},
+		{
+			name:     "for_each within the limit",
+			workflow: forEach,
+			size:     9,
+		},
+		{
+			name:     "nested for_each over the limit",
+			workflow: forEach,
+			size:     10,
+			wantErr:  "for_each steps in pass default expand into more than 100 branches",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Unmarshal([]byte(tt.workflow), yamlTestDialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := Compiler{Program: p, InputSchema: schema}
+			g, err := c.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			input := map[string]any{"groups": list("group", tt.size), "users": list("user", tt.size)}
+			_, err = g.Execute("request", input)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package glide
+
+import (
+	"github.com/common-fate/glide/pkg/node"
+)
+
+// WithLocale localizes the display strings of the result, so that they
+// can be shown to users in their language. The names of the outcome nodes,
+// and the labels of the reasons and pending actions, are translated with the
+// 'names' of the nodes in the dialect and of the steps in the workflow.
+// Strings without a translation for the locale are left as they are.
+//
+// The locale is recorded in the Result, so that rendering the result with
+// ExportJSON labels the steps in the same language.
+func WithLocale(locale string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.locale = locale
+	}
+}
+
+// localize translates the display strings of the result into its locale.
+func (g *Graph) localize(res *Result) error {
+	if res == nil || res.Locale == "" {
+		return nil
+	}
+	if res.graph != nil {
+		g = res.graph
+	}
+
+	if res.OutcomeNode != nil {
+		n := localizeNode(*res.OutcomeNode, res.Locale)
+		res.OutcomeNode = &n
+	}
+	if len(res.ReachedOutcomes) > 0 {
+		reached := make([]node.Node, len(res.ReachedOutcomes))
+		for i, n := range res.ReachedOutcomes {
+			reached[i] = localizeNode(n, res.Locale)
+		}
+		res.ReachedOutcomes = reached
+	}
+
+	for i, r := range res.Reasons {
+		s, err := g.store.step(r.Step)
+		if err != nil {
+			return err
+		}
+		res.Reasons[i].Label = node.Localize(s.Names, res.Locale, r.Label)
+	}
+	for i, p := range res.Pending {
+		s, err := g.store.step(p.Step)
+		if err != nil {
+			return err
+		}
+		res.Pending[i].Label = node.Localize(s.Names, res.Locale, p.Label)
+	}
+	return nil
+}
+
+// localizeNode returns a copy of the node with its name in the locale.
+func localizeNode(n node.Node, locale string) node.Node {
+	n.Name = n.LocalizedName(locale)
+	return n
+}
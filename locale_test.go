@@ -0,0 +1,164 @@
+package glide
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/stretchr/testify/assert"
+)
+
+var localeDialect = dialect.Dialect{
+	Actions: func() map[string]any {
+		return map[string]any{
+			"my_action": &testAction{},
+		}
+	},
+	Nodes: map[string]node.Node{
+		"request":  {Type: node.Start, Name: "Request"},
+		"approved": {Type: node.Outcome, Priority: 1, Name: "Approved", Names: map[string]string{"fr": "Approuvé", "de": "Genehmigt"}},
+		"pending":  {Type: node.Outcome, Priority: 2, Name: "Pending"},
+	},
+}
+
+func TestWithLocale(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: on_call
+        name: On call
+        names:
+          fr: De garde
+        check: "true"
+      - outcome: approved
+  review:
+    steps:
+      - start: request
+      - id: review
+        name: Manager review
+        names:
+          fr-CA: Révision du gestionnaire
+        action: my_action
+      - outcome: pending
+`), localeDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := (&Compiler{Program: p}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		locale      string
+		wantOutcome string
+		wantReason  string
+		wantPending string
+	}{
+		{locale: "", wantOutcome: "Approved", wantReason: "On call", wantPending: "Manager review"},
+		{locale: "fr", wantOutcome: "Approuvé", wantReason: "De garde", wantPending: "Manager review"},
+		{locale: "fr_CA", wantOutcome: "Approuvé", wantReason: "De garde", wantPending: "Révision du gestionnaire"},
+		{locale: "de-AT", wantOutcome: "Genehmigt", wantReason: "On call", wantPending: "Manager review"},
+		{locale: "ja", wantOutcome: "Approved", wantReason: "On call", wantPending: "Manager review"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			var opts []ExecuteOption
+			if tt.locale != "" {
+				opts = append(opts, WithLocale(tt.locale))
+			}
+			got, err := g.Execute("request", nil, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.locale, got.Locale)
+			assert.Equal(t, "approved", got.Outcome)
+			assert.Equal(t, tt.wantOutcome, got.OutcomeNode.Name)
+			assert.Equal(t, tt.wantOutcome, got.ReachedOutcomes[0].Name)
+			if assert.Len(t, got.Reasons, 1) {
+				assert.Equal(t, tt.wantReason, got.Reasons[0].Label)
+			}
+
+			out, err := g.ExportJSON(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var export exportGraph
+			err = json.Unmarshal(out, &export)
+			if err != nil {
+				t.Fatal(err)
+			}
+			labels := map[string]string{}
+			for _, n := range export.Elements.Nodes {
+				labels[n.Data.ID] = n.Data.Label
+			}
+			assert.Equal(t, tt.wantOutcome, labels["approved"])
+			assert.Equal(t, tt.wantReason, labels["default.on_call"])
+			assert.Equal(t, tt.wantPending, labels["review.review"])
+		})
+	}
+}
+
+func TestWithLocale_Pending(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: review
+        name: Manager review
+        names:
+          fr-CA: Révision du gestionnaire
+        action: my_action
+      - outcome: approved
+`), localeDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := (&Compiler{Program: p}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.Execute("request", nil, WithLocale("fr-CA"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, got.Pending, 1) {
+		assert.Equal(t, "Révision du gestionnaire", got.Pending[0].Label)
+	}
+
+	got, err = g.Execute("request", nil, WithLocale("fr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, got.Pending, 1) {
+		assert.Equal(t, "Manager review", got.Pending[0].Label)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	names := map[string]string{"fr": "Approuvé", "pt-BR": "Aprovado", "en-gb": "Approved (UK)"}
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{locale: "", want: "Approved"},
+		{locale: "fr", want: "Approuvé"},
+		{locale: "FR-ca", want: "Approuvé"},
+		{locale: "pt", want: "Approved"},
+		{locale: "pt_BR", want: "Aprovado"},
+		{locale: "en-GB", want: "Approved (UK)"},
+		{locale: "en-US", want: "Approved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			assert.Equal(t, tt.want, node.Localize(names, tt.locale, "Approved"))
+		})
+	}
+}
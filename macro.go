@@ -0,0 +1,132 @@
+package glide
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/parser"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// dialectMacros converts the macros defined in a dialect into CEL macros.
+//
+// Macros are expanded at the AST level while a check expression is parsed.
+// Because the expanded nodes take the source position of the macro call,
+// any type-check errors in an expansion point at the macro in the original
+// check expression.
+func dialectMacros(d *dialect.Dialect) ([]cel.Macro, error) {
+	var names []string
+	for name := range d.Macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var macros []cel.Macro
+	for _, name := range names {
+		m := d.Macros[name]
+
+		parsed, errs := parser.Parse(common.NewTextSource(m.Expression))
+		if len(errs.GetErrors()) > 0 {
+			return nil, fmt.Errorf("macro %s has an invalid expression: %s", name, errs.ToDisplayString())
+		}
+		body := parsed.GetExpr()
+		params := m.Params
+
+		expander := func(eh parser.ExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+			bindings := map[string]*exprpb.Expr{}
+			for i, p := range params {
+				bindings[p] = args[i]
+			}
+			return expandMacro(eh, body, bindings), nil
+		}
+
+		macros = append(macros, parser.NewGlobalMacro(name, len(params), expander))
+	}
+	return macros, nil
+}
+
+// expandMacro copies the macro body into the expression being parsed,
+// substituting parameter identifiers with the arguments of the macro call.
+func expandMacro(eh parser.ExprHelper, e *exprpb.Expr, bindings map[string]*exprpb.Expr) *exprpb.Expr {
+	expand := func(child *exprpb.Expr) *exprpb.Expr {
+		return expandMacro(eh, child, bindings)
+	}
+
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		if arg, ok := bindings[k.IdentExpr.GetName()]; ok {
+			return eh.Copy(arg)
+		}
+		return eh.Ident(k.IdentExpr.GetName())
+
+	case *exprpb.Expr_SelectExpr:
+		sel := k.SelectExpr
+		if sel.GetTestOnly() {
+			return eh.PresenceTest(expand(sel.GetOperand()), sel.GetField())
+		}
+		return eh.Select(expand(sel.GetOperand()), sel.GetField())
+
+	case *exprpb.Expr_CallExpr:
+		call := k.CallExpr
+		var args []*exprpb.Expr
+		for _, a := range call.GetArgs() {
+			args = append(args, expand(a))
+		}
+		if call.GetTarget() != nil {
+			return eh.ReceiverCall(call.GetFunction(), expand(call.GetTarget()), args...)
+		}
+		return eh.GlobalCall(call.GetFunction(), args...)
+
+	case *exprpb.Expr_ListExpr:
+		var elems []*exprpb.Expr
+		for _, el := range k.ListExpr.GetElements() {
+			elems = append(elems, expand(el))
+		}
+		return eh.NewList(elems...)
+
+	case *exprpb.Expr_StructExpr:
+		st := k.StructExpr
+		if st.GetMessageName() == "" {
+			var entries []*exprpb.Expr_CreateStruct_Entry
+			for _, entry := range st.GetEntries() {
+				entries = append(entries, eh.NewMapEntry(expand(entry.GetMapKey()), expand(entry.GetValue()), entry.GetOptionalEntry()))
+			}
+			return eh.NewMap(entries...)
+		}
+		var fields []*exprpb.Expr_CreateStruct_Entry
+		for _, entry := range st.GetEntries() {
+			fields = append(fields, eh.NewObjectFieldInit(entry.GetFieldKey(), expand(entry.GetValue()), entry.GetOptionalEntry()))
+		}
+		return eh.NewObject(st.GetMessageName(), fields...)
+
+	case *exprpb.Expr_ComprehensionExpr:
+		c := k.ComprehensionExpr
+
+		// the iteration variable shadows any parameter with the same name.
+		inner := map[string]*exprpb.Expr{}
+		for name, arg := range bindings {
+			if name != c.GetIterVar() && name != c.GetAccuVar() {
+				inner[name] = arg
+			}
+		}
+		expandInner := func(child *exprpb.Expr) *exprpb.Expr {
+			return expandMacro(eh, child, inner)
+		}
+
+		return eh.Fold(
+			c.GetIterVar(),
+			expand(c.GetIterRange()),
+			c.GetAccuVar(),
+			expand(c.GetAccuInit()),
+			expandInner(c.GetLoopCondition()),
+			expandInner(c.GetLoopStep()),
+			expandInner(c.GetResult()),
+		)
+	}
+
+	// constants, and any expression kinds added in future
+	return eh.Copy(e)
+}
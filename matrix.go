@@ -0,0 +1,646 @@
+package glide
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// matrixVar is the variable which checks in a matrix pass read its values from,
+// e.g. 'matrix.resource'.
+const matrixVar = "matrix"
+
+// Matrix expands a pass into one pass for each combination of its values,
+// like a matrix in GitHub Actions. It is set with the 'matrix' field of a pass:
+//
+//	workflow:
+//	  resource_approval:
+//	    matrix:
+//	      resource: input.resources
+//	    steps:
+//	      - start: request
+//	      - name: Approve ${matrix.resource}
+//	        action: owner_approval
+//	        with:
+//	          resource: ${matrix.resource}
+//	      - outcome: approved
+//
+// Checks read the values as 'matrix.<name>', e.g. 'matrix.resource != "prod"'.
+// Names, descriptions and action properties reference them as '${matrix.<name>}'.
+//
+// The expanded passes are named after the pass and their values, e.g.
+// 'resource_approval[prod]', with the values in the order of their names.
+type Matrix []MatrixVar
+
+// MatrixVar is a named list of values in a matrix.
+//
+// The values are either listed in the workflow, e.g. 'env: [dev, prod]', or read from
+// an input field, e.g. 'resource: input.resources'. A pass over an input field is
+// expanded when the workflow is compiled if the input schema enumerates the values
+// of the field, and a check is added after the start node so that each expanded pass
+// only applies to values which are in the input. Otherwise, the pass is expanded when
+// the workflow is executed, over the values in the input. If the input has no values,
+// the pass is never followed.
+type MatrixVar struct {
+	Name string
+
+	// Values are the values listed in the workflow.
+	Values []any
+
+	// Field is the dot-separated path of the input field the values are read from,
+	// e.g. 'resources'. The field is either a list of values or a single value.
+	Field string
+}
+
+// parseMatrix parses the 'matrix' field of a pass.
+// the value looks like this:
+//
+//	matrix:
+//	  resource: input.resources
+//	  env: [dev, prod]
+func parseMatrix(n ast.Node) (Matrix, error) {
+	var m map[string]ast.Node
+	err := yaml.NodeToValue(n, &m)
+	if err != nil {
+		return nil, noderr.Wrap(fmt.Errorf("matrix must be a map of names to values"), n)
+	}
+
+	var matrix Matrix
+	for name, vn := range m {
+		if !matrixNameRegex.MatchString(name) {
+			return nil, noderr.Wrap(fmt.Errorf("invalid matrix name %q: names must start with a letter and contain only letters, numbers and '_'", name), n)
+		}
+		if vn == nil {
+			return nil, noderr.Wrap(fmt.Errorf("matrix %s must have a value", name), n)
+		}
+
+		v := MatrixVar{Name: name}
+		if str, ok := vn.(*ast.StringNode); ok {
+			field := strings.TrimPrefix(str.Value, "input.")
+			if field == str.Value || field == "" {
+				return nil, noderr.Wrap(fmt.Errorf("matrix %s must be a list of values or an input field, like 'input.resources'", name), vn)
+			}
+			v.Field = field
+		} else {
+			err = yaml.NodeToValue(vn, &v.Values)
+			if err != nil {
+				return nil, noderr.Wrap(fmt.Errorf("matrix %s must be a list of values or an input field, like 'input.resources'", name), vn)
+			}
+			for _, val := range v.Values {
+				if _, err := matrixValueOf(val, ""); err != nil {
+					return nil, noderr.Wrap(fmt.Errorf("matrix %s: %w", name, err), vn)
+				}
+			}
+		}
+		matrix = append(matrix, v)
+	}
+
+	sort.Slice(matrix, func(i, j int) bool {
+		return matrix[i].Name < matrix[j].Name
+	})
+	return matrix, nil
+}
+
+// matrixNameRegex matches valid matrix names. Names are used as CEL fields,
+// so unlike step IDs they can't contain '-'.
+var matrixNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// matrixValue is a value of a matrix variable.
+type matrixValue struct {
+	// text is the value in names, descriptions, action properties and pass IDs.
+	text string
+	// value is a string, bool, int64 or float64, which replaces
+	// 'matrix.<name>' references in checks.
+	value any
+}
+
+// matrixValueOf converts a matrix value from the workflow or the input.
+// Whole numbers are integers, unless the field type is 'number'.
+func matrixValueOf(v any, t jsoncel.FieldType) (matrixValue, error) {
+	var out any
+	switch n := v.(type) {
+	case string, bool:
+		out = n
+	case int:
+		out = int64(n)
+	case int64:
+		out = n
+	case uint64:
+		out = int64(n)
+	case float64:
+		out = n
+		if t != jsoncel.Number && n == float64(int64(n)) {
+			out = int64(n)
+		}
+	default:
+		return matrixValue{}, fmt.Errorf("value %v must be a string, number or boolean", v)
+	}
+	return matrixValue{text: fmt.Sprint(out), value: out}, nil
+}
+
+// placeholderValue is the value of a matrix variable in a pass which is expanded
+// when the workflow is executed. It's used to compile the pass once, as 'pass[*]',
+// so that the pass can be checked and rendered before there is an input.
+func placeholderValue(t jsoncel.FieldType) matrixValue {
+	switch t {
+	case jsoncel.Integer:
+		return matrixValue{text: "*", value: int64(0)}
+	case jsoncel.Number:
+		return matrixValue{text: "*", value: float64(0)}
+	case jsoncel.Boolean:
+		return matrixValue{text: "*", value: false}
+	}
+	return matrixValue{text: "*", value: ""}
+}
+
+// expandMatrices returns a copy of the program with its matrix passes
+// and its for_each steps expanded. See expandForEach.
+//
+// Passes over input fields which aren't enumerated by the schema are expanded over
+// the values in the input. If the input is nil, they are expanded once with placeholder
+// values instead, and runtime is true so that the caller can expand them again
+// when the workflow is executed.
+func expandMatrices(p *Program, schema *jsoncel.Schema, input map[string]any) (out *Program, runtime bool, err error) {
+	out = &Program{}
+	*out = *p
+	out.Workflow = map[string]Path{}
+
+	for id, pass := range p.Workflow {
+		if len(pass.Matrix) == 0 {
+			// matrix references can only be used in matrix passes.
+			_, err = expandSteps(pass.Steps, refs{namespace: matrixVar, pass: id})
+			if err != nil {
+				return nil, false, err
+			}
+			out.Workflow[id] = pass
+			continue
+		}
+
+		combinations := []map[string]matrixValue{{}}
+		var gates []string
+		for _, v := range pass.Matrix {
+			values, gate, isRuntime, err := matrixValues(id, v, schema, input)
+			if err != nil {
+				return nil, false, err
+			}
+			runtime = runtime || isRuntime
+			if gate != "" {
+				gates = append(gates, gate)
+			}
+			if len(combinations)*len(values) > maxMatrixPasses {
+				return nil, false, fmt.Errorf("pass %s: matrix expands into more than %d passes", id, maxMatrixPasses)
+			}
+
+			var next []map[string]matrixValue
+			for _, c := range combinations {
+				for _, val := range values {
+					nc := map[string]matrixValue{v.Name: val}
+					for k, existing := range c {
+						nc[k] = existing
+					}
+					next = append(next, nc)
+				}
+			}
+			combinations = next
+		}
+
+		for _, c := range combinations {
+			var texts []string
+			for _, v := range pass.Matrix {
+				texts = append(texts, c[v.Name].text)
+			}
+			passID := fmt.Sprintf("%s[%s]", id, strings.Join(texts, ","))
+
+			steps := pass.Steps
+			if len(gates) > 0 && len(steps) > 0 {
+				gate := step.Step{Body: step.Check{Expression: strings.Join(gates, " && ")}}
+				steps = append([]step.Step{steps[0], gate}, steps[1:]...)
+			}
+
+			expanded, err := expandSteps(steps, refs{namespace: matrixVar, pass: passID, values: c})
+			if err != nil {
+				return nil, false, err
+			}
+			out.Workflow[passID] = Path{id: passID, Steps: expanded}
+		}
+	}
+
+	for id, pass := range out.Workflow {
+		steps, found, err := expandForEach(pass.Steps, id, schema, input)
+		if err != nil {
+			return nil, false, err
+		}
+		runtime = runtime || found
+		pass.Steps = steps
+		out.Workflow[id] = pass
+	}
+
+	return out, runtime, nil
+}
+
+// matrixValues returns the values of a matrix variable.
+// If the values are enumerated by the input schema, gate is the check
+// which the expanded passes must pass for the value to be in the input.
+// If the input has no values, gate is 'false'.
+func matrixValues(pass string, v MatrixVar, schema *jsoncel.Schema, input map[string]any) (values []matrixValue, gate string, runtime bool, err error) {
+	if v.Field == "" {
+		for _, val := range v.Values {
+			mv, err := matrixValueOf(val, "")
+			if err != nil {
+				return nil, "", false, fmt.Errorf("pass %s: matrix %s: %w", pass, v.Name, err)
+			}
+			values = append(values, mv)
+		}
+		return values, "", false, nil
+	}
+
+	field, item, err := inputField(schema, v.Field)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("pass %s: matrix %s: %w", pass, v.Name, err)
+	}
+
+	if item != nil && len(item.Enum) > 0 {
+		for _, val := range item.Enum {
+			mv, err := matrixValueOf(val, item.Type)
+			if err != nil {
+				return nil, "", false, fmt.Errorf("pass %s: matrix %s: %w", pass, v.Name, err)
+			}
+			values = append(values, mv)
+		}
+		gate = fmt.Sprintf("%s.%s == input.%s", matrixVar, v.Name, v.Field)
+		if field.Type == jsoncel.Array {
+			gate = fmt.Sprintf("%s.%s in input.%s", matrixVar, v.Name, v.Field)
+		}
+		return values, gate, false, nil
+	}
+
+	var t jsoncel.FieldType
+	if item != nil {
+		t = item.Type
+	}
+
+	if input == nil {
+		return []matrixValue{placeholderValue(t)}, "", true, nil
+	}
+
+	values, err = inputValues(input, v.Field, t)
+	if err != nil {
+		return nil, "", true, fmt.Errorf("pass %s: matrix %s: %w", pass, v.Name, err)
+	}
+
+	// if the input has no values, the pass is compiled with placeholder values and a
+	// check which is never true, so that its start and outcome nodes are in the graph.
+	if len(values) == 0 {
+		return []matrixValue{placeholderValue(t)}, "false", true, nil
+	}
+	return values, "", true, nil
+}
+
+// inputField returns the schema of an input field, and the schema of its values,
+// which is the schema of its items if it's a list. They are nil if schema is nil.
+func inputField(schema *jsoncel.Schema, path string) (field, item *jsoncel.Schema, err error) {
+	if schema == nil {
+		return nil, nil, nil
+	}
+	field = schema
+	for _, part := range strings.Split(path, ".") {
+		field = field.Properties[part]
+		if field == nil {
+			return nil, nil, fmt.Errorf("input.%s is not defined in the input schema", path)
+		}
+	}
+	item = field
+	if field.Type == jsoncel.Array && field.Items != nil {
+		item = field.Items
+	}
+	return field, item, nil
+}
+
+// inputValues returns the distinct values of an input field, which is either
+// a list of values or a single value. Duplicate values are dropped, as they
+// would be expanded into steps with the same hash.
+func inputValues(input map[string]any, path string, t jsoncel.FieldType) ([]matrixValue, error) {
+	var list []any
+	val, _ := Lookup(input, path)
+	if rv := reflect.ValueOf(val); rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			list = append(list, rv.Index(i).Interface())
+		}
+	} else if val != nil {
+		list = []any{val}
+	}
+
+	var values []matrixValue
+	seen := map[any]bool{}
+	for _, el := range list {
+		mv, err := matrixValueOf(el, t)
+		if err != nil {
+			return nil, err
+		}
+		if seen[mv.value] {
+			continue
+		}
+		seen[mv.value] = true
+		values = append(values, mv)
+	}
+	return values, nil
+}
+
+// refs are the values which replace the references to a namespace,
+// like 'matrix.<name>' in checks and '${matrix.<name>}' in names.
+type refs struct {
+	// namespace is 'matrix' or 'each'.
+	namespace string
+	pass      string
+
+	// values is nil when checking that a pass without
+	// a matrix has no matrix references.
+	values map[string]matrixValue
+
+	// partial leaves the references to names which aren't in values as they are,
+	// for the steps of nested for_each steps, which are expanded afterwards.
+	partial bool
+}
+
+// lookup returns the value of a name. ok is false if the
+// reference should be left as it is.
+func (r refs) lookup(name string) (v matrixValue, ok bool, err error) {
+	if r.values == nil {
+		return matrixValue{}, false, fmt.Errorf("pass %s has no matrix: %s.%s can only be used in a pass with a matrix", r.pass, r.namespace, name)
+	}
+	v, ok = r.values[name]
+	if !ok && !r.partial {
+		return matrixValue{}, false, fmt.Errorf("pass %s has no %s value %q", r.pass, r.namespace, name)
+	}
+	return v, ok, nil
+}
+
+// expandSteps returns a copy of the steps with the references replaced by their
+// values, and with the steps assigned to the pass. If r.values is nil, the steps
+// are returned as they are and an error is returned if they contain any references.
+func expandSteps(steps []step.Step, r refs) ([]step.Step, error) {
+	if steps == nil {
+		return nil, nil
+	}
+
+	text := r.expandText
+
+	out := make([]step.Step, len(steps))
+	for i, s := range steps {
+		var err error
+		s.Pass = r.pass
+
+		s.Name, err = text(s.Name)
+		if err != nil {
+			return nil, noderr.Wrap(err, s.Node)
+		}
+		s.Description, err = text(s.Description)
+		if err != nil {
+			return nil, noderr.Wrap(err, s.Node)
+		}
+
+		switch b := s.Body.(type) {
+		case step.Check:
+			if r.values != nil {
+				b.Expression, err = r.expandCheck(b.Expression)
+				if err != nil {
+					return nil, noderr.Wrap(err, s.Node)
+				}
+				s.Body = b
+			}
+		case step.Action:
+			// each expansion has its own copy of the action.
+			a, err := expandValue(reflect.ValueOf(b.Action), text)
+			if err != nil {
+				return nil, noderr.Wrap(err, s.Node)
+			}
+			if r.values != nil && a.IsValid() {
+				b.Action = a.Interface()
+				s.Body = b
+			}
+		}
+
+		s.Children, err = expandSteps(s.Children, r)
+		if err != nil {
+			return nil, err
+		}
+		s.OnFail.Steps, err = expandSteps(s.OnFail.Steps, r)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// expandText replaces the '${<namespace>.<name>}' references in a string.
+func (r refs) expandText(s string) (string, error) {
+	prefix := "${" + r.namespace + "."
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, prefix)
+		if i == -1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.Index(s[i:], "}")
+		if end == -1 {
+			return "", fmt.Errorf("unterminated %s reference %q", r.namespace, s[i:])
+		}
+		name := s[i+len(prefix) : i+end]
+
+		v, ok, err := r.lookup(name)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s[:i])
+		if ok {
+			b.WriteString(v.text)
+		} else {
+			b.WriteString(s[i : i+end+1])
+		}
+		s = s[i+end+1:]
+	}
+}
+
+// expandCheck replaces the '<namespace>.<name>' references in a check expression
+// with constants. Expressions which can't be parsed are returned as they are,
+// so that the parse error is reported when the check is compiled.
+func (r refs) expandCheck(expression string) (string, error) {
+	if !strings.Contains(expression, r.namespace) {
+		return expression, nil
+	}
+
+	env, err := cel.NewEnv(cel.EnableMacroCallTracking())
+	if err != nil {
+		return "", err
+	}
+	parsed, issues := env.Parse(expression)
+	if issues != nil && issues.Err() != nil {
+		return expression, nil
+	}
+
+	pe, err := cel.AstToParsedExpr(parsed)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.replaceRefs(pe.GetExpr())
+	if err != nil {
+		return "", err
+	}
+	// macros like 'all' are printed from their calls, which are tracked separately.
+	for _, call := range pe.GetSourceInfo().GetMacroCalls() {
+		err = r.replaceRefs(call)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return cel.AstToString(cel.ParsedExprToAst(pe))
+}
+
+// replaceRefs replaces '<namespace>.<name>' selections with constants, in place.
+func (r refs) replaceRefs(e *exprpb.Expr) error {
+	if e == nil {
+		return nil
+	}
+
+	var children []*exprpb.Expr
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		sel := k.SelectExpr
+		if sel.GetOperand().GetIdentExpr().GetName() == r.namespace && !sel.GetTestOnly() {
+			v, ok, err := r.lookup(sel.GetField())
+			if err != nil {
+				return err
+			}
+			if ok {
+				e.ExprKind = &exprpb.Expr_ConstExpr{ConstExpr: matrixConstant(v.value)}
+			}
+			return nil
+		}
+		children = []*exprpb.Expr{sel.GetOperand()}
+	case *exprpb.Expr_CallExpr:
+		children = append([]*exprpb.Expr{k.CallExpr.GetTarget()}, k.CallExpr.GetArgs()...)
+	case *exprpb.Expr_ListExpr:
+		children = k.ListExpr.GetElements()
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			children = append(children, entry.GetMapKey(), entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		c := k.ComprehensionExpr
+		children = []*exprpb.Expr{c.GetIterRange(), c.GetAccuInit(), c.GetLoopCondition(), c.GetLoopStep(), c.GetResult()}
+	}
+
+	for _, child := range children {
+		err := r.replaceRefs(child)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matrixConstant(v any) *exprpb.Constant {
+	switch t := v.(type) {
+	case bool:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_BoolValue{BoolValue: t}}
+	case int64:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_Int64Value{Int64Value: t}}
+	case float64:
+		return &exprpb.Constant{ConstantKind: &exprpb.Constant_DoubleValue{DoubleValue: t}}
+	}
+	return &exprpb.Constant{ConstantKind: &exprpb.Constant_StringValue{StringValue: fmt.Sprint(v)}}
+}
+
+// expandValue returns a copy of an action, with the matrix references in its
+// exported string fields replaced. Unexported fields are copied as they are.
+func expandValue(v reflect.Value, text func(string) (string, error)) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := text(v.String())
+		if err != nil {
+			return v, err
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(s)
+		return out, nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, nil
+		}
+		el, err := expandValue(v.Elem(), text)
+		if err != nil {
+			return v, err
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(el)
+		return out, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		el, err := expandValue(v.Elem(), text)
+		if err != nil {
+			return v, err
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(el)
+		return out, nil
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			f, err := expandValue(v.Field(i), text)
+			if err != nil {
+				return v, err
+			}
+			out.Field(i).Set(f)
+		}
+		return out, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			el, err := expandValue(v.Index(i), text)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(el)
+		}
+		return out, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			el, err := expandValue(iter.Value(), text)
+			if err != nil {
+				return v, err
+			}
+			out.SetMapIndex(iter.Key(), el)
+		}
+		return out, nil
+	}
+	return v, nil
+}
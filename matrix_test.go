@@ -0,0 +1,268 @@
+package glide
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/stretchr/testify/assert"
+)
+
+const matrixWorkflow = `
+workflow:
+  deploy:
+    matrix:
+      env: [dev, prod]
+    steps:
+      - start: request
+      - name: Deploy to ${matrix.env}
+        check: matrix.env == input.env
+      - action: my_action
+        with:
+          property: ${matrix.env}
+      - outcome: approved
+`
+
+func TestExpandMatrices(t *testing.T) {
+	p, err := Unmarshal([]byte(matrixWorkflow), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Matrix{{Name: "env", Values: []any{"dev", "prod"}}}, p.Workflow["deploy"].Matrix)
+
+	got, runtime, err := expandMatrices(p, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, runtime)
+
+	var passes []string
+	for id := range got.Workflow {
+		passes = append(passes, id)
+	}
+	sort.Strings(passes)
+	assert.Equal(t, []string{"deploy[dev]", "deploy[prod]"}, passes)
+
+	steps := got.Workflow["deploy[prod]"].Steps
+	assert.Equal(t, "Deploy to prod", steps[1].Name)
+	assert.Equal(t, step.Check{Expression: `"prod" == input.env`}, steps[1].Body)
+	assert.Equal(t, "prod", steps[2].Body.(step.Action).Action.(*testAction).Property)
+	assert.Equal(t, "deploy[prod]", steps[2].Pass)
+
+	// the template isn't modified.
+	assert.Equal(t, "${matrix.env}", p.Workflow["deploy"].Steps[2].Body.(step.Action).Action.(*testAction).Property)
+}
+
+func TestMatrix_Execute(t *testing.T) {
+	workflow := `
+workflow:
+  resource:
+    matrix:
+      resource: input.resources
+    steps:
+      - start: request
+      - check: matrix.resource in input.approved
+      - outcome: approved
+`
+	strings := &jsoncel.Schema{Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}}
+	enum := &jsoncel.Schema{Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String, Enum: []any{"dev", "prod"}}}
+
+	tests := []struct {
+		name      string
+		resources *jsoncel.Schema
+		input     map[string]any
+		wantState map[string]State
+	}{
+		{
+			name:      "expanded at compile time from enum",
+			resources: enum,
+			input:     map[string]any{"resources": []any{"prod"}, "approved": []any{"prod"}},
+			wantState: map[string]State{
+				"request":          Complete,
+				"resource[dev].1":  Inactive,
+				"resource[dev].2":  Inactive,
+				"resource[prod].1": Complete,
+				"resource[prod].2": Complete,
+				"approved":         Complete,
+			},
+		},
+		{
+			name:      "expanded at execute time from input",
+			resources: strings,
+			input:     map[string]any{"resources": []any{"dev", "prod", "dev"}, "approved": []any{"dev"}},
+			wantState: map[string]State{
+				"request":          Complete,
+				"resource[dev].1":  Complete,
+				"resource[prod].1": Inactive,
+				"approved":         Complete,
+			},
+		},
+		{
+			name:      "no values in input",
+			resources: strings,
+			input:     map[string]any{"resources": []any{}, "approved": []any{}},
+			wantState: map[string]State{
+				"request":       Complete,
+				"resource[*].1": Inactive,
+				"resource[*].2": Inactive,
+				"approved":      Inactive,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Unmarshal([]byte(workflow), yamlTestDialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := Compiler{Program: p, InputSchema: &jsoncel.Schema{
+				Type: jsoncel.Object,
+				Properties: map[string]*jsoncel.Schema{
+					"resources": tt.resources,
+					"approved":  strings,
+				},
+			}}
+			g, err := c.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantState, got.State)
+
+			// the result is rendered on the graph it was executed on.
+			var buf bytes.Buffer
+			err = g.Render(&buf, got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k := range tt.wantState {
+				assert.Contains(t, buf.String(), k)
+			}
+		})
+	}
+}
+
+func TestMatrix_Placeholder(t *testing.T) {
+	workflow := `
+workflow:
+  resource:
+    matrix:
+      resource: input.resources
+    steps:
+      - start: request
+      - name: Approve ${matrix.resource}
+        check: matrix.resource in input.approved
+      - outcome: approved
+`
+	p, err := Unmarshal([]byte(workflow), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &jsoncel.Schema{Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}}
+	c := Compiler{Program: p, InputSchema: &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{"resources": list, "approved": list},
+	}}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := g.store.step("resource[*].1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Approve *", s.Name)
+	assert.Equal(t, step.Check{Expression: `"" in input.approved`}, s.Body)
+}
+
+func TestMatrix_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr string
+	}{
+		{
+			name: "reference outside matrix pass",
+			give: `
+workflow:
+  default:
+    steps:
+      - start: request
+      - name: Approve ${matrix.env}
+        check: "true"
+      - outcome: approved
+`,
+			wantErr: "pass default has no matrix: matrix.env can only be used in a pass with a matrix",
+		},
+		{
+			name: "unknown matrix value",
+			give: `
+workflow:
+  deploy:
+    matrix:
+      env: [dev]
+    steps:
+      - start: request
+      - check: matrix.region == "us-east-1"
+      - outcome: approved
+`,
+			wantErr: `pass deploy[dev] has no matrix value "region"`,
+		},
+		{
+			name: "field not in schema",
+			give: `
+workflow:
+  deploy:
+    matrix:
+      env: input.envs
+    steps:
+      - start: request
+      - outcome: approved
+`,
+			wantErr: "pass deploy: matrix env: input.envs is not defined in the input schema",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Unmarshal([]byte(tt.give), yamlTestDialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := Compiler{Program: p, InputSchema: &jsoncel.Schema{Type: jsoncel.Object}}
+			_, err = c.Compile()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseMatrix_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr string
+	}{
+		{name: "not an input field", give: "env: prod", wantErr: "matrix env must be a list of values or an input field, like 'input.resources'"},
+		{name: "invalid name", give: "my-env: [dev]", wantErr: `invalid matrix name "my-env"`},
+		{name: "object value", give: "env: [{name: dev}]", wantErr: "matrix env: value map[name:dev] must be a string, number or boolean"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := "workflow:\n  deploy:\n    matrix:\n      " + tt.give + "\n    steps:\n      - start: request\n      - outcome: approved\n"
+			_, err := Unmarshal([]byte(workflow), yamlTestDialect)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
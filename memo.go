@@ -0,0 +1,74 @@
+package glide
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// checkMemoKey returns the key which the results of a check are memoized by
+// during an execution, which is a hash of its type-checked expression, so that
+// checks with identical expressions in several passes are evaluated once.
+//
+// Only checks whose result depends on nothing but the input are memoized.
+// Checks which read the state or outputs of steps, or the 'workflow' variable,
+// can have a different result each time they're evaluated, as can checks
+// which call impure functions, so they return false.
+func (g *Graph) checkMemoKey(ast *cel.Ast) (string, bool) {
+	idents := map[string]bool{}
+	collectIdents(ast.Expr(), idents)
+	for name := range idents {
+		root, _, _ := strings.Cut(name, ".")
+		if root == stepsVar || root == workflowVar {
+			return "", false
+		}
+	}
+	if len(impureCalls(ast.Expr(), g.impureFunctions())) > 0 {
+		return "", false
+	}
+
+	expr, err := cel.AstToString(ast)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(expr))
+	return hex.EncodeToString(sum[:]), true
+}
+
+// collectIdents adds the names of the variables referenced in an expression.
+func collectIdents(e *exprpb.Expr, found map[string]bool) {
+	if e == nil {
+		return
+	}
+
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		found[k.IdentExpr.GetName()] = true
+	case *exprpb.Expr_SelectExpr:
+		collectIdents(k.SelectExpr.GetOperand(), found)
+	case *exprpb.Expr_CallExpr:
+		collectIdents(k.CallExpr.GetTarget(), found)
+		for _, a := range k.CallExpr.GetArgs() {
+			collectIdents(a, found)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, el := range k.ListExpr.GetElements() {
+			collectIdents(el, found)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			collectIdents(entry.GetMapKey(), found)
+			collectIdents(entry.GetValue(), found)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		c := k.ComprehensionExpr
+		collectIdents(c.GetIterRange(), found)
+		collectIdents(c.GetAccuInit(), found)
+		collectIdents(c.GetLoopCondition(), found)
+		collectIdents(c.GetLoopStep(), found)
+		collectIdents(c.GetResult(), found)
+	}
+}
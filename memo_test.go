@@ -0,0 +1,139 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute_MemoizesChecks(t *testing.T) {
+	var calls int
+	d := &dialect.Dialect{
+		Functions: map[string]dialect.Function{
+			"twice": {
+				Overloads: []cel.FunctionOpt{cel.Overload("twice_int", []*cel.Type{cel.IntType}, cel.IntType,
+					cel.UnaryBinding(func(v ref.Val) ref.Val {
+						calls++
+						return v.(types.Int) * 2
+					}))},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		checks    [2]string
+		wantCalls int
+	}{
+		{
+			name:      "identical checks",
+			checks:    [2]string{"twice(input.n) == 4", "twice(input.n) == 4"},
+			wantCalls: 1,
+		},
+		{
+			name:      "identical checks formatted differently",
+			checks:    [2]string{"twice(input.n)==4", "twice( input.n ) == 4"},
+			wantCalls: 1,
+		},
+		{
+			name:      "different checks",
+			checks:    [2]string{"twice(input.n) == 4", "twice(input.n) > 3"},
+			wantCalls: 2,
+		},
+		{
+			name:      "checks reading the workflow variable",
+			checks:    [2]string{`twice(input.n) == 4 && workflow.pass != ""`, `twice(input.n) == 4 && workflow.pass != ""`},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProgram().
+				Pass("a",
+					s.Start("request"),
+					s.Check(tt.checks[0]),
+					s.Named("Approved").Priority(1).Outcome("approved"),
+				).
+				Pass("b",
+					s.Start("request"),
+					s.Check(tt.checks[1]),
+					s.Named("Approved").Priority(1).Outcome("approved"),
+				)
+			p.Dialect = d
+
+			g, err := Compile(p, &jsoncel.Schema{Properties: map[string]*jsoncel.Schema{"n": {Type: jsoncel.Integer}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			calls = 0
+			res, err := g.Execute("request", map[string]any{"n": 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, Complete, res.State["a.1"])
+			assert.Equal(t, Complete, res.State["b.1"])
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestExecute_MemoizesDedupedChecks(t *testing.T) {
+	var calls int
+	d := &dialect.Dialect{
+		Functions: map[string]dialect.Function{
+			"twice": {
+				Overloads: []cel.FunctionOpt{cel.Overload("twice_int", []*cel.Type{cel.IntType}, cel.IntType,
+					cel.UnaryBinding(func(v ref.Val) ref.Val {
+						calls++
+						return v.(types.Int) * 2
+					}))},
+			},
+		},
+	}
+
+	// the checks in a and b are moved into the shared pass, and c's check
+	// isn't as it follows a different step. They're still memoized together.
+	p := NewProgram().
+		Pass("a",
+			s.Start("request"),
+			s.Check("twice(input.n) == 4"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		).
+		Pass("b",
+			s.Start("request"),
+			s.Check("twice(input.n) == 4"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		).
+		Pass("c",
+			s.Start("request"),
+			s.Check("input.n > 0"),
+			s.Check("twice(input.n) == 4"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		)
+	p.Dialect = d
+
+	c := Compiler{
+		Program:     p,
+		InputSchema: &jsoncel.Schema{Properties: map[string]*jsoncel.Schema{"n": {Type: jsoncel.Integer}}},
+		DedupeSteps: true,
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls = 0
+	res, err := g.Execute("request", map[string]any{"n": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Complete, res.State["c.2"])
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,94 @@
+package glide
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// MergeConflict is a pass which is defined in both the base
+// and the overlay program passed to Merge.
+// The overlay pass replaces the base pass in the merged program.
+type MergeConflict struct {
+	// Pass is the name of the pass.
+	Pass string
+	// Base is the pass from the base program which was replaced.
+	Base Path
+	// Overlay is the pass from the overlay program which replaced it.
+	Overlay Path
+}
+
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("pass %s in the base program was replaced by the overlay", c.Pass)
+}
+
+// Merge combines two programs into a single program, so that
+// a baseline workflow can be layered under customisations before it is compiled.
+//
+// Passes in the overlay are added to the base program. If a pass with the same
+// name exists in the base program, the overlay pass replaces it and a
+// MergeConflict is returned describing the replacement, sorted by pass name.
+//
+// An error is returned if the programs were written in incompatible dialects.
+// Neither of the input programs are modified.
+func Merge(base, overlay *Program) (*Program, []MergeConflict, error) {
+	if base == nil || overlay == nil {
+		return nil, nil, errors.New("base and overlay programs must be provided")
+	}
+
+	d := base.Dialect
+	if d == nil {
+		d = overlay.Dialect
+	}
+
+	if base.Dialect != nil && overlay.Dialect != nil {
+		if !reflect.DeepEqual(base.Dialect.Nodes, overlay.Dialect.Nodes) {
+			return nil, nil, errors.New("could not merge programs: the base and overlay dialects have different nodes")
+		}
+		if !reflect.DeepEqual(base.Dialect.Macros, overlay.Dialect.Macros) {
+			return nil, nil, errors.New("could not merge programs: the base and overlay dialects have different macros")
+		}
+	}
+
+	merged := NewProgram()
+	merged.Dialect = d
+
+	// the overlay's version and default outcome take precedence, as it customises the base.
+	merged.Version = base.Version
+	if overlay.Version != "" {
+		merged.Version = overlay.Version
+	}
+	merged.DefaultOutcome = base.DefaultOutcome
+	if overlay.DefaultOutcome != "" {
+		merged.DefaultOutcome = overlay.DefaultOutcome
+	}
+
+	// the preconditions of both programs must pass.
+	merged.Preconditions = append(append([]step.Step(nil), base.Preconditions...), overlay.Preconditions...)
+
+	for name, pass := range base.Workflow {
+		merged.Workflow[name] = pass
+	}
+
+	var conflicts []MergeConflict
+
+	for name, pass := range overlay.Workflow {
+		if existing, ok := merged.Workflow[name]; ok {
+			conflicts = append(conflicts, MergeConflict{
+				Pass:    name,
+				Base:    existing,
+				Overlay: pass,
+			})
+		}
+		merged.Workflow[name] = pass
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Pass < conflicts[j].Pass
+	})
+
+	return merged, conflicts, nil
+}
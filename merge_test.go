@@ -0,0 +1,92 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	base := NewProgram().
+		Pass("baseline", s.Start("request"), s.Check("input.mfa"), s.Outcome("approved")).
+		Pass("team", s.Start("request"), s.Outcome("approved"))
+
+	overlay := NewProgram().
+		Pass("team", s.Start("request"), s.Check("input.on_call"), s.Outcome("approved")).
+		Pass("extra", s.Start("request"), s.Outcome("denied"))
+
+	baseTeam := base.Workflow["team"]
+
+	got, conflicts, err := Merge(base, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]Path{
+		"baseline": base.Workflow["baseline"],
+		"team":     overlay.Workflow["team"],
+		"extra":    overlay.Workflow["extra"],
+	}, got.Workflow)
+
+	assert.Equal(t, []MergeConflict{
+		{
+			Pass:    "team",
+			Base:    baseTeam,
+			Overlay: overlay.Workflow["team"],
+		},
+	}, conflicts)
+
+	// the base program must not be modified.
+	assert.Len(t, base.Workflow, 2)
+	assert.Equal(t, baseTeam, base.Workflow["team"])
+}
+
+func TestMerge_Dialects(t *testing.T) {
+	d1 := dialect.Dialect{
+		Nodes: map[string]node.Node{
+			"request": {Type: node.Start},
+		},
+	}
+	d2 := dialect.Dialect{
+		Nodes: map[string]node.Node{
+			"request": {Type: node.Start},
+			"denied":  {Type: node.Outcome},
+		},
+	}
+
+	type testcase struct {
+		name    string
+		base    *dialect.Dialect
+		overlay *dialect.Dialect
+		want    *dialect.Dialect
+		wantErr bool
+	}
+
+	testcases := []testcase{
+		{name: "same dialect", base: &d1, overlay: &d1, want: &d1},
+		{name: "overlay dialect only", overlay: &d2, want: &d2},
+		{name: "different nodes", base: &d1, overlay: &d2, wantErr: true},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			base := SimpleProgram(s.Start("request"))
+			base.Dialect = tt.base
+			overlay := SimpleProgram(s.Start("request"))
+			overlay.Dialect = tt.overlay
+
+			got, _, err := Merge(base, overlay)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got.Dialect)
+		})
+	}
+}
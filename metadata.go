@@ -0,0 +1,32 @@
+package glide
+
+import (
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// workflowVar is the name of the CEL variable containing workflow metadata.
+// It allows checks which are shared between passes to behave differently
+// in each pass, e.g.
+//
+//   - check: workflow.pass == "breakglass" || input.approved
+const workflowVar = "workflow"
+
+// workflowSchema is the schema of the 'workflow' variable.
+var workflowSchema = &jsoncel.Schema{
+	Type: jsoncel.Object,
+	Properties: map[string]*jsoncel.Schema{
+		"pass":    {Type: jsoncel.String, Description: "The name of the pass the check belongs to."},
+		"step":    {Type: jsoncel.String, Description: "The name of the check step, if it has one."},
+		"version": {Type: jsoncel.String, Description: "The version of the workflow, if it has one."},
+	},
+}
+
+// workflowMetadata returns the value of the 'workflow' variable for a step.
+func workflowMetadata(version string, s step.Step) map[string]any {
+	return map[string]any{
+		"pass":    s.Pass,
+		"step":    s.Name,
+		"version": version,
+	}
+}
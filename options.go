@@ -0,0 +1,119 @@
+package glide
+
+import (
+	"github.com/common-fate/glide/pkg/accesscel"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/google/cel-go/cel"
+)
+
+// CompileOption configures the compilation of a program.
+//
+// Options are the preferred way of configuring the compiler, as
+// new settings can be added as options without breaking callers.
+// The Compiler struct is kept for compatibility.
+type CompileOption func(*Compiler)
+
+// Compile a program into an execution graph, with the input schema
+// the workflow is executed with.
+func Compile(p *Program, schema *jsoncel.Schema, opts ...CompileOption) (*Graph, error) {
+	return newCompiler(p, schema, opts...).Compile()
+}
+
+// Build compiles a program into a Compiled workflow,
+// with the input schema the workflow is executed with.
+func Build(p *Program, schema *jsoncel.Schema, opts ...CompileOption) (*Compiled, error) {
+	return newCompiler(p, schema, opts...).Build()
+}
+
+func newCompiler(p *Program, schema *jsoncel.Schema, opts ...CompileOption) *Compiler {
+	c := Compiler{Program: p, InputSchema: schema}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// WithMaxDepth sets the maximum depth of nested steps. See Compiler.MaxDepth.
+func WithMaxDepth(n int) CompileOption {
+	return func(c *Compiler) {
+		c.MaxDepth = n
+	}
+}
+
+// WithComplexity sets the limits above which checks are reported
+// as too complex in the compiler warnings. See Compiler.Complexity.
+func WithComplexity(l ComplexityLimits) CompileOption {
+	return func(c *Compiler) {
+		c.Complexity = l
+	}
+}
+
+// WithRejectImpure rejects workflows with checks which call impure
+// dialect functions. See Compiler.RejectImpure.
+func WithRejectImpure() CompileOption {
+	return func(c *Compiler) {
+		c.RejectImpure = true
+	}
+}
+
+// WithAllowImpure allows checks to call the impure dialect functions
+// without being reported. See Compiler.AllowImpure.
+func WithAllowImpure(names ...string) CompileOption {
+	return func(c *Compiler) {
+		c.AllowImpure = append(c.AllowImpure, names...)
+	}
+}
+
+// WithFoldConstants removes checks which are always true from the graph.
+// See Compiler.FoldConstants.
+func WithFoldConstants() CompileOption {
+	return func(c *Compiler) {
+		c.FoldConstants = true
+	}
+}
+
+// WithDedupeSteps merges steps which are the same in several passes.
+// See Compiler.DedupeSteps.
+func WithDedupeSteps() CompileOption {
+	return func(c *Compiler) {
+		c.DedupeSteps = true
+	}
+}
+
+// WithLazyPrograms defers constructing the CEL program for each check
+// until it is first evaluated. See Compiler.LazyPrograms.
+func WithLazyPrograms() CompileOption {
+	return func(c *Compiler) {
+		c.LazyPrograms = true
+	}
+}
+
+// WithDefaultOutcomeStrategy sets the strategy which executions of the
+// graph select outcomes with, unless they are executed with WithOutcomeStrategy.
+// See Compiler.OutcomeStrategy.
+func WithDefaultOutcomeStrategy(s OutcomeStrategy) CompileOption {
+	return func(c *Compiler) {
+		c.OutcomeStrategy = s
+	}
+}
+
+// WithLibrary registers a CEL library of functions and macros
+// which checks can use. See Compiler.Libraries.
+func WithLibrary(lib cel.EnvOption) CompileOption {
+	return func(c *Compiler) {
+		c.Libraries = append(c.Libraries, lib)
+	}
+}
+
+// WithAccessFunctions registers the access-control functions of the accesscel
+// package, such as anyIn, emailDomain, matchesGlob and parseDuration.
+func WithAccessFunctions() CompileOption {
+	return WithLibrary(accesscel.Library())
+}
+
+// WithPasses compiles only the passes with the names. See Compiler.Passes.
+func WithPasses(names ...string) CompileOption {
+	return func(c *Compiler) {
+		c.Passes = append(c.Passes, names...)
+	}
+}
@@ -0,0 +1,164 @@
+package glide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_Options(t *testing.T) {
+	p := NewProgram().
+		Pass("auto",
+			s.Start("request"),
+			s.Check("true"),
+			s.Named("Auto Approved").Priority(1).Outcome("auto_approved"),
+		).
+		Pass("manual",
+			s.Start("request"),
+			s.Check("true"),
+			s.Check("true"),
+			s.Named("Approved").Priority(2).Outcome("approved"),
+		)
+
+	g, err := Compile(p, nil,
+		WithMaxDepth(5),
+		WithLazyPrograms(),
+		WithDefaultOutcomeStrategy(FirstReached),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "auto_approved", res.Outcome)
+
+	c, err := Build(p, nil, WithDefaultOutcomeStrategy(AllReached))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = c.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"approved", "auto_approved"}, res.Outcomes)
+}
+
+func TestCompile_MaxDepth(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Boolean(step.And, s.Boolean(step.And, s.Check("true"))),
+		s.Outcome("approved"),
+	)
+
+	_, err := Compile(p, nil, WithMaxDepth(1))
+	assert.Error(t, err)
+
+	_, err = Compile(p, nil)
+	assert.NoError(t, err)
+}
+
+func TestExecute_Tracer(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Check("false"),
+		s.Outcome("approved"),
+	)
+
+	g, err := Compile(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]State{}
+	tracer := TracerFunc(func(hash string, state State, elapsed time.Duration) {
+		got[hash] = state
+	})
+
+	res, err := g.Execute("request", nil, WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]State{
+		"request":   Complete,
+		"default.1": Inactive,
+		"approved":  Inactive,
+	}, got)
+	assert.Equal(t, "", res.Outcome)
+}
+
+func TestCompile_WithAccessFunctions(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Check(`emailDomain(input.email) == "example.com" && anyIn(input.groups, ["admins"])`),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)
+	schema := &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"email":  {Type: jsoncel.String},
+			"groups": {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+		},
+	}
+
+	_, err := Compile(p, schema)
+	assert.ErrorContains(t, err, "undeclared reference to 'emailDomain'")
+
+	g, err := Compile(p, schema, WithAccessFunctions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := g.Execute("request", map[string]any{"email": "alice@Example.com", "groups": []any{"admins"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+
+	res, err = g.Execute("request", map[string]any{"email": "alice@example.org", "groups": []any{"admins"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", res.Outcome)
+}
+
+func TestCompile_WithPasses(t *testing.T) {
+	p := NewProgram().
+		Pass("auto",
+			s.Start("request"),
+			s.Check("true"),
+			s.Named("Auto Approved").Priority(2).Outcome("auto_approved"),
+		).
+		Pass("breakglass",
+			s.Start("request"),
+			s.Check("true"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		)
+
+	g, err := Compile(p, nil, WithPasses("breakglass"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+	assert.NotContains(t, res.State, "auto.1")
+	assert.Contains(t, res.State, "breakglass.1")
+
+	// the program isn't modified.
+	assert.Len(t, p.Workflow, 2)
+
+	_, err = Compile(p, nil, WithPasses("manual"))
+	assert.EqualError(t, err, `pass "manual" not found in the workflow`)
+}
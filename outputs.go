@@ -0,0 +1,114 @@
+package glide
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// stepsVar is the name of the CEL variable containing action outputs.
+const stepsVar = "steps"
+
+// Outputter is implemented by actions which expose output values
+// once they are complete, such as the user who approved a request.
+//
+// Outputs are available to later checks as 'steps.<id>.outputs',
+// where <id> is the ID of the action step, e.g.
+//
+//	steps:
+//	  - id: approval
+//	    action: approval
+//	    with:
+//	      groups: [admins]
+//	  - check: steps.approval.outputs.approver != input.requestor
+//
+// Only action steps with an ID expose their outputs.
+type Outputter interface {
+	// OutputSchema declares the output fields,
+	// so that checks which use them can be type-checked.
+	OutputSchema() *jsoncel.Schema
+
+	// Outputs returns the output values for the input.
+	// It is called after Complete returns true during an execution.
+	// An action is shared by every execution of a compiled workflow,
+	// so outputs must be computed from the input rather than recorded
+	// on the action in Complete.
+	Outputs(ctx context.Context, input any) (map[string]any, error)
+}
+
+// stepsSchema builds the schema for the 'steps' variable, based on
+// the output schemas of the action steps in a program.
+// It also returns the sorted IDs of the steps with outputs.
+func stepsSchema(p *Program) (*jsoncel.Schema, []string, error) {
+	schema := &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{},
+	}
+
+	// sort the passes so that errors are deterministic.
+	var passes []string
+	for name := range p.Workflow {
+		passes = append(passes, name)
+	}
+	sort.Strings(passes)
+
+	var visit func(steps []step.Step) error
+	visit = func(steps []step.Step) error {
+		for _, s := range steps {
+			err := visit(s.Children)
+			if err != nil {
+				return err
+			}
+			err = visit(s.OnFail.Steps)
+			if err != nil {
+				return err
+			}
+
+			a, ok := s.Body.(step.Action)
+			if !ok || s.ID == "" {
+				continue
+			}
+			o, ok := a.Action.(Outputter)
+			if !ok {
+				continue
+			}
+
+			if _, exists := schema.Properties[s.ID]; exists {
+				err = fmt.Errorf("duplicate step id %q: ids of actions with outputs must be unique across passes", s.ID)
+				return noderr.Wrap(err, s.Node)
+			}
+
+			outputs := o.OutputSchema()
+			if outputs == nil {
+				outputs = &jsoncel.Schema{Type: jsoncel.Object}
+			}
+
+			schema.Properties[s.ID] = &jsoncel.Schema{
+				Type: jsoncel.Object,
+				Properties: map[string]*jsoncel.Schema{
+					"outputs": outputs,
+				},
+			}
+		}
+		return nil
+	}
+
+	for _, name := range passes {
+		err := visit(p.Workflow[name].Steps)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var ids []string
+	for id := range schema.Properties {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return schema, ids, nil
+}
@@ -0,0 +1,145 @@
+package glide
+
+import (
+	"fmt"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// UnmarshalWithOverlays unmarshals a base workflow and merges environment-specific
+// overlays over it, in order. For example, a 'workflow.yml' base with a
+// 'workflow.prod.yml' overlay allows one policy source to have staging and
+// production differences.
+//
+// See ApplyOverlay for how the overlays are merged.
+func UnmarshalWithOverlays(base []byte, overlays [][]byte, d dialect.Dialect, opts ...UnmarshalOption) (*Program, error) {
+	p, err := Unmarshal(base, d, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, data := range overlays {
+		o, err := Unmarshal(data, d, opts...)
+		if err != nil {
+			return nil, OverlayError{Index: i, Err: err}
+		}
+		p, err = ApplyOverlay(p, o)
+		if err != nil {
+			return nil, OverlayError{Index: i, Err: err}
+		}
+	}
+	return p, nil
+}
+
+// OverlayError is an error in one of the overlays passed to UnmarshalWithOverlays.
+// If Err is a noderr.NodeError, its node is in the overlay rather than the base workflow.
+type OverlayError struct {
+	// Index of the overlay.
+	Index int
+	Err   error
+}
+
+func (e OverlayError) Error() string {
+	return fmt.Sprintf("overlay %d: %s", e.Index, e.Err)
+}
+
+func (e OverlayError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyOverlay merges an overlay program over a base program.
+//
+// Passes in the overlay which aren't in the base are added. The steps of
+// passes which are in both are merged by their id or name: each overlay step
+// replaces the step in the base pass with the same id, or if it doesn't have
+// an id, the same name. Steps nested in 'and', 'or' and 'on_fail' steps can be
+// replaced too. Start and outcome steps in the overlay are matched by their node,
+// so overlays can repeat them to remain valid workflows.
+//
+// An error is returned if an overlay step can't be matched to exactly one step
+// in the base pass, rather than guessing where the step should go.
+//
+// The overlay's version and default outcome take precedence, and the
+// preconditions of both programs apply. Neither of the input programs are modified.
+func ApplyOverlay(base, overlay *Program) (*Program, error) {
+	merged, _, err := Merge(base, overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, o := range overlay.Workflow {
+		b, ok := base.Workflow[name]
+		if !ok {
+			continue
+		}
+
+		pass := Path{id: name, Steps: make([]step.Step, len(b.Steps))}
+		for i, s := range b.Steps {
+			pass.Steps[i] = copyStep(s)
+		}
+
+		for _, s := range o.Steps {
+			err := overlayStep(name, pass.Steps, s)
+			if err != nil {
+				return nil, err
+			}
+		}
+		merged.Workflow[name] = pass
+	}
+
+	return merged, nil
+}
+
+// overlayStep replaces the step in steps which matches the overlay step.
+func overlayStep(pass string, steps []step.Step, s step.Step) error {
+	key, match := overlayMatcher(s)
+	if key == "" {
+		return noderr.Wrap(fmt.Errorf("overlay step in pass %s must have an id or name to be merged with the base workflow", pass), s.Node)
+	}
+
+	var matches []*step.Step
+	findSteps(steps, match, &matches)
+
+	switch len(matches) {
+	case 0:
+		return noderr.Wrap(fmt.Errorf("overlay step %q does not match any step in pass %s of the base workflow", key, pass), s.Node)
+	case 1:
+		s.Pass = pass
+		*matches[0] = s
+		return nil
+	}
+	return noderr.Wrap(fmt.Errorf("overlay step %q matches %d steps in pass %s of the base workflow: add an id to the steps to merge them", key, len(matches), pass), s.Node)
+}
+
+// overlayMatcher returns the key that an overlay step is matched by, and a function
+// which returns true if a base step matches it. The key is empty if the step can't be matched.
+func overlayMatcher(s step.Step) (string, func(step.Step) bool) {
+	if r, ok := s.Body.(step.Ref); ok {
+		return r.Node.ID, func(b step.Step) bool {
+			br, ok := b.Body.(step.Ref)
+			return ok && br.Node.ID == r.Node.ID
+		}
+	}
+	if s.ID != "" {
+		return s.ID, func(b step.Step) bool { return b.ID == s.ID }
+	}
+	if s.Name != "" {
+		return s.Name, func(b step.Step) bool { return b.ID == "" && b.Name == s.Name }
+	}
+	return "", nil
+}
+
+// findSteps appends the steps which match, including nested steps.
+// Steps nested in a matching step aren't searched, as they will be replaced.
+func findSteps(steps []step.Step, match func(step.Step) bool, matches *[]*step.Step) {
+	for i := range steps {
+		if match(steps[i]) {
+			*matches = append(*matches, &steps[i])
+			continue
+		}
+		findSteps(steps[i].Children, match, matches)
+		findSteps(steps[i].OnFail.Steps, match, matches)
+	}
+}
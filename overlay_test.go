@@ -0,0 +1,184 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalWithOverlays(t *testing.T) {
+	base := `
+version: v1
+workflow:
+  default:
+    steps:
+      - start: request
+      - or:
+        - name: Auto approval
+          check: input.oncall
+        - id: approval
+          action: my_action
+          with:
+            property: staging
+      - outcome: approved
+  break_glass:
+    steps:
+      - start: request
+      - outcome: approved
+`
+
+	// the approved outcome has its priority from the dialect.
+	approved := step.Step{Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: "approved", Priority: 1}}}
+
+	tests := []struct {
+		name     string
+		overlays []string
+		want     *Program
+		wantErr  string
+	}{
+		{
+			name: "no overlays",
+			want: NewProgram().
+				Pass("default",
+					s.Start("request"),
+					s.Boolean(step.Or,
+						s.Named("Auto approval").Check("input.oncall"),
+						s.WithID("approval").Action("my_action", &testAction{Property: "staging"}),
+					),
+					approved,
+				).
+				Pass("break_glass", s.Start("request"), approved),
+		},
+		{
+			name: "replace steps by id and name",
+			overlays: []string{`
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: approval
+        action: my_action
+        with:
+          property: prod
+      - name: Auto approval
+        check: input.oncall && input.verified
+`},
+			want: NewProgram().
+				Pass("default",
+					s.Start("request"),
+					s.Boolean(step.Or,
+						s.Named("Auto approval").Check("input.oncall && input.verified"),
+						s.WithID("approval").Action("my_action", &testAction{Property: "prod"}),
+					),
+					approved,
+				).
+				Pass("break_glass", s.Start("request"), approved),
+		},
+		{
+			name: "overlays are applied in order",
+			overlays: []string{`
+workflow:
+  default:
+    steps:
+      - id: approval
+        action: my_action
+        with:
+          property: prod
+`, `
+workflow:
+  default:
+    steps:
+      - id: approval
+        action: my_action
+        with:
+          property: eu
+  extra:
+    steps:
+      - start: request
+      - outcome: approved
+`},
+			want: NewProgram().
+				Pass("default",
+					s.Start("request"),
+					s.Boolean(step.Or,
+						s.Named("Auto approval").Check("input.oncall"),
+						s.WithID("approval").Action("my_action", &testAction{Property: "eu"}),
+					),
+					approved,
+				).
+				Pass("break_glass", s.Start("request"), approved).
+				Pass("extra", s.Start("request"), approved),
+		},
+		{
+			name: "unmatched step",
+			overlays: []string{`
+workflow:
+  default:
+    steps:
+      - id: missing
+        check: "true"
+`},
+			wantErr: `overlay 0: overlay step "missing" does not match any step in pass default of the base workflow`,
+		},
+		{
+			name: "step without id or name",
+			overlays: []string{`
+workflow:
+  default:
+    steps:
+      - check: "true"
+`},
+			wantErr: "overlay 0: overlay step in pass default must have an id or name to be merged with the base workflow",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var overlays [][]byte
+			for _, o := range tt.overlays {
+				overlays = append(overlays, []byte(o))
+			}
+
+			got, err := UnmarshalWithOverlays([]byte(base), overlays, yamlTestDialect)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "v1", got.Version)
+			statementsEqual(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyOverlay_AmbiguousName(t *testing.T) {
+	base := SimpleProgram(
+		s.Start("request"),
+		s.Named("review").Check("input.a"),
+		s.Named("review").Check("input.b"),
+		s.Outcome("approved"),
+	)
+	overlay := SimpleProgram(s.Named("review").Check("input.c"))
+
+	_, err := ApplyOverlay(base, overlay)
+	assert.EqualError(t, err, `overlay step "review" matches 2 steps in pass default of the base workflow: add an id to the steps to merge them`)
+}
+
+func TestApplyOverlay_BaseNotModified(t *testing.T) {
+	base := SimpleProgram(
+		s.Start("request"),
+		s.Boolean(step.Or, s.Named("review").Check("input.a")),
+		s.Outcome("approved"),
+	)
+	overlay := SimpleProgram(s.Named("review").Check("input.b"))
+
+	_, err := ApplyOverlay(base, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, step.Check{Expression: "input.a"}, base.Workflow["default"].Steps[1].Children[0].Body)
+}
@@ -0,0 +1,286 @@
+package glide
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/pkg/errors"
+)
+
+// Tree is the syntax tree of a workflow definition, before its steps
+// are resolved against a dialect. Tooling such as formatters, linters
+// and converters can work with a tree without knowing the dialect
+// the workflow is written in.
+//
+// Trees are created with Parse, and resolved into a Program with Resolve.
+type Tree struct {
+	// Version is the top-level 'version' field.
+	Version string
+
+	// DefaultOutcome is the top-level 'default_outcome' field.
+	DefaultOutcome string
+
+	// Preconditions are the steps of the top-level 'preconditions' field.
+	Preconditions []RawStep
+
+	// Passes are the passes of the workflow, keyed by pass ID.
+	Passes map[string]TreePass
+}
+
+// TreePass is a pass in a syntax tree.
+type TreePass struct {
+	id string
+
+	// Steps are the steps of the pass, in the order they were written.
+	Steps []RawStep
+
+	// Matrix is the 'matrix' field of the pass.
+	Matrix Matrix
+}
+
+// RawStep is a step in a syntax tree, which hasn't been resolved
+// against a dialect. Nested steps, such as the children of an 'and'
+// step, are part of the step's node.
+type RawStep struct {
+	// Keys are the keys of the step, in the order they were written,
+	// e.g. ["name", "check"].
+	Keys []string
+
+	// Node is the YAML node of the step. Its path is
+	// relative to the root of the YAML document.
+	Node ast.Node
+}
+
+// Parse a glide workflow YAML file into a syntax tree, without
+// resolving its steps against a dialect.
+//
+// Workflows may be untrusted, so definitions which are nested too
+// deeply or which expand to too many nodes through aliases are rejected.
+func Parse(data []byte) (tree *Tree, err error) {
+	f, anchors, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// steps are decoded from the nodes of the workflow, so aliases
+	// are expanded first to give each use of an anchor its own nodes.
+	if anchors {
+		data, err = expandAnchors(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the YAML library re-parses nodes when decoding them with UnmarshalYAML
+	// methods, and can panic on malformed input which it parsed successfully
+	// the first time, so panics are returned as errors.
+	defer func() {
+		if r := recover(); r != nil {
+			tree = nil
+			err = fmt.Errorf("invalid YAML: %v", r)
+		}
+	}()
+
+	return parseTree(data)
+}
+
+// Resolve the steps of a syntax tree against a dialect,
+// producing a program which can be compiled.
+// It is shorthand for NewDecoder(d, opts...).Resolve(tree).
+func Resolve(tree *Tree, d dialect.Dialect, opts ...UnmarshalOption) (*Program, error) {
+	return NewDecoder(d, opts...).Resolve(tree)
+}
+
+// parseTree parses the syntax tree of a YAML document.
+func parseTree(b []byte) (*Tree, error) {
+	var tmp struct {
+		Version        string              `yaml:"version"`
+		DefaultOutcome string              `yaml:"default_outcome"`
+		Preconditions  ast.Node            `yaml:"preconditions"`
+		Workflow       map[string]ast.Node `yaml:"workflow"`
+	}
+
+	err := yaml.Unmarshal(b, &tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	t := Tree{
+		Version:        tmp.Version,
+		DefaultOutcome: tmp.DefaultOutcome,
+		Passes:         map[string]TreePass{},
+	}
+
+	if tmp.Preconditions != nil {
+		t.Preconditions, err = parseRawSteps(PreconditionsPass, tmp.Preconditions, "$")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for id, node := range tmp.Workflow {
+		if node == nil {
+			continue
+		}
+
+		pass := TreePass{id: id}
+
+		// set up a new decoder. Usually we'd provide the bytes to be
+		// read in the buffer, but because we're only using
+		// DecodeFromNode (which doesn't need the buffer)
+		// it can be empty.
+		dec := yaml.NewDecoder(&bytes.Buffer{})
+
+		err = dec.DecodeFromNode(node, &pass)
+		if err != nil {
+			return nil, err
+		}
+
+		t.Passes[id] = pass
+	}
+
+	return &t, nil
+}
+
+func (p *TreePass) UnmarshalYAML(b []byte) error {
+	// the YAML structure looks like this
+	//
+	// workflow:			<- tree
+	//   default:			<- pass
+	//    steps:
+	//      - start: A		<- step
+	//      - outcome: B	<- step
+	//
+
+	// parse the 'steps' field of the pass.
+	var nodeMap map[string]ast.Node
+	err := yaml.Unmarshal(b, &nodeMap)
+	if err != nil {
+		return errors.Wrapf(err, "path %s must contain a 'steps' field", p.id)
+	}
+
+	node, ok := nodeMap["steps"]
+	if !ok || node == nil {
+		return fmt.Errorf("path %s must contain a 'steps' field", p.id)
+	}
+
+	if matrixNode, ok := nodeMap["matrix"]; ok && matrixNode != nil {
+		p.Matrix, err = parseMatrix(matrixNode)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.Steps, err = parseRawSteps(p.id, node, "$.workflow."+p.id)
+	return err
+}
+
+// parseRawSteps parses a list of steps in a pass. The paths of the
+// step nodes are prefixed with pathPrefix, so that they are relative to
+// the root of the YAML document.
+func parseRawSteps(pass string, node ast.Node, pathPrefix string) ([]RawStep, error) {
+	// a pass should contain an array of steps
+	var nodes []ast.Node
+
+	err := yaml.NodeToValue(node, &nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []RawStep
+	for i, n := range nodes {
+		if n == nil {
+			return nil, fmt.Errorf("path %s: step %d must not be empty", pass, i)
+		}
+		fullPath := strings.Replace(n.GetPath(), "$", pathPrefix, 1)
+		n.SetPath(fullPath)
+
+		steps = append(steps, RawStep{Keys: rawStepKeys(n), Node: n})
+	}
+
+	return steps, nil
+}
+
+// rawStepKeys returns the keys of a step node, in the order they were written.
+// Steps which aren't mappings have no keys, and are rejected when resolved.
+func rawStepKeys(n ast.Node) []string {
+	var values []*ast.MappingValueNode
+	switch n := n.(type) {
+	case *ast.MappingNode:
+		values = n.Values
+	case *ast.MappingValueNode:
+		values = []*ast.MappingValueNode{n}
+	}
+
+	var keys []string
+	for _, mv := range values {
+		key := mv.Key.String()
+		if sn, ok := mv.Key.(ast.ScalarNode); ok {
+			key = fmt.Sprint(sn.GetValue())
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// resolveTree resolves the steps of a syntax tree against the dialect.
+// The context must contain the dialect, and any variables to interpolate.
+func resolveTree(ctx context.Context, d dialect.Dialect, t *Tree) (*Program, error) {
+	err := d.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	p := Program{
+		Workflow:       map[string]Path{},
+		Version:        t.Version,
+		DefaultOutcome: t.DefaultOutcome,
+		Dialect:        &d,
+	}
+
+	if t.Preconditions != nil {
+		p.Preconditions, err = resolveSteps(ctx, PreconditionsPass, t.Preconditions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for id, pass := range t.Passes {
+		steps, err := resolveSteps(ctx, id, pass.Steps)
+		if err != nil {
+			return nil, err
+		}
+		p.Workflow[id] = Path{id: id, Steps: steps, Matrix: pass.Matrix}
+	}
+
+	return &p, nil
+}
+
+// resolveSteps decodes the steps of a pass with the dialect in the context.
+func resolveSteps(ctx context.Context, pass string, raw []RawStep) ([]step.Step, error) {
+	var steps []step.Step
+	for _, r := range raw {
+		s := step.Step{Pass: pass, Node: r.Node}
+
+		// set up a new decoder. Usually we'd provide the bytes to be
+		// read in the buffer, but because we're only using
+		// DecodeFromNodeContext (which doesn't need the buffer)
+		// it can be empty.
+		dec := yaml.NewDecoder(&bytes.Buffer{})
+
+		err := dec.DecodeFromNodeContext(ctx, r.Node, &s)
+		if err != nil {
+			return nil, err
+		}
+
+		steps = append(steps, s)
+	}
+
+	return steps, nil
+}
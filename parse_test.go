@@ -0,0 +1,82 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`
+version: "2"
+preconditions:
+  - check: input.active
+workflow:
+  default:
+    steps:
+      - start: request
+      - name: Approval
+        custom_action: {}
+      - outcome: approved
+`)
+
+	// parsing doesn't need a dialect, so steps which
+	// no dialect defines are kept with their raw keys.
+	tree, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "2", tree.Version)
+	assert.Equal(t, []string{"check"}, tree.Preconditions[0].Keys)
+
+	steps := tree.Passes["default"].Steps
+	assert.Len(t, steps, 3)
+	assert.Equal(t, []string{"start"}, steps[0].Keys)
+	assert.Equal(t, []string{"name", "custom_action"}, steps[1].Keys)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte(`
+workflow:
+  default:
+    matrix:
+      team: [a, b]
+`))
+	assert.EqualError(t, err, "path default must contain a 'steps' field")
+}
+
+func TestResolve(t *testing.T) {
+	tree, err := Parse([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: input.approved
+      - action: my_action
+        with:
+          property: value
+      - outcome: approved
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Resolve(tree, yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, step.Check{Expression: "input.approved"}, got.Workflow["default"].Steps[1].Body)
+
+	// the tree isn't modified, so it can be resolved again, with another dialect.
+	_, err = Resolve(tree, *dialect.New())
+	assert.EqualError(t, err, "no actions are defined for this Glide dialect")
+
+	again, err := Resolve(tree, yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "$.workflow.default.steps[1].check", again.Workflow["default"].Steps[1].Node.GetPath())
+}
@@ -0,0 +1,63 @@
+package glide
+
+import (
+	"sort"
+	"time"
+
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// PendingAction is an active action which isn't complete, such as an
+// approval which is waiting for an approver. Host applications can use
+// the schedule of pending actions to send reminders and escalations,
+// without parsing the workflow themselves.
+type PendingAction struct {
+	// Step is the hash of the action's vertex.
+	Step string
+
+	// Label is the name of the step, or what the action does if it has no name.
+	Label string
+
+	// Action is the type of the action, e.g. 'approval'.
+	Action string
+
+	// RemindEvery is how often the approvers should be reminded,
+	// from the 'remind_every' field of the step. Zero if not set.
+	RemindEvery time.Duration
+
+	// EscalateAfter is how long the action can be pending before it should
+	// be escalated, from the 'escalate_after' field of the step. Zero if not set.
+	EscalateAfter time.Duration
+}
+
+// pendingActions returns the actions which are active but not complete,
+// sorted by their hash.
+func (g *Graph) pendingActions(state map[string]State) ([]PendingAction, error) {
+	var hashes []string
+	for k, s := range state {
+		if s == Active {
+			hashes = append(hashes, k)
+		}
+	}
+	sort.Strings(hashes)
+
+	var pending []PendingAction
+	for _, k := range hashes {
+		s, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+		a, ok := s.Body.(step.Action)
+		if !ok {
+			continue
+		}
+		pending = append(pending, PendingAction{
+			Step:          k,
+			Label:         actionLabel(s),
+			Action:        a.Name,
+			RemindEvery:   s.RemindEvery,
+			EscalateAfter: s.EscalateAfter,
+		})
+	}
+	return pending, nil
+}
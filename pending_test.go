@@ -0,0 +1,81 @@
+package glide
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult_Pending(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - name: Manager approval
+        action: my_action
+        remind_every: 1d
+        escalate_after: 3d
+      - action: my_action
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Compiler{Program: p}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []PendingAction{
+		{
+			Step:          "default.1",
+			Label:         "Manager approval",
+			Action:        "my_action",
+			RemindEvery:   24 * time.Hour,
+			EscalateAfter: 72 * time.Hour,
+		},
+	}, res.Pending)
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), `"pending":[{"step":"default.1","label":"Manager approval","action":"my_action","remindEvery":86400,"escalateAfter":259200}]`)
+}
+
+func TestResult_PendingErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr string
+	}{
+		{
+			name:    "not an action",
+			give:    "check: \"true\"\n        remind_every: 1d",
+			wantErr: "remind_every can only be used on action steps",
+		},
+		{
+			name:    "invalid duration",
+			give:    "action: my_action\n        escalate_after: soon",
+			wantErr: `invalid escalate_after: "soon" must be a duration like '2d' or '1d12h'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := "workflow:\n  default:\n    steps:\n      - start: request\n      - " + tt.give + "\n      - outcome: approved\n"
+			_, err := Unmarshal([]byte(workflow), yamlTestDialect)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
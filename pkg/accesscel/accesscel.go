@@ -0,0 +1,163 @@
+// Package accesscel is a library of CEL functions for writing checks
+// on access requests, such as matching email domains and role names.
+//
+// The library is registered with glide.WithAccessFunctions,
+// or with cel.NewEnv(accesscel.Library()) for other environments.
+package accesscel
+
+import (
+	"strings"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// Library returns the access-control functions as a CEL environment option:
+//
+//	anyIn(list, list) bool         - whether any element of the first list is in the second
+//	allIn(list, list) bool         - whether every element of the first list is in the second
+//	emailDomain(string) string     - the lowercased domain of an email address
+//	matchesGlob(string, string) bool - whether a string matches a glob pattern,
+//	                                 where '*' matches any characters and '?' one character
+//	parseDuration(string) duration - a duration such as '1h30m', which may also use
+//	                                 days ('d') and weeks ('w'), written like step estimates
+//
+// The functions are pure, so checks which call them with
+// constant arguments are still folded by the compiler.
+func Library() cel.EnvOption {
+	return cel.Lib(library{})
+}
+
+type library struct{}
+
+func (library) CompileOptions() []cel.EnvOption {
+	list := cel.ListType(cel.DynType)
+	return []cel.EnvOption{
+		cel.Function("anyIn",
+			cel.Overload("anyIn_list_list", []*cel.Type{list, list}, cel.BoolType,
+				cel.BinaryBinding(anyIn))),
+		cel.Function("allIn",
+			cel.Overload("allIn_list_list", []*cel.Type{list, list}, cel.BoolType,
+				cel.BinaryBinding(allIn))),
+		cel.Function("emailDomain",
+			cel.Overload("emailDomain_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(emailDomain))),
+		cel.Function("matchesGlob",
+			cel.Overload("matchesGlob_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(matchesGlob))),
+		cel.Function("parseDuration",
+			cel.Overload("parseDuration_string", []*cel.Type{cel.StringType}, cel.DurationType,
+				cel.UnaryBinding(parseDuration))),
+	}
+}
+
+func (library) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+func anyIn(lhs, rhs ref.Val) ref.Val {
+	return containsElements(lhs, rhs, true)
+}
+
+func allIn(lhs, rhs ref.Val) ref.Val {
+	return containsElements(lhs, rhs, false)
+}
+
+// containsElements returns whether any (or all) of the elements
+// of the first list are contained in the second.
+func containsElements(lhs, rhs ref.Val, matchAny bool) ref.Val {
+	a, ok := lhs.(traits.Lister)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(lhs)
+	}
+	b, ok := rhs.(traits.Lister)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(rhs)
+	}
+
+	it := a.Iterator()
+	for it.HasNext() == types.True {
+		found := b.Contains(it.Next())
+		if types.IsError(found) {
+			return found
+		}
+		if matchAny && found == types.True {
+			return types.True
+		}
+		if !matchAny && found != types.True {
+			return types.False
+		}
+	}
+	return types.Bool(!matchAny)
+}
+
+func emailDomain(val ref.Val) ref.Val {
+	s, ok := val.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(val)
+	}
+	i := strings.LastIndex(string(s), "@")
+	if i < 0 || i == len(s)-1 {
+		return types.NewErr("emailDomain: %q is not an email address", string(s))
+	}
+	return types.String(strings.ToLower(string(s[i+1:])))
+}
+
+func matchesGlob(lhs, rhs ref.Val) ref.Val {
+	s, ok := lhs.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(lhs)
+	}
+	pattern, ok := rhs.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(rhs)
+	}
+	return types.Bool(MatchGlob(string(s), string(pattern)))
+}
+
+// MatchGlob reports whether s matches the pattern, where '*' matches
+// any sequence of characters (including '/' and ':', unlike path.Match,
+// so that patterns can match ARNs and URLs) and '?' matches a single character.
+func MatchGlob(s, pattern string) bool {
+	str, pat := []rune(s), []rune(pattern)
+
+	// the position of the last '*' in the pattern, and
+	// the position in the string it has matched up to.
+	star, match := -1, 0
+	i, j := 0, 0
+	for i < len(str) {
+		switch {
+		case j < len(pat) && (pat[j] == '?' || pat[j] == str[i]):
+			i++
+			j++
+		case j < len(pat) && pat[j] == '*':
+			star, match = j, i
+			j++
+		case star >= 0:
+			// backtrack, so that the last '*' matches one more character.
+			match++
+			i, j = match, star+1
+		default:
+			return false
+		}
+	}
+	for j < len(pat) && pat[j] == '*' {
+		j++
+	}
+	return j == len(pat)
+}
+
+func parseDuration(val ref.Val) ref.Val {
+	s, ok := val.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(val)
+	}
+	d, err := step.ParseEstimate(string(s))
+	if err != nil {
+		return types.NewErr("parseDuration: %s", err)
+	}
+	return types.Duration{Duration: d}
+}
@@ -0,0 +1,87 @@
+package accesscel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibrary(t *testing.T) {
+	env, err := cel.NewEnv(Library(), cel.Variable("input", cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := map[string]any{
+		"groups": []string{"developers", "admins"},
+		"email":  "Alice@Example.COM",
+		"role":   "arn:aws:iam::123456789012:role/admin-readonly",
+		"for":    "1d12h",
+	}
+
+	tests := []struct {
+		expr    string
+		want    any
+		wantErr string
+	}{
+		{expr: `anyIn(input.groups, ["admins", "security"])`, want: true},
+		{expr: `anyIn(input.groups, ["security"])`, want: false},
+		{expr: `anyIn([], ["security"])`, want: false},
+		{expr: `allIn(input.groups, ["developers", "admins", "security"])`, want: true},
+		{expr: `allIn(input.groups, ["admins"])`, want: false},
+		{expr: `emailDomain(input.email) == "example.com"`, want: true},
+		{expr: `emailDomain("nobody")`, wantErr: `emailDomain: "nobody" is not an email address`},
+		{expr: `matchesGlob(input.role, "arn:aws:iam::*:role/admin-*")`, want: true},
+		{expr: `matchesGlob(input.role, "arn:aws:iam::*:role/dev-*")`, want: false},
+		{expr: `parseDuration(input.for) > duration("24h")`, want: true},
+		{expr: `parseDuration("2w")`, want: 14 * 24 * time.Hour},
+		{expr: `parseDuration("1w2d3h")`, want: (9*24 + 3) * time.Hour},
+		{expr: `parseDuration("1h30m")`, want: 90 * time.Minute},
+		{expr: `parseDuration("soon")`, wantErr: `parseDuration: "soon" must be a duration like '2d' or '1d12h', using the units w, d, h, m and s`},
+		{expr: `parseDuration("")`, wantErr: `parseDuration: must not be empty`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tt.expr)
+			if iss.Err() != nil {
+				t.Fatal(iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, err := prg.Eval(map[string]any{"input": input})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got.Value())
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		s, pattern string
+		want       bool
+	}{
+		{"prod-admin", "prod-*", true},
+		{"prod-admin", "*-admin", true},
+		{"prod-admin", "prod-?dmin", true},
+		{"prod-admin", "dev-*", false},
+		{"a/b/c", "a*c", true},
+		{"", "*", true},
+		{"", "?", false},
+		{"abc", "abc", true},
+		{"abcd", "abc", false},
+		{"aaab", "*a*b", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchGlob(tt.s, tt.pattern), "%q %q", tt.s, tt.pattern)
+	}
+}
@@ -0,0 +1,384 @@
+// Package bundle contains the Glide policy bundle format.
+//
+// A bundle packages a workflow with its input schema, the name of the
+// dialect it was written in, and tests, so that a policy can be
+// distributed and versioned as a single artifact.
+//
+// Bundles are gzipped tar archives containing the following files:
+//
+//	bundle.yml    the bundle manifest (required)
+//	workflow.yml  the Glide workflow (required)
+//	schema.json   the input schema, in JSON schema format (required)
+//	tests.yml     tests for the workflow (optional)
+//
+// Bundles can be signed with an ed25519 key. The signature is stored in a
+// detached file next to the bundle, and checked with ReadSigned or OpenSigned.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/common-fate/glide"
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/goccy/go-yaml"
+)
+
+// The names of the files contained in a bundle.
+const (
+	ManifestFile = "bundle.yml"
+	WorkflowFile = "workflow.yml"
+	SchemaFile   = "schema.json"
+	TestsFile    = "tests.yml"
+)
+
+// maxFileSize is the maximum size of a file in a bundle.
+const maxFileSize = 10 << 20 // 10 MiB
+
+// Manifest describes a bundle.
+type Manifest struct {
+	// Name of the policy, e.g. 'access-approvals'.
+	Name string `yaml:"name"`
+	// Version of the policy, e.g. 'v1.2.0'.
+	Version string `yaml:"version"`
+	// Dialect is the name of the Glide dialect
+	// that the workflow is written in, e.g. 'cf'.
+	Dialect string `yaml:"dialect"`
+}
+
+// Test is an example input for the workflow,
+// along with the outcome that it is expected to reach.
+type Test struct {
+	Name string `json:"name"`
+	// Start is the ID of the start node to execute from.
+	// If empty, the only start node in the dialect is used.
+	Start string `json:"start"`
+	// Input to execute the workflow with.
+	Input map[string]any `json:"input"`
+	// Outcome is the expected outcome. If the workflow has a default outcome,
+	// it's the expected outcome when no other outcome is reached. If empty, the
+	// workflow is expected to not reach an outcome, which is only possible for
+	// workflows without a default outcome.
+	Outcome string `json:"outcome"`
+}
+
+// Bundle is a packaged Glide policy.
+type Bundle struct {
+	Manifest Manifest
+	Workflow []byte
+	Schema   []byte
+	Tests    []Test
+}
+
+// Load reads the bundle files from a directory.
+// The tests file is optional.
+func Load(dir string) (*Bundle, error) {
+	files := map[string][]byte{}
+	for _, f := range []string{ManifestFile, WorkflowFile, SchemaFile, TestsFile} {
+		data, err := os.ReadFile(filepath.Join(dir, f))
+		if f == TestsFile && errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[f] = data
+	}
+	return fromFiles(files)
+}
+
+// Open reads a bundle archive from a file.
+func Open(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read a bundle archive.
+func Read(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle: %w", err)
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle: %w", err)
+		}
+
+		switch hdr.Name {
+		case ManifestFile, WorkflowFile, SchemaFile, TestsFile:
+		default:
+			return nil, fmt.Errorf("unexpected file in bundle: %s", hdr.Name)
+		}
+
+		if _, ok := files[hdr.Name]; ok {
+			return nil, fmt.Errorf("duplicate file in bundle: %s", hdr.Name)
+		}
+
+		if hdr.Size > maxFileSize {
+			return nil, fmt.Errorf("%s is larger than the maximum size of %d bytes", hdr.Name, maxFileSize)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s from bundle: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = data
+	}
+
+	return fromFiles(files)
+}
+
+// fromFiles builds a bundle from the contents of its files.
+func fromFiles(files map[string][]byte) (*Bundle, error) {
+	for _, f := range []string{ManifestFile, WorkflowFile, SchemaFile} {
+		if _, ok := files[f]; !ok {
+			return nil, fmt.Errorf("bundle is missing %s", f)
+		}
+	}
+
+	b := Bundle{
+		Workflow: files[WorkflowFile],
+		Schema:   files[SchemaFile],
+	}
+
+	err := yaml.Unmarshal(files[ManifestFile], &b.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ManifestFile, err)
+	}
+	if b.Manifest.Name == "" {
+		return nil, fmt.Errorf("%s must contain a name", ManifestFile)
+	}
+	if b.Manifest.Dialect == "" {
+		return nil, fmt.Errorf("%s must contain a dialect", ManifestFile)
+	}
+
+	if tests, ok := files[TestsFile]; ok {
+		b.Tests, err = ParseTests(tests)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", TestsFile, err)
+		}
+	}
+
+	return &b, nil
+}
+
+// ParseTests parses tests in the format of the tests file,
+// which contains a list of tests under the 'tests' key.
+func ParseTests(data []byte) ([]Test, error) {
+	// the tests are converted to JSON before they are parsed, so that
+	// numbers in the test inputs are decoded in the same way as JSON inputs.
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var t struct {
+		Tests []Test `json:"tests"`
+	}
+	err = json.Unmarshal(data, &t)
+	if err != nil {
+		return nil, err
+	}
+	return t.Tests, nil
+}
+
+// files returns the contents of the files in the bundle.
+func (b *Bundle) files() (map[string][]byte, error) {
+	manifest, err := yaml.Marshal(b.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{
+		ManifestFile: manifest,
+		WorkflowFile: b.Workflow,
+		SchemaFile:   b.Schema,
+	}
+
+	if len(b.Tests) > 0 {
+		tests, err := json.MarshalIndent(map[string][]Test{"tests": b.Tests}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		// JSON is valid YAML.
+		files[TestsFile] = tests
+	}
+
+	return files, nil
+}
+
+// Write the bundle as an archive.
+// The archive is deterministic: writing the same bundle
+// twice produces identical bytes.
+func (b *Bundle) Write(w io.Writer) error {
+	files, err := b.files()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range names {
+		data := files[name]
+		err = tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Bytes returns the bundle archive.
+func (b *Bundle) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	err := b.Write(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Compile the workflow in the bundle using a dialect.
+func (b *Bundle) Compile(d dialect.Dialect) (*glide.Graph, error) {
+	p, err := glide.Unmarshal(b.Workflow, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema jsoncel.Schema
+	err = json.Unmarshal(b.Schema, &schema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", SchemaFile, err)
+	}
+
+	c := glide.Compiler{
+		Program:     p,
+		InputSchema: &schema,
+	}
+	return c.Compile()
+}
+
+// TestFailure is a bundle test which did not reach the expected outcome.
+type TestFailure struct {
+	Test Test
+	// Got is the outcome the workflow reached.
+	Got string
+	// Err is set if the workflow could not be executed.
+	Err error
+}
+
+func (f TestFailure) String() string {
+	if f.Err != nil {
+		return fmt.Sprintf("%s: %s", f.Test.Name, f.Err)
+	}
+	return fmt.Sprintf("%s: expected outcome %q but got %q", f.Test.Name, f.Test.Outcome, f.Got)
+}
+
+// VerifyError is returned by Verify if any of the bundle tests fail.
+type VerifyError struct {
+	Failures []TestFailure
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%d of the bundle tests failed", len(e.Failures))
+}
+
+// Verify compiles the workflow in the bundle and runs the bundle tests.
+// If any of the tests fail, a *VerifyError is returned.
+func (b *Bundle) Verify(d dialect.Dialect) error {
+	g, err := b.Compile(d)
+	if err != nil {
+		return err
+	}
+
+	var failures []TestFailure
+
+	for _, t := range b.Tests {
+		start := t.Start
+		if start == "" {
+			start, err = startNode(d)
+			if err != nil {
+				return fmt.Errorf("test %s: %w", t.Name, err)
+			}
+		}
+
+		input := t.Input
+		if input == nil {
+			input = map[string]any{}
+		}
+
+		res, err := g.Execute(start, input)
+		if err != nil {
+			failures = append(failures, TestFailure{Test: t, Err: err})
+			continue
+		}
+		if res.Outcome != t.Outcome {
+			failures = append(failures, TestFailure{Test: t, Got: res.Outcome})
+		}
+	}
+
+	if len(failures) > 0 {
+		return &VerifyError{Failures: failures}
+	}
+	return nil
+}
+
+// startNode returns the ID of the start node in a dialect.
+// It returns an error if the dialect does not have exactly one start node.
+func startNode(d dialect.Dialect) (string, error) {
+	var starts []string
+	for id, n := range d.Nodes {
+		if n.Type == node.Start {
+			starts = append(starts, id)
+		}
+	}
+	if len(starts) != 1 {
+		sort.Strings(starts)
+		return "", fmt.Errorf("a start node must be specified, as the dialect has %d start nodes %v", len(starts), starts)
+	}
+	return starts[0], nil
+}
@@ -0,0 +1,177 @@
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/stretchr/testify/assert"
+)
+
+const testWorkflow = `
+workflow:
+  on_call:
+    steps:
+      - start: request
+      - check: input.on_call
+      - outcome: approved
+`
+
+const testSchema = `{
+  "type": "object",
+  "properties": {
+    "on_call": {
+      "type": "boolean"
+    }
+  }
+}`
+
+func testFiles(tests string) map[string]string {
+	files := map[string]string{
+		ManifestFile: "name: test\nversion: v1.0.0\ndialect: cf\n",
+		WorkflowFile: testWorkflow,
+		SchemaFile:   testSchema,
+	}
+	if tests != "" {
+		files[TestsFile] = tests
+	}
+	return files
+}
+
+func writeDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir := writeDir(t, testFiles(`
+tests:
+  - name: on call
+    input:
+      on_call: true
+    outcome: approved
+`))
+
+	b, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, b, got)
+
+	// writing the bundle again must produce identical bytes.
+	again, err := got.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, data, again)
+}
+
+func TestLoad(t *testing.T) {
+	type testcase struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}
+
+	missingSchema := testFiles("")
+	delete(missingSchema, SchemaFile)
+
+	noDialect := testFiles("")
+	noDialect[ManifestFile] = "name: test\n"
+
+	testcases := []testcase{
+		{name: "ok", files: testFiles("")},
+		{name: "missing schema", files: missingSchema, wantErr: "open"},
+		{name: "no dialect", files: noDialect, wantErr: "bundle.yml must contain a dialect"},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeDir(t, tt.files))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	type testcase struct {
+		name         string
+		tests        string
+		wantFailures []string
+	}
+
+	testcases := []testcase{
+		{
+			name: "passing",
+			tests: `
+tests:
+  - name: on call
+    input:
+      on_call: true
+    outcome: approved
+  - name: not on call
+    input:
+      on_call: false
+`,
+		},
+		{
+			name: "failing",
+			tests: `
+tests:
+  - name: not on call
+    input:
+      on_call: false
+    outcome: approved
+`,
+			wantFailures: []string{`not on call: expected outcome "approved" but got ""`},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Load(writeDir(t, testFiles(tt.tests)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = b.Verify(cf.Dialect)
+			if tt.wantFailures == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var ve *VerifyError
+			if !errors.As(err, &ve) {
+				t.Fatalf("expected a VerifyError but got %v", err)
+			}
+			var got []string
+			for _, f := range ve.Failures {
+				got = append(got, f.String())
+			}
+			assert.Equal(t, tt.wantFailures, got)
+		})
+	}
+}
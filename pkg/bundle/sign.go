@@ -0,0 +1,141 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrInvalidSignature is returned if a bundle signature
+// was not made by any of the trusted keys.
+var ErrInvalidSignature = errors.New("bundle signature is not valid for any of the trusted keys")
+
+// SignatureExt is the file extension for detached bundle signatures.
+// The signature for 'bundle.tar.gz' is stored in 'bundle.tar.gz.sig'.
+const SignatureExt = ".sig"
+
+// GenerateKey generates a new ed25519 key pair for signing bundles.
+func GenerateKey() (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	return ed25519.GenerateKey(rand.Reader)
+}
+
+// Sign a bundle archive with a private key.
+// The signature is returned base64 encoded, so that it
+// can be stored in a detached signature file.
+func Sign(archive []byte, key ed25519.PrivateKey) []byte {
+	sig := ed25519.Sign(key, archive)
+	return []byte(base64.StdEncoding.EncodeToString(sig))
+}
+
+// VerifySignature returns ErrInvalidSignature if the signature for
+// the bundle archive was not made by any of the trusted keys.
+// It returns an error if any of the trusted keys isn't a valid ed25519 key.
+func VerifySignature(archive, signature []byte, trusted ...ed25519.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("decoding bundle signature: %w", err)
+	}
+
+	for i, key := range trusted {
+		// ed25519.Verify panics if the key isn't the right length.
+		if len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("trusted key %d must be %d bytes (got %d)", i, ed25519.PublicKeySize, len(key))
+		}
+	}
+
+	for _, key := range trusted {
+		if ed25519.Verify(key, archive, sig) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// ReadSigned reads a bundle archive, after checking that
+// it was signed by one of the trusted keys.
+// Servers should use ReadSigned or OpenSigned to only execute
+// workflows from trusted publishers.
+func ReadSigned(archive, signature []byte, trusted ...ed25519.PublicKey) (*Bundle, error) {
+	if len(trusted) == 0 {
+		return nil, errors.New("at least one trusted key must be provided")
+	}
+	err := VerifySignature(archive, signature, trusted...)
+	if err != nil {
+		return nil, err
+	}
+	return Read(bytes.NewReader(archive))
+}
+
+// OpenSigned reads a bundle archive from a file, after checking that it was
+// signed by one of the trusted keys. The signature is read from
+// the detached signature file next to the bundle.
+func OpenSigned(path string, trusted ...ed25519.PublicKey) (*Bundle, error) {
+	archive, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := os.ReadFile(path + SignatureExt)
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle signature: %w", err)
+	}
+	return ReadSigned(archive, signature, trusted...)
+}
+
+// MarshalPrivateKey encodes a private key in PEM format.
+func MarshalPrivateKey(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPublicKey encodes a public key in PEM format.
+func MarshalPublicKey(key ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKey parses a PEM encoded ed25519 private key.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key must be PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	k, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key must be an ed25519 key (got %T)", key)
+	}
+	return k, nil
+}
+
+// ParsePublicKey parses a PEM encoded ed25519 public key.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key must be PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	k, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key must be an ed25519 key (got %T)", key)
+	}
+	return k, nil
+}
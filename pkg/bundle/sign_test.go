@@ -0,0 +1,132 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSigned(t *testing.T) {
+	pub, priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, otherPriv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Load(writeDir(t, testFiles("")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := append([]byte{}, archive...)
+	tampered[len(tampered)-1] ^= 0xff
+
+	type testcase struct {
+		name      string
+		archive   []byte
+		signature []byte
+		trusted   []ed25519.PublicKey
+		wantErr   error
+		// wantErrMsg is checked if wantErr is nil.
+		wantErrMsg string
+	}
+
+	testcases := []testcase{
+		{
+			name:      "ok",
+			archive:   archive,
+			signature: Sign(archive, priv),
+			trusted:   []ed25519.PublicKey{pub},
+		},
+		{
+			name:      "signed by one of the trusted keys",
+			archive:   archive,
+			signature: Sign(archive, otherPriv),
+			trusted:   []ed25519.PublicKey{pub, otherPub},
+		},
+		{
+			name:      "untrusted key",
+			archive:   archive,
+			signature: Sign(archive, otherPriv),
+			trusted:   []ed25519.PublicKey{pub},
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "tampered archive",
+			archive:   tampered,
+			signature: Sign(archive, priv),
+			trusted:   []ed25519.PublicKey{pub},
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:       "trusted key with the wrong length",
+			archive:    archive,
+			signature:  Sign(archive, priv),
+			trusted:    []ed25519.PublicKey{pub, pub[:16]},
+			wantErrMsg: "trusted key 1 must be 32 bytes (got 16)",
+		},
+		{
+			name:       "empty trusted key",
+			archive:    archive,
+			signature:  Sign(archive, priv),
+			trusted:    []ed25519.PublicKey{nil},
+			wantErrMsg: "trusted key 0 must be 32 bytes (got 0)",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadSigned(tt.archive, tt.signature, tt.trusted...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, b, got)
+		})
+	}
+}
+
+func TestKeyEncoding(t *testing.T) {
+	pub, priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privPEM, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotPriv, err := ParsePrivateKey(privPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, priv, gotPriv)
+
+	pubPEM, err := MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotPub, err := ParsePublicKey(pubPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, pub, gotPub)
+
+	_, err = ParsePublicKey(privPEM)
+	assert.Error(t, err)
+}
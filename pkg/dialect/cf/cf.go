@@ -3,35 +3,155 @@
 package cf
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
 
 	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
 	"github.com/common-fate/glide/pkg/node"
+	"github.com/google/cel-go/cel"
 )
 
-var Dialect = dialect.Dialect{
-	Actions: actions,
-	Nodes: map[string]node.Node{
-		"request":  {Type: node.Start, Name: "Request"},
-		"approved": {Type: node.Outcome, Priority: 1, Name: "Approved"},
+// Dialect is the Common Fate dialect without any integrations configured.
+// Use New to configure integrations, like on-call schedules.
+var Dialect = New()
+
+// Option configures the integrations used by the dialect's actions.
+type Option func(*config)
+
+type config struct {
+	schedules Schedules
+	directory Directory
+	owners    Owners
+	outcomes  map[string]dialect.OutcomeHandler
+
+	// preventSelfApproval is set with WithSelfApprovalPrevented.
+	preventSelfApproval bool
+}
+
+// WithSchedules configures the on-call schedule providers used by 'oncall' actions.
+func WithSchedules(s Schedules) Option {
+	return func(c *config) {
+		c.schedules = s
+	}
+}
+
+// WithDirectory configures the directory used by 'manager_approval'
+// actions to look up the requestor's manager.
+func WithDirectory(d Directory) Option {
+	return func(c *config) {
+		c.directory = d
+	}
+}
+
+// WithOutcomeHandler configures the handler called when a workflow reaches
+// the outcome, such as granting access when a request is 'approved'.
+// Handlers are called by glide.Compiled.Drive.
+func WithOutcomeHandler(outcome string, h dialect.OutcomeHandler) Option {
+	return func(c *config) {
+		if c.outcomes == nil {
+			c.outcomes = map[string]dialect.OutcomeHandler{}
+		}
+		c.outcomes[outcome] = h
+	}
+}
+
+// New returns the Common Fate dialect, with integrations configured by the options.
+func New(opts ...Option) dialect.Dialect {
+	var c config
+	for _, o := range opts {
+		o(&c)
+	}
+
+	return dialect.Dialect{
+		Actions: c.actions,
+		Nodes: map[string]node.Node{
+			"request":  {Type: node.Start, Name: "Request"},
+			"approved": {Type: node.Outcome, Priority: 1, Name: "Approved"},
+		},
+		Outcomes:    c.outcomes,
+		Reducers:    reducers,
+		InputSchema: inputSchema,
+	}
+}
+
+// inputSchema declares the approvals of a request, which are read by
+// the approval actions, so that checks on them are type-checked.
+var inputSchema = &jsoncel.Schema{
+	Type: jsoncel.Object,
+	Properties: map[string]*jsoncel.Schema{
+		"approvals": {
+			Type: jsoncel.Array,
+			Items: &jsoncel.Schema{
+				Type: jsoncel.Object,
+				Properties: map[string]*jsoncel.Schema{
+					"user":   {Type: jsoncel.String},
+					"groups": {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+				},
+			},
+		},
 	},
 }
 
-func actions() map[string]any {
+func (c config) actions() map[string]any {
 	return map[string]any{
-		"approval": &Approval{},
+		"approval":         &Approval{directory: c.directory, preventSelfApproval: c.preventSelfApproval},
+		"justification":    &Justification{},
+		"manager_approval": &ManagerApproval{directory: c.directory, preventSelfApproval: c.preventSelfApproval},
+		"max_duration":     &MaxDuration{},
+		"oncall":           &OnCall{schedules: c.schedules},
+		"owner_approval":   &OwnerApproval{owners: c.owners, preventSelfApproval: c.preventSelfApproval},
+		"webhook":          &Webhook{},
 	}
 }
 
+// Approval is an action which is complete when members of the groups
+// have approved the request.
+//
+// For example, to require two approvals from different groups:
+//
+//	action: approval
+//	with:
+//	  groups: [admins, security]
+//	  count: 2
+//	  distinct_groups: true
+//	  exclude_requestor: true
 type Approval struct {
-	Groups []string `yaml:"groups"`
+	Groups []string `yaml:"groups" doc:"the groups which may approve the request"`
+
+	Count int `yaml:"count" doc:"the number of distinct users who must approve the request, which defaults to 1"`
+
+	DistinctGroups bool `yaml:"distinct_groups" doc:"if true, each approval must be counted for a different group"`
+
+	ExcludeRequestor bool `yaml:"exclude_requestor" doc:"if true, an approval by the requestor isn't counted, and the step isn't complete until the requestor is known"`
+
+	// directory is configured with WithDirectory. If it's a GroupDirectory,
+	// the groups are checked to exist when the workflow is compiled.
+	directory Directory
+
+	// preventSelfApproval is configured with WithSelfApprovalPrevented.
+	preventSelfApproval bool
 }
 
 type Input struct {
+	// Requestor is the email address of the user who requested access.
+	Requestor string `mapstructure:"requestor"`
+	// Justification is the business reason given for the request.
+	Justification string `mapstructure:"justification"`
+	// Duration is the length of access requested, e.g. '2h'.
+	Duration  string          `mapstructure:"duration"`
 	Approvals []ApprovalInput `mapstructure:"approvals"`
+	// Resource is the name of the requested resource, e.g. 'aws/prod/billing'.
+	// It is used by owner_approval actions to look up the resource's owners.
+	Resource string `mapstructure:"resource"`
+	// Owners is an ownership mapping used by owner_approval actions
+	// if the dialect isn't configured with one.
+	Owners Owners `mapstructure:"owners"`
+	// Callbacks are events from external systems called by webhook actions.
+	Callbacks []CallbackInput `mapstructure:"callbacks"`
 }
 
 type ApprovalInput struct {
@@ -39,30 +159,183 @@ type ApprovalInput struct {
 	Groups []string `mapstructure:"groups"`
 }
 
-// Complete returns true if an Approval step in a workflow is complete.
-func (a *Approval) Complete(input any) (bool, error) {
+// Complete returns true if an Approval step in a workflow is complete:
+// enough distinct users from the groups have approved the request.
+func (a *Approval) Complete(ctx context.Context, input any) (bool, error) {
 	var i Input
 	err := mapstructure.Decode(input, &i)
 	if err != nil {
 		return false, err
 	}
+	return a.approvers(i) != nil, nil
+}
 
-	for _, approval := range i.Approvals {
+// approvers returns the users who are counted as approving the step,
+// in the order they approved. It returns nil if the step isn't complete.
+func (a *Approval) approvers(i Input) []string {
+	required := map[string]bool{}
+	for _, g := range a.Groups {
+		required[g] = true
+	}
+
+	approvals := i.Approvals
+	if a.preventSelfApproval || a.ExcludeRequestor {
+		var ok bool
+		approvals, ok = approvalsExcludingRequestor(i)
+		if !ok {
+			// approvals can't be checked against an unknown requestor.
+			return nil
+		}
+	}
+
+	// eligible maps each user who approved to the required groups they
+	// approved as a member of. Users are compared case-insensitively,
+	// so each user is only counted once.
+	eligible := map[string][]string{}
+	var users []string
+	for _, approval := range approvals {
+		key := strings.ToLower(approval.User)
 		for _, g := range approval.Groups {
-			for _, requiredGroups := range a.Groups {
-				if g == requiredGroups {
-					// someone from a required group has approved
-					return true, nil
-				}
+			if !required[g] {
+				continue
+			}
+			if _, ok := eligible[key]; !ok {
+				users = append(users, approval.User)
+			}
+			eligible[key] = append(eligible[key], g)
+		}
+	}
+
+	count := a.Count
+	if count < 1 {
+		count = 1
+	}
+
+	approvers := users
+	if a.DistinctGroups {
+		approvers = matchApprovers(users, eligible)
+	}
+	if len(approvers) < count {
+		// not complete yet
+		return nil
+	}
+	return approvers[:count]
+}
+
+// matchApprovers returns the largest set of users who can each be
+// counted for a different group, in the order the users approved.
+// It finds a maximum bipartite matching of users to groups
+// using augmenting paths.
+func matchApprovers(users []string, eligible map[string][]string) []string {
+	// matched maps groups to the user counted for them.
+	matched := map[string]string{}
+
+	var augment func(user string, visited map[string]bool) bool
+	augment = func(user string, visited map[string]bool) bool {
+		for _, g := range eligible[strings.ToLower(user)] {
+			if visited[g] {
+				continue
 			}
+			visited[g] = true
+			other, ok := matched[g]
+			if !ok || augment(other, visited) {
+				matched[g] = user
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, u := range users {
+		augment(u, map[string]bool{})
+	}
+
+	counted := map[string]bool{}
+	for _, u := range matched {
+		counted[u] = true
+	}
+	var approvers []string
+	for _, u := range users {
+		if counted[u] {
+			approvers = append(approvers, u)
+		}
+	}
+	return approvers
+}
+
+// OutputSchema declares the outputs of an Approval step.
+func (a *Approval) OutputSchema() *jsoncel.Schema {
+	return &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"approver":  {Type: jsoncel.String},
+			"approvers": {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+		},
+	}
+}
+
+// Outputs returns the users who approved the step, so that later checks
+// can reference them, e.g. 'steps.approval.outputs.approver' for the
+// first approver or 'steps.approval.outputs.approvers' for all of them.
+func (a *Approval) Outputs(ctx context.Context, input any) (map[string]any, error) {
+	var i Input
+	err := mapstructure.Decode(input, &i)
+	if err != nil {
+		return nil, err
+	}
+
+	approvers := a.approvers(i)
+	var approver string
+	if len(approvers) > 0 {
+		approver = approvers[0]
+	}
+	return map[string]any{"approver": approver, "approvers": append([]string{}, approvers...)}, nil
+}
+
+// ValidateCompile checks that the count can be met, that the input schema
+// declares the requestor if self-approval is prevented or the requestor is
+// excluded, and that the groups exist if the dialect is configured with a
+// directory which can look up groups.
+func (a *Approval) ValidateCompile(env *cel.Env, schema *jsoncel.Schema) error {
+	err := validateRequestor(a.preventSelfApproval, schema)
+	if err != nil {
+		return err
+	}
+	if a.ExcludeRequestor && !declaresRequestor(schema) {
+		return errExcludedRequestorNotDeclared
+	}
+	if a.Count < 0 {
+		return fmt.Errorf("approval count must be at least 1, but was %d", a.Count)
+	}
+	if a.DistinctGroups && a.Count > len(a.Groups) {
+		return fmt.Errorf("approval count of %d is more than the number of groups (%d), so it can't be met by distinct groups", a.Count, len(a.Groups))
+	}
+
+	groups, ok := a.directory.(GroupDirectory)
+	if !ok {
+		return nil
+	}
+
+	for _, g := range a.Groups {
+		exists, err := groups.GroupExists(context.Background(), g)
+		if err != nil {
+			return fmt.Errorf("looking up approval group %s: %w", g, err)
+		}
+		if !exists {
+			return fmt.Errorf("approval group %s does not exist in the directory", g)
 		}
 	}
+	return nil
+}
 
-	// not complete yet
-	return false, nil
+func (a *Approval) Doc() string {
+	return "Complete when a member of one of the groups has approved the request, or when count distinct members have."
 }
 
 func (a *Approval) PrintAction() string {
 	groups := strings.Join(a.Groups, ", ")
+	if a.Count > 1 {
+		return fmt.Sprintf("notifying %s for %d access approvals", groups, a.Count)
+	}
 	return fmt.Sprintf("notifying %s for access approval", groups)
 }
@@ -1,8 +1,13 @@
 package cf
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestApproval_Complete(t *testing.T) {
@@ -61,7 +66,7 @@ func TestApproval_Complete(t *testing.T) {
 			a := &Approval{
 				Groups: tt.fields.Groups,
 			}
-			got, err := a.Complete(input)
+			got, err := a.Complete(context.Background(), input)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Approval.Complete() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -72,3 +77,239 @@ func TestApproval_Complete(t *testing.T) {
 		})
 	}
 }
+
+func TestApproval_Count(t *testing.T) {
+	approvals := func(approvals ...map[string]any) map[string]any {
+		var list []any
+		for _, a := range approvals {
+			list = append(list, a)
+		}
+		return map[string]any{"requestor": "alice@example.com", "approvals": list}
+	}
+	approval := func(user string, groups ...any) map[string]any {
+		return map[string]any{"user": user, "groups": groups}
+	}
+
+	tests := []struct {
+		name          string
+		action        Approval
+		input         map[string]any
+		want          bool
+		wantApprovers []string
+	}{
+		{
+			name:          "two approvers",
+			action:        Approval{Groups: []string{"admins"}, Count: 2},
+			input:         approvals(approval("bob@example.com", "admins"), approval("carol@example.com", "admins")),
+			want:          true,
+			wantApprovers: []string{"bob@example.com", "carol@example.com"},
+		},
+		{
+			name:   "the same approver twice",
+			action: Approval{Groups: []string{"admins"}, Count: 2},
+			input:  approvals(approval("bob@example.com", "admins"), approval("BOB@example.com", "admins")),
+			want:   false,
+		},
+		{
+			name:   "requestor is excluded",
+			action: Approval{Groups: []string{"admins"}, Count: 2, ExcludeRequestor: true},
+			input:  approvals(approval("alice@example.com", "admins"), approval("bob@example.com", "admins")),
+			want:   false,
+		},
+		{
+			// the requestor's approval can't be excluded if the requestor isn't known.
+			name:   "requestor is unknown",
+			action: Approval{Groups: []string{"admins"}, ExcludeRequestor: true},
+			input: map[string]any{"approvals": []any{
+				approval("alice@example.com", "admins"),
+			}},
+			want: false,
+		},
+		{
+			name:          "requestor is counted",
+			action:        Approval{Groups: []string{"admins"}, Count: 2},
+			input:         approvals(approval("alice@example.com", "admins"), approval("bob@example.com", "admins")),
+			want:          true,
+			wantApprovers: []string{"alice@example.com", "bob@example.com"},
+		},
+		{
+			name:   "distinct groups not met",
+			action: Approval{Groups: []string{"admins", "security"}, Count: 2, DistinctGroups: true},
+			input:  approvals(approval("bob@example.com", "admins"), approval("carol@example.com", "admins")),
+			want:   false,
+		},
+		{
+			// bob could be counted for either group, so he's counted
+			// for security to leave admins for carol.
+			name:          "distinct groups",
+			action:        Approval{Groups: []string{"admins", "security"}, Count: 2, DistinctGroups: true},
+			input:         approvals(approval("bob@example.com", "admins", "security"), approval("carol@example.com", "admins")),
+			want:          true,
+			wantApprovers: []string{"bob@example.com", "carol@example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.action.Complete(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+			if tt.want {
+				outputs, err := tt.action.Outputs(context.Background(), tt.input)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, tt.wantApprovers, outputs["approvers"])
+			}
+		})
+	}
+}
+
+func TestApproval_Outputs(t *testing.T) {
+	a := &Approval{Groups: []string{"admins"}}
+
+	input := map[string]any{
+		"approvals": []any{
+			map[string]any{"user": "alice@example.com", "groups": []any{"admins"}},
+		},
+	}
+
+	complete, err := a.Complete(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete {
+		t.Fatal("expected approval to be complete")
+	}
+
+	outputs, err := a.Outputs(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := outputs["approver"]
+	if got != "alice@example.com" {
+		t.Errorf("Approval.Outputs() approver = %v, want %v", got, "alice@example.com")
+	}
+}
+
+// testGroupDirectory is a GroupDirectory with a fixed set of groups.
+type testGroupDirectory struct {
+	StaticDirectory
+	groups []string
+}
+
+func (d testGroupDirectory) GroupExists(ctx context.Context, group string) (bool, error) {
+	for _, g := range d.groups {
+		if g == group {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestApproval_ValidateCompile(t *testing.T) {
+	dir := testGroupDirectory{groups: []string{"admins", "security"}}
+
+	a := Approval{Groups: []string{"admins", "security"}, directory: dir}
+	assert.NoError(t, a.ValidateCompile(nil, nil))
+
+	a = Approval{Groups: []string{"admins", "finance"}, directory: dir}
+	assert.EqualError(t, a.ValidateCompile(nil, nil), "approval group finance does not exist in the directory")
+
+	a = Approval{Groups: []string{"admins"}, Count: 2, DistinctGroups: true}
+	assert.EqualError(t, a.ValidateCompile(nil, nil), "approval count of 2 is more than the number of groups (1), so it can't be met by distinct groups")
+
+	// directories which can't look up groups aren't checked.
+	a = Approval{Groups: []string{"finance"}, directory: StaticDirectory{}}
+	assert.NoError(t, a.ValidateCompile(nil, nil))
+
+	// the requestor must be declared to be excluded.
+	a = Approval{Groups: []string{"admins"}, ExcludeRequestor: true}
+	assert.EqualError(t, a.ValidateCompile(nil, nil), "exclude_requestor is set, so the input schema must declare a 'requestor' string field")
+	declared := &jsoncel.Schema{Type: jsoncel.Object, Properties: map[string]*jsoncel.Schema{"requestor": {Type: jsoncel.String}}}
+	assert.NoError(t, a.ValidateCompile(nil, declared))
+}
+
+func TestNew(t *testing.T) {
+	s := Schedules{"pagerduty": testSchedule{}}
+	dir := StaticDirectory{}
+	owners := Owners{{Pattern: "**", Groups: []string{"admins"}}}
+	d := New(WithSchedules(s), WithDirectory(dir), WithOwners(owners))
+
+	a, ok := d.Actions()["oncall"].(*OnCall)
+	if !ok {
+		t.Fatal("expected oncall action")
+	}
+	assert.Equal(t, s, a.schedules)
+
+	m, ok := d.Actions()["manager_approval"].(*ManagerApproval)
+	if !ok {
+		t.Fatal("expected manager_approval action")
+	}
+	assert.Equal(t, dir, m.directory)
+
+	o, ok := d.Actions()["owner_approval"].(*OwnerApproval)
+	if !ok {
+		t.Fatal("expected owner_approval action")
+	}
+	assert.Equal(t, owners, o.owners)
+
+	// the default dialect has no integrations configured.
+	a, ok = Dialect.Actions()["oncall"].(*OnCall)
+	if !ok {
+		t.Fatal("expected oncall action")
+	}
+	assert.Nil(t, a.schedules)
+}
+
+func TestDescribe(t *testing.T) {
+	desc := Dialect.Describe()
+
+	assert.Equal(t, []dialect.NodeDescription{
+		{ID: "request", Type: "start", Name: "Request"},
+		{ID: "approved", Type: "outcome", Name: "Approved", Priority: 1},
+	}, desc.Nodes)
+
+	var names []string
+	for _, a := range desc.Actions {
+		names = append(names, a.Name)
+		// every action in the dialect is documented.
+		assert.NotEmpty(t, a.Doc, a.Name)
+	}
+	assert.Equal(t, []string{"approval", "justification", "manager_approval", "max_duration", "oncall", "owner_approval", "webhook"}, names)
+
+	approval := desc.Actions[0]
+	assert.Equal(t, &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"groups": {
+				Type:        jsoncel.Array,
+				Items:       &jsoncel.Schema{Type: jsoncel.String},
+				Description: "the groups which may approve the request",
+			},
+			"count": {
+				Type:        jsoncel.Integer,
+				Description: "the number of distinct users who must approve the request, which defaults to 1",
+			},
+			"distinct_groups": {
+				Type:        jsoncel.Boolean,
+				Description: "if true, each approval must be counted for a different group",
+			},
+			"exclude_requestor": {
+				Type:        jsoncel.Boolean,
+				Description: "if true, an approval by the requestor isn't counted, and the step isn't complete until the requestor is known",
+			},
+		},
+	}, approval.With)
+	assert.Equal(t, (&Approval{}).OutputSchema(), approval.Outputs)
+
+	justification := desc.Actions[1]
+	assert.Equal(t, jsoncel.Integer, justification.With.Properties["min_length"].Type)
+
+	// the approvals read by the approval actions are declared in the input.
+	approvals := jsoncel.Lookup(desc.Input, "approvals")
+	if assert.NotNil(t, approvals) {
+		assert.Equal(t, jsoncel.String, approvals.Items.Properties["user"].Type)
+	}
+}
@@ -0,0 +1,94 @@
+package cf
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// MaxDuration is an action which is complete when the duration
+// of access requested in the input is no longer than the maximum.
+//
+// For example:
+//
+//	action: max_duration
+//	with:
+//	  max: 8h
+type MaxDuration struct {
+	// Max is the maximum duration which may be requested, e.g. '8h' or '90m'.
+	Max string `yaml:"max" doc:"the maximum duration which may be requested, e.g. 8h"`
+
+	// max is parsed when the action is unmarshalled,
+	// so that invalid durations are reported with the workflow.
+	max time.Duration
+}
+
+// UnmarshalYAML validates the maximum duration.
+func (m *MaxDuration) UnmarshalYAML(unmarshal func(any) error) error {
+	// unmarshal into an alias to avoid calling this method recursively.
+	type maxDuration MaxDuration
+	var raw maxDuration
+	err := unmarshal(&raw)
+	if err != nil {
+		return err
+	}
+	*m = MaxDuration(raw)
+
+	if m.Max == "" {
+		return fmt.Errorf("max must be provided")
+	}
+
+	m.max, err = time.ParseDuration(m.Max)
+	if err != nil {
+		return fmt.Errorf("invalid max: %w", err)
+	}
+	if m.max <= 0 {
+		return fmt.Errorf("max must be positive")
+	}
+
+	return nil
+}
+
+// Complete returns true if the requested duration is within the maximum.
+func (m *MaxDuration) Complete(ctx context.Context, input any) (bool, error) {
+	var i Input
+	err := mapstructure.Decode(input, &i)
+	if err != nil {
+		return false, err
+	}
+
+	if i.Duration == "" {
+		// not complete until a duration is requested.
+		return false, nil
+	}
+
+	requested, err := ParseDuration(i.Duration)
+	if err != nil {
+		return false, err
+	}
+
+	return requested <= m.max, nil
+}
+
+func (m *MaxDuration) Doc() string {
+	return "Complete when the duration of access requested is no longer than the maximum."
+}
+
+func (m *MaxDuration) PrintAction() string {
+	return fmt.Sprintf("requiring access for at most %s", m.Max)
+}
+
+// ParseDuration parses a requested duration from the input, such as '1h30m'.
+// Requested durations must be positive.
+func ParseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid requested duration: %w", err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("requested duration %s must be positive", s)
+	}
+	return d, nil
+}
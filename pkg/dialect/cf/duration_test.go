@@ -0,0 +1,104 @@
+package cf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxDuration_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			give: `max: 8h`,
+		},
+		{
+			name: "minutes",
+			give: `max: 90m`,
+		},
+		{
+			name:    "missing",
+			give:    `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			give:    `max: 8 hours`,
+			wantErr: true,
+		},
+		{
+			name:    "not positive",
+			give:    `max: 0s`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m MaxDuration
+			err := yaml.Unmarshal([]byte(tt.give), &m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MaxDuration.UnmarshalYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxDuration_Complete(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:  "within max",
+			input: map[string]any{"duration": "2h"},
+			want:  true,
+		},
+		{
+			name:  "equal to max",
+			input: map[string]any{"duration": "480m"},
+			want:  true,
+		},
+		{
+			name:  "over max",
+			input: map[string]any{"duration": "8h1m"},
+			want:  false,
+		},
+		{
+			name:  "missing",
+			input: map[string]any{},
+			want:  false,
+		},
+		{
+			name:    "invalid",
+			input:   map[string]any{"duration": "two hours"},
+			wantErr: true,
+		},
+		{
+			name:    "negative",
+			input:   map[string]any{"duration": "-1h"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m MaxDuration
+			err := yaml.Unmarshal([]byte(`max: 8h`), &m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := m.Complete(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MaxDuration.Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
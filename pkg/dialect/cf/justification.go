@@ -0,0 +1,147 @@
+package cf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Justification is an action which is complete when the input
+// contains a justification for the request which meets the constraints.
+//
+// For example:
+//
+//	action: justification
+//	with:
+//	  min_length: 20
+//	  ticket: JIRA-\d+
+type Justification struct {
+	// MinLength is the minimum length of the justification.
+	MinLength int `yaml:"min_length" doc:"the minimum length of the justification"`
+	// Pattern is a regular expression which the justification must match.
+	Pattern string `yaml:"pattern" doc:"a regular expression which the justification must match"`
+	// Ticket is a regular expression for a ticket reference which
+	// the justification must contain, e.g. 'JIRA-\d+'.
+	Ticket string `yaml:"ticket" doc:"a regular expression for a ticket reference which the justification must contain"`
+
+	// pattern and ticket are compiled when the action is unmarshalled,
+	// so that invalid expressions are reported with the workflow.
+	pattern *regexp.Regexp
+	ticket  *regexp.Regexp
+}
+
+// UnmarshalYAML validates the action's constraints.
+func (j *Justification) UnmarshalYAML(unmarshal func(any) error) error {
+	// unmarshal into an alias to avoid calling this method recursively.
+	type justification Justification
+	var raw justification
+	err := unmarshal(&raw)
+	if err != nil {
+		return err
+	}
+	*j = Justification(raw)
+
+	if j.MinLength < 0 {
+		return fmt.Errorf("min_length must not be negative")
+	}
+
+	if j.Pattern != "" {
+		j.pattern, err = regexp.Compile(j.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	if j.Ticket != "" {
+		j.ticket, err = regexp.Compile(j.Ticket)
+		if err != nil {
+			return fmt.Errorf("invalid ticket: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Complete returns true if the justification in the input meets the constraints.
+func (j *Justification) Complete(ctx context.Context, input any) (bool, error) {
+	_, ok, err := j.check(input)
+	return ok, err
+}
+
+// check returns true if the justification in the input meets the
+// constraints, along with the ticket reference found in it, if any.
+func (j *Justification) check(input any) (string, bool, error) {
+	var i Input
+	err := mapstructure.Decode(input, &i)
+	if err != nil {
+		return "", false, err
+	}
+
+	text := strings.TrimSpace(i.Justification)
+	if text == "" {
+		return "", false, nil
+	}
+
+	if len([]rune(text)) < j.MinLength {
+		return "", false, nil
+	}
+
+	if j.pattern != nil && !j.pattern.MatchString(text) {
+		return "", false, nil
+	}
+
+	var ref string
+	if j.ticket != nil {
+		ref = j.ticket.FindString(text)
+		if ref == "" {
+			return "", false, nil
+		}
+	}
+
+	return ref, true, nil
+}
+
+// OutputSchema declares the outputs of a Justification step.
+func (j *Justification) OutputSchema() *jsoncel.Schema {
+	return &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"ticket": {Type: jsoncel.String},
+		},
+	}
+}
+
+// Outputs returns the ticket reference found in the justification,
+// e.g. 'steps.justification.outputs.ticket'.
+func (j *Justification) Outputs(ctx context.Context, input any) (map[string]any, error) {
+	ref, _, err := j.check(input)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"ticket": ref}, nil
+}
+
+func (j *Justification) Doc() string {
+	return "Complete when the request has a justification which meets the constraints."
+}
+
+func (j *Justification) PrintAction() string {
+	var constraints []string
+	if j.MinLength > 0 {
+		constraints = append(constraints, fmt.Sprintf("at least %d characters", j.MinLength))
+	}
+	if j.Pattern != "" {
+		constraints = append(constraints, fmt.Sprintf("matching %s", j.Pattern))
+	}
+	if j.Ticket != "" {
+		constraints = append(constraints, fmt.Sprintf("referencing a ticket like %s", j.Ticket))
+	}
+	if len(constraints) == 0 {
+		return "requiring a justification"
+	}
+	return fmt.Sprintf("requiring a justification %s", strings.Join(constraints, ", "))
+}
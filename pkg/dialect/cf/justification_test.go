@@ -0,0 +1,125 @@
+package cf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJustification_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			give: `
+min_length: 10
+pattern: ^[A-Z]
+ticket: JIRA-\d+
+`,
+		},
+		{
+			name:    "invalid pattern",
+			give:    `pattern: "[a-"`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid ticket",
+			give:    `ticket: "JIRA-(\\d+"`,
+			wantErr: true,
+		},
+		{
+			name:    "negative min length",
+			give:    `min_length: -1`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var j Justification
+			err := yaml.Unmarshal([]byte(tt.give), &j)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Justification.UnmarshalYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJustification_Complete(t *testing.T) {
+	tests := []struct {
+		name       string
+		give       string
+		input      map[string]any
+		want       bool
+		wantTicket string
+	}{
+		{
+			name:  "no constraints",
+			give:  `{}`,
+			input: map[string]any{"justification": "investigating an incident"},
+			want:  true,
+		},
+		{
+			name:  "missing",
+			give:  `{}`,
+			input: map[string]any{},
+			want:  false,
+		},
+		{
+			name:  "whitespace only",
+			give:  `{}`,
+			input: map[string]any{"justification": "   "},
+			want:  false,
+		},
+		{
+			name:  "too short",
+			give:  `min_length: 20`,
+			input: map[string]any{"justification": "incident"},
+			want:  false,
+		},
+		{
+			name:  "does not match pattern",
+			give:  `pattern: ^[A-Z]`,
+			input: map[string]any{"justification": "investigating an incident"},
+			want:  false,
+		},
+		{
+			name:       "with ticket",
+			give:       `ticket: JIRA-\d+`,
+			input:      map[string]any{"justification": "investigating JIRA-123"},
+			want:       true,
+			wantTicket: "JIRA-123",
+		},
+		{
+			name:  "missing ticket",
+			give:  `ticket: JIRA-\d+`,
+			input: map[string]any{"justification": "investigating an incident"},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var j Justification
+			err := yaml.Unmarshal([]byte(tt.give), &j)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := j.Complete(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+			if tt.want {
+				outputs, err := j.Outputs(context.Background(), tt.input)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, map[string]any{"ticket": tt.wantTicket}, outputs)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package cf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/google/cel-go/cel"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Directory looks up users in an organisation's directory,
+// such as an identity provider which supports SCIM, or LDAP.
+type Directory interface {
+	// Manager returns the email address of the user's manager.
+	// It returns an empty string if the user has no manager.
+	Manager(ctx context.Context, user string) (string, error)
+}
+
+// GroupDirectory is a Directory which can also look up groups.
+// If the directory configured with WithDirectory is a GroupDirectory,
+// approval actions are checked to only reference groups which exist
+// when a workflow is compiled.
+type GroupDirectory interface {
+	Directory
+	// GroupExists returns true if the group exists in the directory.
+	GroupExists(ctx context.Context, group string) (bool, error)
+}
+
+// StaticDirectory is a Directory which maps
+// the email addresses of users to their managers.
+type StaticDirectory map[string]string
+
+func (d StaticDirectory) Manager(ctx context.Context, user string) (string, error) {
+	return d[user], nil
+}
+
+// ManagerApproval is an action which is complete when
+// the requestor's manager has approved the request.
+// The manager is looked up using the Directory configured with WithDirectory.
+//
+// For example:
+//
+//	action: manager_approval
+type ManagerApproval struct {
+	// directory is configured with WithDirectory.
+	directory Directory
+
+	// preventSelfApproval is configured with WithSelfApprovalPrevented.
+	preventSelfApproval bool
+}
+
+// Complete returns true if the requestor's manager has approved the request.
+func (m *ManagerApproval) Complete(ctx context.Context, input any) (bool, error) {
+	approver, err := m.approver(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	return approver != "", nil
+}
+
+// approver returns the manager who approved the request,
+// or an empty string if the manager hasn't approved it yet.
+func (m *ManagerApproval) approver(ctx context.Context, input any) (string, error) {
+	var i Input
+	err := mapstructure.Decode(input, &i)
+	if err != nil {
+		return "", err
+	}
+
+	if i.Requestor == "" {
+		// we can't look up the manager without knowing the requestor.
+		return "", nil
+	}
+
+	if m.directory == nil {
+		return "", fmt.Errorf("a directory must be configured to look up the manager of %s", i.Requestor)
+	}
+
+	manager, err := m.directory.Manager(ctx, i.Requestor)
+	if err != nil {
+		return "", fmt.Errorf("looking up the manager of %s: %w", i.Requestor, err)
+	}
+	if manager == "" {
+		return "", fmt.Errorf("%s does not have a manager in the directory", i.Requestor)
+	}
+
+	approvals := i.Approvals
+	if m.preventSelfApproval {
+		// the requestor is known, so the approvals can always be checked.
+		approvals, _ = approvalsExcludingRequestor(i)
+	}
+
+	for _, approval := range approvals {
+		if strings.EqualFold(approval.User, manager) {
+			return approval.User, nil
+		}
+	}
+
+	// not complete yet
+	return "", nil
+}
+
+// OutputSchema declares the outputs of a ManagerApproval step.
+func (m *ManagerApproval) OutputSchema() *jsoncel.Schema {
+	return &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"approver": {Type: jsoncel.String},
+		},
+	}
+}
+
+// Outputs returns the manager who approved the step.
+func (m *ManagerApproval) Outputs(ctx context.Context, input any) (map[string]any, error) {
+	approver, err := m.approver(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"approver": approver}, nil
+}
+
+// ValidateCompile checks that the input schema declares
+// the requestor, if self-approval is prevented.
+func (m *ManagerApproval) ValidateCompile(env *cel.Env, schema *jsoncel.Schema) error {
+	return validateRequestor(m.preventSelfApproval, schema)
+}
+
+func (m *ManagerApproval) Doc() string {
+	return "Complete when the requestor's manager has approved the request."
+}
+
+func (m *ManagerApproval) PrintAction() string {
+	return "notifying the requestor's manager for access approval"
+}
@@ -0,0 +1,146 @@
+package cf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerApproval_Complete(t *testing.T) {
+	dir := StaticDirectory{"alice@example.com": "bob@example.com"}
+
+	tests := []struct {
+		name         string
+		directory    Directory
+		input        map[string]any
+		want         bool
+		wantApprover string
+		wantErr      bool
+	}{
+		{
+			name:      "approved by manager",
+			directory: dir,
+			input: map[string]any{
+				"requestor": "alice@example.com",
+				"approvals": []any{map[string]any{"user": "Bob@example.com"}},
+			},
+			want:         true,
+			wantApprover: "Bob@example.com",
+		},
+		{
+			name:      "approved by someone else",
+			directory: dir,
+			input: map[string]any{
+				"requestor": "alice@example.com",
+				"approvals": []any{map[string]any{"user": "carol@example.com"}},
+			},
+			want: false,
+		},
+		{
+			name:      "no requestor",
+			directory: dir,
+			input:     map[string]any{},
+			want:      false,
+		},
+		{
+			name:      "no manager",
+			directory: dir,
+			input:     map[string]any{"requestor": "bob@example.com"},
+			wantErr:   true,
+		},
+		{
+			name:    "no directory",
+			input:   map[string]any{"requestor": "alice@example.com"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &ManagerApproval{directory: tt.directory}
+			got, err := m.Complete(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ManagerApproval.Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+			if tt.want {
+				outputs, err := m.Outputs(context.Background(), tt.input)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, map[string]any{"approver": tt.wantApprover}, outputs)
+			}
+		})
+	}
+}
+
+func TestSCIM_Manager(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/scim/v2/Users":
+			switch r.URL.Query().Get("filter") {
+			case `userName eq "alice@example.com"`:
+				_, _ = w.Write([]byte(`{"Resources": [{"id": "1", "userName": "alice@example.com", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"manager": {"value": "2"}}}]}`))
+			case `userName eq "bob@example.com"`:
+				_, _ = w.Write([]byte(`{"Resources": [{"id": "2", "userName": "bob@example.com"}]}`))
+			default:
+				_, _ = w.Write([]byte(`{"Resources": []}`))
+			}
+		case "/scim/v2/Users/2":
+			_, _ = w.Write([]byte(`{"id": "2", "userName": "bob@example.com"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s := SCIM{BaseURL: srv.URL + "/scim/v2", Token: "secret"}
+
+	got, err := s.Manager(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "bob@example.com", got)
+
+	got, err = s.Manager(context.Background(), "bob@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", got)
+
+	_, err = s.Manager(context.Background(), "unknown@example.com")
+	assert.Error(t, err)
+}
+
+func TestSCIM_GroupExists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scim/v2/Groups" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("filter") == `displayName eq "admins"` {
+			_, _ = w.Write([]byte(`{"Resources": [{"id": "1", "displayName": "admins"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Resources": []}`))
+	}))
+	defer srv.Close()
+
+	s := SCIM{BaseURL: srv.URL + "/scim/v2", Token: "secret"}
+
+	got, err := s.GroupExists(context.Background(), "admins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, got)
+
+	got, err = s.GroupExists(context.Background(), "finance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, got)
+}
@@ -0,0 +1,91 @@
+package cf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// ScheduleProvider looks up the users who are on call for a schedule,
+// using an on-call management service like PagerDuty or Opsgenie.
+type ScheduleProvider interface {
+	// OnCall returns the users who are currently on call for the schedule.
+	// Users are identified by their email address.
+	OnCall(ctx context.Context, schedule string) ([]string, error)
+}
+
+// Schedules maps the provider names used in workflows,
+// e.g. 'pagerduty', to schedule providers.
+type Schedules map[string]ScheduleProvider
+
+// OnCall is an action which is complete if the
+// requestor is on call for a schedule.
+//
+// For example:
+//
+//	action: oncall
+//	with:
+//	  provider: pagerduty
+//	  schedule: PABC123
+type OnCall struct {
+	// Provider is the name of the schedule provider, e.g. 'pagerduty'.
+	Provider string `yaml:"provider" doc:"the name of the schedule provider, e.g. pagerduty"`
+	// Schedule is the ID of the schedule in the provider.
+	Schedule string `yaml:"schedule" doc:"the ID of the schedule in the provider"`
+
+	// schedules are the providers configured with WithSchedules.
+	schedules Schedules
+}
+
+// Complete returns true if the requestor is on call for the schedule.
+func (o *OnCall) Complete(ctx context.Context, input any) (bool, error) {
+	var i Input
+	err := mapstructure.Decode(input, &i)
+	if err != nil {
+		return false, err
+	}
+
+	if i.Requestor == "" {
+		// we can't tell whether the requestor is on call.
+		return false, nil
+	}
+
+	p, ok := o.schedules[o.Provider]
+	if !ok {
+		return false, fmt.Errorf("schedule provider %q is not configured (configured providers: %s)", o.Provider, strings.Join(o.schedules.names(), ", "))
+	}
+
+	users, err := p.OnCall(ctx, o.Schedule)
+	if err != nil {
+		return false, fmt.Errorf("looking up on-call users for %s schedule %s: %w", o.Provider, o.Schedule, err)
+	}
+
+	for _, u := range users {
+		if strings.EqualFold(u, i.Requestor) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (o *OnCall) Doc() string {
+	return "Complete when the requestor is on call for the schedule."
+}
+
+func (o *OnCall) PrintAction() string {
+	return fmt.Sprintf("checking whether the requestor is on call for %s schedule %s", o.Provider, o.Schedule)
+}
+
+// names returns the sorted names of the providers.
+func (s Schedules) names() []string {
+	var names []string
+	for n := range s {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
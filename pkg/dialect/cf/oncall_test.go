@@ -0,0 +1,120 @@
+package cf
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSchedule is a ScheduleProvider with a fixed set of on-call users.
+type testSchedule struct {
+	users map[string][]string
+	err   error
+}
+
+func (t testSchedule) OnCall(ctx context.Context, schedule string) ([]string, error) {
+	return t.users[schedule], t.err
+}
+
+func TestOnCall_Complete(t *testing.T) {
+	schedules := Schedules{
+		"pagerduty": testSchedule{users: map[string][]string{"PRIMARY": {"alice@example.com"}}},
+		"broken":    testSchedule{err: errors.New("unavailable")},
+	}
+
+	tests := []struct {
+		name    string
+		give    OnCall
+		input   map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:  "on call",
+			give:  OnCall{Provider: "pagerduty", Schedule: "PRIMARY", schedules: schedules},
+			input: map[string]any{"requestor": "Alice@example.com"},
+			want:  true,
+		},
+		{
+			name:  "not on call",
+			give:  OnCall{Provider: "pagerduty", Schedule: "PRIMARY", schedules: schedules},
+			input: map[string]any{"requestor": "bob@example.com"},
+			want:  false,
+		},
+		{
+			name:  "no requestor",
+			give:  OnCall{Provider: "pagerduty", Schedule: "PRIMARY", schedules: schedules},
+			input: map[string]any{},
+			want:  false,
+		},
+		{
+			name:    "provider not configured",
+			give:    OnCall{Provider: "opsgenie", Schedule: "PRIMARY", schedules: schedules},
+			input:   map[string]any{"requestor": "alice@example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "provider error",
+			give:    OnCall{Provider: "broken", Schedule: "PRIMARY", schedules: schedules},
+			input:   map[string]any{"requestor": "alice@example.com"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.give.Complete(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OnCall.Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPagerDuty_OnCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/oncalls", r.URL.Path)
+		assert.Equal(t, "PRIMARY", r.URL.Query().Get("schedule_ids[]"))
+		assert.Equal(t, "Token token=secret", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"oncalls": [{"user": {"email": "alice@example.com"}}, {"user": {}}]}`))
+	}))
+	defer srv.Close()
+
+	p := PagerDuty{Token: "secret", BaseURL: srv.URL}
+	got, err := p.OnCall(context.Background(), "PRIMARY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"alice@example.com"}, got)
+}
+
+func TestOpsgenie_OnCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/schedules/primary/on-calls", r.URL.Path)
+		assert.Equal(t, "GenieKey secret", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data": {"onCallRecipients": ["alice@example.com"]}}`))
+	}))
+	defer srv.Close()
+
+	o := Opsgenie{APIKey: "secret", BaseURL: srv.URL}
+	got, err := o.OnCall(context.Background(), "primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"alice@example.com"}, got)
+}
+
+func TestOpsgenie_OnCallError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	o := Opsgenie{APIKey: "wrong", BaseURL: srv.URL}
+	_, err := o.OnCall(context.Background(), "primary")
+	assert.Error(t, err)
+}
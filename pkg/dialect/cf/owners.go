@@ -0,0 +1,224 @@
+package cf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/google/cel-go/cel"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Owners maps resources to the groups which own them, like a CODEOWNERS file.
+// Rules are matched in order, and the last rule which matches a resource wins.
+type Owners []OwnerRule
+
+// OwnerRule is a rule in an ownership mapping.
+type OwnerRule struct {
+	// Pattern matches resource names. '*' matches any characters
+	// except '/', and '**' matches any characters.
+	Pattern string `mapstructure:"pattern"`
+	// Groups are the groups which own the matching resources.
+	// A rule with no groups removes the owners of the matching resources.
+	Groups []string `mapstructure:"groups"`
+}
+
+// ParseOwners parses an ownership mapping in the CODEOWNERS format.
+// Each line is a pattern followed by the groups which own it,
+// and lines starting with '#' are comments. For example:
+//
+//	# the platform team owns everything by default
+//	**              platform
+//	aws/prod/**     platform security
+//	aws/dev/*       developers
+func ParseOwners(data []byte) (Owners, error) {
+	var o Owners
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if _, err := ownerPattern(fields[0]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		o = append(o, OwnerRule{Pattern: fields[0], Groups: fields[1:]})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Groups returns the groups which own the resource.
+// It returns nil if no rule matches the resource.
+func (o Owners) Groups(resource string) ([]string, error) {
+	var groups []string
+	for _, r := range o {
+		re, err := ownerPattern(r.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		if re.MatchString(resource) {
+			groups = r.Groups
+		}
+	}
+	return groups, nil
+}
+
+// ownerPattern converts a pattern in an ownership mapping to a regular expression.
+func ownerPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("owner pattern must not be empty")
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// WithOwners configures the ownership mapping used by 'owner_approval'
+// actions to look up the groups which own a resource.
+func WithOwners(o Owners) Option {
+	return func(c *config) {
+		c.owners = o
+	}
+}
+
+// OwnerApproval is an action which is complete when a member of a group
+// which owns the requested resource has approved the request.
+// The owners are looked up in the mapping configured with WithOwners,
+// or in the 'owners' field of the input if no mapping is configured,
+// so that approver groups don't need to be maintained in each workflow.
+//
+// The resource is the 'resource' field of the input,
+// unless the action sets one:
+//
+//	action: owner_approval
+//	with:
+//	  resource: aws/prod/billing
+type OwnerApproval struct {
+	Resource string `yaml:"resource" doc:"the resource to look up the owners of, which defaults to input.resource"`
+
+	// owners is configured with WithOwners.
+	owners Owners
+
+	// preventSelfApproval is configured with WithSelfApprovalPrevented.
+	preventSelfApproval bool
+}
+
+// Complete returns true if an owner of the resource has approved the request.
+func (o *OwnerApproval) Complete(ctx context.Context, input any) (bool, error) {
+	_, ok, err := o.approver(input)
+	return ok, err
+}
+
+// approver returns the owner who approved the request,
+// and false if no owner has approved it yet.
+func (o *OwnerApproval) approver(input any) (string, bool, error) {
+	var i Input
+	err := mapstructure.Decode(input, &i)
+	if err != nil {
+		return "", false, err
+	}
+
+	resource := o.Resource
+	if resource == "" {
+		resource = i.Resource
+	}
+	if resource == "" {
+		// we can't look up the owners without knowing the resource.
+		return "", false, nil
+	}
+
+	owners := o.owners
+	if owners == nil {
+		owners = i.Owners
+	}
+	if owners == nil {
+		return "", false, fmt.Errorf("an ownership mapping must be configured to look up the owners of %s", resource)
+	}
+
+	groups, err := owners.Groups(resource)
+	if err != nil {
+		return "", false, err
+	}
+	if len(groups) == 0 {
+		return "", false, fmt.Errorf("%s does not have any owners in the ownership mapping", resource)
+	}
+
+	approvals := i.Approvals
+	if o.preventSelfApproval {
+		var ok bool
+		approvals, ok = approvalsExcludingRequestor(i)
+		if !ok {
+			// approvals can't be checked against an unknown requestor.
+			return "", false, nil
+		}
+	}
+
+	for _, approval := range approvals {
+		for _, g := range approval.Groups {
+			for _, owner := range groups {
+				if g == owner {
+					return approval.User, true, nil
+				}
+			}
+		}
+	}
+
+	// not complete yet
+	return "", false, nil
+}
+
+// ValidateCompile checks that the input schema declares
+// the requestor, if self-approval is prevented.
+func (o *OwnerApproval) ValidateCompile(env *cel.Env, schema *jsoncel.Schema) error {
+	return validateRequestor(o.preventSelfApproval, schema)
+}
+
+// OutputSchema declares the outputs of an OwnerApproval step.
+func (o *OwnerApproval) OutputSchema() *jsoncel.Schema {
+	return &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"approver": {Type: jsoncel.String},
+		},
+	}
+}
+
+// Outputs returns the owner who approved the step.
+func (o *OwnerApproval) Outputs(ctx context.Context, input any) (map[string]any, error) {
+	approver, _, err := o.approver(input)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"approver": approver}, nil
+}
+
+func (o *OwnerApproval) Doc() string {
+	return "Complete when a member of a group which owns the requested resource has approved the request."
+}
+
+func (o *OwnerApproval) PrintAction() string {
+	if o.Resource != "" {
+		return fmt.Sprintf("notifying the owners of %s for access approval", o.Resource)
+	}
+	return "notifying the owners of the resource for access approval"
+}
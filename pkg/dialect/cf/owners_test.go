@@ -0,0 +1,147 @@
+package cf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOwners(t *testing.T) {
+	data := []byte(`
+# the platform team owns everything by default
+**              platform
+aws/prod/**     platform security
+
+aws/dev/*       developers
+`)
+	got, err := ParseOwners(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Owners{
+		{Pattern: "**", Groups: []string{"platform"}},
+		{Pattern: "aws/prod/**", Groups: []string{"platform", "security"}},
+		{Pattern: "aws/dev/*", Groups: []string{"developers"}},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestOwners_Groups(t *testing.T) {
+	owners := Owners{
+		{Pattern: "**", Groups: []string{"platform"}},
+		{Pattern: "aws/prod/**", Groups: []string{"security"}},
+		{Pattern: "aws/dev/*", Groups: []string{"developers"}},
+		{Pattern: "aws/dev/shared"},
+	}
+
+	tests := []struct {
+		resource string
+		want     []string
+	}{
+		{resource: "gcp/project", want: []string{"platform"}},
+		{resource: "aws/prod/billing", want: []string{"security"}},
+		{resource: "aws/prod/billing/admin", want: []string{"security"}},
+		{resource: "aws/dev/sandbox", want: []string{"developers"}},
+		{resource: "aws/dev/sandbox/admin", want: []string{"platform"}},
+		{resource: "aws/dev/shared", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			got, err := owners.Groups(tt.resource)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOwnerApproval_Complete(t *testing.T) {
+	owners := Owners{
+		{Pattern: "aws/prod/*", Groups: []string{"security"}},
+	}
+
+	tests := []struct {
+		name         string
+		action       OwnerApproval
+		input        map[string]any
+		want         bool
+		wantApprover string
+		wantErr      bool
+	}{
+		{
+			name:   "approved by owner",
+			action: OwnerApproval{owners: owners},
+			input: map[string]any{
+				"resource":  "aws/prod/billing",
+				"approvals": []any{map[string]any{"user": "alice@example.com", "groups": []any{"security"}}},
+			},
+			want:         true,
+			wantApprover: "alice@example.com",
+		},
+		{
+			name:   "approved by someone else",
+			action: OwnerApproval{owners: owners},
+			input: map[string]any{
+				"resource":  "aws/prod/billing",
+				"approvals": []any{map[string]any{"user": "bob@example.com", "groups": []any{"developers"}}},
+			},
+			want: false,
+		},
+		{
+			name:   "resource set by the action",
+			action: OwnerApproval{Resource: "aws/prod/billing", owners: owners},
+			input: map[string]any{
+				"resource":  "aws/dev/sandbox",
+				"approvals": []any{map[string]any{"user": "alice@example.com", "groups": []any{"security"}}},
+			},
+			want:         true,
+			wantApprover: "alice@example.com",
+		},
+		{
+			name: "owners in input",
+			input: map[string]any{
+				"resource":  "aws/prod/billing",
+				"owners":    []any{map[string]any{"pattern": "aws/**", "groups": []any{"platform"}}},
+				"approvals": []any{map[string]any{"user": "carol@example.com", "groups": []any{"platform"}}},
+			},
+			want:         true,
+			wantApprover: "carol@example.com",
+		},
+		{
+			name:   "no resource",
+			action: OwnerApproval{owners: owners},
+			input:  map[string]any{},
+			want:   false,
+		},
+		{
+			name:    "no owners",
+			action:  OwnerApproval{owners: owners},
+			input:   map[string]any{"resource": "aws/dev/sandbox"},
+			wantErr: true,
+		},
+		{
+			name:    "no ownership mapping",
+			input:   map[string]any{"resource": "aws/prod/billing"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.action
+			got, err := o.Complete(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OwnerApproval.Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+			if tt.want {
+				outputs, err := o.Outputs(context.Background(), tt.input)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, map[string]any{"approver": tt.wantApprover}, outputs)
+			}
+		})
+	}
+}
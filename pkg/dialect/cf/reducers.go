@@ -0,0 +1,44 @@
+package cf
+
+import (
+	"fmt"
+
+	"github.com/common-fate/glide/pkg/dialect"
+)
+
+// reducers fold the events of an access request into its input.
+//
+//   - 'approval_added' appends the event data, such as
+//     {"user": "alice@example.com", "groups": ["admins"]}, to 'approvals'.
+//   - 'justification_edited' sets 'justification' to the
+//     'justification' field of the event data.
+//   - 'callback_received' appends the event data, such as
+//     {"name": "ticket_approved"}, to 'callbacks'.
+var reducers = map[string]dialect.InputReducer{
+	"approval_added":       appendReducer("approvals", "user"),
+	"justification_edited": dialect.InputReducerFunc(reduceJustification),
+	"callback_received":    appendReducer("callbacks", "name"),
+}
+
+// appendReducer returns a reducer which appends the event data to
+// the list in the field of the input. The data must have the required field.
+func appendReducer(field, required string) dialect.InputReducer {
+	return dialect.InputReducerFunc(func(input map[string]any, e dialect.InputEvent) (map[string]any, error) {
+		if _, ok := e.Data[required].(string); !ok {
+			return nil, fmt.Errorf("%s events must have a '%s' string", e.Type, required)
+		}
+
+		list, _ := input[field].([]any)
+		input[field] = append(list, e.Data)
+		return input, nil
+	})
+}
+
+func reduceJustification(input map[string]any, e dialect.InputEvent) (map[string]any, error) {
+	j, ok := e.Data["justification"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s events must have a 'justification' string", e.Type)
+	}
+	input["justification"] = j
+	return input, nil
+}
@@ -0,0 +1,42 @@
+package cf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReducers(t *testing.T) {
+	input := map[string]any{}
+	events := []dialect.InputEvent{
+		{Type: "justification_edited", Data: map[string]any{"justification": "incident INC-42"}},
+		{Type: "approval_added", Data: map[string]any{"user": "alice@example.com", "groups": []any{"admins"}}},
+		{Type: "callback_received", Data: map[string]any{"name": "ticket_approved"}},
+	}
+
+	for _, e := range events {
+		var err error
+		input, err = Dialect.Reducers[e.Type].Reduce(input, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.Equal(t, map[string]any{
+		"justification": "incident INC-42",
+		"approvals":     []any{map[string]any{"user": "alice@example.com", "groups": []any{"admins"}}},
+		"callbacks":     []any{map[string]any{"name": "ticket_approved"}},
+	}, input)
+
+	// the input can be decoded by the actions.
+	complete, err := (&Approval{Groups: []string{"admins"}}).Complete(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, complete)
+
+	_, err = Dialect.Reducers["approval_added"].Reduce(input, dialect.InputEvent{Type: "approval_added", Data: map[string]any{}})
+	assert.EqualError(t, err, "approval_added events must have a 'user' string")
+}
@@ -0,0 +1,127 @@
+package cf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultScheduleTimeout is the timeout for requests to schedule providers,
+// if an HTTP client isn't provided.
+const defaultScheduleTimeout = 10 * time.Second
+
+// PagerDuty is a ScheduleProvider which looks up on-call users using the PagerDuty REST API.
+type PagerDuty struct {
+	// Token is a PagerDuty REST API key.
+	Token string
+	// BaseURL defaults to https://api.pagerduty.com.
+	BaseURL string
+	// HTTPClient is used for requests if set.
+	HTTPClient *http.Client
+}
+
+// OnCall returns the email addresses of the users on call for the schedule.
+func (p *PagerDuty) OnCall(ctx context.Context, schedule string) ([]string, error) {
+	base := p.BaseURL
+	if base == "" {
+		base = "https://api.pagerduty.com"
+	}
+
+	q := url.Values{}
+	q.Set("schedule_ids[]", schedule)
+	q.Set("include[]", "users")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/oncalls?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Token token="+p.Token)
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+
+	var res struct {
+		OnCalls []struct {
+			User struct {
+				Email string `json:"email"`
+			} `json:"user"`
+		} `json:"oncalls"`
+	}
+	err = getJSON(httpClient(p.HTTPClient), req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []string
+	for _, o := range res.OnCalls {
+		if o.User.Email != "" {
+			users = append(users, o.User.Email)
+		}
+	}
+	return users, nil
+}
+
+// Opsgenie is a ScheduleProvider which looks up on-call users using the Opsgenie API.
+type Opsgenie struct {
+	// APIKey is an Opsgenie API key.
+	APIKey string
+	// BaseURL defaults to https://api.opsgenie.com.
+	// Use https://api.eu.opsgenie.com for the EU instance.
+	BaseURL string
+	// HTTPClient is used for requests if set.
+	HTTPClient *http.Client
+}
+
+// OnCall returns the usernames, which are email addresses, of the users on call for the schedule.
+func (o *Opsgenie) OnCall(ctx context.Context, schedule string) ([]string, error) {
+	base := o.BaseURL
+	if base == "" {
+		base = "https://api.opsgenie.com"
+	}
+
+	q := url.Values{}
+	q.Set("scheduleIdentifierType", "id")
+	q.Set("flat", "true")
+
+	u := fmt.Sprintf("%s/v2/schedules/%s/on-calls?%s", base, url.PathEscape(schedule), q.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "GenieKey "+o.APIKey)
+
+	var res struct {
+		Data struct {
+			OnCallRecipients []string `json:"onCallRecipients"`
+		} `json:"data"`
+	}
+	err = getJSON(httpClient(o.HTTPClient), req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Data.OnCallRecipients, nil
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return &http.Client{Timeout: defaultScheduleTimeout}
+}
+
+// getJSON makes the request and decodes the JSON response into v.
+func getJSON(c *http.Client, req *http.Request, v any) error {
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", req.URL.Host, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,93 @@
+package cf
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// scimEnterpriseUser is the SCIM enterprise user schema, which contains the user's manager.
+const scimEnterpriseUser = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+
+// SCIM is a Directory which looks up managers using a SCIM 2.0 API (RFC 7644).
+// Users are found by their userName, and managers are read from the
+// enterprise user extension. It is also a GroupDirectory, which finds
+// groups by their displayName.
+type SCIM struct {
+	// BaseURL of the SCIM API, e.g. https://example.okta.com/scim/v2.
+	BaseURL string
+	// Token is sent as a bearer token.
+	Token string
+	// HTTPClient is used for requests if set.
+	HTTPClient *http.Client
+}
+
+type scimUser struct {
+	ID         string `json:"id"`
+	UserName   string `json:"userName"`
+	Enterprise struct {
+		Manager struct {
+			Value string `json:"value"`
+		} `json:"manager"`
+	} `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`
+}
+
+// Manager returns the userName of the user's manager.
+func (s *SCIM) Manager(ctx context.Context, user string) (string, error) {
+	q := url.Values{}
+	q.Set("filter", fmt.Sprintf("userName eq %q", user))
+	q.Set("attributes", "userName,"+scimEnterpriseUser+":manager")
+
+	var list struct {
+		Resources []scimUser `json:"Resources"`
+	}
+	err := s.get(ctx, "/Users?"+q.Encode(), &list)
+	if err != nil {
+		return "", err
+	}
+	if len(list.Resources) == 0 {
+		return "", fmt.Errorf("user %s was not found", user)
+	}
+
+	managerID := list.Resources[0].Enterprise.Manager.Value
+	if managerID == "" {
+		return "", nil
+	}
+
+	var manager scimUser
+	err = s.get(ctx, "/Users/"+url.PathEscape(managerID), &manager)
+	if err != nil {
+		return "", err
+	}
+	return manager.UserName, nil
+}
+
+// GroupExists returns true if a group with the displayName exists.
+func (s *SCIM) GroupExists(ctx context.Context, group string) (bool, error) {
+	q := url.Values{}
+	q.Set("filter", fmt.Sprintf("displayName eq %q", group))
+	q.Set("attributes", "displayName")
+
+	var list struct {
+		Resources []struct {
+			ID string `json:"id"`
+		} `json:"Resources"`
+	}
+	err := s.get(ctx, "/Groups?"+q.Encode(), &list)
+	if err != nil {
+		return false, err
+	}
+	return len(list.Resources) > 0, nil
+}
+
+func (s *SCIM) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(s.BaseURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	req.Header.Set("Accept", "application/scim+json")
+	return getJSON(httpClient(s.HTTPClient), req, v)
+}
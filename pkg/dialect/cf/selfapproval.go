@@ -0,0 +1,66 @@
+package cf
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+)
+
+// WithSelfApprovalPrevented stops the requestor's own approval from
+// completing the dialect's approval actions: 'approval', 'manager_approval'
+// and 'owner_approval'. The requestor is the 'requestor' field of the input,
+// which the input schema must declare, and approval actions aren't complete
+// until it's known, as an approval can't be checked against an unknown requestor.
+//
+// Without this option, a workflow can still exclude the requestor
+// from an approval step with 'exclude_requestor: true'.
+func WithSelfApprovalPrevented() Option {
+	return func(c *config) {
+		c.preventSelfApproval = true
+	}
+}
+
+// errRequestorNotDeclared is returned when compiling a workflow with an
+// approval action if self-approval is prevented, but the input schema
+// doesn't declare the requestor.
+var errRequestorNotDeclared = errors.New("self-approval is prevented, so the input schema must declare a 'requestor' string field")
+
+// errExcludedRequestorNotDeclared is returned when compiling a workflow with
+// an approval step which sets 'exclude_requestor', but the input schema
+// doesn't declare the requestor.
+var errExcludedRequestorNotDeclared = errors.New("exclude_requestor is set, so the input schema must declare a 'requestor' string field")
+
+// validateRequestor checks that the input schema declares the requestor
+// as a string, if self-approval is prevented.
+func validateRequestor(prevent bool, schema *jsoncel.Schema) error {
+	if prevent && !declaresRequestor(schema) {
+		return errRequestorNotDeclared
+	}
+	return nil
+}
+
+// declaresRequestor returns true if the input schema declares the requestor as a string.
+func declaresRequestor(schema *jsoncel.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	requestor := jsoncel.Lookup(schema, "requestor")
+	return requestor != nil && (requestor.Type == "" || requestor.Type == jsoncel.String)
+}
+
+// approvalsExcludingRequestor returns the approvals in the input
+// which weren't made by the requestor. It returns false if
+// the requestor isn't known, so the approvals can't be checked.
+func approvalsExcludingRequestor(i Input) ([]ApprovalInput, bool) {
+	if i.Requestor == "" {
+		return nil, false
+	}
+	var approvals []ApprovalInput
+	for _, a := range i.Approvals {
+		if !strings.EqualFold(a.User, i.Requestor) {
+			approvals = append(approvals, a)
+		}
+	}
+	return approvals, true
+}
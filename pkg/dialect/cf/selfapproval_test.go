@@ -0,0 +1,125 @@
+package cf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfApprovalPrevented_Complete(t *testing.T) {
+	d := New(WithSelfApprovalPrevented(), WithDirectory(StaticDirectory{"alice@example.com": "alice@example.com"}))
+	owners := Owners{{Pattern: "**", Groups: []string{"admins"}}}
+
+	tests := []struct {
+		name   string
+		action string
+		input  map[string]any
+		want   bool
+	}{
+		{
+			name:   "approval by the requestor",
+			action: "approval",
+			input: map[string]any{
+				"requestor": "alice@example.com",
+				"approvals": []any{map[string]any{"user": "Alice@example.com", "groups": []any{"admins"}}},
+			},
+			want: false,
+		},
+		{
+			name:   "approval by someone else",
+			action: "approval",
+			input: map[string]any{
+				"requestor": "alice@example.com",
+				"approvals": []any{map[string]any{"user": "bob@example.com", "groups": []any{"admins"}}},
+			},
+			want: true,
+		},
+		{
+			name:   "approval with an unknown requestor",
+			action: "approval",
+			input: map[string]any{
+				"approvals": []any{map[string]any{"user": "bob@example.com", "groups": []any{"admins"}}},
+			},
+			want: false,
+		},
+		{
+			name:   "manager approval by the requestor",
+			action: "manager_approval",
+			input: map[string]any{
+				"requestor": "alice@example.com",
+				"approvals": []any{map[string]any{"user": "alice@example.com"}},
+			},
+			want: false,
+		},
+		{
+			name:   "owner approval by the requestor",
+			action: "owner_approval",
+			input: map[string]any{
+				"requestor": "alice@example.com",
+				"resource":  "prod",
+				"owners":    owners,
+				"approvals": []any{map[string]any{"user": "alice@example.com", "groups": []any{"admins"}}},
+			},
+			want: false,
+		},
+		{
+			name:   "owner approval by someone else",
+			action: "owner_approval",
+			input: map[string]any{
+				"requestor": "alice@example.com",
+				"resource":  "prod",
+				"owners":    owners,
+				"approvals": []any{map[string]any{"user": "bob@example.com", "groups": []any{"admins"}}},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := d.Actions()[tt.action].(interface {
+				Complete(ctx context.Context, input any) (bool, error)
+			})
+			if a, ok := a.(*Approval); ok {
+				a.Groups = []string{"admins"}
+			}
+			got, err := a.Complete(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelfApprovalPrevented_ValidateCompile(t *testing.T) {
+	declared := &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"requestor": {Type: jsoncel.String},
+		},
+	}
+	wrongType := &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"requestor": {Type: jsoncel.Integer},
+		},
+	}
+
+	a := &Approval{Groups: []string{"admins"}, Count: 1, preventSelfApproval: true}
+	assert.NoError(t, a.ValidateCompile(nil, declared))
+	assert.EqualError(t, a.ValidateCompile(nil, nil), "self-approval is prevented, so the input schema must declare a 'requestor' string field")
+	assert.EqualError(t, a.ValidateCompile(nil, wrongType), "self-approval is prevented, so the input schema must declare a 'requestor' string field")
+
+	m := &ManagerApproval{preventSelfApproval: true}
+	assert.NoError(t, m.ValidateCompile(nil, declared))
+	assert.Error(t, m.ValidateCompile(nil, &jsoncel.Schema{Type: jsoncel.Object}))
+
+	o := &OwnerApproval{preventSelfApproval: true}
+	assert.NoError(t, o.ValidateCompile(nil, declared))
+	assert.Error(t, o.ValidateCompile(nil, nil))
+
+	// without the option, the requestor doesn't need to be declared.
+	assert.NoError(t, (&OwnerApproval{}).ValidateCompile(nil, nil))
+}
@@ -0,0 +1,164 @@
+package cf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Webhook is an action which calls an external system, such as a ticketing
+// system, and waits for the system to respond with a callback.
+//
+// Glide doesn't send the webhook itself. When the action is activated, the call
+// is returned as a WebhookCall in the execution result's Effects for the
+// host application to deliver. The action is complete when a callback with
+// a matching name is present in the input's 'callbacks' field.
+//
+// For example:
+//
+//	action: webhook
+//	with:
+//	  url: https://tickets.example.com/api/tickets
+//	  payload:
+//	    summary: Access requested by {{ .requestor }}
+//	  callback: ticket_approved
+type Webhook struct {
+	// URL to call.
+	URL string `yaml:"url" doc:"the URL to call"`
+	// Method defaults to POST.
+	Method string `yaml:"method" doc:"the HTTP method, which defaults to POST"`
+	// Payload is the JSON request body, given as YAML or as a string
+	// of JSON. Its strings are Go templates which are executed with the
+	// workflow input, and the body is encoded as JSON once they're rendered,
+	// so that values from the input can't change the structure of the body.
+	Payload any `yaml:"payload" doc:"the JSON request body, whose strings are Go templates executed with the input"`
+	// Callback is the name of the callback event which completes the action.
+	Callback string `yaml:"callback" doc:"the name of the callback event which completes the action"`
+}
+
+// WebhookCall is an outbound call for the host application to deliver.
+type WebhookCall struct {
+	URL      string `json:"url"`
+	Method   string `json:"method"`
+	Payload  string `json:"payload,omitempty"`
+	Callback string `json:"callback"`
+}
+
+// CallbackInput is a callback event from an external system.
+type CallbackInput struct {
+	// Name of the callback, matching the 'callback' of a webhook action.
+	Name string `mapstructure:"name"`
+}
+
+// Complete returns true if the input contains the webhook's callback.
+func (w *Webhook) Complete(ctx context.Context, input any) (bool, error) {
+	if w.Callback == "" {
+		return false, fmt.Errorf("webhook to %s must have a callback", w.URL)
+	}
+
+	var i Input
+	err := mapstructure.Decode(input, &i)
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range i.Callbacks {
+		if c.Name == w.Callback {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Effect returns the call for the host application to deliver.
+func (w *Webhook) Effect(ctx context.Context, input any) (any, error) {
+	if w.URL == "" {
+		return nil, fmt.Errorf("webhook must have a url")
+	}
+
+	method := strings.ToUpper(w.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	call := WebhookCall{
+		URL:      w.URL,
+		Method:   method,
+		Callback: w.Callback,
+	}
+
+	payload := w.Payload
+	if s, ok := payload.(string); ok && s != "" {
+		err := json.Unmarshal([]byte(s), &payload)
+		if err != nil {
+			return nil, fmt.Errorf("webhook payload must be JSON, with templates inside its strings: %w", err)
+		}
+	}
+
+	if payload != nil && payload != "" {
+		rendered, err := renderPayload(payload, input)
+		if err != nil {
+			return nil, fmt.Errorf("rendering webhook payload: %w", err)
+		}
+		body, err := json.Marshal(rendered)
+		if err != nil {
+			return nil, fmt.Errorf("encoding webhook payload: %w", err)
+		}
+		call.Payload = string(body)
+	}
+
+	return call, nil
+}
+
+// renderPayload executes the templates in the strings of a payload with the input.
+func renderPayload(payload any, input any) (any, error) {
+	switch v := payload.(type) {
+	case string:
+		tmpl, err := template.New("payload").Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, input)
+		if err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			r, err := renderPayload(e, input)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			r, err := renderPayload(e, input)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+	return payload, nil
+}
+
+func (w *Webhook) Doc() string {
+	return "Calls a URL and is complete when the external system responds with a callback."
+}
+
+func (w *Webhook) PrintAction() string {
+	return fmt.Sprintf("calling %s and waiting for the %s callback", w.URL, w.Callback)
+}
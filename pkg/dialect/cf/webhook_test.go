@@ -0,0 +1,150 @@
+package cf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Complete(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    Webhook
+		input   map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:  "callback received",
+			give:  Webhook{URL: "https://example.com", Callback: "ticket_approved"},
+			input: map[string]any{"callbacks": []any{map[string]any{"name": "ticket_approved"}}},
+			want:  true,
+		},
+		{
+			name:  "other callback received",
+			give:  Webhook{URL: "https://example.com", Callback: "ticket_approved"},
+			input: map[string]any{"callbacks": []any{map[string]any{"name": "ticket_created"}}},
+			want:  false,
+		},
+		{
+			name:  "no callbacks",
+			give:  Webhook{URL: "https://example.com", Callback: "ticket_approved"},
+			input: map[string]any{},
+			want:  false,
+		},
+		{
+			name:    "no callback name",
+			give:    Webhook{URL: "https://example.com"},
+			input:   map[string]any{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.give.Complete(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Webhook.Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWebhook_Effect(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    Webhook
+		input   map[string]any
+		want    any
+		wantErr bool
+	}{
+		{
+			name:  "ok",
+			give:  Webhook{URL: "https://example.com", Payload: `{"user": "{{ .requestor }}"}`, Callback: "done"},
+			input: map[string]any{"requestor": "alice@example.com"},
+			want: WebhookCall{
+				URL:      "https://example.com",
+				Method:   "POST",
+				Payload:  `{"user":"alice@example.com"}`,
+				Callback: "done",
+			},
+		},
+		{
+			// values from the input are escaped, so they can't change the payload.
+			name:  "escaped",
+			give:  Webhook{URL: "https://example.com", Payload: `{"summary": "Access requested by {{ .requestor }}"}`, Callback: "done"},
+			input: map[string]any{"requestor": `alice", "approved": true, "x": "`},
+			want: WebhookCall{
+				URL:      "https://example.com",
+				Method:   "POST",
+				Payload:  `{"summary":"Access requested by alice\", \"approved\": true, \"x\": \""}`,
+				Callback: "done",
+			},
+		},
+		{
+			name:  "structured",
+			give:  Webhook{URL: "https://example.com", Payload: map[string]any{"user": "{{ .requestor }}", "labels": []any{"access", 1}}, Callback: "done"},
+			input: map[string]any{"requestor": "alice@example.com"},
+			want: WebhookCall{
+				URL:      "https://example.com",
+				Method:   "POST",
+				Payload:  `{"labels":["access",1],"user":"alice@example.com"}`,
+				Callback: "done",
+			},
+		},
+		{
+			name:    "template outside of a string",
+			give:    Webhook{URL: "https://example.com", Payload: `{"user": {{ .requestor }}}`, Callback: "done"},
+			input:   map[string]any{"requestor": "alice@example.com"},
+			wantErr: true,
+		},
+		{
+			name: "method",
+			give: Webhook{URL: "https://example.com", Method: "put", Callback: "done"},
+			want: WebhookCall{URL: "https://example.com", Method: "PUT", Callback: "done"},
+		},
+		{
+			name:    "missing input field in payload",
+			give:    Webhook{URL: "https://example.com", Payload: `{"user": "{{ .other }}"}`, Callback: "done"},
+			input:   map[string]any{},
+			wantErr: true,
+		},
+		{
+			name:    "no url",
+			give:    Webhook{Callback: "done"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.give.Effect(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Webhook.Effect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWebhook_PayloadYAML(t *testing.T) {
+	var w Webhook
+	err := yaml.Unmarshal([]byte(`
+url: https://example.com
+callback: done
+payload:
+  summary: Access requested by {{ .requestor }}
+  fields:
+    duration: "{{ .duration }}"
+`), &w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := w.Effect(context.Background(), map[string]any{"requestor": `"alice"`, "duration": "2h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `{"fields":{"duration":"2h"},"summary":"Access requested by \"alice\""}`, got.(WebhookCall).Payload)
+}
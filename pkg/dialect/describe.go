@@ -0,0 +1,185 @@
+package dialect
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+)
+
+// Documenter is implemented by actions which describe what they do,
+// for documentation and workflow editors.
+type Documenter interface {
+	Doc() string
+}
+
+// Description is a machine-readable description of a dialect,
+// used to generate documentation and forms in workflow editors.
+type Description struct {
+	// Nodes are sorted by type, with start nodes first,
+	// then by priority from highest to lowest, then by ID.
+	Nodes []NodeDescription `json:"nodes"`
+	// Actions are sorted by name.
+	Actions []ActionDescription `json:"actions"`
+	// Macros are sorted by name.
+	Macros         []MacroDescription `json:"macros,omitempty"`
+	DefaultOutcome string             `json:"defaultOutcome,omitempty"`
+	// Input is the schema of the input fields which the dialect's actions read.
+	Input *jsoncel.Schema `json:"input,omitempty"`
+}
+
+// NodeDescription describes a start or outcome node.
+type NodeDescription struct {
+	ID string `json:"id"`
+	// Type is 'start' or 'outcome'.
+	Type     string            `json:"type"`
+	Name     string            `json:"name,omitempty"`
+	Names    map[string]string `json:"names,omitempty"`
+	Priority int               `json:"priority"`
+	Metadata map[string]any    `json:"metadata,omitempty"`
+}
+
+// ActionDescription describes an action.
+type ActionDescription struct {
+	Name string `json:"name"`
+	// Doc is returned by the action's Doc method, if it implements Documenter.
+	Doc string `json:"doc,omitempty"`
+	// With is the schema of the action's 'with' properties. It is derived
+	// from the 'yaml' tags of the action struct, and the 'doc' tags of its
+	// fields are used as the property descriptions.
+	With *jsoncel.Schema `json:"with,omitempty"`
+	// Outputs is the schema of the action's outputs,
+	// if it declares them with an OutputSchema method.
+	Outputs *jsoncel.Schema `json:"outputs,omitempty"`
+}
+
+// MacroDescription describes a macro.
+type MacroDescription struct {
+	Name       string   `json:"name"`
+	Params     []string `json:"params,omitempty"`
+	Expression string   `json:"expression"`
+}
+
+// Describe returns a machine-readable description
+// of the nodes, actions and macros in the dialect.
+func (d *Dialect) Describe() Description {
+	desc := Description{
+		Nodes:          []NodeDescription{},
+		Actions:        []ActionDescription{},
+		DefaultOutcome: d.DefaultOutcome,
+		Input:          d.InputSchema,
+	}
+
+	for id, n := range d.Nodes {
+		desc.Nodes = append(desc.Nodes, NodeDescription{
+			ID:       id,
+			Type:     n.Type.String(),
+			Name:     n.Name,
+			Names:    n.Names,
+			Priority: n.Priority,
+			Metadata: n.Metadata,
+		})
+	}
+	sort.Slice(desc.Nodes, func(i, j int) bool {
+		a, b := desc.Nodes[i], desc.Nodes[j]
+		if a.Type != b.Type {
+			return a.Type == "start"
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.ID < b.ID
+	})
+
+	if d.Actions != nil {
+		for name, action := range d.Actions() {
+			a := ActionDescription{Name: name}
+			if doc, ok := action.(Documenter); ok {
+				a.Doc = doc.Doc()
+			}
+			if action != nil {
+				a.With = typeSchema(reflect.TypeOf(action))
+			}
+			if o, ok := action.(interface{ OutputSchema() *jsoncel.Schema }); ok {
+				a.Outputs = o.OutputSchema()
+			}
+			desc.Actions = append(desc.Actions, a)
+		}
+	}
+	sort.Slice(desc.Actions, func(i, j int) bool {
+		return desc.Actions[i].Name < desc.Actions[j].Name
+	})
+
+	for name, m := range d.Macros {
+		desc.Macros = append(desc.Macros, MacroDescription{Name: name, Params: m.Params, Expression: m.Expression})
+	}
+	sort.Slice(desc.Macros, func(i, j int) bool {
+		return desc.Macros[i].Name < desc.Macros[j].Name
+	})
+
+	return desc
+}
+
+// typeSchema returns the schema of a Go type as it is decoded from YAML.
+// Interface types have an empty schema, as they accept any value.
+func typeSchema(t reflect.Type) *jsoncel.Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &jsoncel.Schema{Type: jsoncel.String}
+	case reflect.Bool:
+		return &jsoncel.Schema{Type: jsoncel.Boolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsoncel.Schema{Type: jsoncel.Integer}
+	case reflect.Float32, reflect.Float64:
+		return &jsoncel.Schema{Type: jsoncel.Number}
+	case reflect.Slice, reflect.Array:
+		return &jsoncel.Schema{Type: jsoncel.Array, Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &jsoncel.Schema{Type: jsoncel.Object, AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Struct:
+		s := &jsoncel.Schema{Type: jsoncel.Object, Properties: map[string]*jsoncel.Schema{}}
+		addStructProperties(s, t)
+		return s
+	}
+	return &jsoncel.Schema{}
+}
+
+// addStructProperties adds the exported fields of a struct to an object schema,
+// named by their 'yaml' tags. Inline structs are flattened into the schema.
+func addStructProperties(s *jsoncel.Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructProperties(s, ft)
+			}
+			continue
+		}
+		if name == "" {
+			// the YAML decoder matches untagged fields by their lowercased name.
+			name = strings.ToLower(f.Name)
+		}
+
+		p := typeSchema(f.Type)
+		p.Description = f.Tag.Get("doc")
+		s.Properties[name] = p
+	}
+}
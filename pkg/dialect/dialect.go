@@ -5,9 +5,16 @@ package dialect
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"time"
 
+	"github.com/common-fate/glide/pkg/jsoncel"
 	"github.com/common-fate/glide/pkg/node"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/parser"
 )
 
 type contextKey int
@@ -24,8 +31,138 @@ type Dialect struct {
 	// belong in a workflow for the start and end.
 	Nodes   map[string]node.Node
 	Actions func() map[string]any
+
+	// Macros are named expressions which can be
+	// used in workflow checks, e.g. 'oncall()'.
+	// They are expanded before the check is type-checked.
+	Macros map[string]Macro
+
+	// Functions are custom functions which can be
+	// called in workflow checks, e.g. 'business_hours(input.requested_at)'.
+	Functions map[string]Function
+
+	// Outcomes are handlers for outcome nodes, keyed by node ID.
+	// They are called by an execution driver when a workflow reaches
+	// the outcome, to carry out its side effects, such as granting access.
+	// Executing a workflow never calls them.
+	Outcomes map[string]OutcomeHandler
+
+	// Reducers fold the events of a request, such as an approval being
+	// added, into the input of the workflow, keyed by event type.
+	// Hosts use them to build the input from events with glide.ReduceInput,
+	// rather than each assembling the input themselves.
+	Reducers map[string]InputReducer
+
+	// DefaultOutcome is the ID of the outcome node which is the result of
+	// a workflow when no other outcome is reached, e.g. 'pending_review'.
+	// Workflows can override it with the top-level 'default_outcome' field.
+	DefaultOutcome string
+
+	// InputSchema declares the fields of the input which the dialect's
+	// actions read, such as the approvals of a request. It is merged with
+	// the input schema when a workflow is compiled, so that checks which
+	// reference the fields are type-checked, and the workflow fails to
+	// compile if the input schema declares them with different types.
+	InputSchema *jsoncel.Schema
+}
+
+// OutcomeHandler carries out the side effects of a workflow outcome.
+//
+// Workflows are executed each time their input changes, so a handler
+// may be called more than once for the same request and must be idempotent.
+type OutcomeHandler interface {
+	OnOutcome(ctx context.Context, o Outcome) error
+}
+
+// OutcomeHandlerFunc is a function which handles outcomes.
+type OutcomeHandlerFunc func(ctx context.Context, o Outcome) error
+
+// OnOutcome calls f(ctx, o).
+func (f OutcomeHandlerFunc) OnOutcome(ctx context.Context, o Outcome) error {
+	return f(ctx, o)
 }
 
+// Outcome is a workflow execution which reached an outcome.
+type Outcome struct {
+	// ID is the ID of the outcome node, e.g. 'approved'.
+	ID string
+	// Node is the outcome node, including any metadata.
+	Node node.Node
+	// Start is the ID of the start node the workflow was executed from.
+	Start string
+	// Input is the input the workflow was executed with.
+	Input map[string]any
+	// Effects are the side effects of the active actions, keyed by step ID.
+	Effects map[string]any
+}
+
+// InputEvent is a discrete change to the input of a request,
+// such as an approval being added or a justification being edited.
+type InputEvent struct {
+	// Type of the event, e.g. 'approval_added'.
+	Type string
+	// Time the event occurred.
+	Time time.Time
+	// Data of the event, such as the user who approved the request.
+	Data map[string]any
+}
+
+// InputReducer folds an event into the input of a workflow.
+//
+// Reducers are given a copy of the input, which they can modify
+// and return, and must return the same input for the same event.
+type InputReducer interface {
+	Reduce(input map[string]any, e InputEvent) (map[string]any, error)
+}
+
+// InputReducerFunc is a function which reduces events.
+type InputReducerFunc func(input map[string]any, e InputEvent) (map[string]any, error)
+
+// Reduce calls f(input, e).
+func (f InputReducerFunc) Reduce(input map[string]any, e InputEvent) (map[string]any, error) {
+	return f(input, e)
+}
+
+// Macro is a named check expression provided by a dialect.
+// Macros allow policy authors to write checks like
+//
+//   - check: in_group("admins")
+//
+// without needing to know the layout of the input schema.
+type Macro struct {
+	// Params are the names of the macro arguments, e.g. ["group"].
+	Params []string
+
+	// Expression is the CEL expression that the macro expands to.
+	// Arguments are referenced using their parameter names, e.g.
+	//
+	//	group in input.groups
+	//
+	// Macro expressions cannot reference other macros.
+	Expression string
+}
+
+// Function is a custom CEL function provided by a dialect.
+type Function struct {
+	// Overloads declare the argument and result types of the function
+	// and bind its implementation, e.g.
+	//
+	//	cel.Overload("business_hours_timestamp",
+	//		[]*cel.Type{cel.TimestampType}, cel.BoolType,
+	//		cel.UnaryBinding(businessHours))
+	Overloads []cel.FunctionOpt
+
+	// Impure is set if the function has side effects, or can return
+	// different results for the same arguments, such as a function which
+	// reads the current time or calls an external system.
+	// Checks which call impure functions are reported when a workflow
+	// is compiled, as their decisions aren't a pure function of the input.
+	Impure bool
+}
+
+// identRegex matches valid CEL identifiers.
+var identRegex = regexp.MustCompile(`^[_a-zA-Z][_a-zA-Z0-9]*$`)
+
 // Context returns a copy of the parent context,
 // with the Glide dialect defined.
 func Context(parent context.Context, d Dialect) context.Context {
@@ -63,6 +200,58 @@ func (d *Dialect) Validate() error {
 			priorityMap[n.Priority] = true
 		}
 	}
+
+	for id := range d.Outcomes {
+		n, ok := d.Nodes[id]
+		if !ok || n.Type != node.Outcome {
+			return fmt.Errorf("dialect error: outcome handler %s must be for an outcome node", id)
+		}
+	}
+
+	if d.DefaultOutcome != "" {
+		n, ok := d.Nodes[d.DefaultOutcome]
+		if !ok || n.Type != node.Outcome {
+			return fmt.Errorf("dialect error: default outcome %s must be an outcome node", d.DefaultOutcome)
+		}
+	}
+
+	for name, m := range d.Macros {
+		if !identRegex.MatchString(name) {
+			return fmt.Errorf("dialect error: macro name %q is not a valid identifier", name)
+		}
+		for _, param := range m.Params {
+			if !identRegex.MatchString(param) {
+				return fmt.Errorf("dialect error: macro %s has invalid parameter name %q", name, param)
+			}
+		}
+		_, errs := parser.Parse(common.NewTextSource(m.Expression))
+		if len(errs.GetErrors()) > 0 {
+			return fmt.Errorf("dialect error: macro %s has an invalid expression: %s", name, errs.ToDisplayString())
+		}
+	}
+
+	if d.InputSchema != nil && d.InputSchema.Type != jsoncel.Object {
+		return errors.New("dialect error: input schema must be an object")
+	}
+
+	for typ, r := range d.Reducers {
+		if r == nil {
+			return fmt.Errorf("dialect error: reducer for event type %s must not be nil", typ)
+		}
+	}
+
+	for name, f := range d.Functions {
+		if !identRegex.MatchString(name) {
+			return fmt.Errorf("dialect error: function name %q is not a valid identifier", name)
+		}
+		if _, ok := d.Macros[name]; ok {
+			return fmt.Errorf("dialect error: function %s has the same name as a macro", name)
+		}
+		if len(f.Overloads) == 0 {
+			return fmt.Errorf("dialect error: function %s must have at least one overload", name)
+		}
+	}
+
 	// all good if we get here
 	return nil
 }
@@ -83,3 +272,12 @@ func (d *Dialect) End(names ...string) *Dialect {
 	}
 	return d
 }
+
+// OnOutcome sets the handler for the outcome node with the name.
+func (d *Dialect) OnOutcome(name string, h OutcomeHandler) *Dialect {
+	if d.Outcomes == nil {
+		d.Outcomes = map[string]OutcomeHandler{}
+	}
+	d.Outcomes[name] = h
+	return d
+}
@@ -0,0 +1,159 @@
+// Package interpolate replaces variable references like
+// '${var.team_slack_channel}' in workflow values.
+//
+// Interpolation is applied when a workflow is unmarshalled, rather than
+// by templating the YAML beforehand, so that errors keep their positions
+// in the original workflow file.
+//
+// Two namespaces are supported:
+//
+//	${var.<name>}  a variable provided by the caller
+//	${env.<name>}  an environment variable provided by the caller
+//
+// Environment variables are not read from the process environment
+// unless the caller provides them, so interpolation is always controlled.
+// References to '${matrix.<name>}' and '${each.<name>}' are left as they are,
+// to be replaced when matrix passes and for_each steps are expanded.
+// A literal '${' can be written by escaping it as '$${'.
+package interpolate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/goccy/go-yaml/ast"
+)
+
+type contextKey int
+
+const (
+	varsKey contextKey = iota
+)
+
+// Vars are the values available for interpolation.
+type Vars struct {
+	// Var are the values for '${var.<name>}' references.
+	Var map[string]string
+	// Env are the values for '${env.<name>}' references.
+	Env map[string]string
+}
+
+// Context returns a copy of the parent context,
+// with the interpolation variables defined.
+func Context(parent context.Context, v Vars) context.Context {
+	return context.WithValue(parent, varsKey, v)
+}
+
+// FromContext returns the interpolation variables from the context.
+// If no variables are defined, an empty Vars is returned.
+func FromContext(ctx context.Context) Vars {
+	v, _ := ctx.Value(varsKey).(Vars)
+	return v
+}
+
+// String interpolates the variable references in a string.
+// An error is returned if a referenced variable is not defined.
+func (v Vars) String(s string) (string, error) {
+	var b strings.Builder
+
+	for {
+		i := strings.Index(s, "${")
+		if i == -1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		// '$${' is an escaped '${'
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1])
+			b.WriteString("${")
+			s = s[i+2:]
+			continue
+		}
+
+		b.WriteString(s[:i])
+
+		end := strings.Index(s[i:], "}")
+		if end == -1 {
+			return "", fmt.Errorf("unterminated variable reference %q", s[i:])
+		}
+
+		ref := s[i+2 : i+end]
+		val, err := v.lookup(ref)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(val)
+
+		s = s[i+end+1:]
+	}
+}
+
+// lookup the value for a reference like 'var.team_slack_channel'.
+func (v Vars) lookup(ref string) (string, error) {
+	namespace, name, ok := strings.Cut(strings.TrimSpace(ref), ".")
+	if !ok || name == "" {
+		return "", fmt.Errorf("invalid variable reference ${%s}: expected ${var.<name>} or ${env.<name>}", ref)
+	}
+
+	var values map[string]string
+	switch namespace {
+	case "var":
+		values = v.Var
+	case "env":
+		values = v.Env
+	case "matrix", "each":
+		// matrix and for_each references are replaced when the steps are expanded.
+		return "${" + namespace + "." + name + "}", nil
+	default:
+		return "", fmt.Errorf("invalid variable reference ${%s}: unknown namespace %q", ref, namespace)
+	}
+
+	val, ok := values[name]
+	if !ok {
+		return "", fmt.Errorf("undefined variable ${%s}", ref)
+	}
+	return val, nil
+}
+
+// Node interpolates the variable references in all of the
+// string values in a YAML node, modifying the node in place.
+// Mapping keys are not interpolated.
+//
+// If a referenced variable is not defined,
+// a noderr.NodeError for the string value is returned.
+func (v Vars) Node(n ast.Node) error {
+	switch t := n.(type) {
+	case *ast.StringNode:
+		val, err := v.String(t.Value)
+		if err != nil {
+			return noderr.Wrap(err, t)
+		}
+		t.Value = val
+	case *ast.LiteralNode:
+		return v.Node(t.Value)
+	case *ast.MappingNode:
+		for _, mv := range t.Values {
+			err := v.Node(mv.Value)
+			if err != nil {
+				return err
+			}
+		}
+	case *ast.MappingValueNode:
+		return v.Node(t.Value)
+	case *ast.SequenceNode:
+		for _, child := range t.Values {
+			err := v.Node(child)
+			if err != nil {
+				return err
+			}
+		}
+	case *ast.TagNode:
+		return v.Node(t.Value)
+	case *ast.AnchorNode:
+		return v.Node(t.Value)
+	}
+	return nil
+}
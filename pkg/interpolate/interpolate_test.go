@@ -0,0 +1,47 @@
+package interpolate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVars_String(t *testing.T) {
+	v := Vars{
+		Var: map[string]string{"team": "platform", "channel": "#alerts"},
+		Env: map[string]string{"STAGE": "prod"},
+	}
+
+	tests := []struct {
+		name    string
+		give    string
+		want    string
+		wantErr string
+	}{
+		{name: "no references", give: "hello world", want: "hello world"},
+		{name: "var", give: "${var.team}", want: "platform"},
+		{name: "env", give: "${env.STAGE}", want: "prod"},
+		{name: "multiple", give: "${var.team} approval (${env.STAGE})", want: "platform approval (prod)"},
+		{name: "whitespace", give: "${ var.team }", want: "platform"},
+		{name: "escaped", give: "$${var.team} is ${var.team}", want: "${var.team} is platform"},
+		{name: "lone dollar", give: "costs $5", want: "costs $5"},
+		{name: "matrix", give: "approve ${ matrix.resource }", want: "approve ${matrix.resource}"},
+		{name: "undefined", give: "${var.other}", wantErr: "undefined variable ${var.other}"},
+		{name: "unknown namespace", give: "${foo.bar}", wantErr: `invalid variable reference ${foo.bar}: unknown namespace "foo"`},
+		{name: "no namespace", give: "${team}", wantErr: "invalid variable reference ${team}: expected ${var.<name>} or ${env.<name>}"},
+		{name: "unterminated", give: "${var.team", wantErr: `unterminated variable reference "${var.team"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.String(tt.give)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package jsoncel
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChangeKind is the kind of a change between two versions of a schema.
+type ChangeKind string
+
+const (
+	FieldAdded       ChangeKind = "field_added"
+	FieldRemoved     ChangeKind = "field_removed"
+	TypeChanged      ChangeKind = "type_changed"
+	EnumValueAdded   ChangeKind = "enum_value_added"
+	EnumValueRemoved ChangeKind = "enum_value_removed"
+	ConstChanged     ChangeKind = "const_changed"
+	RequiredRemoved  ChangeKind = "required_removed"
+)
+
+// Change is a change to a field between two versions of a schema.
+type Change struct {
+	// Path is the dot-separated path of the field, e.g. 'group.id'.
+	// The items of arrays are suffixed with '[]', e.g. 'approvers[]',
+	// and the values of maps with '{}', e.g. 'labels{}'.
+	Path string `json:"path"`
+
+	Kind ChangeKind `json:"kind"`
+
+	// Breaking is true if workflows which reference the field
+	// may fail to compile or behave differently with the new schema.
+	Breaking bool `json:"breaking"`
+
+	// Message describes the change, e.g. 'type changed from string to integer'.
+	Message string `json:"message"`
+}
+
+func (c Change) String() string {
+	return c.Path + ": " + c.Message
+}
+
+// CheckCompatibility compares the schema before and after a change, and returns
+// the changes to their fields sorted by path. Use BreakingChanges to find
+// the changes which may invalidate workflows referencing the fields.
+//
+// Changes are breaking if they remove a field or an enum value, change
+// the type of a field or its const value, or make a required field optional,
+// as checks may no longer compile, match or have a value to evaluate.
+// Adding a field or an enum value isn't breaking.
+func CheckCompatibility(before, after *Schema) []Change {
+	var changes []Change
+	compareSchemas("", before, after, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// BreakingChanges returns the changes which are breaking.
+func BreakingChanges(changes []Change) []Change {
+	var out []Change
+	for _, c := range changes {
+		if c.Breaking {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func compareSchemas(path string, before, after *Schema, changes *[]Change) {
+	if before == nil || after == nil {
+		return
+	}
+
+	add := func(path string, kind ChangeKind, breaking bool, format string, args ...any) {
+		*changes = append(*changes, Change{Path: path, Kind: kind, Breaking: breaking, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if bt, at := declaredType(before), declaredType(after); bt != at {
+		add(path, TypeChanged, true, "type changed from %s to %s", typeName(bt), typeName(at))
+		return
+	}
+
+	if before.Const != nil && !equal(normalize(before.Const), normalize(after.Const)) {
+		if after.Const == nil {
+			add(path, ConstChanged, true, "const %s was removed", describe(before.Const))
+		} else {
+			add(path, ConstChanged, true, "const changed from %s to %s", describe(before.Const), describe(after.Const))
+		}
+	}
+
+	if len(before.Enum) > 0 || len(after.Enum) > 0 {
+		for _, v := range before.Enum {
+			if len(after.Enum) > 0 && !containsValue(after.Enum, v) {
+				add(path, EnumValueRemoved, true, "enum value %s was removed", describe(v))
+			}
+		}
+		for _, v := range after.Enum {
+			if len(before.Enum) == 0 {
+				add(path, EnumValueRemoved, true, "values are now restricted to an enum")
+				break
+			}
+			if !containsValue(before.Enum, v) {
+				add(path, EnumValueAdded, false, "enum value %s was added", describe(v))
+			}
+		}
+	}
+
+	required := map[string]bool{}
+	for _, k := range after.Required {
+		required[k] = true
+	}
+	for _, k := range before.Required {
+		if _, ok := after.Properties[k]; ok && !required[k] {
+			add(join(path, k), RequiredRemoved, true, "is no longer required")
+		}
+	}
+
+	for k, bp := range before.Properties {
+		ap, ok := after.Properties[k]
+		if !ok {
+			add(join(path, k), FieldRemoved, true, "field was removed")
+			continue
+		}
+		compareSchemas(join(path, k), bp, ap, changes)
+	}
+	for k := range after.Properties {
+		if _, ok := before.Properties[k]; !ok {
+			add(join(path, k), FieldAdded, false, "field was added")
+		}
+	}
+
+	compareSchemas(path+"[]", itemSchema(before), itemSchema(after), changes)
+	compareSchemas(path+"{}", mapValueSchema(before), mapValueSchema(after), changes)
+}
+
+func containsValue(values []any, v any) bool {
+	for _, e := range values {
+		if equal(normalize(e), normalize(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(t FieldType) string {
+	if t == "" {
+		return "any"
+	}
+	return string(t)
+}
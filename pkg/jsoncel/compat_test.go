@@ -0,0 +1,83 @@
+package jsoncel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	parse := func(s string) *Schema {
+		var out Schema
+		if err := json.Unmarshal([]byte(s), &out); err != nil {
+			t.Fatal(err)
+		}
+		return &out
+	}
+
+	before := parse(`{
+		"type": "object",
+		"required": ["group"],
+		"properties": {
+			"group": {"type": "string"},
+			"duration": {"type": "integer"},
+			"env": {"enum": ["dev", "staging", "prod"]},
+			"approvers": {"type": "array", "items": {"type": "string"}},
+			"user": {
+				"type": "object",
+				"properties": {
+					"email": {"type": "string"},
+					"manager": {"type": "string"}
+				}
+			}
+		}
+	}`)
+	after := parse(`{
+		"type": "object",
+		"properties": {
+			"group": {"type": "string"},
+			"duration": {"type": "number"},
+			"env": {"enum": ["dev", "prod", "sandbox"]},
+			"approvers": {"type": "array", "items": {"type": "object"}},
+			"user": {
+				"type": "object",
+				"properties": {
+					"email": {"type": "string"},
+					"team": {"type": "string"}
+				}
+			}
+		}
+	}`)
+
+	got := CheckCompatibility(before, after)
+
+	var msgs []string
+	for _, c := range got {
+		msgs = append(msgs, c.String())
+	}
+	assert.Equal(t, []string{
+		"approvers[]: type changed from string to object",
+		"duration: type changed from integer to number",
+		`env: enum value "staging" was removed`,
+		`env: enum value "sandbox" was added`,
+		"group: is no longer required",
+		"user.manager: field was removed",
+		"user.team: field was added",
+	}, msgs)
+
+	var breaking []ChangeKind
+	for _, c := range BreakingChanges(got) {
+		breaking = append(breaking, c.Kind)
+	}
+	assert.Equal(t, []ChangeKind{TypeChanged, TypeChanged, EnumValueRemoved, RequiredRemoved, FieldRemoved}, breaking)
+}
+
+func TestCheckCompatibility_Unchanged(t *testing.T) {
+	s := &Schema{
+		Properties: map[string]*Schema{
+			"labels": {Type: Object, AdditionalProperties: &Schema{Type: String}},
+		},
+	}
+	assert.Empty(t, CheckCompatibility(s, s))
+}
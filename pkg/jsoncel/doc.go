@@ -4,6 +4,10 @@
 // This allows input types to be type-checked
 // against a JSON schema definition.
 //
+// The Provider is also a CEL type adapter: inputs are
+// passed to CEL programs as an ObjectValue, which
+// converts nested values lazily using the schema.
+//
 // Acknowledgements: thanks to github.com/invopop/jsonschema
 // and github.com/alecthomas/jsonschema for their work on
 // JSON schema modelling. Struct definitions have been
@@ -0,0 +1,262 @@
+package jsoncel
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Generator generates random values which are valid for a schema,
+// for testing workflows without hand-writing representative inputs.
+//
+// Values have the same types as JSON decoded with encoding/json: objects
+// are map[string]any, arrays are []any, and numbers are float64.
+// Enums, consts, formats, required properties and the bounds on numbers,
+// strings and arrays are respected. Patterns, references and
+// 'allOf', 'not' and conditional sub-schemas aren't.
+type Generator struct {
+	// Optional is the probability that a property which isn't
+	// required is included in a generated object, between 0 and 1.
+	Optional float64
+
+	rand *rand.Rand
+}
+
+// NewGenerator returns a generator seeded with seed, so that
+// the same seed always generates the same values.
+// Half of the optional properties are included in objects.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{Optional: 0.5, rand: rand.New(rand.NewSource(seed))}
+}
+
+// GenInputs generates n random inputs which are valid for the schema,
+// using a random seed. Use NewGenerator to generate inputs reproducibly.
+func GenInputs(s *Schema, n int) []map[string]any {
+	g := NewGenerator(time.Now().UnixNano())
+	inputs := make([]map[string]any, n)
+	for i := range inputs {
+		inputs[i] = g.Input(s)
+	}
+	return inputs
+}
+
+// Input generates an object which is valid for the schema.
+func (g *Generator) Input(s *Schema) map[string]any {
+	return g.object(s)
+}
+
+// Value generates a value which is valid for the schema.
+func (g *Generator) Value(s *Schema) any {
+	if s == nil {
+		return nil
+	}
+	if s.Const != nil {
+		return s.Const
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[g.rand.Intn(len(s.Enum))]
+	}
+	if len(s.OneOf) > 0 {
+		return g.Value(s.OneOf[g.rand.Intn(len(s.OneOf))])
+	}
+	if len(s.AnyOf) > 0 {
+		return g.Value(s.AnyOf[g.rand.Intn(len(s.AnyOf))])
+	}
+
+	switch schemaType(s) {
+	case Null:
+		return nil
+	case Boolean:
+		return g.rand.Intn(2) == 1
+	case Object:
+		return g.object(s)
+	case Array:
+		return g.array(s)
+	case Number:
+		return g.number(s, false)
+	case Integer:
+		return g.number(s, true)
+	}
+	return g.string(s)
+}
+
+// schemaType returns the type of the schema, inferring it
+// from the keywords which are set if the type isn't declared.
+func schemaType(s *Schema) FieldType {
+	switch {
+	case s.Type != "":
+		return s.Type
+	case len(s.Types) > 0:
+		return s.Types[0]
+	case len(s.Properties) > 0:
+		return Object
+	case s.Items != nil || len(s.PrefixItems) > 0:
+		return Array
+	}
+	return String
+}
+
+func (g *Generator) object(s *Schema) map[string]any {
+	out := map[string]any{}
+	if s == nil {
+		return out
+	}
+
+	required := map[string]bool{}
+	for _, k := range s.Required {
+		required[k] = true
+	}
+
+	// properties are visited in order, so that the
+	// same seed always generates the same values.
+	keys := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !required[k] && g.rand.Float64() >= g.Optional {
+			continue
+		}
+		out[k] = g.Value(s.Properties[k])
+	}
+	return out
+}
+
+func (g *Generator) array(s *Schema) []any {
+	max := s.MaxItems
+	if max == 0 {
+		max = s.MinItems + 3
+	}
+	n := s.MinItems + g.rand.Intn(max-s.MinItems+1)
+	if n < len(s.PrefixItems) {
+		n = len(s.PrefixItems)
+	}
+
+	out := make([]any, 0, n)
+	seen := map[string]bool{}
+	for i := 0; i < n; i++ {
+		item := s.Items
+		if i < len(s.PrefixItems) {
+			item = s.PrefixItems[i]
+		}
+
+		v := g.Value(item)
+		if s.UniqueItems {
+			// retry a few times to find a value which hasn't been
+			// generated, as a schema may not have enough unique values.
+			key := fmt.Sprintf("%#v", v)
+			for attempt := 0; seen[key] && attempt < 10; attempt++ {
+				v = g.Value(item)
+				key = fmt.Sprintf("%#v", v)
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// number generates a number between the bounds of the schema.
+// Numbers without an upper or lower bound are generated
+// within 100 of the other bound, or between 0 and 100.
+func (g *Generator) number(s *Schema, integer bool) float64 {
+	var lo, hi float64
+	lower, upper := s.Minimum != nil || s.ExclusiveMinimum != nil, s.Maximum != nil || s.ExclusiveMaximum != nil
+	if s.Minimum != nil {
+		lo = *s.Minimum
+	}
+	if s.ExclusiveMinimum != nil {
+		lo = math.Nextafter(*s.ExclusiveMinimum, math.Inf(1))
+		if integer {
+			lo = math.Floor(*s.ExclusiveMinimum) + 1
+		}
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+	}
+	if s.ExclusiveMaximum != nil {
+		hi = math.Nextafter(*s.ExclusiveMaximum, math.Inf(-1))
+		if integer {
+			hi = math.Ceil(*s.ExclusiveMaximum) - 1
+		}
+	}
+	switch {
+	case !lower && !upper:
+		lo, hi = 0, 100
+	case !upper:
+		hi = lo + 100
+	case !lower:
+		lo = math.Min(0, hi-100)
+	}
+	if hi < lo {
+		hi = lo
+	}
+
+	if !integer {
+		return lo + g.rand.Float64()*(hi-lo)
+	}
+
+	v := math.Ceil(lo) + float64(g.rand.Int63n(int64(math.Floor(hi)-math.Ceil(lo))+1))
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		m := *s.MultipleOf
+		// round up to a multiple, unless that's above the maximum.
+		if r := math.Ceil(v/m) * m; r <= hi {
+			v = r
+		} else {
+			v = math.Floor(v/m) * m
+		}
+	}
+	return v
+}
+
+func (g *Generator) string(s *Schema) string {
+	switch s.Format {
+	case "date-time":
+		return g.time().Format(time.RFC3339)
+	case "date":
+		return g.time().Format("2006-01-02")
+	case "time":
+		return g.time().Format("15:04:05Z07:00")
+	case "email":
+		return g.word(4, 8) + "@example.com"
+	case "hostname":
+		return g.word(4, 8) + ".example.com"
+	case "uri", "url":
+		return "https://example.com/" + g.word(4, 8)
+	case "uuid":
+		b := make([]byte, 16)
+		g.rand.Read(b)
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "ipv4":
+		return fmt.Sprintf("%d.%d.%d.%d", g.rand.Intn(256), g.rand.Intn(256), g.rand.Intn(256), g.rand.Intn(256))
+	}
+
+	max := s.MaxLength
+	if max == 0 {
+		max = s.MinLength + 10
+	}
+	return g.word(s.MinLength, max)
+}
+
+// word generates a lowercase string with a length between min and max.
+func (g *Generator) word(min, max int) string {
+	n := min + g.rand.Intn(max-min+1)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + g.rand.Intn(26))
+	}
+	return string(b)
+}
+
+// time generates a time in the year before 2025-01-01.
+func (g *Generator) time() time.Time {
+	end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return end.Add(-time.Duration(g.rand.Int63n(int64(365 * 24 * time.Hour)))).Truncate(time.Second)
+}
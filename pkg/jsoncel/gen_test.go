@@ -0,0 +1,120 @@
+package jsoncel
+
+import (
+	"encoding/json"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["name", "role", "duration", "requested_at", "tags"],
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "maxLength": 5},
+			"email": {"type": "string", "format": "email"},
+			"role": {"enum": ["admin", "viewer"]},
+			"kind": {"const": "access"},
+			"duration": {"type": "integer", "minimum": 1, "maximum": 8},
+			"requested_at": {"type": "string", "format": "date-time"},
+			"tags": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 3},
+			"group": {
+				"type": "object",
+				"required": ["id"],
+				"properties": {"id": {"type": "string"}}
+			}
+		}
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator(1)
+	for i := 0; i < 50; i++ {
+		input := g.Input(&s)
+
+		for _, k := range s.Required {
+			assert.Contains(t, input, k)
+		}
+
+		name := input["name"].(string)
+		assert.GreaterOrEqual(t, len(name), 2)
+		assert.LessOrEqual(t, len(name), 5)
+
+		assert.Contains(t, []any{"admin", "viewer"}, input["role"])
+
+		d := input["duration"].(float64)
+		assert.GreaterOrEqual(t, d, 1.0)
+		assert.LessOrEqual(t, d, 8.0)
+		assert.Equal(t, float64(int(d)), d)
+
+		_, err := time.Parse(time.RFC3339, input["requested_at"].(string))
+		assert.NoError(t, err)
+
+		tags := input["tags"].([]any)
+		assert.GreaterOrEqual(t, len(tags), 1)
+		assert.LessOrEqual(t, len(tags), 3)
+
+		if email, ok := input["email"]; ok {
+			_, err := mail.ParseAddress(email.(string))
+			assert.NoError(t, err)
+		}
+		if kind, ok := input["kind"]; ok {
+			assert.Equal(t, "access", kind)
+		}
+		if group, ok := input["group"]; ok {
+			assert.Contains(t, group, "id")
+		}
+	}
+}
+
+func TestGenerator_Seed(t *testing.T) {
+	s := &Schema{
+		Type: Object,
+		Properties: map[string]*Schema{
+			"a": {Type: String},
+			"b": {Type: Number},
+			"c": {Type: Boolean},
+		},
+	}
+
+	// the same seed generates the same inputs.
+	assert.Equal(t, NewGenerator(42).Input(s), NewGenerator(42).Input(s))
+}
+
+func TestGenerator_UniqueItems(t *testing.T) {
+	s := &Schema{
+		Type:        Array,
+		Items:       &Schema{Enum: []any{"a", "b"}},
+		MinItems:    2,
+		MaxItems:    5,
+		UniqueItems: true,
+	}
+
+	g := NewGenerator(1)
+	for i := 0; i < 20; i++ {
+		items := g.Value(s).([]any)
+		// only two unique values can be generated.
+		assert.ElementsMatch(t, []any{"a", "b"}, items)
+	}
+}
+
+func TestGenInputs(t *testing.T) {
+	s := &Schema{
+		Type:     Object,
+		Required: []string{"id"},
+		Properties: map[string]*Schema{
+			"id": {Type: String, Format: "uuid"},
+		},
+	}
+
+	inputs := GenInputs(s, 3)
+	assert.Len(t, inputs, 3)
+	for _, input := range inputs {
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`, input["id"])
+	}
+}
@@ -0,0 +1,123 @@
+package jsoncel
+
+import (
+	"fmt"
+)
+
+// MergeConflict is returned by Merge when a field is declared
+// with different types in the schemas being merged.
+type MergeConflict struct {
+	// Path is the dot-separated path of the field, e.g. 'group.id',
+	// using the same notation as Change.
+	Path string
+	// Base is the type of the field in the base schema.
+	Base FieldType
+	// Overlay is the type of the field in the overlay schema.
+	Overlay FieldType
+}
+
+func (e *MergeConflict) Error() string {
+	return fmt.Sprintf("%s is declared as %s in one schema and %s in the other", e.Path, typeName(e.Base), typeName(e.Overlay))
+}
+
+// Merge combines the fields of two schemas into a single schema.
+//
+// The properties of objects and the items of arrays are merged
+// recursively, and the required properties of both schemas are required.
+// Other keywords, such as enums and descriptions, are taken from the base
+// schema if it sets them, otherwise from the overlay.
+//
+// A *MergeConflict is returned if a field is declared with different types.
+// Integer and number fields aren't in conflict, and are merged as integers.
+// Neither of the schemas are modified.
+func Merge(base, overlay *Schema) (*Schema, error) {
+	return mergeSchemas("", base, overlay)
+}
+
+func mergeSchemas(path string, base, overlay *Schema) (*Schema, error) {
+	if base == nil {
+		return overlay, nil
+	}
+	if overlay == nil {
+		return base, nil
+	}
+
+	merged := *base
+
+	bt, ot := declaredType(base), declaredType(overlay)
+	switch {
+	case bt == "" || bt == ot:
+		merged.Type = overlay.Type
+		if base.Type != "" {
+			merged.Type = base.Type
+		}
+		if merged.Types == nil {
+			merged.Types = overlay.Types
+		}
+	case ot == "":
+	case bt == Integer && ot == Number, bt == Number && ot == Integer:
+		merged.Type = Integer
+	default:
+		return nil, &MergeConflict{Path: path, Base: bt, Overlay: ot}
+	}
+
+	if merged.Enum == nil {
+		merged.Enum = overlay.Enum
+	}
+	if merged.Const == nil {
+		merged.Const = overlay.Const
+	}
+	if merged.Format == "" {
+		merged.Format = overlay.Format
+	}
+	if merged.Description == "" {
+		merged.Description = overlay.Description
+	}
+
+	if len(base.Properties) > 0 || len(overlay.Properties) > 0 {
+		merged.Properties = map[string]*Schema{}
+		for k, p := range base.Properties {
+			merged.Properties[k] = p
+		}
+		for k, p := range overlay.Properties {
+			m, err := mergeSchemas(join(path, k), merged.Properties[k], p)
+			if err != nil {
+				return nil, err
+			}
+			merged.Properties[k] = m
+		}
+	}
+
+	merged.Required = append([]string(nil), base.Required...)
+	for _, k := range overlay.Required {
+		if !contains(merged.Required, k) {
+			merged.Required = append(merged.Required, k)
+		}
+	}
+	if len(merged.Required) == 0 {
+		merged.Required = nil
+	}
+
+	items, err := mergeSchemas(path+"[]", base.Items, overlay.Items)
+	if err != nil {
+		return nil, err
+	}
+	merged.Items = items
+
+	values, err := mergeSchemas(path+"{}", base.AdditionalProperties, overlay.AdditionalProperties)
+	if err != nil {
+		return nil, err
+	}
+	merged.AdditionalProperties = values
+
+	return &merged, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package jsoncel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	parse := func(s string) *Schema {
+		var out Schema
+		if err := json.Unmarshal([]byte(s), &out); err != nil {
+			t.Fatal(err)
+		}
+		return &out
+	}
+
+	tests := []struct {
+		name    string
+		base    string
+		overlay string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "fields are added",
+			base:    `{"type": "object", "required": ["group"], "properties": {"group": {"type": "string"}}}`,
+			overlay: `{"type": "object", "required": ["approvals"], "properties": {"approvals": {"type": "array", "items": {"type": "string"}}}}`,
+			want:    `{"type": "object", "required": ["group", "approvals"], "properties": {"group": {"type": "string"}, "approvals": {"type": "array", "items": {"type": "string"}}}}`,
+		},
+		{
+			name:    "nested objects are merged",
+			base:    `{"type": "object", "properties": {"approvals": {"type": "array", "items": {"properties": {"user": {"type": "string"}}}}}}`,
+			overlay: `{"type": "object", "properties": {"approvals": {"type": "array", "items": {"type": "object", "properties": {"groups": {"type": "array", "items": {"type": "string"}}}}}}}`,
+			want:    `{"type": "object", "properties": {"approvals": {"type": "array", "items": {"type": "object", "properties": {"user": {"type": "string"}, "groups": {"type": "array", "items": {"type": "string"}}}}}}}`,
+		},
+		{
+			name:    "base keywords take precedence",
+			base:    `{"type": "object", "properties": {"env": {"type": "string", "enum": ["dev", "prod"]}}}`,
+			overlay: `{"type": "object", "properties": {"env": {"type": "string", "enum": ["dev"], "description": "the environment"}}}`,
+			want:    `{"type": "object", "properties": {"env": {"type": "string", "enum": ["dev", "prod"], "description": "the environment"}}}`,
+		},
+		{
+			name:    "integers and numbers",
+			base:    `{"type": "object", "properties": {"duration": {"type": "number"}}}`,
+			overlay: `{"type": "object", "properties": {"duration": {"type": "integer"}}}`,
+			want:    `{"type": "object", "properties": {"duration": {"type": "integer"}}}`,
+		},
+		{
+			name:    "conflict",
+			base:    `{"type": "object", "properties": {"approvals": {"type": "array", "items": {"type": "integer"}}}}`,
+			overlay: `{"type": "object", "properties": {"approvals": {"type": "array", "items": {"type": "string"}}}}`,
+			wantErr: "approvals[] is declared as integer in one schema and string in the other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, overlay := parse(tt.base), parse(tt.overlay)
+			got, err := Merge(base, overlay)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, parse(tt.want), got)
+
+			// the schemas aren't modified.
+			assert.Equal(t, parse(tt.base), base)
+			assert.Equal(t, parse(tt.overlay), overlay)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package jsoncel
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// ObjectValue is a CEL value for a JSON object described by a schema.
+//
+// ObjectValue implements traits.Mapper, so expressions may use field selection
+// (input.group.id), map indexing (input["group"]["id"]), presence tests
+// (has(input.group)) and comprehensions over the object's keys.
+//
+// Child values are converted lazily as they are accessed, using the schema
+// to guide the conversion (e.g. JSON numbers declared as integers are
+// converted to CEL ints).
+type ObjectValue struct {
+	provider *Provider
+	// key is the key of the object's schema node in the provider's typeMap.
+	key   string
+	value map[string]any
+}
+
+var _ traits.Mapper = &ObjectValue{}
+
+// ConvertToNative implements ref.Val.ConvertToNative.
+func (o *ObjectValue) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	if reflect.TypeOf(o.value).AssignableTo(typeDesc) {
+		return o.value, nil
+	}
+	return types.NewDynamicMap(o.provider.adapter, o.value).ConvertToNative(typeDesc)
+}
+
+// ConvertToType implements ref.Val.ConvertToType.
+func (o *ObjectValue) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case types.TypeType:
+		return o.Type().(ref.Val)
+	case types.MapType:
+		return types.NewDynamicMap(o.provider, o.value)
+	}
+	if typeVal.TypeName() == o.Type().TypeName() {
+		return o
+	}
+	return types.NewErr("type conversion error from '%s' to '%s'", o.Type(), typeVal)
+}
+
+// Equal implements ref.Val.Equal.
+func (o *ObjectValue) Equal(other ref.Val) ref.Val {
+	switch v := other.(type) {
+	case *ObjectValue:
+		return types.Bool(reflect.DeepEqual(o.value, v.value))
+	case traits.Mapper:
+		return types.NewDynamicMap(o.provider.adapter, o.value).Equal(v)
+	}
+	return types.False
+}
+
+// Type implements ref.Val.Type.
+func (o *ObjectValue) Type() ref.Type {
+	return types.NewTypeValue(o.provider.objectTypeName(o.key),
+		traits.ContainerType,
+		traits.FieldTesterType,
+		traits.IndexerType,
+		traits.IterableType,
+		traits.SizerType)
+}
+
+// Value implements ref.Val.Value.
+func (o *ObjectValue) Value() any {
+	return o.value
+}
+
+// Contains returns true if the object contains the provided key.
+func (o *ObjectValue) Contains(key ref.Val) ref.Val {
+	_, found := o.Find(key)
+	return types.Bool(found)
+}
+
+// Get returns the value of a field, or an error if the field does not exist.
+func (o *ObjectValue) Get(key ref.Val) ref.Val {
+	v, found := o.Find(key)
+	if !found {
+		return types.NewErr("no such key: %v", key)
+	}
+	return v
+}
+
+// IsSet returns true if the field is present in the object.
+func (o *ObjectValue) IsSet(field ref.Val) ref.Val {
+	return o.Contains(field)
+}
+
+// Find returns the value of a field, and whether the field was found.
+func (o *ObjectValue) Find(key ref.Val) (ref.Val, bool) {
+	k, ok := key.(types.String)
+	if !ok {
+		return types.ValOrErr(key, "unsupported key type: %s", key.Type()), false
+	}
+	v, ok := o.value[string(k)]
+	if !ok {
+		return nil, false
+	}
+	return o.provider.valueAt(o.key+"."+string(k), v), true
+}
+
+// Iterator returns an iterator over the keys of the object,
+// in sorted order.
+func (o *ObjectValue) Iterator() traits.Iterator {
+	keys := make([]string, 0, len(o.value))
+	for k := range o.value {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return types.NewStringList(o.provider.adapter, keys).Iterator()
+}
+
+// Size returns the number of fields in the object.
+func (o *ObjectValue) Size() ref.Val {
+	return types.Int(len(o.value))
+}
+
+func (o *ObjectValue) String() string {
+	return fmt.Sprintf("%s%v", o.Type().TypeName(), o.value)
+}
@@ -0,0 +1,107 @@
+package jsoncel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectValue(t *testing.T) {
+	p := NewProvider("input", &Schema{
+		Properties: map[string]*Schema{
+			"name": {
+				Type: String,
+			},
+			"count": {
+				Type: Integer,
+			},
+			"group": {
+				Type: Object,
+				Properties: map[string]*Schema{
+					"id": {
+						Type: String,
+					},
+				},
+			},
+			"labels": {
+				Type: Object,
+			},
+			"approvals": {
+				Type: Array,
+				Items: &Schema{
+					Type: Object,
+					Properties: map[string]*Schema{
+						"user": {
+							Type: String,
+						},
+					},
+				},
+			},
+		},
+	})
+	env, err := cel.NewEnv(
+		cel.CustomTypeProvider(p),
+		cel.CustomTypeAdapter(p),
+		cel.Variable("input", cel.ObjectType("input")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := map[string]any{
+		"name":  "test",
+		"count": float64(2),
+		"group": map[string]any{
+			"id": "admins",
+		},
+		"labels": map[string]any{
+			"team": "platform",
+		},
+		"approvals": []any{
+			map[string]any{"user": "alice"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "field", expr: `input.name == "test"`, want: true},
+		{name: "nested field", expr: `input.group.id == "admins"`, want: true},
+		{name: "index", expr: `dyn(input)["group"]["id"] == "admins"`, want: true},
+		{name: "free-form object", expr: `input.labels["team"] == "platform"`, want: true},
+		{name: "has", expr: `has(input.group)`, want: true},
+		{name: "has nested", expr: `has(input.group.id)`, want: true},
+		{name: "integer conversion", expr: `input.count == 2`, want: true},
+		{name: "comprehension over object keys", expr: `input.labels.exists(k, k == "team")`, want: true},
+		{name: "comprehension over array of objects", expr: `input.approvals.exists(a, a.user == "alice")`, want: true},
+		{name: "not null", expr: `input.group != null`, want: true},
+		{name: "in", expr: `"group" in dyn(input)`, want: true},
+		{name: "missing field", expr: `input.missing == "x"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, issues := env.Compile(tt.expr)
+			if issues != nil && issues.Err() != nil {
+				if !tt.wantErr {
+					t.Fatal(issues.Err())
+				}
+				return
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, err := prg.Eval(map[string]any{"input": p.NewObjectValue(input)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got.Value())
+			}
+		})
+	}
+}
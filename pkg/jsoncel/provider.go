@@ -1,6 +1,8 @@
 package jsoncel
 
 import (
+	"strings"
+
 	"github.com/google/cel-go/checker/decls"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
@@ -9,10 +11,16 @@ import (
 
 // Provider extends the CEL ref.TypeProvider interface and
 // provides a JSON Schema-based type-system.
+//
+// Provider also implements ref.TypeAdapter, so that raw
+// JSON input values can be passed to CEL programs directly.
 type Provider struct {
 	// fallback proto-based type provider
 	protos ref.TypeProvider
 
+	// fallback adapter for values which aren't described by the schema.
+	adapter ref.TypeAdapter
+
 	schema *Schema
 
 	typeName string
@@ -36,7 +44,19 @@ type Provider struct {
 	// typeMap will be:
 	// 	group -> {"group": {"type": "object", "properties": {"id": {"type": "string"}}}}
 	// 	group.id -> {"type": "string"}
+	//
+	// array items are mapped with a '[]' suffix, e.g. 'approvals[]', and the
+	// values of free-form objects with a '{}' suffix, e.g. 'labels{}'.
 	typeMap map[string]*Schema
+
+	// objectTypes is a map of CEL object type names to
+	// the corresponding key in typeMap.
+	//
+	// Object type names must not collide with field paths:
+	// the CEL type-checker resolves qualified identifiers like 'input.group'
+	// against the type provider before treating them as field selections.
+	// Nested object types are named like 'input#/group' to avoid this.
+	objectTypes map[string]string
 }
 
 func NewProvider(typeName string, schema *Schema) *Provider {
@@ -44,18 +64,38 @@ func NewProvider(typeName string, schema *Schema) *Provider {
 		schema = &Schema{}
 	}
 
+	reg := types.NewEmptyRegistry()
+
 	p := &Provider{
-		protos:   types.NewEmptyRegistry(),
-		schema:   schema,
-		typeName: typeName,
-		typeMap:  map[string]*Schema{},
+		protos:      reg,
+		adapter:     reg,
+		schema:      schema,
+		typeName:    typeName,
+		typeMap:     map[string]*Schema{},
+		objectTypes: map[string]string{},
+	}
+
+	p.Register(typeName, schema)
+
+	return p
+}
+
+// Register an additional root type with the provider, so that more than
+// one variable can be typed using JSON schemas in the same CEL environment.
+// The type name must not be the same as the name of another root type.
+//
+// Values for the type are created with NewRootValue.
+func (p *Provider) Register(typeName string, schema *Schema) {
+	if schema == nil {
+		schema = &Schema{}
 	}
 
 	// build the typeMap so that we can look up CEL references
 	// into the corresponding JSON schema nodes.
 	p.mapSchema(typeName, schema)
 
-	return p
+	// the root of the schema is always an object.
+	p.objectTypes[typeName] = typeName
 }
 
 // mapSchema builds up the typeMap for the JSON schema.
@@ -71,12 +111,184 @@ func NewProvider(typeName string, schema *Schema) *Provider {
 func (p *Provider) mapSchema(key string, s *Schema) {
 	p.typeMap[key] = s
 
+	if isStruct(s) {
+		p.objectTypes[p.objectTypeName(key)] = key
+	}
+
 	for childKey, child := range s.Properties {
 		p.mapSchema(key+"."+childKey, child)
 	}
+
+	if item := itemSchema(s); item != nil {
+		p.mapSchema(key+"[]", item)
+	}
+
+	if value := mapValueSchema(s); value != nil {
+		p.mapSchema(key+"{}", value)
+	}
+}
+
+// itemSchema returns the schema of the elements of an array. Tuples declared
+// with 'prefixItems' have an item schema only if every element has the same type.
+func itemSchema(s *Schema) *Schema {
+	if len(s.PrefixItems) == 0 {
+		return s.Items
+	}
+	first := s.PrefixItems[0]
+	for _, item := range append(s.PrefixItems[1:], s.Items) {
+		if item != nil && declaredType(item) != declaredType(first) {
+			return nil
+		}
+	}
+	return first
+}
+
+// mapValueSchema returns the schema of the values of a free-form object, from
+// its 'additionalProperties', or its 'patternProperties' if they all have the
+// same type. It returns nil if the values aren't constrained to a single type.
+func mapValueSchema(s *Schema) *Schema {
+	if isStruct(s) {
+		return nil
+	}
+	var value *Schema
+	if s.AdditionalProperties != nil && declaredType(s.AdditionalProperties) != "" {
+		value = s.AdditionalProperties
+	}
+	for _, pattern := range s.PatternProperties {
+		if pattern == nil || declaredType(pattern) == "" {
+			return nil
+		}
+		if value != nil && declaredType(pattern) != declaredType(value) {
+			return nil
+		}
+		value = pattern
+	}
+	return value
+}
+
+// declaredType returns the type of a schema, which is inferred from
+// its 'const' or 'enum' values if it doesn't declare a type.
+func declaredType(s *Schema) FieldType {
+	if s.Type != "" {
+		return s.Type
+	}
+	values := s.Enum
+	if s.Const != nil {
+		values = []any{s.Const}
+	}
+
+	var t FieldType
+	for _, v := range values {
+		var vt FieldType
+		switch n := v.(type) {
+		case string:
+			vt = String
+		case bool:
+			vt = Boolean
+		case float64:
+			vt = Number
+			if n == float64(int64(n)) {
+				vt = Integer
+			}
+		case int, int64:
+			vt = Integer
+		default:
+			return ""
+		}
+		switch {
+		case t == "":
+			t = vt
+		case t == Integer && vt == Number, t == Number && vt == Integer:
+			t = Number
+		case t != vt:
+			return ""
+		}
+	}
+	return t
+}
+
+// objectTypeName returns the CEL type name for an object
+// at a particular key in the typeMap.
+// 'input' -> 'input'
+// 'input.group' -> 'input#/group'
+func (p *Provider) objectTypeName(key string) string {
+	root, rest, ok := strings.Cut(key, ".")
+	if !ok {
+		return key
+	}
+	return root + "#/" + strings.ReplaceAll(rest, ".", "/")
+}
+
+// celType returns the CEL type for the schema node registered at key.
+//
+// Nullable scalars are typed as CEL wrapper types, which can be compared with
+// null, and nullable objects keep their object type, as objects can be null.
+// Nullable arrays and maps, and unions of more than one other type, are dynamic.
+func (p *Provider) celType(key string, s *Schema) (*exprpb.Type, bool) {
+	if s.Type == "" && len(s.Types) > 0 {
+		return decls.Dyn, true
+	}
+	t, ok := p.nonNullCelType(key, s)
+	if !ok || !s.Nullable() {
+		return t, ok
+	}
+	switch t.GetPrimitive() {
+	case exprpb.Type_BOOL, exprpb.Type_INT64, exprpb.Type_DOUBLE, exprpb.Type_STRING:
+		return decls.NewWrapperType(t), true
+	}
+	if t.GetMessageType() != "" {
+		return t, true
+	}
+	return decls.Dyn, true
+}
+
+// nonNullCelType returns the CEL type for the values of the
+// schema node registered at key, other than null.
+func (p *Provider) nonNullCelType(key string, s *Schema) (*exprpb.Type, bool) {
+	switch declaredType(s) {
+	case Null:
+		return decls.Null, true
+	case Boolean:
+		return decls.Bool, true
+	case Object:
+		if !isStruct(s) {
+			// objects without declared properties are free-form,
+			// so they are typed as maps.
+			value := decls.Dyn
+			if v := mapValueSchema(s); v != nil {
+				if t, ok := p.celType(key+"{}", v); ok {
+					value = t
+				}
+			}
+			return decls.NewMapType(decls.String, value), true
+		}
+		return decls.NewObjectType(p.objectTypeName(key)), true
+	case Array:
+		item := itemSchema(s)
+		if item == nil && len(s.PrefixItems) > 0 {
+			// the elements of tuples have different types.
+			return decls.NewListType(decls.Dyn), true
+		}
+		if item == nil {
+			return decls.NewListType(decls.String), true
+		}
+		t, ok := p.celType(key+"[]", item)
+		if !ok {
+			t = decls.Dyn
+		}
+		return decls.NewListType(t), true
+	case Number:
+		return decls.Double, true
+	case String:
+		return decls.String, true
+	case Integer:
+		return decls.Int, true
+	}
+	return nil, false
 }
 
 var _ ref.TypeProvider = &Provider{}
+var _ ref.TypeAdapter = &Provider{}
 
 // EnumValue returns the numeric value of the given enum value name.
 func (p *Provider) EnumValue(enumName string) ref.Val {
@@ -92,25 +304,13 @@ func (p *Provider) FindIdent(identName string) (ref.Val, bool) {
 // FindType looks up the Type given a qualified typeName. Returns false
 // if not found.
 //
+// Only object types are resolved here. Fields are resolved
+// through FindFieldType.
+//
 // Used during type-checking only.
 func (p *Provider) FindType(typeName string) (*exprpb.Type, bool) {
-	if f, ok := p.typeMap[typeName]; ok {
-		switch f.Type {
-		case Null:
-			return decls.Null, true
-		case Boolean:
-			return decls.Bool, true
-		case Object:
-			return decls.NewObjectType(typeName), true
-		case Array:
-			return decls.NewListType(decls.String), true
-		case Number:
-			return decls.Double, true
-		case String:
-			return decls.String, true
-		case Integer:
-			return decls.Int, true
-		}
+	if _, ok := p.objectTypes[typeName]; ok {
+		return decls.NewTypeType(decls.NewObjectType(typeName)), true
 	}
 
 	return p.protos.FindType(typeName)
@@ -121,22 +321,12 @@ func (p *Provider) FindType(typeName string) (*exprpb.Type, bool) {
 //
 // Used during type-checking only.
 func (p *Provider) FindFieldType(messageType string, fieldName string) (*ref.FieldType, bool) {
-	if f, ok := p.typeMap[fieldName]; ok {
-		switch f.Type {
-		case Null:
-			return &ref.FieldType{Type: decls.Null}, true
-		case Boolean:
-			return &ref.FieldType{Type: decls.Bool}, true
-		case Object:
-			return &ref.FieldType{Type: decls.NewObjectType(messageType + "." + fieldName)}, true
-		case Array:
-			return &ref.FieldType{Type: decls.NewListType(decls.String)}, true
-		case Number:
-			return &ref.FieldType{Type: decls.Double}, true
-		case String:
-			return &ref.FieldType{Type: decls.String}, true
-		case Integer:
-			return &ref.FieldType{Type: decls.Int}, true
+	if key, ok := p.objectTypes[messageType]; ok {
+		fieldKey := key + "." + fieldName
+		if f, ok := p.typeMap[fieldKey]; ok {
+			if t, ok := p.celType(fieldKey, f); ok {
+				return &ref.FieldType{Type: t}, true
+			}
 		}
 	}
 
@@ -153,3 +343,83 @@ func (p *Provider) FindFieldType(messageType string, fieldName string) (*ref.Fie
 func (p *Provider) NewValue(typeName string, fields map[string]ref.Val) ref.Val {
 	return p.protos.NewValue(typeName, fields)
 }
+
+// Schema returns the JSON schema used by the provider.
+func (p *Provider) Schema() *Schema {
+	return p.schema
+}
+
+// FieldSchema returns the schema for a field, using a dot-separated
+// path relative to the root of the schema (e.g. 'group.id').
+// It returns nil if the field is not declared in the schema.
+func (p *Provider) FieldSchema(path string) *Schema {
+	return p.typeMap[p.typeName+"."+path]
+}
+
+// NativeToValue converts a Go value into a CEL value.
+// Values which are not described by the schema are converted
+// using the default CEL adapter.
+func (p *Provider) NativeToValue(value any) ref.Val {
+	return p.adapter.NativeToValue(value)
+}
+
+// NewObjectValue wraps the root input value so that it can be
+// provided to a CEL program as the variable for the provider's type name.
+//
+//	prg.Eval(map[string]any{"input": p.NewObjectValue(input)})
+func (p *Provider) NewObjectValue(value map[string]any) *ObjectValue {
+	return p.NewRootValue(p.typeName, value)
+}
+
+// NewRootValue wraps a value so that it can be provided to a CEL program
+// as the variable for a root type added with Register.
+func (p *Provider) NewRootValue(typeName string, value map[string]any) *ObjectValue {
+	return &ObjectValue{provider: p, key: typeName, value: value}
+}
+
+// valueAt converts a Go value into a CEL value, using the
+// schema node registered at key to guide the conversion.
+func (p *Provider) valueAt(key string, value any) ref.Val {
+	s, ok := p.typeMap[key]
+	if !ok {
+		return p.adapter.NativeToValue(value)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if isStruct(s) {
+			return &ObjectValue{provider: p, key: key, value: v}
+		}
+		if _, ok := p.typeMap[key+"{}"]; ok {
+			return types.NewDynamicMap(keyAdapter{provider: p, key: key + "{}"}, v)
+		}
+	case []any:
+		if _, ok := p.typeMap[key+"[]"]; ok {
+			return types.NewDynamicList(keyAdapter{provider: p, key: key + "[]"}, v)
+		}
+	case float64:
+		// JSON numbers are always decoded as float64, but
+		// the schema may declare the field as an integer.
+		if declaredType(s) == Integer && v == float64(int64(v)) {
+			return types.Int(int64(v))
+		}
+	}
+
+	return p.adapter.NativeToValue(value)
+}
+
+// isStruct returns true if the schema is an object with declared properties.
+func isStruct(s *Schema) bool {
+	return s.Type == Object && len(s.Properties) > 0
+}
+
+// keyAdapter adapts values using the schema node registered at a particular key.
+// It is used to adapt the elements of arrays.
+type keyAdapter struct {
+	provider *Provider
+	key      string
+}
+
+func (a keyAdapter) NativeToValue(value any) ref.Val {
+	return a.provider.valueAt(a.key, value)
+}
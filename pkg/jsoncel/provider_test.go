@@ -1,6 +1,7 @@
 package jsoncel
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/google/cel-go/cel"
@@ -35,3 +36,115 @@ func TestProvider(t *testing.T) {
 		t.Fatal(issues.Err())
 	}
 }
+
+func TestProvider_DraftKeywords(t *testing.T) {
+	p := NewProvider("input", &Schema{
+		Properties: map[string]*Schema{
+			"kind": {Const: "access"},
+			"size": {Enum: []any{1.0, 2.0}},
+			"labels": {
+				Type:              Object,
+				PatternProperties: map[string]*Schema{"^[a-z]+$": {Type: String}},
+			},
+			"range": {
+				Type:        Array,
+				PrefixItems: []*Schema{{Type: Integer}, {Type: Integer}},
+			},
+		},
+	})
+	env, err := cel.NewEnv(
+		cel.CustomTypeProvider(p),
+		cel.Variable("input", cel.ObjectType("input")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expr := range []string{
+		`input.kind == "access"`,
+		`input.size > 1`,
+		`input.labels["team"] == "platform"`,
+		`input.range[0] < input.range[1]`,
+	} {
+		_, issues := env.Compile(expr)
+		if issues != nil && issues.Err() != nil {
+			t.Errorf("%s: %s", expr, issues.Err())
+		}
+	}
+
+	_, issues := env.Compile(`input.labels["team"] == 1`)
+	if issues == nil || issues.Err() == nil {
+		t.Error("expected comparing a label to an int to fail to type-check")
+	}
+}
+
+func TestProvider_NullableTypes(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": ["string", "null"]},
+			"count": {"type": ["integer", "null"]},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+			"group": {"type": ["object", "null"], "properties": {"id": {"type": "string"}}},
+			"value": {"type": ["string", "integer"]}
+		}
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProvider("input", &s)
+	env, err := cel.NewEnv(
+		cel.CustomTypeProvider(p),
+		cel.CustomTypeAdapter(p),
+		cel.Variable("input", cel.ObjectType("input")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr  string
+		input map[string]any
+		want  bool
+	}{
+		{expr: `input.name == "alice"`, input: map[string]any{"name": "alice"}, want: true},
+		{expr: `input.name == "alice"`, input: map[string]any{"name": nil}, want: false},
+		{expr: `input.name == null`, input: map[string]any{"name": nil}, want: true},
+		{expr: `input.name != null && input.name.startsWith("a")`, input: map[string]any{"name": "alice"}, want: true},
+		{expr: `input.name != null && input.name.startsWith("a")`, input: map[string]any{"name": nil}, want: false},
+		{expr: `input.count > 1`, input: map[string]any{"count": 2.0}, want: true},
+		{expr: `input.count == null`, input: map[string]any{"count": nil}, want: true},
+		{expr: `input.tags == null`, input: map[string]any{"tags": nil}, want: true},
+		{expr: `"a" in input.tags`, input: map[string]any{"tags": []any{"a"}}, want: true},
+		{expr: `input.group == null`, input: map[string]any{"group": nil}, want: true},
+		{expr: `input.group.id == "g"`, input: map[string]any{"group": map[string]any{"id": "g"}}, want: true},
+		{expr: `input.value == 1`, input: map[string]any{"value": 1.0}, want: true},
+		{expr: `input.value == "one"`, input: map[string]any{"value": "one"}, want: true},
+	}
+	for _, tt := range tests {
+		ast, issues := env.Compile(tt.expr)
+		if issues != nil && issues.Err() != nil {
+			t.Errorf("%s: %s", tt.expr, issues.Err())
+			continue
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _, err := prg.Eval(map[string]any{"input": p.NewObjectValue(tt.input)})
+		if err != nil {
+			t.Errorf("%s with %v: %s", tt.expr, tt.input, err)
+			continue
+		}
+		if got.Value() != tt.want {
+			t.Errorf("%s with %v: got %v, want %v", tt.expr, tt.input, got.Value(), tt.want)
+		}
+	}
+
+	_, issues := env.Compile(`input.name == 1`)
+	if issues == nil || issues.Err() == nil {
+		t.Error("expected comparing a nullable string to an int to fail to type-check")
+	}
+}
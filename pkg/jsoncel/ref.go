@@ -0,0 +1,198 @@
+package jsoncel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// defsPrefix is the prefix of the references which Resolve supports.
+const defsPrefix = "#/$defs/"
+
+// Resolve returns the schema with each '$ref' replaced by the schema it refers
+// to, so that the schema can be used to type checks and validate inputs.
+//
+// Only references to the schemas in the '$defs' of the root schema are supported,
+// e.g. '#/$defs/group'. Other references, and definitions which refer to themselves,
+// are an error, as CEL types can't be recursive. Keywords next to a '$ref', such as
+// a description, are applied on top of the schema it refers to, and the properties
+// they declare are added to it. The schema isn't modified, and is returned as it
+// is if it doesn't have any references.
+func Resolve(s *Schema) (*Schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	r := resolver{defs: s.Defs, resolving: map[string]bool{}}
+	return r.resolve("", s)
+}
+
+type resolver struct {
+	defs map[string]*Schema
+	// resolving are the definitions being resolved,
+	// to find definitions which refer to themselves.
+	resolving map[string]bool
+}
+
+func (r *resolver) resolve(path string, s *Schema) (*Schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if s.DynamicRef != "" {
+		return nil, refError(path, "$dynamicRef %q isn't supported", s.DynamicRef)
+	}
+
+	// the subschemas are resolved first, and the
+	// schema is only copied if one of them changed.
+	out := *s
+	changed := false
+
+	for _, c := range []struct {
+		name   string
+		schema **Schema
+	}{
+		{"not", &out.Not},
+		{"if", &out.If},
+		{"then", &out.Then},
+		{"else", &out.Else},
+		{"[]", &out.Items},
+		{"contains", &out.Contains},
+		{"{}", &out.AdditionalProperties},
+		{"propertyNames", &out.PropertyNames},
+		{"contentSchema", &out.ContentSchema},
+	} {
+		childPath := join(path, c.name)
+		if c.name == "[]" || c.name == "{}" {
+			childPath = path + c.name
+		}
+		resolved, err := r.resolve(childPath, *c.schema)
+		if err != nil {
+			return nil, err
+		}
+		changed = changed || resolved != *c.schema
+		*c.schema = resolved
+	}
+
+	for _, c := range []struct {
+		name    string
+		schemas *[]*Schema
+	}{
+		{"allOf", &out.AllOf},
+		{"anyOf", &out.AnyOf},
+		{"oneOf", &out.OneOf},
+		{"prefixItems", &out.PrefixItems},
+	} {
+		if *c.schemas == nil {
+			continue
+		}
+		list := make([]*Schema, len(*c.schemas))
+		for i, child := range *c.schemas {
+			resolved, err := r.resolve(join(path, fmt.Sprintf("%s.%d", c.name, i)), child)
+			if err != nil {
+				return nil, err
+			}
+			changed = changed || resolved != child
+			list[i] = resolved
+		}
+		*c.schemas = list
+	}
+
+	for _, c := range []struct {
+		prefix  string
+		schemas *map[string]*Schema
+	}{
+		{"", &out.Properties},
+		{"patternProperties.", &out.PatternProperties},
+		{"dependentSchemas.", &out.DependentSchemas},
+	} {
+		if *c.schemas == nil {
+			continue
+		}
+		m := make(map[string]*Schema, len(*c.schemas))
+		for k, child := range *c.schemas {
+			resolved, err := r.resolve(join(path, c.prefix+k), child)
+			if err != nil {
+				return nil, err
+			}
+			changed = changed || resolved != child
+			m[k] = resolved
+		}
+		*c.schemas = m
+	}
+
+	if s.Ref == "" {
+		if !changed {
+			return s, nil
+		}
+		return &out, nil
+	}
+
+	if !strings.HasPrefix(s.Ref, defsPrefix) || strings.Contains(strings.TrimPrefix(s.Ref, defsPrefix), "/") {
+		return nil, refError(path, "$ref %q isn't supported: only references to '%s<name>' are", s.Ref, defsPrefix)
+	}
+	// the name is a JSON pointer token, in which '~1' is '/' and '~0' is '~'.
+	name := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(s.Ref, defsPrefix))
+	def, ok := r.defs[name]
+	if !ok {
+		return nil, refError(path, "$ref %q refers to %s, which isn't in $defs", s.Ref, name)
+	}
+	if r.resolving[name] {
+		return nil, refError(path, "$ref %q refers to %s, which refers to itself: recursive schemas aren't supported", s.Ref, name)
+	}
+
+	r.resolving[name] = true
+	resolved, err := r.resolve(path, def)
+	delete(r.resolving, name)
+	if err != nil {
+		return nil, err
+	}
+	return applyRef(path, &out, resolved)
+}
+
+// applyRef applies the keywords next to a '$ref' on top of the schema it refers
+// to. The properties and required properties of both schemas are combined.
+func applyRef(path string, s, def *Schema) (*Schema, error) {
+	st, dt := declaredType(s), declaredType(def)
+	if st != "" && dt != "" && st != dt && !(st == Integer && dt == Number) {
+		return nil, refError(path, "$ref %q is declared as %s, but is used as %s", s.Ref, typeName(dt), typeName(st))
+	}
+
+	out := *def
+	sv, ov := reflect.ValueOf(s).Elem(), reflect.ValueOf(&out).Elem()
+	for i := 0; i < sv.NumField(); i++ {
+		switch sv.Type().Field(i).Name {
+		case "Ref", "Defs", "Properties", "Required":
+			continue
+		}
+		if f := sv.Field(i); !f.IsZero() {
+			ov.Field(i).Set(f)
+		}
+	}
+
+	if len(s.Properties) > 0 {
+		out.Properties = map[string]*Schema{}
+		for k, p := range def.Properties {
+			out.Properties[k] = p
+		}
+		for k, p := range s.Properties {
+			out.Properties[k] = p
+		}
+	}
+	out.Required = append([]string(nil), def.Required...)
+	for _, k := range s.Required {
+		if !contains(out.Required, k) {
+			out.Required = append(out.Required, k)
+		}
+	}
+	if len(out.Required) == 0 {
+		out.Required = nil
+	}
+	return &out, nil
+}
+
+func refError(path, format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	if path == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
@@ -0,0 +1,110 @@
+package jsoncel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"$defs": {
+			"group": {
+				"type": "object",
+				"properties": {"id": {"type": "string"}, "owner": {"$ref": "#/$defs/user"}},
+				"required": ["id"]
+			},
+			"user": {"type": "string", "x-sensitive": true}
+		},
+		"properties": {
+			"group": {"$ref": "#/$defs/group", "description": "The group to access."},
+			"groups": {"type": "array", "items": {"$ref": "#/$defs/group"}},
+			"team": {"$ref": "#/$defs/group", "properties": {"name": {"type": "string"}}, "required": ["name"]}
+		}
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Resolve(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group := got.Properties["group"]
+	assert.Equal(t, Object, group.Type)
+	assert.Equal(t, "The group to access.", group.Description)
+	assert.Equal(t, []string{"id"}, group.Required)
+	assert.Equal(t, &Schema{Type: String, Sensitive: true}, group.Properties["owner"])
+	assert.Equal(t, Object, got.Properties["groups"].Items.Type)
+
+	team := got.Properties["team"]
+	assert.Len(t, team.Properties, 3)
+	assert.Equal(t, []string{"id", "name"}, team.Required)
+
+	// the schema isn't modified.
+	assert.Equal(t, "#/$defs/group", s.Properties["group"].Ref)
+
+	// the resolved schema types checks and validates inputs.
+	assert.Equal(t, []string{"group.owner", "team.owner"}, SensitiveFields(got))
+	assert.EqualError(t, Validate(&s, map[string]any{"group": map[string]any{"owner": "bob"}}), "group.id: is required")
+
+	// schemas without references are returned as they are.
+	plain := &Schema{Type: Object, Properties: map[string]*Schema{"id": {Type: String}}}
+	got, err = Resolve(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Same(t, plain, got)
+}
+
+func TestResolve_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{
+			name: "remote",
+			give: `{"properties": {"group": {"$ref": "https://example.com/group.json"}}}`,
+			want: `group: $ref "https://example.com/group.json" isn't supported: only references to '#/$defs/<name>' are`,
+		},
+		{
+			name: "missing",
+			give: `{"properties": {"group": {"$ref": "#/$defs/group"}}}`,
+			want: `group: $ref "#/$defs/group" refers to group, which isn't in $defs`,
+		},
+		{
+			name: "recursive",
+			give: `{"$defs": {"node": {"type": "object", "properties": {"child": {"$ref": "#/$defs/node"}}}}, "properties": {"tree": {"$ref": "#/$defs/node"}}}`,
+			want: `tree.child: $ref "#/$defs/node" refers to node, which refers to itself: recursive schemas aren't supported`,
+		},
+		{
+			name: "type mismatch",
+			give: `{"$defs": {"id": {"type": "string"}}, "properties": {"id": {"$ref": "#/$defs/id", "type": "integer"}}}`,
+			want: `id: $ref "#/$defs/id" is declared as string, but is used as integer`,
+		},
+		{
+			name: "dynamic",
+			give: `{"properties": {"group": {"$dynamicRef": "#group"}}}`,
+			want: `group: $dynamicRef "#group" isn't supported`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Schema
+			err := json.Unmarshal([]byte(tt.give), &s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Resolve(&s)
+			assert.EqualError(t, err, tt.want)
+
+			err = Validate(&s, map[string]any{})
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -1,5 +1,15 @@
 package jsoncel
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
 // Version is the JSON Schema version.
 var Version = "https://json-schema.org/draft/2020-12/schema"
 
@@ -7,13 +17,13 @@ var Version = "https://json-schema.org/draft/2020-12/schema"
 // RFC draft-bhutton-json-schema-00 section 4.3
 type Schema struct {
 	// RFC draft-bhutton-json-schema-00
-	Version    string `json:"$schema,omitempty"`     // section 8.1.1
-	ID         ID     `json:"$id,omitempty"`         // section 8.2.1
-	Anchor     string `json:"$anchor,omitempty"`     // section 8.2.2
-	Ref        string `json:"$ref,omitempty"`        // section 8.2.3.1
-	DynamicRef string `json:"$dynamicRef,omitempty"` // section 8.2.3.2
-	// Definitions Definitions `json:"$defs,omitempty"`       // section 8.2.4
-	Comments string `json:"$comment,omitempty"` // section 8.3
+	Version    string             `json:"$schema,omitempty"`     // section 8.1.1
+	ID         ID                 `json:"$id,omitempty"`         // section 8.2.1
+	Anchor     string             `json:"$anchor,omitempty"`     // section 8.2.2
+	Ref        string             `json:"$ref,omitempty"`        // section 8.2.3.1
+	DynamicRef string             `json:"$dynamicRef,omitempty"` // section 8.2.3.2
+	Defs       map[string]*Schema `json:"$defs,omitempty"`       // section 8.2.4
+	Comments   string             `json:"$comment,omitempty"`    // section 8.3
 	// RFC draft-bhutton-json-schema-00 section 10.2.1 (Sub-schemas with logic)
 	AllOf []*Schema `json:"allOf,omitempty"` // section 10.2.1.1
 	AnyOf []*Schema `json:"anyOf,omitempty"` // section 10.2.1.2
@@ -35,13 +45,14 @@ type Schema struct {
 	PropertyNames        *Schema            `json:"propertyNames,omitempty"`        // section 10.3.2.4
 	// RFC draft-bhutton-json-schema-validation-00, section 6
 	Type              FieldType           `json:"type,omitempty"`              // section 6.1.1
+	Types             []FieldType         `json:"-"`                           // section 6.1.1, see UnmarshalJSON
 	Enum              []interface{}       `json:"enum,omitempty"`              // section 6.1.2
 	Const             interface{}         `json:"const,omitempty"`             // section 6.1.3
-	MultipleOf        int                 `json:"multipleOf,omitempty"`        // section 6.2.1
-	Maximum           int                 `json:"maximum,omitempty"`           // section 6.2.2
-	ExclusiveMaximum  bool                `json:"exclusiveMaximum,omitempty"`  // section 6.2.3
-	Minimum           int                 `json:"minimum,omitempty"`           // section 6.2.4
-	ExclusiveMinimum  bool                `json:"exclusiveMinimum,omitempty"`  // section 6.2.5
+	MultipleOf        *float64            `json:"multipleOf,omitempty"`        // section 6.2.1
+	Maximum           *float64            `json:"maximum,omitempty"`           // section 6.2.2
+	ExclusiveMaximum  *float64            `json:"exclusiveMaximum,omitempty"`  // section 6.2.3
+	Minimum           *float64            `json:"minimum,omitempty"`           // section 6.2.4
+	ExclusiveMinimum  *float64            `json:"exclusiveMinimum,omitempty"`  // section 6.2.5
 	MaxLength         int                 `json:"maxLength,omitempty"`         // section 6.3.1
 	MinLength         int                 `json:"minLength,omitempty"`         // section 6.3.2
 	Pattern           string              `json:"pattern,omitempty"`           // section 6.3.3
@@ -69,10 +80,213 @@ type Schema struct {
 	WriteOnly   bool          `json:"writeOnly,omitempty"`   // section 9.4
 	Examples    []interface{} `json:"examples,omitempty"`    // section 9.5
 
+	// Sensitive is a Glide extension which marks a field as containing
+	// sensitive data, such as PII, which should be masked in logs and errors.
+	Sensitive bool `json:"x-sensitive,omitempty"`
+
+	// Extras are the keywords which aren't modelled by the struct,
+	// such as other extensions, keyed by their name.
 	Extras map[string]interface{} `json:"-"`
+}
+
+// Float returns a pointer to the number, for
+// setting numeric keywords such as Minimum.
+func Float(v float64) *float64 {
+	return &v
+}
+
+// schemaFields are the keywords which are modelled by the Schema struct.
+var schemaFields = func() map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(Schema{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// UnmarshalJSON parses a schema, including the forms of keywords
+// which can't be decoded into the struct directly:
+//
+//   - boolean schemas (section 4.3.2): 'true' is an empty schema which
+//     allows any value, and 'false' is parsed as {"not": {}}, which allows none.
+//   - 'items' as an array of schemas, which is how tuples were declared
+//     before draft 2020-12, is parsed as 'prefixItems'.
+//   - boolean 'exclusiveMinimum' and 'exclusiveMaximum' from draft 4, which
+//     make the 'minimum' and 'maximum' exclusive, are parsed as numbers.
+//   - 'type' as an array of types, e.g. ["string", "null"], is parsed as Types.
+//     If only one of the types isn't null, Type is set to it, so that the
+//     schema is treated as that type when the value isn't null.
+//
+// Keywords which aren't modelled by the struct are kept in Extras.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	switch string(bytes.TrimSpace(b)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{Not: &Schema{}}
+		return nil
+	}
+
+	// schemaAlias doesn't have the UnmarshalJSON method,
+	// so that decoding into it doesn't recurse.
+	type schemaAlias Schema
+	var raw struct {
+		schemaAlias
+		Type             json.RawMessage `json:"type,omitempty"`
+		Items            json.RawMessage `json:"items,omitempty"`
+		ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum,omitempty"`
+		ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum,omitempty"`
+	}
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+	*s = Schema(raw.schemaAlias)
+
+	s.Type, s.Types, err = parseType(raw.Type)
+	if err != nil {
+		return fmt.Errorf("invalid type: %w", err)
+	}
+
+	if len(raw.Items) > 0 {
+		if bytes.HasPrefix(bytes.TrimSpace(raw.Items), []byte("[")) {
+			err = json.Unmarshal(raw.Items, &s.PrefixItems)
+		} else {
+			err = json.Unmarshal(raw.Items, &s.Items)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid items: %w", err)
+		}
+	}
+
+	s.ExclusiveMaximum, s.Maximum, err = exclusiveBound(raw.ExclusiveMaximum, s.Maximum)
+	if err != nil {
+		return fmt.Errorf("invalid exclusiveMaximum: %w", err)
+	}
+	s.ExclusiveMinimum, s.Minimum, err = exclusiveBound(raw.ExclusiveMinimum, s.Minimum)
+	if err != nil {
+		return fmt.Errorf("invalid exclusiveMinimum: %w", err)
+	}
+
+	var keywords map[string]any
+	err = json.Unmarshal(b, &keywords)
+	if err != nil {
+		return err
+	}
+	for k, v := range keywords {
+		if schemaFields[k] {
+			continue
+		}
+		if s.Extras == nil {
+			s.Extras = map[string]interface{}{}
+		}
+		s.Extras[k] = v
+	}
+	return nil
+}
+
+// MarshalJSON encodes the schema, writing Types as the 'type' array if it's set.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schemaAlias Schema
+	if len(s.Types) == 0 {
+		return json.Marshal(schemaAlias(s))
+	}
+	return json.Marshal(struct {
+		schemaAlias
+		Type []FieldType `json:"type"`
+	}{schemaAlias: schemaAlias(s), Type: s.Types})
+}
+
+// parseType parses the 'type' keyword, which is a type or an array of types.
+// It returns the type of the schema, and the types if there are more than one.
+func parseType(raw json.RawMessage) (FieldType, []FieldType, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "", nil, nil
+	}
+	if !bytes.HasPrefix(raw, []byte("[")) {
+		var t FieldType
+		err := json.Unmarshal(raw, &t)
+		return t, nil, err
+	}
+
+	var all []FieldType
+	err := json.Unmarshal(raw, &all)
+	if err != nil {
+		return "", nil, err
+	}
+	var types, nonNull []FieldType
+	for _, t := range all {
+		if containsType(types, t) {
+			continue
+		}
+		types = append(types, t)
+		if t != Null {
+			nonNull = append(nonNull, t)
+		}
+	}
 
-	// Special boolean representation of the Schema - section 4.3.2
-	// boolean *bool
+	switch {
+	case len(types) == 0:
+		return "", nil, errors.New("must list at least one type")
+	case len(types) == 1:
+		return types[0], nil, nil
+	case len(nonNull) == 1:
+		return nonNull[0], types, nil
+	}
+	return "", types, nil
+}
+
+func containsType(types []FieldType, t FieldType) bool {
+	for _, el := range types {
+		if el == t {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTypes returns the types a value of the schema may have,
+// or nil if the schema doesn't declare a type.
+func (s *Schema) AllowedTypes() []FieldType {
+	if len(s.Types) > 0 {
+		return s.Types
+	}
+	if s.Type != "" {
+		return []FieldType{s.Type}
+	}
+	return nil
+}
+
+// Nullable returns true if the schema's 'type' allows null
+// along with another type, such as ["string", "null"].
+func (s *Schema) Nullable() bool {
+	return len(s.Types) > 1 && containsType(s.Types, Null)
+}
+
+// exclusiveBound parses an exclusive bound, which is a number, or
+// a boolean in draft 4 which makes the inclusive bound exclusive.
+// It returns the exclusive bound and the inclusive bound.
+func exclusiveBound(raw json.RawMessage, inclusive *float64) (exclusive, bound *float64, err error) {
+	switch string(bytes.TrimSpace(raw)) {
+	case "":
+		return nil, inclusive, nil
+	case "true":
+		return inclusive, nil, nil
+	case "false":
+		return nil, inclusive, nil
+	}
+	var v float64
+	err = json.Unmarshal(raw, &v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &v, inclusive, nil
 }
 
 type FieldType string
@@ -86,3 +300,76 @@ const (
 	String  FieldType = "string"
 	Integer FieldType = "integer"
 )
+
+// MissingFields returns the properties declared in the schema which
+// are not present in the value, as dot-separated paths (e.g. 'group.id').
+//
+// If an object is missing, only the object itself is returned
+// rather than each of its nested properties.
+// The returned paths are sorted.
+func MissingFields(s *Schema, value map[string]any) []string {
+	var missing []string
+	appendMissingFields(&missing, "", s, value)
+	sort.Strings(missing)
+	return missing
+}
+
+func appendMissingFields(missing *[]string, prefix string, s *Schema, value map[string]any) {
+	if s == nil {
+		return
+	}
+	for k, child := range s.Properties {
+		v, ok := value[k]
+		if !ok {
+			*missing = append(*missing, prefix+k)
+			continue
+		}
+		if m, ok := v.(map[string]any); ok {
+			appendMissingFields(missing, prefix+k+".", child, m)
+		}
+	}
+}
+
+// Lookup returns the schema of the field at a dot-separated path,
+// e.g. 'group.id', or nil if the field isn't declared in the schema.
+// The keys of objects which are typed as maps by 'additionalProperties'
+// or 'patternProperties' are declared, e.g. 'labels.team'.
+func Lookup(s *Schema, path string) *Schema {
+	for _, part := range strings.Split(path, ".") {
+		if s == nil {
+			return nil
+		}
+		if child, ok := s.Properties[part]; ok {
+			s = child
+			continue
+		}
+		s = mapValueSchema(s)
+	}
+	return s
+}
+
+// SensitiveFields returns the properties annotated with 'x-sensitive'
+// in the schema, as sorted dot-separated paths (e.g. 'user.email').
+// The properties nested inside a sensitive object aren't returned.
+func SensitiveFields(s *Schema) []string {
+	var fields []string
+	appendSensitiveFields(&fields, "", s)
+	sort.Strings(fields)
+	return fields
+}
+
+func appendSensitiveFields(fields *[]string, prefix string, s *Schema) {
+	if s == nil {
+		return
+	}
+	for k, child := range s.Properties {
+		if child == nil {
+			continue
+		}
+		if child.Sensitive {
+			*fields = append(*fields, prefix+k)
+			continue
+		}
+		appendSensitiveFields(fields, prefix+k+".", child)
+	}
+}
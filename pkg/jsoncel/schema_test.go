@@ -0,0 +1,136 @@
+package jsoncel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingFields(t *testing.T) {
+	s := &Schema{
+		Properties: map[string]*Schema{
+			"name": {Type: String},
+			"group": {
+				Type: Object,
+				Properties: map[string]*Schema{
+					"id":   {Type: String},
+					"name": {Type: String},
+				},
+			},
+			"pagerduty": {
+				Type: Object,
+				Properties: map[string]*Schema{
+					"on_call": {Type: Boolean},
+				},
+			},
+		},
+	}
+
+	got := MissingFields(s, map[string]any{
+		"name": "test",
+		"group": map[string]any{
+			"id": "admins",
+		},
+	})
+
+	assert.Equal(t, []string{"group.name", "pagerduty"}, got)
+}
+
+func TestSensitiveFields(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"user": {
+				"type": "object",
+				"properties": {
+					"email": {"type": "string", "x-sensitive": true},
+					"id": {"type": "string"}
+				}
+			},
+			"address": {
+				"type": "object",
+				"x-sensitive": true,
+				"properties": {
+					"street": {"type": "string", "x-sensitive": true}
+				}
+			}
+		}
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"address", "user.email"}, SensitiveFields(&s))
+}
+
+func TestSchema_UnmarshalJSON(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"any": true,
+			"none": false,
+			"pair": {"type": "array", "items": [{"type": "string"}, {"type": "integer"}]},
+			"legacy": {"type": "integer", "minimum": 1, "exclusiveMinimum": true, "maximum": 10},
+			"kind": {"const": "access"}
+		},
+		"patternProperties": {"^x-": {"type": "string"}},
+		"x-custom": "value"
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, &Schema{}, s.Properties["any"])
+	assert.Equal(t, &Schema{Not: &Schema{}}, s.Properties["none"])
+	assert.Equal(t, []*Schema{{Type: String}, {Type: Integer}}, s.Properties["pair"].PrefixItems)
+	assert.Nil(t, s.Properties["pair"].Items)
+
+	legacy := s.Properties["legacy"]
+	assert.Nil(t, legacy.Minimum)
+	assert.Equal(t, Float(1), legacy.ExclusiveMinimum)
+	assert.Equal(t, Float(10), legacy.Maximum)
+
+	assert.Equal(t, "access", s.Properties["kind"].Const)
+	assert.Equal(t, &Schema{Type: String}, s.PatternProperties["^x-"])
+	assert.Equal(t, "value", s.Extras["x-custom"])
+}
+
+func TestSchema_UnmarshalJSON_TypeArrays(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"nullable": {"type": ["string", "null"]},
+			"single": {"type": ["integer"]},
+			"union": {"type": ["string", "integer", "null"]}
+		}
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nullable := s.Properties["nullable"]
+	assert.Equal(t, String, nullable.Type)
+	assert.Equal(t, []FieldType{String, Null}, nullable.AllowedTypes())
+	assert.True(t, nullable.Nullable())
+
+	assert.Equal(t, &Schema{Type: Integer}, s.Properties["single"])
+
+	union := s.Properties["union"]
+	assert.Equal(t, FieldType(""), union.Type)
+	assert.Equal(t, []FieldType{String, Integer, Null}, union.AllowedTypes())
+
+	// type arrays are written back as arrays.
+	b, err := json.Marshal(nullable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{"type": ["string", "null"]}`, string(b))
+
+	err = json.Unmarshal([]byte(`{"type": []}`), &s)
+	assert.EqualError(t, err, "invalid type: must list at least one type")
+}
@@ -0,0 +1,475 @@
+package jsoncel
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError is a value which isn't valid for its schema.
+type ValidationError struct {
+	// Path is the dot-separated path of the value, e.g. 'group.id'
+	// or 'approvers.0', which is empty for the root value.
+	Path string
+
+	// Message describes why the value isn't valid, e.g. 'must be at least 1'.
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors are the values which aren't valid for a schema, sorted by their path.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks that a value, such as a decoded JSON input, is valid for the
+// schema. It returns ValidationErrors listing every value which isn't valid.
+//
+// The assertion keywords of draft 2020-12 are checked, including 'const', 'enum',
+// the numeric bounds, 'pattern', 'prefixItems', 'patternProperties' and the
+// applicators like 'anyOf' and 'if'. Formats are annotations in draft 2020-12,
+// so they aren't checked. References to '$defs' are resolved with Resolve,
+// and an error is returned if they can't be.
+func Validate(s *Schema, value any) error {
+	s, err := Resolve(s)
+	if err != nil {
+		return err
+	}
+	v := validator{}
+	v.validate("", s, value)
+	return v.result()
+}
+
+// ValidatePartial is Validate, except that required properties which are
+// missing are allowed, for inputs which are still being filled in.
+func ValidatePartial(s *Schema, value any) error {
+	s, err := Resolve(s)
+	if err != nil {
+		return err
+	}
+	v := validator{partial: true}
+	v.validate("", s, value)
+	return v.result()
+}
+
+type validator struct {
+	partial bool
+	errs    ValidationErrors
+}
+
+func (v *validator) result() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.errs, func(i, j int) bool { return v.errs[i].Path < v.errs[j].Path })
+	return v.errs
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid returns true if the value is valid for the schema,
+// without recording the errors.
+func (v *validator) valid(s *Schema, value any) bool {
+	sub := validator{partial: v.partial}
+	sub.validate("", s, value)
+	return len(sub.errs) == 0
+}
+
+func (v *validator) validate(path string, s *Schema, value any) {
+	if s == nil {
+		return
+	}
+	value = normalize(value)
+
+	if types := s.AllowedTypes(); len(types) > 0 && !hasAnyType(value, types) {
+		v.fail(path, "must be %s", describeTypes(types))
+		return
+	}
+	if s.Const != nil && !equal(value, normalize(s.Const)) {
+		v.fail(path, "must be %s", describe(s.Const))
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if equal(value, normalize(e)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			var values []string
+			for _, e := range s.Enum {
+				values = append(values, describe(e))
+			}
+			v.fail(path, "must be one of %s", strings.Join(values, ", "))
+		}
+	}
+
+	switch t := value.(type) {
+	case float64:
+		v.number(path, s, t)
+	case string:
+		v.string(path, s, t)
+	case []any:
+		v.array(path, s, t)
+	case map[string]any:
+		v.object(path, s, t)
+	}
+
+	v.applicators(path, s, value)
+}
+
+func (v *validator) number(path string, s *Schema, n float64) {
+	if s.Minimum != nil && n < *s.Minimum {
+		v.fail(path, "must be at least %v", *s.Minimum)
+	}
+	if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+		v.fail(path, "must be greater than %v", *s.ExclusiveMinimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		v.fail(path, "must be at most %v", *s.Maximum)
+	}
+	if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
+		v.fail(path, "must be less than %v", *s.ExclusiveMaximum)
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		q := n / *s.MultipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, "must be a multiple of %v", *s.MultipleOf)
+		}
+	}
+}
+
+func (v *validator) string(path string, s *Schema, str string) {
+	n := utf8.RuneCountInString(str)
+	if s.MinLength > 0 && n < s.MinLength {
+		v.fail(path, "must be at least %d characters long", s.MinLength)
+	}
+	if s.MaxLength > 0 && n > s.MaxLength {
+		v.fail(path, "must be at most %d characters long", s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			v.fail(path, "has an invalid pattern %q in its schema: %s", s.Pattern, err)
+		} else if !re.MatchString(str) {
+			v.fail(path, "must match the pattern %q", s.Pattern)
+		}
+	}
+}
+
+func (v *validator) array(path string, s *Schema, items []any) {
+	if s.MinItems > 0 && len(items) < s.MinItems {
+		v.fail(path, "must have at least %d items", s.MinItems)
+	}
+	if s.MaxItems > 0 && len(items) > s.MaxItems {
+		v.fail(path, "must have at most %d items", s.MaxItems)
+	}
+	if s.UniqueItems {
+	outer:
+		for i := range items {
+			for j := 0; j < i; j++ {
+				if equal(normalize(items[i]), normalize(items[j])) {
+					v.fail(path, "must not have duplicate items")
+					break outer
+				}
+			}
+		}
+	}
+
+	for i, item := range items {
+		itemSchema := s.Items
+		if i < len(s.PrefixItems) {
+			itemSchema = s.PrefixItems[i]
+		}
+		v.validate(join(path, fmt.Sprint(i)), itemSchema, item)
+	}
+
+	if s.Contains != nil {
+		var n uint
+		for _, item := range items {
+			if v.valid(s.Contains, item) {
+				n++
+			}
+		}
+		min := s.MinContains
+		if min == 0 {
+			min = 1
+		}
+		if n < min {
+			v.fail(path, "must contain at least %d matching items", min)
+		}
+		if s.MaxContains > 0 && n > s.MaxContains {
+			v.fail(path, "must contain at most %d matching items", s.MaxContains)
+		}
+	}
+}
+
+func (v *validator) object(path string, s *Schema, obj map[string]any) {
+	if !v.partial {
+		for _, k := range s.Required {
+			if _, ok := obj[k]; !ok {
+				v.fail(join(path, k), "is required")
+			}
+		}
+		for k, deps := range s.DependentRequired {
+			if _, ok := obj[k]; !ok {
+				continue
+			}
+			for _, d := range deps {
+				if _, ok := obj[d]; !ok {
+					v.fail(join(path, d), "is required when %s is set", k)
+				}
+			}
+		}
+	}
+	if s.MinProperties > 0 && len(obj) < s.MinProperties {
+		v.fail(path, "must have at least %d properties", s.MinProperties)
+	}
+	if s.MaxProperties > 0 && len(obj) > s.MaxProperties {
+		v.fail(path, "must have at most %d properties", s.MaxProperties)
+	}
+
+	patterns := map[string]*regexp.Regexp{}
+	for pattern := range s.PatternProperties {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.fail(path, "has an invalid pattern property %q in its schema: %s", pattern, err)
+			continue
+		}
+		patterns[pattern] = re
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := join(path, k)
+		matched := false
+		if ps, ok := s.Properties[k]; ok {
+			v.validate(child, ps, obj[k])
+			matched = true
+		}
+		for pattern, re := range patterns {
+			if re.MatchString(k) {
+				v.validate(child, s.PatternProperties[pattern], obj[k])
+				matched = true
+			}
+		}
+		if !matched && s.AdditionalProperties != nil {
+			if isFalse(s.AdditionalProperties) {
+				v.fail(child, "is not allowed")
+			} else {
+				v.validate(child, s.AdditionalProperties, obj[k])
+			}
+		}
+		if s.PropertyNames != nil && !v.valid(s.PropertyNames, k) {
+			v.fail(child, "is not a valid property name")
+		}
+		if dep, ok := s.DependentSchemas[k]; ok {
+			v.validate(path, dep, obj)
+		}
+	}
+}
+
+func (v *validator) applicators(path string, s *Schema, value any) {
+	for _, sub := range s.AllOf {
+		v.validate(path, sub, value)
+	}
+	if len(s.AnyOf) > 0 {
+		ok := false
+		for _, sub := range s.AnyOf {
+			if v.valid(sub, value) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			v.fail(path, "must match at least one schema in anyOf")
+		}
+	}
+	if len(s.OneOf) > 0 {
+		n := 0
+		for _, sub := range s.OneOf {
+			if v.valid(sub, value) {
+				n++
+			}
+		}
+		if n != 1 {
+			v.fail(path, "must match exactly one schema in oneOf, but matched %d", n)
+		}
+	}
+	if s.Not != nil {
+		if isFalse(s) {
+			v.fail(path, "is not allowed")
+		} else if v.valid(s.Not, value) {
+			v.fail(path, "must not match the schema in not")
+		}
+	}
+	if s.If != nil {
+		if v.valid(s.If, value) {
+			v.validate(path, s.Then, value)
+		} else {
+			v.validate(path, s.Else, value)
+		}
+	}
+}
+
+// isFalse returns true if the schema is the boolean schema 'false',
+// which is parsed as {"not": {}}.
+func isFalse(s *Schema) bool {
+	return s.Not != nil && reflect.DeepEqual(*s.Not, Schema{}) && reflect.DeepEqual(*s, Schema{Not: s.Not})
+}
+
+// normalize converts Go numbers to float64, and other slices and
+// maps to []any and map[string]any, so that values decoded from
+// JSON and values constructed in Go are validated the same way.
+func normalize(value any) any {
+	switch t := value.(type) {
+	case nil, bool, string, float64, []any, map[string]any:
+		return value
+	case float32:
+		return float64(t)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Slice, reflect.Array:
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = rv.Index(i).Interface()
+		}
+		return out
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = iter.Value().Interface()
+		}
+		return out
+	}
+	return value
+}
+
+// hasType returns true if a normalized value has the JSON type.
+func hasType(value any, t FieldType) bool {
+	switch v := value.(type) {
+	case nil:
+		return t == Null
+	case bool:
+		return t == Boolean
+	case string:
+		return t == String
+	case float64:
+		return t == Number || (t == Integer && v == math.Trunc(v))
+	case []any:
+		return t == Array
+	case map[string]any:
+		return t == Object
+	}
+	return false
+}
+
+func hasAnyType(value any, types []FieldType) bool {
+	for _, t := range types {
+		if hasType(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeTypes lists types for an error message, e.g. 'a string or null'.
+func describeTypes(types []FieldType) string {
+	var names []string
+	for _, t := range types {
+		if t == Null {
+			names = append(names, string(t))
+			continue
+		}
+		names = append(names, article(t)+" "+string(t))
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// equal compares normalized values, comparing the elements of arrays and objects.
+func equal(a, b any) bool {
+	switch at := a.(type) {
+	case []any:
+		bt, ok := b.([]any)
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for i := range at {
+			if !equal(normalize(at[i]), normalize(bt[i])) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bt, ok := b.(map[string]any)
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for k, av := range at {
+			bv, ok := bt[k]
+			if !ok || !equal(normalize(av), normalize(bv)) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func describe(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
+
+func article(t FieldType) string {
+	if t == Integer || t == Object || t == Array {
+		return "an"
+	}
+	return "a"
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
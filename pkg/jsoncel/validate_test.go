@@ -0,0 +1,141 @@
+package jsoncel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["group", "duration"],
+		"properties": {
+			"group": {"type": "string", "pattern": "^[a-z]+$"},
+			"duration": {"type": "integer", "minimum": 1, "exclusiveMaximum": 24},
+			"kind": {"const": "access"},
+			"approvers": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+			"range": {"type": "array", "prefixItems": [{"type": "integer"}, {"type": "integer"}]}
+		},
+		"patternProperties": {
+			"^x-": {"type": "string"}
+		},
+		"additionalProperties": false
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   map[string]any
+		partial bool
+		want    []string
+	}{
+		{
+			name:  "valid",
+			value: map[string]any{"group": "admins", "duration": 2.0, "kind": "access", "x-ticket": "T-1", "range": []any{1, 2}},
+		},
+		{
+			name:  "missing required",
+			value: map[string]any{"group": "admins"},
+			want:  []string{"duration: is required"},
+		},
+		{
+			name:    "missing required with partial input",
+			value:   map[string]any{"group": "admins"},
+			partial: true,
+		},
+		{
+			name:  "bounds",
+			value: map[string]any{"group": "Admins", "duration": 24},
+			want:  []string{`duration: must be less than 24`, `group: must match the pattern "^[a-z]+$"`},
+		},
+		{
+			name:  "not an integer",
+			value: map[string]any{"group": "admins", "duration": 1.5},
+			want:  []string{"duration: must be an integer"},
+		},
+		{
+			name:  "const",
+			value: map[string]any{"group": "admins", "duration": 1, "kind": "other"},
+			want:  []string{`kind: must be "access"`},
+		},
+		{
+			name:  "array items",
+			value: map[string]any{"group": "admins", "duration": 1, "approvers": []string{"a", "a", "b"}},
+			want:  []string{"approvers: must have at most 2 items", "approvers: must not have duplicate items"},
+		},
+		{
+			name:  "tuple items",
+			value: map[string]any{"group": "admins", "duration": 1, "range": []any{1, "two"}},
+			want:  []string{"range.1: must be an integer"},
+		},
+		{
+			name:  "pattern and additional properties",
+			value: map[string]any{"group": "admins", "duration": 1, "x-ticket": 1, "other": true},
+			want:  []string{"other: is not allowed", "x-ticket: must be a string"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validate := Validate
+			if tt.partial {
+				validate = ValidatePartial
+			}
+			err := validate(&s, tt.value)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var got []string
+			for _, e := range err.(ValidationErrors) {
+				got = append(got, e.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidate_Applicators(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"kind": {"enum": ["user", "group"]}
+		},
+		"if": {"properties": {"kind": {"const": "group"}}},
+		"then": {"required": ["group"]},
+		"else": {"required": ["user"]}
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, Validate(&s, map[string]any{"kind": "group", "group": "admins"}))
+	assert.EqualError(t, Validate(&s, map[string]any{"kind": "user"}), "user: is required")
+	assert.EqualError(t, Validate(&s, map[string]any{"kind": "team", "user": "a"}), `kind: must be one of "user", "group"`)
+}
+
+func TestValidate_TypeArrays(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": ["string", "null"], "minLength": 2},
+			"value": {"type": ["string", "integer"]}
+		}
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, Validate(&s, map[string]any{"name": nil, "value": 1}))
+	assert.NoError(t, Validate(&s, map[string]any{"name": "bob", "value": "one"}))
+	assert.EqualError(t, Validate(&s, map[string]any{"name": "b"}), "name: must be at least 2 characters long")
+	assert.EqualError(t, Validate(&s, map[string]any{"name": 1}), "name: must be a string or null")
+	assert.EqualError(t, Validate(&s, map[string]any{"value": true}), "value: must be a string or an integer")
+}
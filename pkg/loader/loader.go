@@ -0,0 +1,407 @@
+// Package loader loads workflow, schema and input files from local
+// or remote sources, so that they don't need to be on the local disk.
+//
+// The following sources are supported:
+//
+//	workflow.yml                                    a local file
+//	file:///policies/workflow.yml                   a local file
+//	https://example.com/workflow.yml                a file served over HTTP(S)
+//	s3://bucket/workflow.yml?region=us-west-2       a publicly readable S3 object
+//	git+https://github.com/org/repo.git//workflow.yml?ref=v1.0.0
+//	                                                a file in a git repository, at a ref
+//
+// A source can be pinned to a checksum by adding a '#sha256=<hex>' suffix.
+// The content of a pinned source is verified before it is returned,
+// and is cached so that later loads don't need to fetch it again.
+//
+// A Loader with trusted keys only returns sources which were signed by one
+// of them, so that servers only execute workflows from trusted publishers.
+// The detached signature is loaded from next to the source, with the
+// '.sig' extension, like the signatures made by 'glide bundle sign'.
+package loader
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/bundle"
+)
+
+// ErrChecksumMismatch is returned if the content of a
+// pinned source does not match the checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// maxSize is the maximum size of a remote source.
+const maxSize = 10 << 20 // 10 MiB
+
+// Loader loads files from local or remote sources.
+type Loader struct {
+	// CacheDir is the directory used to cache pinned sources.
+	// If empty, sources are not cached.
+	CacheDir string
+
+	// HTTPClient is used to fetch HTTP(S) and S3 sources.
+	// If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// TrustedKeys are the keys which sources must be signed by.
+	// If empty, signatures aren't checked.
+	TrustedKeys []ed25519.PublicKey
+}
+
+// New returns a Loader which caches pinned sources
+// in the user's cache directory.
+func New() (*Loader, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Loader{CacheDir: filepath.Join(dir, "glide")}, nil
+}
+
+// Load the content of a source.
+func (l *Loader) Load(ctx context.Context, source string) ([]byte, error) {
+	location, checksum, err := splitChecksum(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != "" {
+		data, signature, ok := l.cached(checksum)
+		if ok {
+			// cached content is verified again, as the trusted keys may have changed.
+			err = l.verify(location, data, signature)
+			if err != nil {
+				return nil, err
+			}
+			return data, nil
+		}
+	}
+
+	data, err := l.fetch(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != "" {
+		got := sha256Hex(data)
+		if got != checksum {
+			return nil, fmt.Errorf("%w for %s: expected sha256 %s but got %s", ErrChecksumMismatch, location, checksum, got)
+		}
+	}
+
+	var signature []byte
+	if len(l.TrustedKeys) > 0 {
+		signature, err = l.fetch(ctx, signatureLocation(location))
+		if err != nil {
+			return nil, fmt.Errorf("loading the signature for %s: %w", location, err)
+		}
+		err = l.verify(location, data, signature)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if checksum != "" {
+		err = l.store(checksum, data, signature)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// verify returns an error wrapping bundle.ErrInvalidSignature if the loader
+// has trusted keys and the content wasn't signed by any of them.
+func (l *Loader) verify(location string, data, signature []byte) error {
+	if len(l.TrustedKeys) == 0 {
+		return nil
+	}
+	err := bundle.VerifySignature(data, signature, l.TrustedKeys...)
+	if err != nil {
+		return fmt.Errorf("verifying %s: %w", location, err)
+	}
+	return nil
+}
+
+// signatureLocation returns the location of the detached signature for a source,
+// which has the signature extension added to its path, e.g.
+// 'git+https://github.com/org/repo.git//bundle.tar.gz.sig?ref=v1.0.0'.
+func signatureLocation(location string) string {
+	if i := strings.Index(location, "?"); i != -1 && IsRemote(location) {
+		return location[:i] + bundle.SignatureExt + location[i:]
+	}
+	return location + bundle.SignatureExt
+}
+
+// IsRemote returns true if the source is not a local file.
+func IsRemote(source string) bool {
+	for _, prefix := range []string{"http://", "https://", "s3://", "git+"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitChecksum splits a source into its location and
+// the sha256 checksum it is pinned to, if any.
+// Fragments other than '#sha256=<hex>' are part of the location.
+func splitChecksum(source string) (location string, checksum string, err error) {
+	i := strings.LastIndex(source, "#")
+	if i == -1 {
+		return source, "", nil
+	}
+
+	location, fragment := source[:i], source[i+1:]
+	checksum = strings.TrimPrefix(fragment, "sha256=")
+	if checksum == fragment {
+		return source, "", nil
+	}
+
+	checksum = strings.ToLower(checksum)
+	b, err := hex.DecodeString(checksum)
+	if err != nil || len(b) != sha256.Size {
+		return "", "", fmt.Errorf("invalid sha256 checksum %q", checksum)
+	}
+	return location, checksum, nil
+}
+
+func (l *Loader) fetch(ctx context.Context, location string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(location, "git+"):
+		return fetchGit(ctx, strings.TrimPrefix(location, "git+"))
+	case strings.HasPrefix(location, "s3://"):
+		u, err := s3URL(location)
+		if err != nil {
+			return nil, err
+		}
+		return l.fetchHTTP(ctx, u)
+	case strings.HasPrefix(location, "http://"), strings.HasPrefix(location, "https://"):
+		return l.fetchHTTP(ctx, location)
+	case strings.HasPrefix(location, "file://"):
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, err
+		}
+		return os.ReadFile(fileURLPath(u))
+	default:
+		return os.ReadFile(location)
+	}
+}
+
+// fileURLPath returns the local path for a file URL. On Windows, file URLs
+// look like 'file:///C:/policies/workflow.yml', so the slash before
+// the volume name is removed.
+func fileURLPath(u *url.URL) string {
+	p := u.Path
+	if strings.HasPrefix(p, "/") && filepath.VolumeName(p[1:]) != "" {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+func (l *Loader) fetchHTTP(ctx context.Context, u string) ([]byte, error) {
+	client := l.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", u, res.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("fetching %s: response is larger than the maximum size of %d bytes", u, maxSize)
+	}
+	return data, nil
+}
+
+// s3URL converts an S3 source into the HTTPS URL for the object.
+// Only publicly readable objects are supported, as requests are not signed.
+func s3URL(location string) (string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return "", fmt.Errorf("invalid S3 source %s: expected s3://<bucket>/<key>", location)
+	}
+
+	host := u.Host + ".s3.amazonaws.com"
+	if region := u.Query().Get("region"); region != "" {
+		host = u.Host + ".s3." + region + ".amazonaws.com"
+	}
+
+	return (&url.URL{Scheme: "https", Host: host, Path: u.Path}).String(), nil
+}
+
+// fetchGit reads a file from a git repository.
+// The source is in the format '<repo>//<path>?ref=<ref>'.
+// If the ref is not provided, the default branch is used.
+func fetchGit(ctx context.Context, source string) ([]byte, error) {
+	repo, ref, path, err := parseGitSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "glide-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) ([]byte, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		if err != nil {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.Bytes(), nil
+	}
+
+	_, err = git("init", "-q")
+	if err != nil {
+		return nil, err
+	}
+	_, err = git("fetch", "-q", "--depth", "1", repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	return git("show", "FETCH_HEAD:"+path)
+}
+
+// parseGitSource parses a source in the format '<repo>//<path>?ref=<ref>'.
+func parseGitSource(source string) (repo, ref, path string, err error) {
+	source, query, _ := strings.Cut(source, "?")
+	if query != "" {
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			return "", "", "", err
+		}
+		ref = q.Get("ref")
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// skip the '//' following the scheme when looking for the path separator.
+	start := 0
+	if i := strings.Index(source, "://"); i != -1 {
+		start = i + len("://")
+	}
+	i := strings.Index(source[start:], "//")
+	if i == -1 {
+		return "", "", "", fmt.Errorf("invalid git source %s: expected <repo>//<path>", source)
+	}
+	repo, path = source[:start+i], source[start+i+2:]
+
+	// prevent arguments from being interpreted as git options.
+	for _, arg := range []string{repo, ref, path} {
+		if arg == "" || strings.HasPrefix(arg, "-") {
+			return "", "", "", fmt.Errorf("invalid git source %s", source)
+		}
+	}
+
+	return repo, ref, path, nil
+}
+
+// cached returns the cached content for a checksum, and its signature if the
+// loader has trusted keys. Cache entries which don't match their checksum,
+// or which weren't cached with a signature when one is needed, are ignored.
+func (l *Loader) cached(checksum string) (data, signature []byte, ok bool) {
+	if l.CacheDir == "" {
+		return nil, nil, false
+	}
+	dir := filepath.Join(l.CacheDir, "sha256")
+	data, err := os.ReadFile(filepath.Join(dir, checksum))
+	if err != nil || sha256Hex(data) != checksum {
+		return nil, nil, false
+	}
+	if len(l.TrustedKeys) > 0 {
+		signature, err = os.ReadFile(filepath.Join(dir, checksum+bundle.SignatureExt))
+		if err != nil {
+			return nil, nil, false
+		}
+	}
+	return data, signature, true
+}
+
+// store the content for a checksum in the cache,
+// along with its signature if it has one.
+func (l *Loader) store(checksum string, data, signature []byte) error {
+	if l.CacheDir == "" {
+		return nil
+	}
+	dir := filepath.Join(l.CacheDir, "sha256")
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	// the signature is written first, so that content
+	// is never cached without the signature it was loaded with.
+	if signature != nil {
+		err = writeFile(dir, checksum+bundle.SignatureExt, signature)
+		if err != nil {
+			return err
+		}
+	}
+	return writeFile(dir, checksum, data)
+}
+
+// writeFile writes a file in the cache. It writes to a temporary file
+// first, so that partially written entries are never read.
+func writeFile(dir, name string, data []byte) error {
+	f, err := os.CreateTemp(dir, name+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(dir, name))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,323 @@
+package loader
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/bundle"
+	"github.com/stretchr/testify/assert"
+)
+
+const content = "workflow: {}\n"
+
+// contentSum is the sha256 checksum of content.
+var contentSum = sha256Hex([]byte(content))
+
+func TestLoad(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workflow.yml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	local := filepath.Join(t.TempDir(), "workflow.yml")
+	err := os.WriteFile(local, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashed := filepath.Join(t.TempDir(), "workflow.yml#v1")
+	err = os.WriteFile(hashed, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		name    string
+		source  string
+		wantErr string
+	}
+
+	testcases := []testcase{
+		{name: "local file", source: local},
+		{name: "file url", source: "file://" + local},
+		{name: "http", source: srv.URL + "/workflow.yml"},
+		{name: "pinned", source: srv.URL + "/workflow.yml#sha256=" + contentSum},
+		{name: "local file pinned", source: local + "#sha256=" + contentSum},
+		{name: "not found", source: srv.URL + "/other.yml", wantErr: "unexpected status 404 Not Found"},
+		{name: "checksum mismatch", source: srv.URL + "/workflow.yml#sha256=" + sha256Hex([]byte("other")), wantErr: "checksum mismatch"},
+		{name: "invalid checksum", source: srv.URL + "/workflow.yml#sha256=abc", wantErr: `invalid sha256 checksum "abc"`},
+		// fragments which aren't checksums are left in the source.
+		{name: "fragment", source: srv.URL + "/workflow.yml#section"},
+		{name: "local file with a # in its name", source: hashed},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			l := Loader{}
+			got, err := l.Load(context.Background(), tt.source)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, content, string(got))
+		})
+	}
+}
+
+func TestLoad_Cache(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	l := Loader{CacheDir: t.TempDir()}
+	source := srv.URL + "/workflow.yml#sha256=" + contentSum
+
+	for i := 0; i < 2; i++ {
+		got, err := l.Load(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, content, string(got))
+	}
+
+	// the second load should be served from the cache.
+	assert.Equal(t, 1, requests)
+
+	// unpinned sources are always fetched.
+	_, err := l.Load(context.Background(), srv.URL+"/workflow.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, requests)
+}
+
+func TestLoad_Signature(t *testing.T) {
+	pub, priv, err := bundle.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := bundle.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/workflow.yml", "/unsigned.yml":
+			_, _ = w.Write([]byte(content))
+		case "/workflow.yml.sig":
+			_, _ = w.Write(bundle.Sign([]byte(content), priv))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	testcases := []struct {
+		name    string
+		source  string
+		trusted []ed25519.PublicKey
+		wantErr error
+	}{
+		{name: "signed", source: srv.URL + "/workflow.yml", trusted: []ed25519.PublicKey{pub}},
+		{name: "signed by one of the keys", source: srv.URL + "/workflow.yml", trusted: []ed25519.PublicKey{other, pub}},
+		{name: "signed by an untrusted key", source: srv.URL + "/workflow.yml", trusted: []ed25519.PublicKey{other}, wantErr: bundle.ErrInvalidSignature},
+		{name: "no trusted keys", source: srv.URL + "/unsigned.yml"},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			l := Loader{TrustedKeys: tt.trusted}
+			got, err := l.Load(context.Background(), tt.source)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, content, string(got))
+		})
+	}
+
+	t.Run("missing signature", func(t *testing.T) {
+		l := Loader{TrustedKeys: []ed25519.PublicKey{pub}}
+		_, err := l.Load(context.Background(), srv.URL+"/unsigned.yml")
+		assert.ErrorContains(t, err, "loading the signature for "+srv.URL+"/unsigned.yml: fetching "+srv.URL+"/unsigned.yml.sig: unexpected status 404 Not Found")
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		dir := t.TempDir()
+		source := srv.URL + "/workflow.yml#sha256=" + contentSum
+
+		l := Loader{CacheDir: dir, TrustedKeys: []ed25519.PublicKey{pub}}
+		_, err := l.Load(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the signature is cached with the content.
+		requests = 0
+		got, err := l.Load(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, content, string(got))
+		assert.Equal(t, 0, requests)
+
+		// cached content is checked against the current trusted keys.
+		l.TrustedKeys = []ed25519.PublicKey{other}
+		_, err = l.Load(context.Background(), source)
+		assert.ErrorIs(t, err, bundle.ErrInvalidSignature)
+	})
+}
+
+func TestSignatureLocation(t *testing.T) {
+	tests := map[string]string{
+		"workflow.yml":                                                  "workflow.yml.sig",
+		"https://example.com/bundle.tar.gz":                             "https://example.com/bundle.tar.gz.sig",
+		"s3://bucket/bundle.tar.gz?region=us-west-2":                    "s3://bucket/bundle.tar.gz.sig?region=us-west-2",
+		"git+https://github.com/org/repo.git//bundle.tar.gz?ref=v1.0.0": "git+https://github.com/org/repo.git//bundle.tar.gz.sig?ref=v1.0.0",
+	}
+	for give, want := range tests {
+		assert.Equal(t, want, signatureLocation(give), give)
+	}
+}
+
+func TestLoad_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	err := os.MkdirAll(filepath.Join(repo, "policies"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(repo, "policies", "workflow.yml"), []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1.0.0")
+
+	l := Loader{}
+	got, err := l.Load(context.Background(), "git+file://"+repo+"//policies/workflow.yml?ref=v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, content, string(got))
+}
+
+func TestParseGitSource(t *testing.T) {
+	type testcase struct {
+		source   string
+		wantRepo string
+		wantRef  string
+		wantPath string
+		wantErr  bool
+	}
+
+	testcases := []testcase{
+		{
+			source:   "https://github.com/org/repo.git//workflow.yml?ref=v1.0.0",
+			wantRepo: "https://github.com/org/repo.git",
+			wantRef:  "v1.0.0",
+			wantPath: "workflow.yml",
+		},
+		{
+			source:   "https://github.com/org/repo.git//policies/workflow.yml",
+			wantRepo: "https://github.com/org/repo.git",
+			wantRef:  "HEAD",
+			wantPath: "policies/workflow.yml",
+		},
+		{source: "https://github.com/org/repo.git", wantErr: true},
+		{source: "https://github.com/org/repo.git//workflow.yml?ref=--upload-pack=evil", wantErr: true},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.source, func(t *testing.T) {
+			repo, ref, path, err := parseGitSource(tt.source)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantRepo, repo)
+			assert.Equal(t, tt.wantRef, ref)
+			assert.Equal(t, tt.wantPath, path)
+		})
+	}
+}
+
+func TestS3URL(t *testing.T) {
+	got, err := s3URL("s3://policies/prod/workflow.yml?region=us-west-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "https://policies.s3.us-west-2.amazonaws.com/prod/workflow.yml", got)
+
+	_, err = s3URL("s3://policies")
+	assert.Error(t, err)
+}
+
+func TestFileURLPath(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "file:///policies/workflow.yml", want: filepath.FromSlash("/policies/workflow.yml")},
+		{give: "file:///policies/my%20workflow.yml", want: filepath.FromSlash("/policies/my workflow.yml")},
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests, struct {
+			give string
+			want string
+		}{give: "file:///C:/policies/workflow.yml", want: `C:\policies\workflow.yml`})
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			u, err := url.Parse(tt.give)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, fileURLPath(u))
+		})
+	}
+}
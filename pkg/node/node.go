@@ -1,5 +1,7 @@
 package node
 
+import "strings"
+
 type Type int
 
 const (
@@ -26,6 +28,11 @@ type Node struct {
 	// Name is a friendly display name for the node.
 	// e.g. "Request"
 	Name string
+
+	// Names are translations of the display name, keyed by locale.
+	// e.g. {"fr": "Approuvé", "de": "Genehmigt"}
+	Names map[string]string
+
 	// Priority of the node.
 	// Used in workflow execution to determine
 	// the final workflow outcome if two end nodes
@@ -34,4 +41,46 @@ type Node struct {
 	// worfklow outcome.
 	// Each end node must have a unique priority.
 	Priority int
+
+	// Metadata is arbitrary information about the node,
+	// configured in the dialect.
+	// e.g. {"severity": "high", "sla": "4h"}
+	//
+	// The metadata of the reached outcome is available
+	// on the execution result, so that callers can branch
+	// on more than the outcome ID.
+	Metadata map[string]any
+}
+
+// LocalizedName returns the display name of the node in the locale.
+// See Localize.
+func (n Node) LocalizedName(locale string) string {
+	return Localize(n.Names, locale, n.Name)
+}
+
+// Localize returns the name for the locale from a map of names keyed by locale.
+// If there's no name for the locale, the name for its base language is used,
+// e.g. 'fr' for 'fr-CA', and otherwise the fallback. Locales are matched
+// case-insensitively, and '_' is treated as '-'.
+func Localize(names map[string]string, locale, fallback string) string {
+	if locale == "" || len(names) == 0 {
+		return fallback
+	}
+	for tag := normalizeLocale(locale); tag != ""; {
+		for k, v := range names {
+			if normalizeLocale(k) == tag && v != "" {
+				return v
+			}
+		}
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	return fallback
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
 }
@@ -4,6 +4,7 @@ package noderr
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
@@ -15,25 +16,57 @@ type NodeError struct {
 }
 
 // PrettyPrint the error along with the YAML node.
+//
+// Nodes which were expanded from an alias don't exist in the source,
+// so the closest enclosing node is printed instead, which is where the alias was used.
 func (ne NodeError) PrettyPrint(yml []byte) (string, error) {
-	path, err := yaml.PathString(ne.Node.GetPath())
+	if ne.Node == nil {
+		return "", errors.New("error is not associated with a YAML node")
+	}
+
+	p := ne.Node.GetPath()
+	for {
+		source, err := annotate(yml, p)
+		if err == nil {
+			return source, nil
+		}
+		parent, ok := parentPath(p)
+		if !ok {
+			return "", err
+		}
+		p = parent
+	}
+}
+
+func annotate(yml []byte, p string) (string, error) {
+	path, err := yaml.PathString(p)
 	if err != nil {
 		return "", err
 	}
-	source, err := path.AnnotateSource([]byte(yml), true)
+	source, err := path.AnnotateSource(yml, true)
 	if err != nil {
 		return "", err
 	}
 	return string(source), nil
 }
 
+// parentPath returns the path of the parent of the node at p,
+// e.g. '$.steps[0]' for '$.steps[0].check'.
+func parentPath(p string) (string, bool) {
+	i := strings.LastIndexAny(p, ".[")
+	if i <= 0 {
+		return "", false
+	}
+	return p[:i], true
+}
+
 func (ne NodeError) Error() string {
 	return ne.Err.Error()
 }
 
 func Wrap(err error, node ast.Node) error {
 	var ne NodeError
-	if errors.Is(err, &ne) {
+	if errors.As(err, &ne) {
 		// the error was already wrapped in a child node.
 		return err
 	}
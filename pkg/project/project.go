@@ -0,0 +1,188 @@
+// Package project contains the Glide project manifest, which lists the
+// workflows in a repository along with their input schemas, dialects and
+// tests, so that every workflow can be verified with a single command.
+//
+// The manifest is a YAML file, named glide.yml by default:
+//
+//	dialect: cf
+//	workflows:
+//	  - name: access
+//	    file: access/workflow.yml
+//	    schema: access/schema.json
+//	    tests: [access/tests/*.yml]
+//	  - name: break-glass
+//	    file: break-glass/workflow.yml
+//	    schema: break-glass/schema.json
+//
+// Paths are relative to the directory containing the manifest.
+// Test suites are in the format of the tests file of a bundle.
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/bundle"
+	"github.com/goccy/go-yaml"
+)
+
+// File is the default name of the project manifest.
+const File = "glide.yml"
+
+// Project is a set of workflows which are verified together.
+type Project struct {
+	// Dir is the directory containing the manifest,
+	// which the paths in the manifest are relative to.
+	Dir string `yaml:"-"`
+	// Dialect is the name of the dialect of
+	// workflows which don't set one, e.g. 'cf'.
+	Dialect   string     `yaml:"dialect"`
+	Workflows []Workflow `yaml:"workflows"`
+}
+
+// Workflow is a workflow in a project.
+type Workflow struct {
+	// Name identifies the workflow, e.g. 'access'. Names are unique in a project.
+	Name string `yaml:"name"`
+	// File is the path of the workflow.
+	File string `yaml:"file"`
+	// Schema is the path of the input schema, in JSON schema format.
+	Schema string `yaml:"schema"`
+	// Dialect is the name of the dialect that the workflow is written in.
+	Dialect string `yaml:"dialect"`
+	// Tests are the paths of the workflow's test suites,
+	// which can be glob patterns, e.g. 'tests/*.yml'.
+	Tests []string `yaml:"tests"`
+}
+
+// Parse a project manifest. The dialect of workflows which don't
+// set one is set to the dialect of the project.
+func Parse(data []byte) (*Project, error) {
+	var p Project
+	err := yaml.Unmarshal(data, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.Workflows) == 0 {
+		return nil, errors.New("project must contain at least one workflow")
+	}
+
+	names := map[string]bool{}
+	for i := range p.Workflows {
+		w := &p.Workflows[i]
+		if w.Name == "" {
+			return nil, fmt.Errorf("workflow %d must have a name", i+1)
+		}
+		if names[w.Name] {
+			return nil, fmt.Errorf("there is more than one workflow named %s", w.Name)
+		}
+		names[w.Name] = true
+
+		if w.File == "" {
+			return nil, fmt.Errorf("workflow %s must have a file", w.Name)
+		}
+		if w.Schema == "" {
+			return nil, fmt.Errorf("workflow %s must have a schema", w.Name)
+		}
+		if w.Dialect == "" {
+			w.Dialect = p.Dialect
+		}
+		if w.Dialect == "" {
+			return nil, fmt.Errorf("workflow %s must have a dialect, as the project doesn't set one", w.Name)
+		}
+	}
+
+	return &p, nil
+}
+
+// Load a project manifest from a file. If path is a
+// directory, the glide.yml file in the directory is loaded.
+func Load(path string) (*Project, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, File)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	p.Dir = filepath.Dir(path)
+	return p, nil
+}
+
+// Bundle reads the files of a workflow in the project, so that it
+// can be compiled and its tests run in the same way as a bundle.
+// The bundle is named after the workflow.
+func (p *Project) Bundle(w Workflow) (*bundle.Bundle, error) {
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Name: w.Name, Dialect: w.Dialect},
+	}
+
+	var err error
+	b.Workflow, err = os.ReadFile(p.path(w.File))
+	if err != nil {
+		return nil, err
+	}
+	b.Schema, err = os.ReadFile(p.path(w.Schema))
+	if err != nil {
+		return nil, err
+	}
+
+	suites, err := p.testSuites(w)
+	if err != nil {
+		return nil, err
+	}
+	for _, suite := range suites {
+		data, err := os.ReadFile(suite)
+		if err != nil {
+			return nil, err
+		}
+		tests, err := bundle.ParseTests(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", suite, err)
+		}
+		b.Tests = append(b.Tests, tests...)
+	}
+
+	return &b, nil
+}
+
+// testSuites returns the paths of the test suites of a workflow, in the order
+// of its test patterns. Patterns which don't match any files aren't an error,
+// but paths without wildcards must exist.
+func (p *Project) testSuites(w Workflow) ([]string, error) {
+	var suites []string
+	for _, pattern := range w.Tests {
+		if !strings.ContainsAny(pattern, `*?[\`) {
+			suites = append(suites, p.path(pattern))
+			continue
+		}
+		matches, err := filepath.Glob(p.path(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid test pattern %q for workflow %s: %w", pattern, w.Name, err)
+		}
+		suites = append(suites, matches...)
+	}
+	return suites, nil
+}
+
+// path returns a path in the manifest relative to the project directory.
+func (p *Project) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(p.Dir, name)
+}
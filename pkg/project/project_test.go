@@ -0,0 +1,177 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/bundle"
+	"github.com/common-fate/glide/pkg/dialect/cf"
+	"github.com/stretchr/testify/assert"
+)
+
+const testWorkflow = `
+workflow:
+  on_call:
+    steps:
+      - start: request
+      - check: input.on_call
+      - outcome: approved
+`
+
+const testSchema = `{
+  "type": "object",
+  "properties": {
+    "on_call": {
+      "type": "boolean"
+    }
+  }
+}`
+
+func writeDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(data), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParse(t *testing.T) {
+	type testcase struct {
+		name    string
+		data    string
+		want    *Project
+		wantErr string
+	}
+
+	testcases := []testcase{
+		{
+			name: "ok",
+			data: `
+dialect: cf
+workflows:
+  - name: access
+    file: access/workflow.yml
+    schema: access/schema.json
+    tests: [access/tests/*.yml]
+  - name: other
+    file: other.yml
+    schema: other.json
+    dialect: other
+`,
+			want: &Project{
+				Dialect: "cf",
+				Workflows: []Workflow{
+					{Name: "access", File: "access/workflow.yml", Schema: "access/schema.json", Dialect: "cf", Tests: []string{"access/tests/*.yml"}},
+					{Name: "other", File: "other.yml", Schema: "other.json", Dialect: "other"},
+				},
+			},
+		},
+		{name: "no workflows", data: "dialect: cf\n", wantErr: "project must contain at least one workflow"},
+		{
+			name: "duplicate names",
+			data: `
+dialect: cf
+workflows:
+  - {name: access, file: a.yml, schema: a.json}
+  - {name: access, file: b.yml, schema: b.json}
+`,
+			wantErr: "there is more than one workflow named access",
+		},
+		{
+			name: "no schema",
+			data: `
+dialect: cf
+workflows:
+  - {name: access, file: a.yml}
+`,
+			wantErr: "workflow access must have a schema",
+		},
+		{
+			name: "no dialect",
+			data: `
+workflows:
+  - {name: access, file: a.yml, schema: a.json}
+`,
+			wantErr: "workflow access must have a dialect, as the project doesn't set one",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProject_Bundle(t *testing.T) {
+	dir := writeDir(t, map[string]string{
+		File: `
+dialect: cf
+workflows:
+  - name: access
+    file: access/workflow.yml
+    schema: access/schema.json
+    tests: [access/tests/*.yml]
+  - name: missing
+    file: access/workflow.yml
+    schema: access/schema.json
+    tests: [access/missing.yml]
+`,
+		"access/workflow.yml": testWorkflow,
+		"access/schema.json":  testSchema,
+		"access/tests/a.yml": `
+tests:
+  - name: on call
+    input: {on_call: true}
+    outcome: approved
+`,
+		"access/tests/b.yml": `
+tests:
+  - name: not on call
+    input: {on_call: false}
+    outcome: approved
+`,
+	})
+
+	p, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, dir, p.Dir)
+
+	b, err := p.Bundle(p.Workflows[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, bundle.Manifest{Name: "access", Dialect: "cf"}, b.Manifest)
+	assert.Equal(t, []string{"on call", "not on call"}, []string{b.Tests[0].Name, b.Tests[1].Name})
+
+	// the tests from every suite are run.
+	err = b.Verify(cf.Dialect)
+	var ve *bundle.VerifyError
+	if assert.ErrorAs(t, err, &ve) {
+		assert.Len(t, ve.Failures, 1)
+		assert.Equal(t, "not on call", ve.Failures[0].Test.Name)
+	}
+
+	// test suites without wildcards must exist.
+	_, err = p.Bundle(p.Workflows[1])
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
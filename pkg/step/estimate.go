@@ -0,0 +1,70 @@
+package step
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// estimateUnits are the units of step estimates.
+// Days and weeks are supported in addition to the units
+// of time.ParseDuration, as approvals can take days.
+var estimateUnits = map[string]time.Duration{
+	"w": 7 * 24 * time.Hour,
+	"d": 24 * time.Hour,
+	"h": time.Hour,
+	"m": time.Minute,
+	"s": time.Second,
+}
+
+// estimateRegex matches a component of an estimate, e.g. '2d'.
+var estimateRegex = regexp.MustCompile(`(\d+)([wdhms])`)
+
+// ParseEstimate parses a step estimate, such as '2d' or '1d12h'.
+// The units are 'w' (weeks), 'd' (days), 'h', 'm' and 's'.
+func ParseEstimate(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("must not be empty")
+	}
+
+	var total time.Duration
+	var parsed int
+	for _, m := range estimateRegex.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] != parsed {
+			break
+		}
+		n, err := strconv.Atoi(s[m[2]:m[3]])
+		if err != nil {
+			return 0, err
+		}
+		total += time.Duration(n) * estimateUnits[s[m[4]:m[5]]]
+		parsed = m[1]
+	}
+
+	if parsed != len(s) {
+		return 0, fmt.Errorf("%q must be a duration like '2d' or '1d12h', using the units w, d, h, m and s", s)
+	}
+	return total, nil
+}
+
+// FormatEstimate formats a duration in days, hours, minutes and seconds, e.g. '2d4h'.
+func FormatEstimate(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+
+	var b strings.Builder
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{{"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute}, {"s", time.Second}}
+	for _, u := range units {
+		if n := d / u.unit; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, u.suffix)
+			d -= n * u.unit
+		}
+	}
+	return b.String()
+}
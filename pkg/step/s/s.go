@@ -4,6 +4,8 @@
 package s
 
 import (
+	"time"
+
 	"github.com/common-fate/glide/pkg/node"
 	"github.com/common-fate/glide/pkg/step"
 )
@@ -29,6 +31,12 @@ func Boolean(op step.Operation, children ...step.Step) step.Step {
 	return step.Step{Body: step.Boolean{Op: op}, Children: children}
 }
 
+// Sequence creates a branch of a parallel step, made up of
+// steps which are run one after another.
+func Sequence(steps ...step.Step) step.Step {
+	return step.Step{Body: step.Sequence{}, Children: steps}
+}
+
 func Check(expression string) step.Step {
 	return step.Step{Body: step.Check{Expression: expression}}
 }
@@ -39,7 +47,17 @@ func Action(name string, action any) step.Step {
 
 type StepBuilder struct {
 	Name         string
+	Desc         string
+	StepID       string
 	NodePriority int
+	Fail         step.OnFail
+	StepNeeds    []string
+	Est          time.Duration
+	Remind       time.Duration
+	Escalate     time.Duration
+	Sched        *step.Schedule
+	Translations map[string]string
+	Guard        string
 }
 
 // Named returns a step with a set name.
@@ -51,6 +69,120 @@ func Named(name string) *StepBuilder {
 	return &StepBuilder{Name: name}
 }
 
+// WithID returns a step with a set ID.
+//
+// Usage:
+//
+//	s.WithID("my-id").Check("<expression>")
+func WithID(id string) *StepBuilder {
+	return &StepBuilder{StepID: id}
+}
+
+// Described returns a step with a set description.
+//
+// Usage:
+//
+//	s.Described("<description>").Check("<expression>")
+func Described(description string) *StepBuilder {
+	return &StepBuilder{Desc: description}
+}
+
+// Estimated returns a step with a set estimate.
+//
+// Usage:
+//
+//	s.Estimated(24 * time.Hour).Action("<name>", <action>)
+func Estimated(estimate time.Duration) *StepBuilder {
+	return &StepBuilder{Est: estimate}
+}
+
+// If returns a step which is guarded by a CEL expression.
+//
+// Usage:
+//
+//	s.If("<expression>").Action("<name>", <action>)
+func If(expression string) *StepBuilder {
+	return &StepBuilder{Guard: expression}
+}
+
+// OnFail returns a step with a set failure behaviour.
+//
+// Usage:
+//
+//	s.OnFail(step.Continue).Action("<name>", <action>)
+func OnFail(behavior step.FailBehavior, steps ...step.Step) *StepBuilder {
+	return &StepBuilder{Fail: step.OnFail{Behavior: behavior, Steps: steps}}
+}
+
+// OnFail sets the failure behaviour of the step.
+// This is only applied to Action steps.
+func (sb *StepBuilder) OnFail(behavior step.FailBehavior, steps ...step.Step) *StepBuilder {
+	sb.Fail = step.OnFail{Behavior: behavior, Steps: steps}
+	return sb
+}
+
+// ID sets the ID of the step.
+func (sb *StepBuilder) ID(id string) *StepBuilder {
+	sb.StepID = id
+	return sb
+}
+
+// Description sets the description of the step.
+func (sb *StepBuilder) Description(description string) *StepBuilder {
+	sb.Desc = description
+	return sb
+}
+
+// Needs sets the IDs of the steps which the step needs.
+func (sb *StepBuilder) Needs(ids ...string) *StepBuilder {
+	sb.StepNeeds = ids
+	return sb
+}
+
+// Estimate sets how long the step is expected to take.
+func (sb *StepBuilder) Estimate(estimate time.Duration) *StepBuilder {
+	sb.Est = estimate
+	return sb
+}
+
+// RemindEvery sets how often the approvers of the step should be reminded.
+// This is only applied to Action steps.
+func (sb *StepBuilder) RemindEvery(every time.Duration) *StepBuilder {
+	sb.Remind = every
+	return sb
+}
+
+// EscalateAfter sets how long the step can be pending before it should be escalated.
+// This is only applied to Action steps.
+func (sb *StepBuilder) EscalateAfter(after time.Duration) *StepBuilder {
+	sb.Escalate = after
+	return sb
+}
+
+// When gates the activation of the step to a schedule.
+// It panics if the schedule is invalid.
+func (sb *StepBuilder) When(spec string) *StepBuilder {
+	sched, err := step.ParseSchedule(spec)
+	if err != nil {
+		panic(err)
+	}
+	sb.Sched = sched
+	return sb
+}
+
+// If guards the activation of the step with a CEL expression.
+// This is only applied to Action steps.
+func (sb *StepBuilder) If(expression string) *StepBuilder {
+	sb.Guard = expression
+	return sb
+}
+
+// Names sets the translations of the step's name, keyed by locale.
+func (sb *StepBuilder) Names(names map[string]string) *StepBuilder {
+	sb.Translations = names
+	return sb
+}
+
 // Priority of the step.
 // This is only applied to Outcome steps.
 func (sb *StepBuilder) Priority(priority int) *StepBuilder {
@@ -67,22 +199,22 @@ func (sb StepBuilder) Ref(id string) step.Step {
 
 // Start creates a new node reference to a Start node.
 func (sb StepBuilder) Start(id string) step.Step {
-	return step.Step{Name: sb.Name, Body: step.Ref{Node: node.Node{Type: node.Start, ID: id, Name: sb.Name}}}
+	return step.Step{Name: sb.Name, Names: sb.Translations, Body: step.Ref{Node: node.Node{Type: node.Start, ID: id, Name: sb.Name, Names: sb.Translations}}}
 }
 
 // Outcome creates a new node reference to an End node.
 func (sb StepBuilder) Outcome(id string) step.Step {
-	return step.Step{Name: sb.Name, Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: id, Priority: sb.NodePriority, Name: sb.Name}}}
+	return step.Step{Name: sb.Name, Names: sb.Translations, Body: step.Ref{Node: node.Node{Type: node.Outcome, ID: id, Priority: sb.NodePriority, Name: sb.Name, Names: sb.Translations}}}
 }
 
 func (sb StepBuilder) Boolean(op step.Operation, children ...step.Step) step.Step {
-	return step.Step{Body: step.Boolean{Op: op}, Children: children}
+	return step.Step{ID: sb.StepID, Description: sb.Desc, Needs: sb.StepNeeds, Estimate: sb.Est, When: sb.Sched, Body: step.Boolean{Op: op}, Children: children}
 }
 
 func (sb StepBuilder) Check(expression string) step.Step {
-	return step.Step{Name: sb.Name, Body: step.Check{Expression: expression}}
+	return step.Step{Name: sb.Name, Names: sb.Translations, ID: sb.StepID, Description: sb.Desc, Needs: sb.StepNeeds, Estimate: sb.Est, When: sb.Sched, Body: step.Check{Expression: expression}}
 }
 
 func (sb StepBuilder) Action(name string, action any) step.Step {
-	return step.Step{Name: sb.Name, Body: step.Action{Name: name, Action: action}}
+	return step.Step{Name: sb.Name, Names: sb.Translations, ID: sb.StepID, Description: sb.Desc, Needs: sb.StepNeeds, Estimate: sb.Est, Body: step.Action{Name: name, Action: action}, OnFail: sb.Fail, RemindEvery: sb.Remind, EscalateAfter: sb.Escalate, When: sb.Sched, If: sb.Guard}
 }
@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/interpolate"
 	"github.com/common-fate/glide/pkg/node"
 	"github.com/common-fate/glide/pkg/noderr"
 	"github.com/pkg/errors"
@@ -19,10 +23,13 @@ import (
 type StepType int
 
 const (
-	CheckType   StepType = iota // a 'check'
-	BooleanType                 // an 'and' or an 'or'
-	RefType                     // a reference to a node (e.g. 'request' or 'approve')
-	ActionType                  // an action to execute as part of a workflow
+	CheckType    StepType = iota // a 'check'
+	BooleanType                  // an 'and' or an 'or'
+	RefType                      // a reference to a node (e.g. 'request' or 'approve')
+	ActionType                   // an action to execute as part of a workflow
+	SequenceType                 // a branch of steps in a 'parallel' step
+	ForEachType                  // steps repeated for each element of an input list
+	WaitType                     // a wait before an escalation
 )
 
 type Body interface {
@@ -45,10 +52,62 @@ type Step struct {
 	// Name is the friendly display name of the step.
 	Name string
 
+	// Names are translations of the name, keyed by locale,
+	// e.g. {"fr": "Approbation du responsable"}.
+	Names map[string]string
+
+	// Description documents the step, e.g. why a check exists.
+	// It is shown as a tooltip when the graph is rendered.
+	Description string
+
+	// ID is an optional identifier for the step, set by the workflow author.
+	// If set, the ID is used as the hash of the step in the graph rather than
+	// the position of the step, so that reordering steps doesn't
+	// change their hash.
+	//
+	// IDs must be unique within a pass.
+	ID string
+
+	// Needs are the IDs of other steps in the pass which must be
+	// complete before this step can be complete, in addition to
+	// the step before it.
+	Needs []string
+
+	// Estimate is how long the step is expected to take to complete,
+	// e.g. 2 days for an approval. It is used to analyse the
+	// expected time to reach each outcome.
+	Estimate time.Duration
+
+	// RemindEvery is how often the host application should remind
+	// the approvers of an action while it's pending, e.g. every day.
+	// It can only be set on Action steps.
+	RemindEvery time.Duration
+
+	// EscalateAfter is how long an action can be pending before the
+	// host application should escalate it, e.g. to a manager.
+	// It can only be set on Action steps.
+	EscalateAfter time.Duration
+
 	// Body of the step
 	Body     Body
 	Children []Step
 
+	// OnFail configures what happens if the step fails.
+	// It can only be set on Action steps.
+	OnFail OnFail
+
+	// When gates the activation of the step to a schedule, such as
+	// business hours. Outside of the schedule, the step isn't
+	// activated even if the steps before it are complete.
+	When *Schedule
+
+	// If is a CEL expression which guards the activation of an action,
+	// e.g. 'input.duration > 8'. If it's false when the step before the
+	// action is complete, the action isn't activated. It's compiled with
+	// the action, rather than as a separate check step.
+	// It can only be set on Action steps.
+	If string
+
 	// Node is the underlying YAML Node.
 	// Used to pretty-print errors.
 	Node ast.Node
@@ -67,6 +126,12 @@ func (e *Step) Label() string {
 	return e.Body.String()
 }
 
+// LocalizedLabel returns the label of the step in the locale, falling back
+// to Label if the step has no name for the locale. See node.Localize.
+func (e *Step) LocalizedLabel(locale string) string {
+	return node.Localize(e.Names, locale, e.Label())
+}
+
 func (e *Step) UnmarshalYAML(ctx context.Context, b []byte) error {
 	// the dialect must be defined in the context
 	d, ok := dialect.FromContext(ctx)
@@ -74,6 +139,9 @@ func (e *Step) UnmarshalYAML(ctx context.Context, b []byte) error {
 		return errors.New("glide dialect must be defined in context using glide.Use()")
 	}
 
+	// variables for interpolating names and action properties
+	vars := interpolate.FromContext(ctx)
+
 	// try and parse as a map (for a Check, Start, Action, or Outcome)
 	//
 	// e.g.
@@ -84,6 +152,13 @@ func (e *Step) UnmarshalYAML(ctx context.Context, b []byte) error {
 	var mapNode map[string]ast.Node
 	err := yaml.Unmarshal(b, &mapNode)
 	if err == nil {
+		// decoding a nil ast.Node causes a panic, so
+		// keys without a value are rejected up front.
+		err = checkNilValues(mapNode)
+		if err != nil {
+			return noderr.Wrap(err, e.Node)
+		}
+
 		// the value looks like this:
 		// - foo: B
 		// 'foo' might be 'start'
@@ -116,6 +191,186 @@ func (e *Step) UnmarshalYAML(ctx context.Context, b []byte) error {
 			if err != nil {
 				return errors.Wrap(err, "unmarshalling name")
 			}
+			e.Name, err = vars.String(e.Name)
+			if err != nil {
+				e.setNodePath(nameNode)
+				return noderr.Wrap(err, nameNode)
+			}
+		}
+
+		// the value might look like this:
+		// - name: Manager approval
+		//   names:
+		//     fr: Approbation du responsable
+		//   action: approval
+
+		namesNode, ok := mapNode["names"]
+		if ok {
+			err = yaml.NodeToValue(namesNode, &e.Names)
+			if err != nil {
+				return noderr.Wrap(errors.Wrap(err, "unmarshalling names"), namesNode)
+			}
+			for locale, name := range e.Names {
+				e.Names[locale], err = vars.String(name)
+				if err != nil {
+					e.setNodePath(namesNode)
+					return noderr.Wrap(err, namesNode)
+				}
+			}
+		}
+
+		// the value might look like this:
+		// - description: Contractors need a second approval
+		//   check: input.contractor
+
+		descriptionNode, ok := mapNode["description"]
+		if ok {
+			err = yaml.NodeToValue(descriptionNode, &e.Description)
+			if err != nil {
+				return errors.Wrap(err, "unmarshalling description")
+			}
+			e.Description, err = vars.String(e.Description)
+			if err != nil {
+				e.setNodePath(descriptionNode)
+				return noderr.Wrap(err, descriptionNode)
+			}
+		}
+
+		// try and set the ID of the node
+		// the value might look like this:
+		// - id: my_check
+		//   foo: B
+
+		idNode, ok := mapNode["id"]
+		if ok {
+			err = yaml.NodeToValue(idNode, &e.ID)
+			if err != nil {
+				return noderr.Wrap(errors.Wrap(err, "unmarshalling id"), idNode)
+			}
+			if !idRegex.MatchString(e.ID) {
+				err = fmt.Errorf("invalid step id %q: ids must start with a letter and contain only letters, numbers, '_' and '-'", e.ID)
+				return noderr.Wrap(err, idNode)
+			}
+		}
+
+		// the value might look like this:
+		// - needs: [security_review]
+		//   check: input.approved
+
+		needsNode, ok := mapNode["needs"]
+		if ok {
+			e.setNodePath(needsNode)
+			err = e.parseNeeds(needsNode)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the value might look like this:
+		// - action: approval
+		//   estimate: 2d
+
+		estimateNode, ok := mapNode["estimate"]
+		if ok {
+			e.setNodePath(estimateNode)
+			var estimate string
+			err = yaml.NodeToValue(estimateNode, &estimate)
+			if err == nil {
+				e.Estimate, err = ParseEstimate(estimate)
+			}
+			if err != nil {
+				return noderr.Wrap(fmt.Errorf("invalid estimate: %w", err), estimateNode)
+			}
+		}
+
+		// the value might look like this:
+		// - action: approval
+		//   remind_every: 1d
+		//   escalate_after: 3d
+
+		for _, f := range []struct {
+			key string
+			val *time.Duration
+		}{{"remind_every", &e.RemindEvery}, {"escalate_after", &e.EscalateAfter}} {
+			durationNode, ok := mapNode[f.key]
+			if !ok {
+				continue
+			}
+			e.setNodePath(durationNode)
+			if _, isAction := mapNode["action"]; !isAction {
+				return noderr.Wrap(fmt.Errorf("%s can only be used on action steps", f.key), durationNode)
+			}
+			var duration string
+			err = yaml.NodeToValue(durationNode, &duration)
+			if err == nil {
+				*f.val, err = ParseEstimate(duration)
+			}
+			if err != nil {
+				return noderr.Wrap(fmt.Errorf("invalid %s: %w", f.key, err), durationNode)
+			}
+		}
+
+		escalateNode, hasEscalate := mapNode["escalate"]
+		if hasEscalate {
+			if _, isAction := mapNode["action"]; !isAction {
+				e.setNodePath(escalateNode)
+				return noderr.Wrap(errors.New("escalate can only be used on action steps"), escalateNode)
+			}
+		}
+
+		// the value might look like this:
+		// - action: approval
+		//   on_fail: continue
+
+		onFailNode, ok := mapNode["on_fail"]
+		if ok {
+			e.setNodePath(onFailNode)
+			if _, isAction := mapNode["action"]; !isAction {
+				return noderr.Wrap(errors.New("on_fail can only be used on action steps"), onFailNode)
+			}
+			err = e.parseOnFail(ctx, onFailNode)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the value might look like this:
+		// - action: approval
+		//   when: mon-fri 09:00-17:00 Australia/Sydney
+
+		whenNode, ok := mapNode["when"]
+		if ok {
+			e.setNodePath(whenNode)
+			var spec string
+			err = yaml.NodeToValue(whenNode, &spec)
+			if err == nil {
+				e.When, err = ParseSchedule(spec)
+			}
+			if err != nil {
+				return noderr.Wrap(fmt.Errorf("invalid when: %w", err), whenNode)
+			}
+		}
+
+		// the value might look like this:
+		// - action: approval
+		//   if: input.duration > 8
+
+		ifNode, ok := mapNode["if"]
+		if ok {
+			e.setNodePath(ifNode)
+			if _, isAction := mapNode["action"]; !isAction {
+				return noderr.Wrap(errors.New("if can only be used on action steps"), ifNode)
+			}
+			if hasEscalate {
+				return noderr.Wrap(errors.New("if can't be used on action steps which escalate"), ifNode)
+			}
+			err = yaml.NodeToValue(ifNode, &e.If)
+			if err != nil {
+				return noderr.Wrap(fmt.Errorf("invalid if: %w", err), ifNode)
+			}
+			if e.If == "" {
+				return noderr.Wrap(errors.New("if must be a CEL expression"), ifNode)
+			}
 		}
 
 		// the value looks like this:
@@ -175,6 +430,17 @@ func (e *Step) UnmarshalYAML(ctx context.Context, b []byte) error {
 
 			with, ok := mapNode["with"]
 			if ok {
+				// replace variable references like '${var.channel}'
+				// before decoding the action properties.
+				err = vars.Node(with)
+				var ne noderr.NodeError
+				if errors.As(err, &ne) {
+					e.setNodePath(ne.Node)
+				}
+				if err != nil {
+					return err
+				}
+
 				// unmarshal the YAML onto the action
 				dec := yaml.NewDecoder(&bytes.Buffer{})
 				err = dec.DecodeFromNodeContext(ctx, with, action)
@@ -184,6 +450,22 @@ func (e *Step) UnmarshalYAML(ctx context.Context, b []byte) error {
 			}
 
 			e.Body = Action{Name: actionType, Action: action}
+
+			// the value might look like this:
+			// - action: approval
+			//   escalate:
+			//     after: 3d
+			//     steps:
+			//       - action: approval
+
+			if hasEscalate {
+				e.setNodePath(escalateNode)
+				after, steps, err := e.parseEscalate(ctx, escalateNode)
+				if err != nil {
+					return err
+				}
+				*e = Escalate(*e, after, steps)
+			}
 			return nil
 
 		}
@@ -196,13 +478,69 @@ func (e *Step) UnmarshalYAML(ctx context.Context, b []byte) error {
 	//     - A
 	//     - B
 
-	var m map[string][]ast.Node
-	err = yaml.Unmarshal(b, &m)
 	if err != nil {
-		// if it doesn't decode here, return an error
+		// if it doesn't decode as a map, return an error
 		return noderr.Wrap(err, e.Node)
 	}
 
+	// the value might look like this:
+	// - for_each: input.entitlements
+	//   as: entitlement
+	//   steps:
+	//     - action: approval
+
+	forEachNode, ok := mapNode["for_each"]
+	if ok {
+		for _, key := range []string{"parallel", "and", "or"} {
+			if _, ok := mapNode[key]; ok {
+				return fmt.Errorf("entry cannot have both 'for_each' and '%s' together", key)
+			}
+		}
+		return e.parseForEach(ctx, forEachNode, mapNode)
+	}
+	if asNode, ok := mapNode["as"]; ok {
+		e.setNodePath(asNode)
+		return noderr.Wrap(errors.New("as can only be used on for_each steps"), asNode)
+	}
+
+	// the value might look like this:
+	// - parallel:
+	//     - action: approval
+	//     - steps:
+	//         - check: input.approved
+	//         - action: notify
+	//   join: any
+
+	parallelNode, ok := mapNode["parallel"]
+	if ok {
+		for _, key := range []string{"and", "or"} {
+			if _, ok := mapNode[key]; ok {
+				return fmt.Errorf("entry cannot have both 'parallel' and '%s' together", key)
+			}
+		}
+		return e.parseParallel(ctx, parallelNode, mapNode["join"])
+	}
+	if joinNode, ok := mapNode["join"]; ok {
+		e.setNodePath(joinNode)
+		return noderr.Wrap(errors.New("join can only be used on parallel steps"), joinNode)
+	}
+
+	// the boolean children are parsed from the map so that
+	// other keys like 'name' and 'id' can be set on a Boolean.
+	m := map[string][]ast.Node{}
+	for _, key := range []string{"and", "or"} {
+		n, ok := mapNode[key]
+		if !ok {
+			continue
+		}
+		var children []ast.Node
+		err = yaml.NodeToValue(n, &children)
+		if err != nil {
+			return noderr.Wrap(err, e.Node)
+		}
+		m[key] = children
+	}
+
 	// the value looks like this:
 	// - foo:
 	//    - B
@@ -245,6 +583,350 @@ func (e *Step) UnmarshalYAML(ctx context.Context, b []byte) error {
 	return nil
 }
 
+// parseParallel parses a parallel step. Each branch is either a single step,
+// or a list of steps which are run one after another.
+// the value looks like this:
+//
+//	parallel:
+//	  - action: approval
+//	  - steps:
+//	      - check: input.approved
+//	      - action: notify
+//	join: any
+//
+// The branches are joined with 'and' semantics unless 'join' is 'any'.
+func (e *Step) parseParallel(ctx context.Context, n ast.Node, join ast.Node) error {
+	e.Body = Boolean{Op: And}
+	if join != nil {
+		e.setNodePath(join)
+		var j string
+		err := yaml.NodeToValue(join, &j)
+		if err != nil {
+			return noderr.Wrap(err, join)
+		}
+		switch j {
+		case "all":
+		case "any":
+			e.Body = Boolean{Op: Or}
+		default:
+			err := fmt.Errorf("invalid join %q: must be 'all' or 'any'", j)
+			return noderr.Wrap(err, join)
+		}
+	}
+
+	var branches []ast.Node
+	err := yaml.NodeToValue(n, &branches)
+	if err != nil {
+		return noderr.Wrap(errors.New("parallel must be a list of steps"), n)
+	}
+
+	for _, child := range branches {
+		e.setNodePath(child)
+		childEntry := Step{Node: child, Pass: e.Pass}
+
+		// a branch with a 'steps' field is a sequence of steps,
+		// unless it's a for_each step.
+		var m map[string]ast.Node
+		if yaml.NodeToValue(child, &m) == nil && m["steps"] != nil && m["for_each"] == nil {
+			childEntry.Body = Sequence{}
+
+			var steps []ast.Node
+			err = yaml.NodeToValue(m["steps"], &steps)
+			if err != nil {
+				return noderr.Wrap(err, child)
+			}
+
+			for _, sn := range steps {
+				e.setNodePath(sn)
+				seqEntry := Step{Node: sn, Pass: e.Pass}
+				dec := yaml.NewDecoder(&bytes.Buffer{})
+				err = dec.DecodeFromNodeContext(ctx, sn, &seqEntry)
+				if err != nil {
+					return err
+				}
+				childEntry.Children = append(childEntry.Children, seqEntry)
+			}
+
+			e.Children = append(e.Children, childEntry)
+			continue
+		}
+
+		dec := yaml.NewDecoder(&bytes.Buffer{})
+		err = dec.DecodeFromNodeContext(ctx, child, &childEntry)
+		if err != nil {
+			return err
+		}
+		e.Children = append(e.Children, childEntry)
+	}
+
+	return nil
+}
+
+// parseForEach parses a step which is repeated for each element of an input list.
+// the value looks like this:
+//
+//	for_each: input.entitlements
+//	as: entitlement
+//	steps:
+//	  - action: approval
+//	    with:
+//	      resource: ${each.entitlement}
+//	join: any
+//
+// 'as' is 'value' if it isn't set. The steps are joined with 'and'
+// semantics unless 'join' is 'any'.
+func (e *Step) parseForEach(ctx context.Context, n ast.Node, m map[string]ast.Node) error {
+	e.setNodePath(n)
+	var field string
+	err := yaml.NodeToValue(n, &field)
+	if err != nil || !strings.HasPrefix(field, "input.") || field == "input." {
+		return noderr.Wrap(fmt.Errorf("for_each must be an input field, like 'input.entitlements'"), n)
+	}
+	f := ForEach{Field: strings.TrimPrefix(field, "input."), As: "value"}
+
+	if asNode, ok := m["as"]; ok {
+		e.setNodePath(asNode)
+		err = yaml.NodeToValue(asNode, &f.As)
+		if err != nil {
+			return noderr.Wrap(err, asNode)
+		}
+		if !forEachNameRegex.MatchString(f.As) {
+			err = fmt.Errorf("invalid for_each name %q: names must start with a letter and contain only letters, numbers and '_'", f.As)
+			return noderr.Wrap(err, asNode)
+		}
+	}
+
+	if join, ok := m["join"]; ok {
+		e.setNodePath(join)
+		var j string
+		err := yaml.NodeToValue(join, &j)
+		if err != nil {
+			return noderr.Wrap(err, join)
+		}
+		switch j {
+		case "all":
+		case "any":
+			f.Op = Or
+		default:
+			err := fmt.Errorf("invalid join %q: must be 'all' or 'any'", j)
+			return noderr.Wrap(err, join)
+		}
+	}
+
+	stepsNode, ok := m["steps"]
+	if !ok {
+		return noderr.Wrap(errors.New("for_each must contain a 'steps' field"), n)
+	}
+	e.setNodePath(stepsNode)
+	var steps []ast.Node
+	err = yaml.NodeToValue(stepsNode, &steps)
+	if err != nil || len(steps) == 0 {
+		return noderr.Wrap(errors.New("for_each steps must be a list of steps"), stepsNode)
+	}
+
+	e.Body = f
+
+	for _, sn := range steps {
+		e.setNodePath(sn)
+		child := Step{Node: sn, Pass: e.Pass}
+		dec := yaml.NewDecoder(&bytes.Buffer{})
+		err = dec.DecodeFromNodeContext(ctx, sn, &child)
+		if err != nil {
+			return err
+		}
+		// the steps are repeated, so their IDs wouldn't be unique.
+		if hasID(child) {
+			return noderr.Wrap(errors.New("steps in a for_each can't have an id, as they are repeated for each element"), sn)
+		}
+		e.Children = append(e.Children, child)
+	}
+
+	return nil
+}
+
+// parseEscalate parses the escalation of an action step.
+// the value looks like this:
+//
+//	after: 3d
+//	steps:
+//	  - action: approval
+//	    with:
+//	      groups: [directors]
+func (e *Step) parseEscalate(ctx context.Context, n ast.Node) (time.Duration, []Step, error) {
+	var m map[string]ast.Node
+	err := yaml.NodeToValue(n, &m)
+	if err != nil {
+		return 0, nil, noderr.Wrap(errors.New("escalate must contain 'after' and 'steps' fields"), n)
+	}
+	err = checkNilValues(m)
+	if err != nil {
+		return 0, nil, noderr.Wrap(err, n)
+	}
+
+	afterNode, ok := m["after"]
+	if !ok {
+		return 0, nil, noderr.Wrap(errors.New("escalate must contain an 'after' field"), n)
+	}
+	e.setNodePath(afterNode)
+	var after time.Duration
+	var duration string
+	err = yaml.NodeToValue(afterNode, &duration)
+	if err == nil {
+		after, err = ParseEstimate(duration)
+	}
+	if err != nil {
+		return 0, nil, noderr.Wrap(fmt.Errorf("invalid escalate after: %w", err), afterNode)
+	}
+
+	stepsNode, ok := m["steps"]
+	if !ok {
+		return 0, nil, noderr.Wrap(errors.New("escalate must contain a 'steps' field"), n)
+	}
+	e.setNodePath(stepsNode)
+	var nodes []ast.Node
+	err = yaml.NodeToValue(stepsNode, &nodes)
+	if err != nil || len(nodes) == 0 {
+		return 0, nil, noderr.Wrap(errors.New("escalate steps must be a list of steps"), stepsNode)
+	}
+
+	var steps []Step
+	for _, sn := range nodes {
+		e.setNodePath(sn)
+		child := Step{Node: sn, Pass: e.Pass}
+		dec := yaml.NewDecoder(&bytes.Buffer{})
+		err = dec.DecodeFromNodeContext(ctx, sn, &child)
+		if err != nil {
+			return 0, nil, err
+		}
+		steps = append(steps, child)
+	}
+	return after, steps, nil
+}
+
+// Escalate returns an 'or' step which is complete once the action is complete,
+// or once the escalation steps are complete. The escalation steps follow a
+// Wait, so they are only activated after the action has been pending for the
+// duration of 'after'.
+//
+// The action keeps its ID, so give escalated actions an ID for their hash to be
+// stable: without one, the action is positioned as the first branch of the 'or' step.
+func Escalate(action Step, after time.Duration, steps []Step) Step {
+	wait := Step{Body: Wait{After: after}, Estimate: after, Node: action.Node, Pass: action.Pass}
+	return Step{
+		Body: Boolean{Op: Or},
+		Children: []Step{
+			action,
+			{Body: Sequence{}, Children: append([]Step{wait}, steps...), Node: action.Node, Pass: action.Pass},
+		},
+		Node: action.Node,
+		Pass: action.Pass,
+	}
+}
+
+// hasID returns true if the step or any of its nested steps has an ID.
+func hasID(s Step) bool {
+	if s.ID != "" {
+		return true
+	}
+	for _, c := range append(append([]Step{}, s.Children...), s.OnFail.Steps...) {
+		if hasID(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNeeds parses the IDs of the steps which a step needs.
+// the value looks like this:
+//
+//	needs: [security_review, manager_approval]
+//
+// or like this, for a single step:
+//
+//	needs: security_review
+func (e *Step) parseNeeds(n ast.Node) error {
+	if str, ok := n.(*ast.StringNode); ok {
+		e.Needs = []string{str.Value}
+	} else {
+		err := yaml.NodeToValue(n, &e.Needs)
+		if err != nil {
+			return noderr.Wrap(errors.New("needs must be a step id or a list of step ids"), n)
+		}
+	}
+
+	for _, id := range e.Needs {
+		if !idRegex.MatchString(id) {
+			err := fmt.Errorf("invalid step id %q in needs: ids must start with a letter and contain only letters, numbers, '_' and '-'", id)
+			return noderr.Wrap(err, n)
+		}
+	}
+	return nil
+}
+
+// parseOnFail parses the failure behaviour of a step.
+// the value looks like this:
+//
+//	on_fail: continue
+//
+// or like this, to route to an alternative branch:
+//
+//	on_fail:
+//	  steps:
+//	    - action: escalate
+//	    - outcome: approved
+func (e *Step) parseOnFail(ctx context.Context, n ast.Node) error {
+	if str, ok := n.(*ast.StringNode); ok {
+		switch str.Value {
+		case "fail":
+			e.OnFail = OnFail{Behavior: FailWorkflow}
+		case "continue":
+			e.OnFail = OnFail{Behavior: Continue}
+		default:
+			err := fmt.Errorf("invalid on_fail behavior %q: must be 'fail', 'continue', or a list of 'steps'", str.Value)
+			return noderr.Wrap(err, n)
+		}
+		return nil
+	}
+
+	var m map[string]ast.Node
+	err := yaml.NodeToValue(n, &m)
+	if err != nil {
+		return noderr.Wrap(err, n)
+	}
+
+	stepsNode, ok := m["steps"]
+	if !ok {
+		return noderr.Wrap(errors.New("on_fail must be 'fail', 'continue', or contain a 'steps' field"), n)
+	}
+
+	var steps []ast.Node
+	err = yaml.NodeToValue(stepsNode, &steps)
+	if err != nil {
+		return noderr.Wrap(err, n)
+	}
+
+	e.OnFail = OnFail{Behavior: Route}
+
+	for _, child := range steps {
+		e.setNodePath(child)
+		childEntry := Step{Node: child, Pass: e.Pass}
+
+		// set up a new decoder. Usually we'd provide the bytes to be
+		// read in the buffer, but because we're only using
+		// DecodeFromNodeContext (which doesn't need the buffer)
+		// it can be empty.
+		dec := yaml.NewDecoder(&bytes.Buffer{})
+
+		err = dec.DecodeFromNodeContext(ctx, child, &childEntry)
+		if err != nil {
+			return err
+		}
+		e.OnFail.Steps = append(e.OnFail.Steps, childEntry)
+	}
+
+	return nil
+}
+
 // parseNodeRef parses a fixed node reference from a Glide workflow statement.
 // the value looks like this:
 //   - start: B
@@ -305,6 +987,7 @@ func (e *Step) parseNodeRef(body ast.Node, d dialect.Dialect, nodeType node.Type
 		// to avoid this, we use the name of the node
 		// as specified in the Glide dialect.
 		e.Name = def.Name
+		e.Names = def.Names
 	}
 
 	e.Body = Ref{Node: n}
@@ -326,8 +1009,11 @@ func (e *Step) parseNodeRef(body ast.Node, d dialect.Dialect, nodeType node.Type
 //
 // If there are problems with this method, you can add new tests to errors_test.go
 // in the main package to verify that errors are coming through as expected.
+//
+// The step's own node is only set when the step is unmarshalled as part of a Path,
+// so paths are left unchanged for steps which are unmarshalled directly.
 func (e Step) setNodePath(n ast.Node) {
-	if n != nil {
+	if n != nil && e.Node != nil {
 		existing := n.GetPath()                // "$.and[0].check"
 		toReplace := e.Node.GetPath()          // "$.workflow.default.steps[0].and"
 		parts := strings.Split(toReplace, ".") // [$, workflow, default, steps[0], and]
@@ -340,7 +1026,28 @@ func (e Step) setNodePath(n ast.Node) {
 	}
 }
 
+// checkNilValues returns an error if any of the keys in the map have no value, e.g.
+//
+//   - action:
+func checkNilValues(m map[string]ast.Node) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if m[k] == nil {
+			return fmt.Errorf("%s must have a value", k)
+		}
+	}
+	return nil
+}
+
 func (e Step) Debug() string {
+	if e.If != "" {
+		return fmt.Sprintf("[%s] %s if %s", Hash(e), e.Body.String(), e.If)
+	}
 	return fmt.Sprintf("[%s] %s", Hash(e), e.Body.String())
 }
 
@@ -348,6 +1055,14 @@ func (e Step) Hash() string {
 	return Hash(e)
 }
 
+// forEachNameRegex matches valid for_each names. Names are used as CEL fields,
+// so unlike step IDs they can't contain '-'.
+var forEachNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// idRegex matches valid step IDs.
+// IDs must start with a letter so that they can't collide with positional hashes.
+var idRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
 var Hash = func(s Step) string {
 	// ref nodes always have a fixed hash, regardless of their position in the statements.
 	// This allows us to combine ref nodes across multiple passes together into a single graph.
@@ -355,6 +1070,11 @@ var Hash = func(s Step) string {
 		return n.Node.ID
 	}
 
+	// steps with an explicit ID are hashed by their ID rather than their position.
+	if s.ID != "" {
+		return s.Pass + "." + s.ID
+	}
+
 	// not very efficient - there is most likely a better approach for this!
 	var posString []string
 	for _, p := range s.Position {
@@ -363,6 +1083,39 @@ var Hash = func(s Step) string {
 	return s.Pass + "." + strings.Join(posString, ".")
 }
 
+// FailBehavior is what happens when an action step fails.
+type FailBehavior int
+
+const (
+	// FailWorkflow fails the entire workflow if the action fails.
+	// It is the default behaviour.
+	FailWorkflow FailBehavior = iota
+	// Continue continues the workflow as if the action was complete.
+	Continue
+	// Route continues the workflow through an alternative branch of steps.
+	Route
+)
+
+func (b FailBehavior) String() string {
+	switch b {
+	case Continue:
+		return "continue"
+	case Route:
+		return "route"
+	}
+	return "fail"
+}
+
+// OnFail configures what happens when an action step fails.
+type OnFail struct {
+	Behavior FailBehavior
+
+	// Steps are the alternative branch of steps which are followed
+	// if the action fails. They are only used if Behavior is Route.
+	// The last step must be an outcome.
+	Steps []Step
+}
+
 // Operation are boolean operations
 // to combine workflow steps.
 // They are either AND or OR.
@@ -390,6 +1143,56 @@ func (b Boolean) String() string {
 	}
 }
 
+// Sequence is a branch of a parallel step. Its children are run
+// one after another. Sequences aren't added to the execution graph:
+// the first child follows the step before the parallel step, and the
+// last child links to the parallel step.
+type Sequence struct{}
+
+func (b Sequence) Type() StepType {
+	return SequenceType
+}
+
+func (b Sequence) String() string {
+	return "sequence"
+}
+
+// ForEach is a step whose children are repeated for each element of an input list,
+// when the workflow is executed. The repeated steps are joined like a parallel step,
+// with a branch for each element.
+type ForEach struct {
+	// Field is the dot-separated path of the input list, e.g. 'entitlements'.
+	Field string
+	// As is the name which the steps reference the element by,
+	// as 'each.<as>' in checks and '${each.<as>}' elsewhere.
+	As string
+	// Op is And if every branch must be complete, and Or if any branch must be.
+	Op Operation
+}
+
+func (f ForEach) Type() StepType {
+	return ForEachType
+}
+
+func (f ForEach) String() string {
+	return fmt.Sprintf("for each %s in input.%s", f.As, f.Field)
+}
+
+// Wait is a step which is complete once it has been active for a duration.
+// Waits guard the steps of an escalation, so that they are only activated
+// once the escalated action has been pending for the duration.
+type Wait struct {
+	After time.Duration
+}
+
+func (w Wait) Type() StepType {
+	return WaitType
+}
+
+func (w Wait) String() string {
+	return "wait " + FormatEstimate(w.After)
+}
+
 type Check struct {
 	Expression string
 }
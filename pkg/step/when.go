@@ -0,0 +1,306 @@
+package step
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a window of time which gates the activation of a step,
+// from the 'when' field of the step. Outside of the window, the step
+// isn't activated even if the steps before it are complete.
+//
+// Schedules are written either as business hours, with optional days
+// and a time zone:
+//
+//	when: mon-fri 09:00-17:00 Australia/Sydney
+//
+// or as a cron expression, with the minute, hour, day of the month,
+// month and day of the week fields, and an optional time zone:
+//
+//	when: "* 9-16 * * 1-5 UTC"
+type Schedule struct {
+	// Spec is the schedule as it was written in the workflow.
+	Spec string
+
+	// loc is the time zone the schedule is evaluated in.
+	loc *time.Location
+
+	// business hours: the days of the week, and the minutes
+	// since midnight which the window opens and closes.
+	days       [7]bool
+	start, end int
+
+	// cron is set if the schedule is a cron expression.
+	cron *cronSchedule
+}
+
+// cronSchedule is a cron expression, with the values which match each field.
+type cronSchedule struct {
+	minute, hour, dom, month, dow []bool
+}
+
+// businessHours is the schedule written as 'business hours'.
+const businessHours = "mon-fri 09:00-17:00"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseSchedule parses a schedule, such as 'mon-fri 09:00-17:00 Europe/London',
+// 'business hours', or a cron expression such as '* 9-16 * * 1-5'.
+// Schedules without a time zone are evaluated in UTC.
+func ParseSchedule(spec string) (*Schedule, error) {
+	s := Schedule{Spec: spec, loc: time.UTC}
+
+	fields := strings.Fields(strings.ToLower(spec))
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(spec)), "business hours") {
+		// keep the time zone as it was written, as zone names are case sensitive.
+		fields = append(strings.Fields(businessHours), strings.Fields(spec)[2:]...)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("must not be empty")
+	}
+
+	// the time zone is the last field, if it isn't part of the schedule.
+	last := strings.Fields(spec)[len(strings.Fields(spec))-1]
+	if isTimeZone(last) {
+		loc, err := time.LoadLocation(last)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q", last)
+		}
+		s.loc = loc
+		fields = fields[:len(fields)-1]
+	}
+
+	var err error
+	if len(fields) == 5 {
+		s.cron, err = parseCron(fields)
+	} else {
+		err = s.parseBusinessHours(fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// isTimeZone returns true if the field of a schedule is a time zone,
+// such as 'UTC' or 'Australia/Sydney', rather than days or times.
+func isTimeZone(field string) bool {
+	if strings.Contains(field, "/") && !strings.ContainsAny(field, "0123456789*") {
+		return true
+	}
+	return field == "UTC" || field == "Local"
+}
+
+func (s *Schedule) parseBusinessHours(fields []string) error {
+	var days, hours string
+	switch len(fields) {
+	case 1:
+		days, hours = "sun-sat", fields[0]
+	case 2:
+		days, hours = fields[0], fields[1]
+	default:
+		return fmt.Errorf("invalid schedule: must be days and hours like '%s', or a cron expression", businessHours)
+	}
+
+	for _, part := range strings.Split(days, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return fmt.Errorf("invalid day %q: must be one of mon, tue, wed, thu, fri, sat or sun", from)
+		}
+		last := first
+		if isRange {
+			last, ok = weekdays[to]
+			if !ok {
+				return fmt.Errorf("invalid day %q: must be one of mon, tue, wed, thu, fri, sat or sun", to)
+			}
+		}
+		// ranges can wrap around the end of the week, e.g. 'sat-sun'.
+		for d := first; ; d = (d + 1) % 7 {
+			s.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	from, to, ok := strings.Cut(hours, "-")
+	if !ok {
+		return fmt.Errorf("invalid hours %q: must be a range like 09:00-17:00", hours)
+	}
+	var err error
+	s.start, err = parseClock(from)
+	if err != nil {
+		return err
+	}
+	s.end, err = parseClock(to)
+	if err != nil {
+		return err
+	}
+	if s.end <= s.start {
+		return fmt.Errorf("invalid hours %q: the end must be after the start", hours)
+	}
+	return nil
+}
+
+// parseClock parses a time of day like '09:00' into minutes since midnight.
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		if clock == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("invalid time %q: must be like 09:00", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseCron(fields []string) (*cronSchedule, error) {
+	var c cronSchedule
+	for i, f := range []struct {
+		name     string
+		min, max int
+		values   *[]bool
+	}{
+		{"minute", 0, 59, &c.minute},
+		{"hour", 0, 23, &c.hour},
+		{"day of the month", 1, 31, &c.dom},
+		{"month", 1, 12, &c.month},
+		{"day of the week", 0, 7, &c.dow},
+	} {
+		values, err := parseCronField(fields[i], f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in cron expression: %w", f.name, err)
+		}
+		*f.values = values
+	}
+
+	// 7 is Sunday, as well as 0.
+	if c.dow[7] {
+		c.dow[0] = true
+	}
+	return &c, nil
+}
+
+// parseCronField parses a field of a cron expression, such as '*', '9-17',
+// '1,15' or '*/5', into the values between min and max which it matches.
+func parseCronField(field string, min, max int) ([]bool, error) {
+	values := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		every := 1
+		if hasStep {
+			var err error
+			every, err = strconv.Atoi(step)
+			if err != nil || every < 1 {
+				return nil, fmt.Errorf("invalid step %q", step)
+			}
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			lo, err = strconv.Atoi(from)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", from)
+			}
+			hi = lo
+			if isRange {
+				hi, err = strconv.Atoi(to)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %q", to)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("%q must be between %d and %d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += every {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// Open returns true if the schedule is open at the time t.
+func (s *Schedule) Open(t time.Time) bool {
+	t = t.In(s.loc)
+	if !s.dayOpen(t) {
+		return false
+	}
+	if s.cron != nil {
+		return s.cron.hour[t.Hour()] && s.cron.minute[t.Minute()]
+	}
+	m := t.Hour()*60 + t.Minute()
+	return m >= s.start && m < s.end
+}
+
+// dayOpen returns true if the schedule is open at any time on the day of t.
+func (s *Schedule) dayOpen(t time.Time) bool {
+	if s.cron == nil {
+		return s.days[t.Weekday()]
+	}
+	c := s.cron
+	if !c.month[int(t.Month())] {
+		return false
+	}
+	// as in cron, if both the day of the month and the day of the week
+	// are restricted, the day matches if either of them does.
+	domAll, dowAll := all(c.dom[1:]), all(c.dow[:7])
+	switch {
+	case domAll && dowAll:
+		return true
+	case domAll:
+		return c.dow[t.Weekday()]
+	case dowAll:
+		return c.dom[t.Day()]
+	}
+	return c.dom[t.Day()] || c.dow[t.Weekday()]
+}
+
+func all(values []bool) bool {
+	for _, v := range values {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
+
+// Next returns the first minute at or after t which the schedule is open,
+// or the zero time if it isn't open in the year after t.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc)
+	end := t.AddDate(1, 0, 0)
+	for t.Before(end) {
+		if s.Open(t) {
+			return t
+		}
+		if !s.dayOpen(t) {
+			// skip to the start of the next day.
+			y, m, d := t.Date()
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, s.loc)
+			continue
+		}
+		t = t.Truncate(time.Minute).Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) String() string {
+	return s.Spec
+}
@@ -0,0 +1,77 @@
+package glide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// PreconditionsPass is the pass of the steps in a program's preconditions.
+//
+// The preconditions are compiled once for each start node, so the compiled
+// steps are in the pass 'preconditions.<start>', e.g. 'preconditions.request'.
+const PreconditionsPass = "preconditions"
+
+// validatePreconditions returns an error if any of the preconditions
+// aren't checks, or 'and' and 'or' steps containing checks.
+func validatePreconditions(steps []step.Step) error {
+	for _, s := range steps {
+		switch s.Body.(type) {
+		case step.Check:
+		case step.Boolean:
+			err := validatePreconditions(s.Children)
+			if err != nil {
+				return err
+			}
+		default:
+			return noderr.Wrap(fmt.Errorf("preconditions must be checks, or 'and' and 'or' steps containing checks: got %s", s.Body), s.Node)
+		}
+	}
+	return nil
+}
+
+// compilePreconditions adds the preconditions to the graph after the start node,
+// and returns the last precondition, which the rest of the pass follows.
+//
+// The preconditions are shared by every pass with the same start node,
+// so they are only added to the graph the first time the start node is visited.
+func compilePreconditions(opts compilePassOpts, start *step.Step) (*step.Step, error) {
+	g := opts.G
+	if last, ok := g.preconditions[start.Hash()]; ok {
+		return last, nil
+	}
+
+	pass := PreconditionsPass + "." + start.Hash()
+	steps := make([]step.Step, len(opts.Preconditions))
+	for i, s := range opts.Preconditions {
+		steps[i] = setPass(copyStep(s), pass)
+	}
+
+	prev := start
+	for i := range steps {
+		s := steps[i]
+		err := visitStatement(&VisitOpts{
+			Statement:     &s,
+			G:             g,
+			Previous:      prev,
+			Index:         i,
+			Env:           opts.Env,
+			MaxDepth:      opts.MaxDepth,
+			NumStatements: len(steps),
+		})
+		if err != nil {
+			return nil, noderr.Wrap(err, s.Node)
+		}
+		prev = &s
+	}
+
+	g.preconditions[start.Hash()] = prev
+	return prev, nil
+}
+
+// isPrecondition returns true if the step was compiled from the program's preconditions.
+func isPrecondition(s step.Step) bool {
+	return strings.HasPrefix(s.Pass, PreconditionsPass+".")
+}
@@ -1,64 +1,54 @@
 package glide
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/common-fate/glide/pkg/dialect"
 	"github.com/common-fate/glide/pkg/step"
-	"github.com/goccy/go-yaml"
-	"github.com/goccy/go-yaml/ast"
 	"github.com/pkg/errors"
 )
 
 // Program is a Glide workflow definition.
 type Program struct {
 	Workflow map[string]Path
+
+	// Preconditions are checks which must all pass before any pass
+	// is evaluated, set with the top-level 'preconditions' field.
+	// They are compiled after each start node, before the first step of each pass.
+	Preconditions []step.Step
+
+	// Version is the optional version of the workflow, set with
+	// the top-level 'version' field. Checks can read it as 'workflow.version'.
+	Version string
+
+	// DefaultOutcome is the ID of the outcome which is the result of the
+	// workflow when no other outcome is reached, set with the top-level
+	// 'default_outcome' field. If empty, the dialect's default outcome is used.
+	DefaultOutcome string
+
+	// Dialect is the Glide dialect that the program was written in.
+	// It is set when the program is unmarshalled.
+	Dialect *dialect.Dialect
 }
 
 func (p *Program) UnmarshalYAML(ctx context.Context, b []byte) error {
 	// validate the dialect
-	_, ok := dialect.FromContext(ctx)
+	d, ok := dialect.FromContext(ctx)
 	if !ok {
 		return errors.New("glide dialect must be defined in context using glide.Use()")
 	}
 
-	if p.Workflow == nil {
-		p.Workflow = map[string]Path{}
-	}
-
-	var tmp struct {
-		Workflow map[string]ast.Node `yaml:"workflow"`
-	}
-
-	err := yaml.Unmarshal(b, &tmp)
+	tree, err := parseTree(b)
 	if err != nil {
 		return err
 	}
 
-	for id, node := range tmp.Workflow {
-		if node == nil {
-			continue
-		}
-
-		pass := Path{id: id}
-
-		// set up a new decoder. Usually we'd provide the bytes to be
-		// read in the buffer, but because we're only using
-		// DecodeFromNodeContext (which doesn't need the buffer)
-		// it can be empty.
-		dec := yaml.NewDecoder(&bytes.Buffer{})
-
-		err = dec.DecodeFromNodeContext(ctx, node, &pass)
-		if err != nil {
-			return err
-		}
-
-		p.Workflow[id] = pass
+	prog, err := resolveTree(ctx, d, tree)
+	if err != nil {
+		return err
 	}
-
+	*p = *prog
 	return nil
 }
 
@@ -68,6 +58,9 @@ func (p *Program) UnmarshalYAML(ctx context.Context, b []byte) error {
 type Path struct {
 	id    string
 	Steps []step.Step
+	// Matrix expands the pass into one pass for each combination
+	// of its values, set with the 'matrix' field. See Matrix.
+	Matrix Matrix
 	// Node  ast.Node
 }
 
@@ -82,56 +75,15 @@ func (p *Path) UnmarshalYAML(ctx context.Context, b []byte) error {
 		return err
 	}
 
-	// the YAML structure looks like this
-	//
-	// workflow:			<- program
-	//   default:			<- path
-	//    steps:
-	//      - start: A		<- step
-	//      - outcome: B	<- step
-	//
-
-	// parse the 'steps' field of the path.
-	var nodeMap map[string]ast.Node
-	err = yaml.Unmarshal(b, &nodeMap)
-	if err != nil {
-		return errors.Wrapf(err, "path %s must contain a 'steps' field", p.id)
-	}
-
-	node, ok := nodeMap["steps"]
-	if !ok {
-		return fmt.Errorf("path %s must contain a 'steps' field", p.id)
-	}
-
-	// a Path should contain an array of Steps
-	var steps []ast.Node
-
-	err = yaml.NodeToValue(node, &steps)
+	pass := TreePass{id: p.id}
+	err = pass.UnmarshalYAML(b)
 	if err != nil {
 		return err
 	}
 
-	for _, n := range steps {
-		fullPath := strings.Replace(n.GetPath(), "$", "$.workflow."+p.id, 1)
-		n.SetPath(fullPath)
-
-		s := step.Step{Pass: p.id, Node: n}
-
-		// set up a new decoder. Usually we'd provide the bytes to be
-		// read in the buffer, but because we're only using
-		// DecodeFromNodeContext (which doesn't need the buffer)
-		// it can be empty.
-		dec := yaml.NewDecoder(&bytes.Buffer{})
-
-		err = dec.DecodeFromNodeContext(ctx, n, &s)
-		if err != nil {
-			return err
-		}
-
-		p.Steps = append(p.Steps, s)
-	}
-
-	return nil
+	p.Matrix = pass.Matrix
+	p.Steps, err = resolveSteps(ctx, p.id, pass.Steps)
+	return err
 }
 
 // SimpleProgram creates a program with one 'default' pass only.
@@ -163,5 +115,46 @@ func setPass(s step.Step, pass string) step.Step {
 	for i, child := range s.Children {
 		s.Children[i] = setPass(child, pass)
 	}
+	for i, child := range s.OnFail.Steps {
+		s.OnFail.Steps[i] = setPass(child, pass)
+	}
 	return s
 }
+
+// copyStep copies a step and its nested steps, so that the copy
+// can be modified without changing the original.
+func copyStep(s step.Step) step.Step {
+	s.Children = copySteps(s.Children)
+	s.OnFail.Steps = copySteps(s.OnFail.Steps)
+	return s
+}
+
+func copySteps(steps []step.Step) []step.Step {
+	if steps == nil {
+		return nil
+	}
+	out := make([]step.Step, len(steps))
+	for i, s := range steps {
+		out[i] = copyStep(s)
+	}
+	return out
+}
+
+// selectPasses returns a copy of the program with only the passes
+// with the names, or the program itself if no names are provided.
+func selectPasses(p *Program, names []string) (*Program, error) {
+	if len(names) == 0 {
+		return p, nil
+	}
+
+	out := *p
+	out.Workflow = map[string]Path{}
+	for _, name := range names {
+		path, ok := p.Workflow[name]
+		if !ok {
+			return nil, fmt.Errorf("pass %q not found in the workflow", name)
+		}
+		out.Workflow[name] = path
+	}
+	return &out, nil
+}
@@ -0,0 +1,398 @@
+package glide
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+	glidev1 "github.com/common-fate/glide/proto/glide/v1"
+	"github.com/goccy/go-yaml"
+	"google.golang.org/protobuf/proto"
+)
+
+// ToProto encodes a program as a glide.v1.Workflow protocol buffer, defined
+// in proto/glide/v1/workflow.proto, for compact storage and for services
+// written in other languages. If the graph the program was compiled to is
+// provided, its vertices and edges are encoded too.
+//
+// The 'with' properties of actions are encoded as JSON,
+// using the 'yaml' tags of the action's fields.
+func ToProto(p *Program, g *Graph) ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("a program must be provided")
+	}
+
+	prog, err := programToProto(p)
+	if err != nil {
+		return nil, err
+	}
+	w := &glidev1.Workflow{Program: prog}
+
+	if g != nil {
+		w.Graph, err = graphToProto(g)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// map fields are sorted by key, so that the same
+	// program is always encoded to the same bytes.
+	return proto.MarshalOptions{Deterministic: true}.Marshal(w)
+}
+
+// FromProto decodes a program encoded with ToProto. Start and outcome nodes
+// and actions are looked up in the dialect, which the program's Dialect is set to.
+//
+// The graph isn't decoded, as the CEL programs of checks can't be encoded:
+// compile the program again to execute it.
+func FromProto(data []byte, d dialect.Dialect) (*Program, error) {
+	var w glidev1.Workflow
+	err := proto.Unmarshal(data, &w)
+	if err != nil {
+		return nil, fmt.Errorf("decoding workflow: %w", err)
+	}
+
+	p := NewProgram()
+	p.Dialect = &d
+	err = programFromProto(w.GetProgram(), p)
+	if err != nil {
+		return nil, fmt.Errorf("decoding workflow: %w", err)
+	}
+	return p, nil
+}
+
+// ToProto encodes the program and the compiled graph. See ToProto.
+func (c *Compiled) ToProto(p *Program) ([]byte, error) {
+	return ToProto(p, c.g)
+}
+
+func programToProto(p *Program) (*glidev1.Program, error) {
+	m := &glidev1.Program{
+		Version:        p.Version,
+		DefaultOutcome: p.DefaultOutcome,
+	}
+
+	names := make([]string, 0, len(p.Workflow))
+	for name := range p.Workflow {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pass, err := passToProto(name, p.Workflow[name], p.Dialect)
+		if err != nil {
+			return nil, err
+		}
+		m.Passes = append(m.Passes, pass)
+	}
+
+	for _, s := range p.Preconditions {
+		pre, err := stepToProto(s, p.Dialect)
+		if err != nil {
+			return nil, err
+		}
+		m.Preconditions = append(m.Preconditions, pre)
+	}
+	return m, nil
+}
+
+func passToProto(name string, pass Path, d *dialect.Dialect) (*glidev1.Pass, error) {
+	m := &glidev1.Pass{Name: name}
+	for _, s := range pass.Steps {
+		ps, err := stepToProto(s, d)
+		if err != nil {
+			return nil, fmt.Errorf("pass %s: %w", name, err)
+		}
+		m.Steps = append(m.Steps, ps)
+	}
+	for _, v := range pass.Matrix {
+		mv := &glidev1.MatrixVar{Name: v.Name, Field: v.Field}
+		if v.Values != nil {
+			values, err := json.Marshal(v.Values)
+			if err != nil {
+				return nil, fmt.Errorf("pass %s: encoding matrix %s: %w", name, v.Name, err)
+			}
+			mv.ValuesJson = values
+		}
+		m.Matrix = append(m.Matrix, mv)
+	}
+	return m, nil
+}
+
+func stepToProto(s step.Step, d *dialect.Dialect) (*glidev1.Step, error) {
+	m := &glidev1.Step{
+		Id:                 s.ID,
+		Name:               s.Name,
+		Names:              s.Names,
+		Description:        s.Description,
+		Needs:              s.Needs,
+		EstimateNanos:      int64(s.Estimate),
+		RemindEveryNanos:   int64(s.RemindEvery),
+		EscalateAfterNanos: int64(s.EscalateAfter),
+		If:                 s.If,
+		OnFail:             glidev1.FailBehavior(s.OnFail.Behavior),
+		Pass:               s.Pass,
+	}
+
+	switch b := s.Body.(type) {
+	case step.Check:
+		m.Type = glidev1.StepType_STEP_TYPE_CHECK
+		m.Expression = b.Expression
+	case step.Boolean:
+		m.Type = glidev1.StepType_STEP_TYPE_BOOLEAN
+		m.Op = glidev1.Operation(b.Op)
+	case step.Ref:
+		if b.Node.Type == node.Start {
+			m.Type = glidev1.StepType_STEP_TYPE_START
+		} else {
+			m.Type = glidev1.StepType_STEP_TYPE_OUTCOME
+		}
+		m.Node = b.Node.ID
+	case step.Action:
+		m.Type = glidev1.StepType_STEP_TYPE_ACTION
+		m.Action = b.Name
+		with, err := actionWith(b, d)
+		if err != nil {
+			return nil, fmt.Errorf("encoding action %s: %w", b.Name, err)
+		}
+		m.WithJson = with
+	case step.Sequence:
+		m.Type = glidev1.StepType_STEP_TYPE_SEQUENCE
+	case step.ForEach:
+		m.Type = glidev1.StepType_STEP_TYPE_FOR_EACH
+		m.Op = glidev1.Operation(b.Op)
+		m.ForEachField = b.Field
+		m.ForEachAs = b.As
+	case step.Wait:
+		m.Type = glidev1.StepType_STEP_TYPE_WAIT
+		m.WaitNanos = int64(b.After)
+	default:
+		return nil, fmt.Errorf("step %s has an unsupported body %T", s.Hash(), s.Body)
+	}
+
+	if s.When != nil {
+		m.When = s.When.Spec
+	}
+
+	for _, c := range s.Children {
+		pc, err := stepToProto(c, d)
+		if err != nil {
+			return nil, err
+		}
+		m.Children = append(m.Children, pc)
+	}
+	for _, c := range s.OnFail.Steps {
+		pc, err := stepToProto(c, d)
+		if err != nil {
+			return nil, err
+		}
+		m.OnFailSteps = append(m.OnFailSteps, pc)
+	}
+
+	for _, p := range s.Position {
+		m.Position = append(m.Position, int32(p))
+	}
+	return m, nil
+}
+
+// actionWith returns the 'with' properties of an action as JSON. It returns
+// nil if the action has the same properties as a new action from the dialect,
+// as decoding empty properties may fail for actions with required properties.
+func actionWith(a step.Action, d *dialect.Dialect) ([]byte, error) {
+	with, err := yaml.Marshal(a.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	if d != nil && d.Actions != nil {
+		if def, ok := d.Actions()[a.Name]; ok && def != nil {
+			defWith, err := yaml.Marshal(def)
+			if err == nil && bytes.Equal(with, defWith) {
+				return nil, nil
+			}
+		}
+	}
+
+	return yaml.YAMLToJSON(with)
+}
+
+func graphToProto(g *Graph) (*glidev1.Graph, error) {
+	m := &glidev1.Graph{}
+
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hashes {
+		s, err := g.store.step(h)
+		if err != nil {
+			return nil, err
+		}
+		// the children of a step are vertices in the graph.
+		s.Children = nil
+		s.OnFail.Steps = nil
+
+		ps, err := stepToProto(s, g.dialect)
+		if err != nil {
+			return nil, err
+		}
+		m.Vertices = append(m.Vertices, &glidev1.Vertex{Hash: h, Step: ps})
+	}
+
+	edges, err := g.store.edges()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range edges {
+		m.Edges = append(m.Edges, &glidev1.Edge{
+			Source:     e.Source,
+			Target:     e.Target,
+			Attributes: e.Attributes,
+		})
+	}
+	return m, nil
+}
+
+func programFromProto(m *glidev1.Program, p *Program) error {
+	p.Version = m.GetVersion()
+	p.DefaultOutcome = m.GetDefaultOutcome()
+
+	for _, pm := range m.GetPasses() {
+		pass, err := passFromProto(pm, p.Dialect)
+		if err != nil {
+			return err
+		}
+		p.Workflow[pm.GetName()] = pass
+	}
+	for _, pre := range m.GetPreconditions() {
+		s, err := stepFromProto(pre, p.Dialect)
+		if err != nil {
+			return err
+		}
+		p.Preconditions = append(p.Preconditions, s)
+	}
+	return nil
+}
+
+func passFromProto(m *glidev1.Pass, d *dialect.Dialect) (Path, error) {
+	pass := Path{id: m.GetName()}
+	for _, sm := range m.GetSteps() {
+		s, err := stepFromProto(sm, d)
+		if err != nil {
+			return Path{}, fmt.Errorf("pass %s: %w", m.GetName(), err)
+		}
+		pass.Steps = append(pass.Steps, s)
+	}
+	for _, mv := range m.GetMatrix() {
+		v := MatrixVar{Name: mv.GetName(), Field: mv.GetField()}
+		if mv.GetValuesJson() != nil {
+			err := json.Unmarshal(mv.GetValuesJson(), &v.Values)
+			if err != nil {
+				return Path{}, fmt.Errorf("pass %s: %w", m.GetName(), err)
+			}
+		}
+		pass.Matrix = append(pass.Matrix, v)
+	}
+	return pass, nil
+}
+
+func stepFromProto(m *glidev1.Step, d *dialect.Dialect) (step.Step, error) {
+	s := step.Step{
+		ID:            m.GetId(),
+		Name:          m.GetName(),
+		Names:         m.GetNames(),
+		Description:   m.GetDescription(),
+		Needs:         m.GetNeeds(),
+		Estimate:      time.Duration(m.GetEstimateNanos()),
+		RemindEvery:   time.Duration(m.GetRemindEveryNanos()),
+		EscalateAfter: time.Duration(m.GetEscalateAfterNanos()),
+		If:            m.GetIf(),
+		Pass:          m.GetPass(),
+	}
+	s.OnFail.Behavior = step.FailBehavior(m.GetOnFail())
+
+	if m.GetWhen() != "" {
+		schedule, err := step.ParseSchedule(m.GetWhen())
+		if err != nil {
+			return step.Step{}, err
+		}
+		s.When = schedule
+	}
+
+	for _, cm := range m.GetChildren() {
+		c, err := stepFromProto(cm, d)
+		if err != nil {
+			return step.Step{}, err
+		}
+		s.Children = append(s.Children, c)
+	}
+	for _, cm := range m.GetOnFailSteps() {
+		c, err := stepFromProto(cm, d)
+		if err != nil {
+			return step.Step{}, err
+		}
+		s.OnFail.Steps = append(s.OnFail.Steps, c)
+	}
+	for _, p := range m.GetPosition() {
+		s.Position = append(s.Position, int(p))
+	}
+
+	switch m.GetType() {
+	case glidev1.StepType_STEP_TYPE_CHECK:
+		s.Body = step.Check{Expression: m.GetExpression()}
+	case glidev1.StepType_STEP_TYPE_BOOLEAN:
+		s.Body = step.Boolean{Op: step.Operation(m.GetOp())}
+	case glidev1.StepType_STEP_TYPE_START, glidev1.StepType_STEP_TYPE_OUTCOME:
+		nodeType := node.Start
+		if m.GetType() == glidev1.StepType_STEP_TYPE_OUTCOME {
+			nodeType = node.Outcome
+		}
+		ref := m.GetNode()
+		n, ok := d.Nodes[ref]
+		if !ok || n.Type != nodeType {
+			return step.Step{}, fmt.Errorf("%s is not a %s node in the dialect", ref, nodeType)
+		}
+		n.ID = ref
+		s.Body = step.Ref{Node: n}
+	case glidev1.StepType_STEP_TYPE_ACTION:
+		a, err := decodeAction(m.GetAction(), m.GetWithJson(), d)
+		if err != nil {
+			return step.Step{}, err
+		}
+		s.Body = step.Action{Name: m.GetAction(), Action: a}
+	case glidev1.StepType_STEP_TYPE_SEQUENCE:
+		s.Body = step.Sequence{}
+	case glidev1.StepType_STEP_TYPE_FOR_EACH:
+		s.Body = step.ForEach{Field: m.GetForEachField(), As: m.GetForEachAs(), Op: step.Operation(m.GetOp())}
+	case glidev1.StepType_STEP_TYPE_WAIT:
+		s.Body = step.Wait{After: time.Duration(m.GetWaitNanos())}
+	default:
+		return step.Step{}, fmt.Errorf("step %s has an unknown type %d", s.Hash(), m.GetType())
+	}
+	return s, nil
+}
+
+// decodeAction creates an action from the dialect,
+// and decodes its 'with' properties onto it.
+func decodeAction(name string, with []byte, d *dialect.Dialect) (any, error) {
+	if d.Actions == nil {
+		return nil, fmt.Errorf("unknown action type %s", name)
+	}
+	a, ok := d.Actions()[name]
+	if !ok || a == nil {
+		return nil, fmt.Errorf("unknown action type %s", name)
+	}
+	if with != nil {
+		err := yaml.Unmarshal(with, a)
+		if err != nil {
+			return nil, fmt.Errorf("decoding action %s: %w", name, err)
+		}
+	}
+	return a, nil
+}
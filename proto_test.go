@@ -0,0 +1,202 @@
+package glide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	glidev1 "github.com/common-fate/glide/proto/glide/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+const protoWorkflow = `
+version: "3"
+preconditions:
+  - check: input.active
+workflow:
+  default:
+    steps:
+      - start: request
+      - or:
+          - name: Admins
+            names:
+              fr: Administrateurs
+            check: input.group == "admins"
+          - id: approval
+            action: my_action
+            with:
+              property: security
+            if: input.duration > 8
+            estimate: 2d
+            when: mon-fri 09:00-17:00
+      - outcome: approved
+  deploy:
+    matrix:
+      env: [dev, prod]
+    steps:
+      - start: request
+      - check: matrix.env == input.env
+      - action: my_action
+      - outcome: approved
+`
+
+var protoSchema = &jsoncel.Schema{
+	Type: jsoncel.Object,
+	Properties: map[string]*jsoncel.Schema{
+		"active":   {Type: jsoncel.Boolean},
+		"group":    {Type: jsoncel.String},
+		"duration": {Type: jsoncel.Integer},
+		"env":      {Type: jsoncel.String},
+	},
+}
+
+func TestProto_RoundTrip(t *testing.T) {
+	p, err := Unmarshal([]byte(protoWorkflow), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ToProto(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FromProto(data, yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "3", got.Version)
+	assert.Equal(t, Matrix{{Name: "env", Values: []any{"dev", "prod"}}}, got.Workflow["deploy"].Matrix)
+
+	or := got.Workflow["default"].Steps[1]
+	assert.Equal(t, step.Boolean{Op: step.Or}, or.Body)
+	assert.Equal(t, map[string]string{"fr": "Administrateurs"}, or.Children[0].Names)
+
+	approval := or.Children[1]
+	assert.Equal(t, step.Action{Name: "my_action", Action: &testAction{Property: "security"}}, approval.Body)
+	assert.Equal(t, "input.duration > 8", approval.If)
+	assert.Equal(t, 48*time.Hour, approval.Estimate)
+	assert.Equal(t, "mon-fri 09:00-17:00", approval.When.Spec)
+
+	start := got.Workflow["default"].Steps[0]
+	assert.Equal(t, step.Ref{Node: node.Node{ID: "request", Type: node.Start}}, start.Body)
+
+	// encoding the decoded program gives the same bytes.
+	again, err := ToProto(got, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, data, again)
+
+	// the decoded program compiles to the same graph.
+	want, err := (&Compiler{Program: p, InputSchema: protoSchema}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := (&Compiler{Program: got, InputSchema: protoSchema}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := want.ExportJSON(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotJSON, err := g.ExportJSON(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+}
+
+func TestProto_Graph(t *testing.T) {
+	p := SimpleProgram(s.Start("request"), s.Check("input.active"), s.Outcome("approved"))
+	g, err := (&Compiler{Program: p, InputSchema: protoSchema}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := g.Freeze().ToProto(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var w glidev1.Workflow
+	err = proto.Unmarshal(data, &w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hashes, edges []string
+	for _, v := range w.GetGraph().GetVertices() {
+		hashes = append(hashes, v.GetHash())
+	}
+	for _, e := range w.GetGraph().GetEdges() {
+		edges = append(edges, e.GetSource()+"->"+e.GetTarget())
+	}
+	assert.Equal(t, []string{"approved", "default.1", "request"}, hashes)
+	assert.Equal(t, []string{"default.1->approved", "request->default.1"}, edges)
+
+	// the program can still be decoded.
+	got, err := FromProto(data, yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, got.Workflow["default"].Steps, 3)
+}
+
+func TestFromProto_Errors(t *testing.T) {
+	p, err := Unmarshal([]byte(protoWorkflow), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ToProto(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := dialect.Dialect{Nodes: yamlTestDialect.Nodes}
+	_, err = FromProto(data, other)
+	assert.ErrorContains(t, err, "unknown action type my_action")
+
+	_, err = FromProto(data[:len(data)-3], yamlTestDialect)
+	assert.Error(t, err)
+}
+
+func TestFromProto_UnpackedPosition(t *testing.T) {
+	// proto2 encoders write repeated scalars unpacked, with a tag for each element.
+	var st []byte
+	st = protowire.AppendTag(st, 1, protowire.VarintType)
+	st = protowire.AppendVarint(st, uint64(glidev1.StepType_STEP_TYPE_START))
+	st = protowire.AppendTag(st, 12, protowire.BytesType)
+	st = protowire.AppendString(st, "request")
+	for _, p := range []uint64{2, 5} {
+		st = protowire.AppendTag(st, 23, protowire.VarintType)
+		st = protowire.AppendVarint(st, p)
+	}
+
+	var pass []byte
+	pass = protowire.AppendTag(pass, 1, protowire.BytesType)
+	pass = protowire.AppendString(pass, "default")
+	pass = protowire.AppendTag(pass, 2, protowire.BytesType)
+	pass = protowire.AppendBytes(pass, st)
+
+	var prog []byte
+	prog = protowire.AppendTag(prog, 3, protowire.BytesType)
+	prog = protowire.AppendBytes(prog, pass)
+
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.BytesType)
+	data = protowire.AppendBytes(data, prog)
+
+	got, err := FromProto(data, yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []int{2, 5}, got.Workflow["default"].Steps[0].Position)
+}
@@ -0,0 +1,126 @@
+package glide
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// dialectFunctions declares the custom functions defined in a dialect
+// in the CEL environment, sorted by name.
+func dialectFunctions(d *dialect.Dialect) []cel.EnvOption {
+	var names []string
+	for name := range d.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var opts []cel.EnvOption
+	for _, name := range names {
+		opts = append(opts, cel.Function(name, d.Functions[name].Overloads...))
+	}
+	return opts
+}
+
+// impureChecks returns a diagnostic for each check which calls an impure
+// dialect function that isn't in the allowlist, ordered by the position
+// of the checks in the graph.
+func (g *Graph) impureChecks(allow []string) ([]Diagnostic, error) {
+	impure := g.impureFunctions()
+	for _, name := range allow {
+		impure[name] = false
+	}
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+	for _, k := range order {
+		a, ok := g.asts[k]
+		if !ok {
+			continue
+		}
+		calls := impureCalls(a.Expr(), impure)
+		if len(calls) == 0 {
+			continue
+		}
+		s, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+		diags = append(diags, Diagnostic{
+			Step:    k,
+			Message: fmt.Sprintf("check %q calls impure functions (%s), so its result may not be a pure function of the input", s.Body.(step.Check).Expression, strings.Join(calls, ", ")),
+			Node:    s.Node,
+		})
+	}
+	return diags, nil
+}
+
+// impureFunctions returns the names of the impure functions in the dialect.
+func (g *Graph) impureFunctions() map[string]bool {
+	impure := map[string]bool{}
+	if g.dialect == nil {
+		return impure
+	}
+	for name, f := range g.dialect.Functions {
+		impure[name] = f.Impure
+	}
+	return impure
+}
+
+// impureCalls returns the names of the impure functions called in an expression,
+// sorted and unique.
+func impureCalls(e *exprpb.Expr, impure map[string]bool) []string {
+	found := map[string]bool{}
+	collectCalls(e, found)
+
+	var calls []string
+	for name := range found {
+		if impure[name] {
+			calls = append(calls, name)
+		}
+	}
+	sort.Strings(calls)
+	return calls
+}
+
+func collectCalls(e *exprpb.Expr, found map[string]bool) {
+	if e == nil {
+		return
+	}
+
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		collectCalls(k.SelectExpr.GetOperand(), found)
+	case *exprpb.Expr_CallExpr:
+		found[k.CallExpr.GetFunction()] = true
+		collectCalls(k.CallExpr.GetTarget(), found)
+		for _, a := range k.CallExpr.GetArgs() {
+			collectCalls(a, found)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, el := range k.ListExpr.GetElements() {
+			collectCalls(el, found)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			collectCalls(entry.GetMapKey(), found)
+			collectCalls(entry.GetValue(), found)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		c := k.ComprehensionExpr
+		collectCalls(c.GetIterRange(), found)
+		collectCalls(c.GetAccuInit(), found)
+		collectCalls(c.GetLoopCondition(), found)
+		collectCalls(c.GetLoopStep(), found)
+		collectCalls(c.GetResult(), found)
+	}
+}
@@ -0,0 +1,96 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_ImpureFunctions(t *testing.T) {
+	d := &dialect.Dialect{
+		Functions: map[string]dialect.Function{
+			"hour": {
+				Overloads: []cel.FunctionOpt{cel.Overload("hour", nil, cel.IntType,
+					cel.FunctionBinding(func(...ref.Val) ref.Val { return types.Int(10) }))},
+				Impure: true,
+			},
+			"twice": {
+				Overloads: []cel.FunctionOpt{cel.Overload("twice_int", []*cel.Type{cel.IntType}, cel.IntType,
+					cel.UnaryBinding(func(v ref.Val) ref.Val { return v.(types.Int) * 2 }))},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		check        string
+		rejectImpure bool
+		allowImpure  []string
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name:  "pure function",
+			check: "twice(input.n) == 4",
+		},
+		{
+			name:         "impure function",
+			check:        "hour() < 17 && twice(input.n) == 4",
+			wantWarnings: []string{`default.1: check "hour() < 17 && twice(input.n) == 4" calls impure functions (hour), so its result may not be a pure function of the input`},
+		},
+		{
+			name:         "impure function rejected",
+			check:        "hour() < 17",
+			rejectImpure: true,
+			wantErr:      `default.1: check "hour() < 17" calls impure functions (hour), so its result may not be a pure function of the input`,
+		},
+		{
+			name:         "allowed impure function",
+			check:        "hour() < 17",
+			rejectImpure: true,
+			allowImpure:  []string{"hour"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := SimpleProgram(
+				s.Start("request"),
+				s.Check(tt.check),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			)
+			p.Dialect = d
+			c := Compiler{
+				Program:      p,
+				InputSchema:  &jsoncel.Schema{Properties: map[string]*jsoncel.Schema{"n": {Type: jsoncel.Integer}}},
+				RejectImpure: tt.rejectImpure,
+				AllowImpure:  tt.allowImpure,
+			}
+			g, err := c.Compile()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, w := range g.Warnings {
+				got = append(got, w.String())
+			}
+			assert.Equal(t, tt.wantWarnings, got)
+
+			res, err := g.Execute("request", map[string]any{"n": 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "approved", res.Outcome)
+		})
+	}
+}
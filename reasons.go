@@ -0,0 +1,116 @@
+package glide
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/dominikbraun/graph"
+)
+
+// Reason is a completed check or action on the path to the outcome of a workflow.
+// Reasons can be used to build audit messages, such as
+// "approved because on-call and manager approval".
+type Reason struct {
+	// Step is the hash of the step.
+	Step string `json:"step"`
+
+	// Label is the name of the step. Checks without a name are labelled
+	// by their expression, and actions by what the action does.
+	Label string `json:"label"`
+
+	// Expression is the CEL expression of a check step.
+	Expression string `json:"expression,omitempty"`
+
+	// Action is the type of an action step, e.g. 'approval'.
+	Action string `json:"action,omitempty"`
+}
+
+func (r Reason) String() string {
+	if r.Expression != "" && r.Label != r.Expression {
+		return fmt.Sprintf("%s (%s)", r.Label, r.Expression)
+	}
+	return r.Label
+}
+
+// outcomeReasons returns the completed checks and actions which led to the outcome,
+// by walking the Completion Graph backwards from it.
+// The reasons are sorted by their position in the workflow.
+func outcomeReasons(cg graph.Graph[string, step.Step], state map[string]State, outcome string) ([]Reason, error) {
+	if cg == nil || outcome == "" {
+		return nil, nil
+	}
+
+	preds, err := cg.PredecessorMap()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := preds[outcome]; !ok {
+		return nil, nil
+	}
+
+	var steps []step.Step
+	seen := map[string]bool{outcome: true}
+	queue := []string{outcome}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+
+		for source := range preds[k] {
+			if seen[source] {
+				continue
+			}
+			seen[source] = true
+			queue = append(queue, source)
+
+			if state[source] != Complete {
+				continue
+			}
+			s, err := cg.Vertex(source)
+			if err != nil {
+				return nil, err
+			}
+			switch s.Body.(type) {
+			case step.Check, step.Action:
+				steps = append(steps, s)
+			}
+		}
+	}
+
+	sort.Slice(steps, func(i, j int) bool {
+		a, b := steps[i], steps[j]
+		if a.Pass != b.Pass {
+			return a.Pass < b.Pass
+		}
+		return comparePositions(a.Position, b.Position) < 0
+	})
+
+	var reasons []Reason
+	for _, s := range steps {
+		r := Reason{Step: s.Hash()}
+		switch t := s.Body.(type) {
+		case step.Check:
+			r.Label = s.Name
+			r.Expression = t.Expression
+			if r.Label == "" {
+				r.Label = t.Expression
+			}
+		case step.Action:
+			r.Label = actionLabel(s)
+			r.Action = t.Name
+		}
+		reasons = append(reasons, r)
+	}
+	return reasons, nil
+}
+
+// comparePositions compares the positions of two steps in a pass,
+// returning a negative number if a comes before b.
+func comparePositions(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
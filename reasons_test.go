@@ -0,0 +1,80 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute_Reasons(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"oncall":  {Type: jsoncel.Boolean},
+			"manager": {Type: jsoncel.Boolean},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		give  *Program
+		input map[string]any
+		want  []string
+	}{
+		{
+			name: "checks and actions",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Named("On call").Check("input.oncall"),
+				s.Action("my_action", &testAction{complete: true}),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"oncall": true},
+			want:  []string{"On call (input.oncall)", "action: my_action"},
+		},
+		{
+			name: "only completed branches",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.Or,
+					s.Check("input.oncall"),
+					s.Check("input.manager"),
+				),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"oncall": false, "manager": true},
+			want:  []string{"input.manager"},
+		},
+		{
+			name: "no outcome",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("input.oncall"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"oncall": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give, InputSchema: schema}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, r := range res.Reasons {
+				got = append(got, r.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
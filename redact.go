@@ -0,0 +1,252 @@
+package glide
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+)
+
+// DefaultMask replaces the values of sensitive input fields
+// if a Redactor doesn't set a mask.
+const DefaultMask = "[REDACTED]"
+
+// Redactor masks sensitive input fields, such as email addresses, so that
+// inputs and the results of executions can be logged and traced without
+// leaking PII.
+//
+// The masked fields are the fields listed in Paths, and the fields annotated
+// with 'x-sensitive: true' in the input schema of the workflow.
+type Redactor struct {
+	// Paths are the dot-separated paths of the input fields to mask, e.g. 'user.email'.
+	Paths []string
+
+	// Mask replaces the values of the fields. If empty, DefaultMask is used.
+	Mask string
+}
+
+// WithRedactor masks the values of sensitive input fields in the errors
+// and reasons of the result, and in the error returned by Execute.
+// Values are masked where they appear as whole tokens in the messages,
+// and values shorter than three characters aren't masked.
+// Errors are still unwrapped to the original error with errors.Is and errors.As,
+// so callers should log the messages of the returned errors rather than
+// of the errors they wrap.
+//
+// Effects aren't masked, as the host application needs them to carry out the effects.
+func WithRedactor(r Redactor) ExecuteOption {
+	return func(o *executeOptions) {
+		o.redactor = &r
+	}
+}
+
+// Redact returns a copy of the input with the values of the sensitive fields
+// replaced by the mask, so that the input can be logged.
+func (g *Graph) Redact(input map[string]any, r Redactor) map[string]any {
+	out := cloneInput(input)
+	for _, path := range g.sensitivePaths(r) {
+		parts := strings.Split(path, ".")
+		m := out
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := m[part].(map[string]any)
+			if !ok {
+				m = nil
+				break
+			}
+			m = next
+		}
+		if _, ok := m[parts[len(parts)-1]]; ok {
+			m[parts[len(parts)-1]] = r.mask()
+		}
+	}
+	return out
+}
+
+func (r Redactor) mask() string {
+	if r.Mask == "" {
+		return DefaultMask
+	}
+	return r.Mask
+}
+
+// sensitivePaths returns the fields listed by the redactor
+// and the fields annotated as sensitive in the input schema.
+func (g *Graph) sensitivePaths(r Redactor) []string {
+	paths := append([]string{}, r.Paths...)
+	if g.provider != nil {
+		paths = append(paths, jsoncel.SensitiveFields(g.provider.Schema())...)
+	}
+	return paths
+}
+
+// minMaskLength is the length of the shortest value which is masked.
+// Shorter values, like a single digit, would mask unrelated parts
+// of messages, such as the hashes of steps.
+const minMaskLength = 3
+
+// masker replaces the values of sensitive input fields in strings.
+// Values are only masked where they're a whole token, so that a value
+// inside a longer word or identifier is left alone.
+type masker struct {
+	// values are the string representations of the sensitive values,
+	// longest first so that values containing other values are masked whole.
+	values []string
+	mask   string
+}
+
+func (g *Graph) newMasker(input map[string]any, r Redactor) masker {
+	m := masker{mask: r.mask()}
+	seen := map[string]bool{}
+	for _, path := range g.sensitivePaths(r) {
+		v, ok := Lookup(input, path)
+		if !ok {
+			continue
+		}
+		for _, s := range leafStrings(v) {
+			if len(s) >= minMaskLength && !seen[s] {
+				seen[s] = true
+				m.values = append(m.values, s)
+			}
+		}
+	}
+	sort.Slice(m.values, func(i, j int) bool {
+		return len(m.values[i]) > len(m.values[j])
+	})
+	return m
+}
+
+// leafStrings returns the string representations of the
+// strings and numbers in a value and its nested values.
+func leafStrings(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case bool, nil:
+		return nil
+	case map[string]any:
+		var out []string
+		for _, el := range t {
+			out = append(out, leafStrings(el)...)
+		}
+		return out
+	case []any:
+		var out []string
+		for _, el := range t {
+			out = append(out, leafStrings(el)...)
+		}
+		return out
+	}
+	return []string{fmt.Sprint(v)}
+}
+
+func (m masker) string(s string) string {
+	for _, v := range m.values {
+		s = m.replaceTokens(s, v)
+	}
+	return s
+}
+
+// replaceTokens replaces the occurrences of v in s which are whole tokens.
+func (m masker) replaceTokens(s, v string) string {
+	var b strings.Builder
+	start := 0
+	for {
+		i := strings.Index(s[start:], v)
+		if i < 0 {
+			break
+		}
+		i += start
+		end := i + len(v)
+		if tokenStart(s, i) && tokenEnd(s, end) {
+			b.WriteString(s[start:i])
+			b.WriteString(m.mask)
+		} else {
+			b.WriteString(s[start:end])
+		}
+		start = end
+	}
+	if start == 0 {
+		return s
+	}
+	b.WriteString(s[start:])
+	return b.String()
+}
+
+// isTokenRune returns true if r can be part of a token, such as
+// an email address, a number or the hash of a step, e.g. 'default.3'.
+func isTokenRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.@+", r)
+}
+
+// tokenStart returns true if a token can start at s[i].
+func tokenStart(s string, i int) bool {
+	r, size := utf8.DecodeLastRuneInString(s[:i])
+	if size == 0 || !isTokenRune(r) {
+		return true
+	}
+	// a dot which doesn't follow a token, e.g. '.5', is punctuation.
+	if r == '.' {
+		before, size := utf8.DecodeLastRuneInString(s[:i-size])
+		return size == 0 || !isTokenRune(before)
+	}
+	return false
+}
+
+// tokenEnd returns true if a token can end at s[i].
+func tokenEnd(s string, i int) bool {
+	r, size := utf8.DecodeRuneInString(s[i:])
+	if size == 0 || !isTokenRune(r) {
+		return true
+	}
+	// a dot which isn't followed by a token, e.g. at the end of a sentence, is punctuation.
+	if r == '.' {
+		after, size := utf8.DecodeRuneInString(s[i+size:])
+		return size == 0 || !isTokenRune(after)
+	}
+	return false
+}
+
+func (m masker) error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := m.string(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return redactedError{err: err, msg: msg}
+}
+
+// result masks the sensitive values in the errors and reasons of a result.
+func (m masker) result(res *Result) {
+	if res == nil {
+		return
+	}
+	for k, err := range res.Errors {
+		res.Errors[k] = m.error(err)
+	}
+	for i, se := range res.StepErrors {
+		res.StepErrors[i].Err = m.error(se.Err)
+	}
+	for i, r := range res.Reasons {
+		res.Reasons[i].Label = m.string(r.Label)
+		res.Reasons[i].Expression = m.string(r.Expression)
+	}
+}
+
+// redactedError is an error whose message has had sensitive values masked.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e redactedError) Error() string {
+	return e.msg
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,144 @@
+package glide
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute_WithRedactor(t *testing.T) {
+	actionErr := errors.New("no user alice@example.com")
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.OnFail(step.Continue).ID("notify").Action("notify", &testFailAction{err: actionErr}),
+			s.Check(`input.email == "alice@example.com"`),
+			s.Named("Approved").Priority(2).Outcome("approved"),
+		),
+		InputSchema: &jsoncel.Schema{
+			Type: jsoncel.Object,
+			Properties: map[string]*jsoncel.Schema{
+				"email": {Type: jsoncel.String, Sensitive: true},
+				"name":  {Type: jsoncel.String},
+			},
+		},
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := map[string]any{"email": "alice@example.com", "name": "alice"}
+
+	tests := []struct {
+		name       string
+		redactor   Redactor
+		wantErr    string
+		wantReason string
+	}{
+		{
+			name:       "schema annotation",
+			wantErr:    "no user [REDACTED]",
+			wantReason: `input.email == "[REDACTED]"`,
+		},
+		{
+			name:       "custom mask",
+			redactor:   Redactor{Mask: "***"},
+			wantErr:    "no user ***",
+			wantReason: `input.email == "***"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Execute("request", input, WithRedactor(tt.redactor))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "approved", got.Outcome)
+			assert.EqualError(t, got.Errors["default.notify"], tt.wantErr)
+			assert.ErrorIs(t, got.Errors["default.notify"], actionErr)
+
+			var reasons []string
+			for _, r := range got.Reasons {
+				reasons = append(reasons, r.Expression)
+			}
+			assert.Contains(t, reasons, tt.wantReason)
+		})
+	}
+}
+
+func TestGraph_Redact(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(s.Start("request"), s.Outcome("approved")),
+		InputSchema: &jsoncel.Schema{
+			Type: jsoncel.Object,
+			Properties: map[string]*jsoncel.Schema{
+				"email": {Type: jsoncel.String, Sensitive: true},
+				"user": {
+					Type: jsoncel.Object,
+					Properties: map[string]*jsoncel.Schema{
+						"phone": {Type: jsoncel.String},
+						"name":  {Type: jsoncel.String},
+					},
+				},
+			},
+		},
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := map[string]any{
+		"email": "alice@example.com",
+		"user":  map[string]any{"phone": "555-0100", "name": "alice"},
+	}
+	got := g.Redact(input, Redactor{Paths: []string{"user.phone", "missing.field"}})
+
+	assert.Equal(t, map[string]any{
+		"email": DefaultMask,
+		"user":  map[string]any{"phone": DefaultMask, "name": "alice"},
+	}, got)
+
+	// the input isn't modified.
+	assert.Equal(t, "555-0100", input["user"].(map[string]any)["phone"])
+}
+
+func TestMasker_String(t *testing.T) {
+	g := &Graph{}
+	input := map[string]any{"age": 3, "email": "alice@example.com", "team": "ops", "id": 123}
+	m := g.newMasker(input, Redactor{Paths: []string{"age", "email", "team", "id"}})
+
+	tests := []struct {
+		give string
+		want string
+	}{
+		{
+			give: "evaluating default.3: no user alice@example.com.",
+			want: "evaluating default.3: no user [REDACTED].",
+		},
+		{
+			// short values aren't masked.
+			give: "age is 3",
+			want: "age is 3",
+		},
+		{
+			give: `team "ops" is not one of the ops-admins`,
+			want: `team "[REDACTED]" is not one of the ops-admins`,
+		},
+		{
+			give: "id 123, not 1234 or 0.123",
+			want: "id [REDACTED], not 1234 or 0.123",
+		},
+		{
+			give: "bob.alice@example.com",
+			want: "bob.alice@example.com",
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, m.string(tt.give))
+	}
+}
@@ -0,0 +1,44 @@
+package glide
+
+import (
+	"fmt"
+
+	"github.com/common-fate/glide/pkg/dialect"
+)
+
+// ReduceInput folds the events into the input in order, with the reducers
+// of the dialect for each event type, and returns the new input.
+// The input isn't modified. If it's nil, the events are folded into
+// an empty input.
+//
+// Hosts which record the events of a request, such as approvals being added,
+// can use it to build the input of the workflow, so that every service which
+// executes the workflow builds the same input.
+func ReduceInput(d dialect.Dialect, input map[string]any, events ...dialect.InputEvent) (map[string]any, error) {
+	out := cloneInput(input)
+	if out == nil {
+		out = map[string]any{}
+	}
+
+	for i, e := range events {
+		r, ok := d.Reducers[e.Type]
+		if !ok {
+			return nil, fmt.Errorf("event %d: the dialect has no reducer for event type %q", i, e.Type)
+		}
+
+		// each reducer is given its own copy of the event data,
+		// so that the input doesn't share values with the event.
+		e.Data = cloneInput(e.Data)
+
+		var err error
+		out, err = r.Reduce(out, e)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: reducing %s: %w", i, e.Type, err)
+		}
+		if out == nil {
+			out = map[string]any{}
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package glide
+
+import (
+	"context"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/stretchr/testify/assert"
+)
+
+// reduceDialect is the test dialect with a reducer
+// which appends approvals to the input.
+var reduceDialect = func() dialect.Dialect {
+	d := yamlTestDialect
+	d.Reducers = map[string]dialect.InputReducer{
+		"approval_added": dialect.InputReducerFunc(func(input map[string]any, e dialect.InputEvent) (map[string]any, error) {
+			approvals, _ := input["approvals"].([]any)
+			input["approvals"] = append(approvals, e.Data["user"])
+			return input, nil
+		}),
+	}
+	return d
+}()
+
+func TestReduceInput(t *testing.T) {
+	input := map[string]any{"requestor": "alice"}
+
+	got, err := ReduceInput(reduceDialect, input,
+		dialect.InputEvent{Type: "approval_added", Data: map[string]any{"user": "bob"}},
+		dialect.InputEvent{Type: "approval_added", Data: map[string]any{"user": "carol"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]any{"requestor": "alice", "approvals": []any{"bob", "carol"}}, got)
+
+	// the input isn't modified.
+	assert.Equal(t, map[string]any{"requestor": "alice"}, input)
+
+	_, err = ReduceInput(reduceDialect, nil, dialect.InputEvent{Type: "justification_edited"})
+	assert.EqualError(t, err, `event 0: the dialect has no reducer for event type "justification_edited"`)
+}
+
+func TestEngine_HandleInputEvent(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: size(input.approvals) >= 2
+      - outcome: approved
+`), reduceDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Build(p, &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"approvals": {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngine(c, "request", NewMemoryStateStore())
+	ctx := context.Background()
+
+	res, err := e.HandleEvent(ctx, "req_1", Event{Input: map[string]any{"approvals": []any{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", res.Outcome)
+
+	for _, user := range []string{"bob", "carol"} {
+		res, err = e.HandleInputEvent(ctx, "req_1", dialect.InputEvent{Type: "approval_added", Data: map[string]any{"user": user}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, "approved", res.Outcome)
+
+	state, err := e.State(ctx, "req_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]any{"approvals": []any{"bob", "carol"}}, state.Input)
+}
@@ -0,0 +1,115 @@
+package glide
+
+import (
+	"sort"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// NodeRef is a reference to a start or outcome node in a workflow.
+type NodeRef struct {
+	// Pass is the name of the pass containing the reference.
+	Pass string
+
+	// Position of the referencing step in the pass.
+	Position []int
+
+	// Node is the referenced node.
+	Node node.Node
+
+	// Resolved is true if the node is defined in the dialect.
+	Resolved bool
+}
+
+// Refs lists every start and outcome reference in the program,
+// ordered by pass name and then position.
+func (p *Program) Refs() []NodeRef {
+	var passes []string
+	for id := range p.Workflow {
+		passes = append(passes, id)
+	}
+	sort.Strings(passes)
+
+	var refs []NodeRef
+	for _, id := range passes {
+		refs = appendRefs(refs, p.Dialect, id, p.Workflow[id].Steps, nil)
+	}
+	return refs
+}
+
+func appendRefs(refs []NodeRef, d *dialect.Dialect, pass string, steps []step.Step, parent []int) []NodeRef {
+	for i, s := range steps {
+		pos := append(append([]int{}, parent...), i)
+
+		if r, ok := s.Body.(step.Ref); ok {
+			refs = append(refs, newNodeRef(d, pass, pos, r.Node))
+		}
+		refs = appendRefs(refs, d, pass, s.Children, pos)
+		refs = appendRefs(refs, d, pass, s.OnFail.Steps, pos)
+	}
+	return refs
+}
+
+func newNodeRef(d *dialect.Dialect, pass string, pos []int, n node.Node) NodeRef {
+	ref := NodeRef{
+		Pass:     pass,
+		Position: pos,
+		Node:     n,
+	}
+	if d != nil {
+		_, ref.Resolved = d.Nodes[n.ID]
+	}
+	return ref
+}
+
+// Refs lists every start and outcome reference which was compiled into the graph,
+// ordered by pass name and then position.
+func (g *Graph) Refs() []NodeRef {
+	refs := append([]NodeRef{}, g.refs...)
+	sort.SliceStable(refs, func(i, j int) bool {
+		if refs[i].Pass != refs[j].Pass {
+			return refs[i].Pass < refs[j].Pass
+		}
+		return lessPosition(refs[i].Position, refs[j].Position)
+	})
+	return refs
+}
+
+func lessPosition(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// UnusedNodes returns the IDs of the dialect nodes which aren't referenced
+// by any of the provided refs, in sorted order.
+//
+// It can be used to flag dialect outcomes which can't be reached by any pass:
+//
+//	unused := glide.UnusedNodes(program.Dialect, program.Refs())
+//
+// Programs built without a dialect have no nodes to flag, so nil is returned.
+func UnusedNodes(d *dialect.Dialect, refs []NodeRef) []string {
+	if d == nil {
+		return nil
+	}
+
+	used := map[string]bool{}
+	for _, r := range refs {
+		used[r.Node.ID] = true
+	}
+
+	var unused []string
+	for id := range d.Nodes {
+		if !used[id] {
+			unused = append(unused, id)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
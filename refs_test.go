@@ -0,0 +1,55 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefs(t *testing.T) {
+	d := dialect.Dialect{
+		Nodes: map[string]node.Node{
+			"request":  {Type: node.Start},
+			"approved": {Type: node.Outcome, Priority: 1},
+			"denied":   {Type: node.Outcome, Priority: 2},
+		},
+	}
+
+	p, err := Unmarshal([]byte(`
+workflow:
+  b:
+    steps:
+      - start: request
+      - check: "false"
+      - outcome: approved
+  a:
+    steps:
+      - start: request
+      - check: "true"
+      - outcome: approved
+`), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []NodeRef{
+		{Pass: "a", Position: []int{0}, Node: node.Node{Type: node.Start, ID: "request"}, Resolved: true},
+		{Pass: "a", Position: []int{2}, Node: node.Node{Type: node.Outcome, ID: "approved", Priority: 1}, Resolved: true},
+		{Pass: "b", Position: []int{0}, Node: node.Node{Type: node.Start, ID: "request"}, Resolved: true},
+		{Pass: "b", Position: []int{2}, Node: node.Node{Type: node.Outcome, ID: "approved", Priority: 1}, Resolved: true},
+	}
+
+	assert.Equal(t, want, p.Refs())
+
+	c := Compiler{Program: p}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, want, g.Refs())
+
+	assert.Equal(t, []string{"denied"}, UnusedNodes(&d, g.Refs()))
+	assert.Nil(t, UnusedNodes(nil, g.Refs()))
+}
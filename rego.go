@@ -0,0 +1,706 @@
+package glide
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// RegoExport is a workflow translated into a Rego module.
+type RegoExport struct {
+	// Module is the Rego source. The 'outcome' rule is the outcome
+	// of the workflow, and each step is a rule which is true
+	// when the step is complete.
+	Module string
+
+	// Untranslated are the steps which couldn't be translated, such as actions.
+	// They are never complete in the module, so the steps after them aren't either.
+	Untranslated []UntranslatedStep
+}
+
+// UntranslatedStep is a step which couldn't be translated to Rego.
+type UntranslatedStep struct {
+	// Step is the hash of the step.
+	Step string `json:"step"`
+	// Rule is the name of the step's rule in the Rego module.
+	Rule   string `json:"rule"`
+	Label  string `json:"label"`
+	Reason string `json:"reason"`
+}
+
+// ExportRego translates the workflow executed from a start node into a Rego module
+// in the package pkg, so that the workflow can be enforced by Open Policy Agent.
+//
+// Checks, 'and' and 'or' steps, needs and outcomes are translated into rules.
+// Actions can't be evaluated outside of Glide, so they are never complete in the
+// module, and are listed in the Untranslated steps along with any checks which
+// use features with no Rego equivalent. Steps with a schedule are never complete
+// in the module either, as they depend on the time of the execution. The module can be evaluated against the
+// same input as the workflow, and uses the Rego v1 syntax.
+//
+// Unlike Execute, a check which references a missing input field is
+// not an error in Rego: the check is simply not complete.
+func (g *Graph) ExportRego(start, pkg string) (*RegoExport, error) {
+	startVertex, err := g.store.step(start)
+	if err != nil {
+		return nil, err
+	}
+	if ref, ok := startVertex.Body.(step.Ref); !ok || ref.Node.Type != node.Start {
+		return nil, fmt.Errorf("provided start %s was not a start node", start)
+	}
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+	reachable, err := g.reachable(start)
+	if err != nil {
+		return nil, err
+	}
+
+	w := regoWriter{g: g, rules: map[string]string{}, used: map[string]bool{}}
+	for _, k := range order {
+		if reachable[k] {
+			w.rules[k] = w.name("step_" + regoIdent(k))
+		}
+	}
+
+	var out RegoExport
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by 'glide export rego' from the workflow starting at '%s'.\n", start)
+	if g.version != "" {
+		fmt.Fprintf(&b, "# Workflow version: %s\n", g.version)
+	}
+	fmt.Fprintf(&b, "package %s\n\nimport rego.v1\n", pkg)
+
+	// outcomes are ordered by priority, so that the highest priority outcome wins.
+	var outcomes []node.Node
+	for _, k := range order {
+		if !reachable[k] {
+			continue
+		}
+		s, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+		// outcomes without a priority are never the result of an execution.
+		if ref, ok := s.Body.(step.Ref); ok && ref.Node.Type == node.Outcome && ref.Node.Priority > 0 {
+			outcomes = append(outcomes, ref.Node)
+		}
+	}
+	sort.SliceStable(outcomes, func(i, j int) bool {
+		return outcomes[i].Priority > outcomes[j].Priority
+	})
+
+	b.WriteString("\n")
+	if g.defaultOutcome != nil {
+		fmt.Fprintf(&b, "default outcome := %s\n\n", regoString(g.defaultOutcome.ID))
+	}
+	for i, o := range outcomes {
+		if i == 0 {
+			b.WriteString("outcome := ")
+		} else {
+			b.WriteString(" else := ")
+		}
+		fmt.Fprintf(&b, "%s if {\n\t%s\n}", regoString(o.ID), w.rules[o.ID])
+	}
+	if len(outcomes) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, k := range order {
+		if !reachable[k] {
+			continue
+		}
+		s, err := g.store.step(k)
+		if err != nil {
+			return nil, err
+		}
+		rule := w.rules[k]
+
+		fmt.Fprintf(&b, "\n# %s: %s\n", k, strings.ReplaceAll(exportLabel(s), "\n", " "))
+		if k == start {
+			fmt.Fprintf(&b, "%s := true\n", rule)
+			continue
+		}
+		fmt.Fprintf(&b, "default %s := false\n", rule)
+
+		preds, needs, err := w.predecessors(k)
+		if err != nil {
+			return nil, err
+		}
+
+		// the schedule of a step depends on the time of the execution,
+		// which isn't part of the input.
+		if _, ok := s.Body.(step.Action); !ok && s.When != nil {
+			reason := fmt.Sprintf("steps with a schedule (%s) depend on the time of the execution, so they are never complete in Rego", s.When.Spec)
+			out.Untranslated = append(out.Untranslated, UntranslatedStep{Step: k, Rule: rule, Label: exportLabel(s), Reason: reason})
+			continue
+		}
+
+		var bodies [][]string
+		var helpers []regoRule
+		switch t := s.Body.(type) {
+		case step.Action:
+			reason := fmt.Sprintf("action %s can't be evaluated in Rego, so it is never complete", t.Name)
+			if len(s.OnFail.Steps) > 0 {
+				reason += " and its on_fail steps are never reached"
+			}
+			out.Untranslated = append(out.Untranslated, UntranslatedStep{Step: k, Rule: rule, Label: exportLabel(s), Reason: reason})
+			continue
+		case step.Wait:
+			reason := "waits depend on the time of the execution, so they are never complete in Rego"
+			out.Untranslated = append(out.Untranslated, UntranslatedStep{Step: k, Rule: rule, Label: exportLabel(s), Reason: reason})
+			continue
+		case step.Check:
+			ast, ok := g.asts[k]
+			if !ok {
+				return nil, fmt.Errorf("could not find the CEL expression of %s", k)
+			}
+			c, err := w.check(rule, ast)
+			if err != nil {
+				out.Untranslated = append(out.Untranslated, UntranslatedStep{Step: k, Rule: rule, Label: exportLabel(s), Reason: err.Error()})
+				continue
+			}
+			if preds == nil {
+				break
+			}
+			// if the check has more than one predecessor, whether any of them
+			// is complete is a rule of its own, to avoid repeating the check.
+			active := preds[0]
+			if len(preds) > 1 {
+				active = w.name(rule + "_active")
+				c.helpers = append([]regoRule{{name: active, bodies: splitBodies(preds)}}, c.helpers...)
+			}
+			bodies = [][]string{append(append([]string{active}, needs...), c.lines...)}
+			helpers = c.helpers
+		case step.Boolean:
+			if preds == nil {
+				break
+			}
+			if t.Op == step.And {
+				bodies = [][]string{append(append([]string{}, preds...), needs...)}
+				break
+			}
+			for _, p := range preds {
+				bodies = append(bodies, append([]string{p}, needs...))
+			}
+		case step.Ref:
+			for _, p := range preds {
+				bodies = append(bodies, append([]string{p}, needs...))
+			}
+		}
+
+		if bodies == nil {
+			continue
+		}
+		w.writeRule(&b, regoRule{name: rule, bodies: bodies})
+		// helper rules are written after the rule which uses them.
+		for _, h := range helpers {
+			w.writeRule(&b, h)
+		}
+	}
+
+	out.Module = b.String()
+	return &out, nil
+}
+
+// regoRule is a rule in a Rego module. The rule is true if any of its bodies are.
+type regoRule struct {
+	name   string
+	bodies [][]string
+}
+
+type regoWriter struct {
+	g *Graph
+	// rules maps step hashes to the names of their rules.
+	rules map[string]string
+	// used are the rule names which have been taken.
+	used map[string]bool
+}
+
+// name returns a unique rule name starting with the base name.
+func (w *regoWriter) name(base string) string {
+	name := base
+	for i := 2; w.used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	w.used[name] = true
+	return name
+}
+
+func (w *regoWriter) writeRule(b *strings.Builder, r regoRule) {
+	for _, body := range r.bodies {
+		fmt.Fprintf(b, "\n%s if {\n", r.name)
+		for _, line := range body {
+			fmt.Fprintf(b, "\t%s\n", line)
+		}
+		b.WriteString("}\n")
+	}
+}
+
+// predecessors returns the rules of the predecessors which activate a step, and the
+// rules of the steps it needs. preds is nil if the step can never be complete:
+// if it's only reached by on_fail edges, or if it needs or, for 'and' steps, waits
+// for a step which can't be reached from the start node.
+func (w *regoWriter) predecessors(k string) (preds, needs []string, err error) {
+	s, err := w.g.store.step(k)
+	if err != nil {
+		return nil, nil, err
+	}
+	and := false
+	if b, ok := s.Body.(step.Boolean); ok && b.Op == step.And {
+		and = true
+	}
+
+	edges, err := w.g.store.predecessors(k)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].Source < edges[j].Source
+	})
+
+	for _, e := range edges {
+		rule, reachable := w.rules[e.Source]
+		// actions never fail in Rego, so on_fail edges are never followed.
+		followed := reachable && e.Attributes[onFailAttribute] != "true"
+
+		if e.Attributes[needsAttribute] == "true" {
+			if !followed {
+				return nil, nil, nil
+			}
+			needs = append(needs, rule)
+			continue
+		}
+		if !followed {
+			if and {
+				return nil, nil, nil
+			}
+			continue
+		}
+		preds = append(preds, rule)
+	}
+	return preds, needs, nil
+}
+
+// splitBodies returns a body for each of the lines.
+func splitBodies(lines []string) [][]string {
+	var bodies [][]string
+	for _, l := range lines {
+		bodies = append(bodies, []string{l})
+	}
+	return bodies
+}
+
+// regoCheck is a check expression translated into Rego.
+type regoCheck struct {
+	// lines are the expressions in the body of the check's rule.
+	lines []string
+	// helpers are the rules which the lines use, for 'or' and negated expressions.
+	helpers []regoRule
+}
+
+// regoTranslator translates a type-checked CEL expression into Rego.
+type regoTranslator struct {
+	w    *regoWriter
+	rule string
+	// types are the types of the expressions, by expression ID.
+	types   map[int64]*exprpb.Type
+	helpers []regoRule
+	// vars are the variables of the comprehensions being translated.
+	// Helper rules can't reference them, so they can't be used inside comprehensions.
+	vars map[string]bool
+}
+
+func (w *regoWriter) check(rule string, ast *cel.Ast) (regoCheck, error) {
+	checked, err := cel.AstToCheckedExpr(ast)
+	if err != nil {
+		return regoCheck{}, err
+	}
+	t := regoTranslator{w: w, rule: rule, types: checked.GetTypeMap(), vars: map[string]bool{}}
+	lines, err := t.cond(checked.GetExpr())
+	if err != nil {
+		return regoCheck{}, err
+	}
+	return regoCheck{lines: lines, helpers: t.helpers}, nil
+}
+
+// regoComparisons maps CEL comparison operators to Rego.
+var regoComparisons = map[string]string{
+	"_==_": "==",
+	"_!=_": "!=",
+	"_<_":  "<",
+	"_<=_": "<=",
+	"_>_":  ">",
+	"_>=_": ">=",
+}
+
+// cond translates a boolean expression into the lines of a rule body.
+func (t *regoTranslator) cond(e *exprpb.Expr) ([]string, error) {
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		if b, ok := k.ConstExpr.GetConstantKind().(*exprpb.Constant_BoolValue); ok {
+			return []string{strconv.FormatBool(b.BoolValue)}, nil
+		}
+	case *exprpb.Expr_SelectExpr:
+		if k.SelectExpr.GetTestOnly() {
+			// has(a.b) is true if the field is present, even if it's null.
+			operand, err := t.term(k.SelectExpr.GetOperand())
+			if err != nil {
+				return nil, err
+			}
+			return []string{fmt.Sprintf("%s in object.keys(%s)", regoString(k.SelectExpr.GetField()), operand)}, nil
+		}
+	case *exprpb.Expr_CallExpr:
+		args := k.CallExpr.GetArgs()
+		switch fn := k.CallExpr.GetFunction(); fn {
+		case "_&&_":
+			var lines []string
+			for _, a := range args {
+				l, err := t.cond(a)
+				if err != nil {
+					return nil, err
+				}
+				lines = append(lines, l...)
+			}
+			return lines, nil
+		case "_||_":
+			var bodies [][]string
+			for _, a := range disjuncts(e) {
+				l, err := t.cond(a)
+				if err != nil {
+					return nil, err
+				}
+				bodies = append(bodies, l)
+			}
+			h, err := t.helper(bodies)
+			if err != nil {
+				return nil, err
+			}
+			return []string{h}, nil
+		case "!_":
+			l, err := t.cond(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if len(l) == 1 && !strings.HasPrefix(l[0], "not ") && !strings.HasPrefix(l[0], "every ") {
+				return []string{"not " + l[0]}, nil
+			}
+			h, err := t.helper([][]string{l})
+			if err != nil {
+				return nil, err
+			}
+			return []string{"not " + h}, nil
+		case "@in":
+			item, err := t.term(args[0])
+			if err != nil {
+				return nil, err
+			}
+			collection, err := t.term(args[1])
+			if err != nil {
+				return nil, err
+			}
+			switch t.kind(args[1]) {
+			case kindList:
+				return []string{fmt.Sprintf("%s in %s", item, collection)}, nil
+			case kindMap:
+				// 'in' tests the keys of a map in CEL, but the values of an object in Rego.
+				return []string{fmt.Sprintf("%s in object.keys(%s)", item, collection)}, nil
+			}
+			return nil, fmt.Errorf("the type of the right-hand side of 'in' must be known to translate it")
+		default:
+			if op, ok := regoComparisons[fn]; ok {
+				a, err := t.term(args[0])
+				if err != nil {
+					return nil, err
+				}
+				b, err := t.term(args[1])
+				if err != nil {
+					return nil, err
+				}
+				return []string{fmt.Sprintf("%s %s %s", a, op, b)}, nil
+			}
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		return t.comprehension(k.ComprehensionExpr)
+	}
+
+	term, err := t.term(e)
+	if err != nil {
+		return nil, err
+	}
+	return []string{term}, nil
+}
+
+// disjuncts flattens nested '||' expressions.
+func disjuncts(e *exprpb.Expr) []*exprpb.Expr {
+	if c := e.GetCallExpr(); c != nil && c.GetFunction() == "_||_" {
+		var out []*exprpb.Expr
+		for _, a := range c.GetArgs() {
+			out = append(out, disjuncts(a)...)
+		}
+		return out
+	}
+	return []*exprpb.Expr{e}
+}
+
+// helper adds a helper rule which is true if any of the bodies are.
+func (t *regoTranslator) helper(bodies [][]string) (string, error) {
+	if len(t.vars) > 0 {
+		return "", fmt.Errorf("'||' and negated expressions inside 'exists' and 'all' can't be translated")
+	}
+	name := t.w.name(fmt.Sprintf("%s_cond_%d", t.rule, len(t.helpers)+1))
+	t.helpers = append(t.helpers, regoRule{name: name, bodies: bodies})
+	return name, nil
+}
+
+// comprehension translates the 'exists' and 'all' macros,
+// which are the comprehensions which return a boolean.
+func (t *regoTranslator) comprehension(c *exprpb.Expr_Comprehension) ([]string, error) {
+	init, ok := c.GetAccuInit().GetConstExpr().GetConstantKind().(*exprpb.Constant_BoolValue)
+	loop := c.GetLoopStep().GetCallExpr()
+	if !ok || loop == nil || len(loop.GetArgs()) != 2 || loop.GetArgs()[0].GetIdentExpr().GetName() != c.GetAccuVar() {
+		return nil, fmt.Errorf("only the 'exists' and 'all' macros can be translated")
+	}
+	exists := !init.BoolValue && loop.GetFunction() == "_||_"
+	all := init.BoolValue && loop.GetFunction() == "_&&_"
+	if !exists && !all {
+		return nil, fmt.Errorf("only the 'exists' and 'all' macros can be translated")
+	}
+
+	collection, err := t.term(c.GetIterRange())
+	if err != nil {
+		return nil, err
+	}
+	v := c.GetIterVar()
+	switch t.kind(c.GetIterRange()) {
+	case kindList:
+	case kindMap:
+		// comprehensions iterate over the keys of a map in CEL.
+		v += ", _"
+	default:
+		return nil, fmt.Errorf("the type of the range of a comprehension must be known to translate it")
+	}
+
+	t.vars[c.GetIterVar()] = true
+	lines, err := t.cond(loop.GetArgs()[1])
+	delete(t.vars, c.GetIterVar())
+	if err != nil {
+		return nil, err
+	}
+
+	if all {
+		return []string{fmt.Sprintf("every %s in %s { %s }", v, collection, strings.Join(lines, "; "))}, nil
+	}
+	return []string{fmt.Sprintf("count([true | some %s in %s; %s]) > 0", v, collection, strings.Join(lines, "; "))}, nil
+}
+
+// term translates an expression into a Rego term.
+func (t *regoTranslator) term(e *exprpb.Expr) (string, error) {
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		switch c := k.ConstExpr.GetConstantKind().(type) {
+		case *exprpb.Constant_BoolValue:
+			return strconv.FormatBool(c.BoolValue), nil
+		case *exprpb.Constant_Int64Value:
+			return strconv.FormatInt(c.Int64Value, 10), nil
+		case *exprpb.Constant_Uint64Value:
+			return strconv.FormatUint(c.Uint64Value, 10), nil
+		case *exprpb.Constant_DoubleValue:
+			return strconv.FormatFloat(c.DoubleValue, 'g', -1, 64), nil
+		case *exprpb.Constant_StringValue:
+			return regoString(c.StringValue), nil
+		case *exprpb.Constant_NullValue:
+			return "null", nil
+		}
+		return "", fmt.Errorf("constant %s can't be translated", k.ConstExpr)
+	case *exprpb.Expr_IdentExpr:
+		name := k.IdentExpr.GetName()
+		switch {
+		case name == "input" || t.vars[name]:
+			return name, nil
+		case name == stepsVar:
+			return "", fmt.Errorf("references to the state of steps can only be translated as 'steps.<id>.complete'")
+		case name == workflowVar:
+			return "", fmt.Errorf("references to the workflow metadata can't be translated")
+		}
+		return "", fmt.Errorf("identifier %s can't be translated", name)
+	case *exprpb.Expr_SelectExpr:
+		if k.SelectExpr.GetTestOnly() {
+			return "", fmt.Errorf("'has' can't be translated as a value")
+		}
+		// 'steps.<id>.complete' is the rule of the step.
+		if path, ok := selectPath(e); ok && strings.HasPrefix(path, stepsVar+".") {
+			parts := strings.Split(path, ".")
+			if hash, ok := t.w.g.stepHashes[parts[1]]; ok && len(parts) == 3 && parts[2] == "complete" {
+				if rule, ok := t.w.rules[hash]; ok {
+					return rule, nil
+				}
+				return "false", nil
+			}
+		}
+		operand, err := t.term(k.SelectExpr.GetOperand())
+		if err != nil {
+			return "", err
+		}
+		field := k.SelectExpr.GetField()
+		if regoFieldRegex.MatchString(field) && !regoKeywords[field] {
+			return operand + "." + field, nil
+		}
+		return fmt.Sprintf("%s[%s]", operand, regoString(field)), nil
+	case *exprpb.Expr_ListExpr:
+		var elems []string
+		for _, el := range k.ListExpr.GetElements() {
+			s, err := t.term(el)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, s)
+		}
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	case *exprpb.Expr_StructExpr:
+		if k.StructExpr.GetMessageName() != "" {
+			return "", fmt.Errorf("message %s can't be translated", k.StructExpr.GetMessageName())
+		}
+		var entries []string
+		for _, entry := range k.StructExpr.GetEntries() {
+			key, err := t.term(entry.GetMapKey())
+			if err != nil {
+				return "", err
+			}
+			value, err := t.term(entry.GetValue())
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, key+": "+value)
+		}
+		return "{" + strings.Join(entries, ", ") + "}", nil
+	case *exprpb.Expr_CallExpr:
+		return t.call(e, k.CallExpr)
+	}
+	return "", fmt.Errorf("expression %s can't be translated as a value", e)
+}
+
+// call translates a function call into a Rego term.
+func (t *regoTranslator) call(e *exprpb.Expr, c *exprpb.Expr_Call) (string, error) {
+	var args []string
+	if c.GetTarget() != nil {
+		target, err := t.term(c.GetTarget())
+		if err != nil {
+			return "", err
+		}
+		args = append(args, target)
+	}
+	for _, a := range c.GetArgs() {
+		s, err := t.term(a)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, s)
+	}
+
+	fn := c.GetFunction()
+	switch fn {
+	case "_[_]":
+		return fmt.Sprintf("%s[%s]", args[0], args[1]), nil
+	case "size":
+		return fmt.Sprintf("count(%s)", args[0]), nil
+	case "startsWith":
+		return fmt.Sprintf("startswith(%s, %s)", args[0], args[1]), nil
+	case "endsWith":
+		return fmt.Sprintf("endswith(%s, %s)", args[0], args[1]), nil
+	case "contains":
+		return fmt.Sprintf("contains(%s, %s)", args[0], args[1]), nil
+	case "matches":
+		return fmt.Sprintf("regex.match(%s, %s)", args[1], args[0]), nil
+	case "-_":
+		return fmt.Sprintf("(0 - %s)", args[0]), nil
+	case "_+_":
+		switch t.kind(e) {
+		case kindString:
+			return fmt.Sprintf("concat(\"\", [%s, %s])", args[0], args[1]), nil
+		case kindList:
+			return fmt.Sprintf("array.concat(%s, %s)", args[0], args[1]), nil
+		case kindNumber, kindDouble:
+			return fmt.Sprintf("(%s + %s)", args[0], args[1]), nil
+		}
+		return "", fmt.Errorf("the type of '+' must be known to translate it")
+	case "_-_", "_*_", "_%_":
+		return fmt.Sprintf("(%s %s %s)", args[0], fn[1:2], args[1]), nil
+	case "_?_:_":
+		return "", fmt.Errorf("conditional expressions can't be translated")
+	case "_/_":
+		// integer division truncates in CEL, but not in Rego.
+		if t.kind(e) != kindDouble {
+			return "", fmt.Errorf("integer division can't be translated")
+		}
+		return fmt.Sprintf("(%s / %s)", args[0], args[1]), nil
+	}
+	if _, ok := regoComparisons[fn]; ok || fn == "_&&_" || fn == "_||_" || fn == "!_" || fn == "@in" {
+		return "", fmt.Errorf("boolean operators can't be translated as values")
+	}
+	return "", fmt.Errorf("function %s can't be translated", strings.Trim(fn, "_@"))
+}
+
+// regoKind is the kind of value an expression has, as far as translating it is concerned.
+type regoKind int
+
+const (
+	kindUnknown regoKind = iota
+	kindString
+	kindNumber
+	kindDouble
+	kindList
+	kindMap
+)
+
+// kind returns the kind of value of a type-checked expression.
+func (t *regoTranslator) kind(e *exprpb.Expr) regoKind {
+	typ := t.types[e.GetId()]
+	switch k := typ.GetTypeKind().(type) {
+	case *exprpb.Type_Primitive:
+		switch k.Primitive {
+		case exprpb.Type_STRING:
+			return kindString
+		case exprpb.Type_INT64, exprpb.Type_UINT64:
+			return kindNumber
+		case exprpb.Type_DOUBLE:
+			return kindDouble
+		}
+	case *exprpb.Type_ListType_:
+		return kindList
+	case *exprpb.Type_MapType_, *exprpb.Type_MessageType:
+		return kindMap
+	}
+	return kindUnknown
+}
+
+// regoFieldRegex matches fields which can be referenced with a dot in Rego.
+var regoFieldRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// regoKeywords are the Rego keywords, which can't be referenced with a dot.
+var regoKeywords = map[string]bool{
+	"as": true, "contains": true, "default": true, "else": true, "every": true, "false": true,
+	"if": true, "import": true, "in": true, "not": true, "null": true, "package": true,
+	"some": true, "true": true, "with": true,
+}
+
+// regoIdent converts a step hash into a Rego identifier.
+func regoIdent(s string) string {
+	return regexp.MustCompile(`[^a-zA-Z0-9_]`).ReplaceAllString(s, "_")
+}
+
+// regoString quotes a string for Rego, which uses the JSON string syntax.
+func regoString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,222 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_ExportRego(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		s.Boolean(step.Or,
+			s.Check(`input.group == "admins"`),
+			s.Named("Approval").Action("my_action", &testAction{}),
+		),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)
+	c := Compiler{Program: p, InputSchema: &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{"group": {Type: jsoncel.String}},
+	}}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.ExportRego("request", "glide.workflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# Generated by 'glide export rego' from the workflow starting at 'request'.
+package glide.workflow
+
+import rego.v1
+
+outcome := "approved" if {
+	step_approved
+}
+
+# request: start: request
+step_request := true
+
+# default.1.0: if: input.group == "admins"
+default step_default_1_0 := false
+
+step_default_1_0 if {
+	step_request
+	input.group == "admins"
+}
+
+# default.1.1: Approval
+default step_default_1_1 := false
+
+# default.1: OR
+default step_default_1 := false
+
+step_default_1 if {
+	step_default_1_0
+}
+
+step_default_1 if {
+	step_default_1_1
+}
+
+# approved: Approved
+default step_approved := false
+
+step_approved if {
+	step_default_1
+}
+`
+	assert.Equal(t, want, got.Module)
+	assert.Equal(t, []UntranslatedStep{
+		{Step: "default.1.1", Rule: "step_default_1_1", Label: "Approval", Reason: "action my_action can't be evaluated in Rego, so it is never complete"},
+	}, got.Untranslated)
+
+	_, err = g.ExportRego("approved", "glide.workflow")
+	assert.Error(t, err)
+}
+
+func TestGraph_ExportRego_Checks(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"group":   {Type: jsoncel.String},
+			"level":   {Type: jsoncel.Integer},
+			"score":   {Type: jsoncel.Number},
+			"blocked": {Type: jsoncel.Boolean},
+			"groups":  {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+			"labels":  {Type: jsoncel.Object, Properties: map[string]*jsoncel.Schema{"team": {Type: jsoncel.String}}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		give string
+		// want is the body of the check's rule, after its predecessor.
+		want string
+		// wantHelpers are the helper rules written after the check's rule.
+		wantHelpers string
+		// wantReason is set if the check can't be translated.
+		wantReason string
+	}{
+		{
+			name: "and",
+			give: `input.group == "admins" && input.level >= 2`,
+			want: "\tinput.group == \"admins\"\n\tinput.level >= 2\n",
+		},
+		{
+			name:        "or",
+			give:        `input.group == "admins" || input.group == "ops" || !input.blocked`,
+			want:        "\tstep_default_1_cond_1\n",
+			wantHelpers: "\nstep_default_1_cond_1 if {\n\tinput.group == \"admins\"\n}\n\nstep_default_1_cond_1 if {\n\tinput.group == \"ops\"\n}\n\nstep_default_1_cond_1 if {\n\tnot input.blocked\n}\n",
+		},
+		{
+			name:        "negated and",
+			give:        `!(input.blocked && input.level > 1)`,
+			want:        "\tnot step_default_1_cond_1\n",
+			wantHelpers: "\nstep_default_1_cond_1 if {\n\tinput.blocked\n\tinput.level > 1\n}\n",
+		},
+		{
+			name: "in list",
+			give: `"admins" in input.groups`,
+			want: "\t\"admins\" in input.groups\n",
+		},
+		{
+			name: "in map",
+			give: `input.group in {"admins": true}`,
+			want: "\tinput.group in object.keys({\"admins\": true})\n",
+		},
+		{
+			name: "has",
+			give: `has(input.labels.team)`,
+			want: "\t\"team\" in object.keys(input.labels)\n",
+		},
+		{
+			name: "exists",
+			give: `input.groups.exists(g, g.startsWith("ops-") && g != "ops-banned")`,
+			want: "\tcount([true | some g in input.groups; startswith(g, \"ops-\"); g != \"ops-banned\"]) > 0\n",
+		},
+		{
+			name: "all",
+			give: `input.groups.all(g, g.matches("^[a-z]+$"))`,
+			want: "\tevery g in input.groups { regex.match(\"^[a-z]+$\", g) }\n",
+		},
+		{
+			name: "functions and arithmetic",
+			give: `size(input.groups) + 1 > 2 && input.group + "-x" == "a-x" && input.score / 2.0 < 1.5`,
+			want: "\t(count(input.groups) + 1) > 2\n\tconcat(\"\", [input.group, \"-x\"]) == \"a-x\"\n\t(input.score / 2) < 1.5\n",
+		},
+		{
+			name:       "integer division",
+			give:       `input.level / 2 == 1`,
+			wantReason: "integer division can't be translated",
+		},
+		{
+			name:       "conditional",
+			give:       `(input.blocked ? 1 : 2) == 1`,
+			wantReason: "conditional expressions can't be translated",
+		},
+		{
+			name:       "or inside exists",
+			give:       `input.groups.exists(g, g == "a" || g == "b")`,
+			wantReason: "'||' and negated expressions inside 'exists' and 'all' can't be translated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{
+				Program: SimpleProgram(
+					s.Start("request"),
+					s.Check(tt.give),
+					s.Named("Approved").Priority(1).Outcome("approved"),
+				),
+				InputSchema: schema,
+			}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.ExportRego("request", "glide.workflow")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantReason != "" {
+				assert.Equal(t, []UntranslatedStep{{Step: "default.1", Rule: "step_default_1", Label: "if: " + tt.give, Reason: tt.wantReason}}, got.Untranslated)
+				assert.NotContains(t, got.Module, "step_default_1 if")
+				return
+			}
+			assert.Empty(t, got.Untranslated)
+			assert.Contains(t, got.Module, "\nstep_default_1 if {\n\tstep_request\n"+tt.want+"}\n"+tt.wantHelpers+"\n# approved")
+		})
+	}
+}
+
+func TestGraph_ExportRego_Schedule(t *testing.T) {
+	p := SimpleProgram(
+		s.Start("request"),
+		(&s.StepBuilder{}).When("mon-fri 09:00-17:00").Check("true"),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)
+	g, err := (&Compiler{Program: p}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.ExportRego("request", "glide.workflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, got.Untranslated, 1) {
+		assert.Equal(t, "default.1", got.Untranslated[0].Step)
+		assert.Equal(t, "steps with a schedule (mon-fri 09:00-17:00) depend on the time of the execution, so they are never complete in Rego", got.Untranslated[0].Reason)
+	}
+	assert.NotContains(t, got.Module, "step_default_1 if")
+}
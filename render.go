@@ -0,0 +1,241 @@
+package glide
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// stateColors are the colours used to shade steps by their state when rendering.
+var stateColors = map[State]string{
+	Complete:    "#00FF00",
+	Active:      "#89CFF0",
+	Unknown:     "#D3D3D3",
+	Failed:      "#FF6961",
+	Unreachable: "#A9A9A9",
+	Skipped:     "#F5F5F5",
+}
+
+// errorColor is the colour used to shade steps which could not be evaluated.
+const errorColor = "#FFA500"
+
+// completionColor is the colour of the edges in the Completion Graph
+// of a result, which completion was carried along.
+const completionColor = "#008000"
+
+// render writes the graph in DOT format, shading steps by their state in the result.
+// Edges in the result's Completion Graph are drawn bold and coloured, so that
+// the paths which completed stand out from the paths which merely exist.
+//
+// Steps are written in the order of their hashes, each followed by the edges out of it,
+// so that rendering the same graph always produces the same output.
+// The attributes are written to the output rather than set on G,
+// so that rendering doesn't modify the graph.
+func (g *Graph) render(w io.Writer, res *Result) error {
+	// results of executions which were expanded for the input are
+	// rendered on the graph which was executed.
+	if res != nil && res.graph != nil && res.graph != g {
+		eg, r := g.expandedFor(res)
+		return eg.render(w, r)
+	}
+
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return err
+	}
+
+	stepErrs := map[string]bool{}
+	if res != nil {
+		for _, se := range res.StepErrors {
+			stepErrs[se.Step] = true
+		}
+	}
+
+	edges, err := g.store.edges()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("strict digraph {\n")
+
+	for _, k := range hashes {
+		s, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+
+		attrs := map[string]string{"label": s.Debug()}
+		if s.Description != "" {
+			attrs["tooltip"] = dotEscape(s.Description)
+		}
+		if state, ok := res.state(k); ok {
+			attrs["style"] = "filled"
+			if color, ok := stateColors[state]; ok {
+				attrs["fillcolor"] = color
+			}
+		}
+		if stepErrs[k] {
+			attrs["style"] = "filled"
+			attrs["fillcolor"] = errorColor
+		}
+		for ak, av := range g.nodeAttributes[k] {
+			attrs[ak] = dotEscape(av)
+		}
+
+		fmt.Fprintf(&b, "\t\"%s\" [ %s ];\n", dotEscape(k), dotAttributes(attrs))
+
+		// edges are sorted by their source, so the edges out of
+		// this step are at the start of the remaining edges.
+		for len(edges) > 0 && edges[0].Source == k {
+			e := edges[0]
+			attrs := e.Attributes
+			if res.completed(e.Source, e.Target) {
+				attrs = completionAttributes(attrs)
+			}
+			fmt.Fprintf(&b, "\t\"%s\" -> \"%s\" [ %s ];\n", dotEscape(e.Source), dotEscape(e.Target), dotAttributes(attrs))
+			edges = edges[1:]
+		}
+	}
+
+	b.WriteString("}\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// completionAttributes returns a copy of the attributes of an edge
+// which carried completion, with the edge drawn bold and coloured.
+func completionAttributes(attrs map[string]string) map[string]string {
+	out := map[string]string{}
+	for k, v := range attrs {
+		out[k] = v
+	}
+	out["color"] = completionColor
+	if style := out["style"]; style != "" {
+		out["style"] = style + ",bold"
+	} else {
+		out["style"] = "bold"
+	}
+	return out
+}
+
+// dotAttributes formats DOT attributes, sorted by their key.
+// Values are written as they are, as labels are already escaped.
+func dotAttributes(attrs map[string]string) string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=\"%s\", ", k, attrs[k])
+	}
+	b.WriteString("weight=0")
+	return b.String()
+}
+
+// renderMermaid writes the graph as a Mermaid flowchart,
+// shading steps by their state in the result.
+func (g *Graph) renderMermaid(w io.Writer, res *Result) error {
+	// results of executions which were expanded for the input are
+	// rendered on the graph which was executed.
+	if res != nil && res.graph != nil && res.graph != g {
+		return res.graph.renderMermaid(w, res)
+	}
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return err
+	}
+
+	stepErrs := map[string]bool{}
+	if res != nil {
+		for _, se := range res.StepErrors {
+			stepErrs[se.Step] = true
+		}
+	}
+
+	// hashes can contain characters which aren't valid in
+	// Mermaid node IDs, so steps are numbered instead.
+	ids := map[string]string{}
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	for i, k := range hashes {
+		s, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+		id := fmt.Sprintf("s%d", i)
+		ids[k] = id
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, mermaidEscape(s.Debug()))
+		if s.Description != "" {
+			fmt.Fprintf(&b, "    %%%% %s\n", strings.ReplaceAll(s.Description, "\n", " "))
+		}
+
+		var color string
+		if state, ok := res.state(k); ok {
+			color = stateColors[state]
+		}
+		if stepErrs[k] {
+			color = errorColor
+		}
+		if color != "" {
+			fmt.Fprintf(&b, "    style %s fill:%s\n", id, color)
+		}
+	}
+
+	edges, err := g.store.edges()
+	if err != nil {
+		return err
+	}
+	for _, e := range edges {
+		arrow := "-->"
+		if e.Attributes["style"] == "dashed" {
+			arrow = "-.->"
+		}
+		if label := e.Attributes["label"]; label != "" {
+			arrow += "|" + mermaidEscape(label) + "|"
+		}
+		fmt.Fprintf(&b, "    %s %s %s\n", ids[e.Source], arrow, ids[e.Target])
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// dotEscape escapes text for use in a quoted DOT attribute.
+func dotEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// mermaidEscape escapes text for use in a Mermaid label.
+// Step labels escape quotes for DOT, so escaped quotes are replaced too.
+func mermaidEscape(s string) string {
+	r := strings.NewReplacer(`\"`, "#quot;", `"`, "#quot;", "|", "#124;", "\n", " ")
+	return r.Replace(s)
+}
+
+// state returns the state of a step in the result, if the result is not nil.
+func (r *Result) state(k string) (State, bool) {
+	if r == nil {
+		return Inactive, false
+	}
+	s, ok := r.State[k]
+	return s, ok
+}
+
+// completed returns true if the edge from source to target is in the
+// Completion Graph of the result, if the result is not nil.
+func (r *Result) completed(source, target string) bool {
+	if r == nil || r.CG == nil {
+		return false
+	}
+	_, err := r.CG.Edge(source, target)
+	return err == nil
+}
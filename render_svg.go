@@ -0,0 +1,193 @@
+package glide
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// SVG layout dimensions, in pixels.
+const (
+	svgCharWidth  = 7
+	svgNodePad    = 24
+	svgNodeHeight = 36
+	svgNodeGap    = 32
+	svgLayerGap   = 64
+	svgMargin     = 20
+)
+
+// svgNode is a step positioned in the SVG layout.
+type svgNode struct {
+	label string
+	// description is shown as a tooltip.
+	description string
+	layer       int
+	// x and y are the coordinates of the centre of the node.
+	x, y  float64
+	width float64
+}
+
+// renderSVG writes the graph as an SVG image, shading steps by their state in the result.
+//
+// Unlike rendering with Graphviz, the layout is calculated in Go, so no CGO dependency
+// is required. Steps are assigned to layers by their longest path from a start node,
+// and ordered within their layer to reduce edge crossings.
+func (g *Graph) renderSVG(w io.Writer, res *Result) error {
+	// results of executions which were expanded for the input are
+	// rendered on the graph which was executed.
+	if res != nil && res.graph != nil && res.graph != g {
+		return res.graph.renderSVG(w, res)
+	}
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return err
+	}
+	if len(order) == 0 {
+		_, err = io.WriteString(w, `<svg xmlns="http://www.w3.org/2000/svg" width="0" height="0"></svg>`+"\n")
+		return err
+	}
+
+	stepErrs := map[string]bool{}
+	if res != nil {
+		for _, se := range res.StepErrors {
+			stepErrs[se.Step] = true
+		}
+	}
+
+	nodes := map[string]*svgNode{}
+	preds := map[string][]edge{}
+	var layers [][]string
+
+	// assign each step to the layer after its deepest predecessor.
+	for _, k := range order {
+		s, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+		pres, err := g.store.predecessors(k)
+		if err != nil {
+			return err
+		}
+		preds[k] = pres
+
+		n := &svgNode{label: strings.ReplaceAll(s.Debug(), `\"`, `"`), description: s.Description}
+		for _, e := range pres {
+			if l := nodes[e.Source].layer + 1; l > n.layer {
+				n.layer = l
+			}
+		}
+		n.width = float64(len(n.label)*svgCharWidth + svgNodePad)
+		nodes[k] = n
+
+		for len(layers) <= n.layer {
+			layers = append(layers, nil)
+		}
+		layers[n.layer] = append(layers[n.layer], k)
+	}
+
+	// order each layer by the average position of the predecessors
+	// of its steps (the barycenter heuristic), to reduce edge crossings.
+	index := map[string]int{}
+	for _, layer := range layers {
+		for i, k := range layer {
+			index[k] = i
+		}
+	}
+	for i := 1; i < len(layers); i++ {
+		layer := layers[i]
+		barycenter := map[string]float64{}
+		for _, k := range layer {
+			var sum float64
+			for _, e := range preds[k] {
+				sum += float64(index[e.Source])
+			}
+			barycenter[k] = sum / float64(len(preds[k]))
+		}
+		sort.SliceStable(layer, func(i, j int) bool {
+			return barycenter[layer[i]] < barycenter[layer[j]]
+		})
+		for i, k := range layer {
+			index[k] = i
+		}
+	}
+
+	// position the steps, centring each layer horizontally.
+	var width float64
+	layerWidths := make([]float64, len(layers))
+	for i, layer := range layers {
+		for _, k := range layer {
+			layerWidths[i] += nodes[k].width
+		}
+		layerWidths[i] += float64(svgNodeGap * (len(layer) - 1))
+		if layerWidths[i] > width {
+			width = layerWidths[i]
+		}
+	}
+	for i, layer := range layers {
+		x := svgMargin + (width-layerWidths[i])/2
+		for _, k := range layer {
+			n := nodes[k]
+			n.x = x + n.width/2
+			n.y = float64(svgMargin + i*(svgNodeHeight+svgLayerGap) + svgNodeHeight/2)
+			x += n.width + svgNodeGap
+		}
+	}
+	height := float64(2*svgMargin + len(layers)*(svgNodeHeight+svgLayerGap) - svgLayerGap)
+	width += 2 * svgMargin
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Times,serif" font-size="12">`+"\n", width, height, width, height)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0 L10,5 L0,10 z"/></marker></defs>` + "\n")
+
+	// edges are drawn first, so that they are beneath the steps.
+	for _, k := range order {
+		for _, e := range preds[k] {
+			src, dst := nodes[e.Source], nodes[e.Target]
+			x1, y1 := src.x, src.y+svgNodeHeight/2
+			x2, y2 := dst.x, dst.y-svgNodeHeight/2
+			midY := (y1 + y2) / 2
+
+			dash := ""
+			switch e.Attributes["style"] {
+			case "dashed":
+				dash = ` stroke-dasharray="6,4"`
+			case "dotted":
+				dash = ` stroke-dasharray="2,3"`
+			}
+			fmt.Fprintf(&b, `<path d="M%.1f,%.1f C%.1f,%.1f %.1f,%.1f %.1f,%.1f" fill="none" stroke="black"%s marker-end="url(#arrow)"/>`+"\n", x1, y1, x1, midY, x2, midY, x2, y2, dash)
+
+			if label := e.Attributes["label"]; label != "" {
+				fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", (x1+x2)/2+4, midY, html.EscapeString(label))
+			}
+		}
+	}
+
+	for _, k := range order {
+		n := nodes[k]
+
+		fill := "white"
+		if state, ok := res.state(k); ok {
+			if color, ok := stateColors[state]; ok {
+				fill = color
+			}
+		}
+		if stepErrs[k] {
+			fill = errorColor
+		}
+
+		fmt.Fprintf(&b, `<g id="%s">`, html.EscapeString(k))
+		if n.description != "" {
+			fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(n.description))
+		}
+		fmt.Fprintf(&b, `<ellipse cx="%.1f" cy="%.1f" rx="%.1f" ry="%d" fill="%s" stroke="black"/>`, n.x, n.y, n.width/2, svgNodeHeight/2, fill)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central">%s</text>`, n.x, n.y, html.EscapeString(n.label))
+		b.WriteString("</g>\n")
+	}
+
+	b.WriteString("</svg>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,84 @@
+package glide
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Event is the input to a workflow at a point in time,
+// such as after an approval was received.
+type Event struct {
+	Time time.Time
+	// Input is the complete input at the time of the event,
+	// rather than only the fields which changed.
+	Input map[string]any
+}
+
+// TimelineEntry is the result of executing a workflow with the input of an event.
+type TimelineEntry struct {
+	// Time is the time of the event.
+	Time time.Time
+
+	// Result of executing the workflow with the event's input.
+	// Steps which could not be evaluated are recorded in its StepErrors.
+	Result *Result
+
+	// Changes maps the hashes of the steps whose state changed since
+	// the previous entry to their new state. In the first entry, it contains
+	// every step which isn't Inactive.
+	Changes map[string]State
+}
+
+// Replay executes a workflow with the input of each event in order,
+// returning the timeline of how the workflow progressed. It can be used to
+// build an audit log of how a request progressed through its approval stages.
+//
+// The internal state of Stateful actions and the timers of waits are carried from
+// each execution to the next, and each event is executed at its time, as it would be
+// if the workflow had been executed as the events occurred.
+// Events must be ordered by their time.
+func Replay(g *Graph, start string, events []Event, opts ...ExecuteOption) ([]TimelineEntry, error) {
+	var timeline []TimelineEntry
+	var prev *Result
+
+	for i, e := range events {
+		if i > 0 && e.Time.Before(events[i-1].Time) {
+			return nil, fmt.Errorf("event %d at %s is before the previous event at %s: events must be ordered by time", i, e.Time.Format(time.RFC3339), events[i-1].Time.Format(time.RFC3339))
+		}
+
+		eventOpts := append(append([]ExecuteOption{}, opts...), WithTime(e.Time))
+		if prev != nil {
+			eventOpts = append(eventOpts, WithActionState(prev.ActionState), WithTimers(prev.Timers))
+		}
+
+		res, err := g.Execute(start, e.Input, eventOpts...)
+		var ee *ExecutionError
+		if err != nil && !errors.As(err, &ee) {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+
+		entry := TimelineEntry{Time: e.Time, Result: res, Changes: map[string]State{}}
+		for k, s := range res.State {
+			if prev == nil {
+				if s != Inactive {
+					entry.Changes[k] = s
+				}
+				continue
+			}
+			if prev.State[k] != s {
+				entry.Changes[k] = s
+			}
+		}
+
+		timeline = append(timeline, entry)
+		prev = res
+	}
+
+	return timeline, nil
+}
+
+// Replay executes the workflow with the input of each event in order. See Replay.
+func (c *Compiled) Replay(start string, events []Event, opts ...ExecuteOption) ([]TimelineEntry, error) {
+	return Replay(c.g, start, events, opts...)
+}
@@ -0,0 +1,93 @@
+package glide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.Check("input.manager"),
+			s.Check("input.security"),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+		InputSchema: &jsoncel.Schema{
+			Properties: map[string]*jsoncel.Schema{
+				"manager":  {Type: jsoncel.Boolean},
+				"security": {Type: jsoncel.Boolean},
+			},
+		},
+	}
+	g, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: t0, Input: map[string]any{"manager": false, "security": false}},
+		{Time: t0.Add(time.Hour), Input: map[string]any{"manager": true, "security": false}},
+		{Time: t0.Add(2 * time.Hour), Input: map[string]any{"manager": true, "security": true}},
+	}
+
+	got, err := g.Replay("request", events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var outcomes []string
+	var changes []map[string]State
+	for i, e := range got {
+		assert.Equal(t, events[i].Time, e.Time)
+		outcomes = append(outcomes, e.Result.Outcome)
+		changes = append(changes, e.Changes)
+	}
+
+	assert.Equal(t, []string{"", "", "approved"}, outcomes)
+	assert.Equal(t, []map[string]State{
+		{"request": Complete},
+		{"default.1": Complete},
+		{"default.2": Complete, "approved": Complete},
+	}, changes)
+}
+
+func TestReplay_ActionState(t *testing.T) {
+	c := Compiler{
+		Program: SimpleProgram(
+			s.Start("request"),
+			s.WithID("remind").Action("remind", &testReminderAction{required: 2}),
+			s.Named("Approved").Priority(1).Outcome("approved"),
+		),
+	}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	got, err := Replay(g, "request", []Event{{Time: t0}, {Time: t0.Add(time.Hour)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the reminder count is carried from the first execution to the second.
+	assert.Equal(t, "", got[0].Result.Outcome)
+	assert.Equal(t, "approved", got[1].Result.Outcome)
+}
+
+func TestReplay_Unordered(t *testing.T) {
+	g, err := (&Compiler{Program: SimpleProgram(s.Start("request"), s.Outcome("approved"))}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	_, err = Replay(g, "request", []Event{{Time: t0}, {Time: t0.Add(-time.Hour)}})
+	assert.EqualError(t, err, "event 1 at 2023-01-01T08:00:00Z is before the previous event at 2023-01-01T09:00:00Z: events must be ordered by time")
+}
@@ -0,0 +1,396 @@
+package glide
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+)
+
+// MarshalText marshals the state as a string, e.g. "complete".
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a state from a string, e.g. "complete".
+func (s *State) UnmarshalText(text []byte) error {
+	for _, st := range []State{Inactive, Complete, Active, Unknown, Failed, Unreachable, Skipped} {
+		if st.String() == string(text) {
+			*s = st
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid state %q", string(text))
+}
+
+// resultJSON is the JSON representation of a Result.
+// Fields are added rather than changed, so that the representation is stable
+// for services which return execution results over their APIs.
+type resultJSON struct {
+	Outcome         *outcomeJSON               `json:"outcome"`
+	Outcomes        []string                   `json:"outcomes,omitempty"`
+	ReachedOutcomes []outcomeJSON              `json:"reachedOutcomes,omitempty"`
+	DefaultOutcome  bool                       `json:"defaultOutcome,omitempty"`
+	Failed          bool                       `json:"failed"`
+	State           map[string]State           `json:"state"`
+	Edges           [][2]string                `json:"edges"`
+	Errors          map[string]string          `json:"errors,omitempty"`
+	ActionErrors    map[string]actionErrorJSON `json:"actionErrors,omitempty"`
+	Effects         map[string]any             `json:"effects,omitempty"`
+	StepErrors      []stepErrorJSON            `json:"stepErrors,omitempty"`
+	Checks          map[string]checkJSON       `json:"checks,omitempty"`
+	UnknownFields   []string                   `json:"unknownFields,omitempty"`
+	FirstCompleted  map[string]int             `json:"firstCompleted,omitempty"`
+	Reasons         []Reason                   `json:"reasons,omitempty"`
+	ActionState     map[string][]byte          `json:"actionState,omitempty"`
+	Pending         []pendingJSON              `json:"pending,omitempty"`
+	Timers          map[string]time.Time       `json:"timers,omitempty"`
+	Gated           []gatedJSON                `json:"gated,omitempty"`
+	Locale          string                     `json:"locale,omitempty"`
+}
+
+type outcomeJSON struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name,omitempty"`
+	Names    map[string]string `json:"names,omitempty"`
+	Priority int               `json:"priority"`
+	Metadata map[string]any    `json:"metadata,omitempty"`
+}
+
+func newOutcomeJSON(n node.Node) outcomeJSON {
+	return outcomeJSON{
+		ID:       n.ID,
+		Name:     n.Name,
+		Names:    n.Names,
+		Priority: n.Priority,
+		Metadata: n.Metadata,
+	}
+}
+
+// pendingJSON is the JSON representation of a PendingAction.
+// Schedules are marshalled as whole seconds, and omitted if not set.
+type pendingJSON struct {
+	Step          string `json:"step"`
+	Label         string `json:"label"`
+	Action        string `json:"action"`
+	RemindEvery   int64  `json:"remindEvery,omitempty"`
+	EscalateAfter int64  `json:"escalateAfter,omitempty"`
+}
+
+// gatedJSON is the JSON representation of a GatedStep.
+// The time the schedule opens is omitted if it doesn't open in the next year.
+type gatedJSON struct {
+	Step  string     `json:"step"`
+	When  string     `json:"when"`
+	Opens *time.Time `json:"opens,omitempty"`
+}
+
+// actionErrorJSON is the JSON representation of an ActionError.
+type actionErrorJSON struct {
+	Kind   ActionErrorKind `json:"kind"`
+	Method string          `json:"method"`
+	Error  string          `json:"error"`
+}
+
+// checkJSON is the JSON representation of an EvaluatedCheck.
+type checkJSON struct {
+	Expression string `json:"expression"`
+	Value      string `json:"value"`
+	Error      string `json:"error,omitempty"`
+}
+
+type stepErrorJSON struct {
+	Step  string `json:"step"`
+	Error string `json:"error"`
+}
+
+// MarshalJSON marshals the result in a stable format which matches ResultSchema.
+//
+// States are marshalled as strings, and the edges of the Completion Graph
+// are marshalled as sorted [source, target] pairs.
+func (r Result) MarshalJSON() ([]byte, error) {
+	out := resultJSON{
+		Outcomes:       r.Outcomes,
+		DefaultOutcome: r.DefaultOutcome,
+		Failed:         r.Failed,
+		State:          r.State,
+		Edges:          [][2]string{},
+		Effects:        r.Effects,
+		UnknownFields:  r.UnknownFields,
+		FirstCompleted: r.FirstCompleted,
+		Reasons:        r.Reasons,
+		ActionState:    r.ActionState,
+		Timers:         r.Timers,
+		Locale:         r.Locale,
+	}
+
+	if out.State == nil {
+		out.State = map[string]State{}
+	}
+
+	if r.OutcomeNode != nil {
+		o := newOutcomeJSON(*r.OutcomeNode)
+		out.Outcome = &o
+	} else if r.Outcome != "" {
+		out.Outcome = &outcomeJSON{ID: r.Outcome}
+	}
+
+	for _, n := range r.ReachedOutcomes {
+		out.ReachedOutcomes = append(out.ReachedOutcomes, newOutcomeJSON(n))
+	}
+
+	if r.CG != nil {
+		adj, err := r.CG.AdjacencyMap()
+		if err != nil {
+			return nil, err
+		}
+		for source, targets := range adj {
+			for target := range targets {
+				out.Edges = append(out.Edges, [2]string{source, target})
+			}
+		}
+		sort.Slice(out.Edges, func(i, j int) bool {
+			if out.Edges[i][0] != out.Edges[j][0] {
+				return out.Edges[i][0] < out.Edges[j][0]
+			}
+			return out.Edges[i][1] < out.Edges[j][1]
+		})
+	}
+
+	if len(r.Errors) > 0 {
+		out.Errors = map[string]string{}
+		for k, err := range r.Errors {
+			out.Errors[k] = err.Error()
+
+			var ae *ActionError
+			if errors.As(err, &ae) {
+				if out.ActionErrors == nil {
+					out.ActionErrors = map[string]actionErrorJSON{}
+				}
+				out.ActionErrors[k] = actionErrorJSON{Kind: ae.Kind, Method: ae.Method, Error: err.Error()}
+			}
+		}
+	}
+
+	for _, se := range r.StepErrors {
+		out.StepErrors = append(out.StepErrors, stepErrorJSON{Step: se.Step, Error: se.Err.Error()})
+	}
+
+	for k, c := range r.Checks {
+		if out.Checks == nil {
+			out.Checks = map[string]checkJSON{}
+		}
+		cj := checkJSON{Expression: c.Expression, Value: c.Value.String()}
+		if c.Err != nil {
+			cj.Error = c.Err.Error()
+		}
+		out.Checks[k] = cj
+	}
+
+	for _, p := range r.Pending {
+		out.Pending = append(out.Pending, pendingJSON{
+			Step:          p.Step,
+			Label:         p.Label,
+			Action:        p.Action,
+			RemindEvery:   int64(p.RemindEvery / time.Second),
+			EscalateAfter: int64(p.EscalateAfter / time.Second),
+		})
+	}
+
+	for _, gs := range r.Gated {
+		g := gatedJSON{Step: gs.Step, When: gs.When}
+		if !gs.Opens.IsZero() {
+			opens := gs.Opens
+			g.Opens = &opens
+		}
+		out.Gated = append(out.Gated, g)
+	}
+
+	return json.Marshal(out)
+}
+
+// ResultSchema returns the JSON Schema of a marshalled Result.
+func ResultSchema() *jsoncel.Schema {
+	states := []any{}
+	for _, s := range []State{Inactive, Complete, Active, Unknown, Failed, Unreachable, Skipped} {
+		states = append(states, s.String())
+	}
+
+	outcome := &jsoncel.Schema{
+		Type:     jsoncel.Object,
+		Required: []string{"id", "priority"},
+		Properties: map[string]*jsoncel.Schema{
+			"id":   {Type: jsoncel.String},
+			"name": {Type: jsoncel.String},
+			"names": {
+				Type:                 jsoncel.Object,
+				AdditionalProperties: &jsoncel.Schema{Type: jsoncel.String},
+			},
+			"priority": {Type: jsoncel.Integer},
+			"metadata": {Type: jsoncel.Object},
+		},
+	}
+
+	return &jsoncel.Schema{
+		Version:  jsoncel.Version,
+		Title:    "Result",
+		Type:     jsoncel.Object,
+		Required: []string{"outcome", "failed", "state", "edges"},
+		Properties: map[string]*jsoncel.Schema{
+			"outcome": {
+				Description: "The outcome of the workflow, or null if the workflow has not reached an outcome.",
+				AnyOf:       []*jsoncel.Schema{{Type: jsoncel.Null}, outcome},
+			},
+			"reachedOutcomes": {
+				Description: "The outcomes which were complete, in the order they were reached.",
+				Type:        jsoncel.Array,
+				Items:       outcome,
+			},
+			"outcomes": {
+				Description: "The IDs of the outcomes selected by the outcome strategy, starting with the outcome.",
+				Type:        jsoncel.Array,
+				Items:       &jsoncel.Schema{Type: jsoncel.String},
+			},
+			"defaultOutcome": {
+				Description: "True if no outcome was reached, and the outcome is the default outcome of the workflow.",
+				Type:        jsoncel.Boolean,
+			},
+			"failed": {
+				Description: "True if an action failed and its on_fail behaviour is to fail the workflow.",
+				Type:        jsoncel.Boolean,
+			},
+			"state": {
+				Description:          "The state of each step, keyed by step ID.",
+				Type:                 jsoncel.Object,
+				AdditionalProperties: &jsoncel.Schema{Type: jsoncel.String, Enum: states},
+			},
+			"edges": {
+				Description: "The edges of the Completion Graph, as [source, target] pairs.",
+				Type:        jsoncel.Array,
+				Items: &jsoncel.Schema{
+					Type:        jsoncel.Array,
+					PrefixItems: []*jsoncel.Schema{{Type: jsoncel.String}, {Type: jsoncel.String}},
+					MinItems:    2,
+					MaxItems:    2,
+				},
+			},
+			"errors": {
+				Description:          "The errors returned by failed actions, keyed by step ID.",
+				Type:                 jsoncel.Object,
+				AdditionalProperties: &jsoncel.Schema{Type: jsoncel.String},
+			},
+			"actionErrors": {
+				Description: "How each action in errors failed, keyed by step ID: whether it returned an error, panicked or timed out.",
+				Type:        jsoncel.Object,
+				AdditionalProperties: &jsoncel.Schema{
+					Type:     jsoncel.Object,
+					Required: []string{"kind", "method", "error"},
+					Properties: map[string]*jsoncel.Schema{
+						"kind":   {Type: jsoncel.String, Enum: []any{string(ActionReturnedError), string(ActionPanicked), string(ActionTimedOut)}},
+						"method": {Type: jsoncel.String},
+						"error":  {Type: jsoncel.String},
+					},
+				},
+			},
+			"effects": {
+				Description: "The side effects of active actions for the host application to carry out, keyed by step ID.",
+				Type:        jsoncel.Object,
+			},
+			"stepErrors": {
+				Description: "The errors which occurred when evaluating steps.",
+				Type:        jsoncel.Array,
+				Items: &jsoncel.Schema{
+					Type:     jsoncel.Object,
+					Required: []string{"step", "error"},
+					Properties: map[string]*jsoncel.Schema{
+						"step":  {Type: jsoncel.String},
+						"error": {Type: jsoncel.String},
+					},
+				},
+			},
+			"checks": {
+				Description: "The values which checks and 'if' guards evaluated to, keyed by step ID. Checks which weren't evaluated are omitted.",
+				Type:        jsoncel.Object,
+				AdditionalProperties: &jsoncel.Schema{
+					Type:     jsoncel.Object,
+					Required: []string{"expression", "value"},
+					Properties: map[string]*jsoncel.Schema{
+						"expression": {Type: jsoncel.String},
+						"value":      {Type: jsoncel.String, Enum: []any{CheckTrue.String(), CheckFalse.String(), CheckUnknown.String(), CheckError.String()}},
+						"error":      {Type: jsoncel.String},
+					},
+				},
+			},
+			"unknownFields": {
+				Description: "The missing input fields whose value could change the outcome.",
+				Type:        jsoncel.Array,
+				Items:       &jsoncel.Schema{Type: jsoncel.String},
+			},
+			"reasons": {
+				Description: "The completed checks and actions on the path to the outcome.",
+				Type:        jsoncel.Array,
+				Items: &jsoncel.Schema{
+					Type:     jsoncel.Object,
+					Required: []string{"step", "label"},
+					Properties: map[string]*jsoncel.Schema{
+						"step":       {Type: jsoncel.String},
+						"label":      {Type: jsoncel.String},
+						"expression": {Type: jsoncel.String},
+						"action":     {Type: jsoncel.String},
+					},
+				},
+			},
+			"actionState": {
+				Description:          "The internal state of stateful actions, base64 encoded and keyed by step ID.",
+				Type:                 jsoncel.Object,
+				AdditionalProperties: &jsoncel.Schema{Type: jsoncel.String},
+			},
+			"pending": {
+				Description: "The actions which are active but not complete, with their reminder and escalation schedules in seconds.",
+				Type:        jsoncel.Array,
+				Items: &jsoncel.Schema{
+					Type:     jsoncel.Object,
+					Required: []string{"step", "label", "action"},
+					Properties: map[string]*jsoncel.Schema{
+						"step":          {Type: jsoncel.String},
+						"label":         {Type: jsoncel.String},
+						"action":        {Type: jsoncel.String},
+						"remindEvery":   {Type: jsoncel.Integer},
+						"escalateAfter": {Type: jsoncel.Integer},
+					},
+				},
+			},
+			"timers": {
+				Description:          "The time each wait before an escalation became active, keyed by step ID.",
+				Type:                 jsoncel.Object,
+				AdditionalProperties: &jsoncel.Schema{Type: jsoncel.String, Format: "date-time"},
+			},
+			"gated": {
+				Description: "The steps which weren't activated because it's outside of the schedule in their 'when' field.",
+				Type:        jsoncel.Array,
+				Items: &jsoncel.Schema{
+					Type:     jsoncel.Object,
+					Required: []string{"step", "when"},
+					Properties: map[string]*jsoncel.Schema{
+						"step":  {Type: jsoncel.String},
+						"when":  {Type: jsoncel.String},
+						"opens": {Type: jsoncel.String, Format: "date-time"},
+					},
+				},
+			},
+			"locale": {
+				Description: "The locale which the names and labels in the result were localized in.",
+				Type:        jsoncel.String,
+			},
+			"firstCompleted": {
+				Description:          "The index of the result in which each step first became complete, for aggregated results.",
+				Type:                 jsoncel.Object,
+				AdditionalProperties: &jsoncel.Schema{Type: jsoncel.Integer},
+			},
+		},
+	}
+}
@@ -0,0 +1,140 @@
+package glide
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		give  *Program
+		input map[string]any
+		want  string
+	}{
+		{
+			name: "outcome",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("true"),
+				step.Step{Body: step.Ref{Node: node.Node{
+					Type:     node.Outcome,
+					ID:       "approved",
+					Name:     "Approved",
+					Priority: 1,
+					Metadata: map[string]any{"sla": "4h"},
+				}}},
+			),
+			want: `{
+				"outcome": {"id": "approved", "name": "Approved", "priority": 1, "metadata": {"sla": "4h"}},
+				"outcomes": ["approved"],
+				"reachedOutcomes": [{"id": "approved", "name": "Approved", "priority": 1, "metadata": {"sla": "4h"}}],
+				"failed": false,
+				"state": {"request": "complete", "default.1": "complete", "approved": "complete"},
+				"edges": [["default.1", "approved"], ["request", "default.1"]],
+				"reasons": [{"step": "default.1", "label": "true", "expression": "true"}],
+				"checks": {"default.1": {"expression": "true", "value": "true"}}
+			}`,
+		},
+		{
+			name: "no outcome",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("false"),
+				s.Outcome("approved"),
+			),
+			want: `{
+				"outcome": null,
+				"failed": false,
+				"state": {"request": "complete", "default.1": "inactive", "approved": "inactive"},
+				"edges": [["request", "default.1"]],
+				"checks": {"default.1": {"expression": "false", "value": "false"}}
+			}`,
+		},
+		{
+			name: "failed action",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.WithID("notify").Action("notify", &testFailAction{err: errors.New("unavailable")}),
+				s.Outcome("approved"),
+			),
+			want: `{
+				"outcome": null,
+				"failed": true,
+				"state": {"request": "complete", "default.notify": "failed", "approved": "unreachable"},
+				"edges": [["request", "default.notify"]],
+				"errors": {"default.notify": "unavailable"},
+				"actionErrors": {"default.notify": {"kind": "error", "method": "Complete", "error": "unavailable"}}
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestState_UnmarshalText(t *testing.T) {
+	for _, want := range []State{Inactive, Complete, Active, Unknown, Failed} {
+		text, err := want.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got State
+		err = got.UnmarshalText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, want, got)
+	}
+
+	var s State
+	assert.Error(t, s.UnmarshalText([]byte("other")))
+}
+
+func TestResultSchema(t *testing.T) {
+	res := Result{State: map[string]State{"request": Complete}}
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	err = json.Unmarshal(b, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := ResultSchema()
+
+	// every marshalled field must be described by the schema.
+	for k := range got {
+		assert.Contains(t, schema.Properties, k)
+	}
+	for _, k := range schema.Required {
+		assert.Contains(t, got, k)
+	}
+}
@@ -0,0 +1,152 @@
+package glide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ResultSchemaVersion is the version of the persisted result format
+// which this version of glide writes. See ResultV1.
+const ResultSchemaVersion = 1
+
+// ResultV1 is version 1 of the format for persisting the result of an execution,
+// so that a long-running workflow can be continued by a later version of glide.
+//
+// Fields are only ever added to a version of the format, and never renamed,
+// retyped or removed, and fields which a reader doesn't know are ignored.
+// A change which can't be made by adding a field introduces a new version,
+// along with a converter from the previous version, and ParseStoredResult
+// upgrades results stored in any earlier version.
+type ResultV1 struct {
+	// SchemaVersion is always 1.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// Outcome is the ID of the primary outcome, or empty if
+	// the workflow hasn't reached an outcome.
+	Outcome string `json:"outcome,omitempty"`
+
+	// Outcomes are the IDs of all the selected outcomes.
+	Outcomes []string `json:"outcomes,omitempty"`
+
+	// DefaultOutcome is true if the outcome is the workflow's default outcome.
+	DefaultOutcome bool `json:"defaultOutcome,omitempty"`
+
+	// Failed is true if an action failed the workflow.
+	Failed bool `json:"failed,omitempty"`
+
+	// State maps the hashes of steps to their state, e.g. "complete".
+	State map[string]State `json:"state"`
+
+	// Errors maps the hashes of failed actions to their error messages.
+	Errors map[string]string `json:"errors,omitempty"`
+
+	// ActionState maps the hashes of Stateful actions to their internal state.
+	ActionState map[string][]byte `json:"actionState,omitempty"`
+
+	// Timers maps the hashes of waits to the time they became active.
+	Timers map[string]time.Time `json:"timers,omitempty"`
+
+	// UnknownFields are the missing input fields which could change the outcome.
+	UnknownFields []string `json:"unknownFields,omitempty"`
+}
+
+// Store returns the result in the current version of the persisted result format.
+func (r *Result) Store() *ResultV1 {
+	out := ResultV1{
+		SchemaVersion:  1,
+		Outcome:        r.Outcome,
+		Outcomes:       r.Outcomes,
+		DefaultOutcome: r.DefaultOutcome,
+		Failed:         r.Failed,
+		State:          r.State,
+		ActionState:    r.ActionState,
+		Timers:         r.Timers,
+		UnknownFields:  r.UnknownFields,
+	}
+	if len(r.Errors) > 0 {
+		out.Errors = map[string]string{}
+		for k, err := range r.Errors {
+			out.Errors[k] = err.Error()
+		}
+	}
+	return &out
+}
+
+// ExecuteOptions returns the options to continue the workflow from the stored
+// result in the next execution, restoring the state of actions and timers.
+func (r *ResultV1) ExecuteOptions() []ExecuteOption {
+	return []ExecuteOption{WithActionState(r.ActionState), WithTimers(r.Timers)}
+}
+
+// ParseStoredResult parses a stored result, upgrading it to the current version
+// of the persisted result format. Besides each version of the format, it accepts
+// results which were stored before the format was versioned: the JSON of a
+// Result, and a JSON object mapping step hashes to their states.
+func ParseStoredResult(data []byte) (*ResultV1, error) {
+	var header struct {
+		SchemaVersion *int `json:"schemaVersion"`
+	}
+	err := json.Unmarshal(data, &header)
+	if err != nil {
+		return nil, fmt.Errorf("parsing stored result: %w", err)
+	}
+	if header.SchemaVersion == nil {
+		return parseUnversionedResult(data)
+	}
+
+	switch *header.SchemaVersion {
+	case 1:
+		var r ResultV1
+		err = json.Unmarshal(data, &r)
+		if err != nil {
+			return nil, fmt.Errorf("parsing stored result version 1: %w", err)
+		}
+		return &r, nil
+	}
+	return nil, fmt.Errorf("stored result has version %d, but this version of glide only supports versions up to %d", *header.SchemaVersion, ResultSchemaVersion)
+}
+
+// parseUnversionedResult upgrades a result which was stored before the format
+// was versioned, either as the JSON of a Result or as a map of step states.
+func parseUnversionedResult(data []byte) (*ResultV1, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("parsing stored result: %w", err)
+	}
+
+	// the JSON of a Result has an object of states, whereas
+	// a map of states has a string for every step.
+	if raw, ok := fields["state"]; ok && bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		var legacy resultJSON
+		err = json.Unmarshal(data, &legacy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing stored result: %w", err)
+		}
+
+		r := ResultV1{
+			SchemaVersion:  1,
+			Outcomes:       legacy.Outcomes,
+			DefaultOutcome: legacy.DefaultOutcome,
+			Failed:         legacy.Failed,
+			State:          legacy.State,
+			Errors:         legacy.Errors,
+			ActionState:    legacy.ActionState,
+			Timers:         legacy.Timers,
+			UnknownFields:  legacy.UnknownFields,
+		}
+		if legacy.Outcome != nil {
+			r.Outcome = legacy.Outcome.ID
+		}
+		return &r, nil
+	}
+
+	var state map[string]State
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, fmt.Errorf("parsing stored result as a map of step states: %w", err)
+	}
+	return &ResultV1{SchemaVersion: 1, State: state}, nil
+}
@@ -0,0 +1,96 @@
+package glide
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultV1_RoundTrip(t *testing.T) {
+	c := Compiler{Program: SimpleProgram(
+		s.Start("request"),
+		s.Check("true"),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(res.Store())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{
+		"schemaVersion": 1,
+		"outcome": "approved",
+		"outcomes": ["approved"],
+		"state": {"request": "complete", "default.1": "complete", "approved": "complete"}
+	}`, string(data))
+
+	got, err := ParseStoredResult(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, res.Store(), got)
+
+	// results stored before the format was versioned are upgraded.
+	legacy, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = ParseStoredResult(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, res.Store(), got)
+}
+
+func TestParseStoredResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		want    *ResultV1
+		wantErr string
+	}{
+		{
+			name: "map of states",
+			give: `{"request": "complete", "default.1": "active"}`,
+			want: &ResultV1{SchemaVersion: 1, State: map[string]State{"request": Complete, "default.1": Active}},
+		},
+		{
+			name: "unknown fields are ignored",
+			give: `{"schemaVersion": 1, "state": {"request": "complete"}, "addedLater": true}`,
+			want: &ResultV1{SchemaVersion: 1, State: map[string]State{"request": Complete}},
+		},
+		{
+			name:    "newer version",
+			give:    `{"schemaVersion": 2, "state": {}}`,
+			wantErr: "stored result has version 2, but this version of glide only supports versions up to 1",
+		},
+		{
+			name:    "invalid state",
+			give:    `{"request": "done"}`,
+			wantErr: `parsing stored result as a map of step states: invalid state "done"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStoredResult([]byte(tt.give))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package glide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ActionErrorKind classifies the errors from calling an action.
+type ActionErrorKind string
+
+const (
+	// ActionReturnedError is an error which the action returned.
+	ActionReturnedError ActionErrorKind = "error"
+
+	// ActionPanicked is a panic in the action, which was recovered.
+	ActionPanicked ActionErrorKind = "panic"
+
+	// ActionTimedOut is an action which didn't return before its timeout.
+	ActionTimedOut ActionErrorKind = "timeout"
+)
+
+// ActionError is an error from calling one of the methods of an action.
+// The errors in Result.Errors are ActionErrors, so that a host application
+// can tell a buggy action apart from one which reported an error.
+type ActionError struct {
+	Kind ActionErrorKind
+
+	// Method is the method of the action which was called, e.g. 'Complete'.
+	Method string
+
+	// Err is the error returned by the action. For panics, it's the recovered
+	// value, and for timeouts it's context.DeadlineExceeded.
+	Err error
+
+	// Stack is the stack trace of the panic, for ActionPanicked errors.
+	Stack []byte
+}
+
+func (e *ActionError) Error() string {
+	switch e.Kind {
+	case ActionPanicked:
+		return fmt.Sprintf("action panicked in %s: %s", e.Method, e.Err)
+	case ActionTimedOut:
+		return fmt.Sprintf("action timed out in %s: %s", e.Method, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// Timeouter is implemented by actions which set their own timeout,
+// overriding the timeout set with WithActionTimeout. A timeout of zero
+// means that the action's methods aren't timed out.
+type Timeouter interface {
+	Timeout() time.Duration
+}
+
+// WithActionTimeout returns an ActionTimedOut error, failing the action,
+// if a method of an action doesn't return within d. The context passed
+// to the method is cancelled when it times out. A method which doesn't
+// return once its context is cancelled keeps running in the background,
+// its result is discarded, and no other methods of the action are called
+// during the execution.
+func WithActionTimeout(d time.Duration) ExecuteOption {
+	return func(o *executeOptions) {
+		o.actionTimeout = d
+	}
+}
+
+// timeoutFor returns the timeout for the methods of an action.
+func (o *executeOptions) timeoutFor(action any) time.Duration {
+	if t, ok := action.(Timeouter); ok {
+		return t.Timeout()
+	}
+	return o.actionTimeout
+}
+
+// callAction calls a method of an action, recovering from panics so that
+// a buggy action can't crash the process which is executing the workflow.
+// If the timeout is non-zero, the method is called in its own goroutine,
+// and an error is returned if it doesn't return within the timeout,
+// after cancelling the context passed to the method.
+// Calls is done once the method returns, even if it timed out.
+// Errors are returned as an *ActionError.
+func callAction[T any](ctx context.Context, calls *sync.WaitGroup, method string, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	calls.Add(1)
+	if timeout <= 0 {
+		defer calls.Done()
+		return recoverAction(ctx, method, fn)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer calls.Done()
+		v, err := recoverAction(ctx, method, fn)
+		done <- result{value: v, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		var zero T
+		return zero, &ActionError{Kind: ActionTimedOut, Method: method, Err: context.DeadlineExceeded}
+	}
+}
+
+// timedOut returns true if the error is from an action method which timed
+// out, in which case the method may still be running in the background.
+func timedOut(err error) bool {
+	var ae *ActionError
+	return errors.As(err, &ae) && ae.Kind == ActionTimedOut
+}
+
+// statefulLocks maps Stateful actions to the lock which is held while an
+// execution evaluates them, since their state is held on the action.
+// Locks are removed once no execution holds or is waiting for them.
+var statefulLocks = struct {
+	sync.Mutex
+	m map[statefulKey]*statefulLock
+}{m: map[statefulKey]*statefulLock{}}
+
+type statefulLock struct {
+	sync.Mutex
+	refs int
+}
+
+// statefulKey identifies a Stateful action. Actions are keyed by their type and
+// the address they point to, rather than by their value, as values holding maps
+// or slices can't be used as map keys. Actions which aren't pointers, maps or
+// slices have no identity of their own, so they are keyed by their type alone,
+// and all of the actions of that type are evaluated by one execution at a time.
+type statefulKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+func newStatefulKey(action any) statefulKey {
+	v := reflect.ValueOf(action)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		return statefulKey{typ: v.Type(), ptr: v.Pointer()}
+	}
+	return statefulKey{typ: v.Type()}
+}
+
+// lockStateful locks a Stateful action, so that it is only evaluated by
+// one execution at a time, and returns the function to unlock it.
+func lockStateful(action any) (unlock func()) {
+	key := newStatefulKey(action)
+
+	statefulLocks.Lock()
+	l, ok := statefulLocks.m[key]
+	if !ok {
+		l = &statefulLock{}
+		statefulLocks.m[key] = l
+	}
+	l.refs++
+	statefulLocks.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		statefulLocks.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(statefulLocks.m, key)
+		}
+		statefulLocks.Unlock()
+	}
+}
+
+func recoverAction[T any](ctx context.Context, method string, fn func(ctx context.Context) (T, error)) (value T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(error)
+			if !ok {
+				perr = fmt.Errorf("%v", r)
+			}
+			err = &ActionError{Kind: ActionPanicked, Method: method, Err: perr, Stack: debug.Stack()}
+		}
+	}()
+
+	value, err = fn(ctx)
+	if err != nil {
+		err = &ActionError{Kind: ActionReturnedError, Method: method, Err: err}
+	}
+	return value, err
+}
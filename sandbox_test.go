@@ -0,0 +1,243 @@
+package glide
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPanicAction panics when it's completed.
+type testPanicAction struct{}
+
+func (testPanicAction) Complete(ctx context.Context, input any) (bool, error) {
+	panic("nil map")
+}
+
+// testSlowAction takes a while to complete.
+type testSlowAction struct {
+	delay time.Duration
+}
+
+func (a testSlowAction) Complete(ctx context.Context, input any) (bool, error) {
+	time.Sleep(a.delay)
+	return true, nil
+}
+
+// testTimeoutAction overrides the action timeout.
+type testTimeoutAction struct {
+	testSlowAction
+	timeout time.Duration
+}
+
+func (a testTimeoutAction) Timeout() time.Duration {
+	return a.timeout
+}
+
+func TestExecute_ActionPanics(t *testing.T) {
+	c := Compiler{Program: SimpleProgram(
+		s.Start("request"),
+		s.WithID("notify").Action("notify", testPanicAction{}),
+		s.Outcome("approved"),
+	)}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, got.Failed)
+	assert.Equal(t, Failed, got.State["default.notify"])
+	assert.EqualError(t, got.Errors["default.notify"], "action panicked in Complete: nil map")
+
+	var ae *ActionError
+	if assert.ErrorAs(t, got.Errors["default.notify"], &ae) {
+		assert.Equal(t, ActionPanicked, ae.Kind)
+		assert.NotEmpty(t, ae.Stack)
+	}
+}
+
+func TestExecute_ActionTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      any
+		opts        []ExecuteOption
+		wantTimeout bool
+	}{
+		{
+			name:   "no timeout",
+			action: testSlowAction{delay: 20 * time.Millisecond},
+		},
+		{
+			name:        "timed out",
+			action:      testSlowAction{delay: time.Second},
+			opts:        []ExecuteOption{WithActionTimeout(10 * time.Millisecond)},
+			wantTimeout: true,
+		},
+		{
+			name:   "action overrides the timeout",
+			action: testTimeoutAction{testSlowAction{delay: 20 * time.Millisecond}, time.Second},
+			opts:   []ExecuteOption{WithActionTimeout(10 * time.Millisecond)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: SimpleProgram(
+				s.Start("request"),
+				s.WithID("slow").Action("slow", tt.action),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			)}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.wantTimeout {
+				assert.Equal(t, "approved", got.Outcome)
+				return
+			}
+
+			assert.True(t, got.Failed)
+			assert.True(t, errors.Is(got.Errors["default.slow"], context.DeadlineExceeded))
+
+			var ae *ActionError
+			if assert.ErrorAs(t, got.Errors["default.slow"], &ae) {
+				assert.Equal(t, ActionTimedOut, ae.Kind)
+				assert.Equal(t, "Complete", ae.Method)
+			}
+		})
+	}
+}
+
+// testCancelledAction blocks until its context is cancelled.
+// It's stateful, so that the test can check that its state isn't
+// saved while it's still running.
+type testCancelledAction struct {
+	cancelled chan error
+	saved     bool
+}
+
+func (a *testCancelledAction) Complete(ctx context.Context, input any) (bool, error) {
+	<-ctx.Done()
+	a.cancelled <- ctx.Err()
+	return false, ctx.Err()
+}
+
+func (a *testCancelledAction) SaveState() ([]byte, error) {
+	a.saved = true
+	return nil, nil
+}
+
+func (a *testCancelledAction) LoadState(data []byte) error {
+	return nil
+}
+
+func TestExecute_ActionTimeoutCancelsContext(t *testing.T) {
+	a := &testCancelledAction{cancelled: make(chan error, 1)}
+	c := Compiler{Program: SimpleProgram(
+		s.Start("request"),
+		s.WithID("slow").Action("slow", a),
+		s.Outcome("approved"),
+	)}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := map[string][]byte{"default.slow": []byte("previous")}
+	got, err := g.Execute("request", nil, WithActionTimeout(10*time.Millisecond), WithActionState(state))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-a.cancelled:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the context of the action wasn't cancelled")
+	}
+
+	assert.True(t, got.Failed)
+	assert.False(t, a.saved, "the state of an action which is still running was saved")
+	assert.Equal(t, state, got.ActionState)
+}
+
+// testContextAction is complete if its context has a value.
+type testContextAction struct{}
+
+type testContextKey struct{}
+
+func (testContextAction) Complete(ctx context.Context, input any) (bool, error) {
+	return ctx.Value(testContextKey{}) == true, nil
+}
+
+func TestExecute_WithContext(t *testing.T) {
+	c := Compiler{Program: SimpleProgram(
+		s.Start("request"),
+		s.WithID("ctx").Action("ctx", testContextAction{}),
+		s.Named("Approved").Priority(1).Outcome("approved"),
+	)}
+	g, err := c.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), testContextKey{}, true)
+	got, err := g.Execute("request", nil, WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", got.Outcome)
+
+	got, err = g.Execute("request", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", got.Outcome)
+}
+
+func TestLockStateful(t *testing.T) {
+	// the value of an action which holds a map in an interface field has a
+	// comparable type, but it can't be used as a map key.
+	type valueAction struct{ state any }
+	unlock := lockStateful(valueAction{state: map[string]int{}})
+	unlock()
+
+	// different actions are locked independently.
+	a, b := &testReminderAction{}, &testReminderAction{}
+	unlockA := lockStateful(a)
+	unlockB := lockStateful(b)
+
+	// the same action is locked until it's unlocked.
+	locked := make(chan struct{})
+	go func() {
+		unlock := lockStateful(a)
+		close(locked)
+		unlock()
+	}()
+	select {
+	case <-locked:
+		t.Fatal("expected the action to be locked")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlockA()
+	<-locked
+	unlockB()
+
+	statefulLocks.Lock()
+	assert.Empty(t, statefulLocks.m)
+	statefulLocks.Unlock()
+}
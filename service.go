@@ -0,0 +1,322 @@
+package glide
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+)
+
+// DefaultCacheSize is the default number of compiled workflows
+// kept in memory by a Service.
+const DefaultCacheSize = 1000
+
+// DefaultLoadTimeout is the default time a Service waits
+// for its Source to load a workflow.
+const DefaultLoadTimeout = 30 * time.Second
+
+// ErrUnknownTenant is returned by a Service for tenants
+// which haven't been configured with SetTenant.
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// Tenant configures how a tenant's workflows are compiled.
+type Tenant struct {
+	// Dialect used to unmarshal the tenant's workflows.
+	Dialect dialect.Dialect
+	// InputSchema is the JSON schema for the input to the tenant's workflows.
+	InputSchema *jsoncel.Schema
+}
+
+// Source loads the YAML definitions of workflows.
+type Source interface {
+	Workflow(ctx context.Context, tenant, workflow string) ([]byte, error)
+}
+
+// SourceFunc is a Source implemented by a function.
+type SourceFunc func(ctx context.Context, tenant, workflow string) ([]byte, error)
+
+func (f SourceFunc) Workflow(ctx context.Context, tenant, workflow string) ([]byte, error) {
+	return f(ctx, tenant, workflow)
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithCacheSize sets the maximum number of compiled workflows kept in memory.
+// The least recently used workflow is evicted when the cache is full.
+// If n is zero or less, the cache is unbounded.
+func WithCacheSize(n int) ServiceOption {
+	return func(s *Service) {
+		s.size = n
+	}
+}
+
+// WithLoadTimeout sets the time to wait for the Source to load a workflow.
+// If d is zero or less, loads have no deadline.
+func WithLoadTimeout(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.loadTimeout = d
+	}
+}
+
+// Service manages the compiled workflows of many tenants,
+// for servers which embed Glide.
+//
+// Workflows are loaded from the Source and compiled the first time they
+// are used, and cached by tenant and workflow ID. Concurrent requests for
+// a workflow which isn't cached share a single compilation.
+// A Service is safe for use by multiple goroutines.
+type Service struct {
+	source      Source
+	size        int
+	loadTimeout time.Duration
+
+	mu       sync.Mutex
+	tenants  map[string]Tenant
+	entries  map[serviceKey]*list.Element
+	lru      *list.List // of *serviceEntry, most recently used first
+	inflight map[serviceKey]*compileCall
+}
+
+type serviceKey struct {
+	tenant   string
+	workflow string
+}
+
+type serviceEntry struct {
+	key      serviceKey
+	compiled *Compiled
+}
+
+// compileCall is a compilation in progress.
+// done is closed when the compilation finishes.
+type compileCall struct {
+	done  chan struct{}
+	entry *serviceEntry
+	err   error
+}
+
+// NewService returns a Service which loads workflows from the source.
+func NewService(source Source, opts ...ServiceOption) *Service {
+	s := &Service{
+		source:      source,
+		size:        DefaultCacheSize,
+		loadTimeout: DefaultLoadTimeout,
+		tenants:     map[string]Tenant{},
+		entries:     map[serviceKey]*list.Element{},
+		lru:         list.New(),
+		inflight:    map[serviceKey]*compileCall{},
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// SetTenant configures a tenant. If the tenant was already
+// configured, its cached workflows are evicted so that they are
+// recompiled with the new configuration.
+func (s *Service) SetTenant(id string, t Tenant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tenants[id] = t
+	s.evictTenant(id)
+}
+
+// RemoveTenant removes a tenant and evicts its cached workflows.
+func (s *Service) RemoveTenant(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tenants, id)
+	s.evictTenant(id)
+}
+
+// Invalidate evicts a cached workflow, so that it is
+// loaded and compiled again the next time it is used.
+// It should be called when the workflow's definition changes.
+func (s *Service) Invalidate(tenant, workflow string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := serviceKey{tenant: tenant, workflow: workflow}
+	s.evict(key)
+	// a compilation in progress may have loaded the old definition,
+	// so don't cache its result.
+	delete(s.inflight, key)
+}
+
+// Workflow returns a tenant's compiled workflow,
+// loading and compiling it if it isn't cached.
+// The workflow is shared with other callers, and may be executed concurrently.
+func (s *Service) Workflow(ctx context.Context, tenant, workflow string) (*Compiled, error) {
+	e, err := s.entry(ctx, tenant, workflow)
+	if err != nil {
+		return nil, err
+	}
+	return e.compiled, nil
+}
+
+// Execute a tenant's workflow. See Graph.Execute.
+// The context is passed to the methods of actions.
+//
+// Workflows are executed concurrently, including executions of the same workflow.
+func (s *Service) Execute(ctx context.Context, tenant, workflow, start string, input map[string]any, opts ...ExecuteOption) (*Result, error) {
+	e, err := s.entry(ctx, tenant, workflow)
+	if err != nil {
+		return nil, err
+	}
+	return e.compiled.Execute(start, input, append([]ExecuteOption{WithContext(ctx)}, opts...)...)
+}
+
+// Drive executes a tenant's workflow and calls the handler for its outcome.
+// See Compiled.Drive.
+func (s *Service) Drive(ctx context.Context, tenant, workflow, start string, input map[string]any, opts ...ExecuteOption) (*Result, error) {
+	e, err := s.entry(ctx, tenant, workflow)
+	if err != nil {
+		return nil, err
+	}
+	return e.compiled.Drive(ctx, start, input, opts...)
+}
+
+// Len returns the number of cached workflows.
+func (s *Service) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+func (s *Service) entry(ctx context.Context, tenant, workflow string) (*serviceEntry, error) {
+	key := serviceKey{tenant: tenant, workflow: workflow}
+
+	s.mu.Lock()
+	if el, ok := s.entries[key]; ok {
+		s.lru.MoveToFront(el)
+		s.mu.Unlock()
+		return el.Value.(*serviceEntry), nil
+	}
+
+	call, ok := s.inflight[key]
+	if !ok {
+		t, ok := s.tenants[tenant]
+		if !ok {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, tenant)
+		}
+
+		call = &compileCall{done: make(chan struct{})}
+		s.inflight[key] = call
+		go s.compile(key, t, call)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.entry, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// compile loads and compiles a workflow, caching it if the
+// call hasn't been superseded by a change to the tenant or workflow.
+//
+// The compilation isn't tied to the context of the request which started it,
+// as other requests may be waiting for it. Loading the workflow is bounded by
+// the load timeout instead.
+func (s *Service) compile(key serviceKey, t Tenant, call *compileCall) {
+	defer close(call.done)
+
+	compiled, err := s.build(key, t)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.inflight[key] == call
+	if current {
+		delete(s.inflight, key)
+	}
+
+	if err != nil {
+		call.err = err
+		return
+	}
+
+	call.entry = &serviceEntry{key: key, compiled: compiled}
+	if current {
+		s.add(call.entry)
+	}
+}
+
+// build loads and compiles a workflow. Panics in the Source or while compiling
+// are returned as errors, so that they don't crash the server or leave the
+// requests waiting for the compilation blocked.
+func (s *Service) build(key serviceKey, t Tenant) (compiled *Compiled, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("compiling workflow %s for tenant %s: panic: %v", key.workflow, key.tenant, r)
+		}
+	}()
+
+	ctx := context.Background()
+	if s.loadTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.loadTimeout)
+		defer cancel()
+	}
+
+	data, err := s.source.Workflow(ctx, key.tenant, key.workflow)
+	if err != nil {
+		return nil, fmt.Errorf("loading workflow %s for tenant %s: %w", key.workflow, key.tenant, err)
+	}
+
+	p, err := Unmarshal(data, t.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	c := Compiler{Program: p, InputSchema: t.InputSchema}
+	return c.Build()
+}
+
+// add caches an entry, evicting the least recently used
+// entries if the cache is full. s.mu must be held.
+func (s *Service) add(e *serviceEntry) {
+	s.entries[e.key] = s.lru.PushFront(e)
+
+	for s.size > 0 && s.lru.Len() > s.size {
+		oldest := s.lru.Back()
+		s.evict(oldest.Value.(*serviceEntry).key)
+	}
+}
+
+// evict removes a cached entry. s.mu must be held.
+func (s *Service) evict(key serviceKey) {
+	el, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	s.lru.Remove(el)
+	delete(s.entries, key)
+}
+
+// evictTenant removes the cached entries and compilations
+// in progress for a tenant. s.mu must be held.
+func (s *Service) evictTenant(tenant string) {
+	for key := range s.entries {
+		if key.tenant == tenant {
+			s.evict(key)
+		}
+	}
+	for key := range s.inflight {
+		if key.tenant == tenant {
+			delete(s.inflight, key)
+		}
+	}
+}
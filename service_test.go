@@ -0,0 +1,273 @@
+package glide
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/stretchr/testify/assert"
+)
+
+var serviceTenant = Tenant{
+	Dialect: dialect.Dialect{
+		Nodes: map[string]node.Node{
+			"request":  {Type: node.Start},
+			"approved": {Type: node.Outcome, Priority: 1},
+		},
+	},
+	InputSchema: &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"ok": {Type: jsoncel.Boolean},
+		},
+	},
+}
+
+// testSource is a Source which counts the workflows loaded.
+type testSource struct {
+	workflows map[string]string
+	loads     int32
+	// wait blocks loads until it is closed, if set.
+	wait chan struct{}
+}
+
+func (t *testSource) Workflow(ctx context.Context, tenant, workflow string) ([]byte, error) {
+	atomic.AddInt32(&t.loads, 1)
+	if t.wait != nil {
+		<-t.wait
+	}
+	w, ok := t.workflows[tenant+"/"+workflow]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(w), nil
+}
+
+const serviceWorkflow = `
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: input.ok
+      - outcome: approved
+`
+
+func TestService_Execute(t *testing.T) {
+	src := &testSource{workflows: map[string]string{"acme/access": serviceWorkflow}}
+	svc := NewService(src)
+	svc.SetTenant("acme", serviceTenant)
+
+	res, err := svc.Execute(context.Background(), "acme", "access", "request", map[string]any{"ok": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+
+	res, err = svc.Execute(context.Background(), "acme", "access", "request", map[string]any{"ok": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", res.Outcome)
+
+	// the workflow is compiled once and then cached.
+	assert.Equal(t, int32(1), atomic.LoadInt32(&src.loads))
+}
+
+func TestService_Errors(t *testing.T) {
+	src := &testSource{workflows: map[string]string{"acme/invalid": `workflow: [`}}
+	svc := NewService(src)
+	svc.SetTenant("acme", serviceTenant)
+
+	_, err := svc.Workflow(context.Background(), "other", "access")
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+
+	_, err = svc.Workflow(context.Background(), "acme", "missing")
+	assert.EqualError(t, err, "loading workflow missing for tenant acme: not found")
+
+	_, err = svc.Workflow(context.Background(), "acme", "invalid")
+	assert.Error(t, err)
+
+	// errors aren't cached.
+	assert.Equal(t, 0, svc.Len())
+}
+
+func TestService_Panics(t *testing.T) {
+	var loads int32
+	svc := NewService(SourceFunc(func(ctx context.Context, tenant, workflow string) ([]byte, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			panic("source failed")
+		}
+		return []byte(serviceWorkflow), nil
+	}))
+	svc.SetTenant("acme", serviceTenant)
+
+	_, err := svc.Workflow(context.Background(), "acme", "access")
+	assert.EqualError(t, err, "compiling workflow access for tenant acme: panic: source failed")
+
+	// the failed compilation isn't cached or left in progress.
+	assert.Equal(t, 0, svc.Len())
+	_, err = svc.Workflow(context.Background(), "acme", "access")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+}
+
+func TestService_LoadTimeout(t *testing.T) {
+	svc := NewService(SourceFunc(func(ctx context.Context, tenant, workflow string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), WithLoadTimeout(10*time.Millisecond))
+	svc.SetTenant("acme", serviceTenant)
+
+	_, err := svc.Workflow(context.Background(), "acme", "access")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, svc.Len())
+}
+
+func TestService_Eviction(t *testing.T) {
+	src := &testSource{workflows: map[string]string{
+		"acme/a": serviceWorkflow,
+		"acme/b": serviceWorkflow,
+		"acme/c": serviceWorkflow,
+	}}
+	svc := NewService(src, WithCacheSize(2))
+	svc.SetTenant("acme", serviceTenant)
+
+	ctx := context.Background()
+	for _, w := range []string{"a", "b", "a", "c"} {
+		_, err := svc.Workflow(ctx, "acme", w)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, 2, svc.Len())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&src.loads))
+
+	// 'b' was least recently used, so it was evicted when 'c' was added.
+	_, err := svc.Workflow(ctx, "acme", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&src.loads))
+	_, err = svc.Workflow(ctx, "acme", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&src.loads))
+
+	// reconfiguring the tenant evicts its workflows.
+	svc.SetTenant("acme", serviceTenant)
+	assert.Equal(t, 0, svc.Len())
+
+	svc.Invalidate("acme", "a")
+	_, err = svc.Workflow(ctx, "acme", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int32(5), atomic.LoadInt32(&src.loads))
+}
+
+func TestService_SingleFlight(t *testing.T) {
+	src := &testSource{
+		workflows: map[string]string{"acme/access": serviceWorkflow},
+		wait:      make(chan struct{}),
+	}
+	svc := NewService(src)
+	svc.SetTenant("acme", serviceTenant)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(ok bool) {
+			defer wg.Done()
+			res, err := svc.Execute(context.Background(), "acme", "access", "request", map[string]any{"ok": ok})
+			if !assert.NoError(t, err) {
+				return
+			}
+			if ok {
+				assert.Equal(t, "approved", res.Outcome)
+			} else {
+				assert.Equal(t, "", res.Outcome)
+			}
+		}(i%2 == 0)
+	}
+
+	// a cancelled request stops waiting, without affecting the compilation.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := svc.Workflow(ctx, "acme", "access")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(src.wait)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&src.loads))
+	assert.Equal(t, 1, svc.Len())
+}
+
+// testBarrierAction is complete once the test releases it,
+// after every execution has started evaluating it.
+type testBarrierAction struct {
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func (a *testBarrierAction) Complete(ctx context.Context, input any) (bool, error) {
+	a.arrived <- struct{}{}
+	select {
+	case <-a.release:
+		return true, nil
+	case <-time.After(time.Second):
+		return false, errors.New("not released")
+	}
+}
+
+func TestService_ExecuteConcurrently(t *testing.T) {
+	const n = 4
+	a := &testBarrierAction{arrived: make(chan struct{}, n), release: make(chan struct{})}
+	tenant := Tenant{
+		Dialect: dialect.Dialect{
+			Actions: func() map[string]any {
+				return map[string]any{"barrier": a}
+			},
+			Nodes: serviceTenant.Dialect.Nodes,
+		},
+	}
+	src := &testSource{workflows: map[string]string{"acme/access": `
+workflow:
+  default:
+    steps:
+      - start: request
+      - action: barrier
+      - outcome: approved
+`}}
+	svc := NewService(src)
+	svc.SetTenant("acme", tenant)
+
+	// executions of the same workflow aren't serialised,
+	// so every execution reaches the action before any are released.
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := svc.Execute(context.Background(), "acme", "access", "request", nil)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "approved", res.Outcome)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case <-a.arrived:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of %d executions ran concurrently", i, n)
+		}
+	}
+	close(a.release)
+	wg.Wait()
+}
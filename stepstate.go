@@ -0,0 +1,304 @@
+package glide
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/common-fate/glide/pkg/step"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Checks can reference the state of other steps by their ID or name, e.g.
+//
+//	- check: steps["Security review"].complete
+//
+// The referenced step is evaluated before the check, even if it is in
+// a different branch of the workflow.
+
+// stepKeys returns the keys which steps can be referenced by in checks.
+// The value is false if the key is ambiguous, because more than one step
+// has it. IDs take precedence over names, so a step can always be referenced
+// by a unique ID.
+func stepKeys(p *Program) map[string]bool {
+	ids := map[string]int{}
+	names := map[string]int{}
+
+	var visit func(steps []step.Step)
+	visit = func(steps []step.Step) {
+		for _, s := range steps {
+			visit(s.Children)
+			visit(s.OnFail.Steps)
+
+			// start and outcome nodes are shared between passes,
+			// so they can't be referenced.
+			if _, ok := s.Body.(step.Ref); ok {
+				continue
+			}
+			if s.ID != "" {
+				ids[s.ID]++
+			}
+			if s.Name != "" {
+				names[s.Name]++
+			}
+		}
+	}
+	for _, pass := range p.Workflow {
+		visit(pass.Steps)
+	}
+
+	keys := map[string]bool{}
+	for name, n := range names {
+		keys[name] = n == 1
+	}
+	for id, n := range ids {
+		keys[id] = n == 1
+	}
+	return keys
+}
+
+// addStateSchema adds the 'complete' and 'state' fields of the
+// referenceable steps to the schema of the 'steps' variable.
+func addStateSchema(schema *jsoncel.Schema, keys map[string]bool) {
+	for key, unique := range keys {
+		if !unique {
+			continue
+		}
+		s, ok := schema.Properties[key]
+		if !ok {
+			s = &jsoncel.Schema{Type: jsoncel.Object, Properties: map[string]*jsoncel.Schema{}}
+			schema.Properties[key] = s
+		}
+		s.Properties["complete"] = &jsoncel.Schema{Type: jsoncel.Boolean}
+		s.Properties["state"] = &jsoncel.Schema{Type: jsoncel.String}
+	}
+}
+
+// rewriteStepIndexes rewrites indexes into the 'steps' variable with
+// a constant key, like 'steps["Security review"]', into field selections.
+// Step names can contain spaces, so they can't always be selected directly,
+// but the type checker only supports selecting the fields of objects.
+func rewriteStepIndexes(e *exprpb.Expr) {
+	if e == nil {
+		return
+	}
+
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		rewriteStepIndexes(k.SelectExpr.GetOperand())
+	case *exprpb.Expr_CallExpr:
+		call := k.CallExpr
+		if key, ok := stepIndex(call); ok {
+			e.ExprKind = &exprpb.Expr_SelectExpr{
+				SelectExpr: &exprpb.Expr_Select{Operand: call.GetArgs()[0], Field: key},
+			}
+			return
+		}
+		rewriteStepIndexes(call.GetTarget())
+		for _, a := range call.GetArgs() {
+			rewriteStepIndexes(a)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, el := range k.ListExpr.GetElements() {
+			rewriteStepIndexes(el)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			rewriteStepIndexes(entry.GetMapKey())
+			rewriteStepIndexes(entry.GetValue())
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		c := k.ComprehensionExpr
+		rewriteStepIndexes(c.GetIterRange())
+		rewriteStepIndexes(c.GetAccuInit())
+		rewriteStepIndexes(c.GetLoopCondition())
+		rewriteStepIndexes(c.GetLoopStep())
+		rewriteStepIndexes(c.GetResult())
+	}
+}
+
+// stepIndex returns the key if the call is an index
+// into the 'steps' variable with a constant string.
+func stepIndex(call *exprpb.Expr_Call) (string, bool) {
+	if call.GetFunction() != "_[_]" || len(call.GetArgs()) != 2 {
+		return "", false
+	}
+	if call.GetArgs()[0].GetIdentExpr().GetName() != stepsVar {
+		return "", false
+	}
+	key, ok := call.GetArgs()[1].GetConstExpr().GetConstantKind().(*exprpb.Constant_StringValue)
+	if !ok {
+		return "", false
+	}
+	return key.StringValue, true
+}
+
+// stepRefs returns the sorted keys of the steps referenced by an expression,
+// after indexes have been rewritten with rewriteStepIndexes.
+func stepRefs(e *exprpb.Expr) []string {
+	found := map[string]bool{}
+	collectStepRefs(e, found)
+
+	var keys []string
+	for k := range found {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func collectStepRefs(e *exprpb.Expr, found map[string]bool) {
+	if e == nil {
+		return
+	}
+
+	switch k := e.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		if k.SelectExpr.GetOperand().GetIdentExpr().GetName() == stepsVar {
+			found[k.SelectExpr.GetField()] = true
+			return
+		}
+		collectStepRefs(k.SelectExpr.GetOperand(), found)
+	case *exprpb.Expr_CallExpr:
+		collectStepRefs(k.CallExpr.GetTarget(), found)
+		for _, a := range k.CallExpr.GetArgs() {
+			collectStepRefs(a, found)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, el := range k.ListExpr.GetElements() {
+			collectStepRefs(el, found)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range k.StructExpr.GetEntries() {
+			collectStepRefs(entry.GetMapKey(), found)
+			collectStepRefs(entry.GetValue(), found)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		c := k.ComprehensionExpr
+		collectStepRefs(c.GetIterRange(), found)
+		collectStepRefs(c.GetAccuInit(), found)
+		collectStepRefs(c.GetLoopCondition(), found)
+		collectStepRefs(c.GetLoopStep(), found)
+		collectStepRefs(c.GetResult(), found)
+	}
+}
+
+// checkStepRefs returns an error if an expression references
+// a step which doesn't exist, or a key shared by more than one step.
+func checkStepRefs(e *exprpb.Expr, keys map[string]bool) error {
+	for _, key := range stepRefs(e) {
+		unique, ok := keys[key]
+		if !ok {
+			return fmt.Errorf("check references step %q, but no step has this id or name", key)
+		}
+		if !unique {
+			return fmt.Errorf("check references step %q, but more than one step has this id or name: add a unique id to the step and reference it instead", key)
+		}
+	}
+	return nil
+}
+
+// resolveStepRefs maps the referenceable step keys to their vertex hashes,
+// and records the steps which each check depends on, so that they are
+// evaluated first. An error is returned if a check depends on a step which
+// can only be reached through the check.
+func (g *Graph) resolveStepRefs(keys map[string]bool) error {
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return err
+	}
+
+	g.stepHashes = map[string]string{}
+	for _, k := range hashes {
+		s, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.Body.(step.Ref); ok {
+			continue
+		}
+		for _, key := range []string{s.ID, s.Name} {
+			if key != "" && keys[key] {
+				g.stepHashes[key] = k
+			}
+		}
+	}
+
+	g.deps = map[string][]string{}
+	for k, ast := range g.asts {
+		for _, key := range stepRefs(ast.Expr()) {
+			if dep, ok := g.stepHashes[key]; ok {
+				g.deps[k] = append(g.deps[k], dep)
+			}
+		}
+		sort.Strings(g.deps[k])
+	}
+
+	// sort the checks so that errors are deterministic.
+	var checks []string
+	for k := range g.deps {
+		checks = append(checks, k)
+	}
+	sort.Strings(checks)
+
+	for _, k := range checks {
+		for _, dep := range g.deps[k] {
+			cycle, err := g.dependsOn(dep, k)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				s, err := g.store.step(k)
+				if err != nil {
+					return err
+				}
+				return noderr.Wrap(fmt.Errorf("check references step %s, which can't be evaluated before the check", dep), s.Node)
+			}
+		}
+	}
+
+	return nil
+}
+
+// dependsOn returns true if the step k can only be evaluated after the step target,
+// because it is a successor of the target, or depends on one.
+func (g *Graph) dependsOn(k, target string) (bool, error) {
+	seen := map[string]bool{}
+	stack := []string{target}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if cur == k {
+			return true, nil
+		}
+		if seen[cur] {
+			continue
+		}
+		seen[cur] = true
+
+		succs, err := g.store.successors(cur)
+		if err != nil {
+			return false, err
+		}
+		stack = append(stack, succs...)
+
+		// checks which depend on the current step are evaluated after it.
+		for check, deps := range g.deps {
+			for _, dep := range deps {
+				if dep == cur {
+					stack = append(stack, check)
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// stepState returns the value of a step in the 'steps' variable.
+func stepState(s State) map[string]any {
+	return map[string]any{
+		"complete": s == Complete,
+		"state":    s.String(),
+	}
+}
@@ -0,0 +1,98 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute_StepStates(t *testing.T) {
+	program := func(check string, complete bool) *Program {
+		return NewProgram().
+			Pass("default",
+				s.Start("request"),
+				s.Check(check),
+				s.Named("Approved").Priority(2).Outcome("approved"),
+			).
+			Pass("security",
+				s.Start("request"),
+				s.Named("Security review").ID("review").Action("review", &testAction{complete: complete}),
+				s.Named("Reviewed").Priority(1).Outcome("reviewed"),
+			)
+	}
+
+	tests := []struct {
+		name     string
+		check    string
+		complete bool
+		want     State
+	}{
+		{name: "by name, complete", check: `steps["Security review"].complete`, complete: true, want: Complete},
+		{name: "by name, not complete", check: `steps["Security review"].complete`, want: Inactive},
+		{name: "by id", check: `steps.review.complete`, complete: true, want: Complete},
+		{name: "state", check: `steps.review.state == "active"`, want: Complete},
+		{name: "negated", check: `!steps.review.complete`, want: Complete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: program(tt.check, tt.complete)}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Execute("request", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want.String(), got.State["default.1"].String())
+		})
+	}
+}
+
+func TestCompile_StepStateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    *Program
+		wantErr string
+	}{
+		{
+			name: "unknown step",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check(`steps["Security review"].complete`),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			wantErr: `check references step "Security review", but no step has this id or name`,
+		},
+		{
+			name: "ambiguous name",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Named("Review").Check("true"),
+				s.Named("Review").Check("true"),
+				s.Check(`steps.Review.complete`),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			wantErr: `check references step "Review", but more than one step has this id or name`,
+		},
+		{
+			name: "step after the check",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check(`steps.Later.complete`),
+				s.Named("Later").Check("true"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			wantErr: "check references step default.2, which can't be evaluated before the check",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give}
+			_, err := c.Compile()
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
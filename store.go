@@ -0,0 +1,178 @@
+package glide
+
+import (
+	"sort"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/dominikbraun/graph"
+	"github.com/pkg/errors"
+)
+
+// errStepExists is returned by a store if a step with the same hash has already been added.
+var errStepExists = errors.New("step already exists")
+
+// store is the data structure which holds the steps of a Graph and the edges between them.
+//
+// The compiler and execution engine only access the graph through this interface,
+// so that the underlying representation can be changed without changing the public API.
+type store interface {
+	// addStep adds a step as a vertex, returning errStepExists if it has already been added.
+	addStep(s step.Step) error
+	// addEdge adds an edge from the source step to the target step.
+	addEdge(source, target string, attributes map[string]string) error
+	// step returns the step with the provided hash.
+	step(hash string) (step.Step, error)
+	// hashes returns the hashes of all steps, sorted.
+	hashes() ([]string, error)
+	// predecessors returns the edges into a step, sorted by their source.
+	predecessors(hash string) ([]edge, error)
+	// successors returns the hashes of the steps which a step has edges to, sorted.
+	successors(hash string) ([]string, error)
+	// edges returns all of the edges, sorted by their source and then their target.
+	edges() ([]edge, error)
+	// removeStep removes a step and the edges into and out of it.
+	removeStep(hash string) error
+}
+
+// edge is an edge between two steps.
+type edge struct {
+	Source     string
+	Target     string
+	Attributes map[string]string
+}
+
+// libraryStore is a store backed by the dominikbraun/graph library.
+//
+// The library computes adjacency maps on each call, so the
+// predecessors and successors of each step are also indexed as
+// edges are added, to avoid rebuilding them during execution.
+type libraryStore struct {
+	g     graph.Graph[string, step.Step]
+	preds map[string][]edge
+	succs map[string][]string
+	// removed are the steps which have been removed. The library
+	// can't remove vertices, so they are hidden instead.
+	removed map[string]bool
+}
+
+func newLibraryStore() *libraryStore {
+	return &libraryStore{
+		g:       graph.New(step.Hash, graph.Directed(), graph.PreventCycles()),
+		preds:   map[string][]edge{},
+		succs:   map[string][]string{},
+		removed: map[string]bool{},
+	}
+}
+
+func (ls *libraryStore) addStep(s step.Step) error {
+	err := ls.g.AddVertex(s, graph.VertexAttribute("label", s.Debug()))
+	if err == graph.ErrVertexAlreadyExists {
+		return errStepExists
+	}
+	return err
+}
+
+func (ls *libraryStore) addEdge(source, target string, attributes map[string]string) error {
+	var opts []func(*graph.EdgeProperties)
+	for k, v := range attributes {
+		opts = append(opts, graph.EdgeAttribute(k, v))
+	}
+	err := ls.g.AddEdge(source, target, opts...)
+	if err != nil {
+		return err
+	}
+
+	ls.preds[target] = append(ls.preds[target], edge{Source: source, Target: target, Attributes: attributes})
+	sort.Slice(ls.preds[target], func(i, j int) bool { return ls.preds[target][i].Source < ls.preds[target][j].Source })
+
+	ls.succs[source] = append(ls.succs[source], target)
+	sort.Strings(ls.succs[source])
+	return nil
+}
+
+func (ls *libraryStore) step(hash string) (step.Step, error) {
+	if ls.removed[hash] {
+		return step.Step{}, graph.ErrVertexNotFound
+	}
+	return ls.g.Vertex(hash)
+}
+
+func (ls *libraryStore) hashes() ([]string, error) {
+	adj, err := ls.g.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+	var hashes []string
+	for k := range adj {
+		if !ls.removed[k] {
+			hashes = append(hashes, k)
+		}
+	}
+	sort.Strings(hashes)
+	return hashes, nil
+}
+
+func (ls *libraryStore) predecessors(hash string) ([]edge, error) {
+	return ls.preds[hash], nil
+}
+
+func (ls *libraryStore) successors(hash string) ([]string, error) {
+	return ls.succs[hash], nil
+}
+
+func (ls *libraryStore) removeStep(hash string) error {
+	if _, err := ls.step(hash); err != nil {
+		return err
+	}
+
+	for _, e := range ls.preds[hash] {
+		err := ls.g.RemoveEdge(e.Source, e.Target)
+		if err != nil {
+			return err
+		}
+		ls.succs[e.Source] = removeString(ls.succs[e.Source], hash)
+	}
+	for _, target := range ls.succs[hash] {
+		err := ls.g.RemoveEdge(hash, target)
+		if err != nil {
+			return err
+		}
+		var pres []edge
+		for _, e := range ls.preds[target] {
+			if e.Source != hash {
+				pres = append(pres, e)
+			}
+		}
+		ls.preds[target] = pres
+	}
+
+	delete(ls.preds, hash)
+	delete(ls.succs, hash)
+	ls.removed[hash] = true
+	return nil
+}
+
+// removeString returns the strings without s.
+func removeString(strs []string, s string) []string {
+	var out []string
+	for _, str := range strs {
+		if str != s {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
+func (ls *libraryStore) edges() ([]edge, error) {
+	var edges []edge
+	for _, pres := range ls.preds {
+		edges = append(edges, pres...)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		return edges[i].Target < edges[j].Target
+	})
+	return edges, nil
+}
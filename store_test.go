@@ -0,0 +1,92 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibraryStore(t *testing.T) {
+	ls := newLibraryStore()
+
+	for _, st := range []string{"c", "b", "a"} {
+		err := ls.addStep(s.Start(st))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := ls.addStep(s.Start("a"))
+	assert.ErrorIs(t, err, errStepExists)
+
+	err = ls.addEdge("c", "a", map[string]string{"label": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ls.addEdge("b", "a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashes, err := ls.hashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, hashes)
+
+	pres, err := ls.predecessors("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []edge{
+		{Source: "b", Target: "a"},
+		{Source: "c", Target: "a", Attributes: map[string]string{"label": "test"}},
+	}, pres)
+
+	succs, err := ls.successors("c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"a"}, succs)
+
+	err = ls.addEdge("b", "c", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges, err := ls.edges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []edge{
+		{Source: "b", Target: "a"},
+		{Source: "b", Target: "c"},
+		{Source: "c", Target: "a", Attributes: map[string]string{"label": "test"}},
+	}, edges)
+
+	// edges which would create a cycle are rejected.
+	err = ls.addEdge("a", "c", nil)
+	assert.Error(t, err)
+
+	err = ls.removeStep("c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes, err = ls.hashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"a", "b"}, hashes)
+	edges, err = ls.edges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []edge{{Source: "b", Target: "a"}}, edges)
+	succs, err = ls.successors("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"a"}, succs)
+	_, err = ls.step("c")
+	assert.Error(t, err)
+}
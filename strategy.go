@@ -0,0 +1,75 @@
+package glide
+
+import (
+	"sort"
+
+	"github.com/common-fate/glide/pkg/node"
+)
+
+// OutcomeStrategy selects the outcomes of an execution
+// from the outcome nodes which were reached.
+//
+// Set it with OutcomeStrategy on the Compiler, or with WithOutcomeStrategy
+// for a single execution. The default is HighestPriority.
+type OutcomeStrategy interface {
+	// SelectOutcomes returns the outcomes of the execution. Reached are the
+	// completed outcome nodes in the order they were reached, with each
+	// outcome listed once. The first outcome returned is the primary
+	// outcome of the execution, set as Result.Outcome.
+	SelectOutcomes(reached []node.Node) []node.Node
+}
+
+// OutcomeStrategyFunc is a function which selects outcomes.
+type OutcomeStrategyFunc func(reached []node.Node) []node.Node
+
+// SelectOutcomes calls f(reached).
+func (f OutcomeStrategyFunc) SelectOutcomes(reached []node.Node) []node.Node {
+	return f(reached)
+}
+
+var (
+	// HighestPriority selects the reached outcome with the highest priority.
+	// If outcomes have the same priority, the first reached is selected.
+	HighestPriority OutcomeStrategy = highestPriority{}
+
+	// FirstReached selects the first outcome which was reached.
+	FirstReached OutcomeStrategy = firstReached{}
+
+	// AllReached selects every reached outcome, ordered from
+	// the highest priority to the lowest.
+	AllReached OutcomeStrategy = allReached{}
+)
+
+type highestPriority struct{}
+
+func (highestPriority) SelectOutcomes(reached []node.Node) []node.Node {
+	// dialects require outcomes to have a priority greater than zero.
+	var out []node.Node
+	var highest int
+	for _, n := range reached {
+		if n.Priority > highest {
+			out = []node.Node{n}
+			highest = n.Priority
+		}
+	}
+	return out
+}
+
+type firstReached struct{}
+
+func (firstReached) SelectOutcomes(reached []node.Node) []node.Node {
+	if len(reached) == 0 {
+		return nil
+	}
+	return reached[:1]
+}
+
+type allReached struct{}
+
+func (allReached) SelectOutcomes(reached []node.Node) []node.Node {
+	out := append([]node.Node{}, reached...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Priority > out[j].Priority
+	})
+	return out
+}
@@ -0,0 +1,159 @@
+package glide
+
+import (
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutcomeStrategy(t *testing.T) {
+	// 'auto_approved' is reached before the higher priority 'approved'.
+	p := NewProgram().
+		Pass("auto",
+			s.Start("request"),
+			s.Check("true"),
+			s.Named("Auto Approved").Priority(1).Outcome("auto_approved"),
+		).
+		Pass("manual",
+			s.Start("request"),
+			s.Check("true"),
+			s.Check("true"),
+			s.Named("Approved").Priority(2).Outcome("approved"),
+		).
+		Pass("denied",
+			s.Start("request"),
+			s.Check("false"),
+			s.Named("Denied").Priority(3).Outcome("denied"),
+		)
+
+	tests := []struct {
+		name         string
+		strategy     OutcomeStrategy
+		wantOutcome  string
+		wantOutcomes []string
+	}{
+		{
+			name:         "default",
+			wantOutcome:  "approved",
+			wantOutcomes: []string{"approved"},
+		},
+		{
+			name:         "first reached",
+			strategy:     FirstReached,
+			wantOutcome:  "auto_approved",
+			wantOutcomes: []string{"auto_approved"},
+		},
+		{
+			name:         "all reached",
+			strategy:     AllReached,
+			wantOutcome:  "approved",
+			wantOutcomes: []string{"approved", "auto_approved"},
+		},
+		{
+			name: "custom",
+			strategy: OutcomeStrategyFunc(func(reached []node.Node) []node.Node {
+				for _, n := range reached {
+					if n.ID == "auto_approved" {
+						return []node.Node{n}
+					}
+				}
+				return nil
+			}),
+			wantOutcome:  "auto_approved",
+			wantOutcomes: []string{"auto_approved"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := (&Compiler{Program: p, OutcomeStrategy: tt.strategy}).Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := g.Execute("request", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantOutcome, res.Outcome)
+			assert.Equal(t, tt.wantOutcomes, res.Outcomes)
+		})
+	}
+}
+
+func TestWithOutcomeStrategy(t *testing.T) {
+	p := NewProgram().
+		Pass("auto",
+			s.Start("request"),
+			s.Named("Auto Approved").Priority(1).Outcome("auto_approved"),
+		).
+		Pass("manual",
+			s.Start("request"),
+			s.Check("true"),
+			s.Named("Approved").Priority(2).Outcome("approved"),
+		)
+
+	g, err := (&Compiler{Program: p, OutcomeStrategy: FirstReached}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the execution option takes precedence over the compiler's strategy,
+	// and short circuiting is ignored for strategies other than HighestPriority.
+	res, err := g.Execute("request", nil, WithOutcomeStrategy(AllReached), WithShortCircuit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"approved", "auto_approved"}, res.Outcomes)
+	assert.Equal(t, Complete, res.State["approved"])
+}
+
+func TestExecute_ReachedOutcomes(t *testing.T) {
+	p := NewProgram().
+		Pass("auto",
+			s.Start("request"),
+			s.Check("input.oncall"),
+			s.Named("Auto Approved").Priority(1).Outcome("auto_approved"),
+		).
+		Pass("manual",
+			s.Start("request"),
+			s.Check("true"),
+			s.Check("true"),
+			s.Named("Approved").Priority(2).Outcome("approved"),
+		).
+		Pass("denied",
+			s.Start("request"),
+			s.Check("false"),
+			s.Named("Denied").Priority(3).Outcome("denied"),
+		)
+	schema := &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{"oncall": {Type: jsoncel.Boolean}},
+	}
+
+	g, err := (&Compiler{Program: p, InputSchema: schema}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both outcomes are listed, although only 'approved' is the outcome.
+	res, err := g.Execute("request", map[string]any{"oncall": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "approved", res.Outcome)
+	assert.Equal(t, []node.Node{
+		{Type: node.Outcome, ID: "auto_approved", Name: "Auto Approved", Priority: 1},
+		{Type: node.Outcome, ID: "approved", Name: "Approved", Priority: 2},
+	}, res.ReachedOutcomes)
+
+	res, err = g.Execute("request", map[string]any{"oncall": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []node.Node{
+		{Type: node.Outcome, ID: "approved", Name: "Approved", Priority: 2},
+	}, res.ReachedOutcomes)
+}
@@ -0,0 +1,161 @@
+package glide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/common-fate/glide/pkg/step"
+)
+
+// Summarize returns a short, human readable summary of a result,
+// for use in notification messages, e.g.
+//
+//	waiting on notifying admins for access approval; Auto approval not met because input.oncall is false
+//
+// The summary is assembled from the names of steps, the PrintAction
+// descriptions of active actions, and the checks which are blocking the
+// workflow. Steps are described in topological order, so that summaries
+// of the same result are identical.
+func Summarize(res *Result, g *Graph) (string, error) {
+	if res == nil {
+		return "not executed", nil
+	}
+	if res.graph != nil {
+		g = res.graph
+	}
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+
+	if res.Failed {
+		for _, k := range order {
+			v, err := g.store.step(k)
+			if err != nil {
+				return "", err
+			}
+			if res.State[k] != Failed || v.OnFail.Behavior != step.FailWorkflow {
+				continue
+			}
+			part := fmt.Sprintf("%s failed", actionLabel(v))
+			if err := res.Errors[k]; err != nil {
+				part += ": " + err.Error()
+			}
+			parts = append(parts, part)
+		}
+		return "workflow failed: " + strings.Join(parts, "; "), nil
+	}
+
+	if res.Outcome != "" && !res.DefaultOutcome {
+		return "reached " + outcomeName(res), nil
+	}
+
+	stepErrs := map[string]bool{}
+	for _, se := range res.StepErrors {
+		stepErrs[se.Step] = true
+	}
+
+	for _, k := range order {
+		v, err := g.store.step(k)
+		if err != nil {
+			return "", err
+		}
+
+		switch t := v.Body.(type) {
+		case step.Action:
+			if res.State[k] == Active {
+				parts = append(parts, "waiting on "+actionLabel(v))
+			}
+		case step.Check:
+			if stepErrs[k] {
+				parts = append(parts, fmt.Sprintf("could not evaluate %s", v.Label()))
+				continue
+			}
+			if res.State[k] != Inactive {
+				continue
+			}
+			// only checks which are blocking the workflow are described,
+			// rather than every check which hasn't been reached yet.
+			blocking, err := g.reached(k, res.State)
+			if err != nil {
+				return "", err
+			}
+			if !blocking {
+				continue
+			}
+			if v.Name != "" {
+				parts = append(parts, fmt.Sprintf("%s not met because %s is false", v.Name, t.Expression))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s is false", t.Expression))
+			}
+		}
+	}
+
+	if len(res.UnknownFields) > 0 {
+		parts = append(parts, "waiting for "+strings.Join(res.UnknownFields, ", "))
+	}
+
+	// the default outcome is a status rather than an outcome which was
+	// reached, so it's shown alongside what the workflow is waiting on.
+	if res.DefaultOutcome {
+		if len(parts) == 0 {
+			return outcomeName(res), nil
+		}
+		return outcomeName(res) + ": " + strings.Join(parts, "; "), nil
+	}
+
+	if len(parts) == 0 {
+		return "no outcome reached", nil
+	}
+	return strings.Join(parts, "; "), nil
+}
+
+// outcomeName returns the name of the result's outcome, or its ID if it has no name.
+func outcomeName(res *Result) string {
+	if res.OutcomeNode != nil && res.OutcomeNode.Name != "" {
+		return res.OutcomeNode.Name
+	}
+	return res.Outcome
+}
+
+// Summarize returns a short, human readable summary of a result. See Summarize.
+func (c *Compiled) Summarize(res *Result) (string, error) {
+	return Summarize(res, c.g)
+}
+
+// reached returns true if an edge into the vertex is followed.
+func (g *Graph) reached(k string, state map[string]State) (bool, error) {
+	predecessors, err := g.store.predecessors(k)
+	if err != nil {
+		return false, err
+	}
+	for _, pred := range predecessors {
+		s, ok := state[pred.Source]
+		if !ok {
+			continue
+		}
+		followed, err := g.edgeFollowed(pred, s)
+		if err != nil {
+			return false, err
+		}
+		if followed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// actionLabel describes an action step by its name,
+// or by what the action does if it doesn't have one.
+func actionLabel(s step.Step) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if a, ok := s.Body.(step.Action); ok {
+		return a.PrintAction()
+	}
+	return s.Label()
+}
@@ -0,0 +1,104 @@
+package glide
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	schema := &jsoncel.Schema{
+		Properties: map[string]*jsoncel.Schema{
+			"oncall": {Type: jsoncel.Boolean},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		give  *Program
+		input map[string]any
+		want  string
+	}{
+		{
+			name: "outcome",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("input.oncall"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"oncall": true},
+			want:  "reached Approved",
+		},
+		{
+			name: "waiting",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Boolean(step.Or,
+					s.Named("Auto approval").Check("input.oncall"),
+					s.Action("approval", &testAction{}),
+				),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"oncall": false},
+			want:  "Auto approval not met because input.oncall is false; waiting on action: approval",
+		},
+		{
+			name: "default outcome",
+			give: func() *Program {
+				p := SimpleProgram(
+					s.Start("request"),
+					s.Check("input.oncall"),
+					s.Named("Approved").Priority(1).Outcome("approved"),
+				)
+				p.DefaultOutcome = "pending_review"
+				return p
+			}(),
+			input: map[string]any{"oncall": false},
+			want:  "pending_review: input.oncall is false",
+		},
+		{
+			name: "unnamed check",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Check("input.oncall"),
+				s.Check("true"),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			input: map[string]any{"oncall": false},
+			want:  "input.oncall is false",
+		},
+		{
+			name: "failed",
+			give: SimpleProgram(
+				s.Start("request"),
+				s.Named("Notify").Action("notify", &testFailAction{err: errors.New("unavailable")}),
+				s.Named("Approved").Priority(1).Outcome("approved"),
+			),
+			want: "workflow failed: Notify failed: unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give, InputSchema: schema}
+			g, err := c.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := g.Execute("request", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.Summarize(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package glide
+
+import "time"
+
+// Tracer is notified of each step evaluated during an execution,
+// so that host applications can log executions or record metrics
+// about slow checks and actions.
+type Tracer interface {
+	// StepEvaluated is called once the state of a step has been evaluated,
+	// with the hash of its vertex and the time taken to evaluate it.
+	// Steps are traced in the order they are visited by the execution.
+	StepEvaluated(hash string, state State, elapsed time.Duration)
+}
+
+// TracerFunc adapts a function into a Tracer.
+type TracerFunc func(hash string, state State, elapsed time.Duration)
+
+func (f TracerFunc) StepEvaluated(hash string, state State, elapsed time.Duration) {
+	f(hash, state, elapsed)
+}
+
+// WithTracer notifies the tracer of each step evaluated during the execution.
+func WithTracer(t Tracer) ExecuteOption {
+	return func(o *executeOptions) {
+		o.tracer = t
+	}
+}
@@ -2,20 +2,86 @@ package glide
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/common-fate/glide/pkg/dialect"
-	"github.com/goccy/go-yaml"
+	"github.com/common-fate/glide/pkg/interpolate"
 )
 
-// Unmarshal a glide workflow YAML file into a program which can be compiled.
-func Unmarshal(data []byte, dialect dialect.Dialect) (*Program, error) {
-	var p Program
-	ctx := context.Background()
-	ctx = Use(ctx, dialect)
+// UnmarshalOption configures how a workflow is unmarshalled.
+type UnmarshalOption func(*interpolate.Vars)
+
+// WithVariables provides the values for '${var.<name>}'
+// references in step names and action 'with' properties.
+func WithVariables(vars map[string]string) UnmarshalOption {
+	return func(v *interpolate.Vars) {
+		v.Var = vars
+	}
+}
 
-	err := yaml.UnmarshalContext(ctx, data, &p)
+// WithEnv provides the values for '${env.<name>}'
+// references in step names and action 'with' properties.
+// The process environment is never read unless it is provided here.
+func WithEnv(env map[string]string) UnmarshalOption {
+	return func(v *interpolate.Vars) {
+		v.Env = env
+	}
+}
+
+// Decoder decodes glide workflow YAML files written in a dialect.
+type Decoder struct {
+	dialect dialect.Dialect
+	vars    interpolate.Vars
+}
+
+// NewDecoder returns a decoder for workflows written in the dialect.
+func NewDecoder(d dialect.Dialect, opts ...UnmarshalOption) *Decoder {
+	dec := Decoder{dialect: d}
+	for _, opt := range opts {
+		opt(&dec.vars)
+	}
+	return &dec
+}
+
+// Decode a glide workflow YAML file into a program which can be compiled.
+//
+// References to undefined variables are returned as a noderr.NodeError.
+//
+// Workflows may be untrusted, so definitions which are nested too
+// deeply or which expand to too many nodes through aliases are rejected.
+func (d *Decoder) Decode(data []byte) (*Program, error) {
+	tree, err := Parse(data)
 	if err != nil {
 		return nil, err
 	}
-	return &p, nil
+	return d.Resolve(tree)
+}
+
+// Resolve the steps of a syntax tree against the decoder's dialect,
+// producing a program which can be compiled.
+//
+// References to undefined variables are returned as a noderr.NodeError.
+// The tree isn't modified, so it can be resolved more than once.
+func (d *Decoder) Resolve(tree *Tree) (prog *Program, err error) {
+	// the YAML library re-parses nodes when decoding them with UnmarshalYAML
+	// methods, and can panic on malformed input which it parsed successfully
+	// the first time, so panics are returned as errors.
+	defer func() {
+		if r := recover(); r != nil {
+			prog = nil
+			err = fmt.Errorf("invalid YAML: %v", r)
+		}
+	}()
+
+	ctx := context.Background()
+	ctx = dialect.Context(ctx, d.dialect)
+	ctx = interpolate.Context(ctx, d.vars)
+
+	return resolveTree(ctx, d.dialect, tree)
+}
+
+// Unmarshal a glide workflow YAML file into a program which can be compiled.
+// It is shorthand for NewDecoder(dialect, opts...).Decode(data).
+func Unmarshal(data []byte, dialect dialect.Dialect, opts ...UnmarshalOption) (*Program, error) {
+	return NewDecoder(dialect, opts...).Decode(data)
 }
@@ -3,8 +3,10 @@ package glide
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
 	"github.com/common-fate/glide/pkg/node"
 	"github.com/common-fate/glide/pkg/step"
 	"github.com/common-fate/glide/pkg/step/s"
@@ -58,6 +60,118 @@ workflow:
 				s.Outcome("D"),
 			),
 		},
+		{
+			name: "with preconditions",
+			give: `
+preconditions:
+  - check: B
+  - or:
+    - check: C
+    - check: D
+workflow:
+  default:
+    steps:
+      - start: A
+      - outcome: E
+`,
+			want: &Program{
+				Workflow: NewProgram().Pass("default",
+					s.Start("A"),
+					s.Outcome("E"),
+				).Workflow,
+				Preconditions: []step.Step{
+					setPass(s.Check("B"), PreconditionsPass),
+					setPass(s.Boolean(step.Or,
+						s.Check("C"),
+						s.Check("D"),
+					), PreconditionsPass),
+				},
+			},
+		},
+		{
+			name: "with parallel",
+			give: `
+workflow:
+  default:
+    steps:
+      - start: A
+      - parallel:
+          - check: B
+          - steps:
+              - check: C
+              - check: D
+        join: any
+      - outcome: E
+`,
+			want: NewProgram().Pass("default",
+				s.Start("A"),
+				s.Boolean(step.Or,
+					s.Check("B"),
+					s.Sequence(
+						s.Check("C"),
+						s.Check("D"),
+					),
+				),
+				s.Outcome("E"),
+			),
+		},
+		{
+			name: "invalid parallel join",
+			give: `
+workflow:
+  default:
+    steps:
+      - parallel:
+          - check: B
+        join: some
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid join without parallel",
+			give: `
+workflow:
+  default:
+    steps:
+      - and:
+          - check: B
+        join: any
+`,
+			wantErr: true,
+		},
+		{
+			name: "with description",
+			give: `
+workflow:
+  default:
+    steps:
+      - check: A
+        description: Contractors need a second approval
+`,
+			want: NewProgram().Pass("default", s.Described("Contractors need a second approval").Check("A")),
+		},
+		{
+			name: "with estimate",
+			give: `
+workflow:
+  default:
+    steps:
+      - check: A
+        estimate: 1d12h
+`,
+			want: NewProgram().Pass("default", s.Estimated(36*time.Hour).Check("A")),
+		},
+		{
+			name: "invalid estimate",
+			give: `
+workflow:
+  default:
+    steps:
+      - check: A
+        estimate: 2 days
+`,
+			wantErr: true,
+		},
 		{
 			name: "with if statement",
 			give: `
@@ -204,6 +318,37 @@ workflow:
 				},
 			),
 		},
+		{
+			name: "step ids",
+			give: `
+workflow:
+  default:
+    steps:
+      - id: my_check
+        check: true
+      - id: nested
+        and:
+          - id: inner
+            check: false
+`,
+			want: NewProgram().Pass("default",
+				s.WithID("my_check").Check("true"),
+				s.WithID("nested").Boolean(step.And,
+					s.WithID("inner").Check("false"),
+				),
+			),
+		},
+		{
+			name: "invalid step id",
+			give: `
+workflow:
+  default:
+    steps:
+      - id: 1
+        check: true
+`,
+			wantErr: true,
+		},
 		{
 			name: "named steps",
 			give: `
@@ -228,7 +373,81 @@ workflow:
 				s.Named("My check").Check("true"),
 				s.Named("End Node Name").Priority(1).Outcome("B"),
 			),
-			only: true,
+		},
+		{
+			name: "action continue on fail",
+			give: `
+workflow:
+  default:
+    steps:
+      - action: my_action
+        on_fail: continue
+`,
+			want: NewProgram().Pass("default",
+				s.OnFail(step.Continue).Action("my_action", &testAction{}),
+			),
+			dialect: &dialect.Dialect{
+				Actions: func() map[string]any {
+					return map[string]any{
+						"my_action": &testAction{},
+					}
+				},
+			},
+		},
+		{
+			name: "action route on fail",
+			give: `
+workflow:
+  default:
+    steps:
+      - action: my_action
+        on_fail:
+          steps:
+            - check: true
+            - outcome: B
+`,
+			want: NewProgram().Pass("default",
+				s.OnFail(step.Route,
+					s.Check("true"),
+					s.Outcome("B"),
+				).Action("my_action", &testAction{}),
+			),
+			dialect: &dialect.Dialect{
+				Actions: func() map[string]any {
+					return map[string]any{
+						"my_action": &testAction{},
+					}
+				},
+			},
+		},
+		{
+			name: "invalid on fail value",
+			give: `
+workflow:
+  default:
+    steps:
+      - action: my_action
+        on_fail: retry
+`,
+			dialect: &dialect.Dialect{
+				Actions: func() map[string]any {
+					return map[string]any{
+						"my_action": &testAction{},
+					}
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid on fail for check",
+			give: `
+workflow:
+  default:
+    steps:
+      - check: true
+        on_fail: continue
+`,
+			wantErr: true,
 		},
 	}
 
@@ -287,6 +506,10 @@ func statementsEqual(t *testing.T, want *Program, got *Program) {
 		cleanedWorkflow.Workflow[passID] = p
 	}
 
+	for _, s := range got.Preconditions {
+		cleanedWorkflow.Preconditions = append(cleanedWorkflow.Preconditions, cleanAst(s))
+	}
+
 	assert.Equal(t, want, cleanedWorkflow)
 }
 
@@ -296,6 +519,9 @@ func cleanAst(s step.Step) step.Step {
 	for i, child := range s.Children {
 		s.Children[i] = cleanAst(child)
 	}
+	for i, child := range s.OnFail.Steps {
+		s.OnFail.Steps[i] = cleanAst(child)
+	}
 	return s
 }
 
@@ -348,3 +574,114 @@ workflow:
 		})
 	}
 }
+
+func TestDecoder(t *testing.T) {
+	dec := NewDecoder(yamlTestDialect, WithVariables(map[string]string{"team": "platform"}))
+
+	// a decoder can be used for more than one workflow.
+	for _, pass := range []string{"first", "second"} {
+		got, err := dec.Decode([]byte(`
+workflow:
+  ` + pass + `:
+    steps:
+      - start: request
+      - name: ${var.team} approval
+        check: input.approved
+      - outcome: approved
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "platform approval", got.Workflow[pass].Steps[1].Name)
+		assert.Equal(t, yamlTestDialect.Nodes, got.Dialect.Nodes)
+	}
+}
+
+func TestUnmarshal_Variables(t *testing.T) {
+	data := []byte(`
+workflow:
+  test:
+    steps:
+      - start: request
+      - name: Notify ${var.team}
+        action: my_action
+        with:
+          property: ${var.channel}-${env.STAGE}
+      - outcome: approved
+`)
+
+	got, err := Unmarshal(data, yamlTestDialect,
+		WithVariables(map[string]string{"team": "platform", "channel": "#platform"}),
+		WithEnv(map[string]string{"STAGE": "prod"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	action := got.Workflow["test"].Steps[1]
+	assert.Equal(t, "Notify platform", action.Name)
+	assert.Equal(t, &testAction{Property: "#platform-prod"}, action.Body.(step.Action).Action)
+}
+
+func TestUnmarshal_Anchors(t *testing.T) {
+	data := []byte(`
+common:
+  approval: &approval
+    check: input.approved
+  notify: &notify
+    action: my_action
+    with:
+      property: "010"
+workflow:
+  first:
+    steps:
+      - start: request
+      - *approval
+      - outcome: approved
+  second:
+    steps:
+      - start: request
+      - name: Approval
+        <<: *approval
+      - <<: *notify
+      - <<: *notify
+        with:
+          property: overridden
+      - outcome: approved
+`)
+
+	got, err := Unmarshal(data, yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each use of the anchor has its own node, with the path where it was used.
+	first := got.Workflow["first"].Steps[1]
+	assert.Equal(t, step.Check{Expression: "input.approved"}, first.Body)
+	assert.Equal(t, "$.workflow.first.steps[1].check", first.Node.GetPath())
+
+	second := got.Workflow["second"].Steps[1]
+	assert.Equal(t, step.Check{Expression: "input.approved"}, second.Body)
+	assert.Equal(t, "Approval", second.Name)
+	assert.Equal(t, "$.workflow.second.steps[1].name", second.Node.GetPath())
+
+	// scalars are decoded as they were written.
+	notify := got.Workflow["second"].Steps[2]
+	assert.Equal(t, &testAction{Property: "010"}, notify.Body.(step.Action).Action)
+
+	// keys in the mapping override merged keys.
+	overridden := got.Workflow["second"].Steps[3]
+	assert.Equal(t, &testAction{Property: "overridden"}, overridden.Body.(step.Action).Action)
+
+	c := Compiler{
+		Program: got,
+		InputSchema: &jsoncel.Schema{
+			Properties: map[string]*jsoncel.Schema{
+				"approved": {Type: jsoncel.Boolean},
+			},
+		},
+	}
+	_, err = c.Compile()
+	assert.NoError(t, err)
+}
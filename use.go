@@ -6,7 +6,11 @@ import (
 	"github.com/common-fate/glide/pkg/dialect"
 )
 
-// Use a specified Glide dialect.
+// Use a specified Glide dialect, for programs and steps which are
+// unmarshalled with yaml.UnmarshalContext.
+//
+// Deprecated: decode workflows with NewDecoder, which doesn't
+// need the dialect to be defined in the context.
 func Use(parent context.Context, d dialect.Dialect) context.Context {
 	return dialect.Context(parent, d)
 }
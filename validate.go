@@ -0,0 +1,68 @@
+package glide
+
+import (
+	"sort"
+
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/google/cel-go/cel"
+)
+
+// CompileValidator is implemented by actions which validate their
+// configuration when a workflow is compiled, such as an approval action
+// checking that the groups it references exist in a directory.
+// Errors are returned by Compiler.Compile, pointing to the action step.
+type CompileValidator interface {
+	// ValidateCompile validates the action. The environment is the
+	// CEL environment the workflow's checks are compiled in, and
+	// the schema is the input schema of the workflow.
+	ValidateCompile(env *cel.Env, schema *jsoncel.Schema) error
+}
+
+// validateActions calls ValidateCompile on each action in a program
+// which implements CompileValidator.
+func validateActions(p *Program, env *cel.Env, schema *jsoncel.Schema) error {
+	// sort the passes so that errors are deterministic.
+	var passes []string
+	for name := range p.Workflow {
+		passes = append(passes, name)
+	}
+	sort.Strings(passes)
+
+	var visit func(steps []step.Step) error
+	visit = func(steps []step.Step) error {
+		for _, s := range steps {
+			if a, ok := s.Body.(step.Action); ok {
+				if v, ok := a.Action.(CompileValidator); ok {
+					err := v.ValidateCompile(env, schema)
+					if err != nil {
+						return noderr.Wrap(err, s.Node)
+					}
+				}
+			}
+
+			err := visit(s.Children)
+			if err != nil {
+				return err
+			}
+			err = visit(s.OnFail.Steps)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err := visit(p.Preconditions)
+	if err != nil {
+		return err
+	}
+	for _, name := range passes {
+		err = visit(p.Workflow[name].Steps)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
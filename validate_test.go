@@ -0,0 +1,134 @@
+package glide
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/dialect"
+	"github.com/common-fate/glide/pkg/jsoncel"
+	"github.com/common-fate/glide/pkg/node"
+	"github.com/common-fate/glide/pkg/noderr"
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_ValidateActions(t *testing.T) {
+	d := dialect.Dialect{
+		Actions: func() map[string]any {
+			return map[string]any{"validated": &testValidatedAction{}}
+		},
+		Nodes: map[string]node.Node{
+			"request":  {Type: node.Start},
+			"approved": {Type: node.Outcome, Priority: 1},
+		},
+	}
+	schema := &jsoncel.Schema{
+		Type:       jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{"team": {Type: jsoncel.String}},
+	}
+
+	tests := []struct {
+		name     string
+		field    string
+		wantErr  string
+		wantPath string
+	}{
+		{
+			name:  "ok",
+			field: "team",
+		},
+		{
+			name:     "invalid",
+			field:    "group",
+			wantErr:  "field group is not in the input schema",
+			wantPath: "$.workflow.default.steps[1].and[0].action",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - and:
+          - action: validated
+            with:
+              field: `+tt.field+`
+      - outcome: approved
+`), d)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = (&Compiler{Program: p, InputSchema: schema}).Compile()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+
+			var ne noderr.NodeError
+			if assert.ErrorAs(t, err, &ne) {
+				assert.Equal(t, tt.wantPath, ne.Node.GetPath())
+			}
+		})
+	}
+}
+
+// testCountedAction counts the times it's validated.
+type testCountedAction struct {
+	validations *int32
+}
+
+func (t *testCountedAction) ValidateCompile(env *cel.Env, schema *jsoncel.Schema) error {
+	atomic.AddInt32(t.validations, 1)
+	return nil
+}
+
+func TestCompile_ValidateActionsOnce(t *testing.T) {
+	var validations int32
+	d := dialect.Dialect{
+		Actions: func() map[string]any {
+			return map[string]any{"counted": &testCountedAction{validations: &validations}}
+		},
+		Nodes: map[string]node.Node{
+			"request":  {Type: node.Start},
+			"approved": {Type: node.Outcome, Priority: 1},
+		},
+	}
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    matrix:
+      resource: input.resources
+    steps:
+      - start: request
+      - action: counted
+      - outcome: approved
+`), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &jsoncel.Schema{
+		Type: jsoncel.Object,
+		Properties: map[string]*jsoncel.Schema{
+			"resources": {Type: jsoncel.Array, Items: &jsoncel.Schema{Type: jsoncel.String}},
+		},
+	}
+	compiled, err := (&Compiler{Program: p, InputSchema: schema}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := atomic.LoadInt32(&validations)
+	assert.NotZero(t, want)
+
+	// the workflow is expanded for each input, without validating the actions again.
+	for i := 0; i < 3; i++ {
+		_, err = compiled.Execute("request", map[string]any{"resources": []any{"a", "b"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, want, atomic.LoadInt32(&validations))
+}
@@ -0,0 +1,224 @@
+package glide
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/pkg/errors"
+)
+
+// ErrStopWalk can be returned by a Visitor to stop walking the graph.
+// Walk returns nil if the walk was stopped with ErrStopWalk.
+var ErrStopWalk = errors.New("stop walk")
+
+// Visitor has typed methods which are called for each step in the graph by Walk.
+//
+// Embed NopVisitor to only implement the methods for the steps you're interested in.
+type Visitor interface {
+	VisitCheck(s step.Step, c step.Check) error
+	VisitAction(s step.Step, a step.Action) error
+	VisitBoolean(s step.Step, b step.Boolean) error
+	VisitRef(s step.Step, r step.Ref) error
+	VisitWait(s step.Step, w step.Wait) error
+}
+
+// NopVisitor is a Visitor which does nothing.
+type NopVisitor struct{}
+
+func (NopVisitor) VisitCheck(s step.Step, c step.Check) error     { return nil }
+func (NopVisitor) VisitAction(s step.Step, a step.Action) error   { return nil }
+func (NopVisitor) VisitBoolean(s step.Step, b step.Boolean) error { return nil }
+func (NopVisitor) VisitRef(s step.Step, r step.Ref) error         { return nil }
+func (NopVisitor) VisitWait(s step.Step, w step.Wait) error       { return nil }
+
+// Walk calls the visitor for each step in the graph, in topological order.
+// A step is always visited after all of its predecessors. Steps which
+// could be visited at the same time are visited in order of their hash,
+// so the order is stable between walks.
+//
+// If the visitor returns an error, the walk stops and the error is returned.
+func (g *Graph) Walk(v Visitor) error {
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, k := range order {
+		s, err := g.store.step(k)
+		if err != nil {
+			return err
+		}
+
+		switch t := s.Body.(type) {
+		case step.Check:
+			err = v.VisitCheck(s, t)
+		case step.Action:
+			err = v.VisitAction(s, t)
+		case step.Boolean:
+			err = v.VisitBoolean(s, t)
+		case step.Ref:
+			err = v.VisitRef(s, t)
+		case step.Wait:
+			err = v.VisitWait(s, t)
+		default:
+			err = fmt.Errorf("unhandled step type %T", s.Body)
+		}
+
+		if errors.Is(err, ErrStopWalk) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bfs visits the steps reachable from the start step in breadth-first order.
+// Successors are visited in order of their hash. A step is visited after each of
+// its reachable predecessors, so that steps which join several paths, like outcomes
+// reached by passes of different lengths, see the state of all of them. Checks which
+// reference the state of other steps are visited after those steps too.
+// The traversal stops if the visit function returns true.
+func (g *Graph) bfs(start string, visit func(k string) bool) error {
+	if _, err := g.store.step(start); err != nil {
+		return err
+	}
+
+	// reachable is used to check whether the predecessors of a step,
+	// and the steps which a check depends on, will be visited.
+	reachable, err := g.reachable(start)
+	if err != nil {
+		return err
+	}
+
+	queue := []string{start}
+	visited := map[string]bool{start: true}
+	done := map[string]bool{}
+
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+
+		// visit the step again later if its predecessors or the steps it depends on
+		// haven't been visited. compiled graphs don't have cycles between steps and
+		// their dependencies, so the steps are visited eventually.
+		waiting, err := g.waiting(k, reachable, done)
+		if err != nil {
+			return err
+		}
+		if waiting {
+			queue = append(queue, k)
+			continue
+		}
+		done[k] = true
+
+		if stop := visit(k); stop {
+			return nil
+		}
+
+		succs, err := g.store.successors(k)
+		if err != nil {
+			return err
+		}
+		for _, next := range succs {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+// waiting returns true if a step has predecessors or depends on
+// reachable steps which haven't been visited.
+func (g *Graph) waiting(k string, reachable, done map[string]bool) (bool, error) {
+	for _, dep := range g.deps[k] {
+		if reachable[dep] && !done[dep] {
+			return true, nil
+		}
+	}
+	preds, err := g.store.predecessors(k)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range preds {
+		if reachable[p.Source] && !done[p.Source] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reachable returns the steps reachable from the start step.
+func (g *Graph) reachable(start string) (map[string]bool, error) {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+
+		succs, err := g.store.successors(k)
+		if err != nil {
+			return nil, err
+		}
+		for _, next := range succs {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return seen, nil
+}
+
+// topologicalOrder returns the vertex hashes of the graph in a stable topological order.
+func (g *Graph) topologicalOrder() ([]string, error) {
+	hashes, err := g.store.hashes()
+	if err != nil {
+		return nil, err
+	}
+
+	// remaining counts the predecessors of each vertex which are yet to be visited.
+	remaining := map[string]int{}
+	var ready []string
+	for _, k := range hashes {
+		pres, err := g.store.predecessors(k)
+		if err != nil {
+			return nil, err
+		}
+		remaining[k] = len(pres)
+		if len(pres) == 0 {
+			ready = append(ready, k)
+		}
+	}
+
+	var order []string
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		k := ready[0]
+		ready = ready[1:]
+		order = append(order, k)
+
+		succs, err := g.store.successors(k)
+		if err != nil {
+			return nil, err
+		}
+		for _, next := range succs {
+			remaining[next]--
+			if remaining[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	if len(order) != len(hashes) {
+		return nil, errors.New("graph contains a cycle")
+	}
+
+	return order, nil
+}
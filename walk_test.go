@@ -0,0 +1,126 @@
+package glide
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/common-fate/glide/pkg/step/s"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingVisitor records the steps it visits.
+type recordingVisitor struct {
+	visited []string
+	// stopAt stops the walk when the step is visited.
+	stopAt string
+}
+
+func (v *recordingVisitor) record(s step.Step, kind string) error {
+	v.visited = append(v.visited, fmt.Sprintf("%s %s", kind, s.Hash()))
+	if s.Hash() == v.stopAt {
+		return ErrStopWalk
+	}
+	return nil
+}
+
+func (v *recordingVisitor) VisitCheck(s step.Step, c step.Check) error {
+	return v.record(s, "check")
+}
+func (v *recordingVisitor) VisitAction(s step.Step, a step.Action) error {
+	return v.record(s, "action")
+}
+func (v *recordingVisitor) VisitBoolean(s step.Step, b step.Boolean) error {
+	return v.record(s, "boolean")
+}
+func (v *recordingVisitor) VisitRef(s step.Step, r step.Ref) error {
+	return v.record(s, "ref")
+}
+func (v *recordingVisitor) VisitWait(s step.Step, w step.Wait) error {
+	return v.record(s, "wait")
+}
+
+func TestGraph_Walk(t *testing.T) {
+	tests := []struct {
+		name   string
+		give   *Program
+		stopAt string
+		want   []string
+	}{
+		{
+			name: "ok",
+			give: SimpleProgram(
+				s.Start("A"),
+				s.Action("approval", nil),
+				s.Outcome("B"),
+			),
+			want: []string{"ref A", "action default.1", "ref B"},
+		},
+		{
+			name: "children are visited before their parent",
+			give: SimpleProgram(
+				s.Start("A"),
+				s.Boolean(step.And,
+					s.Check("true"),
+					s.Check("false"),
+				),
+				s.Outcome("B"),
+			),
+			want: []string{"ref A", "check default.1.0", "check default.1.1", "boolean default.1", "ref B"},
+		},
+		{
+			name: "multiple passes",
+			give: NewProgram().Pass("second",
+				s.Start("A"),
+				s.Check("true"),
+				s.Outcome("B"),
+			).Pass("first",
+				s.Start("A"),
+				s.Check("true"),
+				s.Outcome("B"),
+			),
+			want: []string{"ref A", "check first.1", "check second.1", "ref B"},
+		},
+		{
+			name: "steps are visited after their predecessors",
+			give: NewProgram().Pass("short",
+				s.Start("A"),
+				s.Check("true"),
+				s.Outcome("B"),
+			).Pass("long",
+				s.Start("A"),
+				s.Check("true"),
+				s.Check("true"),
+				s.Outcome("B"),
+			),
+			want: []string{"ref A", "check long.1", "check long.2", "check short.1", "ref B"},
+		},
+		{
+			name: "stop walk",
+			give: SimpleProgram(
+				s.Start("A"),
+				s.Check("true"),
+				s.Outcome("B"),
+			),
+			stopAt: "default.1",
+			want:   []string{"ref A", "check default.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compiler{Program: tt.give}
+			g, err := c.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := recordingVisitor{stopAt: tt.stopAt}
+			err = g.Walk(&v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, v.visited)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package glide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/common-fate/glide/pkg/step"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhen_Execute(t *testing.T) {
+	p, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - id: approval
+        action: my_action
+        when: mon-fri 09:00-17:00 Australia/Sydney
+      - outcome: approved
+`), yamlTestDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := (&Compiler{Program: p}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		time      time.Time
+		wantState State
+		wantGated []GatedStep
+	}{
+		{
+			name:      "business hours",
+			time:      time.Date(2024, 3, 6, 10, 0, 0, 0, sydney), // Wednesday
+			wantState: Active,
+		},
+		{
+			name:      "after hours",
+			time:      time.Date(2024, 3, 6, 17, 0, 0, 0, sydney),
+			wantState: Inactive,
+			wantGated: []GatedStep{{
+				Step:  "default.approval",
+				When:  "mon-fri 09:00-17:00 Australia/Sydney",
+				Opens: time.Date(2024, 3, 7, 9, 0, 0, 0, sydney),
+			}},
+		},
+		{
+			name:      "weekend",
+			time:      time.Date(2024, 3, 9, 12, 0, 0, 0, sydney), // Saturday
+			wantState: Inactive,
+			wantGated: []GatedStep{{
+				Step:  "default.approval",
+				When:  "mon-fri 09:00-17:00 Australia/Sydney",
+				Opens: time.Date(2024, 3, 11, 9, 0, 0, 0, sydney),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Execute("request", nil, WithTime(tt.time))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantState, got.State["default.approval"])
+			assert.Equal(t, len(tt.wantGated), len(got.Gated))
+			for i := range tt.wantGated {
+				assert.Equal(t, tt.wantGated[i].Step, got.Gated[i].Step)
+				assert.Equal(t, tt.wantGated[i].When, got.Gated[i].When)
+				assert.True(t, tt.wantGated[i].Opens.Equal(got.Gated[i].Opens), "opens %s, want %s", got.Gated[i].Opens, tt.wantGated[i].Opens)
+			}
+		})
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		open    []time.Time
+		closed  []time.Time
+		wantErr string
+	}{
+		{
+			spec:   "business hours",
+			open:   []time.Time{time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)},
+			closed: []time.Time{time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC), time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			spec:   "sat-sun 10:00-12:30",
+			open:   []time.Time{time.Date(2024, 3, 3, 12, 29, 0, 0, time.UTC)},
+			closed: []time.Time{time.Date(2024, 3, 3, 12, 30, 0, 0, time.UTC), time.Date(2024, 3, 4, 11, 0, 0, 0, time.UTC)},
+		},
+		{
+			spec:   "08:00-20:00",
+			open:   []time.Time{time.Date(2024, 3, 3, 8, 0, 0, 0, time.UTC)},
+			closed: []time.Time{time.Date(2024, 3, 3, 7, 59, 0, 0, time.UTC)},
+		},
+		{
+			spec:   "* 9-16 * * 1-5 Europe/London",
+			open:   []time.Time{time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC)}, // 9am BST
+			closed: []time.Time{time.Date(2024, 7, 1, 16, 0, 0, 0, time.UTC), time.Date(2024, 7, 6, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			spec:   "*/15 * 1 * *",
+			open:   []time.Time{time.Date(2024, 3, 1, 3, 45, 0, 0, time.UTC)},
+			closed: []time.Time{time.Date(2024, 3, 1, 3, 46, 0, 0, time.UTC), time.Date(2024, 3, 2, 3, 45, 0, 0, time.UTC)},
+		},
+		{spec: "", wantErr: "must not be empty"},
+		{spec: "mon-fri 17:00-09:00", wantErr: `invalid hours "17:00-09:00": the end must be after the start`},
+		{spec: "weekdays 09:00-17:00", wantErr: `invalid day "weekdays": must be one of mon, tue, wed, thu, fri, sat or sun`},
+		{spec: "* 25 * * *", wantErr: `invalid hour in cron expression: "25" must be between 0 and 23`},
+		{spec: "mon-fri 09:00-17:00 Mars/Olympus", wantErr: `invalid time zone "Mars/Olympus"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := step.ParseSchedule(tt.spec)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, ts := range tt.open {
+				assert.True(t, s.Open(ts), "expected open at %s", ts)
+			}
+			for _, ts := range tt.closed {
+				assert.False(t, s.Open(ts), "expected closed at %s", ts)
+			}
+		})
+	}
+}
+
+func TestWhen_Invalid(t *testing.T) {
+	_, err := Unmarshal([]byte(`
+workflow:
+  default:
+    steps:
+      - start: request
+      - check: "true"
+        when: mon-fri 9am-5pm
+      - outcome: approved
+`), yamlTestDialect)
+	assert.EqualError(t, err, `invalid when: invalid time "9am": must be like 09:00`)
+}